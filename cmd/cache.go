@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd 管理生成目录中的缓存.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "管理生成目录中的缓存文件 " + generator.CacheFileName,
+	Args:  cobra.NoArgs,
+}
+
+// cacheClearCmd 删除缓存文件.
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "删除缓存文件，下次生成时完整扫描",
+	Long: `删除生成目录中的缓存文件 .gutowire.cache，下次生成时重新解析全部源文件并重写全部生成文件。
+与 --no-cache 不同，清除后的下一次生成会重新写入缓存。
+
+示例:
+  gutowire cache clear -w ./wire`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		opts, _ := buildOptions(cfg)
+		genPath := resolveWirePath(nil, cfg)
+		if genPath == "" {
+			genPath = "."
+		}
+
+		file, existed, err := runner.ClearCache(genPath, opts...)
+		if err != nil {
+			return err
+		}
+		if !existed {
+			printResult("缓存文件不存在，无需清除: "+file, "file", file)
+			return nil
+		}
+		printResult("已清除缓存: "+file, "file", file)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
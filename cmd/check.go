@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd 只校验不生成.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "校验注解与依赖关系，不写入任何文件",
+	Long: `执行完整的扫描、注解解析与依赖图校验，但不会写入任何文件。
+发现问题时输出诊断信息并以非零状态码退出，适用于 pre-commit 钩子与 CI。
+
+校验内容:
+  - 同一 Set 中的重复接口绑定（duplicate_binding 为 error 时）
+  - 不同 Set 绑定同一接口且同时加入汇总 Sets（conflict_policy 为 error 时）
+  - 组件之间的循环依赖
+  - 注入入口（@autowire.init）用到但没有任何组件提供的依赖（missing_providers 为 error 时）
+
+示例:
+  gutowire check -w ./wire
+  gutowire check -s ./internal`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		opts, _ := buildOptions(cfg)
+		// 校验需要完整的依赖信息，旧版本缓存中没有，这里总是完整扫描
+		opts = append(opts,
+			config.WithCache(false),
+			config.WithLogger(newLogger(os.Stderr, commandLevel(slog.LevelWarn))),
+		)
+		genPath := resolveWirePath(nil, cfg)
+		if genPath == "" {
+			genPath = "."
+		}
+
+		result, err := runner.Check(genPath, opts...)
+		if err != nil {
+			return err
+		}
+
+		printDiagnostics(result)
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("检查未通过: 发现 %d 个问题", len(result.Errors))
+		}
+
+		printResult("检查通过")
+		return nil
+	},
+}
+
+// printDiagnostics function    输出校验发现的警告与错误
+// 文本模式输出到标准错误，JSON 模式输出 warning 与 error 事件到标准输出.
+func printDiagnostics(result *runner.CheckResult) {
+	if jsonOutput() {
+		l := newLogger(os.Stdout, slog.LevelInfo)
+		for _, w := range result.Warnings {
+			l.Warn(w, logger.EventKey, logger.EventWarning)
+		}
+		for _, e := range result.Errors {
+			l.Error("检查发现问题", errorAttrs(e)...)
+		}
+		return
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "! "+w)
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// completeValues function    返回补全固定取值的函数.
+func completeValues(values ...string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeDirs function    补全目录，用于生成路径、搜索路径等参数.
+func completeDirs(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completeConfigFiles function    补全 YAML 配置文件.
+func completeConfigFiles(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return []string{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeHTMLFiles function    补全 HTML 文件.
+func completeHTMLFiles(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return []string{"html", "htm"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeProfiles function    补全配置文件中的配置档名称.
+func completeProfiles(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	enterCompletionDir()
+	cfg, err := config.LoadConfigFile(configFile, "")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(slices.Sorted(maps.Keys(cfg.Profiles)), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSets function    补全扫描到的 Set 名称.
+func completeSets(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFromScan(toComplete, func(sc *generator.AutoWireSearcher) []string {
+		return slices.Collect(maps.Keys(sc.ElementMap))
+	})
+}
+
+// completeComponents function    补全扫描到的组件与绑定的接口，如 svc.Zoo、svc.Store.
+func completeComponents(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeFromScan(toComplete, func(sc *generator.AutoWireSearcher) []string {
+		var names []string
+		for _, c := range sc.Components() {
+			names = append(names, c.Name)
+			names = append(names, c.Interfaces...)
+		}
+		return names
+	})
+}
+
+// completeScaffoldTargets function    补全缺少构造函数的组件.
+func completeScaffoldTargets(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFromScan(toComplete, func(sc *generator.AutoWireSearcher) []string {
+		plan, err := sc.Scaffold()
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for _, s := range plan.Scaffolds {
+			names = append(names, s.Element)
+		}
+		return names
+	})
+}
+
+// completeFromScan function    扫描注解，返回以 toComplete 开头的候选项（去重并排序）
+// 补全时不输出日志，扫描失败时不给出候选项.
+func completeFromScan(toComplete string, candidates func(*generator.AutoWireSearcher) []string) (
+	[]string, cobra.ShellCompDirective) {
+	enterCompletionDir()
+	sc, err := scanProjectWith(slog.New(slog.DiscardHandler))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	values := slices.Compact(slices.Sorted(slices.Values(candidates(sc))))
+	return filterPrefix(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// enterCompletionDir function    补全时切换到 --chdir 指定的目录（补全不执行 PersistentPreRunE）.
+func enterCompletionDir() {
+	if chdir != "" {
+		_ = os.Chdir(chdir)
+	}
+}
+
+// filterPrefix function    返回以 prefix 开头的候选项.
+func filterPrefix(values []string, prefix string) []string {
+	return slices.DeleteFunc(values, func(v string) bool { return !strings.HasPrefix(v, prefix) })
+}
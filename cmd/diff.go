@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/exp/charmtone"
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/diff"
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/runner"
+)
+
+// diff 输出的样式，保留制表符（生成的代码使用制表符缩进），非终端输出时颜色会被去除.
+var (
+	diffLine   = lipgloss.NewStyle().TabWidth(lipgloss.NoTabConversion)
+	diffHeader = diffLine.Bold(true)
+	diffHunk   = diffLine.Foreground(charmtone.Malibu)
+	diffAdd    = diffLine.Foreground(charmtone.Guac)
+	diffDelete = diffLine.Foreground(charmtone.Cherry)
+)
+
+// handleDiff function    在内存中重新生成并输出 diff，同时指定 --check-only 且存在修改时返回错误
+// opts 包含 --strict 时，生成过程中输出的警告同样返回错误.
+func handleDiff(genPath string, opts []config.Option) error {
+	changes, err := runner.Diff(genPath, opts...)
+	if err != nil {
+		return err
+	}
+	printFileDiffs(changes, "重新生成会修改文件")
+	if len(changes) == 0 {
+		printResult("生成的代码已是最新", "path", genPath)
+		return nil
+	}
+	if checkOnly {
+		files := make([]string, 0, len(changes))
+		for _, c := range changes {
+			files = append(files, c.File)
+		}
+		return errors.NewStaleGeneratedError(files)
+	}
+	return nil
+}
+
+// printFileDiffs function    输出重新生成会对生成文件造成的修改
+// 文本模式输出带颜色的 unified diff，JSON 模式每个文件输出一个消息为 msg 的 file_diff 事件.
+func printFileDiffs(changes []generator.FileChange, msg string) {
+	l := newLogger(os.Stdout, slog.LevelInfo)
+	for _, c := range changes {
+		oldName, newName := diffNames(c)
+		text := diff.Unified(oldName, newName, c.Old, c.New)
+		if jsonOutput() {
+			l.Info(msg, logger.EventKey, logger.EventFileDiff, "file", c.File, "diff", text)
+			continue
+		}
+		_, _ = lipgloss.Print(colorizeDiff(text))
+	}
+}
+
+// diffNames function    返回 diff 中 ---、+++ 行的文件名：当前目录下的文件使用带 a/、b/ 前缀的相对路径，
+// 其他文件使用绝对路径，新建与删除的文件一侧为 /dev/null.
+func diffNames(c generator.FileChange) (oldName, newName string) {
+	oldName, newName = filepath.ToSlash(c.File), filepath.ToSlash(c.File)
+	abs, err := filepath.Abs(c.File)
+	if wd, werr := os.Getwd(); err == nil && werr == nil {
+		if rel, ok := parser.RelPath(wd, abs); ok {
+			oldName, newName = "a/"+rel, "b/"+rel
+		}
+	}
+	if c.Old == nil {
+		oldName = diff.DevNull
+	}
+	if c.New == nil {
+		newName = diff.DevNull
+	}
+	return oldName, newName
+}
+
+// colorizeDiff function    为 unified diff 的各行添加颜色.
+func colorizeDiff(text string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		content := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			content = diffHeader.Render(content)
+		case strings.HasPrefix(line, "@@"):
+			content = diffHunk.Render(content)
+		case strings.HasPrefix(line, "+"):
+			content = diffAdd.Render(content)
+		case strings.HasPrefix(line, "-"):
+			content = diffDelete.Render(content)
+		}
+		b.WriteString(content)
+		if strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
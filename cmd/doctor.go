@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/exp/charmtone"
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// doctorStyles 各检查结果的标记与颜色.
+var doctorStyles = map[runner.DoctorStatus]lipgloss.Style{
+	runner.DoctorOK:   lipgloss.NewStyle().Foreground(charmtone.Guac).SetString("✓"),
+	runner.DoctorWarn: lipgloss.NewStyle().Foreground(charmtone.Mustard).SetString("!"),
+	runner.DoctorFail: lipgloss.NewStyle().Foreground(charmtone.Cherry).SetString("✗"),
+}
+
+var (
+	doctorName       = lipgloss.NewStyle().Bold(true).Width(12)
+	doctorSuggestion = lipgloss.NewStyle().Foreground(charmtone.Squid).PaddingLeft(4)
+)
+
+// doctorCmd 检查运行环境.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "检查运行环境（wire、go.mod、PATH、写权限、注解）",
+	Long: `检查运行 gutowire 所需的环境，不写入任何文件:
+
+  wire        wire 命令是否可用及其版本（配置了 wire_version 时检查工具缓存）
+  go.mod      go.mod 是否可读，是否依赖了 wire（fx 后端为 fx）
+  GOPATH/bin  go install 的安装目录是否在 PATH 中
+  生成目录    生成目录是否可写
+  注解        搜索路径中的组件数量与注解格式错误
+
+有检查项未通过时以非零状态码退出。
+
+示例:
+  gutowire doctor
+  gutowire doctor -w ./wire`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		opts, _ := buildOptions(cfg)
+		opts = append(opts,
+			config.WithCache(false),
+			config.WithLogger(newLogger(os.Stderr, commandLevel(slog.LevelError))),
+		)
+		genPath := resolveWirePath(nil, cfg)
+		if genPath == "" {
+			genPath = "."
+		}
+
+		checks := runner.Doctor(genPath, opts...)
+		printDoctor(checks)
+
+		failed := 0
+		for _, c := range checks {
+			if c.Status == runner.DoctorFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("环境检查未通过: %d 项失败", failed)
+		}
+		printResult("环境检查通过")
+		return nil
+	},
+}
+
+// printDoctor function    输出环境检查报告
+// 文本模式输出带颜色的报告，JSON 模式每个检查项输出一个 doctor 事件.
+func printDoctor(checks []runner.DoctorCheck) {
+	if jsonOutput() {
+		l := newLogger(os.Stdout, slog.LevelInfo)
+		for _, c := range checks {
+			l.Info(c.Name, logger.EventKey, logger.EventDoctor,
+				"status", string(c.Status), "detail", c.Detail, "suggestion", c.Suggestion)
+		}
+		return
+	}
+
+	for _, c := range checks {
+		_, _ = lipgloss.Println(doctorStyles[c.Status].String(), doctorName.Render(c.Name), c.Detail)
+		if c.Suggestion != "" && c.Status != runner.DoctorOK {
+			_, _ = lipgloss.Println(doctorSuggestion.Render("→ " + c.Suggestion))
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
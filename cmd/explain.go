@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd 输出类型的提供者链.
+var explainCmd = &cobra.Command{
+	Use:   "explain <Type>",
+	Short: "输出类型的提供者链，不生成任何文件",
+	Long: `扫描 @autowire 注解，输出提供指定类型的组件所在的 Set、提供方式与构造函数，
+并递归列出每个构造函数参数（或 wire.Struct 注入字段）由哪个组件提供，
+用于排查 wire 为什么选择了某个实现。
+
+类型名称支持 包路径.类型名、包名.类型名 以及单独的类型名，匹配多个类型时需要使用完整路径。
+重复绑定按配置的策略处理后再输出；同一类型存在多个提供者时全部列出。
+
+示例:
+  gutowire explain svc.Store                          # 树形式输出 svc.Store 的提供者链
+  gutowire explain example.com/app/svc.Store          # 使用完整路径
+  gutowire explain svc.Store --output=json            # 输出 JSON`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeComponents,
+	RunE: func(_ *cobra.Command, args []string) error {
+		sc, err := scanProject()
+		if err != nil {
+			return err
+		}
+		// 校验失败时仍然输出，便于排查重复提供者等问题
+		err = sc.Prepare()
+		if err == nil {
+			err = sc.Validate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "! 校验未通过，输出的提供者链可能与生成结果不一致: "+err.Error())
+		}
+
+		e, err := sc.Explain(args[0])
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return generator.WriteExplanationJSON(os.Stdout, e)
+		}
+		return generator.WriteExplanation(os.Stdout, e)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/graph"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFocus       string
+	graphDepth       int
+	graphDirection   string
+	graphExcludeSets []string
+	graphOutput      string
+	graphFormat      string
+)
+
+// graphCmd 输出组件依赖图.
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "输出组件依赖图",
+	Long: `扫描 @autowire 注解并输出组件之间的依赖图，支持 Graphviz DOT 与 Mermaid 格式。
+
+示例:
+  gutowire graph                                   # 输出完整依赖图
+  gutowire graph --format mermaid                  # 输出 Mermaid 格式
+  gutowire graph --output json                     # 输出 JSON 格式（节点与边）
+  gutowire graph --focus zoo.Zoo --depth 2         # 只输出 zoo.Zoo 两层以内的子图
+  gutowire graph --focus zoo.Zoo --direction deps  # 只输出 zoo.Zoo 依赖的组件
+  gutowire graph --exclude-set mocks -o deps.dot   # 排除 mocks Set 并写入文件`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// JSON 输出模式下未指定格式时输出 JSON
+		if jsonOutput() && !cmd.Flags().Changed("format") {
+			graphFormat = graph.FormatJSON
+		}
+		switch graphFormat {
+		case graph.FormatDOT, graph.FormatMermaid, graph.FormatJSON:
+		default:
+			return fmt.Errorf("不支持的输出格式: %s（可选 dot、mermaid、json）", graphFormat)
+		}
+		dir := graph.Direction(graphDirection)
+		switch dir {
+		case graph.DirectionDeps, graph.DirectionDependents, graph.DirectionBoth:
+		default:
+			return fmt.Errorf("无效的遍历方向: %s（可选 deps、dependents、both）", graphDirection)
+		}
+
+		g, err := scanGraph()
+		if err != nil {
+			return err
+		}
+
+		g, err = g.Filter(graph.FilterOptions{
+			Focus:       graphFocus,
+			Depth:       graphDepth,
+			Direction:   dir,
+			ExcludeSets: graphExcludeSets,
+		})
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if graphOutput != "" {
+			//nolint:gosec
+			f, err := os.Create(graphOutput)
+			if err != nil {
+				return fmt.Errorf("创建输出文件失败: %w", err)
+			}
+			//nolint:errcheck
+			defer f.Close()
+			w = f
+		}
+		return g.Write(w, graphFormat)
+	},
+}
+
+// scanGraph function    按命令行参数与配置文件扫描注解并构建依赖图.
+func scanGraph() (*graph.Graph, error) {
+	sc, err := scanProject()
+	if err != nil {
+		return nil, err
+	}
+	return graph.Build(sc.ElementMap), nil
+}
+
+// scanProject function    按命令行参数与配置文件完整扫描注解，不写入任何文件
+// 日志输出到标准错误，避免干扰标准输出中的数据.
+func scanProject() (*generator.AutoWireSearcher, error) {
+	return scanProjectWith(newLogger(os.Stderr, commandLevel(slog.LevelWarn)))
+}
+
+// scanProjectWith function    与 scanProject 相同，使用指定的日志器.
+func scanProjectWith(log *slog.Logger) (*generator.AutoWireSearcher, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, _ := buildOptions(cfg)
+	// 旧版本缓存中没有依赖信息，这里总是完整扫描
+	opts = append(opts,
+		config.WithCache(false),
+		config.WithLogger(log),
+	)
+
+	genPath := resolveWirePath(nil, cfg)
+	if genPath == "" {
+		genPath = "."
+	}
+
+	return runner.Scan(genPath, opts...)
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFocus, "focus", "", "聚焦的组件，如 pkg.Zoo，只输出与其相关的子图")
+	graphCmd.Flags().IntVar(&graphDepth, "depth", 0, "聚焦时遍历的最大深度，0 表示不限制")
+	graphCmd.Flags().StringVar(&graphDirection, "direction", string(graph.DirectionBoth),
+		"聚焦时的遍历方向: deps（依赖）、dependents（被依赖）、both")
+	graphCmd.Flags().StringSliceVar(&graphExcludeSets, "exclude-set", nil, "排除的 Set 名称，可重复指定")
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", graph.FormatDOT, "输出格式: dot、mermaid、json")
+	graphCmd.Flags().StringVarP(&graphOutput, "output-file", "o", "", "输出文件路径，默认输出到标准输出")
+	_ = graphCmd.RegisterFlagCompletionFunc("focus", completeComponents)
+	_ = graphCmd.RegisterFlagCompletionFunc("exclude-set", completeSets)
+	_ = graphCmd.RegisterFlagCompletionFunc("direction", completeValues(string(graph.DirectionDeps),
+		string(graph.DirectionDependents), string(graph.DirectionBoth)))
+	_ = graphCmd.RegisterFlagCompletionFunc("format", completeValues(graph.FormatDOT, graph.FormatMermaid,
+		graph.FormatJSON))
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/exp/charmtone"
+	"github.com/charmbracelet/x/term"
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initYes   bool
+	initForce bool
+)
+
+// initCmd 交互式生成配置文件.
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "交互式生成配置文件 .gutowire.yaml",
+	Long: `检测 go.mod 与项目结构，给出搜索路径、生成路径、包名的建议，并列出已有注解中的 Set，
+逐项确认或修改后写入配置文件（默认 .gutowire.yaml，可以通过 --config 指定）。
+
+已有生成文件或 wire 注入器（//go:build wireinject）的目录作为建议的生成路径，
+没有注解时按包名给出 Set 名称的建议。标准输入或标准输出不是终端、--yes 以及 --output=json 时
+不进入向导，直接按建议写入。
+
+示例:
+  gutowire init            # 交互式向导
+  gutowire init --yes      # 按建议直接生成
+  gutowire init --force    # 覆盖已有的配置文件`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		path := cmp.Or(configFile, ".gutowire.yaml")
+		if _, err := os.Stat(path); err == nil && !initForce {
+			return fmt.Errorf("配置文件 %s 已存在，使用 --force 覆盖", path)
+		}
+		p, err := config.Propose(".")
+		if err != nil {
+			return fmt.Errorf("检测项目结构失败: %w", err)
+		}
+
+		if !initYes && !jsonOutput() && term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd()) {
+			m, err := tea.NewProgram(newInitWizard(p, path)).Run()
+			if err != nil {
+				return fmt.Errorf("运行初始化向导失败: %w", err)
+			}
+			w := m.(initWizard)
+			if !w.done {
+				fmt.Println("已取消，未写入配置文件")
+				return nil
+			}
+			p = w.proposal()
+		}
+
+		if err := p.Config().SaveConfigFile(path); err != nil {
+			return fmt.Errorf("生成配置文件失败: %w", err)
+		}
+		printResult("配置文件已生成", "path", path, "output_path", p.OutputPath, "package", p.Package)
+		if !jsonOutput() && len(p.Sets) > 0 && !p.Annotated {
+			fmt.Printf("\n为组件添加注解后运行 gutowire 生成代码，如 // %s(set=%s)\n", config.WireTag, p.Sets[0])
+		}
+		return nil
+	},
+}
+
+var (
+	wizardTitle  = lipgloss.NewStyle().Bold(true).Foreground(charmtone.Coral)
+	wizardLabel  = lipgloss.NewStyle().Width(10)
+	wizardActive = lipgloss.NewStyle().Foreground(charmtone.Guac)
+	wizardNote   = lipgloss.NewStyle().Foreground(charmtone.Squid)
+	wizardError  = lipgloss.NewStyle().Foreground(charmtone.Cherry)
+)
+
+// 向导中可以编辑的字段.
+const (
+	fieldSearchPath = iota
+	fieldOutputPath
+	fieldPackage
+	fieldCount
+)
+
+// initWizard struct    初始化向导：逐项编辑建议的配置，最后一项回车后写入.
+type initWizard struct {
+	base      config.Proposal    // 检测到的建议
+	path      string             // 配置文件路径
+	values    [fieldCount]string // 各字段当前的值
+	focus     int                // 正在编辑的字段
+	pkgEdited bool               // 包名是否被修改过，未修改时随生成路径变化
+	err       string             // 当前字段的校验错误
+	done      bool               // 是否确认写入
+}
+
+// newInitWizard function    使用检测到的建议创建向导.
+func newInitWizard(p config.Proposal, path string) initWizard {
+	w := initWizard{base: p, path: path}
+	w.values[fieldSearchPath] = p.SearchPath
+	w.values[fieldOutputPath] = p.OutputPath
+	w.values[fieldPackage] = p.Package
+	return w
+}
+
+// proposal method    返回按向导输入修改后的建议.
+func (w initWizard) proposal() config.Proposal {
+	p := w.base
+	p.SearchPath = w.values[fieldSearchPath]
+	p.OutputPath = w.values[fieldOutputPath]
+	p.Package = w.values[fieldPackage]
+	return p
+}
+
+func (w initWizard) Init() tea.Cmd {
+	return nil
+}
+
+func (w initWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return w, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "esc":
+		return w, tea.Quit
+	case "enter":
+		if w.err = w.validate(); w.err != "" {
+			return w, nil
+		}
+		if w.focus == fieldCount-1 {
+			w.done = true
+			return w, tea.Quit
+		}
+		w.focus++
+	case "tab", "down":
+		if w.err = w.validate(); w.err == "" {
+			w.focus = (w.focus + 1) % fieldCount
+		}
+	case "shift+tab", "up":
+		if w.err = w.validate(); w.err == "" {
+			w.focus = (w.focus + fieldCount - 1) % fieldCount
+		}
+	case "backspace":
+		runes := []rune(w.values[w.focus])
+		if len(runes) > 0 {
+			w.edit(string(runes[:len(runes)-1]))
+		}
+	default:
+		if key.Text != "" {
+			w.edit(w.values[w.focus] + key.Text)
+		}
+	}
+	return w, nil
+}
+
+// edit method    修改当前字段；包名未修改过时随生成路径的目录名变化.
+func (w *initWizard) edit(value string) {
+	w.values[w.focus], w.err = value, ""
+	switch w.focus {
+	case fieldPackage:
+		w.pkgEdited = true
+	case fieldOutputPath:
+		if !w.pkgEdited {
+			w.values[fieldPackage] = packageName(value)
+		}
+	}
+}
+
+// validate method    校验当前字段，返回错误描述.
+func (w initWizard) validate() string {
+	value := strings.TrimSpace(w.values[w.focus])
+	switch {
+	case value == "":
+		return "不能为空"
+	case w.focus == fieldPackage && !token.IsIdentifier(value):
+		return "不是有效的包名"
+	}
+	return ""
+}
+
+func (w initWizard) View() tea.View {
+	var b strings.Builder
+	b.WriteString(wizardTitle.Render("gutowire 初始化向导") + "\n\n")
+	if w.base.ModulePath != "" {
+		b.WriteString(wizardNote.Render("模块 "+w.base.ModulePath+"（"+w.base.ModuleDir+"）") + "\n\n")
+	} else {
+		b.WriteString(wizardError.Render("未找到 go.mod，生成前需要先执行 go mod init") + "\n\n")
+	}
+
+	labels := [fieldCount]string{"搜索路径", "生成路径", "包名"}
+	for i, label := range labels {
+		line := wizardLabel.Render(label) + " " + w.values[i]
+		if i == w.focus {
+			line = wizardActive.Render("> "+wizardLabel.Render(label)) + " " + w.values[i] + "█"
+			if w.err != "" {
+				line += "  " + wizardError.Render(w.err)
+			}
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	if len(w.base.Sets) == 0 {
+		b.WriteString(wizardNote.Render("没有找到 Go 包，添加组件后使用 @autowire(set=xxx) 注册到 Set") + "\n")
+	} else {
+		note := "建议的 Set（按包名）: "
+		if w.base.Annotated {
+			note = "已有注解中的 Set: "
+		}
+		b.WriteString(wizardNote.Render(note+strings.Join(w.base.Sets, "、")) + "\n")
+	}
+	b.WriteString(wizardNote.Render("回车确认 · tab/↑↓ 切换 · esc 取消，最后一项回车后写入 "+w.path) + "\n")
+	return tea.NewView(b.String())
+}
+
+// packageName function    返回生成路径对应的包名：目录名去掉非法字符，无法使用时为 wire.
+func packageName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, filepath.Base(filepath.Clean(dir)))
+	if !token.IsIdentifier(name) {
+		return "wire"
+	}
+	return name
+}
+
+func init() {
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "不进入向导，按检测到的建议直接生成")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "覆盖已有的配置文件")
+	rootCmd.AddCommand(initCmd)
+}
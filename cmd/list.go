@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var listSets []string
+
+// listCmd 列出扫描到的组件.
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出扫描到的全部组件，不生成任何文件",
+	Long: `扫描 @autowire 注解并列出每个组件的 Set、构造函数、绑定的接口、包路径与源码位置，
+用于在大规模重构后审查实际会被装配的组件。
+
+示例:
+  gutowire list                   # 表格形式输出全部组件
+  gutowire list --set animals     # 只输出指定 Set 中的组件
+  gutowire list --output=json     # 输出 JSON 数组`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		sc, err := scanProject()
+		if err != nil {
+			return err
+		}
+		comps := sc.Components(listSets...)
+		if jsonOutput() {
+			return generator.WriteComponentsJSON(os.Stdout, comps)
+		}
+		return generator.WriteComponents(os.Stdout, comps)
+	},
+}
+
+func init() {
+	listCmd.Flags().StringSliceVar(&listSets, "set", nil, "只列出指定 Set 中的组件，可重复指定")
+	_ = listCmd.RegisterFlagCompletionFunc("set", completeSets)
+	rootCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/migrate"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var migrateDryRun bool
+
+// migrateCmd 将手写的 wire.NewSet 转换为注解.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <wire 文件或目录>...",
+	Short: "将手写的 wire.NewSet 提供者集合转换为 @autowire 注解",
+	Long: `解析已有的 wire.NewSet 声明，找到每个提供者对应的构造函数、结构体或变量，
+在其声明前插入 @autowire(set=...) 注解：
+
+  构造函数 NewX              -> @autowire(set=...)
+  wire.Struct(new(X), ...)   -> @autowire(set=...[,fields=A|B])
+  wire.Bind(new(I), new(*X)) -> 在提供 X 的注解中添加接口 I
+  wire.Value(X)              -> @autowire.value(set=...)
+
+Set 名称取变量名去掉 Set 后缀（ProviderSet 使用包名）。目录会递归查找其中的 Go 文件，
+生成的文件（包括 gutowire 生成的 Set）被跳过。无法转换的提供者会输出原因，需要手动处理；
+确认重新生成的结果后删除原有的 wire.NewSet 声明。
+
+示例:
+  gutowire migrate --dry-run ./internal/wire.go   # 只输出 diff，不修改文件
+  gutowire migrate ./internal                     # 转换目录下的全部 wire.NewSet`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		files, err := wireFiles(args)
+		if err != nil {
+			return err
+		}
+
+		plan, err := migrate.Analyze(files, cfg.AnnotationTag)
+		if err != nil {
+			return err
+		}
+		printSkipped(plan.Skipped)
+
+		if migrateDryRun {
+			return plan.Diff(os.Stdout)
+		}
+		if err := plan.Apply(); err != nil {
+			return err
+		}
+		printResult(fmt.Sprintf("已插入 %d 个注解", len(plan.Insertions)), "files", len(plan.Files()))
+		if len(plan.Sets) > 0 && !jsonOutput() {
+			fmt.Fprintln(os.Stderr, "! 重新生成并确认结果后，删除原有的 Set: "+strings.Join(plan.Sets, ", "))
+		}
+		return nil
+	},
+}
+
+// wireFiles function    展开参数中的目录，返回全部非测试 Go 文件.
+func wireFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.Walk(arg, func(path string, f os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if f.IsDir() {
+				if path != arg && (f.Name() == "vendor" || f.Name() == "testdata" || strings.HasPrefix(f.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if parser.CheckFileType(f.Name()) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// printSkipped function    输出无法自动转换的提供者
+// 文本模式输出到标准错误，JSON 模式输出 warning 事件到标准输出.
+func printSkipped(skipped []migrate.Skipped) {
+	for _, s := range skipped {
+		msg := fmt.Sprintf("%s: %s 未转换: %s", s.Position, s.Expr, s.Reason)
+		if jsonOutput() {
+			newLogger(os.Stdout, slog.LevelInfo).Warn(msg, logger.EventKey, logger.EventWarning)
+			continue
+		}
+		fmt.Fprintln(os.Stderr, "! "+msg)
+	}
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "只输出 unified diff，不修改文件")
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/charmbracelet/fang"
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+// 输出模式.
+const (
+	outputText = "text" // 面向终端的文本输出
+	outputJSON = "json" // 每行一个 JSON 对象的结构化事件
+)
+
+// jsonOutput function    是否为 JSON 输出模式.
+func jsonOutput() bool {
+	return outputMode == outputJSON
+}
+
+// validateOutput function    校验 --output 参数.
+func validateOutput() error {
+	if outputMode != outputText && outputMode != outputJSON {
+		return fmt.Errorf("不支持的输出模式: %s（可选 %s、%s）", outputMode, outputText, outputJSON)
+	}
+	return nil
+}
+
+// logLevel function    返回生成过程的日志级别
+// --verbose 输出调试日志，--quiet 只输出警告与错误，否则使用配置文件的 log_level；
+// 都未指定时文本模式为 info，JSON 模式为 debug（保留每个组件的 element 事件）.
+func logLevel(cfg *config.FileConfig) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelWarn
+	case cfg != nil && cfg.LogLevel != "":
+		if level, err := config.ParseLogLevel(cfg.LogLevel); err == nil {
+			return level
+		}
+	case jsonOutput():
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// commandLevel function    返回子命令的日志级别，--verbose 输出调试日志，--quiet 至少为 warn.
+func commandLevel(def slog.Level) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return max(def, slog.LevelWarn)
+	}
+	return def
+}
+
+// newLogger function    按输出模式创建日志器.
+func newLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	if jsonOutput() {
+		return logger.NewJSON(w, level)
+	}
+	return logger.New(w, level)
+}
+
+// printResult function    输出命令执行成功的结果
+// 文本模式打印带 ✓ 的提示，JSON 模式输出 result 事件.
+func printResult(msg string, attrs ...any) {
+	if !jsonOutput() {
+		fmt.Println("✓ " + msg)
+		return
+	}
+	attrs = append([]any{logger.EventKey, logger.EventResult, "status", "ok"}, attrs...)
+	newLogger(os.Stdout, slog.LevelInfo).Info(msg, attrs...)
+}
+
+// errorAttrs function    返回错误事件的属性，FriendlyError 的各字段单独输出.
+func errorAttrs(err error) []any {
+	attrs := []any{logger.EventKey, logger.EventError}
+	var fe *errors.FriendlyError
+	if !stderrors.As(err, &fe) {
+		return append(attrs, "error", err.Error())
+	}
+	return append(attrs,
+		"error", err.Error(),
+		slog.Group("friendly",
+			"type", fe.Type.String(),
+			"message", fe.Message,
+			"details", fe.Details,
+			"sources", fe.Sources,
+			"suggestions", fe.Suggestions,
+			"help_url", fe.HelpURL,
+		),
+	)
+}
+
+// handleError function    命令返回错误时的处理
+// JSON 模式输出 error 事件到标准输出，否则使用 fang 默认的样式输出.
+func handleError(w io.Writer, styles fang.Styles, err error) {
+	if !jsonOutput() {
+		fang.DefaultErrorHandler(w, styles, err)
+		return
+	}
+	newLogger(os.Stdout, slog.LevelInfo).Error("命令执行失败", errorAttrs(err)...)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+const (
+	progressDelay    = 500 * time.Millisecond // 扫描超过该时间才显示进度条，避免小项目闪烁
+	progressInterval = 100 * time.Millisecond // 进度条的最小刷新间隔
+	progressWidth    = 30                     // 进度条的宽度（字符数）
+)
+
+// progressBar struct    在终端中显示扫描进度与预计剩余时间，输出到标准错误.
+type progressBar struct {
+	w     io.Writer
+	start time.Time
+
+	mu    sync.Mutex
+	last  time.Time // 上次刷新的时间
+	shown bool      // 是否已显示（完成时需要清除）
+}
+
+// progressOption function    返回显示扫描进度条的配置
+// 只在文本模式、非安静模式且标准错误为终端时显示，重定向到文件或管道时不输出控制字符.
+func progressOption() (config.Option, bool) {
+	if jsonOutput() || quiet || !term.IsTerminal(os.Stderr.Fd()) {
+		return nil, false
+	}
+	p := &progressBar{w: os.Stderr}
+	return config.WithProgress(p.update), true
+}
+
+// update method    处理扫描进度：done 为 0 时开始计时，之后按刷新间隔重绘，全部完成时清除进度条.
+func (p *progressBar) update(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if done == 0 {
+		p.start, p.last, p.shown = now, time.Time{}, false
+		return
+	}
+	if done >= total {
+		if p.shown {
+			_, _ = fmt.Fprint(p.w, "\r\033[K")
+			p.shown = false
+		}
+		return
+	}
+	if now.Sub(p.start) < progressDelay || now.Sub(p.last) < progressInterval {
+		return
+	}
+	p.last, p.shown = now, true
+	_, _ = fmt.Fprint(p.w, "\r\033[K"+renderProgress(done, total, now.Sub(p.start)))
+}
+
+// renderProgress function    返回进度条的文本，如 扫描 [#########-----] 4200/10000  42%  剩余 35s.
+func renderProgress(done, total int, elapsed time.Duration) string {
+	filled := progressWidth * done / total
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressWidth-filled)
+	remaining := time.Duration(float64(elapsed) * float64(total-done) / float64(done)).Round(time.Second)
+	return fmt.Sprintf("扫描 [%s] %d/%d %3d%%  剩余 %s", bar, done, total, done*100/total, remaining)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportHTML      string
+	reportSourceURL string
+	reportTitle     string
+)
+
+// reportCmd 输出依赖注入报告.
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "输出依赖注入报告",
+	Long: `扫描 @autowire 注解并输出可浏览的 HTML 报告：各 Set 的组件与提供方式、接口绑定、
+注入入口及其依赖链上的组件、没有提供者的类型，组件链接到源码位置，便于架构评审时分享。
+
+默认使用 file:// 链接源文件；通过 --source-url 指定代码托管平台的链接模板，
+{file} 替换为相对当前目录的文件路径，{line} 替换为行号。
+
+示例:
+  gutowire report --html report.html            # 写入 report.html
+  gutowire report --html -                      # 输出到标准输出
+  gutowire report --html report.html \
+    --source-url 'https://github.com/org/app/blob/main/{file}#L{line}'`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if reportHTML == "" {
+			return fmt.Errorf("需要通过 --html 指定报告文件，- 表示输出到标准输出")
+		}
+		sc, err := scanProject()
+		if err != nil {
+			return err
+		}
+		// 校验失败时仍然输出，报告中会列出没有提供者的类型
+		err = sc.Prepare()
+		if err == nil {
+			err = sc.Validate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "! 校验未通过，报告可能与生成结果不一致: "+err.Error())
+		}
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		opts := generator.ReportOptions{Title: reportTitle, BaseDir: wd, SourceURL: reportSourceURL}
+
+		var w io.Writer = os.Stdout
+		if reportHTML != "-" {
+			//nolint:gosec
+			f, err := os.Create(reportHTML)
+			if err != nil {
+				return fmt.Errorf("创建报告文件失败: %w", err)
+			}
+			//nolint:errcheck
+			defer f.Close()
+			w = f
+		}
+		if err := generator.WriteReportHTML(w, sc.Report(), opts); err != nil {
+			return fmt.Errorf("输出报告失败: %w", err)
+		}
+		if reportHTML != "-" {
+			fmt.Fprintln(os.Stderr, "报告已写入 "+reportHTML)
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportHTML, "html", "", "HTML 报告的输出路径，- 表示输出到标准输出")
+	reportCmd.Flags().StringVar(&reportSourceURL, "source-url", "",
+		"源码链接模板，支持 {file} 与 {line}，默认使用 file:// 链接")
+	reportCmd.Flags().StringVar(&reportTitle, "title", "", "报告标题")
+	_ = reportCmd.RegisterFlagCompletionFunc("html", completeHTMLFiles)
+	_ = reportCmd.RegisterFlagCompletionFunc("source-url", cobra.NoFileCompletions)
+	_ = reportCmd.RegisterFlagCompletionFunc("title", cobra.NoFileCompletions)
+	rootCmd.AddCommand(reportCmd)
+}
@@ -12,6 +12,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/colorprofile"
@@ -30,13 +31,36 @@ const (
 )
 
 var (
-	wirePath   string
-	scope      string
-	pkg        string
-	configFile string
-	watch      bool
-	noCache    bool
-	initConfig bool
+	wirePath    string
+	scope       []string
+	pkg         string
+	configFile  string
+	watch       bool
+	noCache     bool
+	noIgnore    bool
+	noSum       bool
+	initConfig  bool
+	lockTimeout time.Duration
+	wireVersion string
+	wireTags    string
+	wireMode    string
+	missing     string
+	backend     string
+	outputMode  string
+	strict      bool
+	checkOnly   bool
+	showDiff    bool
+	profile     string
+	goos        string
+	goarch      string
+	buildTags   []string
+	verbose     bool
+	quiet       bool
+	chdir       string
+	plugins     []string
+
+	// runCtx 命令的 ctx，收到 Ctrl+C 时取消，终止进行中的扫描、生成与 wire 命令
+	runCtx = context.Background()
 )
 
 // rootCmd represents the base command when called without any subcommands.
@@ -49,8 +73,25 @@ var rootCmd = &cobra.Command{
 示例:
   gutowire ./wire                    # 生成到 ./wire 目录
   gutowire --watch ./wire            # Watch 模式
-  gutowire --init                    # 生成配置文件
-  gutowire --config=.gutowire.yaml   # 使用配置文件`,
+  gutowire init                      # 交互式生成配置文件
+  gutowire --init                    # 生成默认配置文件
+  gutowire --config=.gutowire.yaml   # 使用配置文件
+  gutowire --output=json ./wire      # 输出 JSON 格式的结构化事件`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if ctx := cmd.Context(); ctx != nil {
+			runCtx = ctx
+		}
+		// 先切换工作目录，之后的相对路径（配置文件、生成路径、搜索路径）都相对该目录
+		if chdir != "" {
+			if err := os.Chdir(chdir); err != nil {
+				return fmt.Errorf("切换工作目录失败: %w", err)
+			}
+		}
+		if verbose && quiet {
+			return fmt.Errorf("--verbose 不能与 --quiet 同时使用")
+		}
+		return validateOutput()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -61,73 +102,61 @@ var rootCmd = &cobra.Command{
 		}
 
 		// 加载配置文件
-		cfg, err := config.LoadConfigFile(configFile)
+		cfg, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("加载配置文件失败: %w", err)
+			return err
 		}
 
 		// 构建配置选项（命令行参数优先级高于配置文件）
-		var opts []config.Option
-
-		// 应用包名配置
-		if pkg != "" {
-			opts = append(opts, config.WithPkg(pkg))
-		} else if cfg.Package != "" {
-			opts = append(opts, config.WithPkg(cfg.Package))
-		}
-
-		// 应用搜索路径配置
-		searchPath := scope
-		if searchPath == "" && cfg.SearchPath != "" {
-			searchPath = cfg.SearchPath
-		}
-		if searchPath != "" {
-			opts = append(opts, config.WithSearchPath(searchPath))
-		}
-
-		// 应用缓存配置（命令行 --no-cache 优先级最高）
-		enableCache := cfg.EnableCache
-		if noCache {
-			enableCache = false
-		}
-		opts = append(opts, config.WithCache(enableCache))
-
-		// 应用排除目录配置
-		if len(cfg.ExcludeDirs) > 0 {
-			opts = append(opts, config.WithExcludeDirs(cfg.ExcludeDirs))
-		}
-
-		// 从位置参数或标志或配置文件获取生成路径
-		if wirePath == "" && len(args) > 0 {
-			wirePath = args[0]
-		}
-		if wirePath == "" && cfg.OutputPath != "" {
-			wirePath = cfg.OutputPath
+		opts, searchPaths := buildOptions(cfg)
+
+		// 配置了 targets 且未指定生成路径时批量生成到每个目标
+		if len(cfg.Targets) > 0 && wirePath == "" && len(args) == 0 {
+			if showDiff || watch || cfg.Watch {
+				return fmt.Errorf("配置了 targets 时不支持 --diff 与 watch 模式，请指定单个生成路径")
+			}
+			return handleTargets(cfg.Targets, opts)
 		}
+		genPath := resolveWirePath(args, cfg)
 
 		// 验证必需参数
-		if wirePath == "" {
+		if genPath == "" {
 			return fmt.Errorf("必须指定 Wire 配置文件生成路径\n使用方式: %s [flags] <生成路径>", commandName)
 		}
 
-		// 添加初始化配置
-		if len(cfg.InitTypes) > 0 {
-			opts = append(opts, config.InitStruct(cfg.InitTypes...))
-		} else {
-			opts = append(opts, config.InitStruct())
+		// diff 模式：输出重新生成会造成的修改，与 --check-only 一起使用时有修改则以非零状态退出
+		if showDiff {
+			if watch || cfg.Watch {
+				return fmt.Errorf("--diff 不能与 watch 模式同时使用")
+			}
+			return handleDiff(genPath, opts)
+		}
+
+		// 检查模式：生成的代码不是最新时以非零状态退出
+		if checkOnly {
+			if watch || cfg.Watch {
+				return fmt.Errorf("--check-only 不能与 watch 模式同时使用")
+			}
+			if err := runner.RunAutoWire(genPath, append(opts, config.WithCheckOnly(true))...); err != nil {
+				return err
+			}
+			printResult("生成的代码已是最新", "path", genPath)
+			return nil
 		}
 
 		// Watch 模式
 		if watch || cfg.Watch {
-			return handleWatch(wirePath, searchPath, opts)
+			return handleWatch(genPath, searchPaths, opts)
 		}
 
 		// 执行自动装配
-		if err := runner.RunAutoWire(wirePath, opts...); err != nil {
+		summary, err := runner.RunAutoWireSummary(genPath, opts...)
+		if err != nil {
 			return fmt.Errorf("自动装配失败: %w", err)
 		}
 
-		fmt.Println("✓ Wire 配置文件生成成功")
+		printSummary(summary)
+		printResult("自动装配代码生成成功", "path", genPath)
 		return nil
 	},
 }
@@ -159,11 +188,229 @@ func Execute() {
 		rootCmd,
 		fang.WithVersion(version.Version),
 		fang.WithNotifySignal(os.Interrupt),
+		fang.WithErrorHandler(handleError),
 	); err != nil {
 		os.Exit(1)
 	}
 }
 
+// loadConfig function    加载配置文件并应用 --profile 选择的配置档.
+func loadConfig() (*config.FileConfig, error) {
+	cfg, err := config.LoadConfigFile(configFile, profile)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置文件失败: %w", err)
+	}
+	if cfg.LogLevel != "" {
+		if _, err := config.ParseLogLevel(cfg.LogLevel); err != nil {
+			return nil, fmt.Errorf("配置文件 log_level 无效: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// buildOptions function    根据命令行参数与配置文件构建生成选项
+// 命令行参数优先级高于配置文件，同时返回生效的搜索路径.
+func buildOptions(cfg *config.FileConfig) ([]config.Option, []string) {
+	// 构建配置选项（命令行参数优先级高于配置文件），收到 Ctrl+C 时终止生成
+	opts := []config.Option{config.WithContext(runCtx)}
+
+	// 应用包名配置
+	if pkg != "" {
+		opts = append(opts, config.WithPkg(pkg))
+	} else if cfg.Package != "" {
+		opts = append(opts, config.WithPkg(cfg.Package))
+	}
+
+	// 应用搜索路径配置（--scope 可重复指定）
+	searchPaths := scope
+	if len(searchPaths) == 0 {
+		searchPaths = cfg.Searches()
+	}
+	if len(searchPaths) > 0 {
+		opts = append(opts, config.WithSearchPaths(searchPaths...))
+	}
+
+	// 应用缓存配置（命令行 --no-cache 优先级最高）
+	enableCache := cfg.EnableCache
+	if noCache {
+		enableCache = false
+	}
+	opts = append(opts, config.WithCache(enableCache))
+
+	// 应用忽略文件配置（命令行 --no-ignore-files 优先级最高）
+	opts = append(opts, config.WithIgnoreFiles(cfg.IgnoreFiles && !noIgnore))
+
+	// 应用生成校验文件配置（--no-sum 时不写入 gutowire.sum）
+	if noSum {
+		opts = append(opts, config.WithSumFile(false))
+	}
+
+	// 应用生成目录锁超时配置
+	if lockTimeout > 0 {
+		opts = append(opts, config.WithLockTimeout(lockTimeout))
+	} else if cfg.LockTimeout > 0 {
+		opts = append(opts, config.WithLockTimeout(cfg.LockTimeout))
+	}
+
+	// 应用重复接口绑定策略
+	if cfg.DuplicateBinding != "" {
+		opts = append(opts, config.WithDuplicateBinding(cfg.DuplicateBinding))
+	}
+	if cfg.ConflictPolicy != "" {
+		opts = append(opts, config.WithConflictPolicy(cfg.ConflictPolicy))
+	}
+
+	// 应用缺少提供者的处理方式
+	if missing != "" {
+		opts = append(opts, config.WithMissingProviders(missing))
+	} else if cfg.MissingProviders != "" {
+		opts = append(opts, config.WithMissingProviders(cfg.MissingProviders))
+	}
+
+	// 应用并发数限制
+	if cfg.Parallel > 0 {
+		opts = append(opts, config.WithParallel(cfg.Parallel))
+	}
+
+	// 应用 Set 输出目录
+	if len(cfg.SetOutputs) > 0 {
+		opts = append(opts, config.WithSetOutputs(cfg.SetOutputs))
+	}
+
+	// 应用 Set 的构建标签
+	if len(cfg.SetTags) > 0 {
+		opts = append(opts, config.WithSetTags(cfg.SetTags))
+	}
+
+	// 应用 Set 文件的构建约束
+	if len(cfg.SetBuildTags) > 0 {
+		opts = append(opts, config.WithSetBuildTags(cfg.SetBuildTags))
+	}
+
+	// 应用评估构建约束的目标平台与构建标签（命令行优先）
+	targetOS, targetArch := cfg.GOOS, cfg.GOARCH
+	if goos != "" {
+		targetOS = goos
+	}
+	if goarch != "" {
+		targetArch = goarch
+	}
+	if targetOS != "" || targetArch != "" {
+		opts = append(opts, config.WithTarget(targetOS, targetArch))
+	}
+	if len(buildTags) > 0 {
+		opts = append(opts, config.WithBuildTags(buildTags...))
+	} else if len(cfg.BuildTags) > 0 {
+		opts = append(opts, config.WithBuildTags(cfg.BuildTags...))
+	}
+
+	// 应用固定的 wire 版本
+	if wireVersion != "" {
+		opts = append(opts, config.WithWireVersion(wireVersion))
+	} else if cfg.WireVersion != "" {
+		opts = append(opts, config.WithWireVersion(cfg.WireVersion))
+	}
+
+	// 应用运行 wire 时的构建标签
+	if wireTags != "" {
+		opts = append(opts, config.WithWireTags(wireTags))
+	} else if cfg.WireTags != "" {
+		opts = append(opts, config.WithWireTags(cfg.WireTags))
+	}
+
+	// 应用运行 wire 的方式
+	if wireMode != "" {
+		opts = append(opts, config.WithWireMode(wireMode))
+	} else if cfg.WireMode != "" {
+		opts = append(opts, config.WithWireMode(cfg.WireMode))
+	}
+
+	// 应用依赖注入后端
+	if backend != "" {
+		opts = append(opts, config.WithBackend(backend))
+	} else if cfg.Backend != "" {
+		opts = append(opts, config.WithBackend(cfg.Backend))
+	}
+
+	// 应用注解标记
+	if cfg.AnnotationTag != "" {
+		opts = append(opts, config.WithTag(cfg.AnnotationTag))
+	}
+
+	// 应用严格模式
+	if strict || cfg.Strict {
+		opts = append(opts, config.WithStrict(true))
+	}
+
+	// 应用初始化文件模板
+	if cfg.InitTemplate != "" {
+		opts = append(opts, config.WithInitTemplate(cfg.InitTemplate))
+	}
+
+	// 应用生成文件的命名与头部注释
+	if cfg.FilePrefix != "" || cfg.FileSuffix != "" {
+		opts = append(opts, config.WithFileNaming(cfg.FilePrefix, cfg.FileSuffix))
+	}
+	if cfg.HeaderTemplate != "" {
+		opts = append(opts, config.WithHeaderTemplate(cfg.HeaderTemplate))
+	}
+
+	// 应用代码生成插件（命令行 --plugin 优先）
+	if len(plugins) > 0 {
+		opts = append(opts, config.WithPlugins(plugins...))
+	} else if len(cfg.Plugins) > 0 {
+		opts = append(opts, config.WithPlugins(cfg.Plugins...))
+	}
+
+	// 应用生成文件扫描配置
+	if cfg.IncludeGenerated {
+		opts = append(opts, config.WithIncludeGenerated(cfg.GeneratedGlobs...))
+	}
+
+	// 应用排除目录配置
+	if len(cfg.ExcludeDirs) > 0 {
+		opts = append(opts, config.WithExcludeDirs(cfg.ExcludeDirs))
+	}
+
+	// 应用只扫描的目录配置
+	if len(cfg.IncludeOnly) > 0 {
+		opts = append(opts, config.WithIncludeOnly(cfg.IncludeOnly...))
+	}
+
+	// 应用最大目录深度配置
+	if cfg.MaxDepth > 0 {
+		opts = append(opts, config.WithMaxDepth(cfg.MaxDepth))
+	}
+
+	// 添加初始化配置
+	if len(cfg.InitTypes) > 0 {
+		opts = append(opts, config.InitStruct(cfg.InitTypes...))
+	} else {
+		opts = append(opts, config.InitStruct())
+	}
+
+	// 终端中显示扫描进度与预计剩余时间
+	if opt, ok := progressOption(); ok {
+		opts = append(opts, opt)
+	}
+
+	// 日志级别: --verbose、--quiet 优先于配置文件的 log_level，JSON 输出模式下日志同样输出为 JSON
+	opts = append(opts, config.WithLogger(newLogger(os.Stdout, logLevel(cfg))))
+	return opts, searchPaths
+}
+
+// resolveWirePath function    从标志、位置参数或配置文件获取生成路径.
+func resolveWirePath(args []string, cfg *config.FileConfig) string {
+	path := wirePath
+	if path == "" && len(args) > 0 {
+		path = args[0]
+	}
+	if path == "" && cfg.OutputPath != "" {
+		path = cfg.OutputPath
+	}
+	return path
+}
+
 // handleInitConfig function    处理初始化配置文件.
 func handleInitConfig() error {
 	configPath := ".gutowire.yaml"
@@ -175,21 +422,51 @@ func handleInitConfig() error {
 		return fmt.Errorf("生成配置文件失败: %w", err)
 	}
 
+	if jsonOutput() {
+		printResult("配置文件已生成", "path", configPath)
+		return nil
+	}
 	fmt.Printf("✓ 配置文件已生成: %s\n", configPath)
 	fmt.Println("\n你可以编辑此文件来自定义配置")
 	return nil
 }
 
-// handleWatch function    处理 watch 模式.
-func handleWatch(wirePath, searchPath string, opts []config.Option) error {
-	fmt.Println("🔍 启动 Watch 模式...")
+// handleTargets function    批量生成到配置文件中的每个目标，共享一次扫描；--check-only 时检查每个目标是否最新.
+func handleTargets(targets []config.Target, opts []config.Option) error {
+	runTargets := make([]runner.Target, 0, len(targets))
+	for _, t := range targets {
+		if t.OutputPath == "" {
+			return fmt.Errorf("targets 中的目标需要指定 output_path")
+		}
+		runTargets = append(runTargets, runner.Target{GenPath: t.OutputPath, Options: t.Options()})
+	}
+	if checkOnly {
+		opts = append(opts, config.WithCheckOnly(true))
+	}
 
-	// 首先执行一次生成
-	if err := runner.RunAutoWire(wirePath, opts...); err != nil {
-		return fmt.Errorf("初始生成失败: %w", err)
+	summaries, err := runner.RunTargets(runTargets, opts...)
+	for _, s := range summaries {
+		if checkOnly {
+			printResult("生成的代码已是最新", "path", s.GenPath)
+			continue
+		}
+		printSummary(s.Summary)
+		printResult("自动装配代码生成成功", "path", s.GenPath)
 	}
+	if err != nil {
+		if checkOnly {
+			return err
+		}
+		return fmt.Errorf("自动装配失败: %w", err)
+	}
+	return nil
+}
 
-	fmt.Println("✓ 初始生成完成")
+// handleWatch function    处理 watch 模式.
+func handleWatch(wirePath string, searchPaths []string, opts []config.Option) error {
+	if !jsonOutput() {
+		fmt.Println("🔍 启动 Watch 模式...")
+	}
 
 	// 创建 watcher
 	w, err := watcher.New(wirePath, []string{"*.gen.go", "wire_gen.go"}, opts...)
@@ -199,11 +476,15 @@ func handleWatch(wirePath, searchPath string, opts []config.Option) error {
 	//nolint:errcheck
 	defer w.Close()
 
-	// 开始监听
-	if searchPath == "" {
-		searchPath = "."
+	// 首先执行一次完整生成，之后的变更增量生成
+	if err := w.Run(runCtx); err != nil {
+		return fmt.Errorf("初始生成失败: %w", err)
 	}
-	return w.Watch(searchPath)
+
+	printResult("初始生成完成", "path", wirePath)
+
+	// 开始监听（未指定 scope 时监听配置的搜索路径）
+	return w.Watch(runCtx, searchPaths...)
 }
 
 func init() {
@@ -216,10 +497,52 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.PersistentFlags().StringVarP(&wirePath, "wire_path", "w", "", "Wire 配置文件生成路径")
-	rootCmd.PersistentFlags().StringVarP(&scope, "scope", "s", "", "依赖搜索范围(目录路径),不填则全局搜索")
+	rootCmd.PersistentFlags().StringSliceVarP(&scope, "scope", "s", nil, "依赖搜索范围(目录路径),可重复指定,不填则全局搜索")
 	rootCmd.PersistentFlags().StringVarP(&pkg, "pkg", "p", "", "生成文件的包名")
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "配置文件路径 (默认: .gutowire.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&watch, "watch", false, "启用 watch 模式，自动监听文件变化")
 	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "禁用缓存")
+	rootCmd.PersistentFlags().BoolVar(&noIgnore, "no-ignore-files", false, "扫描时不遵循 .gitignore 与 .gutowireignore")
+	rootCmd.PersistentFlags().BoolVar(&noSum, "no-sum", false, "生成后不写入生成校验文件 gutowire.sum")
 	rootCmd.PersistentFlags().BoolVar(&initConfig, "init", false, "生成示例配置文件")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "等待生成目录锁的超时时间 (默认 1m)")
+	rootCmd.PersistentFlags().StringVar(&wireVersion, "wire-version", "", "固定使用的 wire 版本，如 v0.6.0（自动安装到工具缓存）")
+	rootCmd.PersistentFlags().StringVar(&wireTags, "wire-tags", "", "运行 wire 时使用的构建标签，如 prod（选择 tag= 生成的提供者）")
+	rootCmd.PersistentFlags().StringVar(&wireMode, "wire-mode", "",
+		"运行 wire 的方式: exec（默认，执行 wire 可执行文件）、gorun（go run 项目 go.mod 中的 wire，无需安装，非封闭构建）")
+	rootCmd.PersistentFlags().StringVar(&backend, "backend", "", "依赖注入后端: wire（默认）、fx（生成 fx.Module 注册代码，不运行 wire）")
+	rootCmd.PersistentFlags().StringVar(&outputMode, "output", outputText, "输出模式: text、json（每行一个 JSON 事件）")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "严格模式：注解语法错误或输出警告时终止生成（默认只输出警告）")
+	rootCmd.PersistentFlags().StringVar(&missing, "missing-providers", "",
+		"没有提供者的依赖的处理方式: error（默认，运行 wire 之前报错）、warn（只输出警告，交由 wire 报告）")
+	rootCmd.PersistentFlags().BoolVar(&checkOnly, "check-only", false,
+		"只检查生成的代码（包括 wire_gen.go）是否最新，不写入文件，需要重新生成时以状态 1 退出")
+	rootCmd.PersistentFlags().BoolVar(&showDiff, "diff", false,
+		"只输出重新生成会对生成文件造成的修改（unified diff），不写入文件也不运行 wire")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "使用配置文件中的配置档，如 dev、test、prod")
+	rootCmd.PersistentFlags().StringVar(&goos, "goos", "", "评估源文件构建约束的目标操作系统（默认当前平台）")
+	rootCmd.PersistentFlags().StringVar(&goarch, "goarch", "", "评估源文件构建约束的目标架构（默认当前平台）")
+	rootCmd.PersistentFlags().StringSliceVar(&buildTags, "build-tags", nil, "评估源文件构建约束时启用的构建标签，如 integration")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "输出详细日志（收集到的每个组件与生成的每个文件）")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "安静模式：只输出警告、错误与最终结果")
+	rootCmd.PersistentFlags().StringVarP(&chdir, "chdir", "C", "", "运行前切换到该工作目录")
+	rootCmd.PersistentFlags().StringArrayVar(&plugins, "plugin", nil,
+		"代码生成插件，可重复指定：Go 插件（.so）路径或可执行文件命令（JSON over stdin/stdout）")
+
+	// 参数补全（gutowire completion bash|zsh|fish）
+	rootCmd.ValidArgsFunction = completeDirs
+	for name, fn := range map[string]cobra.CompletionFunc{
+		"wire_path": completeDirs,
+		"scope":     completeDirs,
+		"chdir":     completeDirs,
+		"config":    completeConfigFiles,
+		"profile":   completeProfiles,
+		"output":    completeValues(outputText, outputJSON),
+		"wire-mode": completeValues(config.WireModeExec, config.WireModeGoRun),
+		"backend":   completeValues(config.BackendWire, config.BackendFx),
+
+		"missing-providers": completeValues(config.MissingProvidersError, config.MissingProvidersWarn),
+	} {
+		_ = rootCmd.RegisterFlagCompletionFunc(name, fn)
+	}
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scaffoldMissing bool
+	scaffoldDryRun  bool
+)
+
+// scaffoldCmd 为缺少构造函数的组件生成构造函数.
+var scaffoldCmd = &cobra.Command{
+	Use:   "scaffold [Type]... [--flags]",
+	Short: "为使用 wire.Struct 注入的结构体组件生成 New<Type> 构造函数",
+	Long: `扫描 @autowire 注解，为没有构造函数的结构体组件生成 New<Type> 构造函数，写在类型声明之后。
+参数按注入的字段依次生成（跳过 wire:"-" 字段以及 fields=、exclude= 排除的字段），函数体只为字段赋值，
+之后可以在其中补充初始化逻辑；重新生成时构造函数替代 wire.Struct，fields=、exclude= 参数不再需要。
+
+类型名称支持 包路径.类型名、包名.类型名 以及单独的类型名；--missing 处理全部缺少构造函数的组件。
+已有构造函数、包中已声明同名函数、泛型、非结构体等无法生成的组件：指定时报错，--missing 时跳过。
+
+示例:
+  gutowire scaffold svc.Server               # 为 svc.Server 生成 NewServer
+  gutowire scaffold --missing --dry-run      # 只输出 diff，不修改文件
+  gutowire scaffold --missing                # 为全部缺少构造函数的组件生成`,
+	ValidArgsFunction: completeScaffoldTargets,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(args) == 0 && !scaffoldMissing {
+			return fmt.Errorf("需要指定组件类型，或通过 --missing 处理全部缺少构造函数的组件")
+		}
+		if len(args) > 0 && scaffoldMissing {
+			return fmt.Errorf("--missing 不能与组件类型同时使用")
+		}
+		sc, err := scanProject()
+		if err != nil {
+			return err
+		}
+		plan, err := sc.Scaffold(args...)
+		if err != nil {
+			return err
+		}
+		if len(plan.Scaffolds) == 0 {
+			printResult("没有需要生成构造函数的组件")
+			return nil
+		}
+
+		if scaffoldDryRun {
+			printFileDiffs(plan.Changes, "生成构造函数会修改文件")
+			return nil
+		}
+		if err := plan.Apply(); err != nil {
+			return err
+		}
+		if !jsonOutput() {
+			for _, s := range plan.Scaffolds {
+				fmt.Fprintf(os.Stderr, "  %s: %s(%s)\n", s.Element, s.Constructor, strings.Join(s.Params, ", "))
+			}
+		}
+		printResult(fmt.Sprintf("已生成 %d 个构造函数", len(plan.Scaffolds)), "files", len(plan.Changes))
+		return nil
+	},
+}
+
+func init() {
+	scaffoldCmd.Flags().BoolVar(&scaffoldMissing, "missing", false, "为全部缺少构造函数的组件生成构造函数")
+	scaffoldCmd.Flags().BoolVar(&scaffoldDryRun, "dry-run", false, "只输出 unified diff，不修改文件")
+	rootCmd.AddCommand(scaffoldCmd)
+}
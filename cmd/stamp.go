@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spelens-gud/gutowire/internal/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// unstampedFlags 不记录到生成清单中的标志：只影响本次运行的输出或运行方式.
+var unstampedFlags = []string{"chdir", "output", "verbose", "quiet", "help", "watch", "init", "check-only"}
+
+// stampCmd 生成代码并写入 go:generate 指令与生成清单.
+var stampCmd = &cobra.Command{
+	Use:   "stamp [flags] [生成路径]",
+	Short: "生成代码并写入 //go:generate 指令与生成清单，之后可以用 go generate 或 gutowire regen 重新生成",
+	Long: `与直接运行 gutowire 相同地生成代码，并在生成目录写入 autowire.go，其中包含：
+
+  //go:generate gutowire stamp -C <工作目录> <本次的参数>
+  // gutowire:manifest {"version":...,"dir":...,"args":[...],"input":"sha256:..."}
+
+生成的 Set 文件带有 wireinject 构建约束，go generate 不会读取其中的指令，
+因此指令写在不带构建约束的 autowire.go 中。清单记录生成时的版本与带注解源文件的哈希，
+gutowire regen 读取清单，不需要任何参数即可按原来的参数重新生成。
+
+示例:
+  gutowire stamp -s ./internal -w ./wire
+  go generate ./wire                         # 按记录的参数重新生成
+  gutowire regen ./wire                      # 同上，并报告版本与输入的变化`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		opts, _ := buildOptions(cfg)
+		genPath := resolveWirePath(args, cfg)
+		if genPath == "" {
+			return fmt.Errorf("必须指定 Wire 配置文件生成路径\n使用方式: %s stamp [flags] <生成路径>", commandName)
+		}
+
+		stamp, err := newStamp(cmd.Flags(), args, genPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, config.WithStamp(stamp.Dir, stamp.Args))
+
+		if checkOnly {
+			if err := runner.RunAutoWire(genPath, append(opts, config.WithCheckOnly(true))...); err != nil {
+				return err
+			}
+			printResult("生成的代码已是最新", "path", genPath)
+			return nil
+		}
+		summary, err := runner.RunAutoWireSummary(genPath, opts...)
+		if err != nil {
+			return fmt.Errorf("自动装配失败: %w", err)
+		}
+		printSummary(summary)
+		printResult("自动装配代码生成成功", "path", genPath,
+			"manifest", filepath.Join(genPath, generator.StampFileName))
+		return nil
+	},
+}
+
+// newStamp function    根据本次设置的标志与位置参数生成清单信息
+// 工作目录记录为相对生成目录的路径，生成目录移动到其他机器或其他位置时仍然有效.
+func newStamp(flags *pflag.FlagSet, args []string, genPath string) (*config.Stamp, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("获取工作目录失败: %w", err)
+	}
+	absGen, err := filepath.Abs(genPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取生成目录绝对路径失败: %w", err)
+	}
+	dir, err := filepath.Rel(absGen, cwd)
+	if err != nil {
+		return nil, fmt.Errorf("计算工作目录相对路径失败: %w", err)
+	}
+
+	var stampArgs []string
+	flags.Visit(func(f *pflag.Flag) {
+		if slices.Contains(unstampedFlags, f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				stampArgs = append(stampArgs, fmt.Sprintf("--%s=%s", f.Name, v))
+			}
+			return
+		}
+		stampArgs = append(stampArgs, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return &config.Stamp{Dir: dir, Args: append(stampArgs, args...)}, nil
+}
+
+// regenCmd 按生成清单重新生成.
+var regenCmd = &cobra.Command{
+	Use:   "regen [生成路径]",
+	Short: "读取生成目录中的生成清单，按记录的参数重新生成",
+	Long: `读取 gutowire stamp 写入的 autowire.go 中的生成清单，在记录的工作目录中
+以记录的参数重新运行 gutowire stamp，不需要记住生成时使用的参数。
+
+清单中的版本与当前版本不一致时输出警告；重新生成后报告带注解源文件的哈希是否变化。
+
+示例:
+  gutowire regen ./wire
+  gutowire regen              # 当前目录即生成目录`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		genPath := resolveWirePath(args, config.DefaultConfig())
+		if genPath == "" {
+			genPath = "."
+		}
+		m, err := generator.ReadManifest(genPath)
+		if err != nil {
+			return err
+		}
+		if m.Version != version.Version {
+			newLogger(os.Stderr, commandLevel(slog.LevelWarn)).Warn("生成清单的版本与当前版本不一致",
+				"manifest", m.Version, "current", version.Version)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("获取可执行文件路径失败: %w", err)
+		}
+		regenArgs := append([]string{"stamp", "-C", m.Dir}, m.Args...)
+		for _, name := range []string{"output", "verbose", "quiet"} {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+				regenArgs = append(regenArgs, fmt.Sprintf("--%s=%s", name, f.Value.String()))
+			}
+		}
+		//nolint:gosec
+		c := exec.Command(exe, regenArgs...)
+		c.Dir = genPath
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("重新生成失败: %w", err)
+		}
+
+		regenerated, err := generator.ReadManifest(genPath)
+		if err != nil {
+			return err
+		}
+		printResult("已按生成清单重新生成", "path", genPath, "input_changed", regenerated.Input != m.Input)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stampCmd)
+	rootCmd.AddCommand(regenCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSigma        float64
+	statsOnlyOutliers bool
+	statsExcludeSets  []string
+)
+
+// statsCmd 输出组件依赖指标.
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "输出组件的扇入、扇出与深度指标",
+	Long: `扫描 @autowire 注解并计算每个组件的依赖指标:
+
+  FAN-IN   被多少个组件依赖
+  FAN-OUT  依赖多少个组件
+  DEPTH    距注入根节点（@autowire.init 组件，没有时为不被依赖的组件）的最短深度
+
+指标超过 平均值 + sigma × 标准差 的组件会被标记为离群，通常是需要拆分的“上帝对象”。
+
+示例:
+  gutowire stats                    # 输出全部组件指标
+  gutowire stats --outliers         # 只输出离群组件
+  gutowire stats --sigma 1.5        # 使用更严格的离群阈值`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		g, err := scanGraph()
+		if err != nil {
+			return err
+		}
+		g, err = g.Filter(graph.FilterOptions{ExcludeSets: statsExcludeSets})
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return graph.WriteMetricsJSON(os.Stdout, g.Metrics(statsSigma), statsOnlyOutliers)
+		}
+		return graph.WriteMetrics(os.Stdout, g.Metrics(statsSigma), statsOnlyOutliers)
+	},
+}
+
+func init() {
+	statsCmd.Flags().Float64Var(&statsSigma, "sigma", 2, "离群阈值：超过平均值多少个标准差")
+	statsCmd.Flags().BoolVar(&statsOnlyOutliers, "outliers", false, "只输出离群组件")
+	statsCmd.Flags().StringSliceVar(&statsExcludeSets, "exclude-set", nil, "排除的 Set 名称，可重复指定")
+	_ = statsCmd.RegisterFlagCompletionFunc("exclude-set", completeSets)
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/x/exp/charmtone"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/runner"
+)
+
+var (
+	summaryBorder = lipgloss.NewStyle().Foreground(charmtone.Squid)
+	summaryHeader = lipgloss.NewStyle().Bold(true).Foreground(charmtone.Coral).Padding(0, 1)
+	summaryCell   = lipgloss.NewStyle().Padding(0, 1)
+	summaryCount  = summaryCell.Foreground(charmtone.Guac).Align(lipgloss.Right)
+	summaryNote   = lipgloss.NewStyle().Foreground(charmtone.Squid)
+	deprecatedTag = lipgloss.NewStyle().Bold(true).Foreground(charmtone.Mustard)
+)
+
+// printSummary function    输出生成汇总：各 Set 的组件数量与生成文件、写入的文件数、wire 执行时间与缓存命中率
+// 文本模式输出带颜色的表格（--quiet 时不输出），JSON 模式输出 summary 事件.
+func printSummary(s *runner.Summary) {
+	if s == nil {
+		return
+	}
+	printDeprecations(s.Deprecations)
+	if jsonOutput() {
+		sets := parser.Map(s.Sets, func(set generator.SetSummary) map[string]any {
+			return map[string]any{"name": set.Name, "elements": set.Elements, "file": set.File}
+		})
+		newLogger(os.Stdout, slog.LevelInfo).Info("生成汇总", logger.EventKey, logger.EventSummary,
+			"sets", sets,
+			"files", s.Files,
+			"written", append([]string{}, s.Written...),
+			"cache_hits", s.CacheHits,
+			"cache_misses", s.CacheMisses,
+			"wire_duration", s.WireDuration.String(),
+			"duration", s.Duration.String(),
+		)
+		return
+	}
+	if quiet || len(s.Sets) == 0 {
+		return
+	}
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(summaryBorder).
+		Headers("Set", "组件", "生成文件").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return summaryHeader
+			case col == 1:
+				return summaryCount
+			}
+			return summaryCell
+		})
+	elements := 0
+	for _, set := range s.Sets {
+		file := "-"
+		if set.File != "" {
+			file = displayPath(set.File)
+		}
+		t.Row(set.Name, strconv.Itoa(set.Elements), file)
+		elements += set.Elements
+	}
+	_, _ = lipgloss.Println(t.String())
+
+	_, _ = lipgloss.Println(summaryNote.Render(fmt.Sprintf("%d 个 Set，%d 个组件；生成 %d 个文件，写入 %d 个（其余内容未变化）",
+		len(s.Sets), elements, s.Files, len(s.Written))))
+	timing := fmt.Sprintf("总耗时 %s", roundDuration(s.Duration))
+	if s.WireDuration > 0 {
+		timing = fmt.Sprintf("wire 耗时 %s，%s", roundDuration(s.WireDuration), timing)
+	}
+	if total := s.CacheHits + s.CacheMisses; total > 0 {
+		timing += fmt.Sprintf("；缓存命中率 %.0f%%（%d/%d）", s.CacheHitRate()*100, s.CacheHits, total)
+	}
+	_, _ = lipgloss.Println(summaryNote.Render(timing))
+}
+
+// printDeprecations function    输出初始化函数用到的已弃用组件，每个组件一条警告（--quiet 时同样输出）
+// 文本模式输出到标准错误，JSON 模式输出 warning 事件到标准输出.
+func printDeprecations(deprecations []generator.Deprecation) {
+	if jsonOutput() {
+		l := newLogger(os.Stdout, slog.LevelInfo)
+		for _, d := range deprecations {
+			l.Warn("使用了已弃用的组件", logger.EventKey, logger.EventWarning,
+				"element", d.Element, "reason", d.Message, "injectors", d.Injectors)
+		}
+		return
+	}
+	for _, d := range deprecations {
+		_, _ = lipgloss.Fprintln(os.Stderr, deprecatedTag.Render("deprecated")+" "+d.String())
+	}
+}
+
+// displayPath function    返回相对当前目录的路径，无法计算时返回原路径.
+func displayPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	if rel, err := filepath.Rel(wd, p); err == nil {
+		return rel
+	}
+	return p
+}
+
+// roundDuration function    将耗时保留到毫秒，便于阅读.
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
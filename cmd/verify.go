@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd 校验生成结果与生成校验文件一致.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "校验重新生成的结果与 gutowire.sum 一致，不写入任何文件",
+	Long: `读取生成目录中的生成校验文件 gutowire.sum，重新扫描并在内存中生成，与校验文件比较。
+存在差异时逐条输出并以非零状态码退出，适用于 CI 中确认提交的生成文件是最新的。
+
+比较内容:
+  - gutowire 与 wire 的版本
+  - 带注解的输入文件及其注解
+  - 组件所在包中的其他 Go 文件（如未带注解的构造函数）
+  - 生成的文件（包括在临时目录中重新运行 wire 得到的 wire_gen.go）
+
+生成校验文件在每次成功生成后写入生成目录，使用 --no-sum 可以关闭。
+
+示例:
+  gutowire verify -w ./wire`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		opts, _ := buildOptions(cfg)
+		opts = append(opts, config.WithLogger(newLogger(os.Stderr, commandLevel(slog.LevelWarn))))
+		genPath := resolveWirePath(nil, cfg)
+		if genPath == "" {
+			genPath = "."
+		}
+
+		diffs, err := runner.Verify(genPath, opts...)
+		if err != nil {
+			return err
+		}
+
+		printSumDiffs(diffs)
+		if len(diffs) > 0 {
+			return fmt.Errorf("校验未通过: 与 %s 存在 %d 处差异，请重新运行 gutowire 生成", generator.SumFileName, len(diffs))
+		}
+
+		printResult("生成结果与 gutowire.sum 一致")
+		return nil
+	},
+}
+
+// printSumDiffs function    输出与生成校验文件的差异
+// 文本模式输出到标准错误，JSON 模式输出 warning 事件到标准输出.
+func printSumDiffs(diffs []string) {
+	if jsonOutput() {
+		l := newLogger(os.Stdout, slog.LevelInfo)
+		for _, d := range diffs {
+			l.Warn(d, logger.EventKey, logger.EventWarning)
+		}
+		return
+	}
+	for _, d := range diffs {
+		fmt.Fprintln(os.Stderr, "! "+d)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/spelens-gud/gutowire/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+// versionInfo struct    version 命令的输出：构建信息与生成时使用的 wire.
+type versionInfo struct {
+	version.Info
+	Wire *runner.WireInfo `json:"wire,omitempty"` // fx 后端不使用 wire，为空
+}
+
+// versionCmd 输出版本与构建信息.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "输出版本、构建信息与 wire 的兼容性",
+	Long: `输出 gutowire 的版本、构建时的 git 提交与时间、Go 版本，以及生成的代码需要的最低 wire 版本。
+
+同时检查生成时会使用的 wire（配置了 wire_version 时为工具缓存中的版本，gorun 模式为 go.mod 中的版本，
+否则为 PATH 中的 wire），版本低于最低要求时输出警告并以非零状态码退出，不会安装或运行 wire。
+
+示例:
+  gutowire version
+  gutowire version --json     # 输出 JSON，便于脚本与 issue 模板收集`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		// 版本信息不依赖配置文件，配置文件无效时按默认配置检查 wire
+		var opts []config.Option
+		if cfg, err := loadConfig(); err == nil {
+			opts, _ = buildOptions(cfg)
+		}
+
+		info := versionInfo{Info: version.Get()}
+		if config.NewGenOpt(".", opts...).Backend != config.BackendFx {
+			wire := runner.InstalledWire(opts...)
+			info.Wire = &wire
+		}
+
+		if versionJSON || jsonOutput() {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(info); err != nil {
+				return err
+			}
+		} else {
+			printVersion(info)
+		}
+		if info.Wire != nil && !info.Wire.Compatible {
+			return fmt.Errorf("wire %s 低于生成的代码需要的最低版本 %s", info.Wire.Version, info.MinWireVersion)
+		}
+		return nil
+	},
+}
+
+// printVersion function    以文本形式输出版本信息，未知的字段显示为 unknown.
+func printVersion(info versionInfo) {
+	orUnknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+	fmt.Printf("gutowire %s\n", info.Version)
+	fmt.Printf("  commit:  %s\n", orUnknown(info.Commit))
+	fmt.Printf("  built:   %s\n", orUnknown(info.Date))
+	fmt.Printf("  go:      %s %s\n", info.GoVersion, info.Platform)
+	if info.Wire == nil {
+		fmt.Printf("  wire:    fx 后端不使用 wire\n")
+		return
+	}
+	w := info.Wire
+	wire := orUnknown(w.Version)
+	switch {
+	case w.Path != "":
+		wire = fmt.Sprintf("%s (%s)", w.Path, wire)
+	case w.Version == "":
+		wire = "未找到"
+	}
+	fmt.Printf("  wire:    %s，最低要求 %s\n", wire, w.MinVersion)
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "以 JSON 格式输出（等同于 --output=json）")
+	rootCmd.AddCommand(versionCmd)
+}
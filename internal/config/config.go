@@ -2,13 +2,86 @@
 // 包含配置选项的定义和处理，支持自定义包名、搜索路径、初始化类型等配置。
 package config
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// 重复接口绑定的处理策略.
+const (
+	// DuplicateBindingError 报错终止生成（默认）.
+	DuplicateBindingError = "error"
+	// DuplicateBindingPriority 按 priority= 参数保留优先级最高的实现，相同时先出现者胜出.
+	DuplicateBindingPriority = "priority"
+	// DuplicateBindingSplit 将冲突的实现拆分到带后缀的独立 Set.
+	DuplicateBindingSplit = "split"
+)
+
+// 注入入口用到但没有任何提供者的依赖的处理方式.
+const (
+	// MissingProvidersError 运行 wire 之前报错终止生成（默认）.
+	MissingProvidersError = "error"
+	// MissingProvidersWarn 只输出警告并继续运行 wire（严格模式下警告同样终止生成），
+	// 用于提供者由 gutowire 无法识别的方式（如手写的 wire.NewSet）提供的项目.
+	MissingProvidersWarn = "warn"
+)
+
+// 不同 Set 绑定同一接口且同时加入汇总 Sets 时的处理策略.
+const (
+	// ConflictPolicyError 报错终止生成（默认）.
+	ConflictPolicyError = "error"
+	// ConflictPolicyLastWins 保留汇总 Sets 中最后一个 Set（按名称排序）的绑定.
+	ConflictPolicyLastWins = "last_wins"
+	// ConflictPolicyPreferSet 策略前缀，prefer_set:<name> 保留指定 Set 的绑定.
+	ConflictPolicyPreferSet = "prefer_set:"
+)
+
+// 生成的依赖注入后端.
+const (
+	// BackendWire 生成 Google Wire 的 Set 并运行 wire（默认）.
+	BackendWire = "wire"
+	// BackendFx 生成 uber-go/fx 的 fx.Module 与 fx.Provide 注册代码.
+	BackendFx = "fx"
+)
+
+// 运行 wire 的方式.
+const (
+	// WireModeExec 执行 PATH 或工具缓存中的 wire 可执行文件（默认）.
+	WireModeExec = "exec"
+	// WireModeGoRun 通过 go run 编译运行项目 go.mod 中依赖的 wire，不需要安装 wire 可执行文件
+	// 仍以子进程运行 wire，编译依赖 Go 工具链与模块缓存（缺少模块时需要访问 GOPROXY），不是封闭（hermetic）的运行方式.
+	WireModeGoRun = "gorun"
+	// WireModeEmbedded 已弃用，WireModeGoRun 的旧名称，初始化配置时转换为 WireModeGoRun.
+	WireModeEmbedded = "embedded"
+)
+
 var (
 	// WireTag 注解标记，用于标识需要进行依赖注入的类型或函数.
 	WireTag = "@autowire"
 	// FilePrefix 生成文件的前缀名称.
 	FilePrefix = "autowire"
+	// DefaultExcludeDirs 默认排除的目录：依赖目录、测试数据、版本库以及 node_modules、bazel-out 等构建产物.
+	DefaultExcludeDirs = []string{"vendor", "testdata", ".git", "node_modules", "bazel-*"}
 )
 
+// ParseLogLevel function    解析日志级别，可选 debug、info、warn、error（大小写不敏感）.
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("不支持的日志级别: %s（可选 debug、info、warn、error）", level)
+}
+
 // WithPkg function    设置生成文件的包名
 // 如果不设置，会自动从目录名推断.
 func WithPkg(pkg string) Option {
@@ -38,6 +111,14 @@ func WithSearchPath(path string) Option {
 	}
 }
 
+// WithSearchPaths function    添加多个依赖搜索路径
+// 多个目录中收集到的组件合并生成，重叠的目录只扫描一次.
+func WithSearchPaths(paths ...string) Option {
+	return func(o *Opt) {
+		o.SearchPaths = append(o.SearchPaths, paths...)
+	}
+}
+
 // WithCache function    设置是否启用缓存.
 func WithCache(enable bool) Option {
 	return func(o *Opt) {
@@ -45,9 +126,296 @@ func WithCache(enable bool) Option {
 	}
 }
 
-// WithExcludeDirs function    设置排除的目录列表.
+// WithIgnoreFiles function    设置扫描与监听时是否遵循 .gitignore 与 .gutowireignore（默认启用）
+// 启用后忽略文件中匹配的目录与文件不参与扫描，语法与 .gitignore 一致.
+func WithIgnoreFiles(enable bool) Option {
+	return func(o *Opt) {
+		o.IgnoreFiles = enable
+	}
+}
+
+// WithLogger function    设置日志器
+// 嵌入 gutowire 的程序可以传入自己的 slog.Logger，传入 nil 时使用默认日志器.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *Opt) {
+		o.Logger = l
+	}
+}
+
+// WithLockTimeout function    设置等待生成目录锁的超时时间
+// 多个 gutowire 进程同时生成同一目录时，后启动的进程会等待锁释放.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(o *Opt) {
+		o.LockTimeout = timeout
+	}
+}
+
+// WithDuplicateBinding function    设置同一 Set 中多个实现绑定同一接口时的处理策略
+// 可选值: DuplicateBindingError、DuplicateBindingPriority、DuplicateBindingSplit.
+func WithDuplicateBinding(policy string) Option {
+	return func(o *Opt) {
+		o.DuplicateBinding = policy
+	}
+}
+
+// WithMissingProviders function    设置注入入口用到但没有任何提供者的依赖的处理方式
+// 可选值: MissingProvidersError、MissingProvidersWarn.
+func WithMissingProviders(policy string) Option {
+	return func(o *Opt) {
+		o.MissingProviders = policy
+	}
+}
+
+// WithConflictPolicy function    设置不同 Set 绑定同一接口且同时加入汇总 Sets 时的处理策略
+// 可选值: ConflictPolicyError、ConflictPolicyLastWins、ConflictPolicyPreferSet+Set 名称（如 prefer_set:db）.
+func WithConflictPolicy(policy string) Option {
+	return func(o *Opt) {
+		o.ConflictPolicy = policy
+	}
+}
+
+// CheckDuplicateBinding function    校验同一 Set 中重复接口绑定的处理策略，为空时使用默认的 error.
+func CheckDuplicateBinding(policy string) error {
+	switch policy {
+	case "", DuplicateBindingError, DuplicateBindingPriority, DuplicateBindingSplit:
+		return nil
+	}
+	return fmt.Errorf("无效的 duplicate_binding %q，可选值: error、priority、split", policy)
+}
+
+// ParseConflictPolicy function    校验跨 Set 绑定冲突的处理策略，prefer_set:<name> 返回指定的 Set 名称.
+func ParseConflictPolicy(policy string) (preferSet string, err error) {
+	switch policy {
+	case "", ConflictPolicyError, ConflictPolicyLastWins:
+		return "", nil
+	}
+	if name, ok := strings.CutPrefix(policy, ConflictPolicyPreferSet); ok && name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("无效的 conflict_policy %q，可选值: error、last_wins、prefer_set:<name>", policy)
+}
+
+// WithIncludeGenerated function    开启生成文件（带 Code generated ... DO NOT EDIT. 标记）的扫描
+// 默认跳过生成的文件；globs 为空时包含全部生成文件，否则只包含匹配的文件（相对模块根目录，支持 **）.
+func WithIncludeGenerated(globs ...string) Option {
+	return func(o *Opt) {
+		o.IncludeGenerated = true
+		o.GeneratedGlobs = globs
+	}
+}
+
+// WithWireVersion function    固定使用的 wire 版本，如 v0.6.0
+// 该版本会被安装到工具缓存目录并优先于 PATH 中的 wire 使用.
+func WithWireVersion(version string) Option {
+	return func(o *Opt) {
+		o.WireVersion = version
+	}
+}
+
+// WithWireTags function    设置运行 wire 时使用的构建标签（wire gen -tags）
+// 用于选择 @autowire(tag=...) 生成的带 //go:build 约束的提供者.
+func WithWireTags(tags string) Option {
+	return func(o *Opt) {
+		o.WireTags = tags
+	}
+}
+
+// WithTarget function    设置评估源文件 //go:build 约束的目标平台，为空的参数使用当前平台
+// 不满足约束的文件（如 _windows.go、//go:build windows）不参与扫描.
+func WithTarget(goos, goarch string) Option {
+	return func(o *Opt) {
+		o.GOOS = goos
+		o.GOARCH = goarch
+	}
+}
+
+// WithBuildTags function    设置评估源文件 //go:build 约束时启用的构建标签
+// 运行 wire 时的构建标签（WithWireTags）与 wireinject 总是启用.
+func WithBuildTags(tags ...string) Option {
+	return func(o *Opt) {
+		o.BuildTags = tags
+	}
+}
+
+// WithTag function    设置注解标记，替代默认的 @autowire
+// 如 @inject 或 //go:autowire，.init、.config 等后缀与参数写法保持不变.
+func WithTag(tag string) Option {
+	return func(o *Opt) {
+		o.Tag = tag
+	}
+}
+
+// WithStrict function    设置是否启用严格模式
+// 启用后注解语法错误（缺少括号、无效参数等）以及扫描、写入时输出的警告（跳过循环导入的包、
+// 删除文件失败、无效的注解参数等）会终止生成，否则只输出警告并忽略对应的注解或文件.
+func WithStrict(strict bool) Option {
+	return func(o *Opt) {
+		o.Strict = strict
+	}
+}
+
+// WithWireMode function    设置运行 wire 的方式
+// 可选值: WireModeExec、WireModeGoRun.
+func WithWireMode(mode string) Option {
+	return func(o *Opt) {
+		o.WireMode = mode
+	}
+}
+
+// WithSetOutputs function    设置 Set 的输出目录（Set 名称 -> 相对模块根目录的路径）
+// 配置了输出目录的 Set 生成到独立的包中，与注解中的 out= 参数等价.
+func WithSetOutputs(outputs map[string]string) Option {
+	return func(o *Opt) {
+		o.SetOutputs = outputs
+	}
+}
+
+// WithSetTags function    设置 Set 的构建标签（Set 名称 -> 构建标签）
+// Set 中没有 tag= 参数的组件生成到带该构建约束的文件，与在每个组件上添加 tag= 参数等价.
+func WithSetTags(tags map[string]string) Option {
+	return func(o *Opt) {
+		o.SetTags = tags
+	}
+}
+
+// WithSetBuildTags function    设置 Set 文件的构建约束（Set 名称 -> 构建标签）
+// 该 Set 生成的全部文件带有对应的 //go:build 约束，不加入汇总 Sets，由使用者在带相同约束的代码中引用.
+func WithSetBuildTags(tags map[string]string) Option {
+	return func(o *Opt) {
+		o.SetBuildTags = tags
+	}
+}
+
+// WithParallel function    设置扫描与写入文件的最大并发数
+// 用于在文件描述符受限的 CI 机器上限制并发，0 表示使用 GOMAXPROCS.
+func WithParallel(n int) Option {
+	return func(o *Opt) {
+		o.Parallel = n
+	}
+}
+
+// WithBackend function    设置生成的依赖注入后端
+// 可选值: BackendWire、BackendFx.
+func WithBackend(backend string) Option {
+	return func(o *Opt) {
+		o.Backend = backend
+	}
+}
+
+// WithExcludeDirs function    设置排除的目录列表
+// 每一项可以是目录名（如 vendor）或相对模块根目录的 glob（如 internal/legacy/**、**/*.pb.go）.
 func WithExcludeDirs(dirs []string) Option {
 	return func(o *Opt) {
 		o.ExcludeDirs = dirs
 	}
 }
+
+// WithMaxDepth function    设置扫描时进入的最大目录深度，搜索路径本身为第 0 层，0 表示不限制.
+func WithMaxDepth(depth int) Option {
+	return func(o *Opt) {
+		o.MaxDepth = depth
+	}
+}
+
+// WithIncludeOnly function    只扫描指定的目录
+// 每一项为相对模块根目录的目录或 glob，如 services、pkg/*/api.
+func WithIncludeOnly(dirs ...string) Option {
+	return func(o *Opt) {
+		o.IncludeOnly = dirs
+	}
+}
+
+// WithInitTemplate function    设置初始化文件（wire.gen.go）的 text/template 模板文件路径
+// 模板替代内置模板，用于自定义注入函数的名称、参数或文件头部.
+func WithInitTemplate(path string) Option {
+	return func(o *Opt) {
+		o.InitTemplate = path
+	}
+}
+
+// WithStamp function    生成后在生成目录写入生成清单 autowire.go
+// 清单包含 //go:generate 指令以及参数、版本与输入哈希，gutowire regen 按清单重新生成.
+func WithStamp(dir string, args []string) Option {
+	return func(o *Opt) {
+		o.Stamp = &Stamp{Dir: dir, Args: args}
+	}
+}
+
+// WithPlugins function    添加代码生成插件，生成 Wire 配置文件后依次调用
+// 以 .so 结尾的路径作为 Go 插件打开，其余作为可执行文件命令（可带参数），通过标准输入输出交换 JSON.
+func WithPlugins(plugins ...string) Option {
+	return func(o *Opt) {
+		o.Plugins = append(o.Plugins, plugins...)
+	}
+}
+
+// ProgressFunc 扫描进度回调：done 为已处理的文件数，total 为需要处理的文件总数
+// 收集完文件后以 done 为 0 调用一次，之后每处理完一个文件调用一次，可能在多个 goroutine 中并发调用.
+type ProgressFunc func(done, total int)
+
+// WithProgress function    设置扫描进度回调，用于在大型项目中显示进度与预计剩余时间.
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *Opt) {
+		o.Progress = fn
+	}
+}
+
+// WithContext function    设置 ctx，取消时（如 Ctrl+C）终止进行中的扫描、写入与 wire 命令并返回 ctx 的错误
+// 已经写入的生成文件保留，下次生成时覆盖.
+func WithContext(ctx context.Context) Option {
+	return func(o *Opt) {
+		o.Context = ctx
+	}
+}
+
+// WithFileNaming function    设置生成文件名的前缀与后缀，生成 <前缀>_<名称><后缀>.go，如 zz_wire_animals.go
+// 前缀为空时使用默认的 autowire；生成清单 autowire.go 与生成校验文件 gutowire.sum 的文件名不变.
+func WithFileNaming(prefix, suffix string) Option {
+	return func(o *Opt) {
+		if prefix != "" {
+			o.FilePrefix = prefix
+		}
+		o.FileSuffix = suffix
+	}
+}
+
+// WithHeaderTemplate function    设置生成文件头部注释的 text/template 模板文件路径
+// 模板输出需要是 Go 注释（如许可证声明），添加在生成标记 Code generated ... DO NOT EDIT. 之前，
+// 可以使用 {{ .File }}（生成文件名）.
+func WithHeaderTemplate(path string) Option {
+	return func(o *Opt) {
+		o.HeaderTemplate = path
+	}
+}
+
+// WithCheckOnly function    设置检查模式
+// 检查模式下不写入任何文件（wire 在临时目录中运行），重新生成会修改生成的文件时返回错误，用于 CI 检查生成的代码是否最新.
+func WithCheckOnly(checkOnly bool) Option {
+	return func(o *Opt) {
+		o.CheckOnly = checkOnly
+	}
+}
+
+// WithSets function    只生成指定的 Set，其余 Set 的组件不生成到生成路径
+// 注入入口、配置与测试替身组件分别属于 init、config、mock Set，需要时同样列出.
+func WithSets(sets ...string) Option {
+	return func(o *Opt) {
+		o.Sets = sets
+	}
+}
+
+// WithTargets function    设置批量生成的全部生成路径
+// 多个目标共享一次扫描，导入其中任一生成包的文件不参与扫描，避免任一目标出现循环导入.
+func WithTargets(genPaths ...string) Option {
+	return func(o *Opt) {
+		o.TargetPaths = genPaths
+	}
+}
+
+// WithSumFile function    设置成功生成后是否在生成目录写入生成校验文件 gutowire.sum
+// 生成校验文件记录输入文件与注解的哈希、组件所在包的其他文件、生成的文件以及 gutowire 与 wire 的版本，供 gutowire verify 校验.
+func WithSumFile(enable bool) Option {
+	return func(o *Opt) {
+		o.SumFile = enable
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -66,6 +70,17 @@ func TestWithSearchPath(t *testing.T) {
 	}
 }
 
+func TestSearchRoots(t *testing.T) {
+	opt := &Opt{}
+	WithSearchPath("./services")(opt)
+	WithSearchPaths("pkg", "services/", "./pkg")(opt)
+
+	want := []string{"services", "pkg"}
+	if got := opt.SearchRoots(); !slices.Equal(got, want) {
+		t.Errorf("SearchRoots() = %v, want %v", got, want)
+	}
+}
+
 func TestNewGenOpt(t *testing.T) {
 	// 创建临时目录
 	tmpDir := t.TempDir()
@@ -86,6 +101,14 @@ func TestNewGenOpt(t *testing.T) {
 	if opt.Pkg != "testpkg" {
 		t.Errorf("Pkg = %q, want %q", opt.Pkg, "testpkg")
 	}
+
+	if opt.MissingProviders != MissingProvidersError {
+		t.Errorf("MissingProviders = %q, want %q", opt.MissingProviders, MissingProvidersError)
+	}
+	cfg := &FileConfig{MissingProviders: MissingProvidersWarn}
+	if opt := NewGenOpt(tmpDir, cfg.ToOptions()...); opt.MissingProviders != MissingProvidersWarn {
+		t.Errorf("missing_providers: warn 时 MissingProviders = %q", opt.MissingProviders)
+	}
 }
 
 func TestNewGenOpt_WithOptions(t *testing.T) {
@@ -110,3 +133,245 @@ func TestNewGenOpt_WithOptions(t *testing.T) {
 		t.Errorf("InitWire 长度 = %d, want 2", len(opt.InitWire))
 	}
 }
+
+func TestWithParallel(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if opt := NewGenOpt(tmpDir); opt.Parallel != runtime.GOMAXPROCS(0) {
+		t.Errorf("默认 Parallel = %d, want %d", opt.Parallel, runtime.GOMAXPROCS(0))
+	}
+	if opt := NewGenOpt(tmpDir, WithParallel(2)); opt.Parallel != 2 {
+		t.Errorf("Parallel = %d, want 2", opt.Parallel)
+	}
+}
+
+func TestCheckFileNaming(t *testing.T) {
+	tests := []struct {
+		prefix, suffix string
+		wantErr        bool
+	}{
+		{"", "", false},
+		{"zz_wire", "", false},
+		{"autowire", "_gen", false},
+		{"zz/wire", "", true},
+		{"autowire", "_test", true},
+		{"autowire", " gen", true},
+	}
+	for _, tt := range tests {
+		o := &Opt{FilePrefix: FilePrefix}
+		WithFileNaming(tt.prefix, tt.suffix)(o)
+		if err := o.CheckFileNaming(); (err != nil) != tt.wantErr {
+			t.Errorf("CheckFileNaming(%q, %q) error = %v, wantErr %v", tt.prefix, tt.suffix, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckDuplicateBinding(t *testing.T) {
+	for _, policy := range []string{"", DuplicateBindingError, DuplicateBindingPriority, DuplicateBindingSplit} {
+		if err := CheckDuplicateBinding(policy); err != nil {
+			t.Errorf("CheckDuplicateBinding(%q) error = %v", policy, err)
+		}
+	}
+	if err := CheckDuplicateBinding("first"); err == nil || !strings.Contains(err.Error(), "priority") {
+		t.Errorf("CheckDuplicateBinding(\"first\") error = %v", err)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		" warn ":  slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+	for level, want := range tests {
+		if got, err := ParseLogLevel(level); err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v, want %v", level, got, err, want)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("不支持的日志级别应该返回错误")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gutowire.yaml")
+	data := `search_path: ./
+init_types: ["*"]
+exclude_dirs: [vendor]
+set_tags:
+  mock: dev
+profiles:
+  prod:
+    search_path: ${GUTOWIRE_PROD_ROOT:-.}/cmd
+    exclude_dirs: [vendor, mock]
+    set_tags:
+      debug: never
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 应用配置档之后展开环境变量
+	cfg, err := LoadConfigFile(path, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SearchPath != "./cmd" || !slices.Equal(cfg.ExcludeDirs, []string{"vendor", "mock"}) {
+		t.Errorf("search_path = %s, exclude_dirs = %v", cfg.SearchPath, cfg.ExcludeDirs)
+	}
+	// 配置档中未出现的配置保持顶层的值，映射按键合并
+	if !slices.Equal(cfg.InitTypes, []string{"*"}) || cfg.SetTags["mock"] != "dev" || cfg.SetTags["debug"] != "never" {
+		t.Errorf("init_types = %v, set_tags = %v", cfg.InitTypes, cfg.SetTags)
+	}
+	if _, err := LoadConfigFile(path, "staging"); err == nil {
+		t.Error("不存在的配置档应该返回错误")
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("GUTOWIRE_ROOT", "/work/app")
+	t.Setenv("GUTOWIRE_EMPTY", "")
+	cfg := &FileConfig{
+		SearchPath:  "${GUTOWIRE_ROOT}/internal",
+		SearchPaths: []string{"$GUTOWIRE_ROOT/cmd", "${GUTOWIRE_EMPTY:-./pkg}"},
+		OutputPath:  "${GUTOWIRE_OUT:-./wire}",
+		ExcludeDirs: []string{"vendor", "${GUTOWIRE_ROOT}/legacy", "cost$$"},
+	}
+	if err := cfg.expandEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SearchPath != "/work/app/internal" || cfg.OutputPath != "./wire" ||
+		!slices.Equal(cfg.SearchPaths, []string{"/work/app/cmd", "./pkg"}) ||
+		!slices.Equal(cfg.ExcludeDirs, []string{"vendor", "/work/app/legacy", "cost$"}) {
+		t.Errorf("expandEnv() = %+v", cfg)
+	}
+
+	cfg = &FileConfig{OutputPath: "${GUTOWIRE_MISSING}/wire"}
+	if err := cfg.expandEnv(); err == nil || !strings.Contains(err.Error(), "GUTOWIRE_MISSING") {
+		t.Errorf("expandEnv() error = %v", err)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gutowire.yaml")
+	data := `package: wire
+init_types: ["*"]
+targets:
+  - output_path: ./cmd/api/wire
+    sets: [db, http]
+  - output_path: ./cmd/worker/wire
+    package: workerwire
+    sets: [db, queue]
+    init_types: [worker.Worker]
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfigFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Targets) != 2 {
+		t.Fatalf("targets = %+v", cfg.Targets)
+	}
+
+	// 目标的选项覆盖顶层配置，未指定包名时按输出目录推断
+	base := []Option{WithPkg(cfg.Package), InitStruct(cfg.InitTypes...)}
+	api := NewGenOpt(cfg.Targets[0].OutputPath, append(base, cfg.Targets[0].Options()...)...)
+	if api.Pkg != "wire" || !slices.Equal(api.Sets, []string{"db", "http"}) ||
+		!slices.Equal(api.InitWire, []string{"*"}) {
+		t.Errorf("api = pkg %s, sets %v, init %v", api.Pkg, api.Sets, api.InitWire)
+	}
+	worker := NewGenOpt(cfg.Targets[1].OutputPath, append(base, cfg.Targets[1].Options()...)...)
+	if worker.Pkg != "workerwire" || !slices.Equal(worker.InitWire, []string{"worker.Worker"}) {
+		t.Errorf("worker = pkg %s, init %v", worker.Pkg, worker.InitWire)
+	}
+
+	t.Setenv("GUTOWIRE_OUT", "./out")
+	cfg = &FileConfig{Targets: []Target{{OutputPath: "${GUTOWIRE_OUT}/api"}}}
+	if err := cfg.expandEnv(); err != nil || cfg.Targets[0].OutputPath != "./out/api" {
+		t.Errorf("expandEnv() = %+v, %v", cfg.Targets, err)
+	}
+}
+
+func TestPropose(t *testing.T) {
+	write := func(t *testing.T, root string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(root, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("已有注解与 wire 注入器", func(t *testing.T) {
+		root := t.TempDir()
+		write(t, root, map[string]string{
+			"go.mod":               "module example.com/app\n",
+			"cmd/main.go":          "package main\n",
+			"internal/user/svc.go": "package user\n\n// @autowire(set=user_svc,set=db)\ntype Svc struct{}\n",
+			"internal/order/o.go":  "package order\n",
+			"di/wire.go":           "//go:build wireinject\n\npackage di\n",
+			"vendor/x/x.go":        "package x\n\n// @autowire(set=vendored)\ntype X struct{}\n",
+		})
+		p, err := Propose(filepath.Join(root, "internal"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ModulePath != "example.com/app" || p.SearchPath != ".." {
+			t.Errorf("Propose() module = %q, search path = %q", p.ModulePath, p.SearchPath)
+		}
+		if p.OutputPath != "../di" || p.Package != "di" {
+			t.Errorf("Propose() output = %q, package = %q", p.OutputPath, p.Package)
+		}
+		if !p.Annotated || !slices.Equal(p.Sets, []string{"db", "userSvc"}) {
+			t.Errorf("Propose() sets = %v, annotated = %v", p.Sets, p.Annotated)
+		}
+	})
+
+	t.Run("没有注解时使用包名", func(t *testing.T) {
+		root := t.TempDir()
+		write(t, root, map[string]string{
+			"go.mod":               "module example.com/app\n",
+			"internal/user/svc.go": "package user\n",
+			"wire/autowire_x.go":   "// Code generated by go-autowire. DO NOT EDIT.\n\npackage wire\n",
+		})
+		p, err := Propose(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.SearchPath != "./" || p.OutputPath != "./wire" || p.Package != "wire" {
+			t.Errorf("Propose() = %+v", p)
+		}
+		if p.Annotated || !slices.Equal(p.Sets, []string{"user"}) {
+			t.Errorf("Propose() sets = %v, annotated = %v", p.Sets, p.Annotated)
+		}
+	})
+}
+
+func TestHeaderTemplatePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".gutowire.yaml")
+	if err := os.WriteFile(path, []byte("header_template: ./hack/boilerplate.go.txt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfigFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 相对路径相对配置文件所在目录，与当前工作目录无关
+	opt := NewGenOpt("./wire", cfg.ToOptions()...)
+	if want := filepath.Join(dir, "hack", "boilerplate.go.txt"); opt.HeaderTemplate != want {
+		t.Errorf("header_template = %s, want %s", opt.HeaderTemplate, want)
+	}
+
+	cfg.HeaderTemplate = "/etc/boilerplate.go.txt"
+	if opt := NewGenOpt("./wire", cfg.ToOptions()...); opt.HeaderTemplate != cfg.HeaderTemplate {
+		t.Errorf("header_template = %s, want absolute path unchanged", opt.HeaderTemplate)
+	}
+}
@@ -2,8 +2,14 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/spelens-gud/gutowire/internal/logger"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,8 +24,77 @@ type FileConfig struct {
 	Parallel    int      `yaml:"parallel"`     // 并发数，0 表示自动
 	ExcludeDirs []string `yaml:"exclude_dirs"` // 排除的目录
 	IncludeOnly []string `yaml:"include_only"` // 只包含的目录
+	MaxDepth    int      `yaml:"max_depth"`    // 扫描的最大目录深度，0 表示不限制
 	Watch       bool     `yaml:"watch"`        // 是否启用 watch 模式
 	WatchIgnore []string `yaml:"watch_ignore"` // watch 模式忽略的文件模式
+	IgnoreFiles bool     `yaml:"ignore_files"` // 是否遵循 .gitignore 与 .gutowireignore
+
+	SearchPaths []string `yaml:"search_paths,omitempty"` // 多个依赖搜索路径，与 search_path 合并扫描
+
+	LockTimeout time.Duration `yaml:"lock_timeout,omitempty"` // 等待生成目录锁的超时时间，如 30s
+
+	DuplicateBinding string `yaml:"duplicate_binding,omitempty"` // 重复接口绑定策略: error|priority|split
+	ConflictPolicy   string `yaml:"conflict_policy,omitempty"`   // 跨 Set 绑定冲突策略: error|prefer_set:<name>|last_wins
+	MissingProviders string `yaml:"missing_providers,omitempty"` // 没有提供者的依赖: error|warn
+
+	IncludeGenerated bool     `yaml:"include_generated,omitempty"` // 是否扫描生成的文件
+	GeneratedGlobs   []string `yaml:"generated_globs,omitempty"`   // 允许扫描的生成文件 glob，为空表示全部
+
+	WireVersion string `yaml:"wire_version,omitempty"` // 固定的 wire 版本，如 v0.6.0
+	WireTags    string `yaml:"wire_tags,omitempty"`    // 运行 wire 时使用的构建标签，如 prod
+	WireMode    string `yaml:"wire_mode,omitempty"`    // 运行 wire 的方式: exec|gorun
+
+	Backend string `yaml:"backend,omitempty"` // 依赖注入后端: wire|fx
+
+	AnnotationTag string `yaml:"annotation_tag,omitempty"` // 注解标记，默认 @autowire
+
+	Strict bool `yaml:"strict,omitempty"` // 注解语法错误或输出警告时终止生成
+
+	SetOutputs   map[string]string `yaml:"set_outputs,omitempty"`    // Set 名称 -> 输出目录（相对模块根目录）
+	SetTags      map[string]string `yaml:"set_tags,omitempty"`       // Set 名称 -> 构建标签
+	SetBuildTags map[string]string `yaml:"set_build_tags,omitempty"` // Set 名称 -> Set 文件的构建约束
+
+	GOOS      string   `yaml:"goos,omitempty"`       // 评估源文件构建约束的目标操作系统，默认当前平台
+	GOARCH    string   `yaml:"goarch,omitempty"`     // 评估源文件构建约束的目标架构，默认当前平台
+	BuildTags []string `yaml:"build_tags,omitempty"` // 评估源文件构建约束时启用的构建标签
+
+	LogLevel string `yaml:"log_level,omitempty"` // 日志级别: debug|info|warn|error，默认 info
+
+	InitTemplate string `yaml:"init_template,omitempty"` // 初始化文件（wire.gen.go）的 text/template 模板路径
+
+	FilePrefix     string `yaml:"file_prefix,omitempty"`     // 生成文件名的前缀，默认 autowire
+	FileSuffix     string `yaml:"file_suffix,omitempty"`     // 生成文件名的后缀（.go 之前）
+	HeaderTemplate string `yaml:"header_template,omitempty"` // 生成文件头部注释的 text/template 模板路径
+
+	Plugins []string `yaml:"plugins,omitempty"` // 代码生成插件：Go 插件（.so）路径或可执行文件命令
+
+	// 批量生成的多个输出目标，共享一次扫描，未配置时只生成到 output_path
+	Targets []Target `yaml:"targets,omitempty"`
+
+	// 命名的配置档（如 dev、test、prod），通过 --profile 选择，其中的配置覆盖顶层的同名配置
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"`
+
+	dir string // 配置文件所在目录，header_template 等相对路径相对该目录解析；默认配置为空
+}
+
+// Target struct    批量生成的一个输出目标，其余配置与顶层相同.
+type Target struct {
+	OutputPath string   `yaml:"output_path"`          // 输出路径
+	Package    string   `yaml:"package,omitempty"`    // 包名，为空时按输出目录推断（不使用顶层的 package）
+	Sets       []string `yaml:"sets,omitempty"`       // 生成的 Set，为空表示全部
+	InitTypes  []string `yaml:"init_types,omitempty"` // 需要生成初始化函数的类型，为空时与顶层相同
+}
+
+// Options method    返回目标自身的选项（包名、Set 与初始化类型），追加在顶层配置的选项之后.
+func (t Target) Options() []Option {
+	opts := []Option{WithPkg(t.Package)}
+	if len(t.Sets) > 0 {
+		opts = append(opts, WithSets(t.Sets...))
+	}
+	if len(t.InitTypes) > 0 {
+		opts = append(opts, InitStruct(t.InitTypes...))
+	}
+	return opts
 }
 
 // DefaultConfig function    返回默认配置.
@@ -27,13 +102,30 @@ func DefaultConfig() *FileConfig {
 	return &FileConfig{
 		EnableCache: true,
 		Parallel:    0, // 自动检测
-		ExcludeDirs: []string{"vendor", "testdata", ".git"},
+		ExcludeDirs: slices.Clone(DefaultExcludeDirs),
 		Watch:       false,
+		IgnoreFiles: true,
+	}
+}
+
+// LoadConfigFile function    从文件加载配置，应用 profile 指定的配置档（为空时不应用）并展开路径中的环境变量
+// 未找到配置文件时使用默认配置.
+func LoadConfigFile(path, profile string) (*FileConfig, error) {
+	cfg, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return nil, err
+	}
+	if err := cfg.expandEnv(); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
 
-// LoadConfigFile function    从文件加载配置.
-func LoadConfigFile(path string) (*FileConfig, error) {
+// readConfigFile function    读取并解析配置文件，路径为空时查找默认配置文件，未找到时返回默认配置.
+func readConfigFile(path string) (*FileConfig, error) {
 	// 如果路径为空，尝试查找默认配置文件
 	if path == "" {
 		path = findConfigFile()
@@ -55,10 +147,69 @@ func LoadConfigFile(path string) (*FileConfig, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
+	cfg.dir = filepath.Dir(path)
 
 	return cfg, nil
 }
 
+// ApplyProfile method    使用指定配置档中的配置覆盖顶层配置，name 为空时不做处理
+// 配置档中未出现的配置保持顶层的值，列表整体替换，映射（如 set_outputs、set_tags）按键合并.
+func (c *FileConfig) ApplyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	node, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("配置档 %s 不存在（可选 %s）", name, strings.Join(slices.Sorted(maps.Keys(c.Profiles)), "、"))
+	}
+	profiles := c.Profiles
+	if err := node.Decode(c); err != nil {
+		return fmt.Errorf("解析配置档 %s 失败: %w", name, err)
+	}
+	// 配置档中不能再定义配置档
+	c.Profiles = profiles
+	return nil
+}
+
+// expandEnv method    展开 search_path、search_paths、output_path（包括 targets）与 exclude_dirs 中的环境变量
+// 支持 ${VAR}、$VAR 与 ${VAR:-默认值}，$$ 表示 $ 本身；引用未设置且没有默认值的环境变量时返回错误，
+// 避免生成到意外的目录。$$ 展开后不能再次展开，由 LoadConfigFile 在应用配置档之后调用一次.
+func (c *FileConfig) expandEnv() error {
+	var missing []string
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			if name == "$" {
+				return "$"
+			}
+			name, def, hasDef := strings.Cut(name, ":-")
+			if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDef) {
+				return v
+			}
+			if !hasDef && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return def
+		})
+	}
+
+	c.SearchPath = expand(c.SearchPath)
+	c.OutputPath = expand(c.OutputPath)
+	for i, p := range c.SearchPaths {
+		c.SearchPaths[i] = expand(p)
+	}
+	for i, dir := range c.ExcludeDirs {
+		c.ExcludeDirs[i] = expand(dir)
+	}
+	for i := range c.Targets {
+		c.Targets[i].OutputPath = expand(c.Targets[i].OutputPath)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("配置文件引用了未设置的环境变量: %s（可以使用 ${VAR:-默认值} 指定默认值）",
+			strings.Join(missing, "、"))
+	}
+	return nil
+}
+
 // SaveConfigFile method    保存配置到文件.
 func (c *FileConfig) SaveConfigFile(path string) error {
 	data, err := yaml.Marshal(c)
@@ -93,6 +244,15 @@ func findConfigFile() string {
 	return ""
 }
 
+// Searches method    返回配置的全部搜索路径（search_path 在前）.
+func (c *FileConfig) Searches() []string {
+	var paths []string
+	if c.SearchPath != "" {
+		paths = append(paths, c.SearchPath)
+	}
+	return append(paths, c.SearchPaths...)
+}
+
 // ToOptions method    将配置文件转换为选项.
 func (c *FileConfig) ToOptions() []Option {
 	var opts []Option
@@ -101,30 +261,136 @@ func (c *FileConfig) ToOptions() []Option {
 		opts = append(opts, WithPkg(c.Package))
 	}
 
-	if c.SearchPath != "" {
-		opts = append(opts, WithSearchPath(c.SearchPath))
+	if searches := c.Searches(); len(searches) > 0 {
+		opts = append(opts, WithSearchPaths(searches...))
 	}
 
 	if len(c.InitTypes) > 0 {
 		opts = append(opts, InitStruct(c.InitTypes...))
 	}
 
+	if c.DuplicateBinding != "" {
+		opts = append(opts, WithDuplicateBinding(c.DuplicateBinding))
+	}
+	if c.ConflictPolicy != "" {
+		opts = append(opts, WithConflictPolicy(c.ConflictPolicy))
+	}
+	if c.MissingProviders != "" {
+		opts = append(opts, WithMissingProviders(c.MissingProviders))
+	}
+
+	if level, err := ParseLogLevel(c.LogLevel); err == nil && c.LogLevel != "" {
+		opts = append(opts, WithLogger(logger.New(os.Stdout, level)))
+	}
+
+	if c.Parallel > 0 {
+		opts = append(opts, WithParallel(c.Parallel))
+	}
+
+	if len(c.SetOutputs) > 0 {
+		opts = append(opts, WithSetOutputs(c.SetOutputs))
+	}
+
+	if len(c.SetTags) > 0 {
+		opts = append(opts, WithSetTags(c.SetTags))
+	}
+
+	if len(c.SetBuildTags) > 0 {
+		opts = append(opts, WithSetBuildTags(c.SetBuildTags))
+	}
+
+	if c.IncludeGenerated {
+		opts = append(opts, WithIncludeGenerated(c.GeneratedGlobs...))
+	}
+
+	if c.GOOS != "" || c.GOARCH != "" {
+		opts = append(opts, WithTarget(c.GOOS, c.GOARCH))
+	}
+
+	if len(c.BuildTags) > 0 {
+		opts = append(opts, WithBuildTags(c.BuildTags...))
+	}
+
+	if c.WireVersion != "" {
+		opts = append(opts, WithWireVersion(c.WireVersion))
+	}
+
+	if c.WireTags != "" {
+		opts = append(opts, WithWireTags(c.WireTags))
+	}
+
+	if c.WireMode != "" {
+		opts = append(opts, WithWireMode(c.WireMode))
+	}
+
+	if c.Backend != "" {
+		opts = append(opts, WithBackend(c.Backend))
+	}
+
+	if c.AnnotationTag != "" {
+		opts = append(opts, WithTag(c.AnnotationTag))
+	}
+
+	if c.Strict {
+		opts = append(opts, WithStrict(true))
+	}
+
+	if c.InitTemplate != "" {
+		opts = append(opts, WithInitTemplate(c.InitTemplate))
+	}
+
+	if c.FilePrefix != "" || c.FileSuffix != "" {
+		opts = append(opts, WithFileNaming(c.FilePrefix, c.FileSuffix))
+	}
+
+	if c.HeaderTemplate != "" {
+		opts = append(opts, WithHeaderTemplate(c.resolvePath(c.HeaderTemplate)))
+	}
+
+	if len(c.Plugins) > 0 {
+		opts = append(opts, WithPlugins(c.Plugins...))
+	}
+
+	if len(c.ExcludeDirs) > 0 {
+		opts = append(opts, WithExcludeDirs(c.ExcludeDirs))
+	}
+
+	if len(c.IncludeOnly) > 0 {
+		opts = append(opts, WithIncludeOnly(c.IncludeOnly...))
+	}
+
+	if c.MaxDepth > 0 {
+		opts = append(opts, WithMaxDepth(c.MaxDepth))
+	}
+
 	return opts
 }
 
+// resolvePath method    将配置中的相对路径解析为相对配置文件所在目录的路径，与当前工作目录无关.
+func (c *FileConfig) resolvePath(p string) string {
+	if c.dir == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.dir, p)
+}
+
 // GenerateExampleConfig function    生成示例配置文件.
 func GenerateExampleConfig(path string) error {
-	example := &FileConfig{
+	return exampleConfig().SaveConfigFile(path)
+}
+
+// exampleConfig function    返回示例配置.
+func exampleConfig() *FileConfig {
+	return &FileConfig{
 		SearchPath:  "./",
 		OutputPath:  "./wire",
 		Package:     "wire",
 		InitTypes:   []string{"*"},
 		EnableCache: true,
 		Parallel:    0,
-		ExcludeDirs: []string{"vendor", "testdata", ".git"},
+		ExcludeDirs: slices.Clone(DefaultExcludeDirs),
 		Watch:       false,
 		WatchIgnore: []string{"*.gen.go", "wire_gen.go"},
+		IgnoreFiles: true,
 	}
-
-	return example.SaveConfigFile(path)
 }
@@ -1,20 +1,89 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/spelens-gud/gutowire/internal/logger"
 	"github.com/spelens-gud/gutowire/internal/parser"
 )
 
 // Opt struct    存储配置选项.
 type Opt struct {
-	SearchPath  string   // 依赖搜索路径，指定在哪个目录下查找依赖
-	Pkg         string   // 生成文件的包名
-	GenPath     string   // 生成文件的输出路径
-	InitWire    []string // 需要生成初始化函数的类型列表
-	EnableCache bool     // 是否启用缓存
-	ExcludeDirs []string // 排除的目录列表
+	SearchPath  string        // 依赖搜索路径，指定在哪个目录下查找依赖
+	SearchPaths []string      // 其他依赖搜索路径，与 SearchPath 合并扫描
+	Pkg         string        // 生成文件的包名
+	GenPath     string        // 生成文件的输出路径
+	InitWire    []string      // 需要生成初始化函数的类型列表
+	EnableCache bool          // 是否启用缓存
+	ExcludeDirs []string      // 排除的目录列表，支持相对模块根目录的 glob
+	IncludeOnly []string      // 只扫描的目录列表，支持 glob，为空表示全部
+	MaxDepth    int           // 扫描进入的最大目录深度，搜索路径本身为第 0 层，0 表示不限制
+	Logger      *slog.Logger  // 日志器，未设置时输出到标准输出
+	Progress    ProgressFunc  // 扫描进度回调，为 nil 时不报告进度
+	LockTimeout time.Duration // 等待生成目录锁的超时时间，0 表示使用默认值
+
+	Context context.Context // 取消时终止进行中的扫描、写入与 wire 命令，未设置时不会取消
+
+	DuplicateBinding string // 重复接口绑定的处理策略
+	ConflictPolicy   string // 不同 Set 绑定同一接口时的处理策略
+	MissingProviders string // 没有提供者的依赖的处理方式：error（默认）或 warn
+
+	IncludeGenerated bool     // 是否扫描生成的文件（带 Code generated ... DO NOT EDIT. 标记）
+	GeneratedGlobs   []string // 允许扫描的生成文件 glob，为空表示全部
+
+	WireVersion string // 固定的 wire 版本，为空时使用 PATH 中的 wire
+	WireTags    string // 运行 wire 时使用的构建标签，如 prod，选择 tag= 生成的提供者
+	WireMode    string // 运行 wire 的方式：exec（默认）或 gorun
+
+	Backend string // 生成的依赖注入后端：wire（默认）或 fx
+
+	Tag string // 注解标记，默认 @autowire，可改为 @inject、//go:autowire 等
+
+	Strict   bool             // 严格模式：注解语法错误或输出警告时终止生成，默认只输出警告
+	Warnings *logger.Recorder // 严格模式下记录 Logger 输出的警告，未启用严格模式时为 nil
+
+	Parallel int // 扫描与写入文件的最大并发数，0 表示使用 GOMAXPROCS
+
+	SetOutputs   map[string]string // Set 名称 -> 输出目录（相对模块根目录），未配置的 Set 生成到 GenPath
+	SetTags      map[string]string // Set 名称 -> 构建标签，Set 中没有 tag= 参数的组件使用该标签
+	SetBuildTags map[string]string // Set 名称 -> 构建约束，整个 Set 文件只在启用该标签时参与构建
+
+	GOOS      string   // 评估源文件构建约束的目标操作系统，为空时使用当前平台
+	GOARCH    string   // 评估源文件构建约束的目标架构，为空时使用当前平台
+	BuildTags []string // 评估源文件构建约束时启用的构建标签，如 integration
+
+	CheckOnly bool // 检查模式：不写入文件，只检查重新生成是否会修改生成的文件（包括 wire_gen.go）
+
+	InitTemplate string // 自定义初始化文件（wire.gen.go）模板的路径，为空时使用内置模板
+
+	FilePrefix     string // 生成文件名的前缀，默认 autowire，生成 <前缀>_<名称><后缀>.go
+	FileSuffix     string // 生成文件名的后缀（.go 之前），默认为空
+	HeaderTemplate string // 生成文件头部注释（如许可证）的 text/template 模板路径，为空时不添加
+
+	IgnoreFiles bool // 扫描与监听时是否跳过 .gitignore、.gutowireignore 忽略的目录与文件
+
+	Stamp *Stamp // 生成清单，不为 nil 时在生成目录写入 autowire.go（go:generate 指令与重新生成的参数）
+
+	Plugins []string // 代码生成插件：.so 结尾的 Go 插件路径，或可执行文件命令（可带参数）
+
+	SumFile bool // 成功生成后是否在生成目录写入生成校验文件 gutowire.sum，默认写入
+
+	Sets        []string // 只生成的 Set 名称，为空表示全部
+	TargetPaths []string // 批量生成时全部目标的生成路径，导入其中任一生成包的文件不参与扫描
+}
+
+// Stamp struct    生成清单中重新生成所需的信息.
+type Stamp struct {
+	Dir  string   // 执行 gutowire 的工作目录，相对生成目录
+	Args []string // gutowire stamp 的参数
 }
 
 // Option 配置函数类型，用于设置 Opt.
@@ -27,8 +96,11 @@ type Option func(*Opt)
 func NewGenOpt(genPath string, opts ...Option) *Opt {
 	o := &Opt{
 		GenPath:     genPath,
-		EnableCache: true,                                   // 默认启用缓存
-		ExcludeDirs: []string{"vendor", "testdata", ".git"}, // 默认排除目录
+		EnableCache: true,                             // 默认启用缓存
+		ExcludeDirs: slices.Clone(DefaultExcludeDirs), // 默认排除目录
+		IgnoreFiles: true,                             // 默认遵循 .gitignore 与 .gutowireignore
+		SumFile:     true,                             // 默认写入生成校验文件
+		FilePrefix:  FilePrefix,                       // 默认生成 autowire_*.go
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -49,11 +121,89 @@ func (o *Opt) init() {
 			o.Pkg = strings.ReplaceAll(filepath.Base(o.GenPath), "-", "_")
 		}
 	}
-	// 如果未指定搜索路径，使用 go.mod 所在目录
-	if len(o.SearchPath) == 0 {
+	// 如果未指定注解标记，使用 @autowire
+	if len(o.Tag) == 0 {
+		o.Tag = WireTag
+	}
+	// 如果未指定运行 wire 的方式，默认执行 wire 可执行文件
+	if len(o.WireMode) == 0 {
+		o.WireMode = WireModeExec
+	}
+	// embedded 为 gorun 的旧名称
+	if o.WireMode == WireModeEmbedded {
+		o.WireMode = WireModeGoRun
+	}
+	// 如果未指定后端，默认生成 wire 代码
+	if len(o.Backend) == 0 {
+		o.Backend = BackendWire
+	}
+	// 如果未指定并发数，使用 GOMAXPROCS
+	if o.Parallel <= 0 {
+		o.Parallel = runtime.GOMAXPROCS(0)
+	}
+	// 如果未指定重复绑定策略，默认报错
+	if len(o.DuplicateBinding) == 0 {
+		o.DuplicateBinding = DuplicateBindingError
+	}
+	// 如果未指定跨 Set 绑定冲突策略，默认报错
+	if len(o.ConflictPolicy) == 0 {
+		o.ConflictPolicy = ConflictPolicyError
+	}
+	// 如果未指定缺少提供者的处理方式，默认报错
+	if len(o.MissingProviders) == 0 {
+		o.MissingProviders = MissingProvidersError
+	}
+	// 如果未指定日志器，使用默认日志器
+	if o.Logger == nil {
+		o.Logger = logger.Default()
+	}
+	// 如果未指定 ctx，生成流程不会被取消
+	if o.Context == nil {
+		o.Context = context.Background()
+	}
+	// 严格模式下记录警告，由调用方在生成前后检查
+	if o.Strict && o.Warnings == nil {
+		o.Logger, o.Warnings = logger.Record(o.Logger)
+	}
+	// 如果未指定搜索路径，使用 go.mod 所在目录以及 go.work 中的其他模块
+	if len(o.SearchPath) == 0 && len(o.SearchPaths) == 0 {
 		modPath := parser.GetGoModDir()
 		if len(modPath) > 0 {
 			o.SearchPath = modPath
 		}
+		for _, m := range parser.GetWorkspaceModules() {
+			if rel, _ := parser.RelPath(modPath, m.Dir); rel != "." {
+				o.SearchPaths = append(o.SearchPaths, m.Dir)
+			}
+		}
+	}
+}
+
+// CheckFileNaming method    校验生成文件名的前缀与后缀：只能包含字母、数字、_、- 与 .，
+// 后缀不能以 _test 结尾（生成的文件会被当作测试文件）.
+func (o *Opt) CheckFileNaming() error {
+	if !fileNamePattern.MatchString(o.FilePrefix) {
+		return fmt.Errorf("无效的 file_prefix: %q（只能包含字母、数字、_、- 与 .）", o.FilePrefix)
+	}
+	if o.FileSuffix != "" && !fileNamePattern.MatchString(o.FileSuffix) {
+		return fmt.Errorf("无效的 file_suffix: %q（只能包含字母、数字、_、- 与 .）", o.FileSuffix)
+	}
+	if strings.HasSuffix(o.FileSuffix, "_test") {
+		return fmt.Errorf("无效的 file_suffix: %q（以 _test 结尾的文件只在测试时编译）", o.FileSuffix)
+	}
+	return nil
+}
+
+// fileNamePattern 生成文件名前缀与后缀允许的字符.
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// SearchRoots method    返回去重后的全部搜索路径，SearchPath 在前.
+func (o *Opt) SearchRoots() []string {
+	var roots []string
+	for _, p := range append([]string{o.SearchPath}, o.SearchPaths...) {
+		if p != "" && !slices.Contains(roots, filepath.Clean(p)) {
+			roots = append(roots, filepath.Clean(p))
+		}
 	}
+	return roots
 }
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+	"github.com/stoewer/go-strcase"
+	"golang.org/x/mod/modfile"
+)
+
+// generatedMarker 生成文件头部的标记，用于识别已有的生成目录.
+var generatedMarker = []byte("Code generated by go-autowire")
+
+// injectorMarker wire 注入器文件的构建约束，用于识别手写的 wire 配置目录.
+var injectorMarker = []byte("//go:build wireinject")
+
+// Proposal struct    初始化向导根据项目结构给出的配置建议.
+type Proposal struct {
+	ModuleDir  string   // go.mod 所在目录，没有找到时为空
+	ModulePath string   // go.mod 中声明的模块路径
+	SearchPath string   // 依赖搜索路径：go.mod 所在目录（相对当前目录）
+	OutputPath string   // 生成路径：已有生成文件或 wire 注入器所在的目录，默认 ./wire
+	Package    string   // 生成文件的包名，默认为生成路径的目录名
+	Sets       []string // 已有注解中的 Set，没有注解时为各个包的包名
+	Annotated  bool     // Sets 是否来自已有注解
+}
+
+// Propose function    扫描 dir 所在的模块，给出初始化配置的建议；dir 不在模块中时扫描 dir.
+func Propose(dir string) (Proposal, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Proposal{}, fmt.Errorf("获取绝对路径失败: %w", err)
+	}
+	p := Proposal{SearchPath: "./", OutputPath: "./wire", Package: "wire"}
+	if modDir, modPath, ok := findModule(abs); ok {
+		p.ModuleDir, p.ModulePath = modDir, modPath
+		p.SearchPath = relDir(abs, modDir)
+	}
+
+	root := cmp.Or(p.ModuleDir, abs)
+	sets, pkgs := parser.NewSet[string](), parser.NewSet[string]()
+	var genDir, genPkg, injectorDir, injectorPkg string
+	err = parser.Walk(root, parser.WalkOptions{}, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && skipProposalDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !parser.CheckFileType(info.Name()) {
+			return nil
+		}
+		//nolint:gosec
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		f, err := goparser.ParseFile(token.NewFileSet(), path, data, goparser.PackageClauseOnly)
+		if err != nil {
+			return nil
+		}
+		switch {
+		case bytes.Contains(data, generatedMarker):
+			if genDir == "" {
+				genDir, genPkg = filepath.Dir(path), f.Name.Name
+			}
+			return nil
+		case bytes.Contains(data, injectorMarker):
+			if injectorDir == "" {
+				injectorDir, injectorPkg = filepath.Dir(path), f.Name.Name
+			}
+			return nil
+		}
+		if f.Name.Name != "main" {
+			pkgs.Add(strcase.LowerCamelCase(f.Name.Name))
+		}
+		for line := range strings.Lines(string(data)) {
+			if a, err := annotations.ParseLoose(annotations.DefaultTag, line); err == nil {
+				for _, set := range a.Sets() {
+					sets.Add(strcase.LowerCamelCase(set))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Proposal{}, err
+	}
+
+	// 已有的生成文件优先于手写的 wire 注入器
+	switch {
+	case genDir != "":
+		p.OutputPath, p.Package = relDir(abs, genDir), genPkg
+	case injectorDir != "":
+		p.OutputPath, p.Package = relDir(abs, injectorDir), injectorPkg
+	}
+	p.Sets = sets.ToSlice()
+	p.Annotated = len(p.Sets) > 0
+	if !p.Annotated {
+		p.Sets = slices.DeleteFunc(pkgs.ToSlice(), func(s string) bool { return s == p.Package })
+	}
+	slices.Sort(p.Sets)
+	return p, nil
+}
+
+// Config method    返回按建议填写搜索路径、生成路径与包名的配置，其余配置与示例配置相同.
+func (p Proposal) Config() *FileConfig {
+	c := exampleConfig()
+	c.SearchPath, c.OutputPath, c.Package = p.SearchPath, p.OutputPath, p.Package
+	return c
+}
+
+// findModule function    从 dir 向上查找 go.mod，返回所在目录与模块路径.
+func findModule(dir string) (modDir, modPath string, ok bool) {
+	for d := dir; ; d = filepath.Dir(d) {
+		//nolint:gosec
+		if data, err := os.ReadFile(filepath.Join(d, "go.mod")); err == nil {
+			return d, modfile.ModulePath(data), true
+		}
+		if filepath.Dir(d) == d {
+			return "", "", false
+		}
+	}
+}
+
+// skipProposalDir function    判断扫描建议时是否跳过目录：默认排除的目录、隐藏目录与嵌套的模块.
+func skipProposalDir(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || parser.MatchAnyGlob(DefaultExcludeDirs, name) {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(path, "go.mod"))
+	return err == nil
+}
+
+// relDir function    返回 target 相对 base 的路径，子目录以 ./ 开头，如 ./wire.
+func relDir(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	switch {
+	case err != nil:
+		return target
+	case rel == ".":
+		return "./"
+	case strings.HasPrefix(rel, ".."):
+		return filepath.ToSlash(rel)
+	}
+	return "./" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,165 @@
+// Package diff 生成文本的 unified diff，
+// 用于预览重新生成会对生成文件造成的修改。
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Context 每处修改前后保留的上下文行数.
+const Context = 3
+
+// DevNull 文件不存在（新建或删除）时 ---、+++ 行中使用的文件名.
+const DevNull = "/dev/null"
+
+// edit struct    一行的编辑操作.
+type edit struct {
+	kind byte   // ' ' 相同、'-' 删除、'+' 插入
+	line string // 行内容，保留换行符
+}
+
+// Unified function    返回 before 到 after 的 unified diff，内容相同时返回空字符串
+// oldName、newName 为 ---、+++ 行中的文件名，文件不存在时使用 DevNull.
+func Unified(oldName, newName string, before, after []byte) string {
+	if bytes.Equal(before, after) {
+		return ""
+	}
+	edits := lineEdits(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	oldLine, newLine := 1, 1 // 下一个 edit 在两个文件中的行号
+	for i := 0; i < len(edits); {
+		// 查找下一处修改，间隔不超过两倍上下文的修改合并为同一个 hunk
+		first := i
+		for first < len(edits) && edits[first].kind == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for j := first; j < len(edits) && j-last <= 2*Context; j++ {
+			if edits[j].kind != ' ' {
+				last = j
+			}
+		}
+		start, end := max(first-Context, i), min(last+Context+1, len(edits))
+
+		// 跳过 hunk 之前的相同行
+		oldLine += start - i
+		newLine += start - i
+		oldCount, newCount := 0, 0
+		for _, e := range edits[start:end] {
+			if e.kind != '+' {
+				oldCount++
+			}
+			if e.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, e := range edits[start:end] {
+			b.WriteByte(e.kind)
+			b.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange function    返回 hunk 头部的行范围，没有行时起始行为前一行.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines function    按行拆分文本，保留换行符.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEdits function    使用 Myers 算法计算 a 到 b 的最短编辑序列.
+func lineEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2) // 对角线 k（加 offset）上走得最远的 x
+	var trace [][]int            // 每一步开始前 v 在 [-d, d] 范围内的值
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack function    从终点沿 trace 回溯出编辑序列.
+func backtrack(a, b []string, trace [][]int) []edit {
+	var edits []edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d] // 下标 i 对应对角线 i-d
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, edit{'+', b[y-1]})
+			y--
+		} else {
+			edits = append(edits, edit{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, edit{' ', a[x-1]})
+		x--
+		y--
+	}
+	slices.Reverse(edits)
+	return edits
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	before := "package wire\n\nimport (\n\t\"example.com/a\"\n)\n\nvar ASet = wire.NewSet(\n\ta.NewA,\n)\n"
+	after := "package wire\n\nimport (\n\t\"example.com/a\"\n\t\"example.com/b\"\n)\n\n" +
+		"var ASet = wire.NewSet(\n\ta.NewA,\n\tb.NewB,\n)\n"
+	want := `--- a/autowire_a.go
++++ b/autowire_a.go
+@@ -2,8 +2,10 @@
+ 
+ import (
+ 	"example.com/a"
++	"example.com/b"
+ )
+ 
+ var ASet = wire.NewSet(
+ 	a.NewA,
++	b.NewB,
+ )
+`
+	if got := Unified("a/autowire_a.go", "b/autowire_a.go", []byte(before), []byte(after)); got != want {
+		t.Errorf("Unified() =\n%s\nwant:\n%s", got, want)
+	}
+	if got := Unified("a", "b", []byte(before), []byte(before)); got != "" {
+		t.Errorf("Unified() 内容相同时应为空: %q", got)
+	}
+
+	want = "--- /dev/null\n+++ b/x.go\n@@ -0,0 +1,2 @@\n+package x\n+\n"
+	if got := Unified(DevNull, "b/x.go", nil, []byte("package x\n\n")); got != want {
+		t.Errorf("Unified() 新建文件 =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_Patch(t *testing.T) {
+	if _, err := exec.LookPath("patch"); err != nil {
+		t.Skip("没有 patch 命令")
+	}
+	var oldLines, newLines []string
+	for i := range 60 {
+		line := strings.Repeat("x", i%7)
+		oldLines = append(oldLines, line)
+		switch {
+		case i%13 == 0:
+			newLines = append(newLines, line+"!")
+		case i%17 == 0:
+		default:
+			newLines = append(newLines, line)
+		}
+		if i%19 == 0 {
+			newLines = append(newLines, "inserted")
+		}
+	}
+	before, after := strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f.txt")
+	if err := os.WriteFile(file, []byte(before), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("patch", "-s", file)
+	cmd.Stdin = strings.NewReader(Unified("a/f.txt", "b/f.txt", []byte(before), []byte(after)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("patch 失败: %v\n%s", err, out)
+	}
+	if got, _ := os.ReadFile(file); string(got) != after {
+		t.Errorf("patch 结果与新内容不一致:\n%s", got)
+	}
+}
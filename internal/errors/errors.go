@@ -22,14 +22,51 @@ const (
 	ErrorTypeWireError
 	// ErrorTypeFileNotFound 文件未找到.
 	ErrorTypeFileNotFound
+	// ErrorTypeDuplicateBinding 重复的接口绑定.
+	ErrorTypeDuplicateBinding
+	// ErrorTypeInvalidConfig 无效配置.
+	ErrorTypeInvalidConfig
+	// ErrorTypeStaleGenerated 生成的代码不是最新.
+	ErrorTypeStaleGenerated
+	// ErrorTypeAmbiguousFields 结构体中存在多个相同类型的注入字段.
+	ErrorTypeAmbiguousFields
+	// ErrorTypeDuplicateProvider 同一 Set 中多个组件提供相同的类型.
+	ErrorTypeDuplicateProvider
+	// ErrorTypeStrictWarnings 严格模式下输出了警告.
+	ErrorTypeStrictWarnings
 )
 
+// errorTypeNames 错误类型的名称，用于结构化输出.
+var errorTypeNames = map[ErrorType]string{
+	ErrorTypeUnknown:           "unknown",
+	ErrorTypeCircularDep:       "circular_dep",
+	ErrorTypeMissingDep:        "missing_dep",
+	ErrorTypeInvalidAnnotation: "invalid_annotation",
+	ErrorTypeWireError:         "wire_error",
+	ErrorTypeFileNotFound:      "file_not_found",
+	ErrorTypeDuplicateBinding:  "duplicate_binding",
+	ErrorTypeInvalidConfig:     "invalid_config",
+	ErrorTypeStaleGenerated:    "stale_generated",
+	ErrorTypeAmbiguousFields:   "ambiguous_fields",
+	ErrorTypeDuplicateProvider: "duplicate_provider",
+	ErrorTypeStrictWarnings:    "strict_warnings",
+}
+
+// String method    返回错误类型的名称.
+func (t ErrorType) String() string {
+	if name, ok := errorTypeNames[t]; ok {
+		return name
+	}
+	return errorTypeNames[ErrorTypeUnknown]
+}
+
 // FriendlyError struct    友好的错误信息.
 type FriendlyError struct {
 	Type        ErrorType // 错误类型
 	Message     string    // 错误信息
 	Suggestions []string  // 建议列表
 	Details     string    // 错误详情
+	Sources     []string  // 引起错误的注解位置，如 services/user/repo.go:14 的 @autowire 注解（Repo）
 	HelpURL     string    // 帮助链接
 }
 
@@ -47,6 +84,14 @@ func (e *FriendlyError) Error() string {
 		sb.WriteString("\n\n")
 	}
 
+	if len(e.Sources) > 0 {
+		sb.WriteString("错误来源:\n")
+		for _, source := range e.Sources {
+			sb.WriteString("  - 由 " + source + "引起\n")
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(e.Suggestions) > 0 {
 		sb.WriteString("! 建议:\n")
 		for i, suggestion := range e.Suggestions {
@@ -76,6 +121,26 @@ func NewCircularDepError(pkg string) *FriendlyError {
 	}
 }
 
+// NewProviderCycleError function    创建组件之间循环依赖的错误
+// cycle 为按依赖顺序排列且首尾相同的依赖链（建议包含源码位置），如 A → B → C → A.
+func NewProviderCycleError(cycle []string) *FriendlyError {
+	n := len(cycle)
+	if n > 1 && cycle[0] == cycle[n-1] {
+		n--
+	}
+	return &FriendlyError{
+		Type:    ErrorTypeCircularDep,
+		Message: fmt.Sprintf("检测到 %d 个组件之间存在循环依赖", n),
+		Details: "  " + strings.Join(cycle, "\n  → "),
+		Suggestions: []string{
+			"使用接口或延迟获取（如传入 func() T）打破循环",
+			"将公共依赖提取为独立的组件",
+			"使用 gutowire graph --focus 查看相关组件的依赖关系",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#circular-dependency",
+	}
+}
+
 // NewMissingDepError function    创建缺少依赖错误.
 func NewMissingDepError(typeName string) *FriendlyError {
 	return &FriendlyError{
@@ -105,6 +170,70 @@ func NewInvalidAnnotationError(annotation string, reason string) *FriendlyError
 	}
 }
 
+// NewDuplicateBindingError function    创建重复接口绑定错误
+// impls 为绑定同一接口的所有实现（建议包含源码位置）.
+func NewDuplicateBindingError(set, iface string, impls []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeDuplicateBinding,
+		Message: fmt.Sprintf("%s 中接口 %s 存在多个实现绑定", set, iface),
+		Details: "  - " + strings.Join(impls, "\n  - "),
+		Suggestions: []string{
+			"只保留一个实现的接口绑定参数",
+			"使用 priority=N 参数并配置 duplicate_binding: priority，保留优先级最高的实现",
+			"配置 duplicate_binding: split，将冲突的实现拆分到独立的 Set",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#duplicate-binding",
+	}
+}
+
+// NewSetConflictError function    创建不同 Set 绑定同一接口的冲突错误
+// bindings 为同时加入汇总 Sets 的各个绑定（建议包含源码位置与所在 Set）.
+func NewSetConflictError(iface string, bindings []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeDuplicateBinding,
+		Message: fmt.Sprintf("汇总 Sets 中接口 %s 被多个 Set 绑定", iface),
+		Details: "  - " + strings.Join(bindings, "\n  - "),
+		Suggestions: []string{
+			"只在一个 Set 中绑定该接口",
+			"配置 conflict_policy: prefer_set:<name>，保留指定 Set 的绑定",
+			"配置 conflict_policy: last_wins，保留汇总 Sets 中最后一个 Set（按名称排序）的绑定",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#conflict-policy",
+	}
+}
+
+// NewDuplicateProviderError function    创建重复提供者错误
+// providers 为提供同一类型的组件（建议包含源码位置与提供方式）.
+func NewDuplicateProviderError(set, typeName string, providers []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeDuplicateProvider,
+		Message: fmt.Sprintf("%s 中类型 %s 存在多个提供者", set, typeName),
+		Details: "  - " + strings.Join(providers, "\n  - "),
+		Suggestions: []string{
+			"删除多余组件的注解，或将其移到其他 Set",
+			"使用 qualifier= 参数为各个提供者生成不同的限定类型",
+			"使用 tag= 参数将不同环境的实现生成到互斥的构建标签中",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#duplicate-provider",
+	}
+}
+
+// NewAmbiguousFieldsError function    创建结构体注入字段类型重复错误
+// component 为结构体（建议包含源码位置），fields 为类型相同的字段分组，如 *db.DB: Primary、Replica.
+func NewAmbiguousFieldsError(component string, fields []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeAmbiguousFields,
+		Message: fmt.Sprintf("结构体 %s 中存在多个相同类型的注入字段，wire.Struct 无法区分", component),
+		Details: "  - " + strings.Join(fields, "\n  - "),
+		Suggestions: []string{
+			"使用 exclude= 参数排除多余的字段（保持零值），或使用 fields= 只注入需要的字段",
+			"为不需要注入的字段添加 `wire:\"-\"` 标签",
+			"为各字段定义不同的类型，或改用构造函数注入",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#field-filter",
+	}
+}
+
 // NewWireError function    创建 Wire 错误.
 func NewWireError(output string) *FriendlyError {
 	suggestions := []string{
@@ -143,6 +272,32 @@ func NewFileNotFoundError(path string) *FriendlyError {
 	}
 }
 
+// NewStaleGeneratedError function    创建生成代码过期错误，files 为重新生成时会变化的文件.
+func NewStaleGeneratedError(files []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeStaleGenerated,
+		Message: fmt.Sprintf("生成的代码不是最新的，重新生成会修改 %d 个文件", len(files)),
+		Details: "  - " + strings.Join(files, "\n  - "),
+		Suggestions: []string{
+			"在本地运行 gutowire 重新生成并提交生成的文件",
+			"检查生成的文件是否被手动修改",
+		},
+	}
+}
+
+// NewStrictWarningsError function    创建严格模式下输出了警告的错误.
+func NewStrictWarningsError(warnings []string) *FriendlyError {
+	return &FriendlyError{
+		Type:    ErrorTypeStrictWarnings,
+		Message: fmt.Sprintf("严格模式下不允许警告，共输出 %d 条警告", len(warnings)),
+		Details: "  - " + strings.Join(warnings, "\n  - "),
+		Suggestions: []string{
+			"按警告信息修正注解或文件权限后重新生成",
+			"暂时无法修正时去掉 --strict（或配置文件中的 strict: true）",
+		},
+	}
+}
+
 // WrapError function    包装错误为友好错误.
 func WrapError(err error, message string) *FriendlyError {
 	return &FriendlyError{
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+// normalizeTag function    规范化注解标记，去掉注释前缀，如 //go:autowire 返回 go:autowire.
+func normalizeTag(tag string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "//"))
+}
+
+// annotation method    返回注解标记，未配置时使用 config.WireTag.
+func (sc *AutoWireSearcher) annotation() string {
+	if sc.tag == "" {
+		return config.WireTag
+	}
+	return sc.tag
+}
+
+// docLine struct    去掉注释标记的一行文档注释.
+type docLine struct {
+	text string         // 注释内容
+	pos  token.Position // 内容在源文件中的起始位置
+}
+
+// docLines function    返回去掉注释标记的各行文档注释及其位置
+// 与 CommentGroup.Text 不同，保留 //go:autowire 这类指令形式的注释行；块注释按行拆分并去掉行首的 *.
+func docLines(fset *token.FileSet, cg *ast.CommentGroup) []docLine {
+	if cg == nil {
+		return nil
+	}
+	var lines []docLine
+	for _, c := range cg.List {
+		pos := fset.Position(c.Slash)
+		text := c.Text
+		if strings.HasPrefix(text, "//") {
+			body := strings.TrimPrefix(text[2:], " ")
+			pos.Column += len(text) - len(body)
+			lines = append(lines, docLine{text: body, pos: pos})
+			continue
+		}
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		pos.Column += 2
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				pos.Line++
+				pos.Column = 1
+			}
+			// 去掉块注释每行开头的 *，如 /**\n * @autowire(set=x)\n */
+			linePos := pos
+			if body, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "*"); ok {
+				linePos.Column += len(line) - len(body)
+				line = body
+			}
+			lines = append(lines, docLine{text: line, pos: linePos})
+		}
+	}
+	return lines
+}
+
+// specDocs function    返回声明的文档注释与行尾注释的各行，行尾注释如 type Foo struct{} // @autowire(set=x).
+func specDocs(fset *token.FileSet, doc, comment *ast.CommentGroup) []docLine {
+	return append(docLines(fset, doc), docLines(fset, comment)...)
+}
+
+// hasAnnotation method    判断文档注释中是否包含注解：注释行以注解标记开头（包括格式错误的注解），
+// 正文中提到注解标记（如 "处理 @autowire.value 变量"）的注释不是注解.
+func (sc *AutoWireSearcher) hasAnnotation(lines []docLine) bool {
+	return slices.ContainsFunc(lines, func(l docLine) bool {
+		_, err := annotations.ParseLoose(sc.annotation(), strings.TrimSpace(l.text))
+		return err != annotations.ErrNotAnnotation
+	})
+}
+
+// Diagnostic struct    注解语法问题及其源码位置.
+type Diagnostic struct {
+	Position token.Position `json:"position"` // 注解在源文件中的位置
+	Text     string         `json:"text"`     // 注解原文
+	Reason   string         `json:"reason"`   // 问题描述
+}
+
+// Err method    返回带源码位置与注解原文的友好错误.
+func (d Diagnostic) Err() error {
+	return errors.NewInvalidAnnotationError(d.Text, fmt.Sprintf("%s: %s", d.Position, d.Reason))
+}
+
+// checkAnnotations method    检查声明中注解的语法，返回带源码位置的问题列表
+// 解析时这些注解或参数会被忽略，检查结果用于给出提示或在严格模式下终止生成.
+func (sc *AutoWireSearcher) checkAnnotations(decls []tmpDecl, f *ast.File) []Diagnostic {
+	var diags []Diagnostic
+	for _, decl := range decls {
+		for _, line := range decl.docs {
+			text := strings.TrimSpace(line.text)
+			reason := sc.checkAnnotation(text)
+			if a, err := annotations.ParseLoose(sc.annotation(), text); err == nil && reason == "" {
+				switch {
+				case decl.pkgDoc && a.Suffix != compositeSuffix:
+					reason = fmt.Sprintf("package 文档注释中只支持组合 Set 注解，如 %s.set(name=app,include=db|http)",
+						sc.annotation())
+				case a.Suffix == annotations.SuffixRaw && decl.typeSpec == nil && !decl.pkgDoc:
+					reason = fmt.Sprintf("%s.raw 需要写在表达式提供的类型声明上", sc.annotation())
+				case a.Suffix == annotations.SuffixRaw && decl.typeSpec != nil:
+					reason = sc.checkRawExpr(f, a.Map()["expr"])
+				case a.Suffix == annotations.SuffixEnv && !decl.pkgDoc && !envTypeDecl(&decl):
+					reason = fmt.Sprintf("%s.env 需要写在非结构体类型声明（如 type Port int）或结构体字段上",
+						sc.annotation())
+				case a.Suffix == annotations.SuffixEnv && envTypeDecl(&decl) &&
+					envKind(decl.typeSpec.Type, a.Map()) == "":
+					reason = envKindError(decl.typeSpec.Type)
+				}
+			}
+			if reason == "" {
+				continue
+			}
+			pos := line.pos
+			pos.Column += len(line.text) - len(strings.TrimLeft(line.text, " \t"))
+			diags = append(diags, Diagnostic{Position: pos, Text: text, Reason: reason})
+		}
+		diags = append(diags, sc.checkFieldAnnotations(decl)...)
+	}
+	return diags
+}
+
+// checkFieldAnnotations method    检查结构体字段上的环境变量注解：字段需要单独声明为具名类型或基础类型，
+// 所在的结构体需要带有注解.
+func (sc *AutoWireSearcher) checkFieldAnnotations(decl tmpDecl) []Diagnostic {
+	var diags []Diagnostic
+	fields := slices.SortedFunc(maps.Keys(decl.fieldDocs), func(a, b *ast.Field) int {
+		return cmp.Compare(a.Pos(), b.Pos())
+	})
+	for _, field := range fields {
+		for _, line := range decl.fieldDocs[field] {
+			if !sc.isEnvAnnotation(line) {
+				continue
+			}
+			text := strings.TrimSpace(line.text)
+			reason := sc.checkAnnotation(text)
+			if a, err := annotations.ParseLoose(sc.annotation(), text); err == nil && reason == "" {
+				switch {
+				case !sc.hasAnnotation(decl.docs):
+					reason = fmt.Sprintf("结构体 %s 需要带有 %s 注解，字段才能读取环境变量", decl.name, sc.annotation())
+				case len(field.Names) != 1:
+					reason = "读取环境变量的字段需要单独声明"
+				case !envFieldType(field.Type):
+					reason = fmt.Sprintf("字段类型 %s 无法读取环境变量，只支持具名类型与基础类型", types.ExprString(field.Type))
+				case envKind(field.Type, a.Map()) == "":
+					reason = envKindError(field.Type)
+				}
+			}
+			if reason == "" {
+				continue
+			}
+			pos := line.pos
+			pos.Column += len(line.text) - len(strings.TrimLeft(line.text, " \t"))
+			diags = append(diags, Diagnostic{Position: pos, Text: text, Reason: reason})
+		}
+	}
+	return diags
+}
+
+// isEnvAnnotation method    判断注释行是否为环境变量注解（包括格式错误的注解）.
+func (sc *AutoWireSearcher) isEnvAnnotation(line docLine) bool {
+	a, err := annotations.ParseLoose(sc.annotation(), strings.TrimSpace(line.text))
+	return err != annotations.ErrNotAnnotation && a.Suffix == annotations.SuffixEnv
+}
+
+// checkAnnotation method    检查单行注解，返回问题描述；不是注解或没有问题时返回空字符串.
+func (sc *AutoWireSearcher) checkAnnotation(text string) string {
+	a, err := annotations.ParseTag(sc.annotation(), text)
+	if err == annotations.ErrNotAnnotation {
+		return ""
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if a.Suffix != annotations.SuffixMock && slices.ContainsFunc(a.Sets(), isMockSetName) {
+		return fmt.Sprintf("Set 名称 %s 保留给测试替身（%s.mock），请使用其他名称", mockSet, sc.annotation())
+	}
+	return ""
+}
+
+// Diagnostics method    返回所有源文件中的注解语法问题，按文件与位置排序.
+func (sc *AutoWireSearcher) Diagnostics() []Diagnostic {
+	var diags []Diagnostic
+	for _, file := range parser.SortedKeys(sc.fileDiagnostics) {
+		diags = append(diags, sc.fileDiagnostics[file]...)
+	}
+	return diags
+}
@@ -0,0 +1,178 @@
+package generator
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+func TestCustomAnnotationTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		src  string
+		want []string
+	}{
+		{"@inject", "package svc\n\n// @inject(set=svc)\ntype A struct{}\n\n// @autowire(set=svc)\ntype B struct{}\n",
+			[]string{"A"}},
+		{"//go:autowire", "package svc\n\n// A 组件.\n//\n//go:autowire(set=svc)\ntype A struct{}\n\n" +
+			"//go:autowire.init(set=svc)\nfunc NewB() *B { return nil }\n\ntype B struct{}\n", []string{"A", "NewB"}},
+		{"", "package svc\n\n// @autowire(set=svc)\ntype A struct{}\n", []string{"A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "svc.go", tt.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sc := &AutoWireSearcher{
+				mu:         &sync.Mutex{},
+				ElementMap: make(map[string]map[string]Element),
+				logger:     logger.Discard(),
+				tag:        annotations.NormalizeTag(tt.tag),
+			}
+			elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+				getImplement(f))
+			var names []string
+			for _, e := range elements {
+				names = append(names, e.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("elements = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationMentionedInProse(t *testing.T) {
+	src := `package svc
+
+// bindAll 为带 @autowire 注解的接口查找实现.
+func (s *S) bindAll() {}
+
+// parse 处理 @autowire.value 变量，见 @autowire(set=x) 的说明.
+func parse() {}
+
+// C 的文档提到 @autowire 注解.
+type C struct{}
+
+// S 服务.
+// @autowire(set=svc)
+type S struct{}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.New(&buf, slog.LevelWarn),
+	}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	var names []string
+	for _, d := range decls {
+		names = append(names, d.name)
+	}
+	if !slices.Equal(names, []string{"S"}) {
+		t.Errorf("collectAnnotatedDecls() = %v, want [S]", names)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("正文中提到注解标记不应产生警告:\n%s", buf.String())
+	}
+	if diags := sc.checkAnnotations(decls, f); len(diags) > 0 {
+		t.Errorf("checkAnnotations() = %v, want none", diags)
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	src := "package svc\n\n" +
+		"// @autowire(set=svc)\ntype A struct{}\n\n" +
+		"// @autowire set=svc\ntype B struct{}\n\n" +
+		"//   @autowire(set=svc,priority=high)\ntype C struct{}\n\n" +
+		"// @autowire.inti(set=svc)\nfunc NewD() *D { return nil }\n\ntype D struct{}\n\n" +
+		"/*\n  @autowire(set=svc,foo=bar)\n*/\ntype E struct{}\n\n" +
+		"// @autowired 不是注解\n// @autowire(set=svc,=x)\ntype F struct{}\n\n" +
+		"// @autowire(set=svc,impl=\"example.com/bar.Store|io.Writer\")\ntype G struct{}\n\n" +
+		"// @autowire(set=svc,impl=bar)\ntype H struct{}\n\n" +
+		"// @autowire(set=svc,scope=request)\ntype I struct{}\n\n" +
+		"// @autowire(set=svc,Animal:ref)\ntype J struct{}\n\n" +
+		"// @autowire(set=svc,Animal:ptr,impl=\"io.Writer:value\")\ntype K struct{}\n\n" +
+		"// @autowire(set=db|Mock)\ntype L struct{}\n\n" +
+		"// @autowire.mock(set=mock,for=Store)\ntype M struct{}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+
+	want := []string{
+		"svc.go:6:4 @autowire set=svc",
+		"svc.go:9:6 @autowire(set=svc,priority=high)",
+		"svc.go:12:4 @autowire.inti(set=svc)",
+		"svc.go:18:3 @autowire(set=svc,foo=bar)",
+		"svc.go:23:4 @autowire(set=svc,=x)",
+		"svc.go:29:4 @autowire(set=svc,impl=bar)",
+		"svc.go:32:4 @autowire(set=svc,scope=request)",
+		"svc.go:35:4 @autowire(set=svc,Animal:ref)",
+		"svc.go:41:4 @autowire(set=db|Mock)",
+	}
+	var got []string
+	for _, d := range sc.checkAnnotations(sc.collectAnnotatedDecls(fset, f), f) {
+		if d.Reason == "" || d.Err() == nil {
+			t.Errorf("%s: empty reason", d.Position)
+		}
+		got = append(got, d.Position.String()+" "+d.Text)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("diagnostics =\n%v\nwant\n%v", got, want)
+	}
+
+	// 普通组件不会注册到保留给测试替身的 mock Set
+	for _, elem := range sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+		getImplement(f)) {
+		if elem.Set == mockSet && !elem.Mock {
+			t.Errorf("%s registered to the reserved mock set", elem.Name)
+		}
+	}
+}
+
+func TestAnnotationStyles(t *testing.T) {
+	src := "package svc\n\n" +
+		"/* @autowire(set=svc) */\ntype A struct{}\n\n" +
+		"/**\n * B 组件.\n *\n * @autowire(set=svc)\n */\ntype B struct{}\n\n" +
+		"type C struct{} // @autowire(set=svc)\n\n" +
+		"type D struct {\n\tName string\n} // @autowire(set=svc)\n\n" +
+		"type (\n\tE struct{} // @autowire(set=svc)\n\tF struct{}\n)\n\n" +
+		"var G = &C{} // @autowire.value(set=svc)\n\n" +
+		"type H struct{} // @autowire set=svc\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	elements := sc.parseAnnotations(decls, "svc.go", "example.com/svc", f, getImplement(f))
+	if names := elementNames(elements); !slices.Equal(names, []string{"A", "B", "C", "D", "E", "G"}) {
+		t.Errorf("elements = %v, want [A B C D E G]", names)
+	}
+
+	// 块注释与行尾注释中的注解同样报告准确的位置
+	diags := sc.checkAnnotations(decls, f)
+	if len(diags) != 1 || diags[0].Position.String() != "svc.go:26:20" {
+		t.Errorf("diagnostics = %v, want svc.go:26:20", diags)
+	}
+}
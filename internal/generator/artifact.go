@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+// setFiles struct    本次生成的 Set 文件，供插件获取.
+type setFiles struct {
+	mu    sync.Mutex
+	files map[string]string // Set 名称 -> 生成的文件（绝对路径）
+}
+
+// add method    记录 Set 生成的文件，同一 Set 只记录第一次生成的文件.
+func (s *setFiles) add(set, fileName string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = make(map[string]string)
+	}
+	if _, ok := s.files[set]; !ok {
+		s.files[set] = fileName
+	}
+}
+
+// SetFiles method    返回本次生成的 Set 文件，Set 名称 -> 文件路径（绝对路径），fx 后端为空.
+func (sc *AutoWireSearcher) SetFiles() map[string]string {
+	if sc.setFiles == nil {
+		return nil
+	}
+	sc.setFiles.mu.Lock()
+	defer sc.setFiles.mu.Unlock()
+	return maps.Clone(sc.setFiles.files)
+}
+
+// Package method    返回生成文件的包名.
+func (sc *AutoWireSearcher) Package() string {
+	return sc.pkg
+}
+
+// GenPath method    返回生成目录.
+func (sc *AutoWireSearcher) GenPath() string {
+	return sc.genPath
+}
+
+// WriteArtifact method    原样写入插件生成的文件（不做格式化），相对路径相对生成目录
+// 文件必须位于生成目录中，绝对路径或 ../ 指向生成目录之外时返回错误；
+// 内容未变化时不写入；检查模式下不写入，内容不同或文件不存在时记录为待更新.
+func (sc *AutoWireSearcher) WriteArtifact(fileName string, data []byte) error {
+	path := fileName
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(sc.genPath, path)
+	}
+	rel, err := filepath.Rel(absPath(sc.genPath), absPath(path))
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return fmt.Errorf("文件 %s 不在生成目录 %s 中", fileName, sc.genPath)
+	}
+	fileName = path
+	//nolint:gosec
+	existing, err := os.ReadFile(fileName)
+	if err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if sc.pending != nil {
+		sc.pending.addContent(fileName, data)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	//nolint:gosec
+	if err := os.WriteFile(fileName, data, 0o644); err != nil {
+		return fmt.Errorf("写入文件 %s 失败: %w", fileName, err)
+	}
+	sc.written.add(absPath(fileName))
+	sc.logger.Debug("文件已写入", logger.EventKey, logger.EventFileWritten, "file", fileName)
+	return nil
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestWriteArtifact(t *testing.T) {
+	dir := t.TempDir()
+	genPath := filepath.Join(dir, "wire")
+	sc := &AutoWireSearcher{genPath: genPath, logger: logger.Discard(), written: &fileList{}}
+
+	for _, name := range []string{"docs/graph.md", filepath.Join(genPath, "graph.json")} {
+		if err := sc.WriteArtifact(name, []byte("{}")); err != nil {
+			t.Errorf("WriteArtifact(%s) error = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(genPath, "docs", "graph.md")); err != nil {
+		t.Error(err)
+	}
+
+	// 生成目录之外的路径
+	for _, name := range []string{"../escape.go", "docs/../../escape.go", filepath.Join(dir, "escape.go"), "."} {
+		if err := sc.WriteArtifact(name, []byte("{}")); err == nil {
+			t.Errorf("WriteArtifact(%s) 应该返回错误", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.go")); !os.IsNotExist(err) {
+		t.Errorf("生成目录之外的文件不应写入: %v", err)
+	}
+}
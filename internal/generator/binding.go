@@ -0,0 +1,351 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+	"github.com/stoewer/go-strcase"
+)
+
+// bindingConflict struct    同一 Set 中绑定同一接口的多个实现.
+type bindingConflict struct {
+	set   string   // Set 名称
+	iface string   // 接口的唯一标识（包路径.接口名）
+	keys  []string // 按优先级排序的元素 key，第一个为胜出者
+}
+
+// resolveDuplicateBindings method    按配置的策略处理同一 Set 中的重复接口绑定.
+func (sc *AutoWireSearcher) resolveDuplicateBindings() error {
+	if err := config.CheckDuplicateBinding(sc.dupPolicy); err != nil {
+		return err
+	}
+	conflicts := sc.findBindingConflicts()
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	switch sc.dupPolicy {
+	case config.DuplicateBindingPriority:
+		for _, c := range conflicts {
+			sc.dropLosingBindings(c)
+		}
+	case config.DuplicateBindingSplit:
+		for _, c := range conflicts {
+			sc.splitLosingElements(c)
+		}
+	default:
+		c := conflicts[0]
+		impls := parser.Map(c.keys, func(key string) string {
+			return describeElement(sc.ElementMap[c.set][key])
+		})
+		return errors.NewDuplicateBindingError(strcase.LowerCamelCase(c.set)+"Set", c.iface, impls)
+	}
+	return nil
+}
+
+// setConflict struct    汇总 Sets 中不同 Set 对同一接口的绑定.
+type setConflict struct {
+	iface    string    // 接口的唯一标识（包路径.接口名）
+	keys     []string  // 组件的 key，与 bindings 一一对应
+	bindings []Element // 绑定该接口的组件，按 Set 名称排序
+}
+
+// resolveSetConflicts method    按 conflict_policy 处理不同 Set 绑定同一接口且同时加入汇总 Sets 的冲突
+// 只有生成了引用 Sets 的初始化函数时才会冲突；prefer_set 保留指定 Set 的绑定，last_wins 保留最后一个 Set 的绑定，
+// 落选组件仍提供自身类型，只移除对该接口的绑定.
+func (sc *AutoWireSearcher) resolveSetConflicts() error {
+	preferSet, err := config.ParseConflictPolicy(sc.conflictPolicy)
+	if err != nil {
+		return err
+	}
+	if !sc.hasInjectors() {
+		return nil
+	}
+
+	for _, c := range sc.findSetConflicts() {
+		winner := -1
+		switch {
+		case preferSet != "":
+			winner = slices.IndexFunc(c.bindings, func(elem Element) bool { return elem.Set == preferSet })
+		case sc.conflictPolicy == config.ConflictPolicyLastWins:
+			winner = len(c.bindings) - 1
+		}
+		if winner < 0 {
+			err := errors.NewSetConflictError(c.iface, parser.Map(c.bindings, func(elem Element) string {
+				return describeElement(elem) + "：Set " + elem.Set
+			}))
+			if preferSet != "" {
+				err.Details += fmt.Sprintf("\n  conflict_policy 指定的 Set %s 未绑定该接口", preferSet)
+			}
+			return err
+		}
+		for i, elem := range c.bindings {
+			if i == winner {
+				continue
+			}
+			// 注册到多个 Set 的组件在每个 Set 中都忽略该绑定
+			for set, elements := range sc.ElementMap {
+				if _, ok := elements[c.keys[i]]; ok {
+					sc.dropBinding(set, c.keys[i], c.iface)
+				}
+			}
+			sc.logger.Info("跨 Set 重复绑定已按 conflict_policy 忽略", "iface", c.iface,
+				"element", describeElement(elem), "set", elem.Set, "winner", c.bindings[winner].Set)
+		}
+	}
+	return nil
+}
+
+// findSetConflicts method    查找汇总 Sets 中被多个 Set 绑定的接口，结果顺序稳定
+// 同一组件注册到多个 Set 时只生成到共享 Set，不算绑定冲突；构建标签互斥的绑定同样不算冲突.
+func (sc *AutoWireSearcher) findSetConflicts() []setConflict {
+	byIface := make(map[string]*setConflict) // 输出目录与接口 -> 绑定该接口的组件
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Interface || elem.Composite || elem.Mock || !sc.inSets(elem) {
+				continue
+			}
+			for _, itf := range elem.Implements {
+				id := elem.Out + "\x00" + bindingID(elem, itf)
+				c, ok := byIface[id]
+				if !ok {
+					c = &setConflict{iface: bindingID(elem, itf)}
+					byIface[id] = c
+				}
+				if slices.Contains(c.keys, key) {
+					continue
+				}
+				c.keys = append(c.keys, key)
+				c.bindings = append(c.bindings, elem)
+			}
+		}
+	}
+
+	var conflicts []setConflict
+	for _, id := range parser.SortedKeys(byIface) {
+		c := byIface[id]
+		elements := make(map[string]Element, len(c.keys))
+		for i, key := range c.keys {
+			elements[key] = c.bindings[i]
+		}
+		for _, keys := range bindingGroups(c.keys, elements) {
+			conflicts = append(conflicts, setConflict{iface: c.iface, keys: keys,
+				bindings: parser.Map(keys, func(key string) Element { return elements[key] })})
+		}
+	}
+	return conflicts
+}
+
+// findBindingConflicts method    查找所有 Set 中的重复接口绑定，结果顺序稳定
+// 与重复提供者的检查一致，生成到不同输出目录或构建标签互斥的实现不算冲突（见 bindingGroups）.
+func (sc *AutoWireSearcher) findBindingConflicts() []bindingConflict {
+	var conflicts []bindingConflict
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		elements := sc.ElementMap[set]
+		byIface := make(map[string][]string)
+		for _, key := range parser.SortedKeys(elements) {
+			elem := elements[key]
+			for _, itf := range elem.Implements {
+				id := bindingID(elem, itf)
+				byIface[id] = append(byIface[id], key)
+			}
+		}
+
+		for _, iface := range parser.SortedKeys(byIface) {
+			for _, keys := range bindingGroups(byIface[iface], elements) {
+				// 优先级高者在前，相同优先级保持原有顺序（先出现者胜出）
+				slices.SortStableFunc(keys, func(a, b string) int {
+					return cmp.Compare(elements[b].Priority, elements[a].Priority)
+				})
+				conflicts = append(conflicts, bindingConflict{set: set, iface: iface, keys: keys})
+			}
+		}
+	}
+	return conflicts
+}
+
+// bindingGroups function    按输出目录与构建标签将绑定同一接口的组件分组，返回包含多个组件的分组
+// 不带构建标签的组件在每个构建标签下都会编译，因此加入同一输出目录中的每个分组；不同构建标签的组件互不冲突.
+func bindingGroups(keys []string, elements map[string]Element) [][]string {
+	type groupID struct{ out, tag string }
+	ids := make(map[groupID]bool)
+	for _, key := range keys {
+		elem := elements[key]
+		ids[groupID{elem.Out, elem.Tag}] = true
+	}
+	var groups [][]string
+	for _, key := range keys {
+		id := groupID{elements[key].Out, elements[key].Tag}
+		if !ids[id] {
+			continue
+		}
+		ids[id] = false
+		group := parser.Filter(keys, func(k string) bool {
+			elem := elements[k]
+			return elem.Out == id.out && (elem.Tag == "" || elem.Tag == id.tag)
+		})
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// dropLosingBindings method    保留胜出者的接口绑定，移除其余实现对该接口的绑定.
+func (sc *AutoWireSearcher) dropLosingBindings(c bindingConflict) {
+	for _, key := range c.keys[1:] {
+		elem := sc.dropBinding(c.set, key, c.iface)
+		sc.logger.Info("重复绑定已按优先级忽略", "iface", c.iface, "element", describeElement(elem))
+	}
+}
+
+// dropBinding method    移除 Set 中组件对接口 iface 的绑定，不再绑定的接口同时从提供的类型中移除.
+func (sc *AutoWireSearcher) dropBinding(set, key, iface string) Element {
+	elem := sc.ElementMap[set][key]
+	var dropped []string
+	for _, itf := range elem.Implements {
+		if bindingID(elem, itf) == iface {
+			dropped = append(dropped, qualifiedInterface(elem, itf))
+		}
+	}
+	elem.Implements = slices.DeleteFunc(slices.Clone(elem.Implements), func(itf string) bool {
+		return bindingID(elem, itf) == iface
+	})
+	elem.Provides = slices.DeleteFunc(slices.Clone(elem.Provides), func(t string) bool {
+		return slices.Contains(dropped, t)
+	})
+	sc.ElementMap[set][key] = elem
+	return elem
+}
+
+// splitLosingElements method    将冲突中落选的实现移动到带后缀的独立 Set.
+func (sc *AutoWireSearcher) splitLosingElements(c bindingConflict) {
+	elements := sc.ElementMap[c.set]
+	for _, key := range c.keys[1:] {
+		elem, ok := elements[key]
+		if !ok {
+			// 同一元素可能因多个接口冲突已被移走
+			continue
+		}
+		newSet := c.set + strcase.UpperCamelCase(elem.Name)
+		delete(elements, key)
+		elem.Set = newSet
+		if sc.ElementMap[newSet] == nil {
+			sc.ElementMap[newSet] = make(map[string]Element)
+		}
+		sc.ElementMap[newSet][key] = elem
+		sc.splitSets.Add(newSet)
+		sc.logger.Info("重复绑定已拆分到独立 Set", "iface", c.iface,
+			"element", describeElement(elem), "set", newSet+"Set")
+	}
+}
+
+// checkDuplicateProviders method    在运行 wire 之前检查同一 Set 中提供相同类型的多个组件
+// 包括返回相同类型的构造函数、相同的结构体或值，以及直接返回接口的构造函数与绑定该接口的实现；
+// 生成到不同输出目录或构建标签互斥的组件不算冲突。错误中列出两个组件的源码位置与提供方式.
+func (sc *AutoWireSearcher) checkDuplicateProviders() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		elements := sc.ElementMap[set]
+		byType := make(map[string][]Element)
+		for _, key := range parser.SortedKeys(elements) {
+			elem := elements[key]
+			// 接口注解与组合 Set 不直接提供类型，config 组件的字段作为初始化函数参数传入
+			if elem.Interface || elem.Composite || elem.ConfigWire {
+				continue
+			}
+			for _, t := range elem.Provides {
+				byType[t] = append(byType[t], elem)
+			}
+		}
+
+		for _, t := range parser.SortedKeys(byType) {
+			providers := byType[t]
+			for i, a := range providers {
+				for _, b := range providers[i+1:] {
+					if a.Out == b.Out && (a.Tag == "" || b.Tag == "" || a.Tag == b.Tag) {
+						return errors.NewDuplicateProviderError(setVarName(set), t,
+							[]string{describeProvider(a, t), describeProvider(b, t)})
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// describeProvider function    返回组件提供类型 t 的方式及源码位置，用于重复提供者的错误信息.
+func describeProvider(elem Element, t string) string {
+	return describeElement(elem) + "：" + provideMethod(elem, t)
+}
+
+// provideMethod function    返回组件提供类型 t 的方式，如 wire.Bind 绑定接口、构造函数 NewDB 的返回值.
+func provideMethod(elem Element, t string) string {
+	var how string
+	switch {
+	case slices.ContainsFunc(elem.Implements, func(itf string) bool { return qualifiedInterface(elem, itf) == t }):
+		how = "wire.Bind 绑定接口"
+	case elem.ValueWire:
+		how = "wire.Value 值注入"
+	case elem.Raw != "":
+		how = "表达式 " + elem.Raw
+	case len(elem.Env) > 0:
+		how = "读取环境变量 " + strings.Join(elem.Env, "、")
+	case elem.Constructor != "":
+		how = "构造函数 " + elem.Constructor + " 的返回值"
+	default:
+		how = "wire.Struct 注入"
+	}
+	if elem.Tag != "" {
+		how += "，构建标签 " + elem.Tag
+	}
+	return how
+}
+
+// bindingID function    返回接口绑定的唯一标识
+// 未带包名的接口视为与实现位于同一包.
+func bindingID(elem Element, itf string) string {
+	if strings.Contains(itf, ".") {
+		return itf
+	}
+	return elem.PkgPath + "." + itf
+}
+
+// describeElement function    返回元素的描述（包含源码位置）.
+func describeElement(elem Element) string {
+	name := parser.AppendPkg(elem.Pkg, elem.Name)
+	if !elem.Position.IsValid() {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, elem.Position)
+}
+
+// 接口参数的绑定方式标记，如 @autowire(set=svc,Reader:ptr,Sizer:value).
+const (
+	bindPtr   = annotations.BindPtr   // wire.Bind(new(I), new(*T))
+	bindValue = annotations.BindValue // wire.Bind(new(I), new(T))
+)
+
+// splitBindKind function    拆分接口参数与绑定方式标记，如 Reader:ptr 返回 Reader、ptr，未标记时 kind 为空.
+func splitBindKind(s string) (itf, kind string) {
+	itf, kind, _ = strings.Cut(s, ":")
+	return itf, kind
+}
+
+// addInterface function    为组件添加绑定的接口，kind 为 ptr 或 value 时记录该接口的绑定方式.
+func addInterface(elem *Element, itf, kind string) {
+	elem.Implements = appendUnique(elem.Implements, itf)
+	if kind != bindPtr && kind != bindValue {
+		return
+	}
+	if elem.BindKinds == nil {
+		elem.BindKinds = make(map[string]string)
+	}
+	elem.BindKinds[itf] = kind
+}
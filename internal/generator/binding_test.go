@@ -0,0 +1,278 @@
+package generator
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func newBindingSearcher(policy string) *AutoWireSearcher {
+	return &AutoWireSearcher{
+		mu:        &sync.Mutex{},
+		dupPolicy: policy,
+		logger:    logger.Discard(),
+		splitSets: parser.NewSet[string](),
+		ElementMap: map[string]map[string]Element{
+			"db": {
+				"example.com/db/MySQL": {Name: "MySQL", Pkg: "db", PkgPath: "example.com/db", Implements: []string{"Store"},
+					Provides: []string{"example.com/db.MySQL", "example.com/db.Store"}},
+				"example.com/db/Postgres": {Name: "Postgres", Pkg: "db", PkgPath: "example.com/db", Implements: []string{"Store"},
+					Priority: 10, Provides: []string{"example.com/db.Postgres", "example.com/db.Store"}},
+				"example.com/db/Cache": {Name: "Cache", Pkg: "db", PkgPath: "example.com/db", Implements: []string{"io.Closer"}},
+			},
+		},
+	}
+}
+
+func TestResolveDuplicateBindings_Error(t *testing.T) {
+	sc := newBindingSearcher(config.DuplicateBindingError)
+	if err := sc.resolveDuplicateBindings(); err == nil {
+		t.Fatal("resolveDuplicateBindings() 应该返回错误")
+	}
+	// 无效的策略不会被当作 error 处理
+	sc = newBindingSearcher("first")
+	if err := sc.resolveDuplicateBindings(); err == nil || !strings.Contains(err.Error(), "duplicate_binding") {
+		t.Fatalf("resolveDuplicateBindings() error = %v", err)
+	}
+}
+
+func TestResolveDuplicateBindings_Priority(t *testing.T) {
+	sc := newBindingSearcher(config.DuplicateBindingPriority)
+	if err := sc.resolveDuplicateBindings(); err != nil {
+		t.Fatalf("resolveDuplicateBindings() error = %v", err)
+	}
+
+	db := sc.ElementMap["db"]
+	if got := db["example.com/db/Postgres"].Implements; len(got) != 1 {
+		t.Errorf("Postgres 应该保留绑定, got %v", got)
+	}
+	if got := db["example.com/db/MySQL"].Implements; len(got) != 0 {
+		t.Errorf("MySQL 的绑定应该被移除, got %v", got)
+	}
+	if err := sc.checkDuplicateProviders(); err != nil {
+		t.Errorf("按优先级处理后不应存在重复提供者: %v", err)
+	}
+	if got := db["example.com/db/Cache"].Implements; len(got) != 1 {
+		t.Errorf("Cache 不应受影响, got %v", got)
+	}
+}
+
+func TestResolveDuplicateBindings_Tag(t *testing.T) {
+	blob := func(name, tag string) Element {
+		return Element{Name: name, Pkg: "storage", PkgPath: "example.com/storage", Tag: tag,
+			Implements: []string{"Blob"}, Provides: []string{"example.com/storage." + name, "example.com/storage.Blob"}}
+	}
+	newSearcher := func(elements ...Element) *AutoWireSearcher {
+		sc := newBindingSearcher(config.DuplicateBindingError)
+		sc.ElementMap = map[string]map[string]Element{"storage": {}}
+		for _, elem := range elements {
+			sc.ElementMap["storage"][elem.PkgPath+"/"+elem.Name] = elem
+		}
+		return sc
+	}
+
+	// 构建标签互斥的实现不冲突
+	sc := newSearcher(blob("S3", "prod"), blob("Disk", "dev"))
+	if err := sc.resolveDuplicateBindings(); err != nil {
+		t.Errorf("resolveDuplicateBindings() prod/dev error = %v", err)
+	}
+	if err := sc.checkDuplicateProviders(); err != nil {
+		t.Errorf("checkDuplicateProviders() prod/dev error = %v", err)
+	}
+
+	// 相同构建标签或不带构建标签的实现在该标签下同时编译，仍然冲突
+	for _, tags := range [][2]string{{"prod", "prod"}, {"", "dev"}} {
+		sc := newSearcher(blob("S3", tags[0]), blob("Disk", tags[1]))
+		if err := sc.resolveDuplicateBindings(); err == nil || !strings.Contains(err.Error(), "Blob") {
+			t.Errorf("resolveDuplicateBindings() tags %q error = %v, want conflict", tags, err)
+		}
+	}
+
+	// 不带构建标签的实现与每个标签下的实现分别冲突，按优先级处理后各标签只保留一个绑定
+	sc = newSearcher(blob("S3", "prod"), blob("Disk", "dev"), blob("Memory", ""))
+	sc.dupPolicy = config.DuplicateBindingPriority
+	memory := sc.ElementMap["storage"]["example.com/storage/Memory"]
+	memory.Priority = -1
+	sc.ElementMap["storage"]["example.com/storage/Memory"] = memory
+	if err := sc.resolveDuplicateBindings(); err != nil {
+		t.Fatalf("resolveDuplicateBindings() error = %v", err)
+	}
+	for name, want := range map[string]int{"S3": 1, "Disk": 1, "Memory": 0} {
+		if got := sc.ElementMap["storage"]["example.com/storage/"+name].Implements; len(got) != want {
+			t.Errorf("%s.Implements = %v, want %d 个绑定", name, got, want)
+		}
+	}
+}
+
+func TestResolveDuplicateBindings_Split(t *testing.T) {
+	sc := newBindingSearcher(config.DuplicateBindingSplit)
+	if err := sc.resolveDuplicateBindings(); err != nil {
+		t.Fatalf("resolveDuplicateBindings() error = %v", err)
+	}
+
+	if _, ok := sc.ElementMap["db"]["example.com/db/MySQL"]; ok {
+		t.Error("MySQL 应该被移出 db Set")
+	}
+	split := sc.ElementMap["dbMySql"]
+	if elem, ok := split["example.com/db/MySQL"]; !ok || elem.Set != "dbMySql" {
+		t.Errorf("MySQL 应该被拆分到 dbMySql Set, got %v", sc.ElementMap)
+	}
+	if !sc.splitSets.Contains("dbMySql") {
+		t.Error("拆分出的 Set 应该被记录")
+	}
+}
+
+func TestCheckDuplicateProviders(t *testing.T) {
+	conn := func(name, ctor, tag string) Element {
+		return Element{Name: name, Pkg: "db", PkgPath: "example.com/db", FuncDecl: true, Constructor: ctor,
+			Tag: tag, Provides: []string{"example.com/db.Conn"}}
+	}
+	tests := []struct {
+		name     string
+		elements map[string]Element
+		wantErr  []string
+	}{
+		{
+			name: "构造函数返回相同类型",
+			elements: map[string]Element{
+				"example.com/db/NewConn": conn("NewConn", "NewConn", ""),
+				"example.com/db/OpenDB":  conn("OpenDB", "OpenDB", ""),
+			},
+			wantErr: []string{"DbSet 中类型 example.com/db.Conn 存在多个提供者", "db.NewConn：构造函数 NewConn 的返回值",
+				"db.OpenDB：构造函数 OpenDB 的返回值"},
+		},
+		{
+			name: "构造函数返回接口与绑定该接口的实现",
+			elements: map[string]Element{
+				"example.com/db/NewStore": {Name: "NewStore", Pkg: "db", PkgPath: "example.com/db", FuncDecl: true,
+					Constructor: "NewStore", Provides: []string{"example.com/db.Store"}},
+				"example.com/db/Postgres": {Name: "Postgres", Pkg: "db", PkgPath: "example.com/db",
+					Implements: []string{"Store"}, Provides: []string{"example.com/db.Postgres", "example.com/db.Store"}},
+			},
+			wantErr: []string{"example.com/db.Store", "db.Postgres：wire.Bind 绑定接口"},
+		},
+		{
+			name: "一个带构建标签",
+			elements: map[string]Element{
+				"example.com/db/NewConn": conn("NewConn", "NewConn", ""),
+				"example.com/db/NewMock": conn("NewMock", "NewMock", "dev"),
+			},
+			wantErr: []string{"构建标签 dev"},
+		},
+		{
+			name: "构建标签互斥",
+			elements: map[string]Element{
+				"example.com/db/NewConn": conn("NewConn", "NewConn", "prod"),
+				"example.com/db/NewMock": conn("NewMock", "NewMock", "dev"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: map[string]map[string]Element{"db": tt.elements}}
+			err := sc.checkDuplicateProviders()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("checkDuplicateProviders() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("checkDuplicateProviders() 应该返回错误")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error missing %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSetConflicts(t *testing.T) {
+	newSearcher := func(policy string) *AutoWireSearcher {
+		return &AutoWireSearcher{
+			mu:             &sync.Mutex{},
+			conflictPolicy: policy,
+			logger:         logger.Discard(),
+			splitSets:      parser.NewSet[string](),
+			ElementMap: map[string]map[string]Element{
+				"db": {"example.com/db/MySQL": {Name: "MySQL", Pkg: "db", PkgPath: "example.com/db", Set: "db",
+					Implements: []string{"example.com/store.Store"},
+					Provides:   []string{"example.com/db.MySQL", "example.com/store.Store"}}},
+				"cache": {"example.com/cache/Redis": {Name: "Redis", Pkg: "cache", PkgPath: "example.com/cache",
+					Set: "cache", Implements: []string{"example.com/store.Store"},
+					Provides: []string{"example.com/cache.Redis", "example.com/store.Store"}}},
+				"init": {"example.com/app/App": {Name: "App", Pkg: "app", PkgPath: "example.com/app", Set: "init",
+					InitWire: true, Injector: "App"}},
+			},
+		}
+	}
+	bound := func(sc *AutoWireSearcher) []string {
+		var sets []string
+		for _, set := range parser.SortedKeys(sc.ElementMap) {
+			for _, elem := range sc.ElementMap[set] {
+				if len(elem.Implements) > 0 {
+					sets = append(sets, set)
+				}
+			}
+		}
+		return sets
+	}
+
+	err := newSearcher(config.ConflictPolicyError).resolveSetConflicts()
+	if err == nil || !strings.Contains(err.Error(), "example.com/store.Store") {
+		t.Errorf("resolveSetConflicts() error = %v, want conflict", err)
+	}
+	err = newSearcher(config.ConflictPolicyPreferSet + "queue").resolveSetConflicts()
+	if err == nil || !strings.Contains(err.Error(), "Set queue 未绑定该接口") {
+		t.Errorf("resolveSetConflicts() 指定的 Set 未绑定接口时 error = %v", err)
+	}
+	if err := newSearcher("first_wins").resolveSetConflicts(); err == nil {
+		t.Error("resolveSetConflicts() 无效的策略应该返回错误")
+	}
+
+	for policy, want := range map[string]string{
+		config.ConflictPolicyPreferSet + "cache": "cache",
+		config.ConflictPolicyLastWins:            "db",
+	} {
+		sc := newSearcher(policy)
+		if err := sc.resolveSetConflicts(); err != nil {
+			t.Fatalf("resolveSetConflicts(%s) error = %v", policy, err)
+		}
+		if got := bound(sc); len(got) != 1 || got[0] != want {
+			t.Errorf("resolveSetConflicts(%s) 保留绑定的 Set = %v, want %s", policy, got, want)
+		}
+		if err := sc.checkDuplicateProviders(); err != nil {
+			t.Errorf("resolveSetConflicts(%s) 后不应存在重复提供者: %v", policy, err)
+		}
+	}
+	dropped := newSearcher(config.ConflictPolicyLastWins)
+	_ = dropped.resolveSetConflicts()
+	if got := dropped.ElementMap["cache"]["example.com/cache/Redis"].Provides; len(got) != 1 {
+		t.Errorf("落选组件应只移除接口类型, got %v", got)
+	}
+
+	// 构建标签互斥的绑定不算冲突
+	sc := newSearcher(config.ConflictPolicyError)
+	for set, tag := range map[string]string{"db": "prod", "cache": "dev"} {
+		for key, elem := range sc.ElementMap[set] {
+			elem.Tag = tag
+			sc.ElementMap[set][key] = elem
+		}
+	}
+	if err := sc.resolveSetConflicts(); err != nil {
+		t.Errorf("resolveSetConflicts() prod/dev error = %v", err)
+	}
+
+	// 没有初始化函数时各 Set 单独使用，不算冲突
+	sc = newSearcher(config.ConflictPolicyError)
+	delete(sc.ElementMap, "init")
+	if err := sc.resolveSetConflicts(); err != nil {
+		t.Errorf("resolveSetConflicts() 没有初始化函数时 error = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"go/build"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+// wireInjectTag wire 加载包时使用的构建标签，扫描时同样视为已启用.
+const wireInjectTag = "wireinject"
+
+// newBuildContext function    根据配置创建评估 //go:build 约束与文件名后缀（如 _windows.go）的构建环境
+// 未指定 GOOS/GOARCH 时使用当前平台（同样遵循 GOOS、GOARCH 环境变量）；
+// 构建标签包含配置的 build_tags、运行 wire 时的 wire_tags 以及 wireinject，与 wire 加载包时一致.
+func newBuildContext(o *config.Opt) *build.Context {
+	ctx := build.Default
+	if o.GOOS != "" {
+		ctx.GOOS = o.GOOS
+	}
+	if o.GOARCH != "" {
+		ctx.GOARCH = o.GOARCH
+	}
+	// 交叉编译时 go 默认禁用 cgo
+	if ctx.GOOS != runtime.GOOS || ctx.GOARCH != runtime.GOARCH {
+		ctx.CgoEnabled = false
+	}
+	tags := slices.Clone(o.BuildTags)
+	tags = append(tags, splitBuildTags(o.WireTags)...)
+	ctx.BuildTags = append(tags, wireInjectTag)
+	return &ctx
+}
+
+// splitBuildTags function    拆分以逗号或空格分隔的构建标签，如 prod,integration.
+func splitBuildTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// matchBuild method    判断文件是否满足构建约束，不满足的文件（如其他平台或未启用标签的文件）不参与扫描
+// 读取约束失败时按满足处理，由后续解析报告错误.
+func (sc *AutoWireSearcher) matchBuild(file string) bool {
+	if sc.buildCtx == nil {
+		return true
+	}
+	match, err := sc.buildCtx.MatchFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		sc.logger.Warn("读取构建约束失败", "file", file, "error", err)
+		return true
+	}
+	if !match {
+		sc.logger.Debug("不满足构建约束，跳过文件", "file", file)
+	}
+	return match
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestMatchBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"db.go":          "package db\n",
+		"db_windows.go":  "package db\n",
+		"db_linux.go":    "package db\n",
+		"integration.go": "//go:build integration\n\npackage db\n",
+		"injector.go":    "//go:build wireinject\n\npackage db\n",
+		"wire_gen.go":    "//go:build !wireinject\n\npackage db\n",
+		"prod.go":        "//go:build prod && linux\n\npackage db\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opt  config.Opt
+		want map[string]bool
+	}{
+		{
+			name: "linux",
+			opt:  config.Opt{GOOS: "linux", GOARCH: "amd64"},
+			want: map[string]bool{"db.go": true, "db_windows.go": false, "db_linux.go": true,
+				"integration.go": false, "injector.go": true, "wire_gen.go": false, "prod.go": false},
+		},
+		{
+			name: "windows with tags",
+			opt:  config.Opt{GOOS: "windows", BuildTags: []string{"integration"}, WireTags: "prod"},
+			want: map[string]bool{"db.go": true, "db_windows.go": true, "db_linux.go": false,
+				"integration.go": true, "injector.go": true, "wire_gen.go": false, "prod.go": false},
+		},
+		{
+			name: "linux with wire tags",
+			opt:  config.Opt{GOOS: "linux", WireTags: "prod,integration"},
+			want: map[string]bool{"db.go": true, "db_windows.go": false, "db_linux.go": true,
+				"integration.go": true, "injector.go": true, "wire_gen.go": false, "prod.go": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &AutoWireSearcher{mu: &sync.Mutex{}, logger: logger.Discard(), buildCtx: newBuildContext(&tt.opt)}
+			for name, want := range tt.want {
+				if got := sc.matchBuild(filepath.Join(dir, name)); got != want {
+					t.Errorf("matchBuild(%s) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -5,44 +5,133 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+	"github.com/stoewer/go-strcase"
 )
 
+// cacheVersion 缓存格式版本，Element 结构变化时递增，旧版本的缓存会被丢弃.
+const cacheVersion = 30
+
+// CacheFileName 缓存文件名，保存在生成目录中.
+const CacheFileName = ".gutowire.cache"
+
 // FileCache struct    文件缓存信息.
 type FileCache struct {
 	ModTime  time.Time `json:"mod_time"` // 文件修改时间
+	Size     int64     `json:"size"`     // 文件大小
 	Elements []Element `json:"elements"` // 解析出的元素
-	Hash     string    `json:"hash"`     // 文件内容哈希
+
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"` // 注解语法问题
+	Hash        string       `json:"hash"`                  // 文件内容哈希
+	Package     string       `json:"package,omitempty"`     // 同一包中其他文件的指纹，构造函数可以声明在其他文件中
+}
+
+// cacheData struct    缓存文件的内容.
+type cacheData struct {
+	Version int                   `json:"version"` // 缓存格式版本
+	Tag     string                `json:"tag"`     // 解析时使用的注解标记
+	Sets    map[string]string     `json:"sets"`    // 解析时使用的 Set 输出目录配置
+	Files   map[string]*FileCache `json:"files"`   // 源文件路径 -> 缓存信息
+	Outputs map[string]string     `json:"outputs"` // 生成文件路径 -> 生成输入的指纹
+
+	Generated []string `json:"generated,omitempty"` // 上次生成的全部文件（绝对路径），用于删除不再生成的文件
+	Include   string   `json:"include,omitempty"`   // 解析时扫描生成文件的配置，见 includeKey
 }
 
 // CacheManager struct    缓存管理器.
 type CacheManager struct {
 	cacheFile string                // 缓存文件路径
 	cache     map[string]*FileCache // 文件路径 -> 缓存信息
+	outputs   map[string]string     // 生成文件路径 -> 生成输入的指纹
 	mu        sync.RWMutex          // 读写锁
 	enabled   bool                  // 是否启用缓存
+	tag       string                // 注解标记，与缓存中记录的不一致时丢弃缓存
+	sets      map[string]string     // Set 输出目录配置，与缓存中记录的不一致时丢弃缓存
+	generated []string              // 上次生成的全部文件（绝对路径）
+	include   string                // 扫描生成文件的配置，与缓存中记录的不一致时丢弃缓存
+	memory    bool                  // 只在内存中缓存源文件的解析结果，不读写缓存文件
+	loaded    bool                  // 缓存文件已读取，在进程内共享时不重复读取
 }
 
 // NewCacheManager function    创建缓存管理器.
 func NewCacheManager(genPath string, enabled bool) *CacheManager {
 	return &CacheManager{
-		cacheFile: filepath.Join(genPath, ".gutowire.cache"),
+		cacheFile: filepath.Join(genPath, CacheFileName),
 		cache:     make(map[string]*FileCache),
+		outputs:   make(map[string]string),
 		enabled:   enabled,
 	}
 }
 
-// Load method    加载缓存.
-func (cm *CacheManager) Load() error {
+// NewSharedCache function    创建在进程内多次扫描之间共享的缓存，供 watch 模式使用
+// 完整重新扫描时内容未变化的文件直接复用解析结果；缓存文件只读取一次，
+// 未启用缓存时只在内存中缓存源文件的解析结果（不读写缓存文件，也不跳过生成文件的写入）.
+func NewSharedCache(o *config.Opt) *CacheManager {
+	cm := newCache(o)
 	if !cm.enabled {
+		cm.enabled, cm.memory = true, true
+	}
+	return cm
+}
+
+// newCache function    根据配置创建缓存管理器，记录影响解析结果的注解标记、Set 输出目录与生成文件的扫描配置.
+func newCache(o *config.Opt) *CacheManager {
+	cm := NewCacheManager(o.GenPath, o.EnableCache)
+	cm.tag = annotations.NormalizeTag(o.Tag)
+	cm.include = includeKey(o)
+	cm.sets = make(map[string]string, len(o.SetOutputs))
+	for set, out := range o.SetOutputs {
+		cm.sets[strcase.LowerCamelCase(set)] = filepath.ToSlash(filepath.Clean(out))
+	}
+	return cm
+}
+
+// includeKey function    返回生成文件的扫描配置：空表示跳过全部生成文件，* 表示扫描全部，否则为逗号分隔的 glob
+// 跳过的生成文件以空结果记录到缓存中，配置变化时需要重新检查.
+func includeKey(o *config.Opt) string {
+	switch {
+	case !o.IncludeGenerated:
+		return ""
+	case len(o.GeneratedGlobs) == 0:
+		return "*"
+	}
+	return strings.Join(o.GeneratedGlobs, ",")
+}
+
+// adoptFiles method    使用 src 中源文件的解析结果替换本缓存的记录，生成文件的指纹保持不变
+// 批量生成时各目标的缓存文件因此同样记录共享扫描的结果.
+func (cm *CacheManager) adoptFiles(src *CacheManager) {
+	src.mu.RLock()
+	files := maps.Clone(src.cache)
+	src.mu.RUnlock()
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.cache = files
+}
+
+// Load method    加载缓存，在进程内共享时只读取一次.
+func (cm *CacheManager) Load() error {
+	if !cm.enabled || cm.memory {
 		return nil
 	}
 
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
+	if cm.loaded {
+		return nil
+	}
+	cm.loaded = true
 
 	data, err := os.ReadFile(cm.cacheFile)
 	if err != nil {
@@ -52,23 +141,45 @@ func (cm *CacheManager) Load() error {
 		return fmt.Errorf("读取缓存文件失败: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &cm.cache); err != nil {
+	var cd cacheData
+	if err := json.Unmarshal(data, &cd); err != nil {
 		return fmt.Errorf("解析缓存文件失败: %w", err)
 	}
+	// 旧版本的缓存缺少新增的字段，直接丢弃；注解标记、Set 输出目录或生成文件的扫描配置变化后解析结果不再有效
+	if cd.Version != cacheVersion || cd.Tag != cm.tag || !maps.Equal(cd.Sets, cm.sets) ||
+		cd.Include != cm.include {
+		return nil
+	}
+	if cd.Files != nil {
+		cm.cache = cd.Files
+	}
+	if cd.Outputs != nil {
+		cm.outputs = cd.Outputs
+	}
+	cm.generated = cd.Generated
 
 	return nil
 }
 
 // Save method    保存缓存.
 func (cm *CacheManager) Save() error {
-	if !cm.enabled {
+	if !cm.enabled || cm.memory {
 		return nil
 	}
 
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	data, err := json.MarshalIndent(cm.cache, "", "  ")
+	data, err := json.MarshalIndent(cacheData{
+		Version: cacheVersion,
+		Tag:     cm.tag,
+		Sets:    cm.sets,
+		Files:   cm.cache,
+		Outputs: cm.outputs,
+
+		Generated: cm.generated,
+		Include:   cm.include,
+	}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化缓存失败: %w", err)
 	}
@@ -81,7 +192,9 @@ func (cm *CacheManager) Save() error {
 	return nil
 }
 
-// IsModified method    检查文件是否被修改.
+// IsModified method    检查文件是否被修改
+// 修改时间与大小均未变化时直接视为未修改；修改时间变化时再比较内容哈希，
+// 内容未变（如 git checkout、touch）时刷新记录的修改时间.
 func (cm *CacheManager) IsModified(filePath string) (bool, error) {
 	if !cm.enabled {
 		return true, nil // 缓存未启用，总是返回已修改
@@ -100,18 +213,46 @@ func (cm *CacheManager) IsModified(filePath string) (bool, error) {
 		return true, nil // 缓存中不存在
 	}
 
-	// 比较修改时间
-	if !info.ModTime().Equal(cached.ModTime) {
+	// 带注解的文件引用了同一包中其他文件的声明，其他文件变化时同样需要重新解析
+	if cached.Package != "" && packageFingerprint(filePath) != cached.Package {
+		return true, nil
+	}
+
+	// 修改时间与大小均未变化，无需读取文件
+	if info.ModTime().Equal(cached.ModTime) && info.Size() == cached.Size {
+		return false, nil
+	}
+	if info.Size() != cached.Size {
 		return true, nil
 	}
 
-	// 可选：比较文件哈希（更精确但更慢）
+	// 修改时间变化但大小相同，比较文件哈希
 	hash, err := cm.calculateHash(filePath)
 	if err != nil {
 		return true, err
 	}
+	if hash != cached.Hash {
+		return true, nil
+	}
 
-	return hash != cached.Hash, nil
+	cm.mu.Lock()
+	cached.ModTime = info.ModTime()
+	cm.mu.Unlock()
+	return false, nil
+}
+
+// Expire method    使记录的修改时间失效，下次检查时比较文件内容哈希
+// 用于 watch 模式中报告变更的文件：内容未变化时仍然复用解析结果，修改时间精度不足时也不会漏掉修改.
+func (cm *CacheManager) Expire(filePath string) {
+	if !cm.enabled {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cached, ok := cm.cache[filePath]; ok {
+		cached.ModTime = time.Time{}
+	}
 }
 
 // Get method    获取缓存的元素.
@@ -131,8 +272,23 @@ func (cm *CacheManager) Get(filePath string) ([]Element, bool) {
 	return cached.Elements, true
 }
 
+// Diagnostics method    获取缓存的注解语法问题.
+func (cm *CacheManager) Diagnostics(filePath string) []Diagnostic {
+	if !cm.enabled {
+		return nil
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cached, exists := cm.cache[filePath]; exists {
+		return cached.Diagnostics
+	}
+	return nil
+}
+
 // Set method    设置缓存.
-func (cm *CacheManager) Set(filePath string, elements []Element) error {
+func (cm *CacheManager) Set(filePath string, elements []Element, diags ...Diagnostic) error {
 	if !cm.enabled {
 		return nil
 	}
@@ -147,19 +303,114 @@ func (cm *CacheManager) Set(filePath string, elements []Element) error {
 		return err
 	}
 
+	pkg := ""
+	if len(elements) > 0 {
+		pkg = packageFingerprint(filePath)
+	}
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
 	cm.cache[filePath] = &FileCache{
 		ModTime:  info.ModTime(),
+		Size:     info.Size(),
 		Elements: elements,
 		Hash:     hash,
+		Package:  pkg,
+
+		Diagnostics: diags,
 	}
 
 	return nil
 }
 
-// Clear method    清空缓存.
+// Prune method    移除不在本次扫描文件列表中的缓存（文件已删除或被排除）.
+func (cm *CacheManager) Prune(files []string) {
+	if !cm.enabled {
+		return
+	}
+
+	keep := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		keep[f] = struct{}{}
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for f := range cm.cache {
+		if _, ok := keep[f]; !ok {
+			delete(cm.cache, f)
+		}
+	}
+}
+
+// Remove method    移除文件（或目录下所有文件）的缓存，按绝对路径匹配，扫描时记录的相对路径同样会被移除.
+func (cm *CacheManager) Remove(path string) {
+	if !cm.enabled {
+		return
+	}
+
+	abs := absPath(path)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for f := range cm.cache {
+		if file := absPath(f); file == abs || strings.HasPrefix(file, abs+string(filepath.Separator)) {
+			delete(cm.cache, f)
+		}
+	}
+}
+
+// OutputUnchanged method    判断生成文件的输入指纹是否与上次生成时一致且文件仍然存在.
+func (cm *CacheManager) OutputUnchanged(fileName, fingerprint string) bool {
+	if !cm.enabled || cm.memory {
+		return false
+	}
+
+	cm.mu.RLock()
+	prev, ok := cm.outputs[fileName]
+	cm.mu.RUnlock()
+	if !ok || prev != fingerprint {
+		return false
+	}
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// SetOutput method    记录生成文件的输入指纹.
+func (cm *CacheManager) SetOutput(fileName, fingerprint string) {
+	if !cm.enabled || cm.memory {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.outputs[fileName] = fingerprint
+}
+
+// Generated method    返回上次生成的全部文件（绝对路径）.
+func (cm *CacheManager) Generated() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return slices.Clone(cm.generated)
+}
+
+// SetGenerated method    记录本次生成的全部文件，并移除不再生成的文件的指纹.
+func (cm *CacheManager) SetGenerated(files []string) {
+	if !cm.enabled {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for f := range cm.outputs {
+		if abs := absPath(f); slices.Contains(cm.generated, abs) && !slices.Contains(files, abs) {
+			delete(cm.outputs, f)
+		}
+	}
+	cm.generated = files
+}
+
+// Clear method    清空缓存并删除缓存文件，缓存文件不存在时不返回错误.
 func (cm *CacheManager) Clear() error {
 	if !cm.enabled {
 		return nil
@@ -167,9 +418,39 @@ func (cm *CacheManager) Clear() error {
 
 	cm.mu.Lock()
 	cm.cache = make(map[string]*FileCache)
+	cm.outputs = make(map[string]string)
+	cm.generated = nil
 	cm.mu.Unlock()
 
-	return os.Remove(cm.cacheFile)
+	if cm.memory {
+		return nil
+	}
+	if err := os.Remove(cm.cacheFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除缓存文件失败: %w", err)
+	}
+	return nil
+}
+
+// packageFingerprint function    计算文件所在目录中其他非测试 Go 文件的指纹（名称、大小与修改时间）
+// 新增、删除或修改同一包中的文件时指纹变化.
+func packageFingerprint(filePath string) string {
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	if err != nil {
+		return ""
+	}
+	//nolint:gosec
+	h := md5.New()
+	for _, entry := range entries {
+		if entry.IsDir() || !parser.CheckFileType(entry.Name()) || entry.Name() == filepath.Base(filePath) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // calculateHash method    计算文件内容哈希.
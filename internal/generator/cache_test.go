@@ -0,0 +1,219 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+func TestCacheManager_IsModified(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte("package a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := NewCacheManager(dir, true)
+	if err := cm.Set(file, []Element{{Name: "A"}}); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := cm.IsModified(file); modified {
+		t.Error("文件未修改时应返回 false")
+	}
+
+	// 只修改时间（如 git checkout），内容不变
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := cm.IsModified(file); modified {
+		t.Error("内容未变化时应返回 false")
+	}
+
+	if err := os.WriteFile(file, []byte("package b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := cm.IsModified(file); !modified {
+		t.Error("内容变化时应返回 true")
+	}
+}
+
+func TestCacheManager_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	out := filepath.Join(dir, "autowire_a.go")
+	for _, f := range []string{file, out} {
+		if err := os.WriteFile(f, []byte("package a\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm := NewCacheManager(dir, true)
+	if err := cm.Set(file, []Element{{Name: "A"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Set(filepath.Join(dir, "deleted.go"), nil); err == nil {
+		t.Fatal("不存在的文件应该返回错误")
+	}
+	cm.SetOutput(out, "fp1")
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewCacheManager(dir, true)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if elems, ok := loaded.Get(file); !ok || len(elems) != 1 || elems[0].Name != "A" {
+		t.Errorf("Get() = %v, %v", elems, ok)
+	}
+	if !loaded.OutputUnchanged(out, "fp1") || loaded.OutputUnchanged(out, "fp2") {
+		t.Error("OutputUnchanged() 应该只在指纹一致时返回 true")
+	}
+
+	loaded.Prune(nil)
+	if _, ok := loaded.Get(file); ok {
+		t.Error("Prune() 应该移除不在扫描列表中的文件")
+	}
+
+	// 旧版本格式的缓存应被丢弃
+	if err := os.WriteFile(filepath.Join(dir, ".gutowire.cache"), []byte(`{"a.go":{"elements":[]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := NewCacheManager(dir, true)
+	if err := old.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := old.Get("a.go"); ok {
+		t.Error("旧版本缓存不应被使用")
+	}
+
+	// Clear 删除缓存文件，缓存文件不存在时不报错
+	for range 2 {
+		if err := old.Clear(); err != nil {
+			t.Fatalf("Clear() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, CacheFileName)); !os.IsNotExist(err) {
+		t.Errorf("Clear() 后缓存文件应被删除: %v", err)
+	}
+}
+
+func TestCacheManager_IncludeGenerated(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.pb.go")
+	src := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage a\n"
+	if err := os.WriteFile(file, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cm := newCache(config.NewGenOpt(dir))
+	if err := cm.Set(file, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 扫描生成文件的配置变化后丢弃缓存，跳过的生成文件需要重新检查
+	for _, tt := range []struct {
+		opts []config.Option
+		keep bool
+	}{
+		{nil, true},
+		{[]config.Option{config.WithIncludeGenerated()}, false},
+		{[]config.Option{config.WithIncludeGenerated("api/**")}, false},
+	} {
+		loaded := newCache(config.NewGenOpt(dir, tt.opts...))
+		if err := loaded.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := loaded.Get(file); ok != tt.keep {
+			t.Errorf("include=%q: 缓存保留 = %v, want %v", loaded.include, ok, tt.keep)
+		}
+	}
+}
+
+func TestSharedCache(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte("package a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未启用缓存文件时只在内存中缓存解析结果
+	cm := NewSharedCache(config.NewGenOpt(dir, config.WithCache(false)))
+	if err := cm.Set(file, []Element{{Name: "A"}}); err != nil {
+		t.Fatal(err)
+	}
+	if elements, ok := cm.Get(file); !ok || len(elements) != 1 {
+		t.Errorf("Get = %v, %v", elements, ok)
+	}
+	cm.SetOutput(file, "fp")
+	if cm.OutputUnchanged(file, "fp") {
+		t.Error("内存缓存不应跳过生成文件的写入")
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cm.cacheFile); !os.IsNotExist(err) {
+		t.Errorf("内存缓存不应写入缓存文件: %v", err)
+	}
+
+	// 修改时间与大小不变的修改：Expire 之后比较内容哈希
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("package b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := cm.IsModified(file); modified {
+		t.Fatal("修改时间与大小不变时不读取文件")
+	}
+	cm.Expire(file)
+	if modified, _ := cm.IsModified(file); !modified {
+		t.Error("Expire 之后内容变化应返回 true")
+	}
+}
+
+func TestCacheManager_Remove(t *testing.T) {
+	dir := t.TempDir()
+	cm := NewCacheManager(dir, true)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, name := range []string{"a.go", "sub/b.go", "sub/inner/c.go", "subway.go"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("package a\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// 扫描时可能记录相对路径
+		if rel, err := filepath.Rel(wd, file); err == nil && name == "sub/b.go" {
+			file = rel
+		}
+		if err := cm.Set(file, []Element{{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, file)
+	}
+
+	// 删除目录时移除其下全部文件的缓存，不影响名称前缀相同的文件
+	cm.Remove(filepath.Join(dir, "sub"))
+	cm.Remove(filepath.Join(dir, "a.go"))
+	for i, want := range []bool{false, false, false, true} {
+		if _, ok := cm.Get(keys[i]); ok != want {
+			t.Errorf("Get(%s) ok = %v, want %v", keys[i], ok, want)
+		}
+	}
+}
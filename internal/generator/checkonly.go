@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// fileList struct    并发安全的文件列表，记录检查模式下会变化的文件以及本次生成的文件.
+type fileList struct {
+	mu       sync.Mutex
+	files    []string
+	contents map[string][]byte // 检查模式下文件重新生成后的内容，没有记录表示文件会被删除
+}
+
+// add method    记录一个文件，重复记录时忽略.
+func (p *fileList) add(fileName string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !slices.Contains(p.files, fileName) {
+		p.files = append(p.files, fileName)
+	}
+}
+
+// addContent method    记录一个文件及其重新生成后的内容.
+func (p *fileList) addContent(fileName string, data []byte) {
+	p.add(fileName)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.contents == nil {
+		p.contents = make(map[string][]byte)
+	}
+	p.contents[fileName] = data
+}
+
+// content method    返回文件重新生成后的内容，文件会被删除时返回 false.
+func (p *fileList) content(fileName string) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, ok := p.contents[fileName]
+	return data, ok
+}
+
+// sorted method    返回排序后的文件列表.
+func (p *fileList) sorted() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	files := slices.Clone(p.files)
+	slices.Sort(files)
+	return files
+}
+
+// PendingChanges method    返回检查模式下重新生成时会被修改或删除的文件（已排序），非检查模式返回 nil.
+func (sc *AutoWireSearcher) PendingChanges() []string {
+	if sc.pending == nil {
+		return nil
+	}
+	return sc.pending.sorted()
+}
+
+// FileChange struct    检查模式下重新生成会对生成文件造成的修改.
+type FileChange struct {
+	File string // 文件路径
+	Old  []byte // 磁盘上的内容，文件不存在时为 nil
+	New  []byte // 重新生成后的内容，文件会被删除时为 nil
+}
+
+// PendingFileChanges method    返回检查模式下会被修改或删除的文件及其前后内容，按路径排序，非检查模式返回 nil.
+func (sc *AutoWireSearcher) PendingFileChanges() []FileChange {
+	var changes []FileChange
+	for _, file := range sc.PendingChanges() {
+		change := FileChange{File: file}
+		//nolint:gosec
+		if data, err := os.ReadFile(file); err == nil {
+			change.Old = data
+		}
+		change.New, _ = sc.pending.content(file)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// writeGenerated method    处理 import 后写入生成的文件
+// 检查模式下不写入，只与磁盘上的文件比较，内容不同或文件不存在时记录为待更新.
+func (sc *AutoWireSearcher) writeGenerated(fileName string, src []byte) error {
+	header, err := sc.header(fileName)
+	if err != nil {
+		return err
+	}
+	src = append(header, src...)
+	if sc.pending == nil {
+		return parser.ImportAndWrite(fileName, src)
+	}
+	data, err := parser.ProcessImports(src)
+	if err != nil {
+		return fmt.Errorf("处理 import 语句失败: %w", err)
+	}
+	//nolint:gosec
+	existing, err := os.ReadFile(fileName)
+	if err != nil || !bytes.Equal(existing, data) {
+		sc.pending.addContent(fileName, data)
+	}
+	return nil
+}
+
+// removeFile method    删除过期的生成文件，检查模式下只记录存在的文件为待删除.
+func (sc *AutoWireSearcher) removeFile(fileName string) error {
+	if sc.pending == nil {
+		return os.Remove(fileName)
+	}
+	if _, err := os.Stat(fileName); err != nil {
+		return err
+	}
+	sc.pending.add(fileName)
+	return nil
+}
+
+// ensureGenPath method    确保生成目录存在，检查模式下不创建.
+func (sc *AutoWireSearcher) ensureGenPath() error {
+	if sc.pending != nil {
+		return nil
+	}
+	if err := os.MkdirAll(sc.genPath, 0750); err != nil {
+		return fmt.Errorf("创建目录 %s 失败: %w", sc.genPath, err)
+	}
+	return nil
+}
+
+// saveCache method    保存缓存（包含生成文件的指纹），检查模式下不写入.
+func (sc *AutoWireSearcher) saveCache() {
+	if sc.pending != nil {
+		return
+	}
+	if err := sc.cache.Save(); err != nil {
+		sc.logger.Warn("保存缓存失败", "error", err)
+	}
+}
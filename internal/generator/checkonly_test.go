@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestCheckOnlyPendingChanges(t *testing.T) {
+	dir := t.TempDir()
+	src := []byte("package gen\n\nvar A = 1\n")
+	fresh := filepath.Join(dir, "autowire_fresh.go")
+	stale := filepath.Join(dir, "autowire_stale.go")
+	removed := filepath.Join(dir, "autowire_removed.go")
+	for _, f := range []string{fresh, removed} {
+		if err := parser.ImportAndWrite(f, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(stale, []byte("package gen\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, genPath: dir, logger: logger.Discard(), pending: &fileList{}}
+	for _, f := range []string{fresh, stale, filepath.Join(dir, "autowire_new.go")} {
+		if err := sc.writeGenerated(f, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sc.removeFile(removed); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "autowire_new.go"), removed, stale}
+	if got := sc.PendingChanges(); !slices.Equal(got, want) {
+		t.Errorf("PendingChanges() = %v, want %v", got, want)
+	}
+	// 检查模式下不修改任何文件
+	if data, _ := os.ReadFile(stale); string(data) != "package gen\n" {
+		t.Errorf("stale file modified: %q", data)
+	}
+	if _, err := os.Stat(removed); err != nil {
+		t.Errorf("removed file deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "autowire_new.go")); !os.IsNotExist(err) {
+		t.Errorf("new file written: %v", err)
+	}
+
+	// 记录前后内容供 --diff 输出：新文件没有旧内容，删除的文件没有新内容
+	changes := sc.PendingFileChanges()
+	if len(changes) != 3 {
+		t.Fatalf("PendingFileChanges() = %d, want 3", len(changes))
+	}
+	if c := changes[0]; c.Old != nil || string(c.New) != string(src) {
+		t.Errorf("new file change = %q -> %q", c.Old, c.New)
+	}
+	if c := changes[1]; string(c.Old) != string(src) || c.New != nil {
+		t.Errorf("removed file change = %q -> %q", c.Old, c.New)
+	}
+	if c := changes[2]; string(c.Old) != "package gen\n" || string(c.New) != string(src) {
+		t.Errorf("stale file change = %q -> %q", c.Old, c.New)
+	}
+}
+
+func TestSortImports(t *testing.T) {
+	spec := func(name, path string) *ast.ImportSpec {
+		imp := &ast.ImportSpec{Path: &ast.BasicLit{Value: `"` + path + `"`}}
+		if name != "" {
+			imp.Name = ast.NewIdent(name)
+		}
+		return imp
+	}
+	specs := []*ast.ImportSpec{
+		spec("", "example.com/b"), spec("svc2", "example.com/a/svc"), spec("", "example.com/a"),
+		spec("", "example.com/b"), spec("", "example.com/a/svc"),
+	}
+	var got []string
+	for _, imp := range sortImports(specs) {
+		s := imp.Path.Value
+		if imp.Name != nil {
+			s = imp.Name.Name + " " + s
+		}
+		got = append(got, s)
+	}
+	want := []string{`"example.com/a"`, `"example.com/a/svc"`, `svc2 "example.com/a/svc"`, `"example.com/b"`}
+	if !slices.Equal(got, want) {
+		t.Errorf("sortImports() = %v, want %v", got, want)
+	}
+}
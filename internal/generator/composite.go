@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+	"github.com/stoewer/go-strcase"
+)
+
+// compositeSuffix 组合 Set 注解的后缀，如 @autowire.set(name=app,include=db|http).
+const compositeSuffix = annotations.SuffixSet
+
+// collectPackageDecl method    收集 package 子句文档注释中的注解，这里只识别组合 Set.
+func (sc *AutoWireSearcher) collectPackageDecl(fset *token.FileSet, f *ast.File) []tmpDecl {
+	docs := docLines(fset, f.Doc)
+	if !sc.hasAnnotation(docs) {
+		return nil
+	}
+	return []tmpDecl{{docs: docs, name: f.Name.Name, pkgDoc: true, pos: fset.Position(f.Package)}}
+}
+
+// collectComposite method    解析 @autowire.set(name=app,include=db|http) 注解
+// 组合 Set 引用已生成的 Set（或其他组合 Set），不加入汇总 Sets.
+func (sc *AutoWireSearcher) collectComposite(options map[string]string, decl *tmpDecl, f *ast.File,
+	pkgPath string) *Element {
+	elem := Element{
+		Name:      strcase.LowerCamelCase(options["name"]),
+		Pkg:       f.Name.Name,
+		PkgPath:   pkgPath,
+		Position:  decl.pos,
+		Composite: true,
+	}
+	for _, set := range splitFieldList(options["include"]) {
+		elem.Includes = appendUnique(elem.Includes, strcase.LowerCamelCase(set))
+	}
+	if elem.Name == "" || len(elem.Includes) == 0 {
+		sc.logger.Warn("组合 Set 需要指定 name 与 include 参数，已忽略", "pos", decl.pos)
+		return nil
+	}
+	sc.addComposite(elem)
+	return &elem
+}
+
+// addComposite method    记录组合 Set.
+func (sc *AutoWireSearcher) addComposite(elem Element) {
+	sc.logger.Debug("收集到组合 Set", "set", setVarName(elem.Name), "include", strings.Join(elem.Includes, "|"))
+	if sc.streaming {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.composites = append(sc.composites, elem)
+}
+
+// checkComposites method    检查组合 Set：名称不能与组件的 Set 或其他组合 Set 重复，
+// 包含的 Set 需要存在且生成到生成路径，不能循环包含，也不能通过不同的路径重复包含同一个 Set
+// （wire 会将重复导入的提供者视为冲突）.
+func (sc *AutoWireSearcher) checkComposites() error {
+	slices.SortFunc(sc.composites, compareElements)
+	composites := make(map[string]Element, len(sc.composites))
+	for _, c := range sc.composites {
+		if prev, ok := composites[c.Name]; ok {
+			return compositeError(c, fmt.Sprintf("组合 Set %s 重复定义: %s、%s", setVarName(c.Name),
+				describeElement(prev), describeElement(c)))
+		}
+		if _, ok := sc.ElementMap[c.Name]; ok {
+			return compositeError(c, fmt.Sprintf("组合 Set %s 与组件的 Set 同名: %s", setVarName(c.Name),
+				describeElement(c)))
+		}
+		composites[c.Name] = c
+	}
+
+	for _, c := range sc.composites {
+		for _, set := range c.Includes {
+			if _, ok := composites[set]; ok {
+				continue
+			}
+			if reason := sc.includableSet(set); reason != "" {
+				return compositeError(c, fmt.Sprintf("组合 Set %s 包含的 %s %s: %s", setVarName(c.Name),
+					setVarName(set), reason, describeElement(c)))
+			}
+		}
+		if _, err := expandComposite(c.Name, composites, nil); err != nil {
+			return compositeError(c, fmt.Sprintf("%s: %s", err, describeElement(c)))
+		}
+	}
+	return nil
+}
+
+// includableSet method    判断组件的 Set 能否被组合 Set 包含，返回不能包含的原因.
+func (sc *AutoWireSearcher) includableSet(set string) string {
+	elements, ok := sc.ElementMap[set]
+	switch {
+	case !ok:
+		return "不存在"
+	case set == mockSet:
+		return "只生成到测试注入包"
+	case sc.setBuildTags[set] != "":
+		return "带构建约束 " + sc.setBuildTags[set] + "，组合 Set 不能包含"
+	}
+	for _, elem := range elements {
+		if elem.Out != "" && !elem.InitWire && !elem.ConfigWire {
+			return "生成到输出目录 " + elem.Out + "，组合 Set 只能包含生成路径中的 Set"
+		}
+	}
+	return ""
+}
+
+// expandComposite function    展开组合 Set 包含的全部组件 Set，返回 组件 Set -> 包含路径
+// 循环包含或通过不同的路径包含同一个 Set 时返回错误.
+func expandComposite(name string, composites map[string]Element, stack []string) (map[string]string, error) {
+	stack = append(stack, setVarName(name))
+	leaves := make(map[string]string)
+	for _, set := range composites[name].Includes {
+		sub := map[string]string{set: setVarName(set)}
+		if _, ok := composites[set]; ok {
+			if slices.Contains(stack, setVarName(set)) {
+				return nil, fmt.Errorf("组合 Set 循环包含: %s -> %s", strings.Join(stack, " -> "), setVarName(set))
+			}
+			var err error
+			if sub, err = expandComposite(set, composites, stack); err != nil {
+				return nil, err
+			}
+		}
+		for _, leaf := range parser.SortedKeys(sub) {
+			via := setVarName(set)
+			if sub[leaf] != via {
+				via += " -> " + sub[leaf]
+			}
+			if prev, ok := leaves[leaf]; ok {
+				return nil, fmt.Errorf("组合 Set %s 重复包含 %s（%s 与 %s）", setVarName(name), setVarName(leaf),
+					prev, via)
+			}
+			leaves[leaf] = via
+		}
+	}
+	return leaves, nil
+}
+
+// compositeError function    返回组合 Set 注解的错误.
+func compositeError(c Element, reason string) error {
+	return errors.NewInvalidAnnotationError(
+		fmt.Sprintf("@autowire.set(name=%s,include=%s)", c.Name, strings.Join(c.Includes, "|")), reason)
+}
+
+// compositeFiles method    返回组合 Set 的生成文件名.
+func (sc *AutoWireSearcher) compositeFiles() []string {
+	files := make([]string, 0, len(sc.composites))
+	for _, c := range sc.composites {
+		files = append(files, sc.setFileName(c.Name))
+	}
+	return files
+}
+
+// writeComposites method    为每个组合 Set 生成 autowire_<name>.go，引用包含的 Set
+// 包含共享组件的 Set 以私有 Set 引用，共享 Set 由组合 Set 只包含一次，避免 wire 报告重复绑定.
+func (sc *AutoWireSearcher) writeComposites() error {
+	withShared := sc.setsWithShared()
+	for _, c := range sc.composites {
+		data := &WireSet{Package: sc.pkg, SetName: setVarName(c.Name)}
+		items := make([]string, 0, len(c.Includes))
+		for _, set := range c.Includes {
+			if !withShared.Contains(set) {
+				items = append(items, setVarName(set))
+				continue
+			}
+			items = append(items, strcase.LowerCamelCase(setVarName(set)))
+			data.Shared = sharedSetName
+		}
+		data.Items = []string{strings.Join(items, ",\n\t")}
+		if err := sc.writeTemplateFile(sc.setFileName(c.Name), SetTemp, data, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFxComposites method    fx 后端为每个组合 Set 生成 fx.Options，引用包含的模块.
+func (sc *AutoWireSearcher) writeFxComposites() error {
+	for _, c := range sc.composites {
+		data := FxModule{Package: sc.pkg, Name: fxModuleName(c.Name), Options: parser.Map(c.Includes, fxModuleName)}
+		if err := sc.writeTemplateFile(sc.setFileName(c.Name), FxModuleTemp, data, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestComposites(t *testing.T) {
+	src := "// Package app 应用.\n//\n// @autowire.set(name=app,include=core|http)\n" +
+		"// @autowire.set(name=core,include=db|cache)\n" +
+		"// @autowire(set=app)\npackage app\n\n" +
+		"// @autowire(set=http)\ntype Server struct{}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "app.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	elements := sc.parseAnnotations(decls, "app.go", "example.com/app", f, getImplement(f))
+
+	var names []string
+	for _, e := range elements {
+		names = append(names, e.Name)
+	}
+	// package 文档注释中的 @autowire(set=app) 被忽略
+	if !slices.Equal(names, []string{"app", "core", "Server"}) {
+		t.Errorf("elements = %v", names)
+	}
+	if diags := sc.checkAnnotations(decls, f); len(diags) != 1 || diags[0].Position.Line != 5 {
+		t.Errorf("diagnostics = %v", diags)
+	}
+
+	sc.ElementMap["db"] = map[string]Element{"example.com/db/DB": {Name: "DB"}}
+	if err := sc.checkComposites(); err == nil || !strings.Contains(err.Error(), "CacheSet 不存在") {
+		t.Errorf("checkComposites() error = %v", err)
+	}
+	sc.ElementMap["cache"] = map[string]Element{"example.com/cache/Cache": {Name: "Cache"}}
+	if err := sc.checkComposites(); err != nil {
+		t.Errorf("checkComposites() error = %v", err)
+	}
+
+	// 通过不同的路径包含同一个 Set
+	sc.composites[0].Includes = append(sc.composites[0].Includes, "db")
+	if err := sc.checkComposites(); err == nil || !strings.Contains(err.Error(), "重复包含 DbSet（CoreSet -> DbSet 与 DbSet）") {
+		t.Errorf("checkComposites() error = %v", err)
+	}
+
+	// 循环包含
+	sc.composites[0].Includes = []string{"core"}
+	sc.composites[1].Includes = []string{"app"}
+	if err := sc.checkComposites(); err == nil || !strings.Contains(err.Error(), "循环包含: AppSet -> CoreSet -> AppSet") {
+		t.Errorf("checkComposites() error = %v", err)
+	}
+}
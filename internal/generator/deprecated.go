@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Deprecation struct    已弃用（deprecated= 参数）的组件及依赖链上用到它的初始化函数.
+type Deprecation struct {
+	Element   string   // 组件描述，包含源码位置
+	Message   string   // 弃用说明，如 use NewClientV2
+	Injectors []string // 用到该组件的初始化函数，如 InitializeApp，按名称排序
+}
+
+// Deprecations method    返回初始化函数依赖链上用到的已弃用组件，按包路径与名称排序
+// 没有生成初始化函数时无法确定使用方（Set 可能由手写的 wire.Build 引用），返回全部已弃用组件，Injectors 为空；
+// 需要在 Validate 之后调用，此时重复绑定已按配置的策略处理.
+func (sc *AutoWireSearcher) Deprecations() []Deprecation {
+	byKey := make(map[string]*Deprecation)
+	add := func(elem Element, injector string) {
+		key := elem.PkgPath + "/" + instanceName(elem)
+		d, ok := byKey[key]
+		if !ok {
+			d = &Deprecation{Element: describeElement(elem), Message: elem.Deprecated}
+			byKey[key] = d
+		}
+		if injector != "" && !slices.Contains(d.Injectors, injector) {
+			d.Injectors = append(d.Injectors, injector)
+		}
+	}
+
+	if !sc.hasInjectors() {
+		for _, set := range parser.SortedKeys(sc.ElementMap) {
+			for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+				if elem := sc.ElementMap[set][key]; elem.Deprecated != "" && !elem.Mock {
+					add(elem, "")
+				}
+			}
+		}
+	} else {
+		providers := sc.providerIndex()
+		for _, root := range sc.injectorRoots() {
+			injector := "Initialize" + appName(root)
+			walkInjector(root, providers, func(elem Element) {
+				if elem.Deprecated != "" && !elem.Mock {
+					add(elem, injector)
+				}
+			}, nil)
+		}
+	}
+
+	deprecations := make([]Deprecation, 0, len(byKey))
+	for _, key := range parser.SortedKeys(byKey) {
+		d := byKey[key]
+		slices.Sort(d.Injectors)
+		deprecations = append(deprecations, *d)
+	}
+	return deprecations
+}
+
+// String method    返回弃用警告文本，如 svc.Client (svc/client.go:12) 已弃用: use NewClientV2（InitializeApp 使用）.
+func (d Deprecation) String() string {
+	s := d.Element + " 已弃用"
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+	if len(d.Injectors) > 0 {
+		s += "（" + strings.Join(d.Injectors, "、") + " 使用）"
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const deprecatedSrc = `package app
+
+// @autowire.init(set=app)
+type App struct {
+	Client *Client
+}
+
+// @autowire.init(set=app)
+type Job struct {
+	Client *Client
+}
+
+// @autowire(set=app,deprecated="use NewClientV2, see docs")
+func NewClient() *Client { return nil }
+
+type Client struct{}
+
+// @autowire(set=app,deprecated=legacy)
+type Unused struct{}
+`
+
+func TestDeprecations(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, deprecatedSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+		initWire:   []string{"*"},
+	}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	if diags := sc.checkAnnotations(decls, f); len(diags) != 0 {
+		t.Errorf("checkAnnotations() = %v, want none", diags)
+	}
+	sc.ElementMap["app"] = make(map[string]Element)
+	for _, e := range sc.parseAnnotations(decls, file, "example.com/app", f, getImplement(f)) {
+		sc.ElementMap["app"][e.PkgPath+"/"+e.Name] = e
+	}
+	if got := sc.ElementMap["app"]["example.com/app/NewClient"].Deprecated; got != "use NewClientV2, see docs" {
+		t.Errorf("Deprecated = %q", got)
+	}
+
+	// 只报告初始化函数用到的组件，并列出全部使用方
+	got := sc.Deprecations()
+	if len(got) != 1 || got[0].Message != "use NewClientV2, see docs" ||
+		!slices.Equal(got[0].Injectors, []string{"InitializeApp", "InitializeJob"}) {
+		t.Fatalf("Deprecations() = %+v", got)
+	}
+	want := got[0].Element + " 已弃用: use NewClientV2, see docs（InitializeApp、InitializeJob 使用）"
+	if s := got[0].String(); s != want {
+		t.Errorf("String() = %q, want %q", s, want)
+	}
+
+	// 没有初始化函数时无法确定使用方，报告全部已弃用组件
+	sc.initWire = nil
+	if got := sc.Deprecations(); len(got) != 2 || len(got[0].Injectors) != 0 {
+		t.Errorf("Deprecations() without injectors = %+v", got)
+	}
+}
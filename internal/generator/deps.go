@@ -0,0 +1,499 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// resolveDeps method    解析组件提供的类型与依赖的类型
+// 类型统一使用 "包路径.类型名" 表示并去掉指针，用于构建依赖图.
+func (sc *AutoWireSearcher) resolveDeps(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string) {
+	r := typeResolver{file: f, pkgPath: pkgPath}
+
+	// 类型声明本身即为提供的类型
+	if decl.typeSpec != nil {
+		wireElement.Provides = append(wireElement.Provides, pkgPath+"."+decl.name+typeParamKeys(decl.typeSpec, pkgPath))
+		// 类型别名与被引用的类型相同，如 type Client = redis.Client 同样提供 redis.Client
+		if decl.typeSpec.Assign.IsValid() {
+			wireElement.Provides = appendUnique(wireElement.Provides, r.typeKey(decl.typeSpec.Type))
+		}
+	}
+
+	switch {
+	case decl.method != nil:
+		// 方法工厂：接收者与方法参数为依赖，第一个返回值为提供的类型
+		wireElement.Deps = append(r.fieldListTypes(decl.method.Recv), r.fieldListTypes(decl.method.Type.Params)...)
+		if res := r.fieldListTypes(decl.method.Type.Results); len(res) > 0 {
+			wireElement.Provides = appendUnique(wireElement.Provides, res[0])
+			dropSelfBindings(wireElement, r, res[0])
+		}
+		resolveResults(wireElement, decl.method.Type.Results)
+	case decl.valueSpec != nil:
+		// 包级变量：提供变量的类型，绑定接口时提供的是接口类型
+		if t := sc.valueType(decl, f, r); t != "" && len(wireElement.Implements) == 0 {
+			wireElement.Provides = append(wireElement.Provides, t)
+		}
+	case wireElement.ConfigWire:
+		// config 组件作为初始化函数参数传入，提供其导出字段的类型
+		wireElement.Provides = append(wireElement.Provides, r.fieldTypes(decl.typeSpec, wireElement.Fields)...)
+	case wireElement.Constructor != "":
+		// 构造函数：参数为依赖，第一个返回值为提供的类型
+		if fd := sc.findFuncDecl(f, wireElement.Constructor); fd != nil {
+			// 构造函数可以声明在包中的其他文件中，参数与返回值按该文件的导入解析
+			cr := typeResolver{file: sc.declFile(f, wireElement.Constructor), pkgPath: pkgPath}
+			wireElement.Deps = cr.fieldListTypes(fd.Type.Params)
+			if decl.typeSpec != nil {
+				wireElement.BadResult = sc.checkConstructorResult(wireElement, fd, cr)
+			}
+			if res := cr.fieldListTypes(fd.Type.Results); len(res) > 0 {
+				// 泛型类型的构造函数以返回值中构造函数的类型参数为准，替换类型声明提供的 Repo[T]
+				wireElement.Provides = slices.DeleteFunc(wireElement.Provides, func(p string) bool {
+					return p != res[0] && typeBase(p) == typeBase(res[0])
+				})
+				wireElement.Provides = appendUnique(wireElement.Provides, res[0])
+				dropSelfBindings(wireElement, r, res[0])
+			}
+			resolveResults(wireElement, fd.Type.Results)
+		}
+	case decl.typeSpec != nil:
+		// wire.Struct 注入：除 wire:"-" 外的所有字段（或 fields=、exclude= 指定的字段）均为依赖
+		if st := structOf(decl); st != nil {
+			fields := selectFields(st, wireElement.StructFields)
+			wireElement.Deps = r.fieldListTypes(fields)
+			wireElement.Ambiguous = r.ambiguousFields(fields)
+		}
+	}
+
+	// 绑定的接口
+	for _, itf := range wireElement.Implements {
+		wireElement.Provides = appendUnique(wireElement.Provides, r.qualifyName(itf))
+	}
+
+	// 泛型组件的类型参数替换为 of= 指定的类型实参
+	if params := sc.typeParamList(wireElement, decl, f); params != nil {
+		substituteTypeParams(wireElement, params, pkgPath)
+	}
+}
+
+// checkConstructorResult method    检查类型声明的构造函数（New<T>、Init<T> 或 new= 指定）是否提供该类型：
+// 第一个返回值需要为 T、*T、类型别名引用的类型或 T 实现的接口（无法确定时视为接口）；
+// 确定不匹配时返回构造函数的签名与位置，否则返回空字符串.
+func (sc *AutoWireSearcher) checkConstructorResult(wireElement *Element, fd *ast.FuncDecl, r typeResolver) string {
+	if fd.Type.Results != nil && len(fd.Type.Results.List) > 0 {
+		result := fd.Type.Results.List[0].Type
+		key := typeBase(r.typeKey(result))
+		if slices.ContainsFunc(wireElement.Provides, func(p string) bool { return typeBase(p) == key }) ||
+			(sc.mayBeInterface(result, r.file) && sc.implementsResult(wireElement, result, r)) {
+			return ""
+		}
+	}
+	sig := wireElement.Constructor + strings.TrimPrefix(types.ExprString(fd.Type), "func")
+	if pos := sc.fileSet().Position(fd.Name.Pos()); pos.IsValid() {
+		sig += " (" + pos.String() + ")"
+	}
+	return sig
+}
+
+// mayBeInterface method    判断返回类型是否可能是接口：本包的接口类型、error、any，
+// 以及其他包中无法确定种类的类型；指针、切片等复合类型与本包的非接口类型返回 false
+// 本包的类型按 types.Info 中的底层类型判断，底层类型无法确定（如引用了无法加载的包）时视为接口.
+func (sc *AutoWireSearcher) mayBeInterface(expr ast.Expr, f *ast.File) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return t.Name == "error" || t.Name == "any"
+		}
+		d, ok := sc.lookupDecl(f, t.Name)
+		if !ok {
+			return true
+		}
+		tn, ok := d.obj.(*types.TypeName)
+		if !ok {
+			return true
+		}
+		switch u := tn.Type().Underlying().(type) {
+		case *types.Interface:
+			return true
+		case *types.Basic:
+			return u.Kind() == types.Invalid
+		}
+		return false
+	case *ast.ParenExpr:
+		return sc.mayBeInterface(t.X, f)
+	case *ast.IndexExpr:
+		return sc.mayBeInterface(t.X, f)
+	case *ast.IndexListExpr:
+		return sc.mayBeInterface(t.X, f)
+	case *ast.SelectorExpr, *ast.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// checkConstructors method    检查类型声明的构造函数是否提供该类型
+// 不匹配时 wire 报告的错误指向生成的文件，在生成之前给出组件与构造函数两处源码位置.
+func (sc *AutoWireSearcher) checkConstructors() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.BadResult == "" {
+				continue
+			}
+			reason := fmt.Sprintf("构造函数 %s 的第一个返回值不是 %s、*%s 或其实现的接口，无法提供 %s；"+
+				"请修改构造函数的返回值，或通过 new= 指定其他构造函数",
+				elem.BadResult, elem.Name, elem.Name, describeElement(elem))
+			return errors.NewInvalidAnnotationError(sc.annotation(), reason)
+		}
+	}
+	return nil
+}
+
+// checkBindKinds method    检查接口的 :ptr、:value 标记与构造函数的返回值是否一致
+// 构造函数提供的类型由返回值决定，返回 *T 时不能按值绑定 T，返回 T 时不能绑定 *T.
+func (sc *AutoWireSearcher) checkBindKinds() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Constructor == "" || elem.TypeParams != 0 || len(elem.BindKinds) == 0 {
+				continue
+			}
+			name := resultTypeName(elem.Result)
+			if name == "" {
+				continue
+			}
+			ptr := name != elem.Result
+			for _, itf := range parser.SortedKeys(elem.BindKinds) {
+				if kind := elem.BindKinds[itf]; (kind == bindPtr) != ptr {
+					reason := fmt.Sprintf("构造函数 %s 返回 %s，与接口 %s 的 :%s 标记矛盾：%s；"+
+						"请去掉该标记或修改构造函数的返回值",
+						elem.Constructor, elem.Result, itf, kind, describeElement(elem))
+					return errors.NewInvalidAnnotationError(sc.annotation(), reason)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// implementsResult method    判断组件类型（T 或 *T 的方法）是否实现构造函数返回的接口
+// 只检查本包声明、不含嵌入接口的非泛型接口，其他接口（其他包的接口、error 等）无法仅凭语法树确定，视为实现.
+func (sc *AutoWireSearcher) implementsResult(wireElement *Element, result ast.Expr, r typeResolver) bool {
+	ident, ok := ast.Unparen(result).(*ast.Ident)
+	if !ok || types.Universe.Lookup(ident.Name) != nil {
+		return true
+	}
+	d, ok := sc.lookupDecl(r.file, ident.Name)
+	if !ok {
+		return true
+	}
+	ts, ok := d.node.(*ast.TypeSpec)
+	if !ok || ts.TypeParams != nil {
+		return true
+	}
+	it, ok := ts.Type.(*ast.InterfaceType)
+	if !ok {
+		return true
+	}
+	ir := typeResolver{file: d.file, pkgPath: r.pkgPath}
+	var methods []string
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return true
+		}
+		methods = append(methods, ir.methodSignature(m.Names[0].Name, ft))
+	}
+	if len(methods) == 0 {
+		return true
+	}
+	return implementsAll(methodSets(sc.fileInfo(r.file).syntax, r.pkgPath)[wireElement.Name], methods)
+}
+
+// dropSelfBindings function    构造函数直接返回接口时已提供该接口，不再将其绑定到自身.
+func dropSelfBindings(wireElement *Element, r typeResolver, provided string) {
+	wireElement.Implements = slices.DeleteFunc(wireElement.Implements, func(itf string) bool {
+		return r.qualifyName(itf) == provided
+	})
+}
+
+// implName method    返回查找接口实现声明（var _ I = &T{}）时使用的类型名
+// 类型声明为其名称；函数声明为返回值中本包类型的名称，如 func NewZoo() *Zoo 为 Zoo.
+func (sc *AutoWireSearcher) implName(decl *tmpDecl, f *ast.File) string {
+	if !decl.isFunc {
+		return decl.name
+	}
+	fd := decl.method
+	if fd == nil {
+		fd = sc.findFuncDecl(f, decl.name)
+	}
+	if fd == nil || fd.Type.Results == nil || len(fd.Type.Results.List) == 0 {
+		return ""
+	}
+	return resultTypeName(types.ExprString(fd.Type.Results.List[0].Type))
+}
+
+// resultTypeName function    返回构造函数返回值中本包命名类型的名称（去掉指针），如 *Zoo 返回 Zoo
+// 其他包的类型、预声明类型与复合类型返回空字符串.
+func resultTypeName(result string) string {
+	name := strings.TrimPrefix(result, "*")
+	if !token.IsIdentifier(name) || types.Universe.Lookup(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// resolveResults function    记录构造函数的返回值形式：返回类型、是否返回 cleanup 与 error.
+func resolveResults(wireElement *Element, results *ast.FieldList) {
+	if results == nil || len(results.List) == 0 {
+		return
+	}
+	wireElement.Result = types.ExprString(results.List[0].Type)
+	for _, field := range results.List[1:] {
+		switch t := field.Type.(type) {
+		case *ast.FuncType:
+			// cleanup 函数固定为 func()
+			if (t.Params == nil || len(t.Params.List) == 0) && (t.Results == nil || len(t.Results.List) == 0) {
+				wireElement.Cleanup = true
+			}
+		case *ast.Ident:
+			if t.Name == "error" {
+				wireElement.ReturnsErr = true
+			}
+		}
+	}
+}
+
+// ignoredByWire function    判断结构体字段是否带有 wire:"-" 标签（wire.Struct 不注入该字段）.
+func ignoredByWire(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	return err == nil && reflect.StructTag(tag).Get("wire") == "-"
+}
+
+// appendUnique function    追加不重复的元素.
+func appendUnique(list []string, item string) []string {
+	if slices.Contains(list, item) {
+		return list
+	}
+	return append(list, item)
+}
+
+// typeResolver struct    基于单个文件的导入信息解析类型表达式.
+type typeResolver struct {
+	file    *ast.File // 类型表达式所在的文件
+	pkgPath string    // 文件所在包的导入路径
+}
+
+// fieldListTypes method    解析参数或字段列表中的类型.
+func (r typeResolver) fieldListTypes(fl *ast.FieldList) []string {
+	if fl == nil {
+		return nil
+	}
+	var result []string
+	for _, field := range fl.List {
+		t := r.typeKey(field.Type)
+		// 一个字段声明可以包含多个名称，如 a, b int
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for range n {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// fieldTypes method    解析结构体中指定字段的类型.
+func (r typeResolver) fieldTypes(ts *ast.TypeSpec, fields []string) []string {
+	if ts == nil {
+		return nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return nil
+	}
+	var result []string
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if slices.Contains(fields, name.Name) {
+				result = appendUnique(result, r.typeKey(field.Type))
+			}
+		}
+	}
+	return result
+}
+
+// typeKey method    将类型表达式转换为 "包路径.类型名" 形式，指针类型去掉 *.
+func (r typeResolver) typeKey(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return r.typeKey(t.X)
+	case *ast.ParenExpr:
+		return r.typeKey(t.X)
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return t.Name // 预声明类型，如 string、error
+		}
+		return r.pkgPath + "." + t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return r.importPath(x.Name) + "." + t.Sel.Name
+		}
+	case *ast.ArrayType:
+		return "[]" + r.typeKey(t.Elt)
+	case *ast.Ellipsis:
+		// 可变参数在 wire 中按切片类型注入
+		return "[]" + r.typeKey(t.Elt)
+	case *ast.IndexExpr:
+		return r.typeKey(t.X) + "[" + r.typeKey(t.Index) + "]"
+	case *ast.IndexListExpr:
+		return r.typeKey(t.X) + "[" + strings.Join(parser.Map(t.Indices, r.typeKey), ",") + "]"
+	}
+	return types.ExprString(expr)
+}
+
+// typeParamKeys function    返回泛型类型声明的类型参数列表，如 Repo[T] 返回 [example.com/repo.T]，非泛型类型返回空字符串.
+func typeParamKeys(ts *ast.TypeSpec, pkgPath string) string {
+	if ts.TypeParams == nil {
+		return ""
+	}
+	var keys []string
+	for _, field := range ts.TypeParams.List {
+		for _, name := range field.Names {
+			keys = append(keys, pkgPath+"."+name.Name)
+		}
+	}
+	return "[" + strings.Join(keys, ",") + "]"
+}
+
+// typeBase function    返回去掉类型实参的类型，如 example.com/repo.Repo[example.com/model.User] 返回 example.com/repo.Repo.
+func typeBase(key string) string {
+	if i := strings.Index(key, "["); i > 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// valueType method    推断包级变量的类型：优先按语法推断（见 typeResolver.valueType），
+// 否则使用类型检查得到的类型，如 var DefaultName = Name("x")、var Client = NewClient()；无法确定时返回空字符串.
+func (sc *AutoWireSearcher) valueType(decl *tmpDecl, f *ast.File, r typeResolver) string {
+	if t := r.valueType(decl.valueSpec); t != "" {
+		return t
+	}
+	if d, ok := sc.lookupDecl(f, decl.name); ok {
+		if v, ok := d.obj.(*types.Var); ok {
+			return r.typeKeyOf(v.Type(), v.Pkg())
+		}
+	}
+	return ""
+}
+
+// typeKeyOf method    将类型检查得到的类型转换为依赖图中使用的形式（见 typeKey），无效的类型返回空字符串
+// local 为组件所在的包，单独检查的文件（未加载所在包时）的包路径不完整，使用 r.pkgPath.
+func (r typeResolver) typeKeyOf(t types.Type, local *types.Package) string {
+	switch t := t.(type) {
+	case *types.Pointer:
+		return r.typeKeyOf(t.Elem(), local)
+	case *types.Slice:
+		if elem := r.typeKeyOf(t.Elem(), local); elem != "" {
+			return "[]" + elem
+		}
+		return ""
+	case *types.Basic:
+		if t.Kind() == types.Invalid {
+			return ""
+		}
+		return t.Name()
+	case *types.Alias:
+		return r.typeKeyOf(types.Unalias(t), local)
+	case *types.Named:
+		obj := t.Obj()
+		var key string
+		switch obj.Pkg() {
+		case nil:
+			return obj.Name() // 预声明类型，如 error
+		case local:
+			key = r.pkgPath + "." + obj.Name()
+		default:
+			key = obj.Pkg().Path() + "." + obj.Name()
+		}
+		if args := t.TypeArgs(); args.Len() > 0 {
+			keys := make([]string, args.Len())
+			for i := range args.Len() {
+				keys[i] = r.typeKeyOf(args.At(i), local)
+			}
+			key += "[" + strings.Join(keys, ",") + "]"
+		}
+		return key
+	}
+	return ""
+}
+
+// valueType method    推断变量的类型：优先使用声明的类型，否则从 T{}、&T{} 形式的初始值推断.
+func (r typeResolver) valueType(vs *ast.ValueSpec) string {
+	if vs.Type != nil {
+		return r.typeKey(vs.Type)
+	}
+	if len(vs.Values) != 1 {
+		return ""
+	}
+	v := vs.Values[0]
+	if u, ok := v.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		v = u.X
+	}
+	if cl, ok := v.(*ast.CompositeLit); ok && cl.Type != nil {
+		return r.typeKey(cl.Type)
+	}
+	return ""
+}
+
+// qualifyName method    将注解中的接口名（如 Store、io.Writer）转换为完整形式.
+func (r typeResolver) qualifyName(name string) string {
+	if pkg, sel, ok := strings.Cut(name, "."); ok && !strings.Contains(sel, ".") {
+		return r.importPath(pkg) + "." + sel
+	}
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return r.pkgPath + "." + name
+}
+
+// versionSuffix 匹配导入路径末尾的主版本号，如 /v2.
+var versionSuffix = regexp.MustCompile(`/v\d+$`)
+
+// importPath method    根据包名查找导入路径，找不到时返回包名本身.
+func (r typeResolver) importPath(pkgName string) string {
+	for _, imp := range r.file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == pkgName {
+				return p
+			}
+			continue
+		}
+		// 未指定别名时按约定使用路径最后一段（忽略主版本号后缀）作为包名
+		base := path.Base(versionSuffix.ReplaceAllString(p, ""))
+		base = strings.TrimPrefix(strings.TrimSuffix(base, ".go"), "go-")
+		if base == pkgName || strings.ReplaceAll(base, "-", "_") == pkgName {
+			return p
+		}
+	}
+	return pkgName
+}
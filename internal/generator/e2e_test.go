@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runWireModule 在临时模块 example.com/app 中写入 files（路径 -> 内容），
+// 使用 gutowire -w ./wire 生成代码并运行 wire，最后编译整个模块，返回模块目录.
+func runWireModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("short 模式跳过 wire 端到端测试")
+	}
+	if _, err := exec.LookPath("wire"); err != nil {
+		t.Skip("没有 wire 命令")
+	}
+	cli := filepath.Join(t.TempDir(), "gutowire")
+	build := exec.Command("go", "build", "-o", cli, ".")
+	build.Dir = filepath.Join("..", "..")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("编译 gutowire 失败: %v\n%s", err, out)
+	}
+
+	dir := t.TempDir()
+	sum, err := os.ReadFile(filepath.Join("..", "..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["go.mod"] = "module example.com/app\n\ngo 1.25\n\nrequire github.com/google/wire v0.7.0\n"
+	files["go.sum"] = string(sum)
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(name string, args ...string) {
+		t.Helper()
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s %v 失败: %v\n%s", name, args, err, out)
+		}
+	}
+	run(cli, "-w", "./wire")
+	run("go", "build", "./...")
+	return dir
+}
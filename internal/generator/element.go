@@ -7,10 +7,15 @@ import (
 
 // tmpDecl struct    临时声明信息，用于解析 AST 时存储类型或函数的信息.
 type tmpDecl struct {
-	docs     string        // 文档注释（包含 @autowire 注解）
-	name     string        // 名称
-	isFunc   bool          // 是否为函数
-	typeSpec *ast.TypeSpec // 类型规范（如果是类型声明）
+	docs      []docLine                // 文档注释的各行（包含 @autowire 注解）
+	name      string                   // 名称
+	isFunc    bool                     // 是否为函数
+	typeSpec  *ast.TypeSpec            // 类型规范（如果是类型声明）
+	valueSpec *ast.ValueSpec           // 变量规范（如果是变量声明）
+	method    *ast.FuncDecl            // 方法声明（如果是方法工厂）
+	pkgDoc    bool                     // 是否为 package 子句的文档注释（只识别组合 Set）
+	fieldDocs map[*ast.Field][]docLine // 带注解的结构体字段的文档注释与行尾注释（@autowire.env）
+	pos       token.Position           // 声明所在位置
 }
 
 // getImplement function    分析文件中的接口实现声明
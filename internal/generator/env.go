@@ -0,0 +1,286 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+// envIgnoredOptions 对类型声明上的 @autowire.env 无效的参数：提供者由生成的函数决定.
+var envIgnoredOptions = []string{"new", "of", "fields", "exclude", "lifecycle", "qualifier", "scope", "group"}
+
+// envImports 读取环境变量的提供者使用的标准库.
+var envImports = []string{`"fmt"`, `"os"`, `"strconv"`, `"time"`}
+
+// envHelper 读取并解析环境变量的函数，生成到每个使用 @autowire.env 的包中一次.
+const envHelper = `// autowireLookupEnv 读取环境变量 name 并使用 parse 解析，未设置时使用默认值 def，没有默认值时返回错误.
+func autowireLookupEnv[T any](name, def string, hasDef bool, parse func(string) (T, error)) (T, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		if !hasDef {
+			var zero T
+			return zero, fmt.Errorf("环境变量 %s 未设置", name)
+		}
+		v = def
+	}
+	x, err := parse(v)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("解析环境变量 %s 失败: %w", name, err)
+	}
+	return x, nil
+}`
+
+// envVar struct    一个环境变量的读取方式.
+type envVar struct {
+	name   string // 环境变量名称，如 PORT
+	def    string // 默认值
+	hasDef bool   // 是否指定了默认值，未指定时环境变量必须设置
+	kind   string // 解析方式，annotations.EnvTypes 之一
+	typ    string // 解析结果的类型（源码形式），如 Port、int
+}
+
+// envKind function    返回按类型 typ 读取环境变量的解析方式：type= 参数优先，否则类型本身需要为支持的类型
+// 无法确定时返回空字符串.
+func envKind(typ ast.Expr, options map[string]string) string {
+	if kind := options["type"]; kind != "" {
+		return kind
+	}
+	if kind := types.ExprString(typ); slices.Contains(annotations.EnvTypes, kind) {
+		return kind
+	}
+	return ""
+}
+
+// envKindError function    返回无法确定解析方式时的问题描述.
+func envKindError(typ ast.Expr) string {
+	return fmt.Sprintf("无法按 %s 解析环境变量，需要通过 type= 指定（可选 %s）", types.ExprString(typ),
+		strings.Join(annotations.EnvTypes, "、"))
+}
+
+// newEnvVar function    按注解参数创建环境变量的读取方式，解析结果转换为类型 typ.
+func newEnvVar(typ, kind string, options map[string]string) envVar {
+	def, hasDef := options["default"]
+	return envVar{
+		name:   annotations.Unquote(options["name"]),
+		def:    annotations.Unquote(def),
+		hasDef: hasDef,
+		kind:   kind,
+		typ:    typ,
+	}
+}
+
+// envFieldType function    判断字段类型能否读取环境变量：只支持具名类型与基础类型，指针、切片等复合类型返回 false.
+func envFieldType(typ ast.Expr) bool {
+	switch typ.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return true
+	}
+	return false
+}
+
+// call method    返回读取并解析环境变量的调用表达式.
+func (v envVar) call() string {
+	var parse string
+	switch {
+	case v.kind == "string":
+		parse = fmt.Sprintf("return %s(s), nil", v.typ)
+	case v.kind == "bool":
+		parse = fmt.Sprintf("b, err := strconv.ParseBool(s)\n\t\treturn %s(b), err", v.typ)
+	case v.kind == "time.Duration":
+		parse = fmt.Sprintf("d, err := time.ParseDuration(s)\n\t\treturn %s(d), err", v.typ)
+	case strings.HasPrefix(v.kind, "float"):
+		parse = fmt.Sprintf("f, err := strconv.ParseFloat(s, %s)\n\t\treturn %s(f), err",
+			strings.TrimPrefix(v.kind, "float"), v.typ)
+	case strings.HasPrefix(v.kind, "uint"):
+		parse = fmt.Sprintf("n, err := strconv.ParseUint(s, 10, %s)\n\t\treturn %s(n), err",
+			intBits(strings.TrimPrefix(v.kind, "uint")), v.typ)
+	default:
+		parse = fmt.Sprintf("n, err := strconv.ParseInt(s, 10, %s)\n\t\treturn %s(n), err",
+			intBits(strings.TrimPrefix(v.kind, "int")), v.typ)
+	}
+	return fmt.Sprintf("autowireLookupEnv(%s, %s, %t, func(s string) (%s, error) {\n\t\t%s\n\t})",
+		strconv.Quote(v.name), strconv.Quote(v.def), v.hasDef, v.typ, parse)
+}
+
+// intBits function    返回整数类型的位数，int、uint 为 0（与平台相关）.
+func intBits(bits string) string {
+	if bits == "" {
+		return "0"
+	}
+	return bits
+}
+
+// describe method    返回环境变量的说明，如 PORT（默认 8080）.
+func (v envVar) describe() string {
+	if v.hasDef {
+		return fmt.Sprintf("%s（默认 %s）", v.name, strconv.Quote(v.def))
+	}
+	return v.name
+}
+
+// applyEnv method    处理写在非结构体类型声明上的环境变量注解：生成 Provide<Type> 读取环境变量，
+// 作为组件的构造函数，组件没有依赖，绑定接口时按值绑定；调用方需要确认能够确定解析方式.
+func (sc *AutoWireSearcher) applyEnv(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string,
+	options map[string]string, implementMap map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(options)) {
+		if slices.Contains(envIgnoredOptions, key) {
+			sc.logger.Warn(key+" 参数对 @autowire.env 无效，已忽略", "element", describeElement(*wireElement))
+		}
+	}
+	wireElement.Injector, wireElement.StructFields = "", nil
+	wireElement.Qualifier, wireElement.Scope, wireElement.Group = "", "", ""
+	wireElement.BindValue = true
+
+	sc.addInterfaceImplementations(wireElement, implementMap, decl.name)
+	r := typeResolver{file: f, pkgPath: pkgPath}
+	wireElement.Provides = []string{pkgPath + "." + decl.name}
+	if decl.typeSpec.Assign.IsValid() {
+		wireElement.Provides = appendUnique(wireElement.Provides, r.typeKey(decl.typeSpec.Type))
+	}
+	for _, itf := range wireElement.Implements {
+		wireElement.Provides = appendUnique(wireElement.Provides, r.qualifyName(itf))
+	}
+
+	v := newEnvVar(decl.name, envKind(decl.typeSpec.Type, options), options)
+	provider := "Provide" + decl.name
+	wireElement.Constructor = provider
+	wireElement.Result = decl.name
+	wireElement.ReturnsErr, wireElement.Cleanup = true, false
+	wireElement.Env = []string{v.name}
+	wireElement.EnvProviders = append(wireElement.EnvProviders, envHelper, fmt.Sprintf(
+		"// %s 由 @autowire.env 生成，读取环境变量 %s.\nfunc %s() (%s, error) {\n\treturn %s\n}",
+		provider, v.describe(), provider, decl.name, v.call()))
+	wireElement.Imports = envImports
+}
+
+// envTypeDecl function    判断声明能否使用 @autowire.env：非结构体、非接口的类型声明.
+func envTypeDecl(decl *tmpDecl) bool {
+	if decl.typeSpec == nil {
+		return false
+	}
+	switch decl.typeSpec.Type.(type) {
+	case *ast.StructType, *ast.InterfaceType:
+		return false
+	}
+	return true
+}
+
+// envField struct    结构体中读取环境变量的字段.
+type envField struct {
+	name string // 字段名
+	v    envVar // 读取方式
+}
+
+// structEnvFields method    返回结构体中带有环境变量注解的字段，按声明顺序排列
+// 无效的注解与字段由 checkAnnotations 报告，这里忽略.
+func (sc *AutoWireSearcher) structEnvFields(decl *tmpDecl) []envField {
+	st := structOf(decl)
+	if st == nil || len(decl.fieldDocs) == 0 {
+		return nil
+	}
+	var fields []envField
+	for _, field := range st.Fields.List {
+		if len(field.Names) != 1 || !envFieldType(field.Type) {
+			continue
+		}
+		for _, line := range decl.fieldDocs[field] {
+			a, err := annotations.ParseLoose(sc.annotation(), strings.TrimSpace(line.text))
+			if err != nil || a.Suffix != annotations.SuffixEnv || a.Map()["name"] == "" {
+				continue
+			}
+			if kind := envKind(field.Type, a.Map()); kind != "" {
+				v := newEnvVar(types.ExprString(field.Type), kind, a.Map())
+				fields = append(fields, envField{name: field.Names[0].Name, v: v})
+			}
+		}
+	}
+	return fields
+}
+
+// applyEnvFields method    处理结构体字段上的环境变量注解：生成 Provide<Type> 替代 wire.Struct，
+// 带注解的字段读取环境变量，其余注入的字段作为参数，组件不再依赖读取环境变量的字段.
+func (sc *AutoWireSearcher) applyEnvFields(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string) {
+	envFields := sc.structEnvFields(decl)
+	if len(envFields) == 0 {
+		return
+	}
+	st := structOf(decl)
+	if wireElement.Constructor != "" || wireElement.ConfigWire || wireElement.TypeParams > 0 {
+		sc.logger.Warn("字段上的 @autowire.env 只对使用 wire.Struct 注入的非泛型结构体有效，已忽略",
+			"element", describeElement(*wireElement))
+		return
+	}
+
+	isEnv := func(name string) bool {
+		return slices.ContainsFunc(envFields, func(e envField) bool { return e.name == name })
+	}
+	injected := selectFields(st, wireElement.StructFields)
+	var params, values []string
+	remaining := &ast.FieldList{}
+	for _, field := range injected.List {
+		names := slices.DeleteFunc(fieldNames(field), isEnv)
+		if len(names) == 0 {
+			continue
+		}
+		remaining.List = append(remaining.List, field)
+		for _, name := range names {
+			p := fmt.Sprintf("p%d", len(params))
+			params = append(params, p+" "+types.ExprString(field.Type))
+			values = append(values, name+": "+p)
+		}
+	}
+
+	var body strings.Builder
+	var names []string
+	for i, e := range envFields {
+		v := fmt.Sprintf("e%d", i)
+		fmt.Fprintf(&body, "\t%s, err := %s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", v, e.v.call())
+		values = append(values, e.name+": "+v)
+		names = append(names, e.name+" <- "+e.v.describe())
+		wireElement.Env = append(wireElement.Env, e.v.name)
+	}
+
+	r := typeResolver{file: f, pkgPath: pkgPath}
+	wireElement.Deps = r.fieldListTypes(remaining)
+	wireElement.Ambiguous = r.ambiguousFields(remaining)
+	wireElement.StructFields = nil
+
+	provider := "Provide" + decl.name
+	wireElement.Constructor = provider
+	wireElement.Result = "*" + decl.name
+	wireElement.ReturnsErr, wireElement.Cleanup = true, false
+	wireElement.EnvProviders = append(wireElement.EnvProviders, envHelper, fmt.Sprintf(
+		"// %s 由 @autowire.env 生成，从环境变量读取字段 %s，其余字段由参数注入.\n"+
+			"func %s(%s) (*%s, error) {\n%s\treturn &%s{%s}, nil\n}",
+		provider, strings.Join(names, "、"), provider, strings.Join(params, ", "), decl.name, body.String(),
+		decl.name, strings.Join(values, ", ")))
+	wireElement.Imports = fileImports(f)
+	for _, imp := range envImports {
+		wireElement.Imports = appendUnique(wireElement.Imports, imp)
+	}
+}
+
+// fieldNames function    返回字段声明中的字段名，嵌入字段使用类型名.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		if name := embedName(field.Type); name != "" {
+			return []string{name}
+		}
+		return nil
+	}
+	return parser.Map(field.Names, func(n *ast.Ident) string { return n.Name })
+}
+
+// writeEnvProviders method    在组件所在的包目录中生成 autowire_env.go
+// 扫描过的目录中不再需要读取环境变量的提供者时删除旧文件.
+func (sc *AutoWireSearcher) writeEnvProviders() error {
+	return sc.writeElementDecls(sc.genFileName(envFile), func(elem Element) []string { return elem.EnvProviders })
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const envSrc = `package svc
+
+import "time"
+
+// @autowire.env(set=svc,name=PORT,default=8080)
+type Port int
+
+// @autowire.env(set=svc,name=TIMEOUT,type=time.Duration)
+type Timeout time.Duration
+
+// @autowire.env(set=svc,name=ADDR)
+type Addr struct{}
+
+// @autowire(set=svc)
+type Server struct {
+	Port Port
+	// @autowire.env(name=DEBUG,default=false)
+	Debug bool
+	// @autowire.env(name=LIMITS)
+	Limits []int
+	skip  string ` + "`wire:\"-\"`" + `
+}
+
+type Options struct {
+	// @autowire.env(name=LEVEL)
+	Level string
+}
+`
+
+func TestEnvElement(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", envSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	elements := sc.parseAnnotations(decls, "svc.go", "example.com/svc", f, getImplement(f))
+
+	// 写在结构体上的环境变量注解被忽略并报告
+	var names []string
+	for _, elem := range elements {
+		names = append(names, elem.Name)
+	}
+	if got := strings.Join(names, ","); got != "Port,Timeout,Server" {
+		t.Fatalf("parseAnnotations() = %s", got)
+	}
+	var reasons []string
+	for _, d := range sc.checkAnnotations(decls, f) {
+		reasons = append(reasons, d.Reason)
+	}
+	got := strings.Join(reasons, "\n")
+	for _, want := range []string{"非结构体类型声明", "[]int", "Options"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("checkAnnotations() 缺少 %s:\n%s", want, got)
+		}
+	}
+
+	port := elements[0]
+	if port.Constructor != "ProvidePort" || !port.ReturnsErr || !port.BindValue ||
+		!slices.Equal(port.Env, []string{"PORT"}) || len(port.EnvProviders) != 2 {
+		t.Errorf("Port = %+v", port)
+	}
+	if src := port.EnvProviders[1]; !strings.Contains(src, `autowireLookupEnv("PORT", "8080", true`) ||
+		!strings.Contains(src, "strconv.ParseInt(s, 10, 0)") {
+		t.Errorf("ProvidePort = %s", src)
+	}
+	if src := elements[1].EnvProviders[1]; !strings.Contains(src, `autowireLookupEnv("TIMEOUT", "", false`) ||
+		!strings.Contains(src, "time.ParseDuration(s)") {
+		t.Errorf("ProvideTimeout = %s", src)
+	}
+
+	server := elements[2]
+	// 注解无效的字段按原样注入
+	if server.Constructor != "ProvideServer" || server.Result != "*Server" || server.StructFields != nil ||
+		!slices.Equal(server.Deps, []string{"example.com/svc.Port", "[]int"}) ||
+		!slices.Equal(server.Env, []string{"DEBUG"}) || len(server.Imports) != len(envImports) {
+		t.Errorf("Server = %+v", server)
+	}
+	src := server.EnvProviders[1]
+	if !strings.Contains(src, "func ProvideServer(p0 Port, p1 []int) (*Server, error)") ||
+		!strings.Contains(src, "return &Server{Port: p0, Limits: p1, Debug: e0}, nil") {
+		t.Errorf("ProvideServer = %s", src)
+	}
+}
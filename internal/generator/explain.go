@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// ctxParamNote 由注入入口的 ctx 参数提供 context.Context 时的说明.
+const ctxParamNote = "由初始化函数的 ctx 参数提供"
+
+// argParamNote 由注入入口 args= 参数声明的初始化函数参数提供类型时的说明.
+const argParamNote = "由初始化函数的 args 参数提供"
+
+// Explanation struct    gutowire explain 输出的类型及其提供者链.
+type Explanation struct {
+	Type      string              `json:"type"`                // 类型（包路径.类型名）
+	Providers []ExplainedProvider `json:"providers,omitempty"` // 提供该类型的组件，多个时由 wire.Build 包含的 Set 决定
+	Param     string              `json:"param,omitempty"`     // 由初始化函数参数提供时的说明
+	Missing   bool                `json:"missing,omitempty"`   // 没有任何提供者
+}
+
+// ExplainedProvider struct    提供类型的组件及其依赖的提供者链.
+type ExplainedProvider struct {
+	Name        string        `json:"name"`                  // 带包名的组件名称，如 svc.DB
+	Set         string        `json:"set"`                   // 所属 Set 名称
+	How         string        `json:"how"`                   // 提供方式，如 wire.Bind 绑定接口
+	Constructor string        `json:"constructor,omitempty"` // 构造函数名称，为空表示使用 wire.Struct 或 wire.Value
+	Position    string        `json:"position"`              // 声明在源文件中的位置
+	Deps        []Explanation `json:"deps,omitempty"`        // 构造函数参数或 wire.Struct 注入字段的类型，按声明顺序
+	Repeat      bool          `json:"repeat,omitempty"`      // 依赖已在上文展开，不再重复展开
+	Cycle       bool          `json:"cycle,omitempty"`       // 组件已在当前链上，存在循环依赖
+}
+
+// Explain method    返回类型的提供者链：提供该类型的组件、其构造函数，以及每个依赖由哪个组件提供（递归展开）
+// name 支持 包路径.类型名、包名.类型名 以及单独的类型名，可以带 * 前缀；匹配多个类型时返回错误.
+// 需要在 Validate 之后调用，此时重复绑定已按配置的策略处理.
+func (sc *AutoWireSearcher) Explain(name string) (Explanation, error) {
+	providers := make(map[string][]Element)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			// 接口注解与组合 Set 不直接提供类型，测试替身只用于测试注入包
+			if elem.Interface || elem.Composite || elem.Mock {
+				continue
+			}
+			for _, t := range elem.Provides {
+				providers[t] = append(providers[t], elem)
+			}
+		}
+	}
+
+	t, err := matchType(providers, strings.TrimPrefix(strings.TrimSpace(name), "*"))
+	if err != nil {
+		return Explanation{}, err
+	}
+	x := explainer{
+		providers: providers,
+		argTypes:  parser.NewSet[string](),
+		expanded:  parser.NewSet[string](),
+	}
+	for _, root := range sc.injectorRoots() {
+		x.ctxParam = x.ctxParam || root.Context
+		for _, t := range root.ArgTypes {
+			x.argTypes.Add(t)
+		}
+	}
+	return x.explain(t, parser.NewSet[string]()), nil
+}
+
+// matchType function    返回与名称匹配的唯一类型.
+func matchType(providers map[string][]Element, name string) (string, error) {
+	var matches []string
+	for _, t := range parser.SortedKeys(providers) {
+		pkgPath, typeName := splitTypeName(t)
+		local := ""
+		if elem := providers[t][0]; elem.PkgPath == pkgPath {
+			local = parser.AppendPkg(elem.Pkg, typeName)
+		}
+		if name == t || name == local || name == path.Base(pkgPath)+"."+typeName || name == typeName {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("未找到提供类型 %s 的组件", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("类型名称 %s 匹配多个类型，请使用完整路径: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// splitTypeName function    拆分 包路径.类型名 形式的类型，泛型实例化的类型实参不参与拆分.
+func splitTypeName(t string) (pkgPath, typeName string) {
+	base := t
+	if i := strings.Index(t, "["); i >= 0 {
+		base = t[:i]
+	}
+	i := strings.LastIndex(base, ".")
+	if i < 0 {
+		return "", t
+	}
+	return t[:i], t[i+1:]
+}
+
+// explainer struct    递归展开类型的提供者链.
+type explainer struct {
+	providers map[string][]Element
+	ctxParam  bool               // 存在带 ctx 参数的注入入口
+	argTypes  parser.Set[string] // 注入入口 args= 参数提供的类型
+	expanded  parser.Set[string] // 已展开依赖的组件，再次出现时不再展开
+}
+
+// explain method    返回类型 t 的提供者链，chain 为当前链上的组件，用于发现循环依赖.
+func (x *explainer) explain(t string, chain parser.Set[string]) Explanation {
+	e := Explanation{Type: t}
+	if len(x.providers[t]) == 0 {
+		switch {
+		case x.ctxParam && t == contextType:
+			e.Param = ctxParamNote
+		case x.argTypes.Contains(t):
+			e.Param = argParamNote
+		default:
+			e.Missing = true
+		}
+		return e
+	}
+
+	for _, elem := range x.providers[t] {
+		p := ExplainedProvider{
+			Name:        parser.AppendPkg(elem.Pkg, elem.Name),
+			Set:         elem.Set,
+			How:         provideMethod(elem, t),
+			Constructor: elem.Constructor,
+			Position:    elem.Position.String(),
+		}
+		key := elem.PkgPath + "/" + instanceName(elem)
+		switch {
+		case chain.Contains(key):
+			p.Cycle = true
+		case x.expanded.Contains(key):
+			p.Repeat = len(elem.Deps) > 0
+		default:
+			x.expanded.Add(key)
+			chain.Add(key)
+			for _, dep := range elem.Deps {
+				p.Deps = append(p.Deps, x.explain(dep, chain))
+			}
+			delete(chain, key)
+		}
+		e.Providers = append(e.Providers, p)
+	}
+	return e
+}
+
+// WriteExplanation function    以树形式输出类型的提供者链.
+func WriteExplanation(w io.Writer, e Explanation) error {
+	var b strings.Builder
+	writeExplanation(&b, e, "", "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeExplanation function    输出类型节点，prefix 为当前行的缩进与连线，indent 为子节点的缩进.
+func writeExplanation(b *strings.Builder, e Explanation, prefix, indent string) {
+	b.WriteString(prefix + e.Type)
+	if len(e.Providers) > 1 {
+		fmt.Fprintf(b, "（%d 个提供者，由 wire.Build 包含的 Set 决定）", len(e.Providers))
+	}
+	b.WriteString("\n")
+
+	switch {
+	case e.Missing:
+		b.WriteString(indent + "└── ✗ 没有提供者\n")
+	case e.Param != "":
+		b.WriteString(indent + "└── " + e.Param + "\n")
+	}
+	for i, p := range e.Providers {
+		branch, next := "├── ", "│   "
+		if i == len(e.Providers)-1 {
+			branch, next = "└── ", "    "
+		}
+		line := fmt.Sprintf("%s：%s，Set %s", p.Name, p.How, p.Set)
+		if p.Constructor != "" && !strings.Contains(p.How, p.Constructor) {
+			line += "，构造函数 " + p.Constructor
+		}
+		if p.Position != "" && p.Position != "-" {
+			line += " (" + p.Position + ")"
+		}
+		switch {
+		case p.Cycle:
+			line += " [循环依赖]"
+		case p.Repeat:
+			line += " [依赖已在上文展开]"
+		}
+		b.WriteString(indent + branch + line + "\n")
+		for j, dep := range p.Deps {
+			depBranch, depNext := "├── ", "│   "
+			if j == len(p.Deps)-1 {
+				depBranch, depNext = "└── ", "    "
+			}
+			writeExplanation(b, dep, indent+next+depBranch, indent+next+depNext)
+		}
+	}
+}
+
+// WriteExplanationJSON function    以 JSON 形式输出类型的提供者链.
+func WriteExplanationJSON(w io.Writer, e Explanation) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{
+			"storage": {
+				"example.com/app/svc/DB": {Name: "DB", Pkg: "svc", PkgPath: "example.com/app/svc", Set: "storage",
+					Constructor: "NewDB", Implements: []string{"Store"},
+					Provides: []string{"example.com/app/svc.DB", "example.com/app/svc.Store"},
+					Deps:     []string{"example.com/app/conf.Config", "context.Context"}},
+			},
+			"config": {
+				"example.com/app/conf/Config": {Name: "Config", Pkg: "conf", PkgPath: "example.com/app/conf",
+					Set: "config", Provides: []string{"example.com/app/conf.Config"}},
+			},
+			"app": {
+				"example.com/app/svc/App": {Name: "App", Pkg: "svc", PkgPath: "example.com/app/svc", Set: "app",
+					InitWire: true, Injector: "App", Context: true, Provides: []string{"example.com/app/svc.App"},
+					Deps: []string{"example.com/app/svc.Store", "example.com/app/svc.DB", "example.com/x.Missing"}},
+			},
+		},
+	}
+
+	for _, name := range []string{"svc.Store", "*svc.Store", "Store", "example.com/app/svc.Store"} {
+		e, err := sc.Explain(name)
+		if err != nil || e.Type != "example.com/app/svc.Store" {
+			t.Errorf("Explain(%q) = %q, %v", name, e.Type, err)
+		}
+	}
+	if _, err := sc.Explain("svc.Unknown"); err == nil {
+		t.Error("Explain() 未知类型应返回错误")
+	}
+
+	e, err := sc.Explain("svc.App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := WriteExplanation(&b, e); err != nil {
+		t.Fatal(err)
+	}
+	want := `example.com/app/svc.App
+└── svc.App：wire.Struct 注入，Set app
+    ├── example.com/app/svc.Store
+    │   └── svc.DB：wire.Bind 绑定接口，Set storage，构造函数 NewDB
+    │       ├── example.com/app/conf.Config
+    │       │   └── conf.Config：wire.Struct 注入，Set config
+    │       └── context.Context
+    │           └── 由初始化函数的 ctx 参数提供
+    ├── example.com/app/svc.DB
+    │   └── svc.DB：构造函数 NewDB 的返回值，Set storage [依赖已在上文展开]
+    └── example.com/x.Missing
+        └── ✗ 没有提供者
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteExplanation() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExplain_Ambiguous(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{"svc": {
+			"example.com/a/Repo": {Name: "Repo", Pkg: "a", PkgPath: "example.com/a",
+				Provides: []string{"example.com/a.Repo"}},
+			"example.com/b/Repo": {Name: "Repo", Pkg: "b", PkgPath: "example.com/b",
+				Provides: []string{"example.com/b.Repo"}},
+		}},
+	}
+	if _, err := sc.Explain("Repo"); err == nil || !strings.Contains(err.Error(), "example.com/a.Repo") {
+		t.Errorf("Explain() error = %v, want ambiguous", err)
+	}
+	if e, err := sc.Explain("b.Repo"); err != nil || e.Type != "example.com/b.Repo" {
+		t.Errorf("Explain(b.Repo) = %q, %v", e.Type, err)
+	}
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+// applyMethodFactory method    为带注解的方法生成包装函数 Provide<Receiver><Method>
+// 包装函数的第一个参数为接收者，其余参数与返回值与方法一致，因此组件依赖接收者类型.
+func (sc *AutoWireSearcher) applyMethodFactory(wireElement *Element, decl *tmpDecl, f *ast.File) {
+	fd := decl.method
+	if fd == nil {
+		return
+	}
+	recv := fd.Recv.List[0]
+	params, args := funcParams(fd)
+
+	var outs []string
+	for _, field := range fd.Type.Results.List {
+		for range max(len(field.Names), 1) {
+			outs = append(outs, types.ExprString(field.Type))
+		}
+	}
+	outList := outs[0]
+	if len(outs) > 1 {
+		outList = "(" + strings.Join(outs, ", ") + ")"
+	}
+
+	wireElement.Wrappers = append(wireElement.Wrappers, fmt.Sprintf(
+		"// %s 由 @autowire 生成，调用 (%s).%s.\nfunc %s(%s) %s {\n\treturn r.%s(%s)\n}",
+		wireElement.Constructor, types.ExprString(recv.Type), fd.Name.Name, wireElement.Constructor,
+		strings.Join(append([]string{"r " + types.ExprString(recv.Type)}, params...), ", "), outList,
+		fd.Name.Name, strings.Join(args, ", ")))
+	wireElement.Imports = fileImports(f)
+}
+
+// writeFactories method    在组件所在的包目录中生成 autowire_factory.go
+// 扫描过的目录中不再需要方法工厂包装函数时删除旧文件.
+func (sc *AutoWireSearcher) writeFactories() error {
+	return sc.writeElementDecls(sc.genFileName(factoryFile), func(elem Element) []string { return elem.Wrappers })
+}
+
+// 组件的作用域（scope= 参数）.
+const (
+	// scopeSingleton 每个注入器只构造一次（默认，与 wire 的语义一致）.
+	scopeSingleton = annotations.ScopeSingleton
+	// scopeFactory 提供工厂函数类型 <Type>Factory，每次调用构造新的实例.
+	scopeFactory = annotations.ScopeFactory
+)
+
+// applyScope method    为 scope=factory 的组件生成工厂函数类型 <Type>Factory 与包装构造函数 Provide<Type>Factory
+// 包装构造函数的参数与原构造函数一致，返回的工厂函数每次调用原构造函数，cleanup 与 error 由工厂函数原样返回
+// 组件改为只提供工厂函数类型，不再绑定接口.
+func (sc *AutoWireSearcher) applyScope(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string) {
+	if wireElement.Scope != scopeFactory {
+		return
+	}
+	var fd *ast.FuncDecl
+	if wireElement.Constructor != "" && decl.method == nil {
+		fd = sc.findFuncDecl(f, wireElement.Constructor)
+	}
+	if fd == nil || fd.Type.TypeParams != nil || fd.Type.Results == nil || wireElement.ConfigWire ||
+		wireElement.ValueWire || wireElement.Mock || wireElement.Qualifier != "" {
+		sc.logger.Warn("scope=factory 只对非泛型、没有限定名的构造函数有效，已忽略", "element", describeElement(*wireElement))
+		wireElement.Scope = ""
+		return
+	}
+	base := embedName(fd.Type.Results.List[0].Type)
+	if base == "" {
+		sc.logger.Warn("无法为构造函数的返回类型生成工厂函数类型，已忽略 scope", "element", describeElement(*wireElement))
+		wireElement.Scope = ""
+		return
+	}
+	if len(wireElement.Implements) > 0 {
+		sc.logger.Warn("scope=factory 的组件只提供工厂函数类型，绑定的接口被忽略",
+			"element", describeElement(*wireElement), "interfaces", strings.Join(wireElement.Implements, "、"))
+	}
+
+	name := base + "Factory"
+	provider := "Provide" + name
+	params, args := funcParams(fd)
+	outs := parser.Map(fd.Type.Results.List, func(field *ast.Field) string { return types.ExprString(field.Type) })
+	fnType := "func() " + outs[0]
+	if len(outs) > 1 {
+		fnType = "func() (" + strings.Join(outs, ", ") + ")"
+	}
+
+	wireElement.Wrappers = append(wireElement.Wrappers,
+		fmt.Sprintf("// %s 由 @autowire(scope=factory) 生成，每次调用构造新的 %s.\ntype %s %s",
+			name, outs[0], name, fnType),
+		fmt.Sprintf("// %s 返回调用 %s 的 %s.\nfunc %s(%s) %s {\n\treturn func() %s {\n\t\treturn %s(%s)\n\t}\n}",
+			provider, fd.Name.Name, name, provider, strings.Join(params, ", "), name,
+			strings.TrimPrefix(fnType, "func() "), fd.Name.Name, strings.Join(args, ", ")))
+	// 构造函数可以声明在包中的其他文件中，包装函数使用构造函数所在文件的导入
+	wireElement.Imports = fileImports(sc.declFile(f, fd.Name.Name))
+	wireElement.Implements = nil
+	wireElement.Provides = []string{pkgPath + "." + name}
+	wireElement.Constructor = provider
+	wireElement.Result = name
+	wireElement.Cleanup, wireElement.ReturnsErr = false, false
+}
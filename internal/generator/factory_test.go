@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const factorySrc = `package svc
+
+import "context"
+
+type Client struct{}
+
+type Factory struct{}
+
+// @autowire(set=factory)
+func (f *Factory) BuildClient(ctx context.Context, name string) (*Client, error) { return nil, nil }
+
+// @autowire(set=factory)
+func (f *Factory) Close() {}
+`
+
+func TestMethodFactory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "svc.go")
+	if err := os.WriteFile(file, []byte(factorySrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, factorySrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+	}
+	sc.scannedDirs = map[string]struct{}{dir: {}}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/svc", f, getImplement(f))
+
+	// 没有返回值的方法不能作为工厂
+	if len(elements) != 1 {
+		t.Fatalf("parseAnnotations() = %d elements, want 1", len(elements))
+	}
+	elem := elements[0]
+	if elem.Name != "FactoryBuildClient" || elem.Constructor != "ProvideFactoryBuildClient" || !elem.ReturnsErr {
+		t.Errorf("element = %+v", elem)
+	}
+	if want := []string{"example.com/svc.Factory", "context.Context", "string"}; !slices.Equal(elem.Deps, want) {
+		t.Errorf("Deps = %v, want %v", elem.Deps, want)
+	}
+	if !slices.Contains(elem.Provides, "example.com/svc.Client") {
+		t.Errorf("Provides = %v", elem.Provides)
+	}
+
+	if err := sc.writeFactories(); err != nil {
+		t.Fatalf("writeFactories() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sc.genFileName(factoryFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func ProvideFactoryBuildClient(r *Factory, p0 context.Context, p1 string) (*Client, error) {\n" +
+		"\treturn r.BuildClient(p0, p1)\n}"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("%s missing %q:\n%s", sc.genFileName(factoryFile), want, data)
+	}
+}
+
+const scopeSrc = `package svc
+
+import "context"
+
+type Conn struct{}
+
+// @autowire(set=conn,scope=factory)
+func NewConn(ctx context.Context, dsn string) (*Conn, func(), error) { return nil, nil, nil }
+
+// @autowire(set=conn,scope=factory)
+type Pool struct{}
+`
+
+func TestScopeFactory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "svc.go")
+	if err := os.WriteFile(file, []byte(scopeSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, scopeSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+	}
+	sc.scannedDirs = map[string]struct{}{dir: {}}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/svc", f, getImplement(f))
+	if len(elements) != 2 {
+		t.Fatalf("parseAnnotations() = %d elements, want 2", len(elements))
+	}
+
+	// 构造函数返回的 cleanup 与 error 由工厂函数返回，提供者本身不再返回
+	conn := elements[0]
+	if conn.Constructor != "ProvideConnFactory" || conn.Result != "ConnFactory" || conn.Cleanup || conn.ReturnsErr {
+		t.Errorf("element = %+v", conn)
+	}
+	if !slices.Equal(conn.Provides, []string{"example.com/svc.ConnFactory"}) {
+		t.Errorf("Provides = %v", conn.Provides)
+	}
+	if want := []string{"context.Context", "string"}; !slices.Equal(conn.Deps, want) {
+		t.Errorf("Deps = %v, want %v", conn.Deps, want)
+	}
+	// 没有构造函数的结构体忽略 scope
+	if pool := elements[1]; pool.Scope != "" || len(pool.Wrappers) != 0 {
+		t.Errorf("element = %+v", pool)
+	}
+
+	if err := sc.writeFactories(); err != nil {
+		t.Fatalf("writeFactories() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sc.genFileName(factoryFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type ConnFactory func() (*Conn, func(), error)",
+		"func ProvideConnFactory(p0 context.Context, p1 string) ConnFactory {\n" +
+			"\treturn func() (*Conn, func(), error) {\n\t\treturn NewConn(p0, p1)\n\t}\n}",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s missing %q:\n%s", sc.genFileName(factoryFile), want, data)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// resolveStructFields method    处理 fields=、exclude= 参数，确定 wire.Struct 注入的字段
+// 多个字段以 | 分隔，如 fields=Logger|DB、exclude=cache；只对没有构造函数的结构体有效。
+// 未指定参数但结构体带有 wire:"-" 字段时同样列出注入的字段，生成的代码中不再出现被忽略的字段.
+func (sc *AutoWireSearcher) resolveStructFields(wireElement *Element, decl *tmpDecl, options map[string]string) {
+	include, exclude := splitFieldList(options["fields"]), splitFieldList(options["exclude"])
+	st := structOf(decl)
+	structWire := st != nil && wireElement.Constructor == "" && !wireElement.ConfigWire && !wireElement.ValueWire
+	if len(include) == 0 && len(exclude) == 0 {
+		if structWire && slices.ContainsFunc(st.Fields.List, ignoredByWire) {
+			// 全部字段均被忽略时为空列表
+			wireElement.StructFields = append([]string{}, injectableFields(st)...)
+		}
+		return
+	}
+	if !structWire {
+		sc.logger.Warn("fields 与 exclude 参数只对使用 wire.Struct 注入的结构体有效，已忽略",
+			"element", describeElement(*wireElement))
+		return
+	}
+
+	all := injectableFields(st)
+	for _, name := range append(slices.Clone(include), exclude...) {
+		if !slices.Contains(all, name) {
+			sc.logger.Warn("结构体中不存在可注入的字段", "field", name, "element", describeElement(*wireElement))
+		}
+	}
+
+	fields := all
+	if len(include) > 0 {
+		fields = slices.DeleteFunc(slices.Clone(all), func(name string) bool { return !slices.Contains(include, name) })
+	}
+	fields = slices.DeleteFunc(fields, func(name string) bool { return slices.Contains(exclude, name) })
+	if fields == nil {
+		// nil 表示注入全部字段，全部排除时为空列表
+		fields = []string{}
+	}
+	wireElement.StructFields = fields
+}
+
+// splitFieldList function    解析以 | 分隔的字段列表.
+func splitFieldList(value string) []string {
+	var fields []string
+	for name := range strings.SplitSeq(value, "|") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// underlyingType function    返回非结构体类型声明的类型表达式，类型别名以 = 开头，如 int、= redis.Client
+// 结构体、接口与非类型声明返回空字符串.
+func underlyingType(decl *tmpDecl) string {
+	if decl.typeSpec == nil {
+		return ""
+	}
+	ts := decl.typeSpec
+	if ts.Assign.IsValid() {
+		return "= " + types.ExprString(ts.Type)
+	}
+	switch ts.Type.(type) {
+	case *ast.StructType, *ast.InterfaceType:
+		return ""
+	}
+	return types.ExprString(ts.Type)
+}
+
+// checkNonStructTypes method    检查没有构造函数的非结构体类型（如 type Port int、type Client = redis.Client）
+// wire.Struct 只能注入结构体类型声明的字段，这类类型需要构造函数，在生成之前给出带源码位置的错误.
+func (sc *AutoWireSearcher) checkNonStructTypes() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Underlying == "" || elem.Constructor != "" || elem.FuncDecl || elem.ValueWire {
+				continue
+			}
+			kind := fmt.Sprintf("%s 的底层类型 %s 不是结构体", elem.Name, elem.Underlying)
+			if strings.HasPrefix(elem.Underlying, "=") {
+				kind = fmt.Sprintf("%s 是类型别名（%s %s）", elem.Name, elem.Name, elem.Underlying)
+			}
+			reason := fmt.Sprintf("%s，无法使用 wire.Struct 注入，需要提供 New%s 或 Init%s 构造函数（或通过 new= 指定），"+
+				"也可以改为在该类型的包级变量上使用 @autowire.value: %s",
+				kind, elem.Name, elem.Name, describeElement(elem))
+			return errors.NewInvalidAnnotationError(sc.annotation(), reason)
+		}
+	}
+	return nil
+}
+
+// structOf function    返回类型声明的结构体类型，不是结构体时返回 nil.
+func structOf(decl *tmpDecl) *ast.StructType {
+	if decl.typeSpec == nil {
+		return nil
+	}
+	st, ok := decl.typeSpec.Type.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return nil
+	}
+	return st
+}
+
+// injectableFields function    返回 wire.Struct 可以注入的字段名（跳过 wire:"-"），嵌入字段使用类型名.
+func injectableFields(st *ast.StructType) []string {
+	var names []string
+	for _, field := range st.Fields.List {
+		if ignoredByWire(field) {
+			continue
+		}
+		if len(field.Names) == 0 {
+			if name := embedName(field.Type); name != "" {
+				names = append(names, name)
+			}
+			continue
+		}
+		for _, n := range field.Names {
+			if n.Name != "_" {
+				names = append(names, n.Name)
+			}
+		}
+	}
+	return names
+}
+
+// selectFields function    返回只包含指定字段的字段列表，names 为 nil 时返回全部未忽略的字段.
+func selectFields(st *ast.StructType, names []string) *ast.FieldList {
+	var list []*ast.Field
+	for _, field := range st.Fields.List {
+		if ignoredByWire(field) {
+			continue
+		}
+		if names == nil {
+			list = append(list, field)
+			continue
+		}
+		if len(field.Names) == 0 {
+			if slices.Contains(names, embedName(field.Type)) {
+				list = append(list, field)
+			}
+			continue
+		}
+		kept := slices.DeleteFunc(slices.Clone(field.Names), func(n *ast.Ident) bool {
+			return !slices.Contains(names, n.Name)
+		})
+		if len(kept) > 0 {
+			list = append(list, &ast.Field{Names: kept, Type: field.Type, Tag: field.Tag})
+		}
+	}
+	return &ast.FieldList{List: list}
+}
+
+// ambiguousFields method    返回注入字段中类型相同的字段分组，如 *example.com/db.DB: Primary、Replica
+// wire 按类型注入字段，同一类型的多个字段无法区分，会在运行 wire 时报错.
+func (r typeResolver) ambiguousFields(fl *ast.FieldList) []string {
+	var typeOrder []string
+	names := make(map[string][]string)
+	for _, field := range fl.List {
+		t := r.typeExpr(field.Type)
+		if len(field.Names) == 0 {
+			if _, seen := names[t]; !seen {
+				typeOrder = append(typeOrder, t)
+			}
+			names[t] = append(names[t], embedName(field.Type))
+			continue
+		}
+		for _, n := range field.Names {
+			if n.Name == "_" {
+				continue
+			}
+			if _, seen := names[t]; !seen {
+				typeOrder = append(typeOrder, t)
+			}
+			names[t] = append(names[t], n.Name)
+		}
+	}
+
+	var result []string
+	for _, t := range typeOrder {
+		if len(names[t]) > 1 {
+			result = append(result, t+": "+strings.Join(names[t], "、"))
+		}
+	}
+	return result
+}
+
+// checkAmbiguousFields method    检查 wire.Struct 注入的字段中是否有类型相同的字段
+// 在运行 wire 之前给出带结构体位置与字段名的错误.
+func (sc *AutoWireSearcher) checkAmbiguousFields() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			if elem := sc.ElementMap[set][key]; len(elem.Ambiguous) > 0 {
+				return errors.NewAmbiguousFieldsError(describeElement(elem), elem.Ambiguous)
+			}
+		}
+	}
+	return nil
+}
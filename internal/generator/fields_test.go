@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestStructFields(t *testing.T) {
+	src := "package svc\n\n" +
+		"type Logger struct{}\n\ntype DB struct{}\n\ntype cache struct{}\n\n" +
+		"// @autowire(set=svc,fields=Logger|DB)\ntype A struct {\n\tLogger *Logger\n\tDB *DB\n\tOpt int\n}\n\n" +
+		"// @autowire(set=svc,exclude=cache)\ntype B struct {\n\t*Logger\n\tcache *cache\n\tSkip int `wire:\"-\"`\n}\n\n" +
+		"// @autowire(set=svc)\ntype C struct {\n\tDB *DB\n}\n\n" +
+		"// @autowire(set=svc,exclude=DB)\ntype D struct {\n\tDB *DB\n}\n\n" +
+		"// @autowire(set=svc)\ntype E struct {\n\t*Logger\n\tDB *DB\n\tcache *cache `wire:\"-\"`\n}\n\n" +
+		"// @autowire(set=svc)\ntype F struct {\n\tcache *cache `wire:\"-\"`\n}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+		getImplement(f))
+
+	want := map[string]struct {
+		fields []string
+		args   string
+		deps   []string
+	}{
+		"A": {[]string{"Logger", "DB"}, `, "Logger", "DB"`, []string{"example.com/svc.Logger", "example.com/svc.DB"}},
+		"B": {[]string{"Logger"}, `, "Logger"`, []string{"example.com/svc.Logger"}},
+		"C": {nil, `, "*"`, []string{"example.com/svc.DB"}},
+		"D": {[]string{}, ``, nil},
+		// 带 wire:"-" 字段时列出注入的字段
+		"E": {[]string{"Logger", "DB"}, `, "Logger", "DB"`, []string{"example.com/svc.Logger", "example.com/svc.DB"}},
+		"F": {[]string{}, ``, nil},
+	}
+	for _, e := range elements {
+		w := want[e.Name]
+		if !slices.Equal(e.StructFields, w.fields) || (e.StructFields == nil) != (w.fields == nil) {
+			t.Errorf("%s: StructFields = %#v, want %#v", e.Name, e.StructFields, w.fields)
+		}
+		if got := structFieldArgs(&e); got != w.args {
+			t.Errorf("%s: args = %s, want %s", e.Name, got, w.args)
+		}
+		if !slices.Equal(e.Deps, w.deps) {
+			t.Errorf("%s: Deps = %v, want %v", e.Name, e.Deps, w.deps)
+		}
+	}
+}
+
+func TestAmbiguousFields(t *testing.T) {
+	src := "package svc\n\nimport \"database/sql\"\n\n" +
+		"// @autowire(set=svc)\ntype A struct {\n\tPrimary, Replica *sql.DB\n\tName string\n}\n\n" +
+		"// @autowire(set=svc,exclude=Replica)\ntype B struct {\n\tPrimary *sql.DB\n\tReplica *sql.DB\n}\n\n" +
+		"// @autowire(set=svc)\ntype C struct {\n\tPrimary *sql.DB\n\tReplica *sql.DB `wire:\"-\"`\n\tConn sql.DB\n}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+		getImplement(f))
+
+	want := map[string][]string{"A": {"*database/sql.DB: Primary、Replica"}}
+	sc.ElementMap["svc"] = make(map[string]Element)
+	for _, e := range elements {
+		if !slices.Equal(e.Ambiguous, want[e.Name]) {
+			t.Errorf("%s: Ambiguous = %v, want %v", e.Name, e.Ambiguous, want[e.Name])
+		}
+		sc.ElementMap["svc"][e.Name] = e
+	}
+
+	err = sc.checkAmbiguousFields()
+	if err == nil || !strings.Contains(err.Error(), "svc.A (svc.go:6:6)") {
+		t.Errorf("checkAmbiguousFields() error = %v", err)
+	}
+}
+
+func TestNonStructTypes(t *testing.T) {
+	src := "package svc\n\nimport \"database/sql\"\n\n" +
+		"// @autowire(set=svc)\ntype Port int\n\nfunc NewPort() Port { return 8080 }\n\n" +
+		"// @autowire(set=svc)\ntype DB = sql.DB\n\nfunc NewDB() *sql.DB { return nil }\n\n" +
+		"// @autowire(set=svc,fields=A)\ntype Handlers []string\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+		getImplement(f))
+
+	want := map[string]struct {
+		underlying string
+		provides   []string
+	}{
+		"Port":     {"int", []string{"example.com/svc.Port"}},
+		"DB":       {"= sql.DB", []string{"example.com/svc.DB", "database/sql.DB"}},
+		"Handlers": {"[]string", []string{"example.com/svc.Handlers"}},
+	}
+	for _, e := range elements {
+		w := want[e.Name]
+		if e.Underlying != w.underlying || !slices.Equal(e.Provides, w.provides) {
+			t.Errorf("%s: Underlying = %q, Provides = %v", e.Name, e.Underlying, e.Provides)
+		}
+	}
+
+	// 有构造函数的 Port、DB 可以生成，没有构造函数的 Handlers 在生成之前报错
+	err = sc.checkNonStructTypes()
+	if err == nil || !strings.Contains(err.Error(), "Handlers 的底层类型 []string 不是结构体") ||
+		!strings.Contains(err.Error(), "svc.go:16:6") {
+		t.Errorf("checkNonStructTypes() error = %v", err)
+	}
+	delete(sc.ElementMap["svc"], "example.com/svc/Handlers")
+	if err := sc.checkNonStructTypes(); err != nil {
+		t.Errorf("checkNonStructTypes() error = %v", err)
+	}
+}
@@ -0,0 +1,481 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// fxImport fx 的导入声明.
+const fxImport = `"go.uber.org/fx"`
+
+// FxModule struct    表示一个 fx 模块文件的配置信息.
+type FxModule struct {
+	Package string   // 包名
+	Name    string   // 变量名，如 StorageModule
+	Module  string   // fx.Module 的名称，为空时生成 fx.Options
+	Options []string // 模块中的选项，如 fx.Provide(...)
+	Tags    []string // 构建约束，如 prod、!dev
+}
+
+// GoBuild method    返回 //go:build 约束表达式，如 prod && !dev.
+func (m FxModule) GoBuild() string {
+	return strings.Join(m.Tags, " && ")
+}
+
+// FxModuleTemp 预编译的 fx 模块模板.
+var FxModuleTemp = template.Must(template.New("").Parse(fxModuleTemplate))
+
+// fxModuleTemplate fx 模块的代码生成模板
+// 用于生成类似 var StorageModule = fx.Module("storage", ...) 的代码.
+var fxModuleTemplate = `// Code generated by go-autowire. DO NOT EDIT.
+{{ if .Tags }}
+//go:build {{ .GoBuild }}
+{{ end }}
+package {{ .Package }}
+
+import (
+	"go.uber.org/fx"
+)
+
+var {{ .Name }} = {{ if .Module }}fx.Module("{{ .Module }}",{{ else }}fx.Options({{ end }}
+{{- range .Options }}
+	{{ . }},
+{{- end }}
+)
+`
+
+// fxProvider struct    组件在 fx 中的提供方式.
+type fxProvider struct {
+	fn     string // Provide 函数、构造函数或变量名称，为空表示无法提供
+	supply bool   // 是否通过 fx.Supply 提供（未声明类型的 @autowire.value 变量）
+}
+
+// fxModuleName function    返回 Set 对应的模块变量名，如 storage 返回 StorageModule.
+func fxModuleName(set string) string {
+	return strings.TrimSuffix(setVarName(set), "Set") + "Module"
+}
+
+// WriteFx method    生成 uber-go/fx 的模块注册代码（--backend=fx），不生成 wire Set 与初始化入口
+// 每个 Set 生成 autowire_<set>.go，定义 var <Set>Module = fx.Module("<set>", ...)，
+// autowire_modules.go 将未拆分的模块汇总为 var Module = fx.Options(...)；
+// 没有构造函数的结构体、返回 cleanup 的构造函数、config 字段以及声明了类型的变量
+// 需要在组件所在包的 autowire_fx.go 中生成对应的 Provide 函数.
+func (sc *AutoWireSearcher) WriteFx() error {
+	sc.logger.Info("正在生成 fx 模块到目录", "path", sc.genPath)
+	sc.produced = &fileList{}
+	sc.written = &fileList{}
+
+	// 确保目标目录存在
+	if err := sc.ensureGenPath(); err != nil {
+		return err
+	}
+
+	// 整理并校验扫描结果（重复接口绑定、注入入口名称）
+	if err := sc.Prepare(); err != nil {
+		return err
+	}
+	if err := sc.Validate(); err != nil {
+		return err
+	}
+	if sc.hasMocks() {
+		sc.logger.Warn("fx 后端不支持测试替身，@autowire.mock 组件被忽略")
+		delete(sc.ElementMap, mockSet)
+	}
+	if groups := sc.outputGroups(); len(groups) > 1 {
+		sc.logger.Warn("fx 后端不支持 Set 输出目录，out 与 set_outputs 配置被忽略")
+	}
+
+	// 生成组件所在包中的限定类型、方法工厂包装函数、读取环境变量的提供者与 Provide 函数
+	if err := sc.writeQualifiers(); err != nil {
+		return err
+	}
+	if err := sc.writeFactories(); err != nil {
+		return err
+	}
+	if err := sc.writeEnvProviders(); err != nil {
+		return err
+	}
+	providers, err := sc.writeFxProviders()
+	if err != nil {
+		return err
+	}
+
+	// 清理过期的文件（本次不再生成的模块以及 wire 后端生成的文件）
+	if err := sc.clean(sc.expectedFxFiles()); err != nil {
+		return fmt.Errorf("清理旧文件失败: %w", err)
+	}
+
+	// 并发生成每个 Set 的模块文件
+	var modules []string
+	g := sc.group()
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		elements := sc.ElementMap[set]
+		g.Go(func() error {
+			return sc.writeFxModule(set, elements, providers)
+		})
+		// 拆分出的 Set 与带构建约束的 Set 由使用者自行组合，不加入汇总
+		if !sc.splitSets.Contains(set) && sc.setBuildTags[set] == "" {
+			modules = append(modules, fxModuleName(set))
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("生成模块文件失败: %w", err)
+	}
+	if err := sc.writeFxComposites(); err != nil {
+		return fmt.Errorf("生成组合模块文件失败: %w", err)
+	}
+	if err := sc.writeGroupsFile(); err != nil {
+		return fmt.Errorf("生成分组文件失败: %w", err)
+	}
+
+	// 生成汇总文件
+	if len(modules) > 0 {
+		data := FxModule{Package: sc.pkg, Name: "Module", Options: modules}
+		if err := sc.writeTemplateFile(filepath.Join(sc.genPath, sc.genFileName(fxModulesFile)), FxModuleTemp, data,
+			nil); err != nil {
+			return err
+		}
+	}
+
+	// 删除上次生成、本次不再生成的文件，保存缓存（包含生成文件的指纹）
+	sc.pruneStale()
+	sc.saveCache()
+	return nil
+}
+
+// expectedFxFiles method    返回 fx 后端会产生的 autowire_*.go 文件名，用于清理过期文件.
+func (sc *AutoWireSearcher) expectedFxFiles() []string {
+	var files []string
+	for set, elements := range sc.ElementMap {
+		files = append(files, filepath.Base(sc.setFileName(set)))
+		if tags := elementTags(elements); len(tags) > 0 {
+			for _, tag := range append(tags, defaultTagFile) {
+				files = append(files, filepath.Base(sc.setFileName(set+"_"+tag)))
+			}
+		}
+	}
+	if len(files) > 0 {
+		files = append(files, sc.genFileName(fxModulesFile))
+	}
+	for _, file := range sc.compositeFiles() {
+		files = append(files, filepath.Base(file))
+	}
+	if len(sc.groupProviders()) > 0 {
+		files = append(files, sc.genFileName(groupFile))
+	}
+	return files
+}
+
+// writeFxModule method    为单个 Set 生成 fx 模块文件
+// 带构建标签的组件生成到带 //go:build 约束的 <Set>TaggedModule 中，由主模块引用.
+func (sc *AutoWireSearcher) writeFxModule(set string, elements map[string]Element,
+	providers map[string]fxProvider) error {
+	name := fxModuleName(set)
+	fileName := sc.setFileName(set)
+	sc.logger.Debug("正在生成 "+name, "file", fileName)
+
+	order := parser.SortedKeys(elements)
+	sc.resolvePackageConflicts(elements, make(map[string]map[string]string), order)
+
+	untagged, tagged := splitByTag(elements)
+	var options []string
+	if len(tagged) > 0 {
+		taggedName := strings.TrimSuffix(name, "Module") + "TaggedModule"
+		tags := slices.Sorted(maps.Keys(tagged))
+		for _, tag := range tags {
+			opts, imports := sc.fxOptions(tagged[tag], order, providers)
+			data := FxModule{Package: sc.pkg, Name: taggedName, Options: opts,
+				Tags: append(sc.setConstraints(set), tag)}
+			if err := sc.writeTemplateFile(sc.setFileName(set+"_"+tag), FxModuleTemp, data, imports); err != nil {
+				return err
+			}
+		}
+		// 没有指定任何标签时使用空模块，保证不带标签时同样可以编译
+		data := FxModule{
+			Package: sc.pkg,
+			Name:    taggedName,
+			Tags:    append(sc.setConstraints(set), parser.Map(tags, func(tag string) string { return "!" + tag })...),
+		}
+		if err := sc.writeTemplateFile(sc.setFileName(set+"_"+defaultTagFile), FxModuleTemp, data, nil); err != nil {
+			return err
+		}
+		options = append(options, taggedName)
+	}
+
+	opts, imports := sc.fxOptions(untagged, order, providers)
+	data := FxModule{Package: sc.pkg, Name: name, Module: set, Options: append(opts, options...),
+		Tags: sc.setConstraints(set)}
+	return sc.writeTemplateFile(fileName, FxModuleTemp, data, imports)
+}
+
+// fxOptions method    生成模块中的 fx.Provide 与 fx.Supply 选项
+// 绑定接口的组件使用 fx.Annotate(..., fx.As(...)) 同时提供自身类型与接口，
+// 与 wire 一致，绑定了接口的变量只提供接口类型.
+func (sc *AutoWireSearcher) fxOptions(elements map[string]Element, order []string,
+	providers map[string]fxProvider) ([]string, []*ast.ImportSpec) {
+	var importPkg []*ast.ImportSpec
+	pathPkg := sc.getPkgPath(filepath.Join(sc.genPath, sc.genFileName(fxModulesFile)))
+	refs := newInterfaceRefs(pathPkg, elements)
+
+	var provides, supplies []string
+	for _, key := range order {
+		elem, ok := elements[key]
+		if !ok || providers[key].fn == "" {
+			continue
+		}
+		p := providers[key]
+
+		// 如果元素在同一个包中，不需要包前缀
+		if elem.PkgPath == pathPkg {
+			elem.Pkg = ""
+		}
+
+		item := parser.AppendPkg(elem.Pkg, p.fn)
+		if len(elem.RawExpr) > 0 {
+			item = rawExpr(elem.RawExpr, refs)
+		}
+		as := parser.Map(elem.Implements, func(itf string) string {
+			return fmt.Sprintf("fx.As(new(%s))", sc.interfaceName(&elem, itf, refs))
+		})
+		if len(as) > 0 {
+			if !elem.ValueWire {
+				as = append([]string{"fx.As(fx.Self())"}, as...)
+			}
+			item = fmt.Sprintf("fx.Annotate(%s, %s)", item, strings.Join(as, ", "))
+		}
+		if p.supply {
+			supplies = append(supplies, item)
+		} else {
+			provides = append(provides, item)
+		}
+
+		// 如果需要导入包，添加到 import 列表
+		importPkg = append(importPkg, sc.elementImport(&elem)...)
+	}
+
+	var options []string
+	if len(provides) > 0 {
+		options = append(options, "fx.Provide(\n\t\t"+strings.Join(provides, ",\n\t\t")+",\n\t)")
+	}
+	if len(supplies) > 0 {
+		options = append(options, "fx.Supply(\n\t\t"+strings.Join(supplies, ",\n\t\t")+",\n\t)")
+	}
+	return options, append(importPkg, refs.imports...)
+}
+
+// writeFxProviders method    在组件所在的包目录中生成 autowire_fx.go，返回 组件 key -> 提供方式
+// 扫描过的目录中不再需要 Provide 函数时删除旧文件.
+func (sc *AutoWireSearcher) writeFxProviders() (map[string]fxProvider, error) {
+	providers := make(map[string]fxProvider)
+	files := make(map[string]*sourceFile)
+	parsed := make(map[string]*ast.File)
+
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.GroupProvider {
+				// 分组的汇总函数生成到生成路径，直接引用
+				providers[key] = fxProvider{fn: elem.Constructor}
+				continue
+			}
+			f := sc.parseSourceFile(elem.Position.Filename, parsed)
+			if f == nil {
+				// 无法读取源文件时只能直接引用构造函数
+				providers[key] = fxProvider{fn: elem.Constructor}
+				continue
+			}
+			p, decls := sc.fxProvider(elem, f)
+			providers[key] = p
+			if len(decls) > 0 {
+				// 构造函数声明在包中其他文件时，包装函数使用该文件的导入
+				src := f
+				if elem.Constructor != "" {
+					src = sc.declFile(f, elem.Constructor)
+				}
+				addSourceDecls(files, elem, append(fileImports(src), fxImport), decls)
+			}
+		}
+	}
+	return providers, sc.writeSourceFiles(sc.genFileName(fxProviderFile), files)
+}
+
+// parseSourceFile method    解析组件所在的源文件，同一文件只解析一次.
+func (sc *AutoWireSearcher) parseSourceFile(fileName string, parsed map[string]*ast.File) *ast.File {
+	if fileName == "" {
+		return nil
+	}
+	if f, ok := parsed[fileName]; ok {
+		return f
+	}
+	f, err := goparser.ParseFile(sc.fileSet(), fileName, nil, 0)
+	if err != nil {
+		sc.logger.Warn("解析源文件失败", "file", fileName, "error", err)
+		f = nil
+	} else {
+		sc.bindPackage(f, fileName)
+	}
+	parsed[fileName] = f
+	return f
+}
+
+// fxProvider method    返回组件在 fx 中的提供方式，以及需要生成到组件所在包中的声明.
+func (sc *AutoWireSearcher) fxProvider(elem Element, f *ast.File) (fxProvider, []string) {
+	switch {
+	case len(elem.RawExpr) > 0:
+		// 自定义提供者直接使用表达式
+		return fxProvider{fn: elem.Name}, nil
+	case elem.ConfigWire:
+		return sc.fxConfigProvider(elem, f)
+	case elem.ValueWire:
+		vs := findValueSpec(f, elem.Name)
+		if vs == nil || vs.Type == nil {
+			return fxProvider{fn: elem.Name, supply: true}, nil
+		}
+		// 声明了类型的变量通过函数提供，保证提供的是声明的类型而不是动态类型
+		fn := "Provide" + elem.Name + "Fx"
+		return fxProvider{fn: fn}, []string{fmt.Sprintf(
+			"// %s 由 @autowire.value 生成，向 fx 提供 %s.\nfunc %s() %s {\n\treturn %s\n}",
+			fn, elem.Name, fn, types.ExprString(vs.Type), elem.Name)}
+	case elem.Constructor != "":
+		if !elem.Cleanup {
+			return fxProvider{fn: elem.Constructor}, nil
+		}
+		return sc.fxCleanupProvider(elem, f)
+	default:
+		return sc.fxStructProvider(elem, f)
+	}
+}
+
+// fxCleanupProvider method    为返回 cleanup 的构造函数生成包装函数，cleanup 注册为 fx 的 OnStop 钩子.
+func (sc *AutoWireSearcher) fxCleanupProvider(elem Element, f *ast.File) (fxProvider, []string) {
+	fd := sc.findFuncDecl(f, elem.Constructor)
+	if fd == nil || fd.Type.TypeParams != nil {
+		sc.logger.Warn("fx 后端无法包装返回 cleanup 的构造函数，cleanup 不会被调用", "element", describeElement(elem))
+		return fxProvider{fn: elem.Constructor}, nil
+	}
+
+	params, args := funcParams(fd)
+	call := fmt.Sprintf("%s(%s)", elem.Constructor, strings.Join(args, ", "))
+	fn := elem.Constructor + "Fx"
+	out, body := elem.Result, fmt.Sprintf("\tv, cleanup := %s\n\tlc.Append(fx.StopHook(cleanup))\n\treturn v", call)
+	if elem.ReturnsErr {
+		out = "(" + elem.Result + ", error)"
+		body = fmt.Sprintf("\tv, cleanup, err := %s\n\tif err != nil {\n\t\treturn v, err\n\t}\n"+
+			"\tlc.Append(fx.StopHook(cleanup))\n\treturn v, nil", call)
+	}
+	return fxProvider{fn: fn}, []string{fmt.Sprintf(
+		"// %s 由 @autowire 生成，调用 %s 并将 cleanup 注册为 fx 的 OnStop 钩子.\nfunc %s(%s) %s {\n%s\n}",
+		fn, elem.Constructor, fn, strings.Join(append([]string{"lc fx.Lifecycle"}, params...), ", "), out, body)}
+}
+
+// fxStructProvider method    为没有构造函数的结构体生成构造函数
+// 注入除 wire:"-" 外的所有字段或 fields=、exclude= 指定的字段（对应 wire.Struct）.
+func (sc *AutoWireSearcher) fxStructProvider(elem Element, f *ast.File) (fxProvider, []string) {
+	ts := findTypeSpec(f, elem.Name)
+	if ts == nil || ts.TypeParams != nil {
+		sc.logger.Warn("fx 后端无法为该组件生成构造函数，已跳过", "element", describeElement(elem))
+		return fxProvider{}, nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		sc.logger.Warn("fx 后端无法为该组件生成构造函数，已跳过", "element", describeElement(elem))
+		return fxProvider{}, nil
+	}
+
+	var params, inits []string
+	for _, field := range selectFields(st, elem.StructFields).List {
+		names := parser.Map(field.Names, func(n *ast.Ident) string { return n.Name })
+		if len(names) == 0 {
+			names = []string{embedName(field.Type)}
+		}
+		for _, name := range names {
+			if name == "" || name == "_" {
+				sc.logger.Warn("fx 后端无法为该组件生成构造函数，已跳过", "element", describeElement(elem))
+				return fxProvider{}, nil
+			}
+			p := fmt.Sprintf("p%d", len(params))
+			params = append(params, p+" "+types.ExprString(field.Type))
+			inits = append(inits, fmt.Sprintf("\t\t%s: %s,", name, p))
+		}
+	}
+
+	fn := "New" + elem.Name + "Fx"
+	body := "&" + elem.Name + "{}"
+	if len(inits) > 0 {
+		body = "&" + elem.Name + "{\n" + strings.Join(inits, "\n") + "\n\t}"
+	}
+	return fxProvider{fn: fn}, []string{fmt.Sprintf(
+		"// %s 由 @autowire 生成，注入 %s 的字段.\nfunc %s(%s) *%s {\n\treturn %s\n}",
+		fn, elem.Name, fn, strings.Join(params, ", "), elem.Name, body)}
+}
+
+// fxConfigProvider method    为 @autowire.config 生成 fx.Out 结构体，将配置的导出字段分别提供给 fx
+// 配置本身（*Config）需要由使用者通过 fx.Supply 提供.
+func (sc *AutoWireSearcher) fxConfigProvider(elem Element, f *ast.File) (fxProvider, []string) {
+	ts := findTypeSpec(f, elem.Name)
+	if ts == nil {
+		return fxProvider{}, nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return fxProvider{}, nil
+	}
+
+	var fields, inits []string
+	for _, field := range st.Fields.List {
+		for _, n := range field.Names {
+			if slices.Contains(elem.Fields, n.Name) {
+				fields = append(fields, fmt.Sprintf("\t%s %s", n.Name, types.ExprString(field.Type)))
+				inits = append(inits, fmt.Sprintf("\t\t%s: c.%s,", n.Name, n.Name))
+			}
+		}
+	}
+
+	outName := elem.Name + "FxFields"
+	fn := "New" + outName
+	return fxProvider{fn: fn}, []string{
+		fmt.Sprintf("// %s 由 @autowire.config 生成，向 fx 提供 %s 的字段.\ntype %s struct {\n\tfx.Out\n\n%s\n}",
+			outName, elem.Name, outName, strings.Join(fields, "\n")),
+		fmt.Sprintf("// %s 返回 %s 的字段.\nfunc %s(c *%s) %s {\n\treturn %s{\n%s\n\t}\n}",
+			fn, elem.Name, fn, elem.Name, outName, outName, strings.Join(inits, "\n")),
+	}
+}
+
+// findTypeSpec function    在文件的顶层声明中查找类型声明.
+func findTypeSpec(f *ast.File, name string) *ast.TypeSpec {
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+			for _, spec := range gd.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					return ts
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findValueSpec function    在文件的顶层声明中查找包级变量声明.
+func findValueSpec(f *ast.File, name string) *ast.ValueSpec {
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.VAR {
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if slices.ContainsFunc(vs.Names, func(n *ast.Ident) bool { return n.Name == name }) {
+					return vs
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const fxSrc = `package svc
+
+type Store interface{ Get() string }
+
+type DSN string
+
+type Label string
+
+// @autowire.config(set=config)
+type Config struct {
+	DSN  DSN
+	Port int
+}
+
+// @autowire(set=storage,Store)
+type DB struct {
+	Dsn  DSN
+	Skip string ` + "`wire:\"-\"`" + `
+}
+
+// @autowire(set=storage)
+func NewConn(d DSN) (*Conn, func(), error) { return &Conn{}, func() {}, nil }
+
+type Conn struct{}
+
+// @autowire.value(set=storage)
+var DefaultLabel Label = "hello"
+
+// @autowire.value(set=storage)
+var Version = "v1"
+
+// @autowire(set=storage,tag=prod)
+func NewProd() *Prod { return nil }
+
+type Prod struct{}
+`
+
+func TestWriteFx(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "svc.go")
+	if err := os.WriteFile(file, []byte(fxSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, fxSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genPath := filepath.Join(dir, "gen")
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		genPath:    genPath,
+		pkg:        "gen",
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(genPath, false),
+	}
+	sc.scannedDirs = map[string]struct{}{dir: {}}
+	sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/svc", f, getImplement(f))
+
+	if err := sc.WriteFx(); err != nil {
+		t.Fatalf("WriteFx() error = %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{filepath.Join(genPath, "autowire_storage.go"), []string{
+			`var StorageModule = fx.Module("storage",`,
+			"fx.Annotate(svc.NewDBFx, fx.As(fx.Self()), fx.As(new(svc.Store))),",
+			"svc.NewConnFx,",
+			"svc.ProvideDefaultLabelFx,",
+			"fx.Supply(\n\t\tsvc.Version,\n\t),",
+			"StorageTaggedModule,",
+		}},
+		{filepath.Join(genPath, "autowire_storage_prod.go"), []string{
+			"//go:build prod\n", "var StorageTaggedModule = fx.Options(", "svc.NewProd,",
+		}},
+		{filepath.Join(genPath, "autowire_storage_default.go"), []string{
+			"//go:build !prod\n", "var StorageTaggedModule = fx.Options()",
+		}},
+		{filepath.Join(genPath, "autowire_config.go"), []string{"svc.NewConfigFxFields,"}},
+		{filepath.Join(genPath, "autowire_modules.go"), []string{
+			"var Module = fx.Options(\n\tConfigModule,\n\tStorageModule,\n)",
+		}},
+		{filepath.Join(dir, sc.genFileName(fxProviderFile)), []string{
+			"func NewDBFx(p0 DSN) *DB {\n\treturn &DB{\n\t\tDsn: p0,\n\t}\n}",
+			"func NewConnFx(lc fx.Lifecycle, p0 DSN) (*Conn, error) {\n" +
+				"\tv, cleanup, err := NewConn(p0)\n\tif err != nil {\n\t\treturn v, err\n\t}\n" +
+				"\tlc.Append(fx.StopHook(cleanup))\n\treturn v, nil\n}",
+			"func ProvideDefaultLabelFx() Label {\n\treturn DefaultLabel\n}",
+			"type ConfigFxFields struct {\n\tfx.Out\n\n\tDSN  DSN\n\tPort int\n}",
+			"func NewConfigFxFields(c *Config) ConfigFxFields {",
+		}},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(data)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s missing %q:\n%s", filepath.Base(tt.file), want, out)
+			}
+		}
+	}
+
+	// 切换回 wire 后端时删除 fx 后端生成的文件
+	if err := sc.writeSourceFiles(sc.genFileName(fxProviderFile), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, sc.genFileName(fxProviderFile))); !os.IsNotExist(err) {
+		t.Errorf("%s 应被删除, err = %v", sc.genFileName(fxProviderFile), err)
+	}
+}
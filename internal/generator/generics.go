@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// typeArgPattern 合法的类型实参：类型名或 包名.类型名，可带指针，如 User、*model.User.
+var typeArgPattern = regexp.MustCompile(`^\*?([A-Za-z_]\w*\.)?[A-Za-z_]\w*$`)
+
+// resolveTypeParams method    记录泛型声明的类型参数个数，并将 of= 指定的类型实参解析为完整形式
+// 有构造函数时以构造函数的类型参数为准，否则以类型声明的类型参数为准；多个类型实参以 ; 分隔
+// 返回源码形式的类型实参，用于生成组件包中的包装构造函数.
+func (sc *AutoWireSearcher) resolveTypeParams(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath,
+	of string) []string {
+	wireElement.TypeParams = sc.typeParamList(wireElement, decl, f).NumFields()
+	if of == "" {
+		return nil
+	}
+	r := typeResolver{file: f, pkgPath: pkgPath}
+	var args []string
+	for _, arg := range strings.Split(of, ";") {
+		arg = strings.TrimSpace(arg)
+		if !typeArgPattern.MatchString(arg) {
+			sc.logger.Warn("无效的类型实参，已忽略", "of", arg, "element", describeElement(*wireElement))
+			continue
+		}
+		wireElement.TypeArgs = append(wireElement.TypeArgs, r.typeArg(arg))
+		args = append(args, arg)
+	}
+	return args
+}
+
+// applyTypeArgs method    为泛型组件的实例化生成非泛型的包装构造函数 Provide<Name><TypeArgs>
+// wire 不支持泛型的 wire.Struct 与实例化的泛型函数，Set 改为引用组件所在包中的包装构造函数
+// 结构体按 wire.Struct 注入的字段构造，构造函数以 args 实例化后调用，cleanup 与 error 原样返回.
+func (sc *AutoWireSearcher) applyTypeArgs(wireElement *Element, decl *tmpDecl, f *ast.File, args []string) {
+	if wireElement.TypeParams == 0 || len(args) != wireElement.TypeParams || wireElement.ConfigWire {
+		return
+	}
+	subst := typeParamSubst(sc.typeParamList(wireElement, decl, f), args)
+	name := "Provide" + wireElement.Name + strings.Join(parser.Map(args, typeArgName), "")
+	imports := fileImports(f)
+
+	var params []string
+	var provided, result, body string
+	switch st := structOf(decl); {
+	case wireElement.Constructor != "":
+		fd := sc.findFuncDecl(f, wireElement.Constructor)
+		if fd == nil || fd.Type.Results == nil {
+			return
+		}
+		// 构造函数可以声明在包中的其他文件中，参数与返回值使用构造函数所在文件的导入
+		imports = append(imports, fileImports(sc.declFile(f, fd.Name.Name))...)
+		var callArgs []string
+		params, callArgs = funcParams(fd)
+		outs := parser.Map(fd.Type.Results.List, func(field *ast.Field) string {
+			return subst(types.ExprString(field.Type))
+		})
+		provided, result = outs[0], outs[0]
+		if len(outs) > 1 {
+			result = "(" + strings.Join(outs, ", ") + ")"
+		}
+		body = fmt.Sprintf("%s[%s](%s)", fd.Name.Name, strings.Join(args, ", "), strings.Join(callArgs, ", "))
+	case st != nil:
+		var values []string
+		for _, field := range st.Fields.List {
+			names := parser.Map(field.Names, func(n *ast.Ident) string { return n.Name })
+			if len(field.Names) == 0 {
+				names = []string{embedName(field.Type)}
+			}
+			for _, n := range names {
+				if n == "" || n == "_" || ignoredByWire(field) ||
+					(wireElement.StructFields != nil && !slices.Contains(wireElement.StructFields, n)) {
+					continue
+				}
+				p := fmt.Sprintf("p%d", len(params))
+				params = append(params, p+" "+types.ExprString(field.Type))
+				values = append(values, n+": "+p)
+			}
+		}
+		provided = wireElement.Name + "[" + strings.Join(args, ", ") + "]"
+		result, body = provided, provided+"{"+strings.Join(values, ", ")+"}"
+		if !wireElement.BindValue {
+			provided, result, body = "*"+provided, "*"+result, "&"+body
+		}
+	default:
+		return
+	}
+
+	wireElement.Wrappers = append(wireElement.Wrappers, fmt.Sprintf(
+		"// %s 由 @autowire(of=%s) 生成，提供 %s.\nfunc %s(%s) %s {\n\treturn %s\n}",
+		name, strings.Join(args, ";"), provided, name,
+		subst(strings.Join(params, ", ")), result, body))
+	wireElement.Imports = imports
+	wireElement.Constructor = name
+}
+
+// typeParamSubst function    返回将类型表达式中的类型参数替换为类型实参的函数，如 Repo[T] 替换为 Repo[model.User].
+func typeParamSubst(params *ast.FieldList, args []string) func(string) string {
+	var patterns []*regexp.Regexp
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			patterns = append(patterns, regexp.MustCompile(`(^|[^.\w])`+regexp.QuoteMeta(name.Name)+`\b`))
+		}
+	}
+	return func(expr string) string {
+		for i, re := range patterns {
+			if i < len(args) {
+				expr = re.ReplaceAllString(expr, "${1}"+args[i])
+			}
+		}
+		return expr
+	}
+}
+
+// typeArgName function    返回类型实参在包装构造函数名称中的形式，如 *model.Order 返回 PtrOrder.
+func typeArgName(arg string) string {
+	name := strings.TrimPrefix(arg, "*")
+	name = strcase.UpperCamelCase(name[strings.LastIndex(name, ".")+1:])
+	if strings.HasPrefix(arg, "*") {
+		return "Ptr" + name
+	}
+	return name
+}
+
+// typeParamList method    返回组件的类型参数列表，非泛型组件返回 nil.
+func (sc *AutoWireSearcher) typeParamList(wireElement *Element, decl *tmpDecl, f *ast.File) *ast.FieldList {
+	switch {
+	case decl.valueSpec != nil:
+		return nil
+	case wireElement.Constructor != "":
+		if fd := sc.findFuncDecl(f, wireElement.Constructor); fd != nil {
+			return fd.Type.TypeParams
+		}
+	case decl.typeSpec != nil:
+		return decl.typeSpec.TypeParams
+	}
+	return nil
+}
+
+// substituteTypeParams function    将依赖与提供的类型中的类型参数替换为对应的类型实参
+// 例如 Repo[T] 的字段 T 解析为 pkg.T，实例化为 Repo[model.User] 后替换为 example.com/model.User.
+func substituteTypeParams(wireElement *Element, params *ast.FieldList, pkgPath string) {
+	args := make(map[string]string)
+	i := 0
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			if i < len(wireElement.TypeArgs) {
+				args[pkgPath+"."+name.Name] = strings.TrimPrefix(wireElement.TypeArgs[i], "*")
+			}
+			i++
+		}
+	}
+	replace := func(list []string) []string {
+		return parser.Map(list, func(t string) string { return substituteTypeKey(t, args) })
+	}
+	wireElement.Deps = replace(wireElement.Deps)
+	wireElement.Provides = replace(wireElement.Provides)
+}
+
+// substituteTypeKey function    替换类型中的类型参数，包括类型实参列表中的类型参数，如 Cache[pkg.K,pkg.V].
+func substituteTypeKey(t string, args map[string]string) string {
+	if arg, ok := args[t]; ok {
+		return arg
+	}
+	prefix := t[:len(t)-len(strings.TrimLeft(t, "[]*"))]
+	base := typeBase(t[len(prefix):])
+	if base == t[len(prefix):] || !strings.HasSuffix(t, "]") {
+		return t
+	}
+	inner := t[len(prefix)+len(base)+1 : len(t)-1]
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range inner {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, substituteTypeKey(inner[start:i], args))
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, substituteTypeKey(inner[start:], args))
+	return prefix + base + "[" + strings.Join(parts, ",") + "]"
+}
+
+// typeArg method    将类型实参转换为完整形式，如 *model.User 转换为 *example.com/model.User.
+func (r typeResolver) typeArg(arg string) string {
+	name := strings.TrimPrefix(arg, "*")
+	if types.Universe.Lookup(name) != nil {
+		return arg
+	}
+	return strings.TrimSuffix(arg, name) + r.qualifyName(name)
+}
+
+// instanceName function    返回组件在 ElementMap 中使用的名称，泛型实例化时附加类型实参，如 Repo[model.User].
+func instanceName(elem Element) string {
+	if len(elem.TypeArgs) == 0 {
+		return elem.Name
+	}
+	args := parser.Map(elem.TypeArgs, func(arg string) string {
+		name := strings.TrimPrefix(arg, "*")
+		return strings.TrimSuffix(arg, name) + path.Base(name)
+	})
+	return elem.Name + "[" + strings.Join(args, ",") + "]"
+}
+
+// typeArgList function    返回生成代码中的类型实参列表，如 [model.User]，非泛型组件返回空字符串
+// 只用于类型引用（如 wire.Bind 的实现类型），提供者使用 applyTypeArgs 生成的包装构造函数.
+func typeArgList(elem *Element, refs *interfaceRefs) string {
+	if len(elem.TypeArgs) == 0 {
+		return ""
+	}
+	args := parser.Map(elem.TypeArgs, func(arg string) string {
+		name := strings.TrimPrefix(arg, "*")
+		if strings.Contains(name, ".") {
+			return strings.TrimSuffix(arg, name) + refs.ref(name)
+		}
+		return arg
+	})
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
+// checkTypeArgs method    检查泛型组件是否通过 of= 指定了与类型参数个数一致的类型实参，并生成了包装构造函数
+// 未实例化的泛型与 wire 不支持的泛型提供者在生成之前给出带源码位置的错误.
+func (sc *AutoWireSearcher) checkTypeArgs() error {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if len(elem.TypeArgs) == elem.TypeParams && (elem.TypeParams == 0 ||
+				len(elem.Wrappers) > 0 || len(elem.RawExpr) > 0) {
+				continue
+			}
+			reason := fmt.Sprintf("非泛型组件不能指定类型实参: %s", describeElement(elem))
+			switch {
+			case len(elem.TypeArgs) == elem.TypeParams:
+				reason = fmt.Sprintf("wire 不支持泛型提供者，只能为泛型结构体或返回值的泛型构造函数生成包装构造函数: %s",
+					describeElement(elem))
+			case elem.TypeParams > 0:
+				reason = fmt.Sprintf("泛型组件需要通过 of= 指定 %d 个类型实参（多个以 ; 分隔），实际为 %d 个: %s",
+					elem.TypeParams, len(elem.TypeArgs), describeElement(elem))
+			}
+			return errors.NewInvalidAnnotationError("@autowire(of=...)", reason)
+		}
+	}
+	return nil
+}
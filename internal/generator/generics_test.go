@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const genericsSrc = `package repo
+
+import "example.com/model"
+
+type DB struct{}
+
+// @autowire(set=repo,of=model.User)
+// @autowire(set=repo,of=*model.Order)
+type Repo[T any] struct {
+	DB   *DB
+	Item T
+}
+
+// @autowire(set=repo,of=int;string)
+func NewCache[K comparable, V any](db *DB) *Cache[K, V] { return nil }
+
+type Cache[K comparable, V any] struct{}
+`
+
+func TestGenericElements(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "repo.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, genericsSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+	}
+	sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/repo", f, getImplement(f))
+
+	elements := sc.ElementMap["repo"]
+	keys := slices.Sorted(maps.Keys(elements))
+	want := []string{"example.com/repo/NewCache[int,string]", "example.com/repo/Repo[*model.Order]",
+		"example.com/repo/Repo[model.User]"}
+	if !slices.Equal(keys, want) {
+		t.Fatalf("ElementMap keys = %v, want %v", keys, want)
+	}
+	if deps := elements["example.com/repo/Repo[model.User]"].Deps; !slices.Equal(deps,
+		[]string{"example.com/repo.DB", "example.com/model.User"}) {
+		t.Errorf("Repo[model.User].Deps = %v", deps)
+	}
+	if err := sc.checkTypeArgs(); err != nil {
+		t.Fatalf("checkTypeArgs() error = %v", err)
+	}
+
+	data, imports := sc.generateWireConfig("RepoSet", elements, keys)
+	out := strings.Join(data.Items, "\n")
+	for _, item := range []string{"repo.ProvideNewCacheIntString", "repo.ProvideRepoPtrOrder", "repo.ProvideRepoUser"} {
+		if !strings.Contains(out, item) {
+			t.Errorf("items missing %q:\n%s", item, out)
+		}
+	}
+	if slices.ContainsFunc(imports, func(imp *ast.ImportSpec) bool { return imp.Path.Value == `"example.com/model"` }) {
+		t.Errorf("Set 文件引用包装构造函数，不需要导入 example.com/model")
+	}
+
+	// wire 不支持泛型提供者，组件包中生成非泛型的包装构造函数
+	for key, want := range map[string]string{
+		"example.com/repo/Repo[model.User]": "func ProvideRepoUser(p0 *DB, p1 model.User) *Repo[model.User] {\n" +
+			"\treturn &Repo[model.User]{DB: p0, Item: p1}\n}",
+		"example.com/repo/Repo[*model.Order]": "func ProvideRepoPtrOrder(p0 *DB, p1 *model.Order) " +
+			"*Repo[*model.Order] {\n\treturn &Repo[*model.Order]{DB: p0, Item: p1}\n}",
+		"example.com/repo/NewCache[int,string]": "func ProvideNewCacheIntString(p0 *DB) *Cache[int, string] {\n" +
+			"\treturn NewCache[int, string](p0)\n}",
+	} {
+		if wrappers := elements[key].Wrappers; len(wrappers) != 1 || !strings.HasSuffix(wrappers[0], want) {
+			t.Errorf("%s.Wrappers = %q, want suffix %q", key, wrappers, want)
+		}
+	}
+
+	// 未指定 of= 的泛型组件在生成之前报错
+	elem := elements["example.com/repo/Repo[model.User]"]
+	elem.TypeArgs = nil
+	elements["example.com/repo/Repo[model.User]"] = elem
+	if err := sc.checkTypeArgs(); err == nil || !strings.Contains(err.Error(), "of=") {
+		t.Errorf("checkTypeArgs() error = %v, want of= error", err)
+	}
+}
+
+func TestGenerics_Wire(t *testing.T) {
+	dir := runWireModule(t, map[string]string{
+		"model/model.go": "package model\n\ntype User struct{}\n\ntype Order struct{}\n",
+		"repo/repo.go": `package repo
+
+import "example.com/app/model"
+
+// @autowire(set=repo)
+type DB struct{}
+
+// @autowire(set=repo,of=model.User)
+// @autowire(set=repo,of=*model.Order)
+type Repo[T any] struct {
+	DB *DB
+}
+
+// Store 由 Cache 实现.
+type Store interface{ Len() int }
+
+// @autowire(set=repo,of=string;model.User,Store)
+func NewCache[K comparable, V any](db *DB) (*Cache[K, V], error) { return &Cache[K, V]{}, nil }
+
+type Cache[K comparable, V any] struct{ items map[K]V }
+
+func (c *Cache[K, V]) Len() int { return len(c.items) }
+
+// @autowire(set=repo)
+type Service struct {
+	Users  *Repo[model.User]
+	Orders *Repo[*model.Order]
+	Store  Store
+}
+`,
+		"wire/inject.go": `//go:build wireinject
+
+package wire
+
+import (
+	"example.com/app/repo"
+	"github.com/google/wire"
+)
+
+func InitService() (*repo.Service, error) {
+	panic(wire.Build(RepoSet))
+}
+`,
+	})
+	factory, err := os.ReadFile(filepath.Join(dir, "repo", "autowire_factory.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []string{
+		"func ProvideRepoUser(", "func ProvideRepoPtrOrder(", "func ProvideNewCacheStringUser(",
+	} {
+		if !strings.Contains(string(factory), fn) {
+			t.Errorf("autowire_factory.go 缺少 %s:\n%s", fn, factory)
+		}
+	}
+}
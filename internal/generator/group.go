@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// resolveGroup method    处理 group= 参数：成员绑定的唯一接口作为分组切片的元素类型，成员不再单独绑定该接口
+// 成员没有绑定接口或绑定了多个接口时 GroupType 为空，校验时报告错误.
+func (sc *AutoWireSearcher) resolveGroup(wireElement *Element, f *ast.File, pkgPath string) {
+	if wireElement.Group == "" {
+		return
+	}
+	if wireElement.ConfigWire || wireElement.ValueWire || wireElement.Mock || wireElement.InitWire ||
+		wireElement.TypeParams > 0 || wireElement.Qualifier != "" || wireElement.Scope == scopeFactory {
+		sc.logger.Warn("group 参数只对单例、非泛型、没有限定名的结构体或构造函数有效，已忽略",
+			"element", describeElement(*wireElement))
+		wireElement.Group = ""
+		return
+	}
+	if len(wireElement.Implements) != 1 {
+		return
+	}
+
+	itf := wireElement.Implements[0]
+	wireElement.GroupType = typeResolver{file: f, pkgPath: pkgPath}.qualifyName(itf)
+	wireElement.Implements = nil
+	delete(wireElement.BindKinds, itf)
+	wireElement.Provides = slices.DeleteFunc(wireElement.Provides, func(t string) bool {
+		return t == wireElement.GroupType
+	})
+}
+
+// removeGroupProviders method    移除上次校验时添加的汇总提供者，分组成员变化后重新添加.
+func (sc *AutoWireSearcher) removeGroupProviders() {
+	for _, elements := range sc.ElementMap {
+		maps.DeleteFunc(elements, func(_ string, elem Element) bool { return elem.GroupProvider })
+	}
+}
+
+// resolveGroups method    为每个分组添加汇总提供者 New<Group>Group：以各成员为参数，返回成员组成的切片
+// 汇总提供者加入成员所在的第一个 Set（按名称排序）.
+func (sc *AutoWireSearcher) resolveGroups() error {
+	members := make(map[string][]Element)
+	seen := parser.NewSet[string]()
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		if set == mockSet {
+			continue
+		}
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			// 注册到多个 Set 的成员只加入一次
+			if elem := sc.ElementMap[set][key]; elem.Group != "" && !seen.Contains(key) {
+				seen.Add(key)
+				members[elem.Group] = append(members[elem.Group], elem)
+			}
+		}
+	}
+
+	pkgPath := sc.getPkgPath(filepath.Join(sc.genPath, sc.genFileName(groupFile)))
+	groupTypes := make(map[string]string) // 元素类型 -> 分组
+	for _, group := range parser.SortedKeys(members) {
+		list := members[group]
+		slices.SortFunc(list, compareGroupMembers)
+		for _, m := range list {
+			switch {
+			case m.GroupType == "":
+				return groupError(group, "分组成员需要且只能绑定一个接口，作为切片的元素类型: "+describeElement(m))
+			case m.GroupType != list[0].GroupType:
+				return groupError(group, fmt.Sprintf("分组成员绑定的接口不同: %s 绑定 %s，%s 绑定 %s",
+					describeElement(list[0]), list[0].GroupType, describeElement(m), m.GroupType))
+			case m.Tag != "" || m.Out != "":
+				return groupError(group, "分组成员不支持构建标签与输出目录: "+describeElement(m))
+			}
+		}
+		if prev, ok := groupTypes[list[0].GroupType]; ok {
+			return groupError(group, fmt.Sprintf("分组 %s 与 %s 的元素类型相同: %s", prev, group, list[0].GroupType))
+		}
+		groupTypes[list[0].GroupType] = group
+
+		provider := Element{
+			Name:          strcase.UpperCamelCase(group) + "Group",
+			Set:           slices.MinFunc(list, func(a, b Element) int { return strings.Compare(a.Set, b.Set) }).Set,
+			Pkg:           sc.pkg,
+			PkgPath:       pkgPath,
+			FuncDecl:      true,
+			Group:         group,
+			GroupType:     list[0].GroupType,
+			GroupProvider: true,
+			Provides:      []string{"[]" + list[0].GroupType},
+		}
+		provider.Constructor = "New" + provider.Name
+		for _, m := range list {
+			if len(m.Provides) > 0 {
+				provider.Deps = append(provider.Deps, m.Provides[0])
+			}
+		}
+		sc.ElementMap[provider.Set][path.Join(pkgPath, provider.Name)] = provider
+	}
+	return nil
+}
+
+// compareGroupMembers function    分组成员在切片中的顺序：priority 大的在前，相同时按名称、包路径排序.
+func compareGroupMembers(a, b Element) int {
+	if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+		return c
+	}
+	return compareElements(a, b)
+}
+
+// groupError function    返回分组注解的错误.
+func groupError(group, reason string) error {
+	return errors.NewInvalidAnnotationError("group="+group, reason)
+}
+
+// groupProviders method    返回生成到当前生成路径的汇总提供者，按分组名称排序.
+func (sc *AutoWireSearcher) groupProviders() []Element {
+	pkgPath := sc.getPkgPath(filepath.Join(sc.genPath, sc.genFileName(groupFile)))
+	var providers []Element
+	for _, elements := range sc.ElementMap {
+		for _, elem := range elements {
+			if elem.GroupProvider && elem.PkgPath == pkgPath {
+				providers = append(providers, elem)
+			}
+		}
+	}
+	slices.SortFunc(providers, func(a, b Element) int { return strings.Compare(a.Group, b.Group) })
+	return providers
+}
+
+// groupMembers method    返回分组的成员，按在切片中的顺序排列.
+func (sc *AutoWireSearcher) groupMembers(group string) []Element {
+	var members []Element
+	seen := parser.NewSet[string]()
+	for set, elements := range sc.ElementMap {
+		if set == mockSet {
+			continue
+		}
+		for key, elem := range elements {
+			if elem.Group == group && !elem.GroupProvider && !seen.Contains(key) {
+				seen.Add(key)
+				members = append(members, elem)
+			}
+		}
+	}
+	slices.SortFunc(members, compareGroupMembers)
+	return members
+}
+
+// groupData struct    分组文件的模板数据.
+type groupData struct {
+	Package string      // 包名
+	Imports []string    // 导入，如 web "example.com/app/web"
+	Groups  []groupFunc // 各分组的汇总函数
+}
+
+// groupFunc struct    单个分组的汇总函数.
+type groupFunc struct {
+	Name   string   // 函数名，如 NewRoutesGroup
+	Group  string   // 分组名称
+	Type   string   // 切片的元素类型，如 http.Route
+	Params []string // 各成员参数，按切片中的顺序排列
+	Args   []string // 切片的元素
+}
+
+// writeGroupsFile method    生成 autowire_groups.go：每个分组的汇总函数以各成员为参数，返回成员组成的切片.
+func (sc *AutoWireSearcher) writeGroupsFile() error {
+	providers := sc.groupProviders()
+	if len(providers) == 0 {
+		return nil
+	}
+	fileName := filepath.Join(sc.genPath, sc.genFileName(groupFile))
+	pathPkg := sc.getPkgPath(fileName)
+
+	// 全部分组的成员一起处理包名冲突
+	var groups [][]string
+	elements := make(map[string]Element)
+	var order []string
+	for _, p := range providers {
+		var keys []string
+		for _, m := range sc.groupMembers(p.Group) {
+			key := fmt.Sprintf("%03d", len(order))
+			elements[key] = m
+			order = append(order, key)
+			keys = append(keys, key)
+		}
+		groups = append(groups, keys)
+	}
+	sc.resolvePackageConflicts(elements, make(map[string]map[string]string), order)
+	refs := newInterfaceRefs(pathPkg, elements)
+
+	data := groupData{Package: sc.pkg}
+	var importPkgs []*ast.ImportSpec
+	for i, p := range providers {
+		fn := groupFunc{Name: p.Constructor, Group: p.Group, Type: refs.ref(p.GroupType)}
+		for j, key := range groups[i] {
+			elem := elements[key]
+			if elem.PkgPath == pathPkg {
+				elem.Pkg = ""
+			} else {
+				importPkgs = append(importPkgs, sc.createImportSpec(&elem))
+			}
+			param := fmt.Sprintf("m%d", j)
+			fn.Params = append(fn.Params, param+" "+defaultBindImpl(&elem, parser.AppendPkg(elem.Pkg, elem.Name)))
+			fn.Args = append(fn.Args, param)
+		}
+		data.Groups = append(data.Groups, fn)
+	}
+	// 导入写在模板中：模板只有函数声明，无法像 Set 文件一样追加到已有的 import 声明
+	for _, imp := range sortImports(append(importPkgs, refs.imports...)) {
+		if imp.Name != nil {
+			data.Imports = append(data.Imports, imp.Name.Name+" "+imp.Path.Value)
+		} else {
+			data.Imports = append(data.Imports, imp.Path.Value)
+		}
+	}
+	return sc.writeTemplateFile(fileName, GroupTemp, data, nil)
+}
+
+// GroupTemp 预编译的分组模板.
+var GroupTemp = template.Must(template.New("").Parse(strings.TrimLeft(groupTemplate, "\n")))
+
+// groupTemplate 分组文件的代码生成模板.
+var groupTemplate = `
+// Code generated by go-autowire. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{ range .Groups }}
+// {{ .Name }} 返回分组 {{ .Group }} 的全部成员，按 priority 从大到小、名称排序.
+func {{ .Name }}({{ range .Params }}{{ . }}, {{ end }}) []{{ .Type }} {
+	return []{{ .Type }}{ {{- range .Args }}{{ . }}, {{ end -}} }
+}
+{{ end }}`
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestGroups(t *testing.T) {
+	src := "package web\n\n" +
+		"// Route 路由.\ntype Route interface{ Path() string }\n\n" +
+		"// @autowire(Route, set=http, group=routes)\ntype UserRoute struct{}\n\n" +
+		"// @autowire(Route, set=http, group=routes, priority=1)\ntype OrderRoute struct{}\n\n" +
+		"func NewOrderRoute() *OrderRoute { return nil }\n\n" +
+		"// @autowire.init(set=server)\ntype Server struct {\n\tRoutes []Route\n}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "web.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard(), genPath: dir, pkg: "wire", cache: NewCacheManager(dir, false),
+		initWire: []string{"*"}}
+	for _, e := range sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "web.go", "example.com/web", f,
+		getImplement(f)) {
+		if e.Group != "" && (e.GroupType != "example.com/web.Route" || len(e.Implements) != 0) {
+			t.Errorf("%s: GroupType = %q, Implements = %v", e.Name, e.GroupType, e.Implements)
+		}
+	}
+
+	// 汇总提供者加入成员所在的 Set，按 priority 排列成员，重复校验不会重复添加
+	for range 2 {
+		if err := sc.Prepare(); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+	}
+	providers := sc.groupProviders()
+	if len(providers) != 1 {
+		t.Fatalf("groupProviders() = %v", providers)
+	}
+	p := providers[0]
+	if p.Constructor != "NewRoutesGroup" || p.Set != "http" ||
+		!slices.Equal(p.Provides, []string{"[]example.com/web.Route"}) || !slices.Equal(p.Deps, []string{"example.com/web.OrderRoute", "example.com/web.UserRoute"}) {
+		t.Errorf("汇总提供者 = %+v", p)
+	}
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("MissingProviders() = %v", errs)
+	}
+
+	if err := sc.writeGroupsFile(); err != nil {
+		t.Fatalf("writeGroupsFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sc.genFileName(groupFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func NewRoutesGroup(m0 *web.OrderRoute, m1 *web.UserRoute) []web.Route {\n\treturn []web.Route{m0, m1}\n}"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("autowire_groups.go 缺少 %q:\n%s", want, data)
+	}
+}
+
+func TestGroups_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []Element
+		want    string
+	}{
+		{
+			name:    "没有绑定接口",
+			members: []Element{{Name: "A", Set: "s", Group: "g"}},
+			want:    "只能绑定一个接口",
+		},
+		{
+			name: "接口不同",
+			members: []Element{
+				{Name: "A", Set: "s", Group: "g", GroupType: "x.I"},
+				{Name: "B", Set: "s", Group: "g", GroupType: "x.J"},
+			},
+			want: "绑定的接口不同",
+		},
+		{
+			name: "元素类型相同",
+			members: []Element{
+				{Name: "A", Set: "s", Group: "g", GroupType: "x.I"},
+				{Name: "B", Set: "s", Group: "h", GroupType: "x.I"},
+			},
+			want: "元素类型相同",
+		},
+		{
+			name:    "构建标签",
+			members: []Element{{Name: "A", Set: "s", Group: "g", GroupType: "x.I", Tag: "prod"}},
+			want:    "不支持构建标签",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: map[string]map[string]Element{"s": {}},
+				logger: logger.Discard()}
+			for _, m := range tt.members {
+				sc.ElementMap["s"][m.Name] = m
+			}
+			err := sc.resolveGroups()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("resolveGroups() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
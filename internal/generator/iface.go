@@ -0,0 +1,272 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// collectInterface method    记录带 @autowire 注解的接口声明，扫描结束后为其查找实现.
+func (sc *AutoWireSearcher) collectInterface(decl *tmpDecl, f *ast.File, pkgPath string) *Element {
+	it, ok := decl.typeSpec.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil
+	}
+
+	r := typeResolver{file: f, pkgPath: pkgPath}
+	elem := Element{
+		Name:      decl.name,
+		Pkg:       f.Name.Name,
+		PkgPath:   pkgPath,
+		Position:  decl.pos,
+		Interface: true,
+	}
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			// 嵌入的接口无法仅凭语法树展开
+			sc.logger.Warn("@autowire 接口中的嵌入接口不参与实现匹配",
+				"iface", parser.AppendPkg(elem.Pkg, elem.Name), "embedded", r.typeKey(m.Type))
+			continue
+		}
+		elem.Methods = append(elem.Methods, r.methodSignature(m.Names[0].Name, ft))
+	}
+	slices.Sort(elem.Methods)
+
+	sc.addInterface(elem)
+	return &elem
+}
+
+// addInterface method    并发安全地记录注解接口.
+func (sc *AutoWireSearcher) addInterface(elem Element) {
+	sc.logger.Debug("收集到 wire 接口", "iface", elem.Pkg+"."+elem.Name)
+	if sc.streaming {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.interfaces = append(sc.interfaces, elem)
+}
+
+// bindAnnotatedInterfaces method    为每个注解接口查找实现了其全部方法的组件，并自动添加 wire.Bind
+// 只有同样带 @autowire 注解的结构体组件或返回本包类型的构造函数才会作为候选实现.
+func (sc *AutoWireSearcher) bindAnnotatedInterfaces() {
+	if len(sc.interfaces) == 0 {
+		return
+	}
+	slices.SortFunc(sc.interfaces, func(a, b Element) int {
+		return strings.Compare(a.PkgPath+"."+a.Name, b.PkgPath+"."+b.Name)
+	})
+
+	methodSets := make(map[string]map[string][]string) // 目录 -> 类型名 -> 方法签名
+	for _, itf := range sc.interfaces {
+		id := itf.PkgPath + "." + itf.Name
+		found := 0
+		for _, set := range parser.SortedKeys(sc.ElementMap) {
+			for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+				elem := sc.ElementMap[set][key]
+				// 函数声明按返回值中的本包类型匹配
+				name := elem.Name
+				if elem.FuncDecl {
+					name = resultTypeName(elem.Result)
+				}
+				if name == "" || elem.ConfigWire || elem.ValueWire || elem.Qualifier != "" || elem.Scope != "" ||
+					!elem.Position.IsValid() {
+					continue
+				}
+				dir := filepath.Dir(elem.Position.Filename)
+				if _, ok := methodSets[dir]; !ok {
+					methodSets[dir] = sc.packageMethodSets(dir)
+				}
+				if !implementsAll(methodSets[dir][name], itf.Methods) {
+					continue
+				}
+				found++
+				if slices.ContainsFunc(elem.Implements, func(s string) bool { return qualifiedInterface(elem, s) == id }) {
+					continue
+				}
+				ref := id
+				if elem.PkgPath == itf.PkgPath {
+					ref = itf.Name
+				}
+				elem.Implements = append(elem.Implements, ref)
+				elem.Provides = appendUnique(elem.Provides, id)
+				sc.ElementMap[set][key] = elem
+				sc.logger.Info("自动绑定接口实现", "iface", itf.Pkg+"."+itf.Name, "element", describeElement(elem))
+			}
+		}
+		if found == 0 {
+			sc.logger.Warn("未找到 @autowire 接口的实现", "iface", describeElement(itf))
+		}
+	}
+}
+
+// packageMethodSets method    返回目录下满足构建约束的非测试 Go 文件中 类型名 -> 方法签名 列表
+// 值接收者与指针接收者的方法都计入（绑定时使用指针类型）.
+func (sc *AutoWireSearcher) packageMethodSets(dir string) map[string][]string {
+	info := sc.packageInfo(dir)
+	if len(info.files) == 0 {
+		return map[string][]string{}
+	}
+	return methodSets(info.syntax, sc.getPkgPath(info.files[0]))
+}
+
+// methodSets function    返回语法树中 类型名 -> 方法签名 列表，pkgPath 为文件所在包的路径.
+func methodSets(files []*ast.File, pkgPath string) map[string][]string {
+	result := make(map[string][]string)
+	for _, f := range files {
+		r := typeResolver{file: f, pkgPath: pkgPath}
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || len(fd.Recv.List) == 0 {
+				continue
+			}
+			recv := receiverName(fd.Recv.List[0].Type)
+			result[recv] = append(result[recv], r.methodSignature(fd.Name.Name, fd.Type))
+		}
+	}
+	return result
+}
+
+// receiverName function    返回方法接收者的类型名，忽略指针与类型参数.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// implementsAll function    判断方法集合是否包含接口的全部方法.
+func implementsAll(methods, required []string) bool {
+	if len(required) == 0 {
+		return false
+	}
+	for _, m := range required {
+		if !slices.Contains(methods, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// methodSignature method    返回方法签名的规范形式，如 Get(string)(example.com/db.User,error)
+// 参数与返回值的类型均转换为完整形式，保证跨包比较.
+func (r typeResolver) methodSignature(name string, ft *ast.FuncType) string {
+	params := strings.Join(r.fieldListExprs(ft.Params), ",")
+	results := strings.Join(r.fieldListExprs(ft.Results), ",")
+	return fmt.Sprintf("%s(%s)(%s)", name, params, results)
+}
+
+// fieldListExprs method    解析参数列表中的类型，保留指针、切片等修饰.
+func (r typeResolver) fieldListExprs(fl *ast.FieldList) []string {
+	if fl == nil {
+		return nil
+	}
+	var result []string
+	for _, field := range fl.List {
+		t := r.typeExpr(field.Type)
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for range n {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// typeExpr method    将类型表达式转换为完整形式，与 typeKey 不同的是保留 *、[]、map 等修饰.
+func (r typeResolver) typeExpr(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + r.typeExpr(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + r.typeExpr(t.Elt)
+		}
+		return "[" + types.ExprString(t.Len) + "]" + r.typeExpr(t.Elt)
+	case *ast.MapType:
+		return "map[" + r.typeExpr(t.Key) + "]" + r.typeExpr(t.Value)
+	case *ast.Ellipsis:
+		return "..." + r.typeExpr(t.Elt)
+	case *ast.ChanType:
+		return types.ExprString(&ast.ChanType{Dir: t.Dir, Value: ast.NewIdent(r.typeExpr(t.Value))})
+	case *ast.Ident, *ast.SelectorExpr:
+		return r.typeKey(t)
+	}
+	return types.ExprString(expr)
+}
+
+// externalInterface function    将注解中的 包名.接口名 转换为生成代码可以导入的形式
+// 能在源文件的导入中找到包时返回完整路径形式，如 example.com/bar/v2.Store，生成时与组件所在的包一起处理包名冲突；
+// 其他形式（本包接口、单段路径的标准库接口、已是完整路径）保持不变.
+func externalInterface(f *ast.File, name string) string {
+	pkg, sel, ok := strings.Cut(name, ".")
+	if !ok || strings.Contains(sel, ".") || strings.Contains(pkg, "/") {
+		return name
+	}
+	p := typeResolver{file: f}.importPath(pkg)
+	if p == pkg {
+		return name
+	}
+	return p + "." + sel
+}
+
+// interfaceRefs struct    生成单个 Set 文件时，完整路径形式接口的引用与导入.
+type interfaceRefs struct {
+	pathPkg string            // 生成文件所在包的导入路径
+	aliases map[string]string // 包路径 -> 生成文件中使用的包名
+	imports []*ast.ImportSpec // 需要额外添加的导入
+}
+
+// newInterfaceRefs function    根据 Set 中已处理包名冲突的元素创建接口引用表.
+func newInterfaceRefs(pathPkg string, elements map[string]Element) *interfaceRefs {
+	refs := &interfaceRefs{pathPkg: pathPkg, aliases: make(map[string]string)}
+	for _, elem := range elements {
+		// 没有绑定接口的自定义提供者不导入所在的包，需要时由表达式中的引用导入
+		if !rawOnly(&elem) {
+			refs.aliases[elem.PkgPath] = elem.Pkg
+		}
+	}
+	refs.aliases[pathPkg] = ""
+	return refs
+}
+
+// ref method    将 example.com/repo.Store 转换为生成代码中的引用，如 repo.Store
+// 接口所在包未被 Set 中的元素导入时添加导入，包名冲突时追加数字后缀.
+func (r *interfaceRefs) ref(itf string) string {
+	idx := strings.LastIndex(itf, ".")
+	pkgPath, name := itf[:idx], itf[idx+1:]
+	if alias, ok := r.aliases[pkgPath]; ok {
+		return parser.AppendPkg(alias, name)
+	}
+
+	base := strings.ReplaceAll(path.Base(versionSuffix.ReplaceAllString(pkgPath, "")), "-", "_")
+	alias := base
+	for i := 2; slices.Contains(slices.Collect(maps.Values(r.aliases)), alias); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	r.aliases[pkgPath] = alias
+	r.imports = append(r.imports, &ast.ImportSpec{
+		Name: ast.NewIdent(alias),
+		Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(pkgPath)},
+	})
+	return parser.AppendPkg(alias, name)
+}
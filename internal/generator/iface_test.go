@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestBindAnnotatedInterfaces(t *testing.T) {
+	dir := t.TempDir()
+	implSrc := `package store
+
+import "context"
+
+type MySQL struct{}
+
+func (m *MySQL) Get(ctx context.Context, id int64) ([]byte, error) { return nil, nil }
+func (m MySQL) Close() error { return nil }
+
+type Other struct{}
+
+func (o *Other) Get(ctx context.Context, id string) ([]byte, error) { return nil, nil }
+`
+	implFile := filepath.Join(dir, "store.go")
+	if err := os.WriteFile(implFile, []byte(implSrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ifaceSrc := `package repo
+
+import "context"
+
+type Store interface {
+	Get(context.Context, int64) ([]byte, error)
+	Close() error
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "repo.go", ifaceSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeSpec := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+
+	sc := &AutoWireSearcher{
+		mu:        &sync.Mutex{},
+		logger:    logger.Discard(),
+		splitSets: parser.NewSet[string](),
+		ElementMap: map[string]map[string]Element{
+			"store": {
+				"example.com/store/MySQL": {Name: "MySQL", Pkg: "store", PkgPath: "example.com/store",
+					Position: token.Position{Filename: implFile, Line: 5}},
+				"example.com/store/Other": {Name: "Other", Pkg: "store", PkgPath: "example.com/store",
+					Position: token.Position{Filename: implFile, Line: 10}},
+			},
+		},
+	}
+	if elem := sc.collectInterface(&tmpDecl{name: "Store", typeSpec: typeSpec}, f, "example.com/repo"); elem == nil {
+		t.Fatal("collectInterface() 应该返回接口元素")
+	}
+	sc.bindAnnotatedInterfaces()
+
+	store := sc.ElementMap["store"]
+	if got := store["example.com/store/MySQL"].Implements; len(got) != 1 || got[0] != "example.com/repo.Store" {
+		t.Errorf("MySQL 应该自动绑定 repo.Store, got %v", got)
+	}
+	if got := store["example.com/store/Other"].Implements; len(got) != 0 {
+		t.Errorf("Other 的方法签名不匹配，不应绑定, got %v", got)
+	}
+}
+
+func TestExternalInterface(t *testing.T) {
+	src := "package svc\n\nimport (\n\t\"io\"\n\tstore \"example.com/db/v2\"\n\t\"example.com/kit/log/v3\"\n" +
+		"\tbar \"example.com/bar\"\n)\n"
+	f, err := goparser.ParseFile(token.NewFileSet(), "svc.go", src, goparser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Store", "Store"},
+		{"io.Writer", "io.Writer"},
+		{"bar.Repo", "example.com/bar.Repo"},
+		{"store.Store", "example.com/db/v2.Store"},
+		{"log.Logger", "example.com/kit/log/v3.Logger"},
+		{"example.com/x.Y", "example.com/x.Y"},
+	}
+	for _, tt := range tests {
+		if got := externalInterface(f, tt.name); got != tt.want {
+			t.Errorf("externalInterface(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInterfaceRefs(t *testing.T) {
+	refs := newInterfaceRefs("example.com/app/wire", map[string]Element{
+		"example.com/store/MySQL": {Name: "MySQL", Pkg: "store", PkgPath: "example.com/store"},
+		"example.com/a/repo/Repo": {Name: "Repo", Pkg: "repo", PkgPath: "example.com/a/repo"},
+	})
+
+	tests := []struct {
+		itf  string
+		want string
+	}{
+		{"example.com/store.Store", "store.Store"},
+		{"example.com/app/wire.Provider", "Provider"},
+		{"example.com/b/repo.Repo", "repo2.Repo"},
+		{"example.com/go-kit/log/v2.Logger", "log.Logger"},
+	}
+	for _, tt := range tests {
+		if got := refs.ref(tt.itf); got != tt.want {
+			t.Errorf("ref(%q) = %q, want %q", tt.itf, got, tt.want)
+		}
+	}
+
+	var paths []string
+	for _, imp := range refs.imports {
+		paths = append(paths, imp.Name.Name+" "+imp.Path.Value)
+	}
+	if got := strings.Join(paths, ";"); got != `repo2 "example.com/b/repo";log "example.com/go-kit/log/v2"` {
+		t.Errorf("imports = %s", got)
+	}
+}
+
+func TestInterfaceImportAliases(t *testing.T) {
+	src := `package svc
+
+import (
+	"example.com/a/store"
+	bstore "example.com/b/store"
+)
+
+// @autowire(set=svc,store.Store,bstore.Store)
+type Impl struct{}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "svc.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f, getImplement(f))
+	impl := elements[0]
+
+	// Set 中另一个组件所在的包同样名为 store，接口所在的包依次追加数字后缀
+	refs := newInterfaceRefs("example.com/wire", map[string]Element{
+		"example.com/c/store/Mem": {Name: "Mem", Pkg: "store", PkgPath: "example.com/c/store"},
+		"example.com/svc/Impl":    impl,
+	})
+	var items []string
+	sc.handleNormalWireElement(&impl, &items, "svc.Impl", refs)
+	want := "wire.Struct(new(svc.Impl), \"*\");wire.Bind(new(store2.Store), new(*svc.Impl));" +
+		"wire.Bind(new(store3.Store), new(*svc.Impl))"
+	if got := strings.Join(items, ";"); got != want {
+		t.Errorf("items = %s, want %s", got, want)
+	}
+	var paths []string
+	for _, imp := range refs.imports {
+		paths = append(paths, imp.Name.Name+" "+imp.Path.Value)
+	}
+	if got := strings.Join(paths, ";"); got != `store2 "example.com/b/store";store3 "example.com/a/store"` {
+		t.Errorf("imports = %s", got)
+	}
+}
@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// setFileName method    返回 Set 对应的生成文件路径，如 animals 返回 <genPath>/autowire_animals.go.
+func (sc *AutoWireSearcher) setFileName(set string) string {
+	return filepath.Join(sc.genPath, sc.genFileName(strcase.SnakeCase(set)))
+}
+
+// expectedFiles method    返回本次生成会产生的 autowire_*.go 文件名，用于清理过期文件.
+func (sc *AutoWireSearcher) expectedFiles() []string {
+	var files []string
+	hasAggregate := false
+	for set, elements := range sc.ElementMap {
+		files = append(files, filepath.Base(sc.setFileName(set)))
+		if tags := elementTags(elements); len(tags) > 0 {
+			for _, tag := range append(tags, defaultTagFile) {
+				files = append(files, filepath.Base(sc.setFileName(set+"_"+tag)))
+			}
+		}
+		if !sc.splitSets.Contains(set) && sc.setBuildTags[set] == "" && !isInjectorSet(elements) {
+			hasAggregate = true
+		}
+	}
+	if hasAggregate {
+		files = append(files, sc.genFileName(setsFile))
+	}
+	if len(sc.sharedElements()) > 0 {
+		files = append(files, filepath.Base(sc.sharedFileName()))
+	}
+	for _, file := range sc.compositeFiles() {
+		files = append(files, filepath.Base(file))
+	}
+	if sc.hasLifecycleFile() {
+		files = append(files, sc.genFileName(lifecycleFile))
+	}
+	if len(sc.groupProviders()) > 0 {
+		files = append(files, sc.genFileName(groupFile))
+	}
+	return files
+}
+
+// isExpectedFile function    判断文件名是否在列表中（大小写不敏感的文件系统上忽略大小写）.
+func isExpectedFile(expected []string, name string) bool {
+	return slices.ContainsFunc(expected, func(e string) bool {
+		return parser.NameEqual(e, name)
+	})
+}
+
+// writeIfChanged method    生成文件的输入与上次生成时一致且文件仍存在时跳过写入
+// 输入为格式化与 goimports 处理之前的内容，跳过时可以省去最耗时的 import 处理.
+func (sc *AutoWireSearcher) writeIfChanged(fileName string, input []byte, write func() error) error {
+	if err := sc.ctxErr(); err != nil {
+		return err
+	}
+	sc.produced.add(absPath(fileName))
+	// 检查模式下总是与磁盘上的文件比较，不信任指纹（文件可能被手动修改）
+	if sc.pending != nil {
+		return write()
+	}
+	// 头部注释不在模板输入中，一起计入指纹，修改头部注释模板后重新写入
+	header, err := sc.header(fileName)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(append(header, input...))
+	fingerprint := hex.EncodeToString(sum[:])
+	if sc.cache.OutputUnchanged(fileName, fingerprint) {
+		sc.logger.Debug("内容未变化，跳过生成", logger.EventKey, logger.EventFileUnchanged, "file", fileName)
+		return nil
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	sc.written.add(absPath(fileName))
+	sc.logger.Debug("文件已写入", logger.EventKey, logger.EventFileWritten, "file", fileName)
+	sc.cache.SetOutput(fileName, fingerprint)
+	return nil
+}
+
+// pruneStale method    删除上次生成、本次不再生成的文件（如注解被移除的 Set、不再使用的输出目录中的文件）
+// 上次生成的文件列表记录在缓存中，只删除带 go-autowire 生成标记的文件.
+func (sc *AutoWireSearcher) pruneStale() {
+	produced := sc.produced.sorted()
+	for _, fileName := range sc.cache.Generated() {
+		if !slices.Contains(produced, fileName) {
+			sc.removeGeneratedFile(fileName)
+			if sc.pending == nil {
+				// 不再使用的输出目录变为空目录时一并删除，非空目录删除失败时忽略
+				_ = os.Remove(filepath.Dir(fileName))
+			}
+		}
+	}
+	sc.cache.SetGenerated(produced)
+}
+
+// importsInput function    将导入列表转换为指纹输入.
+func importsInput(specs []string) []byte {
+	slices.Sort(specs)
+	return []byte(strings.Join(specs, "\n"))
+}
+
+// recordFile method    记录源文件解析出的组件（含注解接口）与注解语法问题，供 Rescan 重建 ElementMap.
+func (sc *AutoWireSearcher) recordFile(file string, elements []Element, diags []Diagnostic) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.fileElements == nil {
+		sc.fileElements = make(map[string][]Element)
+	}
+	if sc.fileDiagnostics == nil {
+		sc.fileDiagnostics = make(map[string][]Diagnostic)
+	}
+	sc.fileElements[absPath(file)] = elements
+	if len(diags) > 0 {
+		sc.fileDiagnostics[absPath(file)] = diags
+	} else {
+		delete(sc.fileDiagnostics, absPath(file))
+	}
+}
+
+// Rescan method    增量更新扫描结果：只重新解析变更的文件，其余文件复用上次扫描的结果
+// 路径可以是文件或目录：已删除（或移走）时移除其下所有文件的组件，新建（或移入）的目录扫描其中的文件；
+// 文件不再符合扫描条件（排除目录、include_only、测试文件等）时同样移除其组件。
+// 之后基于内存中各文件的结果重建 ElementMap 并重新绑定注解接口，调用方再执行 Write 即可，
+// 内容未变化的 Set 文件会被跳过.
+func (sc *AutoWireSearcher) Rescan(paths ...string) error {
+	// 包中的文件可能已经变化，重新加载；变更文件所在的包一并加载
+	sc.packages = &packageIndex{}
+	sc.preloadPackages(parser.Map(paths, func(p string) string { return filepath.Dir(absPath(p)) }))
+	sc.stats = &scanStats{}
+	// 位置已记录在组件中，重新创建文件集合，避免监听模式下持续增长
+	sc.fset = token.NewFileSet()
+
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		info, err := os.Stat(p)
+		switch {
+		case err != nil:
+			sc.forget(p)
+		case info.IsDir():
+			if err := sc.rescanDir(p); err != nil {
+				return err
+			}
+		default:
+			if err := sc.rescanFile(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 构造函数可以声明在同一包的其他文件中，重新解析变更文件所在目录中带注解的其他文件
+	for _, file := range sc.annotatedSiblings(paths) {
+		if err := sc.rescanFile(file); err != nil {
+			return err
+		}
+	}
+
+	sc.rebuild()
+	return nil
+}
+
+// annotatedSiblings method    返回与变更文件位于同一目录、解析出组件的其他文件.
+func (sc *AutoWireSearcher) annotatedSiblings(paths []string) []string {
+	changed := parser.NewSet[string]()
+	dirs := parser.NewSet[string]()
+	for _, p := range paths {
+		changed.Add(absPath(p))
+		dirs.Add(filepath.Dir(absPath(p)))
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	var files []string
+	for _, file := range parser.SortedKeys(sc.fileElements) {
+		if len(sc.fileElements[file]) > 0 && !changed.Contains(file) && dirs.Contains(filepath.Dir(file)) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// rescanFile method    重新解析单个文件，不符合扫描条件时移除其组件
+// 文件内容未变化（如只更新了修改时间）时复用缓存的解析结果.
+func (sc *AutoWireSearcher) rescanFile(file string) error {
+	if err := sc.ctxErr(); err != nil {
+		return err
+	}
+	if !sc.shouldScan(file) {
+		sc.forget(file)
+		return nil
+	}
+	sc.dropElements(file)
+	sc.cache.Expire(file)
+	sc.scannedDirs.Add(filepath.Dir(file))
+	return sc.searchWire(file)
+}
+
+// rescanDir method    扫描新建或移入的目录，跳过排除的子目录.
+func (sc *AutoWireSearcher) rescanDir(dir string) error {
+	walkOpts := parser.WalkOptions{Roots: sc.searchRoots}
+	return parser.Walk(dir, walkOpts, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if f.IsDir() {
+			if path != dir && sc.isExcluded(path, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return sc.rescanFile(path)
+	})
+}
+
+// forget method    移除文件（或目录下所有文件）的解析结果及其缓存，删除或移走的文件不会残留在缓存文件中.
+func (sc *AutoWireSearcher) forget(path string) {
+	sc.dropElements(path)
+	sc.cache.Remove(path)
+}
+
+// dropElements method    移除文件（或目录下所有文件）在内存中的解析结果，保留缓存.
+func (sc *AutoWireSearcher) dropElements(path string) {
+	abs := absPath(path)
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for file := range sc.fileElements {
+		if file == abs || strings.HasPrefix(file, abs+string(filepath.Separator)) {
+			delete(sc.fileElements, file)
+			delete(sc.fileDiagnostics, file)
+		}
+	}
+}
+
+// shouldScan method    判断文件是否符合扫描条件，与 SearchAllPath 的过滤规则一致
+// 额外检查文件所在的各级目录是否被排除（完整扫描时由目录遍历跳过）.
+func (sc *AutoWireSearcher) shouldScan(file string) bool {
+	if !parser.CheckFileType(filepath.Base(file)) || sc.isExcluded(file, false) || !sc.isIncluded(file) ||
+		!sc.withinDepth(file) || !sc.matchBuild(file) {
+		return false
+	}
+	modDir := parser.GetModuleDir(file)
+	for dir := filepath.Dir(absPath(file)); ; dir = filepath.Dir(dir) {
+		if _, ok := parser.RelPath(modDir, dir); !ok || dir == modDir || dir == filepath.Dir(dir) {
+			return true
+		}
+		if sc.isExcluded(dir, true) {
+			return false
+		}
+	}
+}
+
+// rebuild method    基于各文件的解析结果重建 ElementMap，并重新绑定注解接口.
+func (sc *AutoWireSearcher) rebuild() {
+	sc.ElementMap = make(map[string]map[string]Element)
+	sc.interfaces = nil
+	sc.composites = nil
+	sc.splitSets = parser.NewSet[string]()
+	for _, file := range parser.SortedKeys(sc.fileElements) {
+		sc.addCachedElements(sc.fileElements[file], file)
+	}
+	sc.bindAnnotatedInterfaces()
+}
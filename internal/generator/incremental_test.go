@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestRescan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	a := write("a.go", "package m\n\n// @autowire(set=a)\ntype A struct{}\n")
+	b := write("b.go", "package m\n\n// @autowire(set=b)\ntype B struct{}\n")
+
+	o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+		config.WithLogger(logger.Discard()))
+	sc := NewAutoWireSearcher(o, "example.com/m")
+	if err := sc.SearchAllPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	assertSets := func(want ...string) {
+		t.Helper()
+		if got := parser.SortedKeys(sc.ElementMap); !slices.Equal(got, want) {
+			t.Errorf("sets = %v, want %v", got, want)
+		}
+	}
+	assertSets("a", "b")
+
+	// 修改文件：组件移动到新的 Set，旧 Set 随之消失
+	write("a.go", "package m\n\n// @autowire(set=c)\ntype A struct{}\n")
+	if err := sc.Rescan(a); err != nil {
+		t.Fatal(err)
+	}
+	assertSets("b", "c")
+
+	// 删除文件：移除其组件
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Rescan(b); err != nil {
+		t.Fatal(err)
+	}
+	assertSets("c")
+
+	// 新建文件
+	write("d_test.go", "package m\n\n// @autowire(set=d)\ntype D struct{}\n")
+	write("d.go", "package m\n\n// @autowire(set=d)\ntype D struct{}\n")
+	if err := sc.Rescan(filepath.Join(dir, "d_test.go"), filepath.Join(dir, "d.go")); err != nil {
+		t.Fatal(err)
+	}
+	assertSets("c", "d")
+
+	// 新建目录：扫描其中的文件；删除目录：移除其下所有文件的组件
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(sub, "inner"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	write("sub/inner/e.go", "package inner\n\n// @autowire(set=e)\ntype E struct{}\n")
+	if err := sc.Rescan(sub); err != nil {
+		t.Fatal(err)
+	}
+	assertSets("c", "d", "e")
+
+	if err := os.RemoveAll(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Rescan(sub); err != nil {
+		t.Fatal(err)
+	}
+	assertSets("c", "d")
+}
+
+func TestPruneStale(t *testing.T) {
+	dir := t.TempDir()
+	genPath := filepath.Join(dir, "wire")
+	outDir := filepath.Join(dir, "out")
+	for _, d := range []string{genPath, outDir} {
+		if err := os.MkdirAll(d, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header := "// Code generated by go-autowire. DO NOT EDIT.\n\npackage wire\n"
+	kept := filepath.Join(genPath, "autowire_a.go")
+	stale := filepath.Join(outDir, "autowire_b.go")
+	manual := filepath.Join(genPath, "wire.gen.go")
+	for file, src := range map[string]string{kept: header, stale: header, manual: "package wire\n"} {
+		if err := os.WriteFile(file, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewCacheManager(genPath, true)
+	cache.generated = []string{kept, stale, manual}
+	cache.outputs = map[string]string{kept: "a", stale: "b"}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, genPath: genPath, cache: cache, logger: logger.Discard(),
+		produced: &fileList{}}
+	sc.produced.add(kept)
+	sc.pruneStale()
+
+	// 不再生成的文件被删除，空的输出目录一并删除；没有生成标记的文件保留
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("kept file removed: %v", err)
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("stale output dir not removed: %v", err)
+	}
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("manual file removed: %v", err)
+	}
+	if got := cache.Generated(); !slices.Equal(got, []string{kept}) {
+		t.Errorf("Generated() = %v", got)
+	}
+	if _, ok := cache.outputs[stale]; ok {
+		t.Error("fingerprint of stale file not removed")
+	}
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+// fullInitResults 无法确定依赖链的返回形式时使用的完整返回值，wire 对任意依赖链均接受该形式.
+const fullInitResults = "(%s, func(), error)"
+
+// contextType 注入入口带 ctx 参数时由初始化函数参数提供的类型.
+const contextType = "context.Context"
+
+// injectorParams function    返回注入入口的初始化函数参数：带 ctx 参数时以 ctx context.Context 开头，
+// 其后为配置参数与 args= 参数声明的额外参数.
+func injectorParams(root Element, params []string) []string {
+	result := slices.Clone(params)
+	if root.Context {
+		result = append([]string{"ctx " + contextType}, result...)
+	}
+	return append(result, root.Args...)
+}
+
+// providedByParams function    判断依赖类型是否由注入入口的初始化函数参数提供
+// （ctx 参数提供 context.Context，args= 参数提供其声明的类型）.
+func providedByParams(root Element, dep string) bool {
+	return (root.Context && dep == contextType) || slices.Contains(root.ArgTypes, dep)
+}
+
+// resolveInjectorArgs method    处理 args= 参数：记录初始化函数的参数声明与参数提供的类型
+// 组件所在包的类型加上包名，如 opts ...Option -> opts ...app.Option.
+func (sc *AutoWireSearcher) resolveInjectorArgs(wireElement *Element, f *ast.File, value string) {
+	fields, err := annotations.ParseArgs(value)
+	if err != nil {
+		// 格式错误由 checkAnnotations 报告
+		return
+	}
+	r := typeResolver{file: f, pkgPath: wireElement.PkgPath}
+	for _, field := range fields {
+		t := field.Type
+		variadic := ""
+		if e, ok := t.(*ast.Ellipsis); ok {
+			t, variadic = e.Elt, "..."
+		}
+		decl := variadic + qualifyResult(types.ExprString(t), wireElement.Pkg)
+		for _, name := range field.Names {
+			wireElement.Args = append(wireElement.Args, name.Name+" "+decl)
+			wireElement.ArgTypes = appendUnique(wireElement.ArgTypes, r.typeKey(field.Type))
+		}
+	}
+}
+
+// initResults method    返回初始化函数的返回值列表
+// 返回类型取构造函数的第一个返回值；依赖链上任一构造函数返回 cleanup 或 error 时，初始化函数同样返回.
+func (sc *AutoWireSearcher) initResults(root Element) string {
+	result := rootResult(root)
+	cleanup, hasErr, ok := sc.injectorReturns(root)
+	if !ok {
+		return fmt.Sprintf(fullInitResults, result)
+	}
+
+	results := []string{result}
+	if cleanup {
+		results = append(results, "func()")
+	}
+	if hasErr {
+		results = append(results, "error")
+	}
+	if len(results) == 1 {
+		return result
+	}
+	return "(" + strings.Join(results, ", ") + ")"
+}
+
+// rootResult function    返回注入入口的返回类型：构造函数的第一个返回值，没有构造函数时为 *T.
+func rootResult(root Element) string {
+	if root.Result != "" {
+		return qualifyResult(root.Result, root.Pkg)
+	}
+	return "*" + parser.AppendPkg(root.Pkg, root.Name)
+}
+
+// injectorReturns method    沿依赖链汇总构造函数是否返回 cleanup 与 error
+// 缺少类型信息或存在未找到提供者的依赖时 ok 为 false，调用方应使用完整的返回形式.
+func (sc *AutoWireSearcher) injectorReturns(root Element) (cleanup, hasErr, ok bool) {
+	if len(root.Provides) == 0 {
+		return false, false, false
+	}
+
+	providers := sc.providerIndex()
+	visited := parser.NewSet[string]()
+	queue := []Element{root}
+	for len(queue) > 0 {
+		elem := queue[0]
+		queue = queue[1:]
+		key := elem.PkgPath + "/" + instanceName(elem)
+		if visited.Contains(key) {
+			continue
+		}
+		visited.Add(key)
+
+		if elem.Raw != "" {
+			// 自定义提供者的签名未知
+			return false, false, false
+		}
+		cleanup = cleanup || elem.Cleanup
+		hasErr = hasErr || elem.ReturnsErr
+		for _, dep := range elem.Deps {
+			if providedByParams(root, dep) {
+				continue
+			}
+			if len(providers[dep]) == 0 {
+				return false, false, false
+			}
+			queue = append(queue, providers[dep]...)
+		}
+	}
+	return cleanup, hasErr, true
+}
+
+// qualifyResult function    将源码形式的类型表达式中属于组件所在包的标识符加上包名
+// 如 *Zoo -> *zoo.Zoo，已带包名的类型（如 http.Handler）保持不变.
+func qualifyResult(expr, pkg string) string {
+	if pkg == "" {
+		return expr
+	}
+	x, err := goparser.ParseExpr(expr)
+	if err != nil {
+		return expr
+	}
+
+	// 选择器中的标识符（包名与类型名）不需要处理
+	skip := parser.NewSet[*ast.Ident]()
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := t.X.(*ast.Ident); ok {
+				skip.Add(id)
+				skip.Add(t.Sel)
+			}
+		case *ast.Ident:
+			if !skip.Contains(t) && types.Universe.Lookup(t.Name) == nil {
+				t.Name = pkg + "." + t.Name
+			}
+		}
+		return true
+	})
+	return types.ExprString(x)
+}
+
+// loadInitTemplate method    返回初始化文件模板：配置了 init_template 时读取并解析该文件，否则使用默认模板.
+func (sc *AutoWireSearcher) loadInitTemplate() (*template.Template, error) {
+	if sc.initTemplate == "" {
+		return InitTemp, nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(sc.initTemplate)
+	if err != nil {
+		return nil, initTemplateError(sc.initTemplate, err)
+	}
+	tmpl, err := template.New(filepath.Base(sc.initTemplate)).Funcs(initTemplateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, initTemplateError(sc.initTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// initTemplateError function    返回读取、解析或执行初始化文件模板失败的错误，path 为空表示默认模板.
+func initTemplateError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("执行模板失败: %w", err)
+	}
+	return &errors.FriendlyError{
+		Type:    errors.ErrorTypeInvalidConfig,
+		Message: "初始化文件模板无效: " + path,
+		Details: err.Error(),
+		Suggestions: []string{
+			"检查 init_template 配置的路径是否正确（相对于当前目录）",
+			"模板数据为 InitFile：.Package 与 .Injectors，每个 Injector 包含 .Name、.Type、.Params、.Results、.Build",
+			"可以使用 join 函数拼接参数，如 {{ join .Params \", \" }}",
+		},
+		HelpURL: "https://github.com/spelens-gud/gutowire#init-template",
+	}
+}
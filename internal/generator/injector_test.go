@@ -0,0 +1,317 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestWriteInitFile_NamedInjectors(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{
+		mu:       &sync.Mutex{},
+		genPath:  dir,
+		pkg:      "wire",
+		logger:   logger.Discard(),
+		cache:    NewCacheManager(dir, false),
+		initWire: []string{"*"},
+		initElements: []Element{
+			{Name: "Worker", InitWire: true, Injector: "WorkerApp"},
+			{Name: "Legacy", InitWire: true},
+			{Name: "Server", InitWire: true, Injector: "ServerApp"},
+		},
+	}
+	if err := sc.writeInitFile(); err != nil {
+		t.Fatalf("writeInitFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "wire.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"func InitializeLegacy() (*Legacy, func(), error) {\n\tpanic(wire.Build(Sets))",
+		"func InitializeServerApp() (*Server, func(), error) {\n\tpanic(wire.Build(Sets, InitServerAppSet))",
+		"func InitializeWorkerApp() (*Worker, func(), error) {\n\tpanic(wire.Build(Sets, InitWorkerAppSet))",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("wire.gen.go 缺少 %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "InitializeServer(") {
+		t.Error("命名注入入口不应再生成按类型命名的初始化函数")
+	}
+}
+
+func TestWriteInitFile_Context(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{
+		mu:             &sync.Mutex{},
+		genPath:        dir,
+		pkg:            "wire",
+		logger:         logger.Discard(),
+		cache:          NewCacheManager(dir, false),
+		initWire:       []string{"*"},
+		initElements:   []Element{{Name: "Server", Pkg: "app", InitWire: true, Injector: "Server", Context: true}},
+		configElements: []Element{{Name: "Config", Pkg: "conf", ConfigWire: true}},
+	}
+	if err := sc.writeInitFile(); err != nil {
+		t.Fatalf("writeInitFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "wire.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func InitializeServer(ctx context.Context, c0 *conf.Config) (*app.Server, func(), error) {"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("wire.gen.go 缺少 %q:\n%s", want, data)
+	}
+}
+
+func TestWriteInitFile_ExplicitTypes(t *testing.T) {
+	dir := t.TempDir()
+	// 显式指定的类型不需要 @autowire.init 组件
+	sc := &AutoWireSearcher{
+		mu:       &sync.Mutex{},
+		genPath:  dir,
+		pkg:      "wire",
+		logger:   logger.Discard(),
+		cache:    NewCacheManager(dir, false),
+		initWire: []string{"*Zoo"},
+	}
+	if err := sc.writeInitFile(); err != nil {
+		t.Fatalf("writeInitFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "wire.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "func InitializeZoo() (*Zoo, func(), error) {"; !strings.Contains(string(data), want) {
+		t.Errorf("wire.gen.go 中缺少 %s:\n%s", want, data)
+	}
+}
+
+func TestWriteInitFile_Template(t *testing.T) {
+	dir := t.TempDir()
+	tmplFile := filepath.Join(dir, "init.tmpl")
+	tmpl := "// Code generated by go-autowire. DO NOT EDIT.\n\n//go:build wireinject\n\npackage {{ .Package }}\n" +
+		"{{ range .Injectors }}\n// New{{ .Name }}Injector 构造 {{ .Type }}.\n" +
+		"func New{{ .Name }}Injector(ctx context.Context, {{ join .Params \", \" }}) {{ .Results }} {\n" +
+		"\tpanic(wire.Build({{ .Build }}))\n}\n{{ end }}"
+	if err := os.WriteFile(tmplFile, []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:             &sync.Mutex{},
+		genPath:        dir,
+		pkg:            "wire",
+		logger:         logger.Discard(),
+		cache:          NewCacheManager(dir, false),
+		initWire:       []string{"*"},
+		initElements:   []Element{{Name: "Server", Pkg: "app", InitWire: true}},
+		configElements: []Element{{Name: "Config", Pkg: "conf", ConfigWire: true}},
+		initTemplate:   tmplFile,
+	}
+	if err := sc.writeInitFile(); err != nil {
+		t.Fatalf("writeInitFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "wire.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// NewServerInjector 构造 *app.Server.\n" +
+		"func NewServerInjector(ctx context.Context, c0 *conf.Config) (*app.Server, func(), error) {"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("wire.gen.go 缺少 %q:\n%s", want, data)
+	}
+
+	// 模板语法错误返回包含模板路径的错误
+	if err := os.WriteFile(tmplFile, []byte("{{ range .Injectors }}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.writeInitFile(); err == nil || !strings.Contains(err.Error(), tmplFile) {
+		t.Errorf("writeInitFile() error = %v", err)
+	}
+}
+
+func TestCheckInjectorNames(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{
+			"initServerApp": {
+				"example.com/a/App": {Name: "App", Pkg: "a", InitWire: true, Injector: "ServerApp"},
+				"example.com/b/App": {Name: "App", Pkg: "b", InitWire: true, Injector: "ServerApp"},
+			},
+		},
+	}
+	if err := sc.checkInjectorNames(); err == nil {
+		t.Error("checkInjectorNames() 应该报告重复的注入入口名称")
+	}
+
+	delete(sc.ElementMap["initServerApp"], "example.com/b/App")
+	if err := sc.checkInjectorNames(); err != nil {
+		t.Errorf("checkInjectorNames() error = %v", err)
+	}
+}
+
+func TestInitResults(t *testing.T) {
+	db := Element{Name: "DB", Pkg: "db", PkgPath: "example.com/db", Constructor: "NewDB",
+		Provides: []string{"example.com/db.DB"}, Result: "*DB", Cleanup: true, ReturnsErr: true}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{"infra": {"example.com/db/DB": db}},
+	}
+
+	tests := []struct {
+		name string
+		root Element
+		want string
+	}{
+		{
+			name: "wire.Struct 无返回 cleanup 与 error",
+			root: Element{Name: "Plain", Pkg: "app", Provides: []string{"example.com/app.Plain"}},
+			want: "*app.Plain",
+		},
+		{
+			name: "构造函数仅返回 error",
+			root: Element{Name: "Worker", Pkg: "app", Provides: []string{"example.com/app.Worker"},
+				Result: "Worker", ReturnsErr: true},
+			want: "(app.Worker, error)",
+		},
+		{
+			name: "依赖链上的 cleanup 与 error 向上传递",
+			root: Element{Name: "NewServer", Pkg: "app", Provides: []string{"net/http.Handler"},
+				Result: "http.Handler", Deps: []string{"example.com/db.DB"}},
+			want: "(http.Handler, func(), error)",
+		},
+		{
+			name: "存在未知依赖时使用完整形式",
+			root: Element{Name: "App", Pkg: "app", Provides: []string{"example.com/app.App"},
+				Result: "*App", Deps: []string{"example.com/unknown.X"}},
+			want: "(*app.App, func(), error)",
+		},
+		{
+			name: "ctx 参数提供 context.Context",
+			root: Element{Name: "Job", Pkg: "app", Provides: []string{"example.com/app.Job"},
+				Result: "*Job", Deps: []string{"context.Context"}, Context: true},
+			want: "*app.Job",
+		},
+		{
+			name: "缺少类型信息时使用完整形式",
+			root: Element{Name: "Legacy", Pkg: "app"},
+			want: "(*app.Legacy, func(), error)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sc.initResults(tt.root); got != tt.want {
+				t.Errorf("initResults() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifyResult(t *testing.T) {
+	tests := []struct {
+		expr, pkg, want string
+	}{
+		{"*Zoo", "zoo", "*zoo.Zoo"},
+		{"http.Handler", "app", "http.Handler"},
+		{"[]*Item", "store", "[]*store.Item"},
+		{"map[string]Handler", "app", "map[string]app.Handler"},
+		{"*Zoo", "", "*Zoo"},
+	}
+	for _, tt := range tests {
+		if got := qualifyResult(tt.expr, tt.pkg); got != tt.want {
+			t.Errorf("qualifyResult(%q, %q) = %q, want %q", tt.expr, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+const injectorArgsSrc = `package app
+
+type Option func(*Client)
+
+// @autowire.init(set=app,ctx,args="name string, opts ...Option")
+type App struct {
+	Client *Client
+}
+
+// @autowire(set=app)
+func NewClient(name string, opts ...Option) *Client { return nil }
+
+type Client struct{}
+
+// @autowire(set=app,args="x int")
+type Ignored struct{}
+
+// @autowire.init(set=app,args="opts ...Option, name string")
+type Bad struct{}
+
+// @autowire.init(set=app,args="c0 string")
+type Reserved struct{}
+`
+
+func TestInjectorArgs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, injectorArgsSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{"app": {}},
+		genPath:    dir,
+		pkg:        "wire",
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+		initWire:   []string{"*"},
+	}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	var diags []string
+	for _, d := range sc.checkAnnotations(decls, f) {
+		diags = append(diags, d.Reason)
+	}
+	if len(diags) != 2 || !strings.Contains(diags[0], "final parameter") || !strings.Contains(diags[1], "c0") {
+		t.Errorf("checkAnnotations() = %v, want variadic and reserved name errors", diags)
+	}
+
+	for _, e := range sc.parseAnnotations(decls[:3], file, "example.com/app", f, getImplement(f)) {
+		sc.ElementMap["app"][e.PkgPath+"/"+e.Name] = e
+	}
+	app := sc.ElementMap["app"]["example.com/app/App"]
+	if !slices.Equal(app.Args, []string{"name string", "opts ...app.Option"}) ||
+		!slices.Equal(app.ArgTypes, []string{"string", "[]example.com/app.Option"}) {
+		t.Errorf("Args = %v, ArgTypes = %v", app.Args, app.ArgTypes)
+	}
+	if ignored := sc.ElementMap["app"]["example.com/app/Ignored"]; len(ignored.Args) != 0 {
+		t.Errorf("args 参数只对 @autowire.init 有效: %v", ignored.Args)
+	}
+	// 可变参数按切片类型匹配初始化函数参数
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("MissingProviders() = %v, want none", errs)
+	}
+
+	sc.initElements = []Element{app}
+	if err := sc.writeInitFile(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "wire.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func InitializeApp(ctx context.Context, name string, opts ...app.Option) *app.App {"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("wire.gen.go 缺少 %q:\n%s", want, data)
+	}
+}
@@ -0,0 +1,358 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// lifecycleMethods 生命周期方法，签名均为 func(context.Context) error，按此顺序记录.
+var lifecycleMethods = []string{"Start", "Stop"}
+
+// resolveLifecycle method    查找组件类型上的 Start(ctx) error、Stop(ctx) error 方法
+// 默认只查找组件所在的文件；lifecycle 参数会查找整个包目录，两个方法都不存在时给出警告.
+func (sc *AutoWireSearcher) resolveLifecycle(wireElement *Element, decl *tmpDecl, f *ast.File, file string,
+	options map[string]string) {
+	_, explicit := options["lifecycle"]
+	name := sc.implName(decl, f)
+	if name == "" || wireElement.ConfigWire || wireElement.ValueWire || wireElement.TypeParams > 0 ||
+		wireElement.Qualifier != "" || wireElement.Scope == scopeFactory {
+		if explicit {
+			sc.logger.Warn("lifecycle 参数只对单例、非泛型、没有限定名的结构体或构造函数有效，已忽略",
+				"element", describeElement(*wireElement))
+		}
+		return
+	}
+
+	methods := lifecycleMethodsOf(f, name)
+	if explicit && len(methods) < len(lifecycleMethods) {
+		methods = sc.packageLifecycleMethods(file, name)
+		if len(methods) == 0 {
+			sc.logger.Warn("未找到 Start(context.Context) error 或 Stop(context.Context) error 方法",
+				"element", describeElement(*wireElement))
+		}
+	}
+	wireElement.Lifecycle = methods
+}
+
+// packageLifecycleMethods method    在组件所在包满足构建约束的全部源文件（不含测试文件）中查找生命周期方法.
+func (sc *AutoWireSearcher) packageLifecycleMethods(file, typeName string) []string {
+	var methods []string
+	for _, name := range sc.packageGoFiles(filepath.Dir(file)) {
+		f, err := goparser.ParseFile(sc.fileSet(), name, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		methods = append(methods, lifecycleMethodsOf(f, typeName)...)
+	}
+	return slices.DeleteFunc(slices.Clone(lifecycleMethods), func(m string) bool {
+		return !slices.Contains(methods, m)
+	})
+}
+
+// lifecycleMethodsOf function    返回文件中类型 typeName（T 或 *T 接收者）的生命周期方法.
+func lifecycleMethodsOf(f *ast.File, typeName string) []string {
+	var methods []string
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Recv == nil || len(fd.Recv.List) == 0 || receiverName(fd.Recv.List[0].Type) != typeName {
+			continue
+		}
+		if slices.Contains(lifecycleMethods, fd.Name.Name) && isLifecycleFunc(f, fd.Type) {
+			methods = append(methods, fd.Name.Name)
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(lifecycleMethods), func(m string) bool {
+		return !slices.Contains(methods, m)
+	})
+}
+
+// isLifecycleFunc function    判断函数签名是否为 func(context.Context) error.
+func isLifecycleFunc(f *ast.File, ft *ast.FuncType) bool {
+	if ft.Params == nil || len(ft.Params.List) != 1 || len(ft.Params.List[0].Names) > 1 {
+		return false
+	}
+	if ft.Results == nil || len(ft.Results.List) != 1 || len(ft.Results.List[0].Names) > 1 {
+		return false
+	}
+	if id, ok := ft.Results.List[0].Type.(*ast.Ident); !ok || id.Name != "error" {
+		return false
+	}
+	sel, ok := ft.Params.List[0].Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && importName(f, "context") == pkg.Name
+}
+
+// importName function    返回文件中导入路径对应的包名，未导入时返回空字符串.
+func importName(f *ast.File, importPath string) string {
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path.Base(importPath)
+	}
+	return ""
+}
+
+// lifecycleElements method    返回带生命周期方法的组件，按依赖顺序排列（被依赖的组件在前）
+// 只包含汇总 Sets 中的组件：带构建标签、拆分出的 Set 与命名注入入口 Set 中的组件不参与.
+func (sc *AutoWireSearcher) lifecycleElements() []Element {
+	providers := sc.providerIndex()
+	visited := parser.NewSet[string]()
+	var ordered []Element
+
+	var visit func(elem Element)
+	visit = func(elem Element) {
+		key := elem.PkgPath + "/" + instanceName(elem)
+		if visited.Contains(key) {
+			return
+		}
+		visited.Add(key)
+		for _, dep := range elem.Deps {
+			for _, p := range providers[dep] {
+				visit(p)
+			}
+		}
+		if len(elem.Lifecycle) > 0 && sc.inSets(elem) {
+			ordered = append(ordered, elem)
+		}
+	}
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			if elem := sc.ElementMap[set][key]; len(elem.Lifecycle) > 0 {
+				visit(elem)
+			}
+		}
+	}
+	return ordered
+}
+
+// inSets method    判断组件是否由汇总 Sets 提供.
+func (sc *AutoWireSearcher) inSets(elem Element) bool {
+	return elem.Tag == "" && !sc.splitSets.Contains(elem.Set) && sc.setBuildTags[elem.Set] == "" &&
+		!isInjectorSet(sc.ElementMap[elem.Set])
+}
+
+// lifecycleRoots method    返回需要生成 Initialize<Name>App 的注入入口，没有生命周期组件时为空.
+func (sc *AutoWireSearcher) lifecycleRoots() []Element {
+	hasInit := false
+	for _, elements := range sc.ElementMap {
+		for _, elem := range elements {
+			hasInit = hasInit || elem.InitWire
+		}
+	}
+	if !hasInit || len(sc.lifecycleElements()) == 0 {
+		return nil
+	}
+	return sc.injectorRoots()
+}
+
+// appName function    返回注入入口对应的 App 类型名前缀，命名注入入口使用其名称.
+func appName(root Element) string {
+	if root.Injector != "" {
+		return root.Injector
+	}
+	return root.Name
+}
+
+// lifecycleData struct    生命周期文件的模板数据.
+type lifecycleData struct {
+	Package string          // 包名
+	Params  []string        // NewLifecycle 的参数，按依赖顺序排列
+	Hooks   []lifecycleHook // 各组件的启动与停止函数
+	Apps    []lifecycleApp  // 各注入入口的 App 类型
+}
+
+// lifecycleHook struct    单个组件的生命周期钩子.
+type lifecycleHook struct {
+	Name  string // 组件名称，用于错误信息
+	Start string // 启动函数表达式，没有 Start 方法时为 nil
+	Stop  string // 停止函数表达式，没有 Stop 方法时为 nil
+}
+
+// lifecycleApp struct    注入入口的 App 类型.
+type lifecycleApp struct {
+	Name  string // 类型名前缀，生成 <Name>App
+	Field string // 注入入口组件的字段名
+	Type  string // 注入入口组件的类型
+}
+
+// writeLifecycleFile method    生成 autowire_lifecycle.go：Lifecycle 按依赖顺序启动组件、按相反顺序停止，
+// 并为每个注入入口生成包含 Lifecycle 的 <Name>App 类型（由 wire.gen.go 中的 Initialize<Name>App 创建）.
+func (sc *AutoWireSearcher) writeLifecycleFile() error {
+	roots := sc.lifecycleRoots()
+	if len(roots) == 0 {
+		return nil
+	}
+	fileName := filepath.Join(sc.genPath, sc.genFileName(lifecycleFile))
+	pathPkg := sc.getPkgPath(fileName)
+
+	// 生命周期组件与注入入口一起处理包名冲突
+	hooks := sc.lifecycleElements()
+	elements := make(map[string]Element, len(hooks)+len(roots))
+	var order []string
+	for i, elem := range append(slices.Clone(hooks), roots...) {
+		key := fmt.Sprintf("%03d", i)
+		elements[key] = elem
+		order = append(order, key)
+	}
+	sc.resolvePackageConflicts(elements, make(map[string]map[string]string), order)
+
+	data := lifecycleData{Package: sc.pkg}
+	var importPkgs []*ast.ImportSpec
+	for i, key := range order {
+		elem := elements[key]
+		if elem.PkgPath == pathPkg {
+			elem.Pkg = ""
+		} else {
+			importPkgs = append(importPkgs, sc.createImportSpec(&elem))
+		}
+
+		if i >= len(hooks) {
+			field := strcase.UpperCamelCase(elem.Name)
+			if field == "Lifecycle" {
+				field = "Root"
+			}
+			data.Apps = append(data.Apps, lifecycleApp{Name: appName(elem), Field: field, Type: rootResult(elem)})
+			continue
+		}
+
+		param := "c" + strconv.Itoa(i)
+		hook := lifecycleHook{Name: parser.AppendPkg(hooks[i].Pkg, hooks[i].Name), Start: "nil", Stop: "nil"}
+		if slices.Contains(elem.Lifecycle, "Start") {
+			hook.Start = param + ".Start"
+		}
+		if slices.Contains(elem.Lifecycle, "Stop") {
+			hook.Stop = param + ".Stop"
+		}
+		data.Params = append(data.Params, param+" "+defaultBindImpl(&elem, parser.AppendPkg(elem.Pkg, elem.Name)))
+		data.Hooks = append(data.Hooks, hook)
+	}
+	return sc.writeTemplateFile(fileName, LifecycleTemp, data, importPkgs)
+}
+
+// appInjectors method    返回 wire.gen.go 中各注入入口对应的 Initialize<Name>App 函数.
+func (sc *AutoWireSearcher) appInjectors(params []string) []Injector {
+	var injectors []Injector
+	for _, root := range sc.lifecycleRoots() {
+		sets := "Sets"
+		if root.Injector != "" {
+			sets += ", " + setVarName("init"+root.Injector)
+		}
+		app := appName(root) + "App"
+		injectors = append(injectors, Injector{
+			Name:    sc.injectorPrefix + app,
+			Type:    "*" + app,
+			Params:  injectorParams(root, params),
+			Results: fmt.Sprintf(fullInitResults, "*"+app),
+			Build:   sets + ", NewLifecycle, wire.Struct(new(" + app + `), "*")`,
+		})
+	}
+	return injectors
+}
+
+// hasLifecycleFile method    判断本次生成是否包含 autowire_lifecycle.go.
+func (sc *AutoWireSearcher) hasLifecycleFile() bool {
+	return len(sc.lifecycleRoots()) > 0
+}
+
+// LifecycleTemp 预编译的生命周期模板.
+var LifecycleTemp = template.Must(template.New("").Parse(strings.TrimLeft(lifecycleTemplate, "\n")))
+
+// lifecycleTemplate 生命周期文件的代码生成模板.
+var lifecycleTemplate = `
+// Code generated by go-autowire. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// LifecycleHook 组件的启动与停止函数.
+type LifecycleHook struct {
+	Name  string                          // 组件名称
+	Start func(ctx context.Context) error // 启动函数，为 nil 表示无需启动
+	Stop  func(ctx context.Context) error // 停止函数，为 nil 表示无需停止
+}
+
+// Lifecycle 按依赖顺序启动组件，按相反顺序停止组件.
+type Lifecycle struct {
+	hooks   []LifecycleHook
+	started int
+}
+
+// NewLifecycle 创建生命周期，参数按依赖顺序排列，被依赖的组件先启动.
+func NewLifecycle({{ range .Params }}{{ . }}, {{ end }}) *Lifecycle {
+	return &Lifecycle{hooks: []LifecycleHook{
+		{{- range .Hooks }}
+		{Name: "{{ .Name }}", Start: {{ .Start }}, Stop: {{ .Stop }}},
+		{{- end }}
+	}}
+}
+
+// Hooks 返回按启动顺序排列的生命周期钩子.
+func (l *Lifecycle) Hooks() []LifecycleHook {
+	return l.hooks
+}
+
+// Start 按依赖顺序启动组件，启动失败时按相反顺序停止已经启动的组件.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for l.started < len(l.hooks) {
+		h := l.hooks[l.started]
+		if h.Start != nil {
+			if err := h.Start(ctx); err != nil {
+				return errors.Join(fmt.Errorf("启动 %s 失败: %w", h.Name, err), l.Stop(ctx))
+			}
+		}
+		l.started++
+	}
+	return nil
+}
+
+// Stop 按启动的相反顺序停止已经启动的组件，返回全部停止失败的错误.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.Stop != nil {
+			if err := h.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("停止 %s 失败: %w", h.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run 启动全部组件并等待 ctx 结束，之后按相反顺序停止组件.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return l.Stop(context.WithoutCancel(ctx))
+}
+{{ range .Apps }}
+// {{ .Name }}App 注入入口 {{ .Field }} 及其生命周期，由 Initialize{{ .Name }}App 创建.
+type {{ .Name }}App struct {
+	*Lifecycle
+	{{ .Field }} {{ .Type }}
+}
+{{ end }}`
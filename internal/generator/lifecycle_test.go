@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestLifecycle(t *testing.T) {
+	src := "package svc\n\nimport stdctx \"context\"\n\n" +
+		"// @autowire(set=svc)\ntype DB struct{}\n\n" +
+		"func (d *DB) Start(ctx stdctx.Context) error { return nil }\n\n" +
+		"func (d *DB) Stop(stdctx.Context) error { return nil }\n\n" +
+		"// @autowire(set=svc)\ntype Server struct{}\n\n" +
+		"func NewServer(db *DB) *Server { return nil }\n\n" +
+		"func (s Server) Start(ctx stdctx.Context) error { return nil }\n\n" +
+		"// Stop 签名不符合，忽略.\nfunc (s *Server) Stop() {}\n\n" +
+		"// @autowire(set=svc)\ntype Cache struct{}\n\n" +
+		"// @autowire.init(set=svc)\ntype App struct {\n\tServer *Server\n}\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard(), initWire: []string{"*"}}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f,
+		getImplement(f))
+
+	want := map[string][]string{"DB": {"Start", "Stop"}, "Server": {"Start"}, "Cache": nil, "App": nil}
+	for _, e := range elements {
+		if !slices.Equal(e.Lifecycle, want[e.Name]) {
+			t.Errorf("%s: Lifecycle = %v, want %v", e.Name, e.Lifecycle, want[e.Name])
+		}
+	}
+
+	// 被依赖的 DB 先启动
+	order := elementNames(sc.lifecycleElements())
+	if !slices.Equal(order, []string{"DB", "Server"}) {
+		t.Errorf("lifecycleElements = %v, want [DB Server]", order)
+	}
+	if roots := sc.lifecycleRoots(); len(roots) != 1 || appName(roots[0]) != "App" {
+		t.Errorf("lifecycleRoots = %v, want [App]", roots)
+	}
+}
+
+// elementNames function    返回组件名称列表.
+func elementNames(elements []Element) []string {
+	names := make([]string, 0, len(elements))
+	for _, e := range elements {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func TestLifecycleFileLayout(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard(), initWire: []string{"*"}, genPath: dir, pkg: "wire",
+		cache: NewCacheManager(dir, false)}
+	for pkgPath, src := range map[string]string{
+		"example.com/db": "package db\n\nimport \"context\"\n\n// @autowire(set=svc)\ntype DB struct{}\n\n" +
+			"func (d *DB) Start(context.Context) error { return nil }\n",
+		"example.com/svc": "package svc\n\nimport (\n\t\"context\"\n\n\t\"example.com/db\"\n)\n\n" +
+			"// @autowire(set=svc)\ntype Server struct{ DB *db.DB }\n\n" +
+			"func (s *Server) Stop(context.Context) error { return nil }\n\n" +
+			"// @autowire.init(set=svc)\ntype App struct{ Server *Server }\n",
+	} {
+		file := path.Base(pkgPath) + ".go"
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, pkgPath, f, getImplement(f))
+	}
+	if err := sc.writeLifecycleFile(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sc.genFileName(lifecycleFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 组件包的导入位于导入块中，类型的文档注释紧跟在导入块之后
+	want := `// Code generated by go-autowire. DO NOT EDIT.
+
+package wire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"example.com/db"
+	"example.com/svc"
+)
+
+// LifecycleHook 组件的启动与停止函数.
+type LifecycleHook struct {
+`
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("%s 的布局不正确:\n%s\nwant prefix:\n%s", lifecycleFile, data, want)
+	}
+}
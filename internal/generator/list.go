@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Component struct    gutowire list 输出的单个组件.
+type Component struct {
+	Name        string   `json:"name"`                  // 带包名的组件名称，如 svc.Zoo
+	Set         string   `json:"set"`                   // 所属 Set 名称
+	Kind        string   `json:"kind"`                  // 声明类型：type、func、value
+	Annotation  string   `json:"annotation"`            // 注解类型：autowire、init、config、value
+	Constructor string   `json:"constructor,omitempty"` // 构造函数名称或 @autowire.raw 的表达式，为空表示使用 wire.Struct 或 wire.FieldsOf
+	Interfaces  []string `json:"interfaces,omitempty"`  // 绑定的接口
+	PkgPath     string   `json:"pkg_path"`              // 完整的包导入路径
+	Position    string   `json:"position"`              // 声明在源文件中的位置
+	Deprecated  string   `json:"deprecated,omitempty"`  // 弃用说明，为空表示未弃用
+}
+
+// Components method    返回扫描到的全部组件，按 Set、包路径与名称排序
+// sets 不为空时只返回这些 Set 中的组件.
+func (sc *AutoWireSearcher) Components(sets ...string) []Component {
+	var comps []Component
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		if len(sets) > 0 && !slices.Contains(sets, set) {
+			continue
+		}
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			c := newComponent(sc.ElementMap[set][key])
+			c.Set = set
+			comps = append(comps, c)
+		}
+	}
+	return comps
+}
+
+// newComponent function    将组件转换为列表项.
+func newComponent(e Element) Component {
+	kind, annotation := "type", "autowire"
+	switch {
+	case e.FuncDecl:
+		kind = "func"
+	case e.ValueWire:
+		kind = "value"
+	}
+	switch {
+	case e.InitWire:
+		annotation = "init"
+	case e.ConfigWire:
+		annotation = "config"
+	case e.ValueWire:
+		annotation = "value"
+	case e.Raw != "":
+		annotation = "raw"
+	case len(e.Env) > 0 && e.Underlying != "":
+		annotation = "env"
+	}
+	interfaces := slices.Clone(e.Implements)
+	slices.Sort(interfaces)
+	return Component{
+		Name:        parser.AppendPkg(e.Pkg, e.Name),
+		Kind:        kind,
+		Annotation:  annotation,
+		Constructor: cmp.Or(e.Raw, e.Constructor),
+		Interfaces:  interfaces,
+		PkgPath:     e.PkgPath,
+		Position:    e.Position.String(),
+		Deprecated:  e.Deprecated,
+	}
+}
+
+// WriteComponents function    以表格形式输出组件列表.
+func WriteComponents(w io.Writer, comps []Component) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "COMPONENT\tSET\tKIND\tCONSTRUCTOR\tINTERFACES\tPACKAGE\tPOSITION")
+	for _, c := range comps {
+		kind := c.Kind
+		if c.Annotation != "autowire" && c.Annotation != c.Kind {
+			kind += "," + c.Annotation
+		}
+		if c.Deprecated != "" {
+			kind += ",deprecated"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.Set, kind, orDash(c.Constructor),
+			orDash(strings.Join(c.Interfaces, ",")), c.PkgPath, c.Position)
+	}
+	return tw.Flush()
+}
+
+// WriteComponentsJSON function    以 JSON 数组形式输出组件列表.
+func WriteComponentsJSON(w io.Writer, comps []Component) error {
+	if comps == nil {
+		comps = []Component{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(comps)
+}
+
+// orDash function    空字符串显示为 -.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
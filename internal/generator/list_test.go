@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: map[string]map[string]Element{
+		"zoo": {
+			"example.com/m/zoo/Zoo": {Name: "Zoo", Pkg: "zoo", PkgPath: "example.com/m/zoo", InitWire: true,
+				Position: token.Position{Filename: "zoo/zoo.go", Line: 5, Column: 6}},
+		},
+		"animals": {
+			"example.com/m/animals/NewCat": {Name: "NewCat", Pkg: "animals", PkgPath: "example.com/m/animals",
+				Constructor: "NewCat", FuncDecl: true, Implements: []string{"Namer", "Animal"}},
+		},
+	}}
+
+	comps := sc.Components()
+	if got := []string{comps[0].Name, comps[1].Name}; !slices.Equal(got, []string{"animals.NewCat", "zoo.Zoo"}) {
+		t.Fatalf("components = %v", got)
+	}
+	if c := comps[0]; c.Kind != "func" || c.Set != "animals" || !slices.Equal(c.Interfaces, []string{"Animal", "Namer"}) {
+		t.Errorf("NewCat = %+v", c)
+	}
+	if c := comps[1]; c.Annotation != "init" || c.Position != "zoo/zoo.go:5:6" {
+		t.Errorf("Zoo = %+v", c)
+	}
+	if got := sc.Components("zoo"); len(got) != 1 || got[0].Name != "zoo.Zoo" {
+		t.Errorf("Components(zoo) = %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteComponents(&buf, comps); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Animal,Namer") || !strings.Contains(buf.String(), "type,init") {
+		t.Errorf("table =\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := WriteComponentsJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Component
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded == nil {
+		t.Errorf("json = %s, err = %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// mockSet 测试替身（@autowire.mock）所在的 Set 名称，保留给测试替身，其他注解不能使用.
+const mockSet = "mock"
+
+// isMockSetName function    判断注解中的 Set 名称是否为保留的测试替身 Set（按生成的 Set 名称比较，如 Mock 与 mock 相同）.
+func isMockSetName(set string) bool {
+	return strcase.LowerCamelCase(set) == mockSet
+}
+
+// mockPkg 测试注入包的目录名与包名，生成到生成路径的子目录.
+const mockPkg = "autowiretest"
+
+// hasMocks method    判断是否存在测试替身.
+func (sc *AutoWireSearcher) hasMocks() bool {
+	return len(sc.ElementMap[mockSet]) > 0
+}
+
+// mockedInterfaces method    返回测试替身绑定的全部接口（完整形式）.
+func (sc *AutoWireSearcher) mockedInterfaces() parser.Set[string] {
+	mocked := parser.NewSet[string]()
+	for _, elem := range sc.ElementMap[mockSet] {
+		for _, itf := range elem.Implements {
+			mocked.Add(qualifiedInterface(elem, itf))
+		}
+	}
+	return mocked
+}
+
+// qualifiedInterface function    返回组件绑定的接口的完整形式，如 repo.Store 返回 example.com/repo.Store
+// 包名形式的接口按组件提供的类型（解析时已转换为完整形式）查找.
+func qualifiedInterface(elem Element, itf string) string {
+	switch {
+	case strings.Contains(itf, "/"):
+		return itf
+	case !strings.Contains(itf, "."):
+		return elem.PkgPath + "." + itf
+	}
+	for _, p := range elem.Provides {
+		if p == itf || strings.HasSuffix(p, "/"+itf) {
+			return p
+		}
+	}
+	return itf
+}
+
+// testElementMap method    返回测试注入包使用的组件：被测试替身替代的接口绑定被移除，
+// 直接提供这些接口的组件（如返回接口的构造函数）整体移除，测试替身保留在 mock Set 中.
+func (sc *AutoWireSearcher) testElementMap() map[string]map[string]Element {
+	mocked := sc.mockedInterfaces()
+	elementMap := make(map[string]map[string]Element, len(sc.ElementMap))
+	for set, elements := range sc.ElementMap {
+		m := make(map[string]Element, len(elements))
+		for key, elem := range elements {
+			if set == mockSet {
+				m[key] = elem
+				continue
+			}
+			bound := parser.Map(elem.Implements, func(itf string) string { return qualifiedInterface(elem, itf) })
+			if slices.ContainsFunc(elem.Provides, func(p string) bool {
+				return mocked.Contains(p) && !slices.Contains(bound, p)
+			}) {
+				sc.logger.Debug("组件提供的接口已被测试替身替代，不加入测试注入包", "element", describeElement(elem))
+				continue
+			}
+			elem.Implements = slices.DeleteFunc(slices.Clone(elem.Implements), func(itf string) bool {
+				return mocked.Contains(qualifiedInterface(elem, itf))
+			})
+			m[key] = elem
+		}
+		if len(m) > 0 {
+			elementMap[set] = m
+		}
+	}
+	return elementMap
+}
+
+// writeMockOutput method    存在测试替身时生成测试注入包 <genPath>/autowiretest
+// 包含全部 Set（被替代的接口绑定改为 MockSet 中的测试替身）与 InitializeTest<Name> 初始化函数，
+// 只在测试中导入，测试替身不会编译进正式代码。wire 不加载 _test.go 文件，其他包也无法导入 _test.go 中的声明，
+// 因此不生成 autowire_mocks_test.go，而是生成独立的包.
+func (sc *AutoWireSearcher) writeMockOutput() error {
+	if !sc.hasMocks() {
+		return nil
+	}
+	sub := sc.outputSearcher("", sc.testElementMap())
+	sub.genPath = filepath.Join(sc.genPath, mockPkg)
+	sub.pkg = mockPkg
+	sub.injectorPrefix = "Test"
+	sc.outputDirs = append(sc.outputDirs, absPath(sub.genPath))
+	return sub.writeOutput()
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestTestElementMap(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu:     &sync.Mutex{},
+		logger: logger.Discard(),
+		ElementMap: map[string]map[string]Element{
+			"repo": {
+				"example.com/repo/MySQL": {Name: "MySQL", PkgPath: "example.com/repo",
+					Implements: []string{"Store", "io.Closer"}, Provides: []string{"example.com/repo.Store", "io.Closer"}},
+				"example.com/repo/NewStore": {Name: "NewStore", PkgPath: "example.com/repo", FuncDecl: true,
+					Provides: []string{"example.com/repo.Store"}},
+				"example.com/repo/Service": {Name: "Service", PkgPath: "example.com/repo"},
+			},
+			mockSet: {
+				"example.com/mocks/StoreMock": {Name: "StoreMock", PkgPath: "example.com/mocks", Mock: true,
+					Implements: []string{"repo.Store"}, Provides: []string{"example.com/repo.Store"}},
+			},
+		},
+	}
+
+	mock := sc.ElementMap[mockSet]["example.com/mocks/StoreMock"]
+	if got := qualifiedInterface(mock, "repo.Store"); got != "example.com/repo.Store" {
+		t.Errorf("qualifiedInterface = %q, want example.com/repo.Store", got)
+	}
+
+	m := sc.testElementMap()
+	repo := m["repo"]
+	if got := repo["example.com/repo/MySQL"].Implements; !slices.Equal(got, []string{"io.Closer"}) {
+		t.Errorf("MySQL.Implements = %v, want [io.Closer]", got)
+	}
+	if _, ok := repo["example.com/repo/NewStore"]; ok {
+		t.Error("直接提供被替代接口的 NewStore 不应加入测试注入包")
+	}
+	if _, ok := repo["example.com/repo/Service"]; !ok {
+		t.Error("Service 应该保留")
+	}
+	if len(m[mockSet]) != 1 {
+		t.Errorf("mock Set = %v, want StoreMock", m[mockSet])
+	}
+	// 原始组件不受影响
+	if got := sc.ElementMap["repo"]["example.com/repo/MySQL"].Implements; len(got) != 2 {
+		t.Errorf("原始 MySQL.Implements 被修改: %v", got)
+	}
+}
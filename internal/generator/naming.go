@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// 生成文件的名称（不含前缀与后缀），完整文件名见 genFileName.
+const (
+	setsFile       = "sets"             // 汇总 Sets，生成到生成路径
+	groupFile      = "groups"           // 分组的汇总函数，生成到生成路径
+	lifecycleFile  = "lifecycle"        // 生命周期组件的启动与停止，生成到生成路径
+	fxModulesFile  = "modules"          // fx 后端汇总的 Module，生成到生成路径
+	factoryFile    = "factory"          // 方法工厂与 scope=factory 的包装函数，生成到组件所在包
+	qualifierFile  = "qualifier"        // 限定类型与包装构造函数，生成到组件所在包
+	envFile        = "env"              // 读取环境变量的提供者，生成到组件所在包
+	sharedFile     = "shared_providers" // 注册到多个 Set 的组件（共享 Set），生成到生成路径与输出目录
+	fxProviderFile = "fx"               // fx 后端的 Provide 函数，生成到组件所在包
+)
+
+// initFile 初始化入口文件名，固定生成到生成路径，不受前缀与后缀配置影响.
+const initFile = "wire.gen.go"
+
+// generatedMarker 生成文件的标记，删除过期文件时只删除带该标记的文件.
+const generatedMarker = "// Code generated by go-autowire. DO NOT EDIT."
+
+// genFileName method    返回生成文件的文件名（不含目录）：<前缀>_<名称><后缀>.go，如 animals 返回 autowire_animals.go.
+func (sc *AutoWireSearcher) genFileName(name string) string {
+	return cmp.Or(sc.filePrefix, config.FilePrefix) + "_" + name + sc.fileSuffix + ".go"
+}
+
+// isCleanable function    判断生成路径中的文件是否参与过期清理：只处理 .go 文件，
+// 生成清单与初始化入口文件由各自的写入步骤维护，不参与清理.
+func isCleanable(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".go") &&
+		!parser.NameEqual(name, StampFileName) && !parser.NameEqual(name, initFile)
+}
+
+// isGenerated function    判断文件内容是否由 gutowire 生成：package 子句之前的注释中带有生成标记
+// 配置了头部注释时生成标记不在第一行.
+func isGenerated(data []byte) bool {
+	for line := range bytes.Lines(data) {
+		line = bytes.TrimSpace(line)
+		switch {
+		case string(line) == generatedMarker:
+			return true
+		case bytes.HasPrefix(line, []byte("package ")):
+			return false
+		}
+	}
+	return false
+}
+
+// headerData struct    头部注释模板的数据.
+type headerData struct {
+	File string // 生成文件名（不含目录），如 zz_wire_animals.go
+}
+
+// loadHeader method    读取并解析头部注释模板，未配置时返回 nil.
+func (sc *AutoWireSearcher) loadHeader() (*template.Template, error) {
+	if sc.headerTemplate == "" {
+		return nil, nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(sc.headerTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("读取头部注释模板失败: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(sc.headerTemplate)).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析头部注释模板 %s 失败: %w", sc.headerTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// header method    返回生成文件的头部注释（以空行结尾），未配置头部注释模板时为空
+// 模板的输出需要是 Go 注释，否则返回错误.
+func (sc *AutoWireSearcher) header(fileName string) ([]byte, error) {
+	if sc.headerTmpl == nil {
+		return nil, nil
+	}
+	tmpl, err := sc.headerTmpl()
+	if tmpl == nil || err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, headerData{File: filepath.Base(fileName)}); err != nil {
+		return nil, fmt.Errorf("执行头部注释模板失败: %w", err)
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return nil, nil
+	}
+	// 头部只能包含注释：与 package 子句一起解析，注释之外的内容会导致语法错误
+	if _, err := goparser.ParseFile(token.NewFileSet(), "", text+"\n\npackage p\n", 0); err != nil {
+		return nil, fmt.Errorf("头部注释模板 %s 的输出只能包含 Go 注释", sc.headerTemplate)
+	}
+	return []byte(text + "\n\n"), nil
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestFileNamingAndHeader(t *testing.T) {
+	dir := t.TempDir()
+	headerFile := filepath.Join(dir, "header.tmpl")
+	header := "// Copyright 2026 Acme Inc.\n// File: {{ .File }}\n"
+	if err := os.WriteFile(headerFile, []byte(header), 0600); err != nil {
+		t.Fatal(err)
+	}
+	genPath := filepath.Join(dir, "gen")
+	if err := os.MkdirAll(genPath, 0750); err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:             &sync.Mutex{},
+		genPath:        genPath,
+		pkg:            "gen",
+		logger:         logger.Discard(),
+		cache:          NewCacheManager(genPath, false),
+		filePrefix:     "zz_wire",
+		fileSuffix:     "_gen",
+		headerTemplate: headerFile,
+	}
+	sc.headerTmpl = sync.OnceValues(sc.loadHeader)
+
+	elements := map[string]Element{"example.com/zoo/Cat": {Name: "Cat", Pkg: "zoo", PkgPath: "example.com/zoo"}}
+	sc.ElementMap = map[string]map[string]Element{"animals": elements}
+	if err := sc.writeSet("animals", elements); err != nil {
+		t.Fatalf("writeSet() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(genPath, "zz_wire_animals_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// Copyright 2026 Acme Inc.\n// File: zz_wire_animals_gen.go\n\n" +
+		"// Code generated by go-autowire. DO NOT EDIT.\n\n//go:build wireinject\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("header = \n%s\nwant prefix\n%s", data, want)
+	}
+	if !isGenerated(data) {
+		t.Error("isGenerated() = false for a file with a header")
+	}
+
+	// 只删除带生成标记的过期文件（包括修改前缀之前的旧文件名），同样前缀的手写文件保留
+	stale := filepath.Join(genPath, "zz_wire_old_gen.go")
+	oldPrefix := filepath.Join(genPath, "autowire_animals.go")
+	manual := filepath.Join(genPath, "zz_wire_manual_gen.go")
+	for _, file := range []string{stale, oldPrefix} {
+		if err := os.WriteFile(file, []byte(generatedMarker+"\n\npackage gen\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(manual, []byte("package gen\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.clean(sc.expectedFiles()); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{stale, oldPrefix} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("stale generated file %s should be removed", filepath.Base(file))
+		}
+	}
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("hand-written file should be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(genPath, "zz_wire_animals_gen.go")); err != nil {
+		t.Errorf("expected file should be kept: %v", err)
+	}
+}
+
+func TestHeader_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	headerFile := filepath.Join(dir, "header.tmpl")
+	if err := os.WriteFile(headerFile, []byte("Copyright 2026 Acme Inc.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, headerTemplate: headerFile}
+	sc.headerTmpl = sync.OnceValues(sc.loadHeader)
+	if _, err := sc.header("autowire_sets.go"); err == nil || !strings.Contains(err.Error(), "只能包含 Go 注释") {
+		t.Errorf("header() error = %v, want comment-only error", err)
+	}
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// outputGroups method    按输出目录对 ElementMap 分组，返回 输出目录 -> Set 名称 -> 组件
+// 普通 Set 整体生成到其组件指定的输出目录；init 与 config 组件按各自的输出目录分组，
+// 每个输出目录生成独立的 InitSet、ConfigSet 与初始化函数.
+func (sc *AutoWireSearcher) outputGroups() map[string]map[string]map[string]Element {
+	groups := map[string]map[string]map[string]Element{"": {}}
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		if set == mockSet {
+			// 测试替身只生成到测试注入包
+			continue
+		}
+		setOut := ""
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Out == "" || elem.InitWire || elem.ConfigWire {
+				continue
+			}
+			if setOut == "" {
+				setOut = elem.Out
+			} else if setOut != elem.Out {
+				sc.logger.Warn("同一 Set 中的组件指定了不同的输出目录，使用第一个", "set", set, "out", setOut,
+					"element", describeElement(elem))
+			}
+		}
+
+		for key, elem := range sc.ElementMap[set] {
+			out := setOut
+			if elem.InitWire || elem.ConfigWire {
+				out = elem.Out
+			}
+			if groups[out] == nil {
+				groups[out] = make(map[string]map[string]Element)
+			}
+			if groups[out][set] == nil {
+				groups[out][set] = make(map[string]Element)
+			}
+			groups[out][set][key] = elem
+		}
+	}
+	return groups
+}
+
+// writeOutputs method    按输出目录分别生成 Set 文件、汇总文件和初始化文件
+// 未指定输出目录的 Set 生成到生成路径，其余输出目录各自生成一个独立的包.
+func (sc *AutoWireSearcher) writeOutputs() error {
+	groups := sc.outputGroups()
+	sc.outputDirs = nil
+	for _, out := range parser.SortedKeys(groups) {
+		sub := sc.outputSearcher(out, groups[out])
+		if out != "" {
+			sc.outputDirs = append(sc.outputDirs, sub.genPath)
+		} else {
+			// 组合 Set 只生成到生成路径
+			sub.composites = sc.composites
+		}
+		if err := sub.writeOutput(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutputDirs method    返回上次生成时除生成路径外的输出目录（绝对路径），需要同样运行 wire.
+func (sc *AutoWireSearcher) OutputDirs() []string {
+	return slices.Clone(sc.outputDirs)
+}
+
+// outputSearcher method    返回只包含指定组件、生成到输出目录 out 的搜索器，out 为空表示生成路径
+// 复制原搜索器的全部配置与本次生成共享的记录，只替换组件、生成路径与包名，并清空 Set 列表等生成过程中的状态.
+func (sc *AutoWireSearcher) outputSearcher(out string, elementMap map[string]map[string]Element) *AutoWireSearcher {
+	sub := *sc
+	sub.ElementMap = elementMap
+	if out != "" {
+		sub.genPath = filepath.Join(parser.GetGoModDir(), filepath.FromSlash(out))
+		sub.pkg = outputPkgName(sub.genPath)
+	}
+	sub.sets, sub.initElements, sub.configElements = nil, nil, nil
+	sub.composites, sub.outputDirs = nil, nil
+	return &sub
+}
+
+// outputPkgName function    返回输出目录的包名：优先读取目录中已有的 Go 文件，否则使用目录名.
+func outputPkgName(dir string) string {
+	if pkg, err := parser.GetPathGoPkgName(dir); err == nil && pkg != "" {
+		return pkg
+	}
+	return strings.ReplaceAll(filepath.Base(dir), "-", "_")
+}
+
+// writeOutput method    在生成路径中清理过期文件并生成 Set 文件、汇总文件和初始化文件.
+func (sc *AutoWireSearcher) writeOutput() error {
+	// 没有任何组件且目录不存在时无需生成，也没有需要清理的文件
+	if len(sc.ElementMap) == 0 && len(sc.composites) == 0 {
+		if _, err := os.Stat(sc.genPath); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	// 确保目标目录存在
+	if err := sc.ensureGenPath(); err != nil {
+		return err
+	}
+
+	// 清理过期的文件（本次不再生成的 Set）
+	if err := sc.clean(sc.expectedFiles()); err != nil {
+		return fmt.Errorf("清理旧文件失败: %w", err)
+	}
+
+	// 并发生成共享 Set 与每个 Set 的文件
+	g := sc.group()
+	if shared := sc.sharedElements(); len(shared) > 0 {
+		g.Go(func() error {
+			return sc.writeSharedSet(shared)
+		})
+	}
+	for set, m := range sc.ElementMap {
+		g.Go(func() error {
+			return sc.writeSet(set, m)
+		})
+	}
+
+	// 等待所有 Set 文件生成完成
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("生成 Set 文件失败: %w", err)
+	}
+
+	// 生成组合 Set、汇总文件和初始化文件
+	if err := sc.writeComposites(); err != nil {
+		return fmt.Errorf("生成组合 Set 文件失败: %w", err)
+	}
+	return sc.writeSets()
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestOutputGroups(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{
+			"svc":     {"A": {Name: "A"}, "B": {Name: "B"}},
+			"billing": {"C": {Name: "C", Out: "internal/billing"}, "D": {Name: "D"}},
+			"app":     {"App": {Name: "App", InitWire: true, Out: "cmd/app"}, "E": {Name: "E"}},
+		},
+		logger: logger.Discard(),
+	}
+
+	groups := sc.outputGroups()
+	want := map[string][]string{
+		"":                 {"app", "svc"},
+		"cmd/app":          {"app"},
+		"internal/billing": {"billing"},
+	}
+	if got := parser.SortedKeys(groups); !slices.Equal(got, parser.SortedKeys(want)) {
+		t.Fatalf("groups = %v", got)
+	}
+	for out, sets := range want {
+		if got := parser.SortedKeys(groups[out]); !slices.Equal(got, sets) {
+			t.Errorf("groups[%q] = %v, want %v", out, got, sets)
+		}
+	}
+	// 整个 Set 跟随其组件指定的输出目录，init 组件按自身的输出目录分组
+	if len(groups["internal/billing"]["billing"]) != 2 {
+		t.Errorf("billing = %v", groups["internal/billing"]["billing"])
+	}
+	if _, ok := groups["cmd/app"]["app"]["App"]; !ok || len(groups[""]["app"]) != 1 {
+		t.Errorf("app = %v / %v", groups["cmd/app"]["app"], groups[""]["app"])
+	}
+}
+
+func TestOutputSearcher(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu:           &sync.Mutex{},
+		genPath:      "wire",
+		pkg:          "wire",
+		sets:         []string{"SvcSet"},
+		composites:   []Element{{Name: "app", Composite: true}},
+		onlySets:     []string{"svc"},
+		setBuildTags: map[string]string{"svc": "linux"},
+		fileSuffix:   "_gen",
+	}
+	elementMap := map[string]map[string]Element{"svc": {"A": {Name: "A"}}}
+
+	// 只替换组件、生成路径与包名，其余配置与原搜索器一致，生成过程中的状态被清空
+	sub := sc.outputSearcher("", elementMap)
+	if sub.genPath != "wire" || sub.pkg != "wire" || len(sub.ElementMap) != 1 || sub.mu != sc.mu {
+		t.Errorf("outputSearcher() = %+v", sub)
+	}
+	if sub.fileSuffix != "_gen" || sub.setBuildTags["svc"] != "linux" || !slices.Equal(sub.onlySets, sc.onlySets) {
+		t.Errorf("outputSearcher() 未复制配置: %+v", sub)
+	}
+	if len(sub.sets) != 0 || len(sub.composites) != 0 || len(sc.sets) != 1 {
+		t.Errorf("sets = %v, composites = %v", sub.sets, sub.composites)
+	}
+}
@@ -0,0 +1,360 @@
+package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// packageIndex struct    按目录缓存的包信息，同一次扫描中每个包只加载一次
+// 预先登记的目录（见 preloadPackages）在首次使用时通过一次 packages.Load 一并加载.
+type packageIndex struct {
+	mu    sync.Mutex
+	dirs  map[string]*packageInfo
+	files map[*ast.File]*packageInfo // 解析的源文件与包中的语法树 -> 所在的包
+
+	batch     []string // 预先登记的目录（绝对路径）
+	batchOnce sync.Once
+}
+
+// packageInfo struct    目录中满足构建约束的非测试 Go 文件及其顶层声明，首次使用时加载.
+type packageInfo struct {
+	once   sync.Once
+	files  []string               // 包文件（绝对路径）
+	syntax []*ast.File            // 包文件的语法树
+	decls  map[string]packageDecl // 顶层函数、类型、变量与常量名称 -> 声明
+}
+
+// packageDecl struct    包中的顶层声明，通过 types.Info 与语法树中的声明对应.
+type packageDecl struct {
+	obj  types.Object // 类型检查得到的对象，如 *types.Func、*types.TypeName
+	node ast.Node     // 声明：*ast.FuncDecl、*ast.TypeSpec 或 *ast.ValueSpec
+	file *ast.File    // 声明所在的文件
+}
+
+// loadedPackage struct    加载得到的单个包.
+type loadedPackage struct {
+	files  []string    // 包文件（绝对路径）
+	syntax []*ast.File // 语法树
+	info   *types.Info // 类型信息
+}
+
+// preloadPackages method    登记本次扫描需要的包目录，首次查找其中任一目录时通过一次 packages.Load 一并加载.
+func (sc *AutoWireSearcher) preloadPackages(dirs []string) {
+	idx := sc.packages
+	if idx == nil || len(dirs) == 0 {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, dir := range dirs {
+		if dir = absPath(dir); !slices.Contains(idx.batch, dir) {
+			idx.batch = append(idx.batch, dir)
+		}
+	}
+}
+
+// packageInfo method    返回目录的包信息，结果按目录缓存.
+func (sc *AutoWireSearcher) packageInfo(dir string) *packageInfo {
+	dir = absPath(dir)
+	idx := sc.packages
+	if idx == nil {
+		idx = &packageIndex{}
+	}
+	idx.mu.Lock()
+	batched := slices.Contains(idx.batch, dir)
+	batch := idx.batch
+	idx.mu.Unlock()
+	if batched {
+		idx.batchOnce.Do(func() {
+			for d, pkg := range sc.loadPackages(batch) {
+				idx.set(d, pkg)
+			}
+		})
+	}
+
+	info := idx.info(dir)
+	info.once.Do(func() {
+		// 未预先登记的目录（如监听模式下新增的目录、接口实现所在的目录）单独加载
+		info.fill(sc.loadPackages([]string{dir})[dir])
+		idx.bindFiles(info.syntax, info)
+	})
+	return info
+}
+
+// info method    返回目录对应的包信息，不存在时创建.
+func (idx *packageIndex) info(dir string) *packageInfo {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.dirs == nil {
+		idx.dirs = make(map[string]*packageInfo)
+	}
+	info, ok := idx.dirs[dir]
+	if !ok {
+		info = &packageInfo{}
+		idx.dirs[dir] = info
+	}
+	return info
+}
+
+// set method    记录批量加载得到的包.
+func (idx *packageIndex) set(dir string, pkg loadedPackage) {
+	info := idx.info(dir)
+	info.once.Do(func() {
+		info.fill(pkg)
+		idx.bindFiles(info.syntax, info)
+	})
+}
+
+// fill method    使用加载得到的包填充包信息.
+func (info *packageInfo) fill(pkg loadedPackage) {
+	info.files, info.syntax, info.decls = pkg.files, pkg.syntax, indexDecls(pkg.syntax, pkg.info)
+}
+
+// bindFiles method    记录语法树所在的包，在其他文件中的声明里继续查找时使用.
+func (idx *packageIndex) bindFiles(files []*ast.File, info *packageInfo) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.files == nil {
+		idx.files = make(map[*ast.File]*packageInfo)
+	}
+	for _, f := range files {
+		idx.files[f] = info
+	}
+}
+
+// packageGoFiles method    返回目录中满足构建约束的非测试 Go 文件（绝对路径）.
+func (sc *AutoWireSearcher) packageGoFiles(dir string) []string {
+	return sc.packageInfo(dir).files
+}
+
+// loadPackages method    通过一次 packages.Load 按目标平台与构建标签加载目录中的包（语法树与类型信息），
+// 返回 目录 -> 包；目录不在模块中或 go list 失败时回退为读取目录、逐个文件评估构建约束并单独做类型检查.
+func (sc *AutoWireSearcher) loadPackages(dirs []string) map[string]loadedPackage {
+	fset := sc.fileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Context: sc.ctx,
+		Dir:     dirs[0],
+		Fset:    fset,
+		// 不下载缺失的依赖
+		Env: append(os.Environ(), "GOPROXY=off"),
+		// 依赖从源码做类型检查（不依赖可能无法生成的导出数据），只需要声明，去掉函数体以加快类型检查
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			mode := goparser.AllErrors | goparser.ParseComments | goparser.SkipObjectResolution
+			if slices.Contains(dirs, filepath.Dir(filename)) {
+				return goparser.ParseFile(fset, filename, src, mode)
+			}
+			f, err := goparser.ParseFile(fset, filename, src, goparser.SkipObjectResolution)
+			if f == nil {
+				return nil, err
+			}
+			for _, d := range f.Decls {
+				if fd, ok := d.(*ast.FuncDecl); ok {
+					fd.Body = nil
+				}
+			}
+			return f, err
+		},
+	}
+	if sc.buildCtx != nil {
+		cfg.Env = append(cfg.Env, "GOOS="+sc.buildCtx.GOOS, "GOARCH="+sc.buildCtx.GOARCH,
+			"CGO_ENABLED="+strconv.Itoa(boolInt(sc.buildCtx.CgoEnabled)))
+		if len(sc.buildCtx.BuildTags) > 0 {
+			cfg.BuildFlags = []string{"-tags=" + strings.Join(sc.buildCtx.BuildTags, ",")}
+		}
+	}
+
+	result := make(map[string]loadedPackage, len(dirs))
+	pkgs, err := packages.Load(cfg, dirs...)
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) == 0 || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		dir := filepath.Dir(pkg.GoFiles[0])
+		if slices.Contains(dirs, dir) {
+			result[dir] = loadedPackage{files: pkg.GoFiles, syntax: pkg.Syntax, info: pkg.TypesInfo}
+		}
+	}
+
+	for _, dir := range dirs {
+		if _, ok := result[dir]; ok {
+			continue
+		}
+		if sc.logger != nil {
+			sc.logger.Debug("go/packages 无法加载包，改为读取目录", "dir", dir, "error", err)
+		}
+		files := sc.readPackageFiles(dir)
+		syntax := parsePackageFiles(fset, files)
+		result[dir] = loadedPackage{files: files, syntax: syntax, info: checkFiles(fset, syntax)}
+	}
+	return result
+}
+
+// readPackageFiles method    读取目录，返回满足构建约束的非测试 Go 文件（绝对路径）.
+func (sc *AutoWireSearcher) readPackageFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		file := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && parser.CheckFileType(entry.Name()) && sc.matchBuild(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// parsePackageFiles function    解析包文件，包名与第一个文件不一致的文件（如 package main 的工具文件）不解析.
+func parsePackageFiles(fset *token.FileSet, files []string) []*ast.File {
+	var syntax []*ast.File
+	for _, file := range files {
+		f, err := goparser.ParseFile(fset, file, nil, goparser.ParseComments|goparser.SkipObjectResolution)
+		if err != nil || (len(syntax) > 0 && f.Name.Name != syntax[0].Name.Name) {
+			continue
+		}
+		syntax = append(syntax, f)
+	}
+	return syntax
+}
+
+// checkFiles function    对语法树做类型检查，忽略错误（无法导入的包的类型视为无效），只用于解析包中的顶层声明.
+func checkFiles(fset *token.FileSet, files []*ast.File) *types.Info {
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	if len(files) == 0 {
+		return info
+	}
+	conf := types.Config{Error: func(error) {}}
+	_, _ = conf.Check(files[0].Name.Name, fset, files, info)
+	return info
+}
+
+// indexDecls function    收集包中的顶层函数（不含方法）、类型、变量与常量声明，对象取自 types.Info.
+func indexDecls(files []*ast.File, info *types.Info) map[string]packageDecl {
+	decls := make(map[string]packageDecl)
+	if info == nil {
+		return decls
+	}
+	add := func(ident *ast.Ident, node ast.Node, f *ast.File) {
+		if obj := info.Defs[ident]; obj != nil && obj.Parent() != nil && obj.Parent().Parent() == types.Universe {
+			decls[ident.Name] = packageDecl{obj: obj, node: node, file: f}
+		}
+	}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					add(d.Name, d, f)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						add(s.Name, s, f)
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							add(name, s, f)
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// fileSet method    返回本次扫描共享的文件集合，未初始化时（如测试中直接构造的搜索器）返回新的文件集合
+// token.FileSet 可以并发使用，各文件的位置在整个扫描中唯一.
+func (sc *AutoWireSearcher) fileSet() *token.FileSet {
+	if sc.fset == nil {
+		return token.NewFileSet()
+	}
+	return sc.fset
+}
+
+// fileSetOf method    返回包含文件位置的文件集合：文件不在共享的文件集合中时（如测试中使用单独的文件集合解析），
+// 返回按文件位置登记的新文件集合，类型检查只需要文件的位置范围.
+func (sc *AutoWireSearcher) fileSetOf(f *ast.File) *token.FileSet {
+	if sc.fset != nil && sc.fset.File(f.Pos()) != nil {
+		return sc.fset
+	}
+	fset := token.NewFileSet()
+	fset.AddFile("", int(f.FileStart), int(f.FileEnd-f.FileStart))
+	return fset
+}
+
+// boolInt function    将布尔值转换为 0 或 1.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// bindPackage method    记录源文件所在的包，之后在文件中查找声明时包括同一包中其他文件的声明
+// 构造函数（New<Name>、Init<Name>、new= 指定的函数）以及 fx 使用的类型可以声明在包中的任意文件中.
+func (sc *AutoWireSearcher) bindPackage(f *ast.File, file string) {
+	if sc.packages == nil {
+		return
+	}
+	sc.packages.bindFiles([]*ast.File{f}, sc.packageInfo(filepath.Dir(file)))
+}
+
+// fileInfo method    返回文件所在的包；未记录所在包的文件（如测试中直接解析的文件）只包含文件自身.
+func (sc *AutoWireSearcher) fileInfo(f *ast.File) *packageInfo {
+	var info *packageInfo
+	if idx := sc.packages; idx != nil {
+		idx.mu.Lock()
+		info = idx.files[f]
+		idx.mu.Unlock()
+	}
+	if info == nil {
+		syntax := []*ast.File{f}
+		info = &packageInfo{syntax: syntax, decls: indexDecls(syntax, checkFiles(sc.fileSetOf(f), syntax))}
+		if sc.packages != nil {
+			sc.packages.bindFiles(syntax, info)
+		}
+	}
+	return info
+}
+
+// lookupDecl method    查找文件所在包中的顶层声明.
+func (sc *AutoWireSearcher) lookupDecl(f *ast.File, name string) (packageDecl, bool) {
+	d, ok := sc.fileInfo(f).decls[name]
+	return d, ok
+}
+
+// findFuncDecl method    在文件所在包中查找函数声明（不含方法）.
+func (sc *AutoWireSearcher) findFuncDecl(f *ast.File, name string) *ast.FuncDecl {
+	if d, ok := sc.lookupDecl(f, name); ok {
+		if _, isFunc := d.obj.(*types.Func); isFunc {
+			fd, _ := d.node.(*ast.FuncDecl)
+			return fd
+		}
+	}
+	return nil
+}
+
+// declFile method    返回名称对应的顶层声明所在的文件：同一包中其他文件的声明为该文件，否则为 f
+// 构造函数参数等类型表达式中的包名需要按声明所在文件的导入解析.
+func (sc *AutoWireSearcher) declFile(f *ast.File, name string) *ast.File {
+	if d, ok := sc.lookupDecl(f, name); ok {
+		return d.file
+	}
+	return f
+}
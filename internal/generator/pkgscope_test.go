@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestPackageScope(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/m\n\ngo 1.21\n")
+	write("zoo.go", "package m\n\n// @autowire(set=svc)\ntype Zoo struct{}\n\n"+
+		"// @autowire(set=svc,new=MakeShop)\ntype Shop struct{}\n\n"+
+		"// @autowire(set=svc,new=OpenSource)\ntype File struct{}\n")
+	// 构造函数与其参数类型的导入都在另一个文件中
+	write("ctor.go", "package m\n\nimport \"database/sql\"\n\n"+
+		"func NewZoo(db *sql.DB) *Zoo { return &Zoo{} }\n\nfunc MakeShop() *Shop { return &Shop{} }\n")
+	// 返回值的底层类型为其他包的接口，按类型信息判断为接口
+	write("source.go", "package m\n\nimport \"io\"\n\ntype Source io.Reader\n\n"+
+		"func OpenSource() Source { return nil }\n")
+	// 不满足构建约束的文件不参与查找，否则 InitZoo 优先于 NewZoo
+	write("zoo_windows.go", "//go:build windows\n\npackage m\n\nfunc InitZoo() *Zoo { return nil }\n")
+
+	o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+		config.WithLogger(logger.Discard()), config.WithTarget("linux", ""))
+	sc := NewAutoWireSearcher(o, "example.com/m")
+	if err := sc.SearchAllPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	elements := sc.ElementMap["svc"]
+	zoo, shop := findElement(elements, "Zoo"), findElement(elements, "Shop")
+	if zoo.Constructor != "NewZoo" || !slices.Equal(zoo.Deps, []string{"database/sql.DB"}) {
+		t.Errorf("Zoo: Constructor = %q, Deps = %v", zoo.Constructor, zoo.Deps)
+	}
+	if shop.Constructor != "MakeShop" {
+		t.Errorf("Shop: Constructor = %q, want MakeShop", shop.Constructor)
+	}
+	if file := findElement(elements, "File"); file.Constructor != "OpenSource" || file.BadResult != "" {
+		t.Errorf("File: Constructor = %q, BadResult = %q", file.Constructor, file.BadResult)
+	}
+	if !slices.Contains(sc.packages.batch, absPath(dir)) {
+		t.Errorf("batch = %v, want the annotated package preloaded", sc.packages.batch)
+	}
+
+	// 只修改构造函数所在的文件，带注解的文件同样重新解析
+	write("ctor.go", "package m\n\nfunc MakeShop() *Shop { return &Shop{} }\n")
+	if err := sc.Rescan(filepath.Join(dir, "ctor.go")); err != nil {
+		t.Fatal(err)
+	}
+	if zoo := findElement(sc.ElementMap["svc"], "Zoo"); zoo.Constructor != "" {
+		t.Errorf("Zoo: Constructor = %q after NewZoo was removed", zoo.Constructor)
+	}
+}
+
+// findElement function    按名称查找组件.
+func findElement(elements map[string]Element, name string) Element {
+	for _, e := range elements {
+		if e.Name == name {
+			return e
+		}
+	}
+	return Element{}
+}
@@ -0,0 +1,271 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// qualifierTemplateHead 组件包中生成文件（限定类型、fx Provide 函数）的头部模板.
+var qualifierTemplateHead = `// Code generated by go-autowire. DO NOT EDIT.
+
+package %s
+
+import (
+%s
+)
+`
+
+// applyQualifier method    为带 qualifier= 参数的组件生成限定类型
+// 绑定了接口时为每个接口生成 type <Qualifier><Interface> <Interface>，并改为绑定限定接口；
+// 否则为构造函数（方法工厂为其包装函数）的返回类型生成包装结构体 <Qualifier><Type> 及包装构造函数 Provide<Qualifier><Type>.
+func (sc *AutoWireSearcher) applyQualifier(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string) {
+	if wireElement.Qualifier == "" {
+		return
+	}
+	qualifier := strcase.UpperCamelCase(wireElement.Qualifier)
+	r := typeResolver{file: f, pkgPath: pkgPath}
+
+	switch {
+	case len(wireElement.Implements) > 0:
+		for i, itf := range wireElement.Implements {
+			name := qualifier + itf[strings.LastIndex(itf, ".")+1:]
+			wireElement.Qualified = append(wireElement.Qualified,
+				fmt.Sprintf("// %s 由 @autowire(qualifier=%s) 生成的限定接口.\ntype %s %s",
+					name, wireElement.Qualifier, name, itf))
+			wireElement.Provides = replaceItem(wireElement.Provides, r.qualifyName(itf), pkgPath+"."+name)
+			wireElement.Implements[i] = name
+			if kind, ok := wireElement.BindKinds[itf]; ok {
+				delete(wireElement.BindKinds, itf)
+				wireElement.BindKinds[name] = kind
+			}
+		}
+	case wireElement.Constructor != "" && !wireElement.ValueWire:
+		var fd *ast.FuncDecl
+		if decl.method != nil {
+			// 方法工厂：包装生成的 Provide<Receiver><Method>，接收者为第一个参数
+			fd = methodProviderDecl(wireElement.Constructor, decl.method)
+		} else {
+			fd = sc.findFuncDecl(f, wireElement.Constructor)
+			// 构造函数可以声明在包中的其他文件中，包装代码使用构造函数所在文件的导入
+			f = sc.declFile(f, wireElement.Constructor)
+			r = typeResolver{file: f, pkgPath: pkgPath}
+		}
+		if fd == nil || !sc.qualifyConstructor(wireElement, fd, qualifier, r) {
+			sc.logger.Warn("无法为构造函数生成限定类型，忽略 qualifier", "element", describeElement(*wireElement))
+			return
+		}
+	default:
+		sc.logger.Warn("qualifier 需要绑定接口或提供构造函数，已忽略", "element", describeElement(*wireElement))
+		return
+	}
+
+	wireElement.Imports = fileImports(f)
+}
+
+// methodProviderDecl function    返回方法工厂包装函数 name 的声明：接收者作为第一个参数，其余参数与返回值与方法一致.
+func methodProviderDecl(name string, method *ast.FuncDecl) *ast.FuncDecl {
+	recv := &ast.Field{Names: []*ast.Ident{ast.NewIdent("r")}, Type: method.Recv.List[0].Type}
+	return &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: append([]*ast.Field{recv}, method.Type.Params.List...)},
+			Results: method.Type.Results,
+		},
+	}
+}
+
+// fileImports function    返回文件的导入声明，如 "fmt"、sq "database/sql".
+func fileImports(f *ast.File) []string {
+	return parser.Map(f.Imports, func(imp *ast.ImportSpec) string {
+		if imp.Name != nil {
+			return imp.Name.Name + " " + imp.Path.Value
+		}
+		return imp.Path.Value
+	})
+}
+
+// qualifyConstructor method    为构造函数生成包装结构体与包装构造函数，返回是否生成成功
+// 包装构造函数的参数与原构造函数一致，cleanup 与 error 原样返回.
+func (sc *AutoWireSearcher) qualifyConstructor(wireElement *Element, fd *ast.FuncDecl, qualifier string,
+	r typeResolver) bool {
+	results := fd.Type.Results
+	if fd.Type.TypeParams != nil || results == nil || len(results.List) == 0 || len(results.List[0].Names) > 1 {
+		return false
+	}
+	base := embedName(results.List[0].Type)
+	if base == "" {
+		return false
+	}
+	name := qualifier + base
+	resultType := types.ExprString(results.List[0].Type)
+
+	params, args := funcParams(fd)
+
+	// 返回值：v 为原返回值，其余仅支持 cleanup 与 error
+	vars, rets, outs := []string{"v"}, []string{name + "{v}"}, []string{name}
+	for _, field := range results.List[1:] {
+		switch t := field.Type.(type) {
+		case *ast.FuncType:
+			vars, rets, outs = append(vars, "cleanup"), append(rets, "cleanup"), append(outs, "func()")
+		case *ast.Ident:
+			if t.Name != "error" {
+				return false
+			}
+			vars, rets, outs = append(vars, "err"), append(rets, "err"), append(outs, "error")
+		default:
+			return false
+		}
+	}
+
+	provider := "Provide" + name
+	call := fmt.Sprintf("%s(%s)", fd.Name.Name, strings.Join(args, ", "))
+	body := fmt.Sprintf("\treturn %s{%s}", name, call)
+	if len(vars) > 1 {
+		body = fmt.Sprintf("\t%s := %s\n\treturn %s", strings.Join(vars, ", "), call, strings.Join(rets, ", "))
+	}
+	outList := outs[0]
+	if len(outs) > 1 {
+		outList = "(" + strings.Join(outs, ", ") + ")"
+	}
+
+	wireElement.Qualified = append(wireElement.Qualified,
+		fmt.Sprintf("// %s 由 @autowire(qualifier=%s) 生成的限定类型.\ntype %s struct {\n\t%s\n}",
+			name, wireElement.Qualifier, name, resultType),
+		fmt.Sprintf("// %s 返回 %s 包装的 %s.\nfunc %s(%s) %s {\n%s\n}",
+			provider, fd.Name.Name, name, provider, strings.Join(params, ", "), outList, body))
+	wireElement.Provides = replaceItem(wireElement.Provides, r.typeKey(results.List[0].Type), r.pkgPath+"."+name)
+	wireElement.Constructor = provider
+	wireElement.Result = name
+	return true
+}
+
+// funcParams function    返回包装函数的参数声明与调用参数
+// 参数统一命名为 p0、p1...，可变参数调用时展开.
+func funcParams(fd *ast.FuncDecl) (params, args []string) {
+	for _, field := range fd.Type.Params.List {
+		n := max(len(field.Names), 1)
+		for range n {
+			p := fmt.Sprintf("p%d", len(params))
+			params = append(params, p+" "+types.ExprString(field.Type))
+			if _, ok := field.Type.(*ast.Ellipsis); ok {
+				p += "..."
+			}
+			args = append(args, p)
+		}
+	}
+	return params, args
+}
+
+// embedName function    返回可嵌入结构体的类型名，如 *sql.DB 返回 DB；无法嵌入时返回空字符串.
+func embedName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return ""
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// replaceItem function    将列表中的 old 替换为 item，不存在时追加.
+func replaceItem(list []string, old, item string) []string {
+	list = slices.DeleteFunc(list, func(s string) bool { return s == old })
+	return appendUnique(list, item)
+}
+
+// writeQualifiers method    在组件所在的包目录中生成 autowire_qualifier.go
+// 扫描过的目录中不再需要限定类型时删除旧文件.
+func (sc *AutoWireSearcher) writeQualifiers() error {
+	return sc.writeElementDecls(sc.genFileName(qualifierFile), func(elem Element) []string { return elem.Qualified })
+}
+
+// writeElementDecls method    将组件记录的声明（decls 返回）生成到组件所在包目录中名为 name 的文件.
+func (sc *AutoWireSearcher) writeElementDecls(name string, decls func(Element) []string) error {
+	files := make(map[string]*sourceFile)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if len(decls(elem)) == 0 || !elem.Position.IsValid() {
+				continue
+			}
+			addSourceDecls(files, elem, elem.Imports, decls(elem))
+		}
+	}
+	return sc.writeSourceFiles(name, files)
+}
+
+// sourceFile struct    生成在组件所在包目录中的文件内容.
+type sourceFile struct {
+	pkg     string   // 包名
+	imports []string // 导入声明，未使用的导入写入时会被移除
+	decls   []string // 声明的源码
+}
+
+// addSourceDecls function    将声明追加到组件所在目录对应的文件中，忽略重复项.
+func addSourceDecls(files map[string]*sourceFile, elem Element, imports, decls []string) {
+	dir := filepath.Dir(elem.Position.Filename)
+	sf, ok := files[dir]
+	if !ok {
+		sf = &sourceFile{pkg: elem.Pkg}
+		files[dir] = sf
+	}
+	for _, imp := range imports {
+		sf.imports = appendUnique(sf.imports, imp)
+	}
+	for _, decl := range decls {
+		sf.decls = appendUnique(sf.decls, decl)
+	}
+}
+
+// writeSourceFiles method    在组件所在的包目录中生成名为 name 的文件
+// files 为 目录 -> 文件内容，扫描过的目录中不再需要该文件时删除旧文件
+// 生成路径中的同名文件可能是 Set 文件（如 factory Set 的 autowire_factory.go），不删除.
+func (sc *AutoWireSearcher) writeSourceFiles(name string, files map[string]*sourceFile) error {
+	genPath := absPath(sc.genPath)
+	for _, dir := range parser.SortedKeys(sc.scannedDirs) {
+		fileName := filepath.Join(dir, name)
+		sf, ok := files[dir]
+		if !ok {
+			if rel, _ := parser.RelPath(genPath, absPath(dir)); rel != "." {
+				sc.removeGeneratedFile(fileName)
+			}
+			continue
+		}
+		slices.Sort(sf.imports)
+		src := fmt.Sprintf(qualifierTemplateHead, sf.pkg, "\t"+strings.Join(sf.imports, "\n\t")) +
+			"\n" + strings.Join(sf.decls, "\n\n") + "\n"
+		sc.logger.Debug("正在生成组件包中的文件", "file", fileName)
+		if err := sc.writeIfChanged(fileName, []byte(src), func() error {
+			return sc.writeGenerated(fileName, []byte(src))
+		}); err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %w", fileName, err)
+		}
+	}
+	return nil
+}
+
+// removeGeneratedFile method    删除由 gutowire 生成的文件，非生成文件保持不变.
+func (sc *AutoWireSearcher) removeGeneratedFile(fileName string) {
+	//nolint:gosec
+	data, err := os.ReadFile(fileName)
+	if err != nil || !isGenerated(data) {
+		return
+	}
+	if err := sc.removeFile(fileName); err != nil {
+		sc.logger.Warn("删除文件失败", "file", fileName, "error", err)
+	}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const qualifierSrc = `package db
+
+import "database/sql"
+
+type Store interface{ Get() string }
+
+// @autowire(set=db,Store,qualifier=primary)
+type Postgres struct{}
+
+// @autowire(set=db,qualifier=main)
+func OpenMain(dsn string, opts ...string) (*sql.DB, func(), error) { return nil, nil, nil }
+
+// @autowire(set=db,qualifier=broken)
+func Name() string { return "" }
+
+type Pool struct{}
+
+// @autowire(set=db,qualifier=replica)
+func (p *Pool) Open(dsn string) (*sql.DB, error) { return nil, nil }
+`
+
+func TestApplyQualifier(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "db.go")
+	if err := os.WriteFile(file, []byte(qualifierSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, qualifierSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+	}
+	sc.scannedDirs = map[string]struct{}{dir: {}}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/db", f, getImplement(f))
+
+	byName := make(map[string]Element)
+	for _, e := range elements {
+		byName[e.Name] = e
+	}
+
+	pg := byName["Postgres"]
+	if !slices.Equal(pg.Implements, []string{"PrimaryStore"}) {
+		t.Errorf("Postgres.Implements = %v, want [PrimaryStore]", pg.Implements)
+	}
+	if !slices.Contains(pg.Provides, "example.com/db.PrimaryStore") ||
+		slices.Contains(pg.Provides, "example.com/db.Store") {
+		t.Errorf("Postgres.Provides = %v", pg.Provides)
+	}
+
+	open := byName["OpenMain"]
+	if open.Constructor != "ProvideMainDB" || open.Result != "MainDB" {
+		t.Errorf("OpenMain 构造函数 = %s, 返回类型 = %s", open.Constructor, open.Result)
+	}
+	if !slices.Contains(open.Provides, "example.com/db.MainDB") {
+		t.Errorf("OpenMain.Provides = %v", open.Provides)
+	}
+
+	// 方法工厂包装生成的 Provide<Receiver><Method>
+	replica := byName["PoolOpen"]
+	if replica.Constructor != "ProvideReplicaDB" || !slices.Contains(replica.Provides, "example.com/db.ReplicaDB") ||
+		!slices.Contains(replica.Deps, "example.com/db.Pool") {
+		t.Errorf("PoolOpen = %+v", replica)
+	}
+
+	if broken := byName["Name"]; len(broken.Qualified) != 0 || broken.Constructor != "Name" {
+		t.Errorf("无法嵌入的返回类型应忽略 qualifier: %+v", broken)
+	}
+
+	if err := sc.writeQualifiers(); err != nil {
+		t.Fatalf("writeQualifiers() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, sc.genFileName(qualifierFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"type PrimaryStore Store",
+		"type MainDB struct {\n\t*sql.DB\n}",
+		"func ProvideMainDB(p0 string, p1 ...string) (MainDB, func(), error) {\n" +
+			"\tv, cleanup, err := OpenMain(p0, p1...)\n\treturn MainDB{v}, cleanup, err\n}",
+		"func ProvideReplicaDB(p0 *Pool, p1 string) (ReplicaDB, error) {\n" +
+			"\tv, err := ProvidePoolOpen(p0, p1)\n\treturn ReplicaDB{v}, err\n}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%s 缺少 %q:\n%s", sc.genFileName(qualifierFile), want, out)
+		}
+	}
+
+	// 不再需要限定类型时删除生成的文件
+	sc.ElementMap = make(map[string]map[string]Element)
+	if err := sc.writeQualifiers(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, sc.genFileName(qualifierFile))); !os.IsNotExist(err) {
+		t.Errorf("过期的 %s 应被删除", sc.genFileName(qualifierFile))
+	}
+}
@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+)
+
+// rawIgnoredOptions 对 @autowire.raw 无效的参数：构造方式由表达式决定.
+var rawIgnoredOptions = []string{"init", "config", "new", "of", "fields", "exclude", "lifecycle", "qualifier", "scope",
+	"group"}
+
+// frameworkImports 生成文件自身导入的框架包，表达式中引用这些包时保持原样.
+var frameworkImports = []string{"github.com/google/wire", "go.uber.org/fx"}
+
+// resolveRawExpr method    解析 expr= 参数中的表达式，引用的包与本包的导出标识符记录为完整形式，
+// 生成时按生成文件的导入重新引用，如 db.Open(DefaultDSN) 拆分为 ["", "example.com/db.Open", "(",
+// "example.com/app.DefaultDSN", ")"].
+func (sc *AutoWireSearcher) resolveRawExpr(wireElement *Element, f *ast.File, value string) {
+	src := annotations.Unquote(value)
+	fset := token.NewFileSet()
+	expr, err := goparser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		return
+	}
+	tf := fset.File(expr.Pos())
+
+	var parts []string
+	last := 0
+	addRef := func(from, to token.Pos, ref string) {
+		start, end := tf.Offset(from), tf.Offset(to)
+		parts = append(parts, src[last:start], ref)
+		last = end
+	}
+	r := typeResolver{file: f, pkgPath: wireElement.PkgPath}
+	visitRawRefs(expr, r, func(sel *ast.SelectorExpr, pkgPath string) {
+		if !slices.Contains(frameworkImports, pkgPath) {
+			addRef(sel.Pos(), sel.End(), pkgPath+"."+sel.Sel.Name)
+		}
+	}, func(id *ast.Ident) {
+		// 本包声明的导出标识符，如 NewThing、DefaultConfig
+		if _, ok := sc.lookupDecl(f, id.Name); ok && ast.IsExported(id.Name) {
+			addRef(id.Pos(), id.End(), wireElement.PkgPath+"."+id.Name)
+		}
+	})
+
+	wireElement.Raw = src
+	wireElement.RawExpr = append(parts, src[last:])
+}
+
+// checkRawExpr method    检查 expr= 参数中引用的标识符：生成的 Set 只能引用导入的包、预声明标识符与本包导出的包级声明，
+// 无法解析或未导出的标识符（如 mypkg.ProvideThing(cfg) 中的 cfg）返回问题描述，否则返回空字符串.
+func (sc *AutoWireSearcher) checkRawExpr(f *ast.File, value string) string {
+	expr, err := annotations.ParseExpr(value)
+	if err != nil {
+		return ""
+	}
+	var unresolved, unexported []string
+	visitRawRefs(expr, typeResolver{file: f}, func(*ast.SelectorExpr, string) {}, func(id *ast.Ident) {
+		switch _, ok := sc.lookupDecl(f, id.Name); {
+		case id.Name == "_" || (!ok && types.Universe.Lookup(id.Name) != nil):
+		case !ok:
+			unresolved = appendUnique(unresolved, id.Name)
+		case !ast.IsExported(id.Name):
+			unexported = appendUnique(unexported, id.Name)
+		}
+	})
+	var problems []string
+	if len(unresolved) > 0 {
+		problems = append(problems, "无法解析的标识符 "+strings.Join(unresolved, "、"))
+	}
+	if len(unexported) > 0 {
+		problems = append(problems, "未导出的标识符 "+strings.Join(unexported, "、"))
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return "expr 参数引用了" + strings.Join(problems, "，以及") +
+		"，生成的 Set 中无法引用；请改用导入的包或本包导出的包级声明"
+}
+
+// visitRawRefs function    遍历表达式中引用的标识符：引用导入包的选择器（如 db.Open）调用 pkgRef，
+// 其余标识符调用 ident；选择器右侧的字段与方法名、复合字面量的字段名以及表达式内声明的参数与变量不处理.
+func visitRawRefs(expr ast.Expr, r typeResolver, pkgRef func(*ast.SelectorExpr, string), ident func(*ast.Ident)) {
+	locals := make(map[string]bool)
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			for _, name := range localNames(n) {
+				locals[name] = true
+			}
+		case *ast.SelectorExpr:
+			// pkg.Name 引用导入的包；其他选择器（如 cfg.Field）只处理左侧，字段与方法名保持原样
+			if x, ok := n.X.(*ast.Ident); ok && !locals[x.Name] {
+				if p := r.importPath(x.Name); p != x.Name {
+					pkgRef(n, p)
+					return false
+				}
+			}
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.KeyValueExpr:
+			// 复合字面量中的字段名保持原样
+			if _, ok := n.Key.(*ast.Ident); !ok {
+				ast.Inspect(n.Key, visit)
+			}
+			ast.Inspect(n.Value, visit)
+			return false
+		case *ast.Ident:
+			if !locals[n.Name] {
+				ident(n)
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+}
+
+// localNames function    返回函数字面量中声明的参数、返回值、变量、类型与标签名称.
+func localNames(fn *ast.FuncLit) []string {
+	var names []string
+	addIdents := func(idents ...ast.Expr) {
+		for _, e := range idents {
+			if id, ok := e.(*ast.Ident); ok {
+				names = append(names, id.Name)
+			}
+		}
+	}
+	ast.Inspect(fn, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			for _, id := range n.Names {
+				addIdents(id)
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				addIdents(n.Lhs...)
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				addIdents(n.Key, n.Value)
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				addIdents(id)
+			}
+		case *ast.TypeSpec:
+			addIdents(n.Name)
+		case *ast.LabeledStmt:
+			addIdents(n.Label)
+		}
+		return true
+	})
+	return names
+}
+
+// applyRaw method    处理自定义提供者：组件由表达式提供，不再查找构造函数与注入字段，
+// 提供的类型为注解所在的类型与绑定的接口，依赖无法从表达式中确定，视为没有依赖.
+func (sc *AutoWireSearcher) applyRaw(wireElement *Element, decl *tmpDecl, f *ast.File, pkgPath string,
+	options map[string]string, implementMap map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(options)) {
+		if slices.Contains(rawIgnoredOptions, key) {
+			sc.logger.Warn(key+" 参数对 @autowire.raw 无效，已忽略", "element", describeElement(*wireElement))
+		}
+	}
+	wireElement.Constructor, wireElement.Underlying = "", ""
+	wireElement.Qualifier, wireElement.Scope, wireElement.Group = "", "", ""
+
+	sc.addInterfaceImplementations(wireElement, implementMap, decl.name)
+
+	r := typeResolver{file: f, pkgPath: pkgPath}
+	wireElement.Provides = []string{pkgPath + "." + decl.name}
+	if decl.typeSpec.Assign.IsValid() {
+		wireElement.Provides = appendUnique(wireElement.Provides, r.typeKey(decl.typeSpec.Type))
+	}
+	for _, itf := range wireElement.Implements {
+		wireElement.Provides = appendUnique(wireElement.Provides, r.qualifyName(itf))
+	}
+}
+
+// rawOnly function    判断组件是否为没有绑定接口的自定义提供者：生成代码只使用表达式，
+// 不直接引用组件所在的包，只导入表达式中引用的包.
+func rawOnly(elem *Element) bool {
+	return len(elem.RawExpr) > 0 && len(elem.Implements) == 0
+}
+
+// rawExpr function    按生成文件的导入还原自定义提供者表达式.
+func rawExpr(parts []string, refs *interfaceRefs) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			part = refs.ref(part)
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// elementImport method    返回生成代码引用组件所在的包时需要的导入，没有绑定接口的自定义提供者返回 nil.
+func (sc *AutoWireSearcher) elementImport(elem *Element) []*ast.ImportSpec {
+	if len(elem.Pkg) == 0 || rawOnly(elem) {
+		return nil
+	}
+	return []*ast.ImportSpec{sc.createImportSpec(elem)}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const rawSrc = `package svc
+
+import (
+	"github.com/google/wire"
+	cache "example.com/lib/redis"
+)
+
+type Config struct{ Addr string }
+
+var DefaultAddr = "localhost"
+
+// @autowire.raw(set=svc,expr="cache.Curry(DefaultAddr, Config{Addr: DefaultAddr})")
+type Client struct{}
+
+func NewClient() *Client { return nil }
+
+// @autowire.raw(set=svc,Store,expr="wire.Value(&MemStore{})")
+type MemStore struct{}
+
+// @autowire.raw(set=svc,expr=NewClient)
+func Build() *Client { return nil }
+
+// @autowire(set=svc,expr=NewClient)
+type Plain struct{}
+
+var defaultAddr = ""
+
+// @autowire.raw(set=svc,expr="cache.Open(addr, defaultAddr, func(n int) int { return n })")
+type Bad struct{}
+`
+
+func TestRawElement(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "svc.go", rawSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	elements := sc.parseAnnotations(decls, "svc.go", "example.com/svc", f, getImplement(f))
+
+	// 写在函数声明上的 @autowire.raw 被忽略并报告
+	var names []string
+	for _, elem := range elements {
+		names = append(names, elem.Name)
+	}
+	if got := strings.Join(names, ","); got != "Client,MemStore,Plain,Bad" {
+		t.Fatalf("parseAnnotations() = %s", got)
+	}
+
+	// 表达式中无法解析与未导出的标识符在注解位置报告，函数字面量的参数不报告
+	diags := sc.checkAnnotations(decls, f)
+	if len(diags) != 2 || !strings.Contains(diags[0].Reason, "类型声明") ||
+		diags[1].Position.Line != 28 || !strings.Contains(diags[1].Reason, "无法解析的标识符 addr") ||
+		!strings.Contains(diags[1].Reason, "未导出的标识符 defaultAddr") || strings.Contains(diags[1].Reason, " n") {
+		t.Errorf("checkAnnotations() = %+v", diags)
+	}
+
+	client := elements[0]
+	if client.Constructor != "" || client.Deps != nil ||
+		!slices.Equal(client.Provides, []string{"example.com/svc.Client"}) {
+		t.Errorf("Client = %+v", client)
+	}
+	want := []string{"", "example.com/lib/redis.Curry", "(", "example.com/svc.DefaultAddr", ", ",
+		"example.com/svc.Config", "{Addr: ", "example.com/svc.DefaultAddr", "})"}
+	if !slices.Equal(client.RawExpr, want) {
+		t.Errorf("RawExpr = %q, want %q", client.RawExpr, want)
+	}
+	if plain := elements[2]; plain.Raw != "" || plain.RawExpr != nil {
+		t.Errorf("Plain = %+v, expr 只对 @autowire.raw 有效", plain)
+	}
+
+	refs := newInterfaceRefs("example.com/wire", map[string]Element{"Client": client})
+	var items []string
+	sc.handleNormalWireElement(&client, &items, "svc.Client", refs)
+	store := elements[1]
+	sc.handleNormalWireElement(&store, &items, "svc.MemStore", refs)
+	want = []string{
+		"redis.Curry(svc.DefaultAddr, svc.Config{Addr: svc.DefaultAddr})",
+		"wire.Value(&svc.MemStore{})",
+		"wire.Bind(new(svc.Store), new(*svc.MemStore))",
+	}
+	if !slices.Equal(items, want) {
+		t.Errorf("items = %q, want %q", items, want)
+	}
+	if sc.elementImport(&client) != nil || sc.elementImport(&store) == nil {
+		t.Error("elementImport() 只在绑定接口时导入组件所在的包")
+	}
+}
@@ -0,0 +1,357 @@
+package generator
+
+import (
+	"cmp"
+	"html/template"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Report struct    gutowire report 输出的依赖注入报告.
+type Report struct {
+	Sets       []ReportSet        // 组件的 Set 与组合 Set，按名称排序
+	Bindings   []ReportBinding    // 接口绑定，按接口排序
+	Injectors  []ReportInjector   // 注入入口
+	Unresolved []ReportUnresolved // 注入入口依赖链上没有提供者的类型
+}
+
+// ReportSet struct    报告中的 Set.
+type ReportSet struct {
+	Name       string        // Set 名称
+	Includes   []string      // 组合 Set 包含的 Set，组件的 Set 为空
+	Components []ReportEntry // Set 中的组件
+	Position   ReportSource  // 组合 Set 注解所在的位置
+}
+
+// ReportEntry struct    报告中的单个组件.
+type ReportEntry struct {
+	Component
+	Anchor   string       // 页面内锚点
+	Provides []string     // 提供的类型
+	Deps     []ReportDep  // 依赖的类型
+	Source   ReportSource // 声明在源文件中的位置
+}
+
+// ReportDep struct    组件依赖的类型及其提供者.
+type ReportDep struct {
+	Type     string // 类型（包路径.类型名）
+	Provider string // 第一个提供者的锚点，为空表示没有提供者
+}
+
+// ReportBinding struct    接口及绑定到它的组件.
+type ReportBinding struct {
+	Interface       string      // 接口（包路径.接口名）
+	Implementations []ReportRef // 绑定到接口的组件，多于一个时由优先级或限定名区分
+}
+
+// ReportInjector struct    注入入口及其依赖链上的组件.
+type ReportInjector struct {
+	Name       string      // 初始化函数名称，如 InitializeZoo
+	Result     string      // 初始化函数的返回类型，如 *zoo.Zoo
+	Root       ReportRef   // 入口组件
+	Components []ReportRef // 依赖链上的组件（不含入口本身），按访问顺序
+	Missing    []string    // 依赖链上没有提供者的类型
+}
+
+// ReportUnresolved struct    没有提供者的类型及依赖它的组件.
+type ReportUnresolved struct {
+	Type      string      // 类型（包路径.类型名）
+	Consumers []ReportRef // 依赖该类型的组件
+}
+
+// ReportRef struct    对组件的引用.
+type ReportRef struct {
+	Name   string       // 带包名的组件名称
+	Anchor string       // 组件在报告中的锚点
+	Source ReportSource // 声明在源文件中的位置
+}
+
+// ReportSource struct    源码位置.
+type ReportSource struct {
+	File string // 源文件路径
+	Line int    // 行号，0 表示未知
+}
+
+// ReportOptions struct    HTML 报告的输出选项.
+type ReportOptions struct {
+	Title     string // 报告标题
+	BaseDir   string // 源文件路径相对的目录，用于显示与 SourceURL 中的 {file}
+	SourceURL string // 源码链接模板，支持 {file} 与 {line}，为空时使用 file:// 链接
+}
+
+// Report method    汇总扫描结果：各 Set 的组件、接口绑定、注入入口及其依赖链、没有提供者的类型
+// 需要在 Validate 之后调用.
+func (sc *AutoWireSearcher) Report() Report {
+	var r Report
+	providers := sc.providerIndex()
+
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		rs := ReportSet{Name: set}
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			entry := ReportEntry{
+				Component: newComponent(elem),
+				Anchor:    reportAnchor(elem),
+				Provides:  elem.Provides,
+				Source:    reportSource(elem),
+			}
+			entry.Set = set
+			for _, dep := range elem.Deps {
+				d := ReportDep{Type: dep}
+				if p := providers[dep]; len(p) > 0 {
+					d.Provider = reportAnchor(p[0])
+				}
+				entry.Deps = append(entry.Deps, d)
+			}
+			rs.Components = append(rs.Components, entry)
+		}
+		r.Sets = append(r.Sets, rs)
+	}
+	for _, c := range sc.composites {
+		r.Sets = append(r.Sets, ReportSet{Name: c.Name, Includes: c.Includes, Position: reportSource(c)})
+	}
+	slices.SortFunc(r.Sets, func(a, b ReportSet) int { return strings.Compare(a.Name, b.Name) })
+
+	ifaces := parser.NewSet[string]()
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, elem := range sc.ElementMap[set] {
+			for _, itf := range elem.Implements {
+				ifaces.Add(qualifiedInterface(elem, itf))
+			}
+		}
+	}
+	for _, itf := range parser.SortedKeys(ifaces) {
+		impls := sc.Implementations(itf)
+		if len(impls) == 0 {
+			continue
+		}
+		r.Bindings = append(r.Bindings, ReportBinding{Interface: itf, Implementations: parser.Map(impls, reportRef)})
+	}
+
+	for _, root := range sc.injectorRoots() {
+		inj := ReportInjector{
+			Name:   "Initialize" + appName(root),
+			Result: rootResult(root),
+			Root:   reportRef(root),
+		}
+		// 第一个访问的组件为入口本身
+		first := true
+		walkInjector(root, providers, func(elem Element) {
+			if !first {
+				inj.Components = append(inj.Components, reportRef(elem))
+			}
+			first = false
+		}, func(_ Element, dep string) {
+			inj.Missing = appendUnique(inj.Missing, dep)
+		})
+		r.Injectors = append(r.Injectors, inj)
+	}
+
+	missing := sc.missingDeps()
+	for _, t := range parser.SortedKeys(missing) {
+		r.Unresolved = append(r.Unresolved, ReportUnresolved{Type: t, Consumers: parser.Map(missing[t], reportRef)})
+	}
+	return r
+}
+
+// reportAnchor function    返回组件在报告中的锚点，如 example.com/m/zoo.Zoo 返回 c-example.com-m-zoo.Zoo.
+func reportAnchor(elem Element) string {
+	return "c-" + strings.Map(func(r rune) rune {
+		if r == '.' || r == '_' || r == '-' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '-'
+	}, elem.PkgPath+"."+instanceName(elem))
+}
+
+// reportRef function    返回对组件的引用.
+func reportRef(elem Element) ReportRef {
+	return ReportRef{Name: parser.AppendPkg(elem.Pkg, instanceName(elem)), Anchor: reportAnchor(elem),
+		Source: reportSource(elem)}
+}
+
+// reportSource function    返回组件声明的源码位置.
+func reportSource(elem Element) ReportSource {
+	return ReportSource{File: elem.Position.Filename, Line: elem.Position.Line}
+}
+
+// reportData struct    HTML 报告模板的数据.
+type reportData struct {
+	Report
+	Options    ReportOptions
+	Title      string
+	Components int
+}
+
+// WriteReportHTML function    以单个 HTML 页面输出报告，页面内的组件、接口与注入入口互相链接，
+// 组件链接到源码位置.
+func WriteReportHTML(w io.Writer, r Report, opts ReportOptions) error {
+	data := reportData{Report: r, Options: opts, Title: cmp.Or(opts.Title, "gutowire 依赖注入报告")}
+	for _, set := range r.Sets {
+		data.Components += len(set.Components)
+	}
+	return ReportTemp.Execute(w, data)
+}
+
+// relSource function    返回相对 BaseDir 的源文件路径，不在 BaseDir 下时保持原样.
+func relSource(opts ReportOptions, file string) string {
+	if opts.BaseDir != "" && filepath.IsAbs(file) {
+		if p, err := filepath.Rel(opts.BaseDir, file); err == nil && !strings.HasPrefix(p, "..") {
+			file = p
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// displaySource function    返回源码位置的显示形式，如 zoo/zoo.go:12.
+func displaySource(opts ReportOptions, src ReportSource) string {
+	if src.Line == 0 {
+		return relSource(opts, src.File)
+	}
+	return relSource(opts, src.File) + ":" + strconv.Itoa(src.Line)
+}
+
+// sourceURL function    返回源码位置的链接，没有链接模板时使用 file:// 链接.
+func sourceURL(opts ReportOptions, src ReportSource) template.URL {
+	if src.File == "" {
+		return ""
+	}
+	if opts.SourceURL != "" {
+		r := strings.NewReplacer("{file}", relSource(opts, src.File), "{line}", strconv.Itoa(src.Line))
+		//nolint:gosec
+		return template.URL(r.Replace(opts.SourceURL))
+	}
+	file, err := filepath.Abs(src.File)
+	if err != nil {
+		file = src.File
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+	if src.Line > 0 {
+		u.Fragment = "L" + strconv.Itoa(src.Line)
+	}
+	//nolint:gosec
+	return template.URL(u.String())
+}
+
+// ReportTemp 预编译的 HTML 报告模板.
+var ReportTemp = template.Must(template.New("").Funcs(template.FuncMap{
+	"display": displaySource,
+	"source":  sourceURL,
+}).Parse(reportTemplate))
+
+// reportTemplate HTML 报告模板
+// 单个页面，不依赖外部资源，左侧为导航，组件、接口与注入入口通过锚点互相链接.
+var reportTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Title }}</title>
+<style>
+body { margin: 0; font: 14px/1.5 -apple-system, "Segoe UI", "PingFang SC", sans-serif; color: #24292f; }
+nav { position: fixed; top: 0; bottom: 0; left: 0; width: 240px; overflow-y: auto; padding: 16px;
+  background: #f6f8fa; border-right: 1px solid #d0d7de; box-sizing: border-box; }
+nav ul { list-style: none; padding-left: 12px; margin: 4px 0; }
+main { margin-left: 240px; padding: 16px 32px; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+code { font: 13px ui-monospace, SFMono-Regular, Menlo, monospace; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0 24px; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+:target { background: #fff8c5; }
+.missing { color: #cf222e; }
+.muted { color: #57606a; }
+.summary span { margin-right: 24px; }
+</style>
+</head>
+<body>
+<nav>
+<strong>{{ .Title }}</strong>
+<ul>
+<li><a href="#sets">Set（{{ len .Sets }}）</a>
+<ul>{{ range .Sets }}<li><a href="#set-{{ .Name }}">{{ .Name }}</a></li>{{ end }}</ul></li>
+<li><a href="#bindings">接口绑定（{{ len .Bindings }}）</a></li>
+<li><a href="#injectors">注入入口（{{ len .Injectors }}）</a></li>
+<li><a href="#unresolved">未解析的类型（{{ len .Unresolved }}）</a></li>
+</ul>
+</nav>
+<main>
+<h1>{{ .Title }}</h1>
+<p class="summary"><span>Set: {{ len .Sets }}</span><span>组件: {{ .Components }}</span>
+<span>接口: {{ len .Bindings }}</span><span>注入入口: {{ len .Injectors }}</span>
+<span{{ if .Unresolved }} class="missing"{{ end }}>未解析: {{ len .Unresolved }}</span></p>
+
+<h2 id="sets">Set</h2>
+{{ range .Sets }}
+<h3 id="set-{{ .Name }}">{{ .Name }}</h3>
+{{ if .Includes }}
+<p>组合 Set，包含: {{ range $i, $s := .Includes }}{{ if $i }}、{{ end }}<a href="#set-{{ $s }}">{{ $s }}</a>{{ end }}
+{{ with .Position }}（<a href="{{ source $.Options . }}">{{ display $.Options . }}</a>）{{ end }}</p>
+{{ else }}
+<table>
+<tr><th>组件</th><th>类型</th><th>提供方式</th><th>提供</th><th>依赖</th><th>源码</th></tr>
+{{ range .Components }}
+<tr id="{{ .Anchor }}">
+<td><code>{{ .Name }}</code>{{ if .Deprecated }} <span class="missing">已弃用</span>{{ end }}</td>
+<td>{{ .Kind }}{{ if ne .Annotation "autowire" }}, {{ .Annotation }}{{ end }}</td>
+<td>{{ if .Constructor }}<code>{{ .Constructor }}</code>{{ else }}<span class="muted">-</span>{{ end }}</td>
+<td>{{ range .Provides }}<code>{{ . }}</code><br>{{ end }}</td>
+<td>{{ range .Deps }}{{ if .Provider }}<a href="#{{ .Provider }}"><code>{{ .Type }}</code></a>{{ else }}
+<code class="missing">{{ .Type }}</code>{{ end }}<br>{{ end }}</td>
+<td><a href="{{ source $.Options .Source }}">{{ display $.Options .Source }}</a></td>
+</tr>
+{{ end }}
+</table>
+{{ end }}
+{{ end }}
+
+<h2 id="bindings">接口绑定</h2>
+{{ if .Bindings }}
+<table>
+<tr><th>接口</th><th>实现</th></tr>
+{{ range .Bindings }}
+<tr><td><code>{{ .Interface }}</code></td>
+<td>{{ range .Implementations }}<a href="#{{ .Anchor }}"><code>{{ .Name }}</code></a>
+<span class="muted">{{ display $.Options .Source }}</span><br>{{ end }}</td></tr>
+{{ end }}
+</table>
+{{ else }}<p class="muted">没有接口绑定</p>{{ end }}
+
+<h2 id="injectors">注入入口</h2>
+{{ if .Injectors }}
+<table>
+<tr><th>初始化函数</th><th>返回</th><th>依赖链上的组件</th></tr>
+{{ range .Injectors }}
+<tr><td><code>{{ .Name }}</code><br><a href="#{{ .Root.Anchor }}">{{ .Root.Name }}</a>
+<span class="muted">{{ display $.Options .Root.Source }}</span></td>
+<td><code>{{ .Result }}</code></td>
+<td>{{ range .Components }}<a href="#{{ .Anchor }}"><code>{{ .Name }}</code></a><br>{{ end }}
+{{ range .Missing }}<code class="missing">{{ . }}</code>（没有提供者）<br>{{ end }}</td></tr>
+{{ end }}
+</table>
+{{ else }}<p class="muted">没有注入入口</p>{{ end }}
+
+<h2 id="unresolved">未解析的类型</h2>
+{{ if .Unresolved }}
+<table>
+<tr><th>类型</th><th>依赖它的组件</th></tr>
+{{ range .Unresolved }}
+<tr><td><code class="missing">{{ .Type }}</code></td>
+<td>{{ range .Consumers }}<a href="#{{ .Anchor }}"><code>{{ .Name }}</code></a>
+<a class="muted" href="{{ source $.Options .Source }}">{{ display $.Options .Source }}</a><br>{{ end }}</td></tr>
+{{ end }}
+</table>
+{{ else }}<p class="muted">注入入口依赖链上的类型都有提供者</p>{{ end }}
+</main>
+</body>
+</html>
+`
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"bytes"
+	"go/token"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{
+			"zoo": {
+				"example.com/m/zoo/Zoo": {Name: "Zoo", Pkg: "zoo", PkgPath: "example.com/m/zoo", InitWire: true,
+					Provides: []string{"example.com/m/zoo.Zoo"},
+					Deps:     []string{"example.com/m/animals.Animal", "example.com/m/db.DB"},
+					Position: token.Position{Filename: "/src/m/zoo/zoo.go", Line: 5, Column: 6}},
+			},
+			"animals": {
+				"example.com/m/animals/Cat": {Name: "Cat", Pkg: "animals", PkgPath: "example.com/m/animals",
+					Constructor: "NewCat", Implements: []string{"Animal"},
+					Provides: []string{"example.com/m/animals.Cat", "example.com/m/animals.Animal"},
+					Position: token.Position{Filename: "/src/m/animals/cat.go", Line: 8, Column: 6}},
+			},
+		},
+		composites: []Element{{Name: "app", Composite: true, Includes: []string{"animals", "zoo"}}},
+		initWire:   []string{"*"},
+	}
+
+	r := sc.Report()
+	names := reportSetNames(r.Sets)
+	if !slices.Equal(names, []string{"animals", "app", "zoo"}) {
+		t.Fatalf("Sets = %v", names)
+	}
+	zoo := r.Sets[2].Components[0]
+	if len(zoo.Deps) != 2 || zoo.Deps[0].Provider != "c-example.com-m-animals.Cat" || zoo.Deps[1].Provider != "" {
+		t.Errorf("Zoo.Deps = %+v", zoo.Deps)
+	}
+	if len(r.Bindings) != 1 || r.Bindings[0].Interface != "example.com/m/animals.Animal" ||
+		r.Bindings[0].Implementations[0].Name != "animals.Cat" {
+		t.Errorf("Bindings = %+v", r.Bindings)
+	}
+	if len(r.Injectors) != 1 {
+		t.Fatalf("Injectors = %+v", r.Injectors)
+	}
+	if inj := r.Injectors[0]; inj.Name != "InitializeZoo" || inj.Result != "*zoo.Zoo" ||
+		len(inj.Components) != 1 || !slices.Equal(inj.Missing, []string{"example.com/m/db.DB"}) {
+		t.Errorf("Injector = %+v", inj)
+	}
+	if len(r.Unresolved) != 1 || r.Unresolved[0].Type != "example.com/m/db.DB" ||
+		r.Unresolved[0].Consumers[0].Name != "zoo.Zoo" {
+		t.Errorf("Unresolved = %+v", r.Unresolved)
+	}
+
+	var buf bytes.Buffer
+	opts := ReportOptions{BaseDir: "/src/m", SourceURL: "https://git.example.com/m/blob/main/{file}#L{line}"}
+	if err := WriteReportHTML(&buf, r, opts); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<tr id="c-example.com-m-zoo.Zoo">`,
+		`href="https://git.example.com/m/blob/main/zoo/zoo.go#L5">zoo/zoo.go:5</a>`,
+		`<a href="#set-animals">animals</a>`,
+		`<code class="missing">example.com/m/db.DB</code>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("报告中缺少 %s", want)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteReportHTML(&buf, r, ReportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `href="file:///src/m/animals/cat.go#L8"`) {
+		t.Error("未指定链接模板时应使用 file:// 链接")
+	}
+}
+
+func reportSetNames(sets []ReportSet) []string {
+	var names []string
+	for _, s := range sets {
+		names = append(names, s.Name)
+	}
+	return names
+}
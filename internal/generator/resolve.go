@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// providerIndex method    返回 类型 -> 提供该类型的组件 的映射.
+func (sc *AutoWireSearcher) providerIndex() map[string][]Element {
+	providers := make(map[string][]Element)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			for _, t := range elem.Provides {
+				providers[t] = append(providers[t], elem)
+			}
+		}
+	}
+	return providers
+}
+
+// injectorRoots method    返回会生成初始化函数的组件，与 writeInitFile 的规则一致.
+func (sc *AutoWireSearcher) injectorRoots() []Element {
+	var roots []Element
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			switch {
+			case elem.InitWire && elem.Injector != "":
+				roots = append(roots, elem)
+			case len(sc.initWire) == 1 && sc.initWire[0] == "*":
+				if elem.InitWire {
+					roots = append(roots, elem)
+				}
+			case slices.Contains(sc.initWire, parser.AppendPkg(elem.Pkg, elem.Name)):
+				roots = append(roots, elem)
+			}
+		}
+	}
+	return roots
+}
+
+// hasInjectors method    判断是否会生成引用汇总 Sets 的初始化函数，与 writeInitFile 的规则一致.
+func (sc *AutoWireSearcher) hasInjectors() bool {
+	explicit := len(sc.initWire) > 0 && !(len(sc.initWire) == 1 && sc.initWire[0] == "*")
+	return explicit || len(sc.injectorRoots()) > 0
+}
+
+// Implementations method    返回绑定到接口 iface（包路径.接口名）的组件，包括自动绑定的实现
+// 接口注解、组合 Set、测试替身、配置与值注入组件不计入，同一组件出现在多个 Set 中时只返回一次.
+func (sc *AutoWireSearcher) Implementations(iface string) []Element {
+	var impls []Element
+	seen := parser.NewSet[string]()
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Interface || elem.Composite || elem.Mock || elem.ConfigWire || elem.ValueWire ||
+				!slices.Contains(elem.Provides, iface) || seen.Contains(elem.PkgPath+"."+elem.Name) {
+				continue
+			}
+			seen.Add(elem.PkgPath + "." + elem.Name)
+			impls = append(impls, elem)
+		}
+	}
+	return impls
+}
+
+// InjectorType function    返回以组件为注入入口时初始化函数的返回类型，如 *zoo.Zoo
+// local 为 true 表示生成目录与组件位于同一包，类型不带包名.
+func InjectorType(elem Element, local bool) string {
+	if local {
+		elem.Pkg = ""
+	}
+	return rootResult(elem)
+}
+
+// walkInjector function    按广度优先遍历注入入口依赖链上的组件（含入口本身），每个组件只访问一次
+// visit 在访问组件时调用，missing 在依赖类型没有提供者时调用，均可以为 nil；
+// 初始化函数参数提供的类型（ctx 参数的 context.Context）不再查找提供者.
+func walkInjector(root Element, providers map[string][]Element, visit func(Element), missing func(Element, string)) {
+	visited := parser.NewSet[string]()
+	queue := []Element{root}
+	for len(queue) > 0 {
+		elem := queue[0]
+		queue = queue[1:]
+		key := elem.PkgPath + "/" + instanceName(elem)
+		if visited.Contains(key) {
+			continue
+		}
+		visited.Add(key)
+		if visit != nil {
+			visit(elem)
+		}
+
+		for _, dep := range elem.Deps {
+			switch {
+			case providedByParams(root, dep):
+			case len(providers[dep]) == 0:
+				if missing != nil {
+					missing(elem, dep)
+				}
+			default:
+				queue = append(queue, providers[dep]...)
+			}
+		}
+	}
+}
+
+// missingDeps method    沿每个初始化函数的依赖链查找没有提供者的依赖类型，返回 类型 -> 依赖它的组件.
+// 存在无法确定类型的 @autowire.value 变量时，它可能提供任意缺少的类型，不报告缺少提供者，由 wire 校验.
+func (sc *AutoWireSearcher) missingDeps() map[string][]Element {
+	if elem, ok := sc.untypedValue(); ok {
+		sc.logger.Debug("无法确定变量的类型，跳过缺少提供者的检查", "element", describeElement(elem))
+		return nil
+	}
+	providers := sc.providerIndex()
+	consumers := make(map[string][]Element)
+	seen := parser.NewSet[string]()
+	for _, root := range sc.injectorRoots() {
+		walkInjector(root, providers, nil, func(elem Element, dep string) {
+			if key := dep + " " + describeElement(elem); !seen.Contains(key) {
+				seen.Add(key)
+				consumers[dep] = append(consumers[dep], elem)
+			}
+		})
+	}
+	return consumers
+}
+
+// untypedValue method    返回一个无法确定类型（没有提供的类型）的 @autowire.value 变量.
+func (sc *AutoWireSearcher) untypedValue() (Element, bool) {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			if elem := sc.ElementMap[set][key]; elem.ValueWire && len(elem.Provides) == 0 {
+				return elem, true
+			}
+		}
+	}
+	return Element{}, false
+}
+
+// MissingProviders method    在运行 wire 之前检查没有任何提供者的依赖类型
+// 沿每个初始化函数的依赖链遍历构造函数参数与 wire.Struct 字段（wire 只校验注入入口可达的依赖），
+// 初始化函数参数提供的类型（ctx 参数的 context.Context）只对该注入入口有效；
+// 每个缺少提供者的类型返回一个错误，Details 中列出依赖它的组件及源码位置.
+func (sc *AutoWireSearcher) MissingProviders() []error {
+	missing := sc.missingDeps()
+	errs := make([]error, 0, len(missing))
+	for _, t := range parser.SortedKeys(missing) {
+		consumers := parser.Map(missing[t], describeElement)
+		err := errors.NewMissingDepError(t)
+		err.Details = "  - 被 " + strings.Join(consumers, " 依赖\n  - 被 ") + " 依赖"
+		if t == contextType {
+			err.Suggestions = append([]string{"在注入入口的 @autowire.init 注解中添加 ctx 参数，由初始化函数传入 context.Context"},
+				err.Suggestions...)
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
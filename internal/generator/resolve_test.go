@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const resolveSrc = `package app
+
+import "database/sql"
+
+// @autowire.init(set=app)
+type App struct {
+	Svc   *Service
+	Debug bool ` + "`wire:\"-\"`" + `
+}
+
+// @autowire(set=app)
+func NewService(db *sql.DB, name string) *Service { return nil }
+
+type Service struct{}
+
+// @autowire(set=app)
+func NewUnused(c *Cache) *Unused { return nil }
+
+type Unused struct{}
+
+type Cache struct{}
+`
+
+func TestMissingProviders(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, resolveSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		ElementMap: make(map[string]map[string]Element),
+		logger:     logger.Discard(),
+		cache:      NewCacheManager(dir, false),
+		initWire:   []string{"*"},
+	}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/app", f, getImplement(f))
+	sc.ElementMap["app"] = make(map[string]Element)
+	for _, e := range elements {
+		sc.ElementMap["app"][e.PkgPath+"/"+e.Name] = e
+	}
+
+	errs := sc.MissingProviders()
+	if len(errs) != 2 {
+		t.Fatalf("MissingProviders() = %v, want 2 errors", errs)
+	}
+	for i, want := range []string{"database/sql.DB", "string"} {
+		msg := errs[i].Error()
+		if !strings.Contains(msg, want) || !strings.Contains(msg, "app.NewService (") ||
+			!strings.Contains(msg, "app.go:12") {
+			t.Errorf("errs[%d] = %s, want missing %s with position", i, msg, want)
+		}
+	}
+
+	// 没有初始化函数时 wire 不会校验依赖
+	sc.initWire = nil
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("MissingProviders() without injectors = %v, want none", errs)
+	}
+}
+
+func TestMissingProviders_Context(t *testing.T) {
+	repo := Element{Name: "NewRepo", Pkg: "app", PkgPath: "example.com/app", FuncDecl: true,
+		Provides: []string{"example.com/app.Repo"}, Deps: []string{"context.Context"}}
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{"app": {
+			"example.com/app/NewRepo": repo,
+			"example.com/app/Server": {Name: "Server", Pkg: "app", PkgPath: "example.com/app", InitWire: true,
+				Injector: "Server", Context: true, Provides: []string{"example.com/app.Server"},
+				Deps: []string{"example.com/app.Repo"}},
+		}},
+	}
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("ctx 参数应提供 context.Context: %v", errs)
+	}
+
+	// 没有 ctx 参数的注入入口同样依赖 context.Context 时报告缺少提供者
+	sc.ElementMap["app"]["example.com/app/Worker"] = Element{Name: "Worker", Pkg: "app", PkgPath: "example.com/app",
+		InitWire: true, Injector: "Worker", Provides: []string{"example.com/app.Worker"},
+		Deps: []string{"example.com/app.Repo"}}
+	errs := sc.MissingProviders()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "context.Context") {
+		t.Errorf("MissingProviders() = %v, want missing context.Context", errs)
+	}
+}
+
+func TestImplementations(t *testing.T) {
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: map[string]map[string]Element{
+		"zoo": {
+			"Dog": {Name: "Dog", Pkg: "zoo", PkgPath: "example.com/zoo",
+				Provides: []string{"example.com/zoo.Dog", "example.com/zoo.Animal"}},
+			"Cat": {Name: "Cat", Pkg: "zoo", PkgPath: "example.com/zoo", Result: "Cat", Constructor: "NewCat",
+				Provides: []string{"example.com/zoo.Cat", "example.com/zoo.Pet"}},
+			"Animal": {Name: "Animal", Pkg: "zoo", PkgPath: "example.com/zoo", Interface: true,
+				Provides: []string{"example.com/zoo.Animal"}},
+		},
+		// 拆分到独立 Set 的同一组件只返回一次
+		"zooDog": {"Dog": {Name: "Dog", Pkg: "zoo", PkgPath: "example.com/zoo",
+			Provides: []string{"example.com/zoo.Animal"}}},
+	}}
+
+	impls := sc.Implementations("example.com/zoo.Animal")
+	if len(impls) != 1 || impls[0].Name != "Dog" {
+		t.Fatalf("Implementations(Animal) = %v", elementNames(impls))
+	}
+	if got := InjectorType(impls[0], false); got != "*zoo.Dog" {
+		t.Errorf("InjectorType() = %s, want *zoo.Dog", got)
+	}
+	cat := sc.Implementations("example.com/zoo.Pet")
+	if len(cat) != 1 || InjectorType(cat[0], true) != "Cat" {
+		t.Errorf("Implementations(Pet) = %v", elementNames(cat))
+	}
+	if impls := sc.Implementations("example.com/zoo.Store"); len(impls) != 0 {
+		t.Errorf("Implementations(Store) = %v, want none", elementNames(impls))
+	}
+}
+
+func TestMissingProviders_Value(t *testing.T) {
+	src := `package app
+
+import "example.com/missing/ext"
+
+// Name 服务名称.
+type Name string
+
+// @autowire.value(set=app)
+var DefaultName = Name("x")
+
+// @autowire.value(set=app)
+var DefaultClock = newClock()
+
+func newClock() *Clock { return nil }
+
+type Clock struct{}
+
+// @autowire.init(set=app)
+type App struct {
+	Name  Name
+	Clock *Clock
+}
+`
+	parse := func(src string) *AutoWireSearcher {
+		t.Helper()
+		dir := t.TempDir()
+		file := filepath.Join(dir, "app.go")
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc := &AutoWireSearcher{
+			mu:         &sync.Mutex{},
+			ElementMap: map[string]map[string]Element{"app": {}},
+			logger:     logger.Discard(),
+			cache:      NewCacheManager(dir, false),
+			initWire:   []string{"*"},
+		}
+		for _, e := range sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), file, "example.com/app", f,
+			getImplement(f)) {
+			sc.ElementMap["app"][e.PkgPath+"/"+e.Name] = e
+		}
+		return sc
+	}
+
+	// 转换与函数调用形式的初始值按类型检查得到的类型提供
+	sc := parse(src)
+	for name, want := range map[string]string{
+		"DefaultName":  "example.com/app.Name",
+		"DefaultClock": "example.com/app.Clock",
+	} {
+		if got := sc.ElementMap["app"]["example.com/app/"+name].Provides; len(got) != 1 || got[0] != want {
+			t.Errorf("%s.Provides = %v, want %s", name, got, want)
+		}
+	}
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("MissingProviders() = %v, want none", errs)
+	}
+
+	// 无法确定类型的变量可能提供任意类型，不报告缺少提供者
+	sc = parse(strings.Replace(src, "var DefaultClock = newClock()", "var DefaultClock = ext.New()", 1))
+	if got := sc.ElementMap["app"]["example.com/app/DefaultClock"].Provides; len(got) != 0 {
+		t.Errorf("DefaultClock.Provides = %v, want unknown", got)
+	}
+	if errs := sc.MissingProviders(); len(errs) != 0 {
+		t.Errorf("MissingProviders() with untyped value = %v, want none", errs)
+	}
+}
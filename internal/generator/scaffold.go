@@ -0,0 +1,334 @@
+package generator
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// Scaffold struct    为缺少构造函数的组件生成的构造函数.
+type Scaffold struct {
+	Element     string         // 组件，如 svc.Server
+	Constructor string         // 构造函数名称，如 NewServer
+	Params      []string       // 构造函数参数，与注入的字段一一对应，如 log *Logger
+	Position    token.Position // 组件类型声明的位置
+}
+
+// ScaffoldPlan struct    生成构造函数的计划，Apply 写入源文件.
+type ScaffoldPlan struct {
+	Scaffolds []Scaffold   // 生成的构造函数，按源文件与声明顺序排列
+	Changes   []FileChange // 修改的源文件及其前后内容，按路径排序
+}
+
+// scaffoldInsert struct    插入到源文件中的构造函数.
+type scaffoldInsert struct {
+	offset int    // 插入位置：类型声明所在行的下一行行首
+	code   string // 构造函数源码
+}
+
+// Scaffold method    为使用 wire.Struct 注入的结构体组件生成 New<Type> 构造函数，写在类型声明之后
+// 参数按注入的字段（跳过 wire:"-" 与 fields=、exclude= 排除的字段）依次生成，函数体只为字段赋值。
+// names 支持 包路径.类型名、包名.类型名 以及单独的类型名，为空时处理全部缺少构造函数的组件；
+// 指定的组件无法生成构造函数时返回错误.
+func (sc *AutoWireSearcher) Scaffold(names ...string) (*ScaffoldPlan, error) {
+	components := make(map[string][]Element)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if elem.Interface || elem.Composite {
+				continue
+			}
+			t := elem.PkgPath + "." + elem.Name
+			if len(components[t]) == 0 {
+				components[t] = append(components[t], elem)
+			}
+		}
+	}
+
+	var targets []Element
+	if len(names) == 0 {
+		for _, t := range parser.SortedKeys(components) {
+			if elem := components[t][0]; scaffoldReason(elem) == "" {
+				targets = append(targets, elem)
+			}
+		}
+	}
+	for _, name := range names {
+		t, err := matchType(components, strings.TrimPrefix(strings.TrimSpace(name), "*"))
+		if err != nil {
+			return nil, err
+		}
+		elem := components[t][0]
+		if reason := scaffoldReason(elem); reason != "" {
+			return nil, fmt.Errorf("无法为 %s 生成构造函数: %s", describeElement(elem), reason)
+		}
+		if !slices.ContainsFunc(targets, func(e Element) bool { return e.Position == elem.Position }) {
+			targets = append(targets, elem)
+		}
+	}
+
+	byFile := make(map[string][]Element)
+	for _, elem := range targets {
+		byFile[elem.Position.Filename] = append(byFile[elem.Position.Filename], elem)
+	}
+	plan := &ScaffoldPlan{}
+	for _, file := range parser.SortedKeys(byFile) {
+		scaffolds, change, err := scaffoldFile(file, byFile[file], len(names) > 0)
+		if err != nil {
+			return nil, err
+		}
+		plan.Scaffolds = append(plan.Scaffolds, scaffolds...)
+		if change != nil {
+			plan.Changes = append(plan.Changes, *change)
+		}
+	}
+	return plan, nil
+}
+
+// scaffoldReason function    返回组件无法生成构造函数的原因，可以生成时返回空字符串
+// 只检查扫描结果，结构体字段与包中已有的声明在解析源文件时检查.
+func scaffoldReason(elem Element) string {
+	switch {
+	case elem.Constructor != "":
+		return "已有构造函数 " + elem.Constructor
+	case elem.FuncDecl:
+		return "函数本身就是构造函数"
+	case elem.ValueWire:
+		return "包级变量通过 wire.Value 提供"
+	case elem.ConfigWire:
+		return "配置组件按字段提供，不需要构造函数"
+	case elem.Raw != "":
+		return "由自定义提供者表达式提供"
+	case elem.TypeParams > 0:
+		return "暂不支持泛型组件"
+	case elem.Underlying != "":
+		return "不是结构体类型"
+	case !elem.Position.IsValid():
+		return "缺少源码位置"
+	}
+	return ""
+}
+
+// scaffoldFile function    为同一源文件中的组件生成构造函数，返回生成的构造函数与修改后的文件内容
+// strict 为 true 时（指定了组件）无法生成的组件返回错误，否则跳过.
+func scaffoldFile(file string, elems []Element, strict bool) ([]Scaffold, *FileChange, error) {
+	//nolint:gosec
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取文件 %s 失败: %w", file, err)
+	}
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, file, src, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析文件 %s 失败: %w", file, err)
+	}
+	declared, err := packageDecls(filepath.Dir(file))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var scaffolds []Scaffold
+	var inserts []scaffoldInsert
+	slices.SortFunc(elems, func(a, b Element) int { return cmp.Compare(a.Position.Offset, b.Position.Offset) })
+	for _, elem := range elems {
+		s, reason := scaffoldElement(f, elem, declared)
+		if reason != "" {
+			if strict {
+				return nil, nil, fmt.Errorf("无法为 %s 生成构造函数: %s", describeElement(elem), reason)
+			}
+			continue
+		}
+		// 插入到类型声明（包括所在的 type 分组）结束行的下一行
+		end := fset.Position(s.decl.End()).Offset
+		offset := len(src)
+		if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+			offset = end + i + 1
+		}
+		declared.Add(s.Constructor)
+		scaffolds = append(scaffolds, s.Scaffold)
+		inserts = append(inserts, scaffoldInsert{offset: offset, code: s.code})
+	}
+	if len(inserts) == 0 {
+		return scaffolds, nil, nil
+	}
+
+	// 从后向前插入，同一位置按声明顺序排列
+	slices.SortStableFunc(inserts, func(a, b scaffoldInsert) int { return cmp.Compare(b.offset, a.offset) })
+	out := slices.Clone(src)
+	for i := 0; i < len(inserts); {
+		j := i
+		var code strings.Builder
+		for ; j < len(inserts) && inserts[j].offset == inserts[i].offset; j++ {
+			code.WriteString("\n" + inserts[j].code + "\n")
+		}
+		prefix := ""
+		if inserts[i].offset == len(out) && !bytes.HasSuffix(out, []byte("\n")) {
+			prefix = "\n"
+		}
+		out = slices.Concat(out[:inserts[i].offset], []byte(prefix+code.String()), out[inserts[i].offset:])
+		i = j
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("格式化文件 %s 失败: %w", file, err)
+	}
+	return scaffolds, &FileChange{File: file, Old: src, New: formatted}, nil
+}
+
+// scaffoldDecl struct    生成的构造函数及其对应的类型声明.
+type scaffoldDecl struct {
+	Scaffold
+
+	decl *ast.GenDecl // 组件所在的 type 声明
+	code string       // 构造函数源码
+}
+
+// scaffoldElement function    生成组件的构造函数，无法生成时返回原因
+// declared 为包中已有的顶层声明，与构造函数同名时无法生成.
+func scaffoldElement(f *ast.File, elem Element, declared parser.Set[string]) (scaffoldDecl, string) {
+	spec := findTypeSpec(f, elem.Name)
+	if spec == nil {
+		return scaffoldDecl{}, "源文件中未找到类型声明"
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.Assign.IsValid() {
+		return scaffoldDecl{}, "不是结构体类型"
+	}
+	name := scaffoldPrefix + elem.Name
+	if declared.Contains(name) {
+		return scaffoldDecl{}, "包中已声明 " + name
+	}
+
+	// 参数名避开字段类型中引用的包名
+	fields := selectFields(st, elem.StructFields)
+	taken := parser.NewSet[string]()
+	ast.Inspect(fields, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				taken.Add(id.Name)
+			}
+		}
+		return true
+	})
+	var params, values []string
+	for _, field := range fields.List {
+		names := fieldNames(field)
+		if len(names) == 0 {
+			return scaffoldDecl{}, fmt.Sprintf("无法确定嵌入字段 %s 的名称", types.ExprString(field.Type))
+		}
+		for _, fieldName := range names {
+			p := paramName(fieldName, taken)
+			params = append(params, p+" "+types.ExprString(field.Type))
+			values = append(values, fmt.Sprintf("\t\t%s: %s,\n", fieldName, p))
+		}
+	}
+	literal := "&" + elem.Name + "{}"
+	if len(values) > 0 {
+		literal = "&" + elem.Name + "{\n" + strings.Join(values, "") + "\t}"
+	}
+	code := fmt.Sprintf("// %s 创建 %s.\nfunc %s(%s) *%s {\n\treturn %s\n}",
+		name, elem.Name, name, strings.Join(params, ", "), elem.Name, literal)
+	return scaffoldDecl{
+		Scaffold: Scaffold{
+			Element:     parser.AppendPkg(elem.Pkg, elem.Name),
+			Constructor: name,
+			Params:      params,
+			Position:    elem.Position,
+		},
+		decl: typeDeclOf(f, spec),
+		code: code,
+	}, ""
+}
+
+// scaffoldPrefix 生成的构造函数名称前缀，扫描时识别为组件的构造函数.
+const scaffoldPrefix = "New"
+
+// typeDeclOf function    返回类型声明所在的 type 声明（可能是包含多个类型的分组）.
+func typeDeclOf(f *ast.File, spec *ast.TypeSpec) *ast.GenDecl {
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Pos() <= spec.Pos() && spec.End() <= gd.End() {
+			return gd
+		}
+	}
+	return nil
+}
+
+// paramName function    返回字段对应的参数名：首字母小写，与关键字、包名或已有参数冲突时添加数字后缀.
+func paramName(field string, taken parser.Set[string]) string {
+	base := strcase.LowerCamelCase(field)
+	if base == "" || base == "_" {
+		base = "v"
+	}
+	name := base
+	for i := 1; token.IsKeyword(name) || taken.Contains(name); i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	taken.Add(name)
+	return name
+}
+
+// packageDecls function    返回目录中非测试 Go 文件的顶层声明名称（不含方法），用于避免生成同名的构造函数.
+func packageDecls(dir string) (parser.Set[string], error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	names := parser.NewSet[string]()
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := goparser.ParseFile(fset, file, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("解析文件 %s 失败: %w", file, err)
+		}
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					names.Add(d.Name.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						names.Add(s.Name.Name)
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							names.Add(n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// Apply method    将生成的构造函数写入源文件，保留文件原有的权限.
+func (p *ScaffoldPlan) Apply() error {
+	for _, c := range p.Changes {
+		info, err := os.Stat(c.File)
+		if err != nil {
+			return fmt.Errorf("读取文件 %s 失败: %w", c.File, err)
+		}
+		//nolint:gosec
+		if err := os.WriteFile(c.File, c.New, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("写入文件 %s 失败: %w", c.File, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+const scaffoldSrc = `package svc
+
+import "log/slog"
+
+// @autowire(set=svc)
+type Repo struct {
+	Log  *slog.Logger
+	skip string ` + "`wire:\"-\"`" + `
+}
+
+type (
+	// @autowire(set=svc,exclude=cache)
+	Server struct {
+		*Repo
+		Type  string
+		slog  int
+		cache map[string]string
+	} // 分组中的结构体
+
+	// @autowire(set=svc)
+	Empty struct{}
+)
+
+// @autowire(set=svc)
+type Taken struct{}
+
+var NewTaken = 1
+
+// @autowire(set=svc)
+func NewClient() *Repo { return nil }
+`
+
+func TestScaffold(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "svc.go")
+	if err := os.WriteFile(file, []byte(scaffoldSrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, scaffoldSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	elements := sc.parseAnnotations(decls, file, "example.com/svc", f, getImplement(f))
+	sc.ElementMap["svc"] = make(map[string]Element)
+	for _, elem := range elements {
+		sc.ElementMap["svc"][elem.Name] = elem
+	}
+
+	if _, err := sc.Scaffold("svc.NewClient"); err == nil || !strings.Contains(err.Error(), "已有构造函数") {
+		t.Errorf("Scaffold(NewClient) error = %v", err)
+	}
+	if _, err := sc.Scaffold("Taken"); err == nil || !strings.Contains(err.Error(), "包中已声明 NewTaken") {
+		t.Errorf("Scaffold(Taken) error = %v", err)
+	}
+
+	// 未指定组件时跳过无法生成的组件，构造函数按声明顺序写在类型声明之后
+	plan, err := sc.Scaffold()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range plan.Scaffolds {
+		names = append(names, s.Constructor)
+	}
+	if got := strings.Join(names, ","); got != "NewRepo,NewServer,NewEmpty" || len(plan.Changes) != 1 {
+		t.Fatalf("Scaffold() = %s, changes = %d", got, len(plan.Changes))
+	}
+	src := string(plan.Changes[0].New)
+	for _, want := range []string{
+		"}\n\n// NewRepo 创建 Repo.\nfunc NewRepo(log *slog.Logger) *Repo {\n\treturn &Repo{\n\t\tLog: log,\n\t}\n}\n",
+		"func NewServer(repo *Repo, type1 string, slog int) *Server {",
+		"\t} // 分组中的结构体\n",
+		"func NewEmpty() *Empty {\n\treturn &Empty{}\n}",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("生成的源码缺少 %q:\n%s", want, src)
+		}
+	}
+	if strings.Index(src, "func NewServer") > strings.Index(src, "func NewEmpty") ||
+		strings.Index(src, ")\n\n// NewServer") < 0 {
+		t.Errorf("构造函数应按声明顺序写在 type 分组之后:\n%s", src)
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != src {
+		t.Error("Apply() 写入的内容与计划不一致")
+	}
+}
@@ -6,156 +6,469 @@ package generator
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/format"
 	goparser "go/parser"
 	"go/token"
-	"log"
+	"log/slog"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 
 	"github.com/spelens-gud/gutowire/internal/config"
 	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/logger"
 	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
 	"github.com/stoewer/go-strcase"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 // AutoWireSearcher struct    自动装配搜索器，负责扫描和收集所有需要注入的组件.
 type AutoWireSearcher struct {
-	sets           []string                      // 所有 Set 的名称列表
-	genPath        string                        // 生成文件的路径
-	pkg            string                        // 包名
-	ElementMap     map[string]map[string]Element // Set名称 -> (组件路径 -> 组件信息)
-	modBase        string                        // Go module 的基础路径
-	initElements   []Element                     // 标记为 init 的元素列表
-	configElements []Element                     // 标记为 config 的元素列表
-	initWire       []string                      // 需要初始化的类型
-	wg             errgroup.Group                // 并发控制
-	mu             sync.Mutex                    // 并发安全锁
-	cache          *CacheManager                 // 缓存管理器
-	excludeDirs    []string                      // 排除的目录列表
-}
-
-// NewAutoWireSearcher function    创建一个自动装配搜索器.
-func NewAutoWireSearcher(genPath string, modBase string, initWire []string, pkg string, enableCache bool,
-	excludeDirs []string) *AutoWireSearcher {
+	sets            []string                      // 所有 Set 的名称列表
+	genPath         string                        // 生成文件的路径
+	pkg             string                        // 包名
+	ElementMap      map[string]map[string]Element // Set名称 -> (组件路径 -> 组件信息)
+	modBase         string                        // Go module 的基础路径
+	initElements    []Element                     // 标记为 init 的元素列表
+	configElements  []Element                     // 标记为 config 的元素列表
+	initWire        []string                      // 需要初始化的类型
+	parallel        int                           // 最大并发数，0 表示不限制
+	mu              *sync.Mutex                   // 并发安全锁，输出目录的搜索器与原搜索器共用
+	cache           *CacheManager                 // 缓存管理器
+	excludeDirs     []string                      // 排除的目录或 glob 列表
+	includeOnly     []string                      // 只扫描的目录或 glob 列表，为空表示全部
+	maxDepth        int                           // 扫描进入的最大目录深度，0 表示不限制
+	searchRoots     []string                      // 本次扫描的搜索路径，用于计算增量重新生成时文件的目录深度
+	logger          *slog.Logger                  // 日志器
+	progress        config.ProgressFunc           // 扫描进度回调，为 nil 时不报告
+	dupPolicy       string                        // 重复接口绑定的处理策略
+	conflictPolicy  string                        // 不同 Set 绑定同一接口的处理策略
+	splitSets       parser.Set[string]            // 因重复绑定拆分出的 Set，不加入汇总 Sets
+	scannedDirs     parser.Set[string]            // 扫描过的源码目录，用于清理过期的限定类型文件
+	interfaces      []Element                     // 带 @autowire 注解的接口，扫描结束后自动绑定实现
+	composites      []Element                     // 组合 Set（@autowire.set），引用其他 Set
+	fileElements    map[string][]Element          // 源文件 -> 解析出的组件，用于增量重新生成
+	fileDiagnostics map[string][]Diagnostic       // 源文件 -> 注解语法问题
+	tag             string                        // 注解标记，为空时使用 config.WireTag
+	setOutputs      map[string]string             // Set 名称 -> 输出目录（相对模块根目录）
+	setTags         map[string]string             // Set 名称 -> 构建标签（配置文件中的 set_tags）
+	setBuildTags    map[string]string             // Set 名称 -> Set 文件的构建约束（配置文件中的 set_build_tags）
+	outputDirs      []string                      // 上次生成时除生成路径外的输出目录
+	pending         *fileList                     // 检查模式下记录待更新的文件，为 nil 时正常写入
+	produced        *fileList                     // 本次生成的全部文件（绝对路径）
+	written         *fileList                     // 本次内容变化而写入的文件（绝对路径）
+	stats           *scanStats                    // 扫描时源文件的缓存命中统计
+	fset            *token.FileSet                // 本次扫描共享的文件集合，并发解析的源文件均以文件名登记
+	providers       *providerSources              // 本次生成的提供者表达式 -> 组件，用于将 wire 的错误映射回注解
+	setFiles        *setFiles                     // 本次生成的 Set 文件，供插件获取
+	injectorPrefix  string                        // 初始化函数名称前缀，测试注入包中为 Test
+	initTemplate    string                        // 自定义初始化文件模板的路径（init_template），为空时使用 InitTemp
+	buildCtx        *build.Context                // 评估文件构建约束的目标平台与构建标签，为 nil 时扫描全部文件
+	packages        *packageIndex                 // 按目录缓存的包文件与顶层声明，用于跨文件查找构造函数与类型
+	ignore          *parser.Ignore                // .gitignore 与 .gutowireignore 的规则，为 nil 时不遵循
+	onlySets        []string                      // 只生成的 Set 名称（WithSets），为空表示全部
+	targetPaths     []string                      // 批量生成时全部目标的生成路径，导入其中任一生成包的文件不参与扫描
+	streaming       bool                          // 流式扫描（Stream），解析出的组件交给回调，不保留在 ElementMap 中
+	ctx             context.Context               // 取消时终止扫描与写入，为 nil 时不会取消
+
+	includeGenerated bool     // 是否扫描生成的文件
+	generatedGlobs   []string // 允许扫描的生成文件 glob（相对模块根目录），为空表示全部
+
+	filePrefix     string                             // 生成文件名的前缀，为空时使用 config.FilePrefix
+	fileSuffix     string                             // 生成文件名的后缀（.go 之前）
+	headerTemplate string                             // 生成文件头部注释的模板路径，为空时不添加
+	headerTmpl     func() (*template.Template, error) // 只读取一次的头部注释模板，为 nil 时不添加
+}
+
+// NewAutoWireSearcher function    根据配置选项创建一个自动装配搜索器.
+//
+// o: 已初始化的配置选项（见 config.NewGenOpt）
+// modBase: Go module 的基础路径
+func NewAutoWireSearcher(o *config.Opt, modBase string) *AutoWireSearcher {
+	excludeDirs := o.ExcludeDirs
 	if len(excludeDirs) == 0 {
-		excludeDirs = []string{"vendor", "testdata", ".git"}
+		excludeDirs = slices.Clone(config.DefaultExcludeDirs)
+	}
+	cache := newCache(o)
+	tag, setOutputs := cache.tag, cache.sets
+	sc := &AutoWireSearcher{
+		genPath:        o.GenPath,
+		modBase:        modBase,
+		initWire:       o.InitWire,
+		ElementMap:     make(map[string]map[string]Element),
+		mu:             &sync.Mutex{},
+		pkg:            strings.ReplaceAll(o.Pkg, "-", "_"), // 包名中的 - 替换为 _（Go 包名规范）
+		cache:          cache,
+		tag:            tag,
+		excludeDirs:    excludeDirs,
+		includeOnly:    o.IncludeOnly,
+		maxDepth:       o.MaxDepth,
+		logger:         o.Logger,
+		progress:       o.Progress,
+		ctx:            o.Context,
+		dupPolicy:      o.DuplicateBinding,
+		conflictPolicy: o.ConflictPolicy,
+		splitSets:      parser.NewSet[string](),
+		scannedDirs:    parser.NewSet[string](),
+
+		fileElements: make(map[string][]Element),
+
+		includeGenerated: o.IncludeGenerated,
+		generatedGlobs:   o.GeneratedGlobs,
+
+		parallel:     o.Parallel,
+		setOutputs:   setOutputs,
+		buildCtx:     newBuildContext(o),
+		packages:     &packageIndex{},
+		stats:        &scanStats{},
+		fset:         token.NewFileSet(),
+		initTemplate: o.InitTemplate,
+
+		filePrefix:     o.FilePrefix,
+		fileSuffix:     o.FileSuffix,
+		headerTemplate: o.HeaderTemplate,
+	}
+	sc.headerTmpl = sync.OnceValues(sc.loadHeader)
+	if o.CheckOnly {
+		sc.pending = &fileList{}
+	}
+	if o.IgnoreFiles {
+		sc.ignore = parser.NewIgnore()
+	}
+	sc.setTags = sc.normalizeSetTags(o.SetTags)
+	sc.setBuildTags = sc.normalizeSetTags(o.SetBuildTags)
+	sc.onlySets = parser.Map(o.Sets, strcase.LowerCamelCase)
+	sc.targetPaths = o.TargetPaths
+	return sc
+}
+
+// ShareCache method    使用进程内共享的缓存（见 NewSharedCache）代替搜索器自带的缓存，需要在扫描之前调用.
+func (sc *AutoWireSearcher) ShareCache(cm *CacheManager) {
+	sc.cache = cm
+}
+
+// SetContext method    设置之后的扫描与写入使用的 ctx，增量生成时每次运行使用调用方传入的 ctx.
+func (sc *AutoWireSearcher) SetContext(ctx context.Context) {
+	sc.ctx = ctx
+}
+
+// ctxErr method    返回 ctx 被取消的原因，未设置 ctx 或未取消时返回 nil
+// 扫描每个文件、写入每个生成文件之前检查，取消后尽快返回.
+func (sc *AutoWireSearcher) ctxErr() error {
+	if sc.ctx == nil {
+		return nil
 	}
-	return &AutoWireSearcher{
-		genPath:     genPath,
-		modBase:     modBase,
-		initWire:    initWire,
-		ElementMap:  make(map[string]map[string]Element),
-		pkg:         pkg,
-		cache:       NewCacheManager(genPath, enableCache),
-		excludeDirs: excludeDirs,
+	return sc.ctx.Err()
+}
+
+// group method    返回新的并发控制，按配置限制同时扫描或写入的文件数.
+func (sc *AutoWireSearcher) group() *errgroup.Group {
+	g := &errgroup.Group{}
+	if sc.parallel > 0 {
+		g.SetLimit(sc.parallel)
 	}
+	return g
 }
 
 // SearchAllPath method    递归扫描指定目录下的所有 Go 文件
-// 跳过配置的排除目录，跳过测试文件.
-func (sc *AutoWireSearcher) SearchAllPath(file string) (err error) {
+// 指定多个目录时合并扫描结果，重叠的目录只扫描一次；跳过配置的排除目录，跳过测试文件.
+func (sc *AutoWireSearcher) SearchAllPath(roots ...string) (err error) {
 	// 加载缓存
 	if err := sc.cache.Load(); err != nil {
-		log.Printf("[warn] 加载缓存失败: %v", err)
+		sc.logger.Warn("加载缓存失败", "error", err)
 	}
 
-	var files []string
+	sc.stats = &scanStats{}
+	sc.fset = token.NewFileSet()
 
 	// 第一步：收集所有需要处理的文件
-	err = filepath.Walk(file, func(path string, f os.FileInfo, _ error) error {
-		fn := f.Name()
-
-		// 跳过配置的排除目录
-		if f.IsDir() && sc.isExcludedDir(fn) {
-			return filepath.SkipDir
-		}
-
-		// 只处理 .go 文件，跳过测试文件
-		if f.IsDir() || !parser.CheckFileType(fn) {
-			return nil
-		}
-
-		files = append(files, path)
-		return nil
-	})
-
+	files, err := sc.collectFiles(roots)
 	if err != nil {
 		return err
 	}
 
-	// 第二步：并发处理所有文件
+	// 移除已删除文件的缓存
+	sc.cache.Prune(files)
+
+	// 需要重新解析的带注解文件所在的包在首次查找声明时通过一次 packages.Load 一并加载
+	sc.preloadPackages(sc.pendingDirs(files))
+
+	// 第二步：并发处理所有文件，每处理完一个文件报告一次进度
+	var done atomic.Int64
+	sc.reportProgress(0, len(files))
+	g := sc.group()
 	for _, filePath := range files {
 		// filePath := filePath // 捕获循环变量
-		sc.wg.Go(func() error {
-			return sc.searchWire(filePath)
+		g.Go(func() error {
+			if err := sc.ctxErr(); err != nil {
+				return err
+			}
+			err := sc.searchWire(filePath)
+			sc.reportProgress(int(done.Add(1)), len(files))
+			return err
 		})
 	}
 
 	// 等待所有文件处理完成
-	return sc.wg.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	// 第三步：为带注解的接口查找实现并添加绑定
+	sc.bindAnnotatedInterfaces()
+	return nil
 }
 
-// isExcludedDir method    检查目录是否应该被排除.
-func (sc *AutoWireSearcher) isExcludedDir(dirName string) bool {
+// collectFiles method    收集搜索路径下需要扫描的 Go 文件，重叠的目录只收集一次
+// 跟随指向搜索路径之外的目录符号链接，超过 max_depth 的目录不进入.
+func (sc *AutoWireSearcher) collectFiles(roots []string) (files []string, err error) {
+	seen := parser.NewSet[string]()
+	sc.searchRoots = roots
+	walkOpts := parser.WalkOptions{MaxDepth: sc.maxDepth, Roots: roots}
+	for _, root := range roots {
+		err = parser.Walk(root, walkOpts, func(path string, f os.FileInfo, walkErr error) error {
+			if err := sc.ctxErr(); err != nil {
+				return err
+			}
+			if walkErr != nil {
+				// 搜索路径本身不可访问时报错，其余不可访问的目录跳过
+				if path == root {
+					return walkErr
+				}
+				return nil
+			}
+			fn := f.Name()
+
+			// 跳过配置的排除目录（扫描根目录本身不排除）
+			if f.IsDir() {
+				if path != root && sc.isExcluded(path, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// 只处理 .go 文件，跳过测试文件、排除的文件、include_only 之外的文件以及不满足构建约束的文件
+			if !parser.CheckFileType(fn) || sc.isExcluded(path, false) || !sc.isIncluded(path) || !sc.matchBuild(path) {
+				return nil
+			}
+
+			// 多个搜索路径重叠时同一文件只处理一次
+			if abs := absPath(path); !seen.Contains(abs) {
+				seen.Add(abs)
+				files = append(files, path)
+				sc.scannedDirs.Add(filepath.Dir(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// pendingDirs method    返回需要重新解析（缓存未命中且带注解）的文件所在的目录.
+func (sc *AutoWireSearcher) pendingDirs(files []string) []string {
+	var mu sync.Mutex
+	dirs := parser.NewSet[string]()
+	g := sc.group()
+	for _, file := range files {
+		g.Go(func() error {
+			if modified, err := sc.cache.IsModified(file); err == nil && !modified {
+				if _, ok := sc.cache.Get(file); ok {
+					return nil
+				}
+			}
+			// 读取失败在解析时报告
+			hasTag, generated, err := sc.quickCheckForTag(file)
+			if err != nil || !hasTag || (generated && !sc.includeGeneratedFile(file)) {
+				return nil
+			}
+			mu.Lock()
+			dirs.Add(filepath.Dir(absPath(file)))
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return parser.SortedKeys(dirs)
+}
+
+// reportProgress method    报告扫描进度，未设置进度回调时忽略.
+func (sc *AutoWireSearcher) reportProgress(done, total int) {
+	if sc.progress != nil {
+		sc.progress(done, total)
+	}
+}
+
+// isExcluded method    检查目录或文件是否应该被排除
+// 排除项可以是目录名（如 vendor）或相对模块根目录的 glob（如 internal/legacy/**、**/*.pb.go），
+// 同时排除 .gitignore 与 .gutowireignore 忽略的路径.
+func (sc *AutoWireSearcher) isExcluded(path string, isDir bool) bool {
+	if sc.ignore != nil && sc.ignore.Match(path, isDir) {
+		return true
+	}
+	if len(sc.excludeDirs) == 0 {
+		return false
+	}
+	name := filepath.Base(path)
+	rel := sc.relPath(path)
 	for _, excluded := range sc.excludeDirs {
-		if dirName == excluded {
+		if isDir && parser.NameEqual(name, excluded) {
+			return true
+		}
+		// 目录加上末尾的 /，使 dir/** 形式的模式同样匹配目录本身
+		if parser.MatchGlob(excluded, rel) || (isDir && parser.MatchGlob(excluded, rel+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIncluded method    检查文件是否在 include_only 范围内，未配置时包含全部文件
+// 每一项可以是目录（包含其下所有文件）或 glob.
+func (sc *AutoWireSearcher) isIncluded(path string) bool {
+	if len(sc.includeOnly) == 0 {
+		return true
+	}
+	rel := sc.relPath(path)
+	for _, included := range sc.includeOnly {
+		included = strings.TrimSuffix(filepath.ToSlash(included), "/")
+		if parser.MatchGlob(included, rel) || parser.MatchGlob(included+"/**", rel) {
 			return true
 		}
 	}
 	return false
 }
 
+// withinDepth method    检查文件所在目录是否在某个搜索路径的 max_depth 以内，未配置或不在搜索路径内时返回 true
+// 完整扫描时由目录遍历限制深度，增量重新生成时使用该方法过滤.
+func (sc *AutoWireSearcher) withinDepth(file string) bool {
+	if sc.maxDepth <= 0 {
+		return true
+	}
+	dir := filepath.Dir(absPath(file))
+	found := false
+	for _, root := range sc.searchRoots {
+		rel, ok := parser.RelPath(absPath(root), dir)
+		if !ok {
+			continue
+		}
+		found = true
+		if rel == "." || strings.Count(rel, "/")+1 <= sc.maxDepth {
+			return true
+		}
+	}
+	return !found
+}
+
+// relPath method    返回相对所在模块根目录的路径（go.work 中的模块各自计算），不在模块内时原样返回.
+func (sc *AutoWireSearcher) relPath(path string) string {
+	if rel, ok := parser.RelPath(parser.GetModuleDir(path), absPath(path)); ok {
+		return rel
+	}
+	return filepath.ToSlash(path)
+}
+
 // searchWire method    扫描单个 Go 文件，查找并解析 @autowire 注解.
 func (sc *AutoWireSearcher) searchWire(file string) error {
+	scan, ok, err := sc.parseWire(file)
+	if err != nil || !ok {
+		return err
+	}
+	// 使用缓存的元素，重新解析的组件在解析时已添加
+	if scan.cached {
+		sc.addCachedElements(scan.elements, file)
+	}
+	sc.recordFile(file, scan.elements, scan.diags)
+	return nil
+}
+
+// fileScan struct    单个源文件的扫描结果.
+type fileScan struct {
+	elements []Element    // 解析出的组件，按声明顺序排列
+	diags    []Diagnostic // 注解语法问题
+	cached   bool         // 是否为缓存的结果
+}
+
+// parseWire method    解析单个 Go 文件中的 @autowire 注解，返回解析出的组件与注解语法问题
+// 文件未修改时使用缓存的结果，重新解析的组件在解析时已添加到 ElementMap（流式扫描除外）；
+// 没有注解或跳过的文件 ok 为 false.
+func (sc *AutoWireSearcher) parseWire(file string) (scan fileScan, ok bool, err error) {
 	// 检查缓存：如果文件未修改，使用缓存的结果
 	if modified, err := sc.cache.IsModified(file); err == nil && !modified {
 		if elements, ok := sc.cache.Get(file); ok {
-			// 使用缓存的元素
-			sc.addCachedElements(elements, file)
-			return nil
+			sc.stats.hit()
+			return fileScan{elements: elements, diags: sc.cache.Diagnostics(file), cached: true}, true, nil
 		}
 	}
 
+	sc.stats.miss()
+
 	// 快速检查：扫描文件前100行，如果没有 @autowire 标记则跳过
-	hasTag, err := sc.quickCheckForTag(file)
+	hasTag, generated, err := sc.quickCheckForTag(file)
 	if err != nil {
-		return errors.WrapError(err, fmt.Sprintf("快速检查文件 %s 失败", file))
+		return scan, false, errors.WrapError(err, fmt.Sprintf("快速检查文件 %s 失败", file))
 	}
 	if !hasTag {
-		return nil
+		// 记录空结果，文件未修改时下次无需再检查
+		if err := sc.cache.Set(file, nil); err != nil {
+			sc.logger.Warn("更新缓存失败", "error", err)
+		}
+		return scan, false, nil
+	}
+
+	// 默认跳过生成的文件，除非显式开启 include_generated；记录空结果，文件未修改时下次无需再检查
+	if generated && !sc.includeGeneratedFile(file) {
+		sc.logger.Debug("跳过生成的文件", "file", file)
+		if err := sc.cache.Set(file, nil); err != nil {
+			sc.logger.Warn("更新缓存失败", "error", err)
+		}
+		return scan, false, nil
 	}
 
 	// 读取文件内容
 	//nolint:gosec
 	data, err := os.ReadFile(file)
 	if err != nil {
-		return errors.NewFileNotFoundError(file)
+		return scan, false, errors.NewFileNotFoundError(file)
 	}
 
-	// 解析 Go 源文件的 AST
-	parseFile, err := goparser.ParseFile(token.NewFileSet(), "", data, goparser.ParseComments)
+	// 解析 Go 源文件的 AST，以文件名登记到共享的文件集合，组件与诊断中的位置可以直接定位到源文件
+	fset := sc.fileSet()
+	parseFile, err := goparser.ParseFile(fset, file, data, goparser.ParseComments)
 	if err != nil {
-		return errors.WrapError(err, fmt.Sprintf("解析文件 %s 失败", file))
+		return scan, false, errors.WrapError(err, fmt.Sprintf("解析文件 %s 失败", file))
 	}
 
 	// 检查是否会导致循环导入
 	if sc.wouldCauseCircularImport(parseFile, file) {
-		return nil
+		return scan, false, nil
 	}
 
 	// 收集所有带 @autowire 注解的声明
-	matchDecls := sc.collectAnnotatedDecls(parseFile)
+	matchDecls := sc.collectAnnotatedDecls(fset, parseFile)
+
+	// 构造函数与类型可以声明在同一包的其他文件中
+	if len(matchDecls) > 0 {
+		sc.bindPackage(parseFile, file)
+	}
 
 	// 获取接口实现关系
 	implementMap := getImplement(parseFile)
@@ -163,44 +476,56 @@ func (sc *AutoWireSearcher) searchWire(file string) error {
 	// 计算包路径（只计算一次）
 	pkgPath := sc.getPkgPath(file)
 
-	// 解析每个声明的注解
+	// 解析每个声明的注解，并检查注解语法
 	elements := sc.parseAnnotations(matchDecls, file, pkgPath, parseFile, implementMap)
+	diags := sc.checkAnnotations(matchDecls, parseFile)
 
 	// 更新缓存
-	if err := sc.cache.Set(file, elements); err != nil {
-		log.Printf("[warn] 更新缓存失败: %v", err)
+	if err := sc.cache.Set(file, elements, diags...); err != nil {
+		sc.logger.Warn("更新缓存失败", "error", err)
 	}
-
-	return nil
+	return fileScan{elements: elements, diags: diags}, true, nil
 }
 
 // addCachedElements method    添加缓存的元素到 ElementMap.
 func (sc *AutoWireSearcher) addCachedElements(elements []Element, file string) {
 	pkgPath := sc.getPkgPath(file)
 	for _, elem := range elements {
-		setName := "unknown"
-		if elem.InitWire {
-			setName = "init"
-		} else if elem.ConfigWire {
-			setName = "config"
+		if elem.Interface {
+			sc.addInterface(elem)
+			continue
+		}
+		if elem.Composite {
+			sc.addComposite(elem)
+			continue
+		}
+		setName := elem.Set
+		if setName == "" {
+			// 兼容旧版本缓存：未记录 Set 名称时按标记推断
+			setName = "unknown"
+			if elem.InitWire {
+				setName = "init"
+			} else if elem.ConfigWire {
+				setName = "config"
+			}
 		}
 
 		sc.mu.Lock()
 		if sc.ElementMap[setName] == nil {
 			sc.ElementMap[setName] = make(map[string]Element)
 		}
-		sc.ElementMap[setName][path.Join(pkgPath, elem.Name)] = elem
+		sc.ElementMap[setName][path.Join(pkgPath, instanceName(elem))] = elem
 		sc.mu.Unlock()
 	}
 }
 
 // quickCheckForTag method    快速检查文件是否包含 @autowire 标记
-// 只扫描文件前100行，避免读取整个大文件.
-func (sc *AutoWireSearcher) quickCheckForTag(file string) (bool, error) {
+// 只扫描文件前100行，避免读取整个大文件；同时识别 package 之前的生成文件标记.
+func (sc *AutoWireSearcher) quickCheckForTag(file string) (hasTag, generated bool, err error) {
 	//nolint:gosec
 	f, err := os.Open(file)
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
 	defer func() {
 		_ = f.Close()
@@ -208,24 +533,61 @@ func (sc *AutoWireSearcher) quickCheckForTag(file string) (bool, error) {
 
 	scanner := bufio.NewScanner(f)
 	lineCount := 0
-	tagBytes := []byte(config.WireTag)
+	tagBytes := []byte(sc.annotation())
+	inHeader := true
 
 	for scanner.Scan() && lineCount < 100 {
-		if bytes.Contains(scanner.Bytes(), tagBytes) {
-			return true, nil
+		line := scanner.Bytes()
+		if inHeader {
+			if bytes.HasPrefix(line, []byte("package ")) {
+				inHeader = false
+			} else if generatedHeader.Match(line) {
+				generated = true
+			}
+		}
+		if bytes.Contains(line, tagBytes) {
+			return true, generated, nil
 		}
 		lineCount++
 	}
 
-	return false, scanner.Err()
+	return false, generated, scanner.Err()
 }
 
-// wouldCauseCircularImport method    检查是否会引发循环导入.
+// generatedHeader 匹配 Go 约定的生成文件标记，见 https://go.dev/s/generatedcode.
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// includeGeneratedFile method    判断生成的文件是否需要被扫描
+// 开启 include_generated 且未配置 glob 时包含全部生成文件，否则只包含匹配 glob 的文件.
+func (sc *AutoWireSearcher) includeGeneratedFile(file string) bool {
+	if !sc.includeGenerated {
+		return false
+	}
+	if len(sc.generatedGlobs) == 0 {
+		return true
+	}
+	return parser.MatchAnyGlob(sc.generatedGlobs, sc.relPath(file))
+}
+
+// absPath function    返回绝对路径，失败时原样返回.
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// wouldCauseCircularImport method    检查是否会引发循环导入
+// 批量生成时检查全部目标的生成包.
 func (sc *AutoWireSearcher) wouldCauseCircularImport(parseFile *ast.File, file string) bool {
-	genPkgPath := fmt.Sprintf(`"%s"`, sc.getPkgPath(filepath.Join(sc.genPath, "...")))
+	genPkgPaths := parser.Map(append([]string{sc.genPath}, sc.targetPaths...), func(genPath string) string {
+		return sc.getPkgPath(filepath.Join(genPath, "..."))
+	})
 	for _, imp := range parseFile.Imports {
-		if imp.Path.Value == genPkgPath {
-			log.Printf("[warn] 包 %s (来自 %s) 已导入生成目标包，跳过以避免循环依赖", parseFile.Name.Name, file)
+		// 生成目录的包路径来自文件系统，在大小写不敏感的系统上可能与导入路径大小写不同
+		impPath, err := strconv.Unquote(imp.Path.Value)
+		if err == nil && slices.ContainsFunc(genPkgPaths, func(p string) bool { return parser.NameEqual(impPath, p) }) {
+			sc.logger.Warn("包已导入生成目标包，跳过以避免循环依赖", "pkg", parseFile.Name.Name, "file", file)
 			return true
 		}
 	}
@@ -233,26 +595,42 @@ func (sc *AutoWireSearcher) wouldCauseCircularImport(parseFile *ast.File, file s
 }
 
 // collectAnnotatedDecls method    收集所有带 @autowire 注解的声明.
-func (sc *AutoWireSearcher) collectAnnotatedDecls(parseFile *ast.File) []tmpDecl {
-	var matchDecls []tmpDecl
+func (sc *AutoWireSearcher) collectAnnotatedDecls(fset *token.FileSet, parseFile *ast.File) []tmpDecl {
+	// package 子句文档注释中的组合 Set
+	matchDecls := sc.collectPackageDecl(fset, parseFile)
 
 	for _, decl := range parseFile.Decls {
 		switch d := decl.(type) {
 		case *ast.GenDecl:
-			// 只处理 type 声明
-			if d.Tok.String() != "type" {
-				continue
+			switch d.Tok {
+			case token.TYPE:
+				matchDecls = append(matchDecls, sc.collectTypeDecls(fset, d)...)
+			case token.VAR:
+				// 包级变量（@autowire.value）
+				matchDecls = append(matchDecls, sc.collectVarDecls(fset, d)...)
 			}
-			matchDecls = append(matchDecls, sc.collectTypeDecls(d)...)
 
 		case *ast.FuncDecl:
 			// 处理函数声明(构造函数)
-			if strings.Contains(d.Doc.Text(), config.WireTag) {
-				matchDecls = append(matchDecls, tmpDecl{
-					docs:   d.Doc.Text(),
+			if docs := docLines(fset, d.Doc); sc.hasAnnotation(docs) {
+				td := tmpDecl{
+					docs:   docs,
 					name:   d.Name.Name,
 					isFunc: true,
-				})
+					pos:    fset.Position(d.Name.Pos()),
+				}
+				// 方法工厂：组件名称为 接收者类型名+方法名，如 FactoryBuildClient
+				if d.Recv != nil {
+					recv := embedName(d.Recv.List[0].Type)
+					if recv == "" || d.Type.Results.NumFields() == 0 {
+						sc.logger.Warn("方法工厂的接收者必须为非泛型的具名类型且方法需要返回值，已忽略",
+							"method", d.Name.Name, "pos", td.pos)
+						continue
+					}
+					td.name = strcase.UpperCamelCase(recv) + d.Name.Name
+					td.method = d
+				}
+				matchDecls = append(matchDecls, td)
 			}
 		}
 	}
@@ -260,43 +638,90 @@ func (sc *AutoWireSearcher) collectAnnotatedDecls(parseFile *ast.File) []tmpDecl
 	return matchDecls
 }
 
-// collectTypeDecls method    收集类型声明中的注解.
-func (sc *AutoWireSearcher) collectTypeDecls(d *ast.GenDecl) []tmpDecl {
+// collectTypeDecls method    收集类型声明中的注解
+// 字段上带有环境变量注解的结构体同样收集，结构体本身未带注解时由 checkAnnotations 报告.
+func (sc *AutoWireSearcher) collectTypeDecls(fset *token.FileSet, d *ast.GenDecl) []tmpDecl {
 	var result []tmpDecl
-
-	// 情况1: 单个类型声明
-	// @autowire()
-	// type Some struct{}
-	if len(d.Specs) == 1 && strings.Contains(d.Doc.Text(), config.WireTag) {
-		if id, ok := d.Specs[0].(*ast.TypeSpec); ok {
-			result = append(result, tmpDecl{
-				docs:     d.Doc.Text(),
-				name:     id.Name.Name,
-				isFunc:   false,
-				typeSpec: id,
-			})
-		}
-		return result
-	}
-
-	// 情况2: 类型组声明
-	// type (
-	//     @autowire()
-	//     A struct{}
-	//     @autowire()
-	//     B struct{}
-	// )
 	for _, sp := range d.Specs {
-		if id, ok := sp.(*ast.TypeSpec); ok && strings.Contains(id.Doc.Text(), config.WireTag) {
-			result = append(result, tmpDecl{
-				docs:     id.Doc.Text(),
-				name:     id.Name.Name,
-				isFunc:   false,
-				typeSpec: id,
-			})
+		id, ok := sp.(*ast.TypeSpec)
+		if !ok {
+			continue
 		}
+		// 情况1: 单个类型声明，注解也可以写在行尾
+		// @autowire()
+		// type Some struct{}
+		// type Other struct{} // @autowire()
+		docs := specDocs(fset, d.Doc, id.Comment)
+		// 情况2: 类型组声明
+		// type (
+		//     @autowire()
+		//     A struct{}
+		//     B struct{} // @autowire()
+		// )
+		if len(d.Specs) != 1 || !sc.hasAnnotation(docs) {
+			docs = specDocs(fset, id.Doc, id.Comment)
+		}
+		fieldDocs := sc.collectFieldDocs(fset, id)
+		if !sc.hasAnnotation(docs) && len(fieldDocs) == 0 {
+			continue
+		}
+		if !sc.hasAnnotation(docs) {
+			docs = nil
+		}
+		result = append(result, tmpDecl{
+			docs:      docs,
+			fieldDocs: fieldDocs,
+			name:      id.Name.Name,
+			isFunc:    false,
+			typeSpec:  id,
+			pos:       fset.Position(id.Name.Pos()),
+		})
 	}
+	return result
+}
+
+// collectFieldDocs method    收集结构体字段上的环境变量注解，返回 字段 -> 文档注释与行尾注释的各行，没有时返回 nil
+// 字段注释中提到的其他注解（如 value bool // @autowire.value）不是字段的注解，忽略.
+func (sc *AutoWireSearcher) collectFieldDocs(fset *token.FileSet, ts *ast.TypeSpec) map[*ast.Field][]docLine {
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return nil
+	}
+	var fieldDocs map[*ast.Field][]docLine
+	for _, field := range st.Fields.List {
+		docs := specDocs(fset, field.Doc, field.Comment)
+		if slices.ContainsFunc(docs, sc.isEnvAnnotation) {
+			if fieldDocs == nil {
+				fieldDocs = make(map[*ast.Field][]docLine)
+			}
+			fieldDocs[field] = docs
+		}
+	}
+	return fieldDocs
+}
 
+// collectVarDecls method    收集变量声明中的注解，支持单个声明与分组声明.
+func (sc *AutoWireSearcher) collectVarDecls(fset *token.FileSet, d *ast.GenDecl) []tmpDecl {
+	var result []tmpDecl
+	for _, sp := range d.Specs {
+		vs, ok := sp.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != 1 {
+			continue
+		}
+		docs := specDocs(fset, vs.Doc, vs.Comment)
+		if len(d.Specs) == 1 && !sc.hasAnnotation(docs) {
+			docs = specDocs(fset, d.Doc, vs.Comment)
+		}
+		if !sc.hasAnnotation(docs) {
+			continue
+		}
+		result = append(result, tmpDecl{
+			docs:      docs,
+			name:      vs.Names[0].Name,
+			valueSpec: vs,
+			pos:       fset.Position(vs.Names[0].Pos()),
+		})
+	}
 	return result
 }
 
@@ -305,12 +730,9 @@ func (sc *AutoWireSearcher) parseAnnotations(matchDecls []tmpDecl, file string,
 	parseFile *ast.File, implementMap map[string]string) []Element {
 	var elements []Element
 	for _, decl := range matchDecls {
-		lines := strings.Split(decl.docs, "\n")
-		for _, c := range lines {
-			if elem := sc.analysisWireTag(strings.TrimSpace(c), file, pkgPath, &decl,
-				parseFile, implementMap); elem != nil {
-				elements = append(elements, *elem)
-			}
+		for _, line := range decl.docs {
+			elements = append(elements, sc.analysisWireTag(strings.TrimSpace(line.text), file, pkgPath, &decl,
+				parseFile, implementMap)...)
 		}
 	}
 	return elements
@@ -322,24 +744,74 @@ func (sc *AutoWireSearcher) getPkgPath(filePath string) (pkgPath string) {
 	return parser.GetPkgPath(filePath, sc.modBase)
 }
 
-// analysisWireTag method    解析单行 @autowire 注解，返回解析出的元素.
+// analysisWireTag method    解析单行 @autowire 注解，返回解析出的元素
+// set=db|app 将组件注册到多个 Set，每个 Set 返回一个元素.
 func (sc *AutoWireSearcher) analysisWireTag(tag, filePath string, pkgPath string, decl *tmpDecl, f *ast.File,
-	implementMap map[string]string) *Element {
-	// 检查是否为 @autowire 注解
-	if !strings.HasPrefix(tag, config.WireTag) {
+	implementMap map[string]string) []Element {
+	// 解析注解的后缀与参数，不是注解或缺少括号时忽略（语法问题由 checkAnnotations 报告）
+	a, err := annotations.ParseLoose(sc.annotation(), tag)
+	if err != nil {
 		return nil
 	}
+	itemFunc, options := a.Suffix, a.Map()
 
-	itemFunc, tagStr := sc.parseTagSuffix(tag)
+	// 组合 Set：记录下来，生成时引用包含的 Set
+	if itemFunc == compositeSuffix {
+		return elementList(sc.collectComposite(options, decl, f, pkgPath))
+	}
+	if decl.pkgDoc {
+		return nil
+	}
 
-	// 检查括号格式
-	if !strings.HasPrefix(tagStr, "(") || !strings.HasSuffix(tagStr, ")") {
+	// 自定义提供者只能写在类型声明上（由 checkAnnotations 报告），接口类型同样由表达式提供
+	raw := itemFunc == annotations.SuffixRaw
+	if raw && decl.typeSpec == nil {
 		return nil
 	}
+	// 读取环境变量的注解只能写在非结构体类型声明上，且需要能够确定解析方式（由 checkAnnotations 报告），
+	// 结构体字段上的注解单独处理
+	if itemFunc == annotations.SuffixEnv &&
+		(!envTypeDecl(decl) || options["name"] == "" || envKind(decl.typeSpec.Type, options) == "") {
+		return nil
+	}
+
+	// 接口声明：记录下来，扫描结束后查找实现
+	if decl.typeSpec != nil && !raw {
+		if _, ok := decl.typeSpec.Type.(*ast.InterfaceType); ok {
+			return elementList(sc.collectInterface(decl, f, pkgPath))
+		}
+	}
+
+	// mock Set 保留给测试替身，普通组件注册到 mock 时忽略（由 checkAnnotations 报告）
+	sets := slices.DeleteFunc(splitFieldList(options["set"]), isMockSetName)
+	if len(sets) == 0 {
+		sets = []string{""}
+	}
+	elements := make([]Element, 0, len(sets))
+	for _, set := range sets {
+		setOptions := maps.Clone(options)
+		setOptions["set"] = set
+		elem := sc.parseElement(itemFunc, setOptions, filePath, pkgPath, decl, f, implementMap)
+		elements = append(elements, elem)
+		// init、config 与测试替身组件生成到固定的 Set，只需要添加一次
+		if elem.Set != sc.determineSetName(setOptions) {
+			break
+		}
+	}
+	return elements
+}
 
-	// 解析注解参数
-	options := sc.parseTagOptions(tagStr)
+// elementList function    将可能为 nil 的元素转换为列表.
+func elementList(elem *Element) []Element {
+	if elem == nil {
+		return nil
+	}
+	return []Element{*elem}
+}
 
+// parseElement method    按注解参数解析组件并添加到 options["set"] 指定的 Set 中.
+func (sc *AutoWireSearcher) parseElement(itemFunc string, options map[string]string, filePath, pkgPath string,
+	decl *tmpDecl, f *ast.File, implementMap map[string]string) Element {
 	// 创建组件元素
 	wireElement := sc.createWireElement(decl, f, pkgPath)
 
@@ -352,71 +824,101 @@ func (sc *AutoWireSearcher) analysisWireTag(tag, filePath string, pkgPath string
 	// 解析其他选项
 	itemFunc = sc.parseOptions(options, &wireElement, f, itemFunc)
 
+	// 没有 out= 参数时使用配置文件中 Set 的输出目录
+	if wireElement.Out == "" {
+		wireElement.Out = sc.setOutputs[setName]
+	}
+
+	// 泛型声明的类型参数与 of= 指定的类型实参
+	typeArgs := sc.resolveTypeParams(&wireElement, decl, f, pkgPath, options["of"])
+
 	// 处理特殊函数标记
 	setName = sc.handleSpecialFunctions(itemFunc, setName, &wireElement, decl)
 
-	// 添加接口实现关系
-	sc.addInterfaceImplementations(&wireElement, implementMap, decl.name)
+	// 自定义提供者表达式：不查找构造函数与注入字段，只记录提供的类型
+	if wireElement.Raw != "" {
+		sc.applyRaw(&wireElement, decl, f, pkgPath, options, implementMap)
+		wireElement.Set = setName
+		sc.addElementToMap(setName, pkgPath, wireElement, instanceName(wireElement))
+		return wireElement
+	}
 
-	// 将组件添加到 elementMap
-	sc.addElementToMap(setName, pkgPath, wireElement, decl.name)
+	// 读取环境变量的类型：生成 Provide<Type> 作为构造函数
+	if itemFunc == annotations.SuffixEnv {
+		sc.applyEnv(&wireElement, decl, f, pkgPath, options, implementMap)
+		wireElement.Set = setName
+		sc.addElementToMap(setName, pkgPath, wireElement, instanceName(wireElement))
+		return wireElement
+	}
 
-	return &wireElement
-}
+	// wire.Struct 注入的字段
+	sc.resolveStructFields(&wireElement, decl, options)
 
-// parseTagSuffix method    解析 .init 或 .config 后缀.
-func (sc *AutoWireSearcher) parseTagSuffix(tag string) (itemFunc, tagStr string) {
-	tagStr = tag[len(config.WireTag):] // 去掉 @autowire 前缀
+	// 生命周期方法 Start(ctx) error、Stop(ctx) error
+	sc.resolveLifecycle(&wireElement, decl, f, filePath, options)
 
-	// 解析 .init 或 .config 后缀
-	// 例如: @autowire.init(set=zoo)
-	if len(tagStr) > 0 && tagStr[0] == '.' {
-		idx := strings.IndexRune(tagStr, '(')
-		if idx != -1 {
-			itemFunc = tagStr[1:idx] // 提取 init 或 config
-			tagStr = tagStr[idx:]
-		}
-	}
-	return
-}
+	// 添加接口实现关系（函数声明按返回值类型查找）
+	sc.addInterfaceImplementations(&wireElement, implementMap, sc.implName(decl, f))
 
-// parseTagOptions method    解析注解参数.
-func (sc *AutoWireSearcher) parseTagOptions(tagStr string) map[string]string {
-	options := make(map[string]string, 4) // 预分配容量，通常注解参数不超过4个
-	content := strings.TrimPrefix(strings.TrimSuffix(tagStr, ")"), "(")
+	// 解析提供与依赖的类型，用于依赖图分析
+	sc.resolveDeps(&wireElement, decl, f, pkgPath)
 
-	for _, s := range strings.Split(content, ",") {
-		if s = strings.TrimSpace(s); len(s) == 0 {
-			continue
-		}
-		spo := strings.Split(s, "=")
-		v := ""
-		if len(spo) > 1 {
-			v = strings.TrimSpace(spo[1])
-		}
-		options[strings.TrimSpace(spo[0])] = v
-	}
-	return options
+	// 结构体字段上的 @autowire.env：生成 Provide<Type> 替代 wire.Struct
+	sc.applyEnvFields(&wireElement, decl, f, pkgPath)
+
+	// 泛型组件的实例化生成非泛型的包装构造函数
+	sc.applyTypeArgs(&wireElement, decl, f, typeArgs)
+
+	// 分组成员绑定的接口作为分组切片的元素类型
+	sc.resolveGroup(&wireElement, f, pkgPath)
+
+	// 为方法工厂生成包装函数
+	sc.applyMethodFactory(&wireElement, decl, f)
+
+	// 生成限定类型，替换绑定的接口或构造函数
+	sc.applyQualifier(&wireElement, decl, f, pkgPath)
+
+	// scope=factory 生成工厂函数类型，替换构造函数
+	sc.applyScope(&wireElement, decl, f, pkgPath)
+
+	wireElement.Set = setName
+
+	// 将组件添加到 elementMap（同一泛型声明的不同实例化分别添加）
+	sc.addElementToMap(setName, pkgPath, wireElement, instanceName(wireElement))
+
+	return wireElement
 }
 
 // createWireElement method    创建组件元素.
 func (sc *AutoWireSearcher) createWireElement(decl *tmpDecl, f *ast.File, pkgPath string) Element {
 	return Element{
-		Name:    decl.name,
-		Pkg:     f.Name.Name,
-		PkgPath: pkgPath,
+		Name:     decl.name,
+		Pkg:      f.Name.Name,
+		PkgPath:  pkgPath,
+		FuncDecl: decl.isFunc,
+		Position: decl.pos,
+		// 包级变量（@autowire.value）通过 wire.Value 提供
+		ValueWire:  decl.valueSpec != nil,
+		Underlying: underlyingType(decl),
 	}
 }
 
 // determineConstructor method    确定构造函数.
 func (sc *AutoWireSearcher) determineConstructor(wireElement *Element, decl *tmpDecl, f *ast.File) {
-	if decl.isFunc {
+	if decl.valueSpec != nil {
+		// 变量没有构造函数
+		return
+	}
+	if decl.method != nil {
+		// 方法工厂使用生成的包装函数作为构造函数
+		wireElement.Constructor = "Provide" + decl.name
+	} else if decl.isFunc {
 		// 如果是函数声明，函数本身就是构造函数
 		wireElement.Constructor = decl.name
 	} else {
-		// 如果是结构体，查找 New<Name> 或 Init<Name> 构造函数
+		// 如果是结构体，在整个包中查找 New<Name> 或 Init<Name> 构造函数
 		for _, constructorPrefix := range []string{"Init", "New"} {
-			if ct, ok := f.Scope.Objects[constructorPrefix+decl.name]; ok && ct.Kind == ast.Fun {
+			if sc.findFuncDecl(f, constructorPrefix+decl.name) != nil {
 				wireElement.Constructor = constructorPrefix + decl.name
 				break
 			}
@@ -437,23 +939,98 @@ func (sc *AutoWireSearcher) parseOptions(options map[string]string, wireElement
 	itemFunc string) string {
 	resultFunc := itemFunc
 
-	for key, value := range options {
+	// 按参数名顺序处理，绑定的接口与生成的导入别名保持稳定
+	for _, key := range parser.SortedKeys(options) {
+		value := options[key]
 		switch key {
 		case "init", "config":
 			// 如果在参数中指定 init 或 config
 			resultFunc = key
-		case "set":
-			// set 已经处理过，跳过
+		case "value":
+			// 按值绑定接口：wire.Bind(new(I), new(T))
+			wireElement.BindValue = true
+		case "set", "of", "fields", "exclude", "lifecycle":
+			// set 已经处理过，of 在解析类型参数时处理，fields 与 exclude 在确定注入字段时处理，
+			// lifecycle 在查找生命周期方法时处理，跳过
 			continue
 		case "new":
-			// 自定义构造函数名称
-			if ct, ok := f.Scope.Objects[value]; ok && ct.Kind == ast.Fun {
+			// 自定义构造函数名称，可以声明在包中的任意文件中
+			if sc.findFuncDecl(f, value) != nil {
 				wireElement.Constructor = value
 			}
 			continue
+		case "priority":
+			// 重复绑定时的优先级，数值越大越优先
+			wireElement.Priority, _ = strconv.Atoi(value)
+			continue
+		case "name":
+			// 命名注入入口，生成 Initialize<Name>
+			wireElement.Injector = strcase.UpperCamelCase(value)
+			continue
+		case "ctx":
+			// 初始化函数以 ctx context.Context 为第一个参数，只对 @autowire.init 有效
+			wireElement.Context = true
+			continue
+		case "args":
+			// 初始化函数的额外参数，传给接收这些类型的构造函数，只对 @autowire.init 有效
+			sc.resolveInjectorArgs(wireElement, f, value)
+			continue
+		case "tag":
+			// 构建标签，生成到带 //go:build 约束的独立文件（无效的标签由 checkAnnotations 报告）
+			if annotations.ValidBuildTag(value) {
+				wireElement.Tag = value
+			}
+			continue
+		case "qualifier":
+			// 限定名，生成 <Qualifier><Type> 限定类型
+			wireElement.Qualifier = value
+			continue
+		case "scope":
+			// 作用域，factory 生成 <Type>Factory 工厂函数类型，singleton 为默认行为
+			if value == scopeFactory {
+				wireElement.Scope = value
+			}
+			continue
+		case "group":
+			// 分组，分组的全部成员汇总为切片注入
+			wireElement.Group = strcase.LowerCamelCase(value)
+			continue
+		case "out":
+			// 输出目录，Set 生成到该目录的独立包中
+			wireElement.Out = filepath.ToSlash(filepath.Clean(value))
+			continue
+		case "impl":
+			// 完整路径形式的接口，如 impl="github.com/foo/bar.Store"，多个接口以 | 分隔
+			for _, itf := range splitFieldList(strings.Trim(value, `"`)) {
+				name, kind := splitBindKind(itf)
+				addInterface(wireElement, name, kind)
+			}
+			continue
+		case "for":
+			// 测试替身替代的接口（@autowire.mock(for=Store)），多个接口以 | 分隔
+			for _, itf := range splitFieldList(value) {
+				wireElement.Implements = appendUnique(wireElement.Implements, externalInterface(f, itf))
+			}
+			continue
+		case "expr":
+			// 自定义提供者表达式，只对 @autowire.raw 有效（格式错误由 checkAnnotations 报告）
+			sc.resolveRawExpr(wireElement, f, value)
+			continue
+		case "type", "default":
+			// 环境变量的解析类型与默认值，生成读取环境变量的提供者时处理
+			if itemFunc != annotations.SuffixEnv {
+				sc.logger.Warn(key+" 参数只对 @autowire.env 有效，已忽略", "element", describeElement(*wireElement))
+			}
+			continue
+		case "deprecated":
+			// 已弃用，仍然生成提供者，生成时列出用到它的初始化函数
+			wireElement.Deprecated = strings.Trim(value, `"`)
+			continue
 		default:
-			// 其他参数视为接口名称
-			wireElement.Implements = append(wireElement.Implements, key)
+			// 其他参数视为接口名称，无法按包名直接导入的接口转换为完整路径形式
+			// 接口名可以带 :ptr 或 :value 标记，单独指定该接口绑定 *T 还是 T
+			name, kind := splitBindKind(key)
+			addInterface(wireElement, externalInterface(f, name), kind)
 		}
 	}
 	return resultFunc
@@ -469,10 +1046,31 @@ func (sc *AutoWireSearcher) handleSpecialFunctions(itemFunc, setName string, wir
 		// @autowire.init - 标记为初始化入口
 		wireElement.InitWire = true
 		resultSetName = "init"
+		if wireElement.Injector != "" {
+			// 命名注入入口使用独立的 Set
+			resultSetName = "init" + wireElement.Injector
+		}
 	case "config":
 		// @autowire.config - 配置注入模式
 		sc.handleConfigFunction(wireElement, decl)
 		resultSetName = "config"
+	case "mock":
+		// @autowire.mock - 测试替身，只生成到测试注入包的 MockSet
+		wireElement.Mock = true
+		resultSetName = mockSet
+
+	}
+	if wireElement.Raw != "" && itemFunc != annotations.SuffixRaw {
+		sc.logger.Warn("expr 参数只对 @autowire.raw 有效，已忽略", "element", describeElement(*wireElement))
+		wireElement.Raw, wireElement.RawExpr = "", nil
+	}
+	if wireElement.Context && !wireElement.InitWire {
+		sc.logger.Warn("ctx 参数只对 @autowire.init 有效，已忽略", "element", describeElement(*wireElement))
+		wireElement.Context = false
+	}
+	if len(wireElement.Args) > 0 && !wireElement.InitWire {
+		sc.logger.Warn("args 参数只对 @autowire.init 有效，已忽略", "element", describeElement(*wireElement))
+		wireElement.Args, wireElement.ArgTypes = nil, nil
 	}
 	return resultSetName
 }
@@ -535,7 +1133,11 @@ func (sc *AutoWireSearcher) addInterfaceImplementations(wireElement *Element,
 
 // addElementToMap method    将组件添加到 elementMap.
 func (sc *AutoWireSearcher) addElementToMap(setName, pkgPath string, wireElement Element, name string) {
-	log.Printf("收集到 wire 对象 [ %sSet ] : %s\n", strcase.LowerCamelCase(setName), wireElement.Pkg+"."+wireElement.Name)
+	sc.logger.Debug("收集到 wire 对象", logger.EventKey, logger.EventElement,
+		"set", strcase.LowerCamelCase(setName)+"Set", "element", wireElement.Pkg+"."+wireElement.Name)
+	if sc.streaming {
+		return
+	}
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
@@ -551,45 +1153,142 @@ func (sc *AutoWireSearcher) addElementToMap(setName, pkgPath string, wireElement
 // 2. 生成汇总文件（autowire_sets.go）
 // 3. 生成初始化入口文件(wire.gen.go).
 func (sc *AutoWireSearcher) Write() error {
-	log.Printf("正在生成文件到目录 [ %s ] ...", sc.genPath)
+	sc.logger.Info("正在生成文件到目录", "path", sc.genPath)
 	sc.sets = nil
+	sc.initElements, sc.configElements = nil, nil
+	sc.produced = &fileList{}
+	sc.written = &fileList{}
+	sc.providers = &providerSources{}
+	sc.setFiles = &setFiles{}
+
+	// 整理并校验扫描结果（重复接口绑定、注入入口名称）
+	if err := sc.Prepare(); err != nil {
+		return err
+	}
+	if err := sc.Validate(); err != nil {
+		return err
+	}
 
-	// 确保目标目录存在
-	if err := os.MkdirAll(sc.genPath, 0750); err != nil {
-		return fmt.Errorf("创建目录 %s 失败: %w", sc.genPath, err)
+	// 生成组件所在包中的限定类型、方法工厂包装函数与读取环境变量的提供者，并删除 fx 后端生成的 Provide 函数
+	if err := sc.writeQualifiers(); err != nil {
+		return err
+	}
+	if err := sc.writeFactories(); err != nil {
+		return err
+	}
+	if err := sc.writeEnvProviders(); err != nil {
+		return err
+	}
+	if err := sc.writeSourceFiles(sc.genFileName(fxProviderFile), nil); err != nil {
+		return err
 	}
 
-	// 清理旧文件
-	if err := sc.clean(); err != nil {
-		return fmt.Errorf("清理旧文件失败: %w", err)
+	// 按输出目录生成 Set 文件、汇总文件和初始化文件
+	if err := sc.writeOutputs(); err != nil {
+		return err
 	}
 
-	// 并发生成每个 Set 的文件
-	for set, m := range sc.ElementMap {
-		// set, m := set, m // 捕获循环变量
-		sc.wg.Go(func() error {
-			return sc.writeSet(set, m)
-		})
+	// 存在测试替身时生成测试注入包
+	if err := sc.writeMockOutput(); err != nil {
+		return err
 	}
 
-	// 等待所有 Set 文件生成完成
-	if err := sc.wg.Wait(); err != nil {
-		return fmt.Errorf("生成 Set 文件失败: %w", err)
+	// 删除上次生成、本次不再生成的文件
+	sc.pruneStale()
+
+	// 保存缓存（包含生成文件的指纹）
+	sc.saveCache()
+	return nil
+}
+
+// checkInjectorNames method    检查命名注入入口是否重名.
+func (sc *AutoWireSearcher) checkInjectorNames() error {
+	seen := make(map[string]Element)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for _, key := range parser.SortedKeys(sc.ElementMap[set]) {
+			elem := sc.ElementMap[set][key]
+			if !elem.InitWire || elem.Injector == "" {
+				continue
+			}
+			if prev, ok := seen[elem.Injector]; ok {
+				return errors.NewInvalidAnnotationError("@autowire.init(name="+elem.Injector+")",
+					fmt.Sprintf("注入入口名称重复: %s 与 %s", describeElement(prev), describeElement(elem)))
+			}
+			seen[elem.Injector] = elem
+		}
+	}
+	return nil
+}
+
+// setVarName function    返回 Set 对应的变量名，如 animals 返回 AnimalsSet.
+func setVarName(set string) string {
+	return cases.Title(language.Und, cases.NoLower).String(strcase.UpperCamelCase(set)) + "Set"
+}
+
+// isInjectorSet function    判断是否为命名注入入口的独立 Set.
+func isInjectorSet(elements map[string]Element) bool {
+	for _, elem := range elements {
+		if elem.InitWire && elem.Injector != "" {
+			return true
+		}
 	}
+	return false
+}
 
-	// 保存缓存
-	if err := sc.cache.Save(); err != nil {
-		log.Printf("[warn] 保存缓存失败: %v", err)
+// Prepare method    整理扫描结果，供校验与生成使用，重复调用结果相同
+// 按 Set 过滤与标签筛选组件、移除不包含组件的 Set、添加分组汇总提供者，
+// 并按配置的策略处理同一 Set 中与不同 Set 之间的重复接口绑定（error 策略下返回错误）.
+func (sc *AutoWireSearcher) Prepare() error {
+	// 不包含任何组件的 Set 不生成文件（先移除上次添加的分组汇总提供者）
+	sc.removeGroupProviders()
+	sc.applyOnlySets()
+	maps.DeleteFunc(sc.ElementMap, func(_ string, elements map[string]Element) bool {
+		return len(elements) == 0
+	})
+	sc.applySetTags()
+	if err := sc.resolveGroups(); err != nil {
+		return err
+	}
+	if err := sc.resolveDuplicateBindings(); err != nil {
+		return err
 	}
+	return sc.resolveSetConflicts()
+}
 
-	// 生成汇总文件和初始化文件
-	return sc.writeSets()
+// Validate method    在不修改扫描结果、不写入任何文件的情况下校验 Prepare 整理后的扫描结果
+// 校验提供相同类型的多个组件、wire.Struct 中类型相同的字段、组合 Set 包含的 Set 以及注入入口名称是否重复.
+func (sc *AutoWireSearcher) Validate() error {
+	if err := sc.checkDuplicateProviders(); err != nil {
+		return err
+	}
+	if err := sc.checkTypeArgs(); err != nil {
+		return err
+	}
+	if err := sc.checkNonStructTypes(); err != nil {
+		return err
+	}
+	if err := sc.checkConstructors(); err != nil {
+		return err
+	}
+	if err := sc.checkBindKinds(); err != nil {
+		return err
+	}
+	if err := sc.checkAmbiguousFields(); err != nil {
+		return err
+	}
+	if err := sc.checkComposites(); err != nil {
+		return err
+	}
+	return sc.checkInjectorNames()
 }
 
 // clean method    清理之前生成的文件
-// 删除所有 autowire_*.go 和 wire_gen.go 文件，为新的生成做准备.
-func (sc *AutoWireSearcher) clean() error {
+// 删除 wire_gen.go 以及不在 expected 中、带生成标记的 .go 文件，内容未变化的文件保留以便增量生成.
+func (sc *AutoWireSearcher) clean(expected []string) error {
 	entries, err := os.ReadDir(sc.genPath)
+	if os.IsNotExist(err) && sc.pending != nil {
+		return nil // 检查模式下不创建目录
+	}
 	if err != nil {
 		return fmt.Errorf("读取目录 %s 失败: %w", sc.genPath, err)
 	}
@@ -597,18 +1296,25 @@ func (sc *AutoWireSearcher) clean() error {
 		return nil
 	}
 
-	// 删除 wire_gen.go（由 wire 命令生成的文件）
-	if err := os.Remove(filepath.Join(sc.genPath, "wire_gen.go")); err != nil && !os.IsNotExist(err) {
-		log.Printf("[warn] 删除 wire_gen.go 失败: %v", err)
+	// 删除 wire_gen.go（由 wire 命令生成的文件，检查模式下不运行 wire，保持不变）
+	if sc.pending == nil {
+		if err := os.Remove(filepath.Join(sc.genPath, "wire_gen.go")); err != nil && !os.IsNotExist(err) {
+			sc.logger.Warn("删除 wire_gen.go 失败", "error", err)
+		}
 	}
 
-	// 删除所有 autowire_*.go 文件
+	// 删除过期的生成文件（大小写不敏感的文件系统上忽略大小写）：按生成标记而不是文件名判断，
+	// 修改 file_prefix/file_suffix 后旧命名的文件同样会被清理，不带标记的手写文件保留
 	for _, entry := range entries {
 		name := entry.Name()
-		if strings.HasPrefix(name, config.FilePrefix+"_") && strings.HasSuffix(name, ".go") {
-			filePath := filepath.Join(sc.genPath, name)
-			if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-				log.Printf("[warn] 删除文件 %s 失败: %v", name, err)
+		if entry.IsDir() || isExpectedFile(expected, name) || !isCleanable(name) {
+			continue
+		}
+		filePath := filepath.Join(sc.genPath, name)
+		//nolint:gosec
+		if data, err := os.ReadFile(filePath); err == nil && isGenerated(data) {
+			if err := sc.removeFile(filePath); err != nil && !os.IsNotExist(err) {
+				sc.logger.Warn("删除文件失败", "file", name, "error", err)
 			}
 		}
 	}
@@ -623,10 +1329,10 @@ func (sc *AutoWireSearcher) clean() error {
 func (sc *AutoWireSearcher) writeSet(set string, elements map[string]Element) error {
 	pkgMap := make(map[string]map[string]string) // 用于处理包名冲突
 
-	setName := cases.Title(language.Und, cases.NoLower).String(strcase.UpperCamelCase(set)) + "Set"
-	fileName := filepath.Join(sc.genPath, config.FilePrefix+"_"+strcase.SnakeCase(set)+".go")
+	setName := setVarName(set)
+	fileName := sc.setFileName(set)
 
-	log.Printf("正在生成 %s [ %s ]", setName, fileName)
+	sc.logger.Debug("正在生成 "+setName, "file", fileName)
 
 	// 收集所有元素的 key 并排序，保证生成顺序稳定
 	order := parser.SortedKeys(elements)
@@ -634,15 +1340,50 @@ func (sc *AutoWireSearcher) writeSet(set string, elements map[string]Element) er
 	// 处理包名冲突
 	sc.resolvePackageConflicts(elements, pkgMap, order)
 
+	// 注册到多个 Set 的组件生成到共享 Set，Set 自有的组件生成到私有 Set
+	var shared string
+	if own := splitShared(elements, sc.sharedElements()); own != nil {
+		shared, elements = sharedSetName, own
+		order = slices.DeleteFunc(order, func(k string) bool {
+			_, ok := own[k]
+			return !ok
+		})
+	}
+
+	// 带构建标签的组件生成到独立的文件，主 Set 引用其中的 TaggedSet
+	untagged, tagged := splitByTag(elements)
+	if len(tagged) > 0 {
+		if err := sc.writeTaggedSets(set, setName, tagged, order); err != nil {
+			return err
+		}
+		order = slices.DeleteFunc(order, func(k string) bool {
+			_, ok := untagged[k]
+			return !ok
+		})
+	}
+
 	// 生成 Wire 配置代码
-	data, importPkg := sc.generateWireConfig(setName, elements, order)
+	data, importPkg := sc.generateWireConfig(setName, untagged, order)
+	data.Tags = sc.setConstraints(set)
+	data.Shared = shared
+	if len(tagged) > 0 {
+		data.Items = append(data.Items, taggedSetName(setName))
+	}
 
 	// 写入文件
 	if err := sc.writeConfigFile(fileName, data, importPkg); err != nil {
 		return err
 	}
+	sc.setFiles.add(set, absPath(fileName))
 
-	// 记录 Set 名称
+	// 记录 Set 名称（拆分出的 Set 与带构建约束的 Set 由使用者自行组合，
+	// 命名注入入口的 Set 只用于对应的注入函数，均不加入汇总；包含共享组件的 Set 以私有 Set 加入汇总）
+	if sc.splitSets.Contains(set) || sc.setBuildTags[set] != "" || isInjectorSet(elements) {
+		return nil
+	}
+	if shared != "" {
+		setName = data.PrivateName()
+	}
 	sc.mu.Lock()
 	sc.sets = append(sc.sets, setName)
 	sc.mu.Unlock()
@@ -685,13 +1426,14 @@ func (sc *AutoWireSearcher) resolvePackageConflicts(elements map[string]Element,
 func (sc *AutoWireSearcher) generateWireConfig(setName string, elements map[string]Element,
 	order []string) (WireSet, []*ast.ImportSpec) {
 	var importPkg []*ast.ImportSpec
-	pathPkg := sc.getPkgPath(filepath.Join(sc.genPath, config.FilePrefix+"_"+
-		strcase.SnakeCase(strings.TrimSuffix(setName, "Set"))+".go"))
+	pathPkg := sc.getPkgPath(filepath.Join(sc.genPath,
+		sc.genFileName(strcase.SnakeCase(strings.TrimSuffix(setName, "Set")))))
 
 	data := WireSet{
 		Package: sc.pkg,
 		SetName: setName,
 	}
+	refs := newInterfaceRefs(pathPkg, elements)
 
 	// 为每个元素生成 Wire 配置代码
 	for _, key := range order {
@@ -703,26 +1445,28 @@ func (sc *AutoWireSearcher) generateWireConfig(setName string, elements map[stri
 			elem.Pkg = ""
 		}
 
+		// 泛型组件只在绑定接口时引用实例化的类型，提供者为组件包中的包装构造函数，无需导入类型实参的包
 		stName := parser.AppendPkg(elem.Pkg, elem.Name)
+		if len(elem.Implements) > 0 || len(elem.Wrappers) == 0 {
+			stName += typeArgList(&elem, refs)
+		}
 
 		if elem.ConfigWire {
 			// 配置模式：使用 wire.FieldsOf 提取字段
 			sc.handleConfigWireElement(&elem, &wireItem, stName)
 		} else {
 			// 普通模式
-			sc.handleNormalWireElement(&elem, &wireItem, stName)
+			sc.handleNormalWireElement(&elem, &wireItem, stName, refs)
 		}
 
 		data.Items = append(data.Items, strings.Join(wireItem, ",\n\t"))
+		sc.providers.add(wireItem, elements[key])
 
 		// 如果需要导入包，添加到 import 列表
-		if len(elem.Pkg) > 0 {
-			imp := sc.createImportSpec(&elem)
-			importPkg = append(importPkg, imp)
-		}
+		importPkg = append(importPkg, sc.elementImport(&elem)...)
 	}
 
-	return data, importPkg
+	return data, append(importPkg, refs.imports...)
 }
 
 // handleConfigWireElement method    处理配置类型的 Wire 元素.
@@ -740,25 +1484,28 @@ func (sc *AutoWireSearcher) handleConfigWireElement(elem *Element, wireItem *[]s
 }
 
 // handleNormalWireElement method    处理普通类型的 Wire 元素.
-func (sc *AutoWireSearcher) handleNormalWireElement(elem *Element, wireItem *[]string, stName string) {
-	if elem.Constructor != "" {
-		// 有构造函数，直接使用构造函数
+func (sc *AutoWireSearcher) handleNormalWireElement(elem *Element, wireItem *[]string, stName string,
+	refs *interfaceRefs) {
+	if elem.ValueWire {
+		sc.handleValueWireElement(elem, wireItem, stName, refs)
+		return
+	}
+	if len(elem.RawExpr) > 0 {
+		// 自定义提供者，原样使用表达式（引用的包按生成文件的导入重新引用）
+		*wireItem = append(*wireItem, rawExpr(elem.RawExpr, refs))
+	} else if elem.Constructor != "" {
+		// 有构造函数，直接使用构造函数（泛型组件为 applyTypeArgs 生成的包装构造函数）
 		*wireItem = append(*wireItem, parser.AppendPkg(elem.Pkg, elem.Constructor))
 	} else {
-		// 没有构造函数，使用 wire.Struct 自动注入所有字段
-		*wireItem = append(*wireItem, fmt.Sprintf(`wire.Struct(new(%s), "*")`, stName))
+		// 没有构造函数，使用 wire.Struct 注入所有字段或 fields=、exclude= 指定的字段
+		*wireItem = append(*wireItem, fmt.Sprintf(`wire.Struct(new(%s)%s)`, stName, structFieldArgs(elem)))
 	}
 
 	// 添加接口绑定
 	for _, itf := range elem.Implements {
-		var itfName string
-		if strings.Contains(itf, ".") {
-			itfName = itf
-		} else {
-			itfName = parser.AppendPkg(elem.Pkg, itf)
-		}
-		// 生成 wire.Bind(new(Interface), new(*Implementation))
-		*wireItem = append(*wireItem, fmt.Sprintf(`wire.Bind(new(%s), new(*%s))`, itfName, stName))
+		itfName := sc.interfaceName(elem, itf, refs)
+		// 生成 wire.Bind(new(Interface), new(*Implementation))，按值提供时为 new(Implementation)
+		*wireItem = append(*wireItem, fmt.Sprintf(`wire.Bind(new(%s), new(%s))`, itfName, bindImpl(elem, stName, itf)))
 	}
 
 	// 如果标记为 init，添加到 initElements
@@ -769,6 +1516,87 @@ func (sc *AutoWireSearcher) handleNormalWireElement(elem *Element, wireItem *[]s
 	}
 }
 
+// structFieldArgs function    返回 wire.Struct 的字段参数（含前导逗号），未指定字段时为 "*"，全部排除时为空.
+func structFieldArgs(elem *Element) string {
+	if elem.StructFields == nil {
+		return `, "*"`
+	}
+	var args string
+	for _, field := range elem.StructFields {
+		args += ", " + strconv.Quote(field)
+	}
+	return args
+}
+
+// bindImpl function    返回接口 itf 的 wire.Bind 第二个参数中的实现类型
+// wire.Struct 提供的组件带 :ptr、:value 标记时分别为 *T、T；构造函数返回本包的类型时与返回值一致（T 或 *T），
+// 与返回值矛盾的标记由 checkBindKinds 报告；其他情况默认为 *T，value 参数表示按值绑定 T.
+func bindImpl(elem *Element, stName, itf string) string {
+	if !isStructProvider(elem) {
+		return defaultBindImpl(elem, stName)
+	}
+	switch elem.BindKinds[itf] {
+	case bindPtr:
+		return "*" + stName
+	case bindValue:
+		return stName
+	}
+	return defaultBindImpl(elem, stName)
+}
+
+// isStructProvider function    判断组件是否通过 wire.Struct 提供（没有构造函数与自定义表达式）.
+func isStructProvider(elem *Element) bool {
+	return elem.Constructor == "" && len(elem.RawExpr) == 0 && !elem.ValueWire
+}
+
+// defaultBindImpl function    返回未指定绑定方式的接口的实现类型.
+func defaultBindImpl(elem *Element, stName string) string {
+	if elem.Constructor != "" {
+		// 泛型构造函数的返回值如 *Cache[K, V]，实现类型使用 stName 中的类型实参，如 *repo.Cache[string, int]
+		result, args := elem.Result, ""
+		if elem.TypeParams > 0 {
+			result, args = typeBase(result), strings.TrimPrefix(stName, parser.AppendPkg(elem.Pkg, elem.Name))
+		}
+		if name := resultTypeName(result); name != "" {
+			if name == result {
+				return parser.AppendPkg(elem.Pkg, name) + args
+			}
+			return "*" + parser.AppendPkg(elem.Pkg, name) + args
+		}
+	}
+	if elem.BindValue {
+		return stName
+	}
+	return "*" + stName
+}
+
+// handleValueWireElement method    处理 @autowire.value 变量
+// 绑定了接口时生成 wire.InterfaceValue，否则生成 wire.Value.
+func (sc *AutoWireSearcher) handleValueWireElement(elem *Element, wireItem *[]string, varName string,
+	refs *interfaceRefs) {
+	if len(elem.Implements) == 0 {
+		*wireItem = append(*wireItem, fmt.Sprintf(`wire.Value(%s)`, varName))
+		return
+	}
+	for _, itf := range elem.Implements {
+		itfName := sc.interfaceName(elem, itf, refs)
+		*wireItem = append(*wireItem, fmt.Sprintf(`wire.InterfaceValue(new(%s), %s)`, itfName, varName))
+	}
+}
+
+// interfaceName method    返回生成代码中接口的引用形式.
+func (sc *AutoWireSearcher) interfaceName(elem *Element, itf string, refs *interfaceRefs) string {
+	switch {
+	case strings.Contains(itf, "/"):
+		// 完整路径形式（自动发现的跨包接口），需要单独导入接口所在的包
+		return refs.ref(itf)
+	case strings.Contains(itf, "."):
+		return itf
+	default:
+		return parser.AppendPkg(elem.Pkg, itf)
+	}
+}
+
 // createImportSpec method    创建导入规范.
 func (sc *AutoWireSearcher) createImportSpec(elem *Element) *ast.ImportSpec {
 	imp := &ast.ImportSpec{
@@ -778,32 +1606,76 @@ func (sc *AutoWireSearcher) createImportSpec(elem *Element) *ast.ImportSpec {
 		},
 	}
 	// 如果包名与路径最后一段不同，需要指定别名
-	_, last := filepath.Split(elem.PkgPath)
-	if last != elem.Pkg {
+	// 导入路径始终以 / 分隔，不能使用 filepath 处理
+	if path.Base(elem.PkgPath) != elem.Pkg {
 		imp.Name = ast.NewIdent(elem.Pkg)
 	}
 	return imp
 }
 
+// sortImports function    按导入路径与别名排序并去除重复的导入，保证多次生成的结果一致.
+func sortImports(specs []*ast.ImportSpec) []*ast.ImportSpec {
+	key := func(imp *ast.ImportSpec) string {
+		if imp.Name != nil {
+			return imp.Path.Value + " " + imp.Name.Name
+		}
+		return imp.Path.Value
+	}
+	sorted := slices.SortedFunc(slices.Values(specs), func(a, b *ast.ImportSpec) int {
+		return strings.Compare(key(a), key(b))
+	})
+	return slices.CompactFunc(sorted, func(a, b *ast.ImportSpec) bool {
+		return key(a) == key(b)
+	})
+}
+
 // writeConfigFile method    写入配置文件.
 func (sc *AutoWireSearcher) writeConfigFile(fileName string, data WireSet, importPkgs []*ast.ImportSpec) error {
+	return sc.writeTemplateFile(fileName, SetTemp, data, importPkgs)
+}
+
+// writeTemplateFile method    使用模板生成代码，添加 import 语句后格式化并写入文件.
+func (sc *AutoWireSearcher) writeTemplateFile(fileName string, tmpl *template.Template, data any,
+	importPkgs []*ast.ImportSpec) error {
 	fs := token.NewFileSet()
 	src := bytes.NewBuffer(nil)
 
 	// 使用模板生成基础代码
-	if err := SetTemp.Execute(src, data); err != nil {
+	if err := tmpl.Execute(src, data); err != nil {
 		return fmt.Errorf("执行模板失败: %w", err)
 	}
 
+	// 模板内容与导入均未变化时跳过格式化与写入
+	imps := parser.Map(importPkgs, func(imp *ast.ImportSpec) string {
+		if imp.Name != nil {
+			return imp.Name.Name + " " + imp.Path.Value
+		}
+		return imp.Path.Value
+	})
+	input := append(slices.Clone(src.Bytes()), importsInput(imps)...)
+	return sc.writeIfChanged(fileName, input, func() error {
+		return sc.formatAndWrite(fs, fileName, src.Bytes(), importPkgs)
+	})
+}
+
+// formatAndWrite method    解析模板生成的代码，添加排序去重后的 import 语句，格式化并写入文件.
+func (sc *AutoWireSearcher) formatAndWrite(fs *token.FileSet, fileName string, src []byte, importPkgs []*ast.ImportSpec) error {
 	// 解析生成的代码，添加 import 语句
-	f, err := goparser.ParseFile(fs, "", src, goparser.ParseComments)
+	f, err := goparser.ParseFile(fs, fileName, src, goparser.ParseComments)
 	if err != nil {
 		return fmt.Errorf("解析生成的代码失败: %w", err)
 	}
-	if decl, ok := f.Decls[0].(*ast.GenDecl); ok {
-		for _, imp := range importPkgs {
-			decl.Specs = append(decl.Specs, imp)
+	// 通过 astutil 添加导入以分配位置，直接追加没有位置的导入会使导入块之后的注释移入导入块
+	for _, imp := range sortImports(importPkgs) {
+		var name string
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return fmt.Errorf("解析导入路径 %s 失败: %w", imp.Path.Value, err)
 		}
+		astutil.AddNamedImport(fs, f, name, path)
 	}
 
 	// 格式化代码
@@ -813,36 +1685,49 @@ func (sc *AutoWireSearcher) writeConfigFile(fileName string, data WireSet, impor
 	}
 
 	// 处理 import 并写入文件
-	return parser.ImportAndWrite(fileName, setDataBuf.Bytes())
+	return sc.writeGenerated(fileName, setDataBuf.Bytes())
 }
 
 // writeSets method    生成汇总文件和初始化入口文件
-// 生成两个文件：
+// 生成四个文件：
 // 1. autowire_sets.go - 包含所有 Set 的汇总
-// 2. wire.gen.go - 包含初始化函数入口.
+// 2. wire.gen.go - 包含初始化函数入口
+// 3. autowire_lifecycle.go - 生命周期组件的启动与停止（存在生命周期组件时）
+// 4. autowire_groups.go - 分组的汇总函数（存在 group= 参数时）.
 func (sc *AutoWireSearcher) writeSets() error {
 	if len(sc.sets) == 0 {
 		return nil
 	}
 
 	// 任务1: 生成 autowire_sets.go
-	sc.wg.Go(func() error {
+	g := sc.group()
+	g.Go(func() error {
 		return sc.writeSetsFile()
 	})
 
 	// 任务2: 生成 wire.gen.go（初始化函数入口）
-	sc.wg.Go(func() error {
+	g.Go(func() error {
 		return sc.writeInitFile()
 	})
 
-	return sc.wg.Wait()
+	// 任务3: 生成 autowire_lifecycle.go（存在生命周期组件时）
+	g.Go(func() error {
+		return sc.writeLifecycleFile()
+	})
+
+	// 任务4: 生成 autowire_groups.go（存在分组时）
+	g.Go(func() error {
+		return sc.writeGroupsFile()
+	})
+
+	return g.Wait()
 }
 
 // writeSetsFile method    生成 autowire_sets.go 文件.
 func (sc *AutoWireSearcher) writeSetsFile() error {
 	slices.Sort(sc.sets)
 
-	fileName := filepath.Join(sc.genPath, config.FilePrefix+"_sets.go")
+	fileName := filepath.Join(sc.genPath, sc.genFileName(setsFile))
 	bf := bytes.NewBuffer(nil)
 
 	// 创建一个包含所有 Set 的大 Set
@@ -858,52 +1743,81 @@ func (sc *AutoWireSearcher) writeSetsFile() error {
 	}
 
 	// 写入文件
-	return parser.ImportAndWrite(fileName, bf.Bytes())
+	return sc.writeIfChanged(fileName, bf.Bytes(), func() error {
+		return sc.writeGenerated(fileName, bf.Bytes())
+	})
 }
 
 // writeInitFile method    生成 wire.gen.go 初始化文件.
 func (sc *AutoWireSearcher) writeInitFile() error {
-	// 如果没有 init 元素或未指定 initWire，跳过
-	if len(sc.initElements) == 0 || len(sc.initWire) == 0 {
+	// 如果没有 init 元素，或未指定 initWire 且没有命名注入入口，跳过；显式指定的类型不需要 init 元素
+	hasNamed := slices.ContainsFunc(sc.initElements, func(e Element) bool { return e.Injector != "" })
+	explicit := len(sc.initWire) > 0 && !(len(sc.initWire) == 1 && sc.initWire[0] == "*")
+	if !explicit && (len(sc.initElements) == 0 || (len(sc.initWire) == 0 && !hasNamed)) {
 		return nil
 	}
 
 	// 按名称排序，保证生成的代码顺序稳定
-	slices.SortFunc(sc.initElements, func(a, b Element) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+	slices.SortFunc(sc.initElements, compareElements)
 
-	// 生成文件头部
-	inits := []string{fmt.Sprintf(initTemplateHead, sc.pkg)}
+	tmpl, err := sc.loadInitTemplate()
+	if err != nil {
+		return err
+	}
 
 	// 收集所有配置参数
-	configs := make([]string, 0, len(sc.configElements))
-	slices.SortFunc(sc.configElements, func(a, b Element) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+	params := make([]string, 0, len(sc.configElements))
+	slices.SortFunc(sc.configElements, compareElements)
 
 	// 为每个配置生成参数：c0 *Config, c1 *AnotherConfig
 	for i, c := range sc.configElements {
-		configs = append(configs, fmt.Sprintf(`c%d *%s`, i, parser.AppendPkg(c.Pkg, c.Name)))
+		params = append(params, fmt.Sprintf(`c%d *%s`, i, parser.AppendPkg(c.Pkg, c.Name)))
 	}
 
-	paramConfig := strings.Join(configs, ",")
+	data := InitFile{Package: sc.pkg}
 
 	// 生成初始化函数
-	if len(sc.initWire) == 1 && sc.initWire[0] == "*" {
-		// 为所有 init 元素生成初始化函数
+	switch {
+	case len(sc.initWire) == 0:
+		// 只生成命名注入入口
+	case len(sc.initWire) == 1 && sc.initWire[0] == "*":
+		// 为所有未命名的 init 元素生成初始化函数
 		for _, w := range sc.initElements {
-			inits = append(inits, fmt.Sprintf(initItemTemplate, w.Name, paramConfig, "*"+parser.AppendPkg(w.Pkg, w.Name)))
+			if w.Injector != "" {
+				continue
+			}
+			data.Injectors = append(data.Injectors, Injector{Name: sc.injectorPrefix + w.Name, Type: rootResult(w),
+				Params: injectorParams(w, params), Results: sc.initResults(w), Build: "Sets"})
 		}
-	} else {
+	default:
 		// 只为指定的类型生成初始化函数
 		for _, i := range sc.initWire {
-			sp := strings.Split(i, ".")
-			inits = append(inits, fmt.Sprintf(initItemTemplate, sp[len(sp)-1], paramConfig, i))
+			sp := strings.Split(strings.TrimPrefix(i, "*"), ".")
+			data.Injectors = append(data.Injectors, Injector{Name: sc.injectorPrefix + sp[len(sp)-1], Type: i,
+				Params: params, Results: fmt.Sprintf(fullInitResults, i), Build: "Sets"})
+		}
+	}
+
+	// 命名注入入口：使用公共 Sets 与各自独立的 Set
+	for _, w := range sc.initElements {
+		if w.Injector == "" {
+			continue
 		}
+		data.Injectors = append(data.Injectors, Injector{Name: sc.injectorPrefix + w.Injector, Type: rootResult(w),
+			Params: injectorParams(w, params), Results: sc.initResults(w),
+			Build: "Sets, " + setVarName("init"+w.Injector)})
 	}
 
+	// 存在生命周期组件时为每个注入入口生成 Initialize<Name>App
+	data.Injectors = append(data.Injectors, sc.appInjectors(params)...)
+
 	// 写入 wire.gen.go
-	wireGenData := strings.Join(inits, "\n")
-	return parser.ImportAndWrite(filepath.Join(sc.genPath, "wire.gen.go"), []byte(wireGenData))
+	bf := bytes.NewBuffer(nil)
+	if err := tmpl.Execute(bf, data); err != nil {
+		return initTemplateError(sc.initTemplate, err)
+	}
+	fileName := filepath.Join(sc.genPath, initFile)
+	return sc.writeIfChanged(fileName, bf.Bytes(), func() error {
+		return sc.writeGenerated(fileName, bf.Bytes())
+	})
 }
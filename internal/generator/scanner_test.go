@@ -0,0 +1,463 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestScanFilters(t *testing.T) {
+	root := parser.GetGoModDir()
+	sc := &AutoWireSearcher{
+		mu:          &sync.Mutex{},
+		excludeDirs: []string{"vendor", "internal/legacy/**", "**/*.pb.go"},
+		includeOnly: []string{"services", "pkg/*/api/"},
+	}
+
+	excluded := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"vendor", true, true},
+		{"services/vendor", true, true},
+		{"internal/legacy", true, true},
+		{"internal/legacy/db", true, true},
+		{"internal/legacy.go", false, false},
+		{"services/user/user.pb.go", false, true},
+		{"services/user/user.go", false, false},
+	}
+	for _, tt := range excluded {
+		if got := sc.isExcluded(filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("isExcluded(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	included := []struct {
+		path string
+		want bool
+	}{
+		{"services/user/user.go", true},
+		{"pkg/order/api/handler.go", true},
+		{"pkg/order/internal/store.go", false},
+		{"cmd/main.go", false},
+	}
+	for _, tt := range included {
+		if got := sc.isIncluded(filepath.Join(root, tt.path)); got != tt.want {
+			t.Errorf("isIncluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if !(&AutoWireSearcher{mu: &sync.Mutex{}}).isIncluded(filepath.Join(root, "cmd/main.go")) {
+		t.Error("未配置 include_only 时应包含全部文件")
+	}
+}
+
+func TestHandleNormalWireElement_BindImpl(t *testing.T) {
+	tests := []struct {
+		name string
+		elem Element
+		want string
+	}{
+		{"指针构造函数", Element{Name: "Zoo", Constructor: "NewZoo", Result: "*Zoo"}, "new(*svc.Zoo)"},
+		{"值构造函数", Element{Name: "Zoo", Constructor: "NewZoo", Result: "Zoo"}, "new(svc.Zoo)"},
+		{"函数声明", Element{Name: "NewCat", Constructor: "NewCat", Result: "*Cat", FuncDecl: true}, "new(*svc.Cat)"},
+		{"wire.Struct", Element{Name: "Zoo"}, "new(*svc.Zoo)"},
+		{"value 参数", Element{Name: "Zoo", BindValue: true}, "new(svc.Zoo)"},
+		{":ptr 标记", Element{Name: "Zoo", BindKinds: map[string]string{"Animal": bindPtr}}, "new(*svc.Zoo)"},
+		{"构造函数忽略标记", Element{Name: "Zoo", Constructor: "NewZoo", Result: "*Zoo", BindKinds: map[string]string{
+			"Animal": bindPtr}}, "new(*svc.Zoo)"},
+		{"自定义表达式忽略标记", Element{Name: "Zoo", RawExpr: []string{"svc.Zoo{}"}, BindValue: true,
+			BindKinds: map[string]string{"Animal": bindPtr}}, "new(svc.Zoo)"},
+		{":value 标记", Element{Name: "Zoo", BindKinds: map[string]string{"Animal": bindValue}}, "new(svc.Zoo)"},
+		{"标记优先于 value 参数", Element{Name: "Zoo", BindValue: true, BindKinds: map[string]string{
+			"Animal": bindPtr}}, "new(*svc.Zoo)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &AutoWireSearcher{mu: &sync.Mutex{}}
+			elem := tt.elem
+			elem.Pkg, elem.Implements = "svc", []string{"Animal"}
+			var items []string
+			sc.handleNormalWireElement(&elem, &items, "svc."+elem.Name, newInterfaceRefs("example.com/wire", nil))
+			want := "wire.Bind(new(svc.Animal), " + tt.want + ")"
+			if got := items[len(items)-1]; got != want {
+				t.Errorf("bind = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations_BindKinds(t *testing.T) {
+	src := "package svc\n\n" +
+		"type Animal interface{ Name() string }\n\n" +
+		"type Store interface{ Get() string }\n\n" +
+		"// @autowire(set=svc,Animal:value,Store:ptr,impl=\"io.Closer:value\")\ntype Zoo struct{}\n"
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "svc.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f, getImplement(f))
+	if len(elements) != 1 {
+		t.Fatalf("elements = %v", elementNames(elements))
+	}
+
+	elem := elements[0]
+	var items []string
+	sc.handleNormalWireElement(&elem, &items, "svc.Zoo", newInterfaceRefs("example.com/wire", nil))
+	slices.Sort(items)
+	want := []string{
+		"wire.Bind(new(io.Closer), new(svc.Zoo))",
+		"wire.Bind(new(svc.Animal), new(svc.Zoo))",
+		"wire.Bind(new(svc.Store), new(*svc.Zoo))",
+		`wire.Struct(new(svc.Zoo), "*")`,
+	}
+	if !slices.Equal(items, want) {
+		t.Errorf("items =\n%v\nwant\n%v", items, want)
+	}
+}
+
+func TestParseAnnotations_MultipleSets(t *testing.T) {
+	src := "package infra\n\n" +
+		"// @autowire(set=db|app)\ntype Logger struct{}\n\n" +
+		"// @autowire(set=db,set=worker)\ntype Config struct{}\n\n" +
+		"// @autowire.init(set=app|worker)\ntype App struct{}\n"
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "infra.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	elements := sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "infra.go", "example.com/infra", f,
+		getImplement(f))
+
+	var got []string
+	for _, elem := range elements {
+		got = append(got, elem.Set+"/"+elem.Name)
+	}
+	// init 组件生成到固定的 Set，只添加一次
+	want := []string{"db/Logger", "app/Logger", "db/Config", "worker/Config", "init/App"}
+	if !slices.Equal(got, want) {
+		t.Errorf("elements = %v, want %v", got, want)
+	}
+	for _, set := range []string{"db", "app", "worker"} {
+		if len(sc.ElementMap[set]) == 0 {
+			t.Errorf("Set %s 中没有组件", set)
+		}
+	}
+}
+
+func TestParseAnnotations_FuncResult(t *testing.T) {
+	src := `package svc
+
+type Store interface{ Get() string }
+
+type Animal interface{ Name() string }
+
+type Zoo struct{}
+
+func (*Zoo) Name() string { return "" }
+
+var _ Animal = &Zoo{}
+
+// @autowire(set=svc,Store)
+func NewStore() Store { return nil }
+
+// @autowire(set=svc)
+func NewZoo() *Zoo { return &Zoo{} }
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "svc.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard()}
+	sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f, getImplement(f))
+
+	var items []string
+	refs := newInterfaceRefs("example.com/wire", nil)
+	for _, key := range parser.SortedKeys(sc.ElementMap["svc"]) {
+		elem := sc.ElementMap["svc"][key]
+		sc.handleNormalWireElement(&elem, &items, "svc."+elem.Name, refs)
+	}
+	want := "svc.NewStore;svc.NewZoo;wire.Bind(new(svc.Animal), new(*svc.Zoo))"
+	if got := strings.Join(items, ";"); got != want {
+		t.Errorf("items = %s, want %s", got, want)
+	}
+}
+
+func TestCheckConstructors(t *testing.T) {
+	src := `package svc
+
+import "net/http"
+
+type Store interface{ Get() string }
+
+// @autowire(set=svc)
+type Zoo struct{}
+
+func NewZoo() Zoo { return Zoo{} }
+
+// @autowire(set=svc,Store)
+type Repo struct{}
+
+func NewRepo() Store { return nil }
+
+// @autowire(set=svc)
+type Client struct{}
+
+func NewClient() http.Handler { return nil }
+
+// @autowire(set=svc,new=OpenCat)
+type Cat struct{}
+
+func OpenCat(name string) (*Zoo, error) { return nil, nil }
+
+// @autowire(set=svc,Store)
+type Shop struct{}
+
+func (*Shop) Get() string { return "" }
+
+func NewShop() Store { return &Shop{} }
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "svc.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, ElementMap: make(map[string]map[string]Element),
+		logger: logger.Discard(), fset: fset}
+	sc.parseAnnotations(sc.collectAnnotatedDecls(fset, f), "svc.go", "example.com/svc", f, getImplement(f))
+
+	// 返回类型本身、已实现的本包接口与其他包的类型（可能是接口）均视为匹配，未实现的本包接口视为不匹配
+	for _, key := range parser.SortedKeys(sc.ElementMap["svc"]) {
+		elem := sc.ElementMap["svc"][key]
+		if bad := elem.Name == "Cat" || elem.Name == "Repo"; (elem.BadResult != "") != bad {
+			t.Errorf("%s: BadResult = %q", elem.Name, elem.BadResult)
+		}
+	}
+	err = sc.checkConstructors()
+	if err == nil || !strings.Contains(err.Error(), "OpenCat(name string) (*Zoo, error) (svc.go:25:6)") ||
+		!strings.Contains(err.Error(), "svc.Cat (svc.go:23:6)") {
+		t.Errorf("checkConstructors() error = %v", err)
+	}
+}
+
+func TestCheckBindKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		elem    Element
+		wantErr bool
+	}{
+		{"wire.Struct", Element{Name: "Zoo", BindKinds: map[string]string{"Animal": bindValue}}, false},
+		{"与指针返回值一致", Element{Name: "Zoo", Constructor: "NewZoo", Result: "*Zoo",
+			BindKinds: map[string]string{"Animal": bindPtr}}, false},
+		{"与值返回值一致", Element{Name: "Zoo", Constructor: "NewZoo", Result: "Zoo",
+			BindKinds: map[string]string{"Animal": bindValue}}, false},
+		{"返回 T 标记 :ptr", Element{Name: "Zoo", Constructor: "NewZoo", Result: "Zoo",
+			BindKinds: map[string]string{"Animal": bindPtr}}, true},
+		{"返回 *T 标记 :value", Element{Name: "Zoo", Constructor: "NewZoo", Result: "*Zoo",
+			BindKinds: map[string]string{"Animal": bindValue}}, true},
+		{"其他包的返回值", Element{Name: "Zoo", Constructor: "NewZoo", Result: "http.Handler",
+			BindKinds: map[string]string{"Animal": bindValue}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elem := tt.elem
+			elem.Pkg, elem.Implements = "svc", []string{"Animal"}
+			sc := &AutoWireSearcher{mu: &sync.Mutex{},
+				ElementMap: map[string]map[string]Element{"svc": {"example.com/svc/Zoo": elem}}}
+			err := sc.checkBindKinds()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBindKinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "NewZoo") {
+				t.Errorf("checkBindKinds() error = %v, want constructor name", err)
+			}
+		})
+	}
+}
+
+func TestSearchAllPath_Generated(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"mock/mock_store.go": "// Code generated by MockGen. DO NOT EDIT.\n\npackage mock\n\n" +
+			"// @autowire(set=mocks)\ntype MockStore struct{}\n",
+		"api/v1/svc.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage v1\n\n" +
+			"// @autowire(set=api)\ntype Svc struct{}\n",
+		"internal/handler.go": "package internal\n\n// @autowire(set=http)\ntype Handler struct{}\n",
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scan := func(opts ...config.Option) *AutoWireSearcher {
+		t.Helper()
+		opts = append([]config.Option{config.WithPkg("wire"), config.WithLogger(logger.Discard())}, opts...)
+		sc := NewAutoWireSearcher(config.NewGenOpt(filepath.Join(dir, "wire"), opts...), "example.com/m")
+		if err := sc.SearchAllPath(dir); err != nil {
+			t.Fatal(err)
+		}
+		return sc
+	}
+
+	// 默认跳过生成的文件，并以空结果记录到缓存中
+	sc := scan()
+	if len(sc.ElementMap["mocks"]) != 0 || len(sc.ElementMap["api"]) != 0 || len(sc.ElementMap["http"]) != 1 {
+		t.Errorf("默认应只扫描手写的文件: %v", sc.ElementMap)
+	}
+	if elements, ok := sc.cache.Get(filepath.Join(dir, "mock/mock_store.go")); !ok || len(elements) != 0 {
+		t.Errorf("跳过的生成文件应以空结果缓存: %v, %v", elements, ok)
+	}
+
+	// 只扫描匹配 glob 的生成文件
+	sc = scan(config.WithIncludeGenerated("**/*.pb.go"))
+	if len(sc.ElementMap["mocks"]) != 0 || len(sc.ElementMap["api"]) != 1 {
+		t.Errorf("应只扫描匹配 glob 的生成文件: %v", sc.ElementMap)
+	}
+}
+
+func TestSearchAllPath_MaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                        "module example.com/m\n\ngo 1.21\n",
+		"svc/svc.go":                    "package svc\n\n// @autowire(set=svc)\ntype Svc struct{}\n",
+		"svc/repo/repo.go":              "package repo\n\n// @autowire(set=repo)\ntype Repo struct{}\n",
+		"web/node_modules/pkg/pkg.go":   "package pkg\n\n// @autowire(set=npm)\ntype Pkg struct{}\n",
+		"bazel-out/k8-fastbuild/out.go": "package out\n\n// @autowire(set=bazel)\ntype Out struct{}\n",
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+		config.WithLogger(logger.Discard()), config.WithMaxDepth(1))
+	sc := NewAutoWireSearcher(o, "example.com/m")
+	if err := sc.SearchAllPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.SortedKeys(sc.ElementMap); !slices.Equal(got, []string{"svc"}) {
+		t.Errorf("Sets = %v, want [svc]", got)
+	}
+	// 增量重新生成时同样按深度过滤
+	if sc.shouldScan(filepath.Join(dir, "svc/repo/repo.go")) || !sc.shouldScan(filepath.Join(dir, "svc/svc.go")) {
+		t.Error("shouldScan() 应跳过超过 max_depth 的文件")
+	}
+}
+
+func TestSearchAllPath_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"zoo.go": "package m\n\n// @autowire(set=svc)\ntype Zoo struct{}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+		config.WithLogger(logger.Discard()), config.WithContext(ctx))
+	sc := NewAutoWireSearcher(o, "example.com/m")
+	if err := sc.SearchAllPath(dir); !errors.Is(err, context.Canceled) {
+		t.Fatalf("SearchAllPath() error = %v, want context.Canceled", err)
+	}
+
+	// 增量生成时使用新的 ctx，取消后不再写入文件
+	sc.SetContext(context.Background())
+	if err := sc.SearchAllPath(dir); err != nil || len(sc.ElementMap["svc"]) != 1 {
+		t.Fatalf("SearchAllPath() error = %v, elements = %v", err, sc.ElementMap)
+	}
+	sc.SetContext(ctx)
+	if err := sc.Write(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wire", "autowire_svc.go")); !os.IsNotExist(err) {
+		t.Errorf("取消后不应写入 Set 文件: %v", err)
+	}
+}
+
+func TestSearchAllPath_Positions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"zoo.go": "package m\n\n// @autowire(set=svc)\ntype Zoo struct{}\n",
+		"cat.go": "package m\n\n// @autowire(set=svc)\ntype Cat struct{}\n\n// @autowire(set=svc,bogus=1)\ntype Dog struct{}\n",
+		"new.go": "package m\n\nfunc NewCat() *Cat { return &Cat{} }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		progress []int
+	)
+	o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+		config.WithLogger(logger.Discard()), config.WithParallel(4), config.WithProgress(func(done, total int) {
+			mu.Lock()
+			defer mu.Unlock()
+			if total != 3 {
+				t.Errorf("progress total = %d, want 3", total)
+			}
+			progress = append(progress, done)
+		}))
+	sc := NewAutoWireSearcher(o, "example.com/m")
+	if err := sc.SearchAllPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	// 收集完文件后报告一次 0，之后每个文件报告一次
+	slices.Sort(progress)
+	if !slices.Equal(progress, []int{0, 1, 2, 3}) {
+		t.Errorf("progress = %v, want [0 1 2 3]", progress)
+	}
+	// 并发解析的文件共享同一个文件集合，位置指向各自的源文件
+	want := map[string]string{
+		"Zoo": filepath.Join(dir, "zoo.go") + ":4:6",
+		"Cat": filepath.Join(dir, "cat.go") + ":4:6",
+		"Dog": filepath.Join(dir, "cat.go") + ":7:6",
+	}
+	for name, pos := range want {
+		if got := findElement(sc.ElementMap["svc"], name).Position.String(); got != pos {
+			t.Errorf("%s: Position = %s, want %s", name, got, pos)
+		}
+	}
+	if cat := findElement(sc.ElementMap["svc"], "Cat"); cat.Constructor != "NewCat" {
+		t.Errorf("Cat: Constructor = %q, want NewCat", cat.Constructor)
+	}
+	diags := sc.Diagnostics()
+	if len(diags) != 1 || diags[0].Position.Filename != filepath.Join(dir, "cat.go") || diags[0].Position.Line != 6 {
+		t.Errorf("diagnostics = %v", diags)
+	}
+}
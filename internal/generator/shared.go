@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// sharedSetName 共享 Set 的变量名，注册到多个 Set（set=db|app）的组件只在其中生成一次.
+const sharedSetName = "sharedProviders"
+
+// sharedElements method    返回注册到多个加入汇总 Sets 的 Set 中的组件（key -> 第一个 Set 中的元素）
+// 这些组件生成到共享 Set，各 Set 与汇总 Sets 分别引用，避免 wire 报告同一组件的重复绑定.
+func (sc *AutoWireSearcher) sharedElements() map[string]Element {
+	shared := make(map[string]Element)
+	count := make(map[string]int)
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		for key, elem := range sc.ElementMap[set] {
+			if elem.Interface || elem.Composite || elem.Mock || !sc.inSets(elem) {
+				continue
+			}
+			if count[key]++; count[key] == 1 {
+				shared[key] = elem
+			}
+		}
+	}
+	maps.DeleteFunc(shared, func(key string, _ Element) bool { return count[key] < 2 })
+	return shared
+}
+
+// splitShared function    从 Set 的组件中移除共享组件，返回 Set 自有的组件，Set 不包含共享组件时返回 nil.
+func splitShared(elements, shared map[string]Element) map[string]Element {
+	own := maps.Clone(elements)
+	maps.DeleteFunc(own, func(key string, _ Element) bool {
+		_, ok := shared[key]
+		return ok
+	})
+	if len(own) == len(elements) {
+		return nil
+	}
+	return own
+}
+
+// setsWithShared method    返回包含共享组件的组件 Set 与组合 Set，它们生成私有 Set 与包含共享 Set 的导出 Set.
+func (sc *AutoWireSearcher) setsWithShared() parser.Set[string] {
+	result := parser.NewSet[string]()
+	shared := sc.sharedElements()
+	if len(shared) == 0 {
+		return result
+	}
+	for set, elements := range sc.ElementMap {
+		if splitShared(elements, shared) != nil {
+			result.Add(set)
+		}
+	}
+	// 组合 Set 可以包含其他组合 Set，逐层传播直到不再变化
+	for changed := true; changed; {
+		changed = false
+		for _, c := range sc.composites {
+			if !result.Contains(c.Name) && slices.ContainsFunc(c.Includes, result.Contains) {
+				result.Add(c.Name)
+				changed = true
+			}
+		}
+	}
+	return result
+}
+
+// sharedFileName method    返回共享 Set 的生成文件路径.
+func (sc *AutoWireSearcher) sharedFileName() string {
+	return filepath.Join(sc.genPath, sc.genFileName(sharedFile))
+}
+
+// writeSharedSet method    生成共享 Set 文件 autowire_shared_providers.go，并将共享 Set 加入汇总 Sets.
+func (sc *AutoWireSearcher) writeSharedSet(shared map[string]Element) error {
+	elements := maps.Clone(shared)
+	order := parser.SortedKeys(elements)
+	sc.resolvePackageConflicts(elements, make(map[string]map[string]string), order)
+	data, importPkg := sc.generateWireConfig(sharedSetName, elements, order)
+	if err := sc.writeConfigFile(sc.sharedFileName(), data, importPkg); err != nil {
+		return err
+	}
+	sc.mu.Lock()
+	sc.sets = append(sc.sets, sharedSetName)
+	sc.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestWriteSet_Shared(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{
+		mu:        &sync.Mutex{},
+		genPath:   dir,
+		pkg:       "wire",
+		logger:    logger.Discard(),
+		cache:     NewCacheManager(dir, false),
+		splitSets: parser.NewSet[string](),
+		setFiles:  &setFiles{},
+		providers: &providerSources{},
+	}
+	logger := Element{Name: "Logger", Pkg: "log", PkgPath: "example.com/log", Constructor: "NewLogger"}
+	db, app := logger, logger
+	db.Set, app.Set = "db", "app"
+	sc.ElementMap = map[string]map[string]Element{
+		"db": {
+			"example.com/log/Logger": db,
+			"example.com/db/DB": {Name: "DB", Set: "db", Pkg: "db", PkgPath: "example.com/db",
+				Constructor: "Open"},
+		},
+		"app": {"example.com/log/Logger": app},
+		"worker": {"example.com/worker/Pool": {Name: "Pool", Set: "worker", Pkg: "worker",
+			PkgPath: "example.com/worker", Constructor: "NewPool"}},
+	}
+	if err := sc.writeSharedSet(sc.sharedElements()); err != nil {
+		t.Fatalf("writeSharedSet() error = %v", err)
+	}
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		if err := sc.writeSet(set, sc.ElementMap[set]); err != nil {
+			t.Fatalf("writeSet(%s) error = %v", set, err)
+		}
+	}
+
+	// 共享组件只生成一次，包含共享组件的 Set 组合私有 Set 与共享 Set，汇总 Sets 引用私有 Set
+	tests := []struct {
+		file string
+		want []string
+		not  []string
+	}{
+		{"autowire_shared_providers.go", []string{"var sharedProviders = wire.NewSet(", "log.NewLogger"},
+			[]string{"Open"}},
+		{"autowire_db.go", []string{"var dbSet = wire.NewSet(", "db.Open",
+			"var DbSet = wire.NewSet(dbSet, sharedProviders)"}, []string{"NewLogger"}},
+		{"autowire_app.go", []string{"var appSet = wire.NewSet()", "var AppSet = wire.NewSet(appSet, sharedProviders)"},
+			[]string{"NewLogger"}},
+		{"autowire_worker.go", []string{"var WorkerSet = wire.NewSet(", "worker.NewPool"}, []string{"sharedProviders"}},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(data)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s missing %q:\n%s", tt.file, want, out)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(out, not) {
+				t.Errorf("%s should not contain %q:\n%s", tt.file, not, out)
+			}
+		}
+	}
+
+	slices.Sort(sc.sets)
+	if want := []string{"WorkerSet", "appSet", "dbSet", "sharedProviders"}; !slices.Equal(sc.sets, want) {
+		t.Errorf("sets = %v, want %v", sc.sets, want)
+	}
+	if files := sc.expectedFiles(); !slices.Contains(files, "autowire_shared_providers.go") {
+		t.Errorf("expectedFiles() = %v, missing autowire_shared_providers.go", files)
+	}
+}
+
+func TestSharedElements(t *testing.T) {
+	logger := Element{Name: "Logger", Pkg: "log", PkgPath: "example.com/log"}
+	db, app, split := logger, logger, logger
+	db.Set, app.Set, split.Set = "db", "app", "split"
+	sc := &AutoWireSearcher{
+		mu:        &sync.Mutex{},
+		splitSets: parser.NewSet("split"),
+		ElementMap: map[string]map[string]Element{
+			"db":    {"example.com/log/Logger": db},
+			"app":   {"example.com/log/Logger": app},
+			"split": {"example.com/log/Logger": split},
+		},
+	}
+	shared := sc.sharedElements()
+	if len(shared) != 1 || shared["example.com/log/Logger"].Set != "app" {
+		t.Errorf("sharedElements() = %v", shared)
+	}
+
+	// 拆分出的 Set 不加入汇总，只剩一个 Set 时不需要共享
+	delete(sc.ElementMap, "app")
+	if shared := sc.sharedElements(); len(shared) != 0 {
+		t.Errorf("sharedElements() = %v, want empty", shared)
+	}
+}
+
+func TestShared_Wire(t *testing.T) {
+	dir := runWireModule(t, map[string]string{
+		"a/a.go": `package a
+
+// @autowire(set=db|app|worker)
+type Logger struct{}
+
+// @autowire(set=db)
+type DB struct{ L *Logger }
+
+// @autowire(set=app)
+type App struct {
+	L *Logger
+	D *DB
+}
+
+// @autowire(set=worker)
+type Worker struct{ L *Logger }
+
+// @autowire.set(name=core,include=db|app)
+type Core struct{}
+
+// @autowire.set(name=all,include=core|worker)
+type All struct{}
+`,
+		// 组合 Set 与私有 Set 在同一个 wire.Build 中组合时，共享 Set 只包含一次
+		"wire/inject.go": `//go:build wireinject
+
+package wire
+
+import (
+	"example.com/app/a"
+	"github.com/google/wire"
+)
+
+func InitCore() *a.App {
+	panic(wire.Build(CoreSet))
+}
+
+func InitAll() *a.Worker {
+	panic(wire.Build(AllSet))
+}
+
+func InitApp() *a.App {
+	panic(wire.Build(appSet, dbSet, sharedProviders))
+}
+`,
+	})
+	gen, err := os.ReadFile(filepath.Join(dir, "wire", "wire_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, injector := range []string{"func InitCore()", "func InitAll()", "func InitApp()"} {
+		if !strings.Contains(string(gen), injector) {
+			t.Errorf("wire_gen.go 缺少 %s:\n%s", injector, gen)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/version"
+)
+
+// StampFileName 生成清单文件名，不带构建约束以便 go generate 识别其中的指令
+// 不以 autowire_ 开头，清理过期文件时不会被删除.
+var StampFileName = config.FilePrefix + ".go"
+
+// manifestPrefix 生成清单所在行的前缀.
+const manifestPrefix = "// gutowire:manifest "
+
+// Manifest struct    生成清单，记录重新生成所需的工作目录、参数以及生成时的版本与输入哈希.
+type Manifest struct {
+	Version string   `json:"version"` // 生成时 gutowire 的版本
+	Dir     string   `json:"dir"`     // 执行 gutowire 的工作目录，相对生成目录
+	Args    []string `json:"args"`    // gutowire stamp 的参数
+	Input   string   `json:"input"`   // 带注解的源文件的哈希，如 sha256:...
+}
+
+// ReadManifest function    读取生成目录中 autowire.go 记录的生成清单.
+func ReadManifest(genPath string) (*Manifest, error) {
+	fileName := filepath.Join(genPath, StampFileName)
+	//nolint:gosec
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("读取生成清单失败: %w", err)
+	}
+	//nolint:errcheck
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), manifestPrefix)
+		if !ok {
+			continue
+		}
+		var m Manifest
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			return nil, fmt.Errorf("解析生成清单 %s 失败: %w", fileName, err)
+		}
+		return &m, nil
+	}
+	return nil, fmt.Errorf("%s 中没有生成清单，请先运行 gutowire stamp", fileName)
+}
+
+// WriteStamp method    在生成目录写入 autowire.go，包含 //go:generate 指令与生成清单.
+func (sc *AutoWireSearcher) WriteStamp(stamp *config.Stamp) error {
+	m := Manifest{Version: version.Version, Dir: filepath.ToSlash(stamp.Dir), Args: stamp.Args, Input: sc.InputHash()}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("序列化生成清单失败: %w", err)
+	}
+
+	directive := append([]string{"gutowire", "stamp", "-C", m.Dir}, m.Args...)
+	src := fmt.Sprintf("// Code generated by go-autowire. DO NOT EDIT.\n\n%s%s\n\npackage %s\n\n//go:generate %s\n",
+		manifestPrefix, data, sc.pkg, strings.Join(parser.Map(directive, quoteGenerateArg), " "))
+	fileName := filepath.Join(sc.genPath, StampFileName)
+	return sc.writeIfChanged(fileName, []byte(src), func() error {
+		return sc.writeGenerated(fileName, []byte(src))
+	})
+}
+
+// quoteGenerateArg function    go generate 按空格分隔参数，包含空白或引号的参数使用双引号.
+func quoteGenerateArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// InputHash method    返回带注解的源文件的哈希：按相对模块根目录的路径排序，包含路径与文件内容
+// 与机器上的绝对路径无关，相同的源码在不同机器上得到相同的哈希.
+func (sc *AutoWireSearcher) InputHash() string {
+	h := sha256.New()
+	for _, file := range parser.SortedKeys(sc.fileElements) {
+		if len(sc.fileElements[file]) == 0 {
+			continue
+		}
+		//nolint:gosec
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%s\n%d\n", sc.relPath(file), len(data))
+		_, _ = h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestWriteStamp(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.go")
+	if err := os.WriteFile(src, []byte("package app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:           &sync.Mutex{},
+		genPath:      dir,
+		pkg:          "wire",
+		logger:       logger.Discard(),
+		cache:        NewCacheManager(dir, false),
+		fileElements: map[string][]Element{src: {{Name: "App", Pkg: "app"}}},
+	}
+
+	args := []string{"--scope=./internal", "--build-tags=a b"}
+	if err := sc.WriteStamp(&config.Stamp{Dir: "..", Args: args}); err != nil {
+		t.Fatalf("WriteStamp() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, StampFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `//go:generate gutowire stamp -C .. --scope=./internal "--build-tags=a b"`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("autowire.go 缺少 %q:\n%s", want, data)
+	}
+
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if m.Dir != ".." || !slices.Equal(m.Args, args) || m.Input != sc.InputHash() {
+		t.Errorf("ReadManifest() = %+v", m)
+	}
+
+	// 带注解的源文件变化后输入哈希随之变化
+	if err := os.WriteFile(src, []byte("package app\n\n// App 应用.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if sc.InputHash() == m.Input {
+		t.Error("InputHash() 在源文件变化后没有变化")
+	}
+}
+
+func TestReadManifest_Missing(t *testing.T) {
+	if _, err := ReadManifest(t.TempDir()); err == nil {
+		t.Error("ReadManifest() 在没有 autowire.go 时应返回错误")
+	}
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ElementFunc 流式扫描时接收组件的回调，返回错误时停止扫描.
+type ElementFunc func(Element) error
+
+// Stream method    递归扫描指定目录下的所有 Go 文件，每解析完一个文件即将其中的组件按声明顺序交给 fn
+// 组件不保留在 ElementMap 中，内存占用与单个文件的组件数量相关，不与项目规模相关；
+// 注解接口与组合 Set 不是组件，不会交给 fn，也不会为注解接口查找实现（需要全部组件）。
+// fn 不会被并发调用，文件之间的顺序不固定；fn 返回错误或 ctx 被取消时停止扫描并返回该错误.
+func (sc *AutoWireSearcher) Stream(ctx context.Context, fn ElementFunc, roots ...string) error {
+	if err := sc.cache.Load(); err != nil {
+		sc.logger.Warn("加载缓存失败", "error", err)
+	}
+
+	// 每个文件使用独立的文件集合，解析完即可释放
+	sc.ctx = ctx
+	sc.streaming = true
+	sc.stats = &scanStats{}
+	sc.fset = nil
+
+	files, err := sc.collectFiles(roots)
+	if err != nil {
+		return err
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	if sc.parallel > 0 {
+		g.SetLimit(sc.parallel)
+	}
+	var (
+		mu      sync.Mutex
+		stopped bool         // fn 已返回错误，等待中的文件不再交给 fn
+		done    atomic.Int64 // 已处理的文件数
+	)
+	sc.reportProgress(0, len(files))
+	for _, file := range files {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			scan, ok, err := sc.parseWire(file)
+			sc.reportProgress(int(done.Add(1)), len(files))
+			if err != nil || !ok {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return nil
+			}
+			for _, elem := range scan.elements {
+				if elem.Interface || elem.Composite {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := fn(elem); err != nil {
+					stopped = true
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
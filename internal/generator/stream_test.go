@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestStream(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"zoo.go": "package m\n\n// @autowire(set=svc)\ntype Zoo struct{}\n\n" +
+			"// @autowire(set=svc)\ntype Animal interface{ Name() string }\n",
+		"cat.go": "package m\n\n// @autowire(set=svc|pets)\ntype Cat struct{}\n",
+		"new.go": "package m\n\nfunc NewCat() *Cat { return &Cat{} }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newSearcher := func() *AutoWireSearcher {
+		o := config.NewGenOpt(filepath.Join(dir, "wire"), config.WithPkg("wire"), config.WithCache(false),
+			config.WithLogger(logger.Discard()), config.WithParallel(2))
+		return NewAutoWireSearcher(o, "example.com/m")
+	}
+
+	// 注解接口不交给回调，组件不保留在 ElementMap 中
+	sc := newSearcher()
+	var got []string
+	err := sc.Stream(context.Background(), func(elem Element) error {
+		got = append(got, elem.Set+"/"+elem.Name+"/"+elem.Constructor)
+		return nil
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if want := []string{"pets/Cat/NewCat", "svc/Cat/NewCat", "svc/Zoo/"}; !slices.Equal(got, want) {
+		t.Errorf("Stream() = %v, want %v", got, want)
+	}
+	if len(sc.ElementMap) != 0 || len(sc.interfaces) != 0 || len(sc.fileElements) != 0 {
+		t.Errorf("流式扫描不应保留组件: %v", sc.ElementMap)
+	}
+
+	// 回调返回错误时停止扫描
+	stop := errors.New("stop")
+	calls := 0
+	err = newSearcher().Stream(context.Background(), func(Element) error {
+		calls++
+		return stop
+	}, dir)
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Stream() error = %v, calls = %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = newSearcher().Stream(ctx, func(Element) error {
+		t.Error("ctx 取消后不应再调用回调")
+		return nil
+	}, dir)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Stream() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,240 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/version"
+)
+
+// SumFileName 生成校验文件名，记录生成的输入、输出与工具版本，用于 gutowire verify
+// 不以 autowire_ 开头，清理过期文件时不会被删除；与生成目录锁 .gutowire.lock 无关.
+const SumFileName = "gutowire.sum"
+
+// Sum struct    生成校验：一次成功生成的输入文件、注解、生成的文件以及 gutowire 与 wire 的版本
+// 路径均相对模块根目录，内容不包含时间与机器相关的信息，相同的输入在不同机器上得到相同的校验文件.
+type Sum struct {
+	Version     string     `json:"version"`                // 生成时 gutowire 的版本
+	WireVersion string     `json:"wire_version,omitempty"` // 运行的 wire 版本，fx 后端为空
+	Inputs      []SumInput `json:"inputs"`                 // 带注解的源文件，按路径排序
+	Packages    []SumFile  `json:"packages"`               // 组件所在包中的其他 Go 文件（不含测试文件），按路径排序
+	Outputs     []SumFile  `json:"outputs"`                // 生成的文件，按路径排序
+}
+
+// SumInput struct    带注解的源文件.
+type SumInput struct {
+	SumFile
+	Annotations string `json:"annotations"` // 文件中注解解析结果的摘要，如 sha256:...
+}
+
+// SumFile struct    文件及其内容的哈希.
+type SumFile struct {
+	File   string `json:"file"`   // 相对模块根目录的路径
+	SHA256 string `json:"sha256"` // 文件内容的哈希，如 sha256:...
+}
+
+// Sum method    根据本次扫描与生成的结果计算生成校验，wireVersion 为运行的 wire 版本
+// 生成的文件包括本次写入（或内容未变化而跳过写入）的文件、生成清单 autowire.go 以及各输出目录中 wire 生成的 wire_gen.go；
+// wireGens 不为 nil 时 wire_gen.go 使用其中重新生成的内容（路径 -> 内容，没有记录表示不会生成），否则读取磁盘.
+func (sc *AutoWireSearcher) Sum(wireVersion string, wireGens map[string][]byte) *Sum {
+	s := &Sum{Version: version.Version, WireVersion: wireVersion,
+		Inputs: []SumInput{}, Packages: []SumFile{}, Outputs: []SumFile{}}
+	for _, file := range parser.SortedKeys(sc.fileElements) {
+		elements := sc.fileElements[file]
+		if len(elements) == 0 {
+			continue
+		}
+		s.Inputs = append(s.Inputs, SumInput{SumFile: sc.sumFile(file), Annotations: annotationDigest(elements)})
+	}
+
+	var outputs []string
+	if sc.produced != nil {
+		outputs = sc.produced.sorted()
+	}
+	var wireGenFiles []string
+	for _, dir := range append([]string{sc.genPath}, sc.outputDirs...) {
+		wireGenFiles = append(wireGenFiles, absPath(filepath.Join(dir, "wire_gen.go")))
+	}
+	for _, file := range append([]string{absPath(filepath.Join(sc.genPath, StampFileName))}, wireGenFiles...) {
+		if slices.Contains(outputs, file) {
+			continue
+		}
+		if slices.Contains(wireGenFiles, file) && wireGens != nil {
+			if data, ok := wireGens[file]; ok {
+				s.Outputs = append(s.Outputs, SumFile{File: sc.relPath(file), SHA256: sha256Digest(data)})
+			}
+			continue
+		}
+		if _, err := os.Stat(file); err == nil {
+			outputs = append(outputs, file)
+		}
+	}
+	for _, file := range outputs {
+		s.Outputs = append(s.Outputs, sc.sumFile(file))
+	}
+
+	// 未带注解的构造函数、字段类型等同样影响 wire 的生成结果
+	for _, file := range sc.packageFiles() {
+		if !slices.Contains(outputs, file) {
+			s.Packages = append(s.Packages, sc.sumFile(file))
+		}
+	}
+	slices.SortFunc(s.Inputs, func(a, b SumInput) int { return compareSumFiles(a.SumFile, b.SumFile) })
+	slices.SortFunc(s.Packages, compareSumFiles)
+	slices.SortFunc(s.Outputs, compareSumFiles)
+	return s
+}
+
+// packageFiles method    返回带注解的源文件所在目录中其余的 Go 文件（不含测试文件与带注解的源文件），按路径排序.
+func (sc *AutoWireSearcher) packageFiles() []string {
+	dirs, annotated := parser.NewSet[string](), parser.NewSet[string]()
+	for file, elements := range sc.fileElements {
+		if len(elements) > 0 {
+			annotated.Add(absPath(file))
+			dirs.Add(filepath.Dir(absPath(file)))
+		}
+	}
+	var files []string
+	for _, dir := range parser.SortedKeys(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			file := filepath.Join(dir, name)
+			if !entry.Type().IsRegular() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+				annotated.Contains(file) {
+				continue
+			}
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// sumFile method    返回文件相对模块根目录的路径与内容哈希，文件不存在时哈希为空.
+func (sc *AutoWireSearcher) sumFile(file string) SumFile {
+	sf := SumFile{File: sc.relPath(file)}
+	//nolint:gosec
+	if data, err := os.ReadFile(file); err == nil {
+		sf.SHA256 = sha256Digest(data)
+	}
+	return sf
+}
+
+// compareSumFiles function    按路径排序.
+func compareSumFiles(a, b SumFile) int {
+	return strings.Compare(a.File, b.File)
+}
+
+// annotationDigest function    返回文件中注解解析结果的摘要，与源码位置无关（只移动声明不改变摘要）.
+func annotationDigest(elements []Element) string {
+	elements = slices.Clone(elements)
+	for i := range elements {
+		elements[i].Position = token.Position{}
+	}
+	slices.SortFunc(elements, compareElements)
+	data, _ := json.Marshal(elements)
+	return sha256Digest(data)
+}
+
+// sha256Digest function    返回内容的 sha256 哈希，如 sha256:....
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// WriteSum method    在生成目录写入生成校验文件 gutowire.sum，内容未变化时不写入.
+func (sc *AutoWireSearcher) WriteSum(s *Sum) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化生成校验失败: %w", err)
+	}
+	data = append(data, '\n')
+	fileName := filepath.Join(sc.genPath, SumFileName)
+	//nolint:gosec
+	if existing, err := os.ReadFile(fileName); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	//nolint:gosec
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		return fmt.Errorf("写入生成校验文件失败: %w", err)
+	}
+	return nil
+}
+
+// ReadSum function    读取生成目录中的生成校验文件.
+func ReadSum(genPath string) (*Sum, error) {
+	fileName := filepath.Join(genPath, SumFileName)
+	//nolint:gosec
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("读取生成校验文件失败: %w", err)
+	}
+	var s Sum
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("解析生成校验文件 %s 失败: %w", fileName, err)
+	}
+	return &s, nil
+}
+
+// Diff method    返回与另一个生成校验的差异描述，没有差异时为空；s 为记录的校验，current 为当前的结果.
+func (s *Sum) Diff(current *Sum) []string {
+	var diffs []string
+	if s.Version != current.Version {
+		diffs = append(diffs, fmt.Sprintf("gutowire 版本不同: 校验文件 %s，当前 %s", s.Version, current.Version))
+	}
+	if s.WireVersion != current.WireVersion {
+		diffs = append(diffs, fmt.Sprintf("wire 版本不同: 校验文件 %s，当前 %s", s.WireVersion, current.WireVersion))
+	}
+
+	inputs := parser.Map(s.Inputs, func(in SumInput) SumFile { return in.SumFile })
+	currentInputs := parser.Map(current.Inputs, func(in SumInput) SumFile { return in.SumFile })
+	diffs = append(diffs, diffFiles("输入文件", inputs, currentInputs)...)
+
+	annotations := make(map[string]string, len(s.Inputs))
+	for _, in := range s.Inputs {
+		annotations[in.File] = in.Annotations
+	}
+	for _, in := range current.Inputs {
+		if digest, ok := annotations[in.File]; ok && digest != in.Annotations {
+			diffs = append(diffs, "注解已变化: "+in.File)
+		}
+	}
+
+	diffs = append(diffs, diffFiles("包文件", s.Packages, current.Packages)...)
+	return append(diffs, diffFiles("生成的文件", s.Outputs, current.Outputs)...)
+}
+
+// diffFiles function    比较两组文件，返回新增、删除与内容变化的文件描述.
+func diffFiles(kind string, recorded, current []SumFile) []string {
+	hashes := make(map[string]string, len(recorded))
+	for _, f := range recorded {
+		hashes[f.File] = f.SHA256
+	}
+	var diffs []string
+	for _, f := range current {
+		hash, ok := hashes[f.File]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s不在校验文件中: %s", kind, f.File))
+		case hash != f.SHA256:
+			diffs = append(diffs, fmt.Sprintf("%s内容已变化: %s", kind, f.File))
+		}
+		delete(hashes, f.File)
+	}
+	for _, file := range parser.SortedKeys(hashes) {
+		diffs = append(diffs, fmt.Sprintf("%s已删除: %s", kind, file))
+	}
+	return diffs
+}
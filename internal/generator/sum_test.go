@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestSum(t *testing.T) {
+	dir := t.TempDir()
+	src, ctor := filepath.Join(dir, "app.go"), filepath.Join(dir, "new.go")
+	for _, file := range []string{src, ctor, filepath.Join(dir, "app_test.go")} {
+		if err := os.WriteFile(file, []byte("package app\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	genPath := filepath.Join(dir, "wire")
+	if err := os.MkdirAll(genPath, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	wireGen := filepath.Join(genPath, "wire_gen.go")
+	if err := os.WriteFile(wireGen, []byte("package wire\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sc := &AutoWireSearcher{
+		mu:           &sync.Mutex{},
+		genPath:      genPath,
+		pkg:          "wire",
+		logger:       logger.Discard(),
+		cache:        NewCacheManager(genPath, false),
+		fileElements: map[string][]Element{src: {{Name: "App", Pkg: "app"}}},
+	}
+
+	recorded := sc.Sum("v0.6.0", nil)
+	if len(recorded.Inputs) != 1 || recorded.Inputs[0].File != sc.relPath(src) || recorded.Inputs[0].SHA256 == "" {
+		t.Errorf("Sum().Inputs = %+v", recorded.Inputs)
+	}
+	if len(recorded.Outputs) != 1 || recorded.Outputs[0].File != sc.relPath(wireGen) {
+		t.Errorf("Sum().Outputs = %+v", recorded.Outputs)
+	}
+	if len(recorded.Packages) != 1 || recorded.Packages[0].File != sc.relPath(ctor) {
+		t.Errorf("Sum().Packages = %+v", recorded.Packages)
+	}
+	if err := sc.WriteSum(recorded); err != nil {
+		t.Fatalf("WriteSum() error = %v", err)
+	}
+	read, err := ReadSum(genPath)
+	if err != nil {
+		t.Fatalf("ReadSum() error = %v", err)
+	}
+	if diffs := read.Diff(sc.Sum("v0.6.0", nil)); len(diffs) != 0 {
+		t.Errorf("Diff() 在没有变化时 = %v", diffs)
+	}
+
+	// 重新生成的 wire_gen.go 与记录的内容不同
+	regen := map[string][]byte{wireGen: []byte("package wire\n\n// 重新生成\n")}
+	if diffs := read.Diff(sc.Sum("v0.6.0", regen)); !slices.Equal(diffs,
+		[]string{"生成的文件内容已变化: " + sc.relPath(wireGen)}) {
+		t.Errorf("Diff() 重新生成的 wire_gen.go 变化时 = %v", diffs)
+	}
+
+	// 注解、未带注解的包文件、生成的文件与 wire 版本变化后均报告差异
+	sc.fileElements[src] = []Element{{Name: "App", Pkg: "app", Constructor: "NewApp"}}
+	if err := os.WriteFile(wireGen, []byte("package wire\n\n// 手动修改\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ctor, []byte("package app\n\nfunc NewApp() *App { return nil }\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	diffs := read.Diff(sc.Sum("v0.7.0", nil))
+	for _, want := range []string{"wire 版本不同", "注解已变化: " + sc.relPath(src), "包文件内容已变化: " + sc.relPath(ctor),
+		"生成的文件内容已变化: " + sc.relPath(wireGen)} {
+		if !slices.ContainsFunc(diffs, func(d string) bool { return strings.HasPrefix(d, want) }) {
+			t.Errorf("Diff() = %v，缺少 %q", diffs, want)
+		}
+	}
+}
+
+func TestSum_DiffFiles(t *testing.T) {
+	recorded := []SumFile{{File: "a.go", SHA256: "1"}, {File: "b.go", SHA256: "2"}}
+	current := []SumFile{{File: "a.go", SHA256: "1"}, {File: "c.go", SHA256: "3"}}
+	want := []string{"输入文件不在校验文件中: c.go", "输入文件已删除: b.go"}
+	if got := diffFiles("输入文件", recorded, current); !slices.Equal(got, want) {
+		t.Errorf("diffFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestReadSum_Missing(t *testing.T) {
+	if _, err := ReadSum(t.TempDir()); err == nil {
+		t.Error("ReadSum() 在没有 gutowire.sum 时应返回错误")
+	}
+}
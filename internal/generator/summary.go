@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"sync/atomic"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// scanStats struct    扫描时源文件的缓存命中统计.
+type scanStats struct {
+	hits   atomic.Int64 // 复用缓存解析结果的文件数
+	misses atomic.Int64 // 重新检查或解析的文件数
+}
+
+// hit method    记录一个复用缓存的文件.
+func (s *scanStats) hit() {
+	if s != nil {
+		s.hits.Add(1)
+	}
+}
+
+// miss method    记录一个重新检查的文件.
+func (s *scanStats) miss() {
+	if s != nil {
+		s.misses.Add(1)
+	}
+}
+
+// SetSummary struct    一个 Set 的生成结果.
+type SetSummary struct {
+	Name     string // Set 名称
+	Elements int    // Set 中的组件数量
+	File     string // 生成的文件（绝对路径），fx 后端为空
+}
+
+// Summary struct    一次生成的汇总信息.
+type Summary struct {
+	Sets        []SetSummary // 按名称排序
+	Files       int          // 本次生成的文件数（包括内容未变化而跳过写入的文件）
+	Written     []string     // 内容变化而写入的文件（绝对路径，已排序）
+	CacheHits   int          // 复用缓存解析结果的源文件数
+	CacheMisses int          // 重新检查或解析的源文件数
+}
+
+// CacheHitRate method    返回源文件缓存命中率（0 到 1），没有扫描文件时为 0.
+func (s Summary) CacheHitRate() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total)
+}
+
+// Summary method    返回最近一次扫描与生成的汇总信息，需要在 Write 或 WriteFx 之后调用.
+func (sc *AutoWireSearcher) Summary() Summary {
+	files := sc.SetFiles()
+	var s Summary
+	if sc.stats != nil {
+		s.CacheHits, s.CacheMisses = int(sc.stats.hits.Load()), int(sc.stats.misses.Load())
+	}
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		s.Sets = append(s.Sets, SetSummary{Name: set, Elements: len(sc.ElementMap[set]), File: files[set]})
+	}
+	if sc.produced != nil {
+		s.Files = len(sc.produced.sorted())
+	}
+	if sc.written != nil {
+		s.Written = sc.written.sorted()
+	}
+	return s
+}
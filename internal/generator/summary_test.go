@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	sc := &AutoWireSearcher{
+		mu: &sync.Mutex{},
+		ElementMap: map[string]map[string]Element{
+			"repo": {"a": {Name: "A"}, "b": {Name: "B"}},
+			"svc":  {"c": {Name: "C"}},
+		},
+		produced: &fileList{},
+		written:  &fileList{},
+		setFiles: &setFiles{},
+		stats:    &scanStats{},
+	}
+	sc.setFiles.add("repo", "/gen/autowire_repo.go")
+	sc.produced.add("/gen/autowire_repo.go")
+	sc.produced.add("/gen/autowire_svc.go")
+	sc.written.add("/gen/autowire_svc.go")
+	for range 3 {
+		sc.stats.hit()
+	}
+	sc.stats.miss()
+
+	s := sc.Summary()
+	want := []SetSummary{{Name: "repo", Elements: 2, File: "/gen/autowire_repo.go"}, {Name: "svc", Elements: 1}}
+	if !slices.Equal(s.Sets, want) {
+		t.Errorf("Sets = %v, want %v", s.Sets, want)
+	}
+	if s.Files != 2 || !slices.Equal(s.Written, []string{"/gen/autowire_svc.go"}) {
+		t.Errorf("Files = %d, Written = %v", s.Files, s.Written)
+	}
+	if s.CacheHitRate() != 0.75 {
+		t.Errorf("CacheHitRate() = %v, want 0.75", s.CacheHitRate())
+	}
+	if (Summary{}).CacheHitRate() != 0 {
+		t.Error("没有扫描文件时命中率应为 0")
+	}
+}
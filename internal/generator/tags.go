@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/annotations"
+	"github.com/stoewer/go-strcase"
+)
+
+// defaultTagFile 未指定任何构建标签时生效的文件后缀，如 autowire_storage_default.go.
+const defaultTagFile = "default"
+
+// normalizeSetTags method    规范化配置中的 Set 名称，忽略无效的构建标签.
+func (sc *AutoWireSearcher) normalizeSetTags(tags map[string]string) map[string]string {
+	normalized := make(map[string]string, len(tags))
+	for set, tag := range tags {
+		if !annotations.ValidBuildTag(tag) {
+			sc.logger.Warn("无效的构建标签，已忽略", "set", set, "tag", tag)
+			continue
+		}
+		normalized[strcase.LowerCamelCase(set)] = tag
+	}
+	return normalized
+}
+
+// setConstraints method    返回 Set 文件的构建约束（配置文件中的 set_build_tags），未配置时为空
+// Set 生成的全部文件（包括各构建标签的文件）都带有该约束.
+func (sc *AutoWireSearcher) setConstraints(set string) []string {
+	if tag := sc.setBuildTags[set]; tag != "" {
+		return []string{tag}
+	}
+	return nil
+}
+
+// applySetTags method    为配置了构建标签的 Set 中没有 tag= 参数的组件设置构建标签.
+func (sc *AutoWireSearcher) applySetTags() {
+	for set, tag := range sc.setTags {
+		for key, elem := range sc.ElementMap[set] {
+			if elem.Tag == "" {
+				elem.Tag = tag
+				sc.ElementMap[set][key] = elem
+			}
+		}
+	}
+}
+
+// splitByTag function    按构建标签拆分 Set 中的组件，返回未带标签的组件与 标签 -> 组件 的映射.
+func splitByTag(elements map[string]Element) (map[string]Element, map[string]map[string]Element) {
+	untagged := make(map[string]Element)
+	tagged := make(map[string]map[string]Element)
+	for key, elem := range elements {
+		if elem.Tag == "" {
+			untagged[key] = elem
+			continue
+		}
+		if tagged[elem.Tag] == nil {
+			tagged[elem.Tag] = make(map[string]Element)
+		}
+		tagged[elem.Tag][key] = elem
+	}
+	return untagged, tagged
+}
+
+// elementTags function    返回 Set 中用到的构建标签（已排序）.
+func elementTags(elements map[string]Element) []string {
+	tags := parser.NewSet[string]()
+	for _, elem := range elements {
+		if elem.Tag != "" {
+			tags.Add(elem.Tag)
+		}
+	}
+	return slices.Sorted(maps.Keys(tags))
+}
+
+// taggedSetName function    返回带构建标签组件的 Set 变量名，如 StorageSet 返回 StorageTaggedSet.
+func taggedSetName(setName string) string {
+	return strings.TrimSuffix(setName, "Set") + "TaggedSet"
+}
+
+// writeTaggedSets method    为每个构建标签生成带 //go:build 约束的 Set 文件
+// 每个文件都定义同名的 <Set>TaggedSet，另生成一个在所有标签均未指定时生效的空 Set，
+// 主 Set 引用 <Set>TaggedSet，因此构建时通过标签选择实现即可，无需修改 Set.
+func (sc *AutoWireSearcher) writeTaggedSets(set, setName string, tagged map[string]map[string]Element,
+	order []string) error {
+	tags := slices.Sorted(maps.Keys(tagged))
+	name := taggedSetName(setName)
+
+	for _, tag := range tags {
+		elements := tagged[tag]
+		keys := slices.DeleteFunc(slices.Clone(order), func(k string) bool {
+			_, ok := elements[k]
+			return !ok
+		})
+		data, importPkg := sc.generateWireConfig(name, elements, keys)
+		data.Tags = append(sc.setConstraints(set), tag)
+		if err := sc.writeConfigFile(sc.setFileName(set+"_"+tag), data, importPkg); err != nil {
+			return err
+		}
+	}
+
+	// 没有指定任何标签时使用空 Set，保证不带标签时同样可以编译
+	data := WireSet{
+		Package: sc.pkg,
+		SetName: name,
+		Tags:    append(sc.setConstraints(set), parser.Map(tags, func(tag string) string { return "!" + tag })...),
+	}
+	return sc.writeConfigFile(sc.setFileName(set+"_"+defaultTagFile), data, nil)
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestWriteSet_Tags(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{
+		mu:      &sync.Mutex{},
+		genPath: dir,
+		pkg:     "wire",
+		logger:  logger.Discard(),
+		cache:   NewCacheManager(dir, false),
+	}
+	elements := map[string]Element{
+		"example.com/store/PG":     {Name: "PG", Pkg: "store", PkgPath: "example.com/store", Constructor: "NewPG", Tag: "prod"},
+		"example.com/store/Mem":    {Name: "Mem", Pkg: "store", PkgPath: "example.com/store", Constructor: "NewMem", Tag: "dev"},
+		"example.com/store/Common": {Name: "Common", Pkg: "store", PkgPath: "example.com/store"},
+	}
+	sc.ElementMap = map[string]map[string]Element{"storage": elements}
+	if err := sc.writeSet("storage", elements); err != nil {
+		t.Fatalf("writeSet() error = %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+		not  []string
+	}{
+		{"autowire_storage.go", []string{"//go:build wireinject\n", "StorageTaggedSet", "store.Common"}, []string{"NewPG", "NewMem"}},
+		{"autowire_storage_prod.go", []string{"//go:build wireinject && prod\n", "// +build wireinject,prod\n",
+			"var StorageTaggedSet = wire.NewSet(", "store.NewPG"}, []string{"NewMem"}},
+		{"autowire_storage_dev.go", []string{"//go:build wireinject && dev\n", "store.NewMem"}, []string{"NewPG"}},
+		{"autowire_storage_default.go", []string{"//go:build wireinject && !dev && !prod\n",
+			"// +build wireinject,!dev,!prod\n", "var StorageTaggedSet = wire.NewSet()"}, nil},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(data)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s missing %q:\n%s", tt.file, want, out)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(out, not) {
+				t.Errorf("%s should not contain %q:\n%s", tt.file, not, out)
+			}
+		}
+	}
+
+	files := sc.expectedFiles()
+	for _, f := range []string{"autowire_storage_prod.go", "autowire_storage_dev.go", "autowire_storage_default.go"} {
+		if !slices.Contains(files, f) {
+			t.Errorf("expectedFiles() = %v, missing %s", files, f)
+		}
+	}
+}
+
+func TestApplySetTags(t *testing.T) {
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, logger: logger.Discard(), ElementMap: map[string]map[string]Element{
+		"mockRepo": {"a/A": {Name: "A"}, "a/B": {Name: "B", Tag: "test"}},
+		"repo":     {"a/C": {Name: "C"}},
+	}}
+	sc.setTags = sc.normalizeSetTags(map[string]string{"mock_repo": "dev", "repo": "bad tag"})
+	sc.applySetTags()
+
+	for set, want := range map[string]map[string]string{"mockRepo": {"a/A": "dev", "a/B": "test"}, "repo": {"a/C": ""}} {
+		for key, tag := range want {
+			if got := sc.ElementMap[set][key].Tag; got != tag {
+				t.Errorf("%s %s: Tag = %q, want %q", set, key, got, tag)
+			}
+		}
+	}
+}
+
+func TestWriteSet_BuildTags(t *testing.T) {
+	dir := t.TempDir()
+	sc := &AutoWireSearcher{
+		mu:        &sync.Mutex{},
+		genPath:   dir,
+		pkg:       "wire",
+		logger:    logger.Discard(),
+		cache:     NewCacheManager(dir, false),
+		splitSets: parser.NewSet[string](),
+		setFiles:  &setFiles{},
+	}
+	sc.setBuildTags = sc.normalizeSetTags(map[string]string{"integration": "integration"})
+	sc.ElementMap = map[string]map[string]Element{
+		"integration": {
+			"example.com/it/Fake": {Name: "Fake", Pkg: "it", PkgPath: "example.com/it", Constructor: "NewFake"},
+			"example.com/it/Slow": {Name: "Slow", Pkg: "it", PkgPath: "example.com/it", Constructor: "NewSlow",
+				Tag: "race"},
+		},
+		"repo": {"example.com/store/PG": {Name: "PG", Pkg: "store", PkgPath: "example.com/store", Constructor: "NewPG"}},
+	}
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		if err := sc.writeSet(set, sc.ElementMap[set]); err != nil {
+			t.Fatalf("writeSet(%s) error = %v", set, err)
+		}
+	}
+
+	tests := map[string]string{
+		"autowire_integration.go":         "//go:build wireinject && integration\n",
+		"autowire_integration_race.go":    "//go:build wireinject && integration && race\n",
+		"autowire_integration_default.go": "//go:build wireinject && integration && !race\n",
+		"autowire_repo.go":                "//go:build wireinject\n",
+	}
+	for file, want := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s missing %q:\n%s", file, want, data)
+		}
+	}
+	if want := []string{"RepoSet"}; !slices.Equal(sc.sets, want) {
+		t.Errorf("sets = %v, want %v", sc.sets, want)
+	}
+	if reason := sc.includableSet("integration"); reason == "" {
+		t.Error("includableSet(integration) should reject a set with build constraints")
+	}
+}
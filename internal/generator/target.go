@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+// ForTarget method    返回使用本次扫描结果、按 o 生成到另一个目标的搜索器，批量生成时多个目标共享一次扫描
+// 组件按记录的源文件解析结果重新汇总，注解语法问题已在扫描的目标中报告，不再重复；
+// 每个目标使用生成路径中各自的缓存，记录各自生成的文件.
+func (sc *AutoWireSearcher) ForTarget(o *config.Opt) *AutoWireSearcher {
+	t := NewAutoWireSearcher(o, sc.modBase)
+	if err := t.cache.Load(); err != nil {
+		t.logger.Warn("加载缓存失败", "error", err)
+	}
+	t.cache.adoptFiles(sc.cache)
+
+	t.fileElements = sc.fileElements
+	t.scannedDirs = sc.scannedDirs
+	t.searchRoots = sc.searchRoots
+	t.packages = sc.packages
+	t.stats = sc.stats
+	t.fset = sc.fset
+	t.rebuild()
+	return t
+}
+
+// applyOnlySets method    只保留 WithSets 指定的 Set 与组合 Set，未指定时保留全部.
+func (sc *AutoWireSearcher) applyOnlySets() {
+	if len(sc.onlySets) == 0 {
+		return
+	}
+	maps.DeleteFunc(sc.ElementMap, func(set string, _ map[string]Element) bool {
+		return !slices.Contains(sc.onlySets, set)
+	})
+	sc.composites = slices.DeleteFunc(sc.composites, func(c Element) bool {
+		return !slices.Contains(sc.onlySets, c.Name)
+	})
+}
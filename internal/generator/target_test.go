@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestForTarget(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package m\n\n// @autowire(set=a)\ntype A struct{}\n")
+	write("b.go", "package m\n\n// @autowire(set=b)\ntype B struct{}\n\n// @autowire(set=b,bad\ntype C struct{}\n")
+
+	targets := config.WithTargets(filepath.Join(dir, "api"), filepath.Join(dir, "worker"))
+	opt := func(genPath string, sets ...string) *config.Opt {
+		return config.NewGenOpt(filepath.Join(dir, genPath), config.WithSets(sets...), targets,
+			config.WithCache(false), config.WithLogger(logger.Discard()))
+	}
+	sc := NewAutoWireSearcher(opt("api", "a"), "example.com/m")
+	if err := sc.SearchAllPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.SortedKeys(sc.ElementMap); !slices.Equal(got, []string{"a", "b"}) || len(sc.Diagnostics()) != 1 {
+		t.Fatalf("sets = %v, diagnostics = %v", got, sc.Diagnostics())
+	}
+
+	worker := sc.ForTarget(opt("worker", "B"))
+	if worker.genPath != filepath.Join(dir, "worker") || len(worker.Diagnostics()) != 0 {
+		t.Errorf("ForTarget() genPath = %s, diagnostics = %v", worker.genPath, worker.Diagnostics())
+	}
+	if err := worker.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.SortedKeys(worker.ElementMap); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("worker sets = %v, want [b]", got)
+	}
+	// 扫描结果不受其他目标的 Set 过滤影响，Validate 不修改扫描结果
+	if err := sc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.SortedKeys(sc.ElementMap); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Validate() 修改了扫描结果: sets = %v", got)
+	}
+	if err := sc.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.SortedKeys(sc.ElementMap); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("api sets = %v, want [a]", got)
+	}
+}
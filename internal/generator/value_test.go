@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestCollectVarDecls(t *testing.T) {
+	src := `package cfg
+
+// @autowire.value(set=config)
+var DefaultOptions = &Options{}
+
+var (
+	// @autowire.value(set=config,io.Writer)
+	Out = os.Stdout
+
+	Plain = 1
+
+	// @autowire.value(set=config)
+	Timeout time.Duration = 3
+)
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "cfg.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}, logger: logger.Discard()}
+	decls := sc.collectAnnotatedDecls(fset, f)
+	var names []string
+	for _, d := range decls {
+		names = append(names, d.name)
+	}
+	if got := strings.Join(names, ","); got != "DefaultOptions,Out,Timeout" {
+		t.Fatalf("collectAnnotatedDecls() = %s", got)
+	}
+
+	r := typeResolver{file: f, pkgPath: "example.com/cfg"}
+	if got := r.valueType(decls[0].valueSpec); got != "example.com/cfg.Options" {
+		t.Errorf("valueType(DefaultOptions) = %s", got)
+	}
+	if got := r.valueType(decls[2].valueSpec); got != "time.Duration" {
+		t.Errorf("valueType(Timeout) = %s", got)
+	}
+}
+
+func TestHandleValueWireElement(t *testing.T) {
+	sc := &AutoWireSearcher{mu: &sync.Mutex{}}
+	refs := newInterfaceRefs("example.com/wire", nil)
+
+	var items []string
+	sc.handleNormalWireElement(&Element{Name: "Timeout", Pkg: "cfg", ValueWire: true}, &items, "cfg.Timeout", refs)
+	sc.handleNormalWireElement(&Element{Name: "Out", Pkg: "cfg", ValueWire: true, Implements: []string{"io.Writer"}},
+		&items, "cfg.Out", refs)
+
+	want := "wire.Value(cfg.Timeout);wire.InterfaceValue(new(io.Writer), cfg.Out)"
+	if got := strings.Join(items, ";"); got != want {
+		t.Errorf("items = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// wirePosPattern wire 输出中的源码位置，如 /work/gen/autowire_sets.go:20:2、C:\work\svc\repo.go:14:6.
+var wirePosPattern = regexp.MustCompile(`((?:[A-Za-z]:)?[^\s():"]+\.go):(\d+)(?::\d+)?`)
+
+// providerSources struct    生成的 Set 中每个提供者表达式对应的组件，用于将 wire 报告的位置映射回注解.
+type providerSources struct {
+	mu       sync.Mutex
+	elements map[string]Element // 提供者表达式，如 svc.NewRepo、wire.Bind(new(svc.Repo), new(*svc.repo)) -> 组件
+}
+
+// add method    记录组件生成的提供者表达式，同一表达式只记录第一次出现的组件.
+func (p *providerSources) add(items []string, elem Element) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.elements == nil {
+		p.elements = make(map[string]Element)
+	}
+	for _, item := range items {
+		if _, ok := p.elements[item]; !ok {
+			p.elements[item] = elem
+		}
+	}
+}
+
+// get method    返回提供者表达式对应的组件.
+func (p *providerSources) get(expr string) (Element, bool) {
+	if p == nil {
+		return Element{}, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	elem, ok := p.elements[expr]
+	return elem, ok
+}
+
+// WireSources method    将 wire 输出中的位置映射回产生对应提供者的注解
+// 位置在源文件中时查找该文件中声明的组件，在生成的 Set 文件中时按该行的提供者表达式查找组件；
+// 返回去重后的注解位置描述，如 services/user/repo.go:14 的 @autowire 注解（Repo）.
+func (sc *AutoWireSearcher) WireSources(output string) []string {
+	var sources []string
+	for _, m := range wirePosPattern.FindAllStringSubmatch(output, -1) {
+		file := m[1]
+		if !filepath.IsAbs(file) {
+			// wire 在生成目录中运行，相对路径相对生成目录
+			file = filepath.Join(sc.genPath, file)
+		}
+		line, _ := strconv.Atoi(m[2])
+		elem, ok := sc.wireSource(file, line)
+		if !ok || !elem.Position.IsValid() {
+			continue
+		}
+		source := fmt.Sprintf("%s:%d 的 %s 注解（%s）",
+			sc.relPath(elem.Position.Filename), elem.Position.Line, sc.annotation(), instanceName(elem))
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// wireSource method    返回 wire 报告的位置对应的组件.
+func (sc *AutoWireSearcher) wireSource(file string, line int) (Element, bool) {
+	if elem, ok := sc.declaredAt(file, line); ok {
+		return elem, true
+	}
+
+	// 生成的文件：该行为一个提供者表达式（末尾带逗号）
+	//nolint:gosec
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Element{}, false
+	}
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return Element{}, false
+	}
+	return sc.providers.get(strings.TrimSuffix(strings.TrimSpace(lines[line-1]), ","))
+}
+
+// declaredAt method    返回源文件中声明在该行的组件，没有时返回该行之前最近声明的组件
+// wire 报告构造函数的位置，构造函数通常紧跟在带注解的类型之后.
+func (sc *AutoWireSearcher) declaredAt(file string, line int) (Element, bool) {
+	var found Element
+	for _, set := range sc.ElementMap {
+		for _, elem := range set {
+			pos := elem.Position
+			if !pos.IsValid() || pos.Line > line {
+				continue
+			}
+			if rel, ok := parser.RelPath(absPath(pos.Filename), absPath(file)); !ok || rel != "." {
+				continue
+			}
+			if pos.Line > found.Position.Line ||
+				(pos.Line == found.Position.Line && elem.Name < found.Name) {
+				found = elem
+			}
+		}
+	}
+	return found, found.Position.IsValid()
+}
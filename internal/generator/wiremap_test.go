@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestWireSources(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "svc", "repo.go")
+	gen := filepath.Join(dir, "gen", "autowire_svc.go")
+	if err := os.MkdirAll(filepath.Dir(gen), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	setSrc := "package gen\n\nvar SvcSet = wire.NewSet(\n\tsvc.NewRepo,\n\tsvc.NewCache,\n)\n"
+	if err := os.WriteFile(gen, []byte(setSrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := Element{Name: "Repo", Constructor: "NewRepo", Position: token.Position{Filename: src, Line: 10}}
+	cache := Element{Name: "Cache", Constructor: "NewCache", Position: token.Position{Filename: src, Line: 30}}
+	sc := &AutoWireSearcher{
+		mu:         &sync.Mutex{},
+		genPath:    filepath.Dir(gen),
+		logger:     logger.Discard(),
+		ElementMap: map[string]map[string]Element{"svc": {"svc.Repo": repo, "svc.Cache": cache}},
+		providers:  &providerSources{},
+	}
+	sc.providers.add([]string{"svc.NewRepo"}, repo)
+	sc.providers.add([]string{"svc.NewCache"}, cache)
+
+	output := "wire: " + filepath.Join(dir, "gen", "wire.gen.go") + ":12:1: inject InitializeApp: " +
+		"no provider found for *db.DB\n" +
+		"\tneeded by *svc.Repo in provider \"NewRepo\" (" + src + ":16:6)\n" +
+		"wire: autowire_svc.go:5:2: unused provider\n" +
+		"wire: " + src + ":12:6: duplicate\n"
+	got := sc.WireSources(output)
+	want := []string{
+		"svc/repo.go:10 的 @autowire 注解（Repo）",
+		"svc/repo.go:30 的 @autowire 注解（Cache）",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("WireSources() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !strings.HasSuffix(got[i], want[i]) {
+			t.Errorf("WireSources()[%d] = %q, want suffix %q", i, got[i], want[i])
+		}
+	}
+}
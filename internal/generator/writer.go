@@ -1,19 +1,74 @@
 package generator
 
 import (
+	"go/token"
+	"strings"
 	"text/template"
+
+	"github.com/stoewer/go-strcase"
 )
 
 // Element struct    表示一个可注入的组件(结构体或函数).
 type Element struct {
-	Name        string   // 组件名称，如 Zoo、Cat
-	Constructor string   // 构造函数名称，如 NewZoo、InitCat
-	Fields      []string // 结构体字段列表（用于 config 模式）
-	Implements  []string // 实现的接口列表
-	Pkg         string   // 所在包名
-	PkgPath     string   // 完整的包导入路径
-	InitWire    bool     // 是否标记为 @autowire.init
-	ConfigWire  bool     // 是否标记为 @autowire.config
+	Name          string            // 组件名称，如 Zoo、Cat
+	Set           string            // 所属 Set 名称，如 animals
+	Constructor   string            // 构造函数名称，如 NewZoo、InitCat
+	BadResult     string            // 类型声明的构造函数不返回该类型时的签名与位置，如 NewZoo() *Cat (zoo.go:12:6)
+	Fields        []string          // 结构体字段列表（用于 config 模式）
+	StructFields  []string          // wire.Struct 注入的字段（fields=、exclude= 参数），nil 表示注入全部字段
+	Underlying    string            // 非结构体类型声明的类型表达式，如 int、= redis.Client（类型别名），结构体为空
+	Ambiguous     []string          // wire.Struct 注入的字段中类型相同的字段，如 *example.com/db.DB: Primary、Replica
+	Implements    []string          // 实现的接口列表
+	Pkg           string            // 所在包名
+	PkgPath       string            // 完整的包导入路径
+	FuncDecl      bool              // 是否为函数声明（而非类型声明）
+	Priority      int               // 绑定优先级（priority= 参数），用于解决重复绑定
+	Provides      []string          // 提供的类型（包路径.类型名，不含指针），包括绑定的接口
+	Deps          []string          // 依赖的类型（构造函数参数或 wire.Struct 注入的字段）
+	Result        string            // 构造函数第一个返回值的类型表达式（源码形式），如 *Zoo、http.Handler
+	Cleanup       bool              // 构造函数是否返回 cleanup 函数 func()
+	ReturnsErr    bool              // 构造函数是否返回 error
+	TypeParams    int               // 泛型声明的类型参数个数（有构造函数时为构造函数的类型参数）
+	TypeArgs      []string          // 泛型实例化的类型实参（of= 参数），完整形式如 example.com/model.User
+	Tag           string            // 构建标签（tag= 参数），生成到带 //go:build 约束的独立文件
+	Out           string            // 输出目录（out= 参数或配置文件中 Set 的输出目录），相对模块根目录，为空表示生成路径
+	BindValue     bool              // 按值绑定接口（value 参数），生成 wire.Bind(new(I), new(T))
+	BindKinds     map[string]string // 接口 -> 绑定方式 ptr 或 value（接口参数的 :ptr、:value 标记），优先于 BindValue
+	Qualifier     string            // 限定名（qualifier= 参数），用于区分同一接口或类型的多个提供者
+	Qualified     []string          // 限定类型及包装构造函数的源码，生成到组件所在包的 autowire_qualifier.go
+	Scope         string            // 作用域（scope= 参数），factory 表示提供每次调用构造新实例的 <Type>Factory
+	Wrappers      []string          // 方法工厂、泛型实例化与 scope=factory 的包装函数源码，生成到组件所在包的 autowire_factory.go
+	Imports       []string          // 组件所在文件的导入（仅 Qualified、Wrappers 或 EnvProviders 非空时记录），用于生成组件包中的文件
+	Lifecycle     []string          // 组件类型上的生命周期方法（Start、Stop），按依赖顺序启动、相反顺序停止
+	Group         string            // 分组（group= 参数），分组的全部成员汇总为切片 []GroupType 注入
+	GroupType     string            // 分组切片的元素类型：成员绑定的唯一接口（包路径.类型名），成员不再单独绑定
+	GroupProvider bool              // 是否为分组的汇总提供者 New<Group>Group，生成到生成路径的 autowire_groups.go
+	InitWire      bool              // 是否标记为 @autowire.init
+	Injector      string            // 命名注入入口（@autowire.init(name=...)），生成 Initialize<Injector>
+	Context       bool              // 初始化函数以 ctx context.Context 为第一个参数（ctx 参数），传给需要 context 的构造函数
+	Args          []string          // 初始化函数的额外参数（args= 参数），如 opts ...app.Option，追加在配置参数之后
+	ArgTypes      []string          // 额外参数提供的类型，可变参数为切片类型，如 []example.com/app.Option
+	ConfigWire    bool              // 是否标记为 @autowire.config
+	ValueWire     bool              // 是否标记为 @autowire.value（包级变量）
+	Mock          bool              // 是否标记为 @autowire.mock（测试替身，只生成到测试注入包）
+	Interface     bool              // 是否为带 @autowire 注解的接口（自动查找实现并绑定）
+	Composite     bool              // 是否为组合 Set（@autowire.set），Name 为组合 Set 名称
+	Includes      []string          // 组合 Set 包含的 Set 名称（include= 参数）
+	Methods       []string          // 接口的方法签名（仅 Interface 为 true 时有效）
+	Deprecated    string            // 弃用说明（deprecated= 参数），为空表示未弃用
+	Raw           string            // 自定义提供者表达式（@autowire.raw 的 expr= 参数），原样生成到 Set 中
+	RawExpr       []string          // 拆分后的表达式：偶数位置为源码片段，奇数位置为引用的完整形式，如 example.com/db.Open
+	Env           []string          // 读取的环境变量名称（@autowire.env），如 PORT
+	EnvProviders  []string          // 读取环境变量的提供者源码，生成到组件所在包的 autowire_env.go
+	Position      token.Position    // 声明在源文件中的位置
+}
+
+// compareElements function    按名称、包路径排序组件，同名组件的顺序同样稳定.
+func compareElements(a, b Element) int {
+	if c := strings.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	return strings.Compare(a.PkgPath, b.PkgPath)
 }
 
 // WireSet struct    表示一个 Wire Set 的配置信息.
@@ -21,6 +76,23 @@ type WireSet struct {
 	Package string   // 包名
 	Items   []string // Set 中包含的所有项（构造函数、结构体等）
 	SetName string   // Set 的名称，如 AnimalsSet
+	Tags    []string // 除 wireinject 外的构建约束，如 prod、!dev
+	Shared  string   // 共享 Set 的变量名，非空时 Items 生成到私有 Set（见 PrivateName），SetName 组合私有 Set 与共享 Set
+}
+
+// PrivateName method    返回 Set 自有组件的私有 Set 名称，如 DbSet 返回 dbSet，汇总 Sets 与组合 Set 引用私有 Set.
+func (s WireSet) PrivateName() string {
+	return strcase.LowerCamelCase(s.SetName)
+}
+
+// GoBuild method    返回 //go:build 约束表达式，如 wireinject && prod.
+func (s WireSet) GoBuild() string {
+	return strings.Join(append([]string{"wireinject"}, s.Tags...), " && ")
+}
+
+// PlusBuild method    返回旧式 // +build 约束，如 wireinject,prod.
+func (s WireSet) PlusBuild() string {
+	return strings.Join(append([]string{"wireinject"}, s.Tags...), ",")
 }
 
 // SetTemp 预编译的 Set 模板，用于快速生成代码.
@@ -30,8 +102,8 @@ var SetTemp = template.Must(template.New("").Parse(setTemplate))
 // 用于生成类似 var AnimalsSet = wire.NewSet(...) 的代码.
 var setTemplate = `// Code generated by go-autowire. DO NOT EDIT.
 
-//go:build wireinject
-// +build wireinject
+//go:build {{ .GoBuild }}
+// +build {{ .PlusBuild }}
 
 package {{ .Package }}
 
@@ -39,25 +111,49 @@ import (
 	"github.com/google/wire"
 )
 
-var {{ .SetName }} = wire.NewSet({{ range $Item := .Items}} 
+var {{ if .Shared }}{{ .PrivateName }}{{ else }}{{ .SetName }}{{ end }} = wire.NewSet({{ range $Item := .Items}} 
 	{{ $Item }},
     {{ end }}
 )
+{{- if .Shared }}
+
+// {{ .SetName }} 包含共享 Set {{ .Shared }}，在同一个 wire.Build 中使用多个 Set 时
+// 应引用 {{ .PrivateName }} 等私有 Set 并只包含一次 {{ .Shared }}，否则 wire 会报告重复绑定.
+var {{ .SetName }} = wire.NewSet({{ .PrivateName }}, {{ .Shared }})
+{{- end }}
 `
 
-// initTemplateHead 初始化函数文件的头部模板.
-var initTemplateHead = `// Code generated by go-autowire. DO NOT EDIT.
+// InitFile struct    初始化文件 wire.gen.go 的模板数据，自定义模板（init_template）使用相同的字段.
+type InitFile struct {
+	Package   string     // 包名
+	Injectors []Injector // 初始化函数，按生成顺序排列
+}
+
+// Injector struct    单个初始化函数的模板数据.
+type Injector struct {
+	Name    string   // 注入入口名称，如 Zoo、AdminApp，测试注入包中带 Test 前缀
+	Type    string   // 返回的组件类型，如 *zoo.Zoo
+	Params  []string // 参数声明，如 c0 *config.Config
+	Results string   // 返回值列表，如 (*zoo.Zoo, func(), error)
+	Build   string   // wire.Build 的参数，如 Sets, InitAdminSet
+}
+
+// initTemplateFuncs 初始化文件模板可用的函数.
+var initTemplateFuncs = template.FuncMap{"join": strings.Join}
+
+// InitTemp 预编译的初始化文件模板.
+var InitTemp = template.Must(template.New("init").Funcs(initTemplateFuncs).Parse(initTemplate))
+
+// initTemplate 初始化文件的代码生成模板
+// 生成类似 func InitializeZoo() (*Zoo, func(), error) 的函数，返回值按依赖链上的构造函数签名确定.
+var initTemplate = `// Code generated by go-autowire. DO NOT EDIT.
 
 //go:build wireinject
 // +build wireinject
 
-package %s
-`
-
-// initItemTemplate 单个初始化函数的模板
-// 生成类似 func InitializeZoo() (*Zoo, func(), error) 的函数.
-var initItemTemplate = `
-func Initialize%s(%s) (%s, func(), error) {
-	panic(wire.Build(Sets))
+package {{ .Package }}
+{{ range .Injectors }}
+func Initialize{{ .Name }}({{ join .Params ", " }}) {{ .Results }} {
+	panic(wire.Build({{ .Build }}))
 }
-`
+{{ end }}`
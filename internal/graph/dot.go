@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// WriteDOT method    将依赖图以 Graphviz DOT 格式写出，同一 Set 的节点放在同一子图中.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph gutowire {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	bySet := make(map[string][]string)
+	for id, n := range g.Nodes {
+		bySet[n.Set] = append(bySet[n.Set], id)
+	}
+	for i, set := range parser.SortedKeys(bySet) {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%s;\n", strconv.Quote(strcase.UpperCamelCase(set)+"Set"))
+		for _, id := range parser.SortedKeys(parser.NewSet(bySet[set]...)) {
+			fmt.Fprintf(&sb, "    %s [label=%s];\n", strconv.Quote(id), strconv.Quote(g.Nodes[id].Label))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
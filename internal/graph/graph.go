@@ -0,0 +1,226 @@
+// Package graph 根据扫描得到的组件构建依赖图。
+// 提供依赖图的过滤（聚焦、深度、排除 Set）与导出能力。
+package graph
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Direction 聚焦查询时遍历的方向.
+type Direction string
+
+const (
+	// DirectionDeps 沿依赖方向遍历（聚焦节点依赖了谁）.
+	DirectionDeps Direction = "deps"
+	// DirectionDependents 沿被依赖方向遍历（谁依赖了聚焦节点）.
+	DirectionDependents Direction = "dependents"
+	// DirectionBoth 同时沿两个方向遍历.
+	DirectionBoth Direction = "both"
+)
+
+// 依赖图的输出格式.
+const (
+	FormatDOT     = "dot"     // Graphviz DOT
+	FormatMermaid = "mermaid" // Mermaid flowchart
+	FormatJSON    = "json"    // 节点与边的 JSON
+)
+
+// Node struct    依赖图中的节点，对应一个组件.
+type Node struct {
+	ID      string            // 唯一标识（包路径/组件名称）
+	Label   string            // 显示名称，如 pkg.Zoo
+	Set     string            // 所属 Set 名称
+	Element generator.Element // 组件信息
+}
+
+// Edge struct    依赖图中的边，From 依赖 To.
+type Edge struct {
+	From string // 消费者节点 ID
+	To   string // 提供者节点 ID
+	Type string // 依赖的类型（包路径.类型名）
+}
+
+// Graph struct    组件依赖图.
+type Graph struct {
+	Nodes   map[string]*Node    // 节点 ID -> 节点
+	Edges   []Edge              // 按 From、To 排序的边
+	Missing map[string][]string // 节点 ID -> 没有任何组件提供的依赖类型
+}
+
+// FilterOptions struct    依赖图过滤选项.
+type FilterOptions struct {
+	Focus       string    // 聚焦的组件，如 pkg.Zoo，为空表示不聚焦
+	Depth       int       // 聚焦遍历的最大深度，0 表示不限制
+	Direction   Direction // 聚焦遍历方向，默认 both
+	ExcludeSets []string  // 需要排除的 Set 名称
+}
+
+// Build function    根据 ElementMap 构建依赖图
+// 组件的依赖类型与其他组件提供的类型一致时连一条边.
+func Build(elementMap map[string]map[string]generator.Element) *Graph {
+	g := &Graph{Nodes: make(map[string]*Node), Missing: make(map[string][]string)}
+	providers := make(map[string][]string)
+
+	for _, set := range parser.SortedKeys(elementMap) {
+		for _, id := range parser.SortedKeys(elementMap[set]) {
+			elem := elementMap[set][id]
+			g.Nodes[id] = &Node{
+				ID:      id,
+				Label:   parser.AppendPkg(elem.Pkg, elem.Name),
+				Set:     set,
+				Element: elem,
+			}
+			for _, t := range elem.Provides {
+				providers[t] = append(providers[t], id)
+			}
+		}
+	}
+
+	seen := parser.NewSet[string]()
+	for _, id := range parser.SortedKeys(g.Nodes) {
+		for _, t := range g.Nodes[id].Element.Deps {
+			if len(providers[t]) == 0 && !slices.Contains(g.Missing[id], t) {
+				g.Missing[id] = append(g.Missing[id], t)
+			}
+			for _, to := range providers[t] {
+				if to == id || seen.Contains(id+"\x00"+to) {
+					continue
+				}
+				seen.Add(id + "\x00" + to)
+				g.Edges = append(g.Edges, Edge{From: id, To: to, Type: t})
+			}
+		}
+	}
+	g.sortEdges()
+	return g
+}
+
+// Filter method    按选项过滤依赖图，返回新的依赖图.
+func (g *Graph) Filter(opt FilterOptions) (*Graph, error) {
+	keep := parser.NewSet[string]()
+	for id, n := range g.Nodes {
+		if !slices.Contains(opt.ExcludeSets, n.Set) {
+			keep.Add(id)
+		}
+	}
+
+	if opt.Focus != "" {
+		roots := g.Find(opt.Focus)
+		if len(roots) == 0 {
+			return nil, fmt.Errorf("未找到匹配的组件: %s", opt.Focus)
+		}
+		focused := parser.NewSet[string]()
+		if opt.Direction != DirectionDependents {
+			maps.Copy(focused, reachable(roots, keep, opt.Depth, g.adjacency(false)))
+		}
+		if opt.Direction != DirectionDeps {
+			maps.Copy(focused, reachable(roots, keep, opt.Depth, g.adjacency(true)))
+		}
+		keep = focused
+	}
+
+	return g.subgraph(keep), nil
+}
+
+// Find method    查找与名称匹配的节点 ID
+// 支持 pkg.Name、包路径/Name 以及单独的组件名称.
+func (g *Graph) Find(name string) []string {
+	var ids []string
+	for _, id := range parser.SortedKeys(g.Nodes) {
+		n := g.Nodes[id]
+		if n.Label == name || n.ID == name || n.Element.Name == name ||
+			strings.HasSuffix(n.ID, "/"+strings.ReplaceAll(name, ".", "/")) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// adjacency method    返回邻接表，reverse 为 true 时边反向（提供者 -> 消费者）.
+func (g *Graph) adjacency(reverse bool) map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range g.Edges {
+		if reverse {
+			adj[e.To] = append(adj[e.To], e.From)
+		} else {
+			adj[e.From] = append(adj[e.From], e.To)
+		}
+	}
+	return adj
+}
+
+// reachable function    从起点出发在允许的节点内沿邻接表遍历，返回可达节点（含起点）.
+func reachable(roots []string, allowed parser.Set[string], depth int, adj map[string][]string) parser.Set[string] {
+	visited := parser.NewSet[string]()
+	frontier := make([]string, 0, len(roots))
+	for _, id := range roots {
+		if allowed.Contains(id) {
+			visited.Add(id)
+			frontier = append(frontier, id)
+		}
+	}
+
+	for level := 0; len(frontier) > 0 && (depth <= 0 || level < depth); level++ {
+		var next []string
+		for _, id := range frontier {
+			for _, nb := range adj[id] {
+				if allowed.Contains(nb) && !visited.Contains(nb) {
+					visited.Add(nb)
+					next = append(next, nb)
+				}
+			}
+		}
+		frontier = next
+	}
+	return visited
+}
+
+// subgraph method    返回仅包含指定节点及其之间边的子图.
+func (g *Graph) subgraph(keep parser.Set[string]) *Graph {
+	sub := &Graph{Nodes: make(map[string]*Node), Missing: make(map[string][]string)}
+	for id, n := range g.Nodes {
+		if keep.Contains(id) {
+			sub.Nodes[id] = n
+			if m, ok := g.Missing[id]; ok {
+				sub.Missing[id] = m
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		if keep.Contains(e.From) && keep.Contains(e.To) {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+// Write method    按指定格式写出依赖图.
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatMermaid:
+		return g.WriteMermaid(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s（可选 %s、%s、%s）", format, FormatDOT, FormatMermaid, FormatJSON)
+	}
+}
+
+// sortEdges method    对边排序，保证输出稳定.
+func (g *Graph) sortEdges() {
+	slices.SortFunc(g.Edges, func(a, b Edge) int {
+		if c := strings.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return strings.Compare(a.To, b.To)
+	})
+}
@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+)
+
+// newTestGraph function    构建 Zoo -> Cat -> Food、Zoo -> Dog、Mock -> Food 的依赖图.
+func newTestGraph() *Graph {
+	return Build(map[string]map[string]generator.Element{
+		"zoo": {
+			"example.com/zoo/Zoo": {Name: "Zoo", Pkg: "zoo", Provides: []string{"example.com/zoo.Zoo"},
+				Deps: []string{"example.com/zoo.Cat", "example.com/zoo.Dog"}},
+			"example.com/zoo/Cat": {Name: "Cat", Pkg: "zoo", Provides: []string{"example.com/zoo.Cat"},
+				Deps: []string{"example.com/food.Food"}},
+			"example.com/zoo/Dog": {Name: "Dog", Pkg: "zoo", Provides: []string{"example.com/zoo.Dog"}},
+		},
+		"food": {
+			"example.com/food/Food": {Name: "Food", Pkg: "food", Provides: []string{"example.com/food.Food"}},
+		},
+		"mocks": {
+			"example.com/mocks/Mock": {Name: "Mock", Pkg: "mocks", Provides: []string{"example.com/mocks.Mock"},
+				Deps: []string{"example.com/food.Food"}},
+		},
+	})
+}
+
+func TestBuild(t *testing.T) {
+	g := newTestGraph()
+	if len(g.Nodes) != 5 {
+		t.Errorf("节点数量 = %d, want 5", len(g.Nodes))
+	}
+	if len(g.Edges) != 4 {
+		t.Errorf("边数量 = %d, want 4: %v", len(g.Edges), g.Edges)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  FilterOptions
+		want []string
+	}{
+		{"不过滤", FilterOptions{}, []string{"Cat", "Dog", "Food", "Mock", "Zoo"}},
+		{"排除 Set", FilterOptions{ExcludeSets: []string{"mocks"}}, []string{"Cat", "Dog", "Food", "Zoo"}},
+		{"聚焦依赖", FilterOptions{Focus: "zoo.Zoo", Direction: DirectionDeps}, []string{"Cat", "Dog", "Food", "Zoo"}},
+		{"限制深度", FilterOptions{Focus: "zoo.Zoo", Depth: 1, Direction: DirectionDeps}, []string{"Cat", "Dog", "Zoo"}},
+		{"聚焦被依赖", FilterOptions{Focus: "Food", Direction: DirectionDependents}, []string{"Cat", "Food", "Mock", "Zoo"}},
+		{
+			"聚焦并排除 Set",
+			FilterOptions{Focus: "food.Food", Direction: DirectionBoth, ExcludeSets: []string{"mocks"}},
+			[]string{"Cat", "Food", "Zoo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newTestGraph().Filter(tt.opt)
+			if err != nil {
+				t.Fatalf("Filter() error = %v", err)
+			}
+			var got []string
+			for _, id := range sortedNodeIDs(g) {
+				got = append(got, g.Nodes[id].Element.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_FocusNotFound(t *testing.T) {
+	if _, err := newTestGraph().Filter(FilterOptions{Focus: "zoo.Lion"}); err == nil {
+		t.Error("Filter() 应该返回错误")
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestGraph().WriteDOT(&buf); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`label="ZooSet";`,
+		`"example.com/zoo/Zoo" [label="zoo.Zoo"];`,
+		`"example.com/zoo/Zoo" -> "example.com/zoo/Cat";`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteDOT() 缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+// sortedNodeIDs function    按组件名称排序返回节点 ID.
+func sortedNodeIDs(g *Graph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		return strings.Compare(g.Nodes[a].Element.Name, g.Nodes[b].Element.Name)
+	})
+	return ids
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestGraph().Write(&buf, FormatMermaid); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+	// 节点按 ID 排序编号: Food=n0, Mock=n1, Cat=n2, Dog=n3, Zoo=n4
+	for _, want := range []string{
+		"flowchart LR\n",
+		`subgraph s2 ["ZooSet"]`,
+		`n4["zoo.Zoo"]`,
+		"n4 --> n2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteMermaid() 缺少 %q:\n%s", want, out)
+		}
+	}
+
+	if err := newTestGraph().Write(&buf, "svg"); err == nil {
+		t.Error("Write() 不支持的格式应该返回错误")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestGraph().Write(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Nodes []struct {
+			ID  string `json:"id"`
+			Set string `json:"set"`
+		} `json:"nodes"`
+		Edges []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("输出不是合法的 JSON: %v\n%s", err, buf.String())
+	}
+	if len(out.Nodes) != 5 || len(out.Edges) != 4 {
+		t.Errorf("节点数量 = %d, 边数量 = %d, want 5, 4", len(out.Nodes), len(out.Edges))
+	}
+	if out.Nodes[0].ID != "example.com/food/Food" || out.Nodes[0].Set != "food" {
+		t.Errorf("节点应按 ID 排序: %+v", out.Nodes[0])
+	}
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// jsonNode struct    JSON 输出中的节点.
+type jsonNode struct {
+	ID       string   `json:"id"`
+	Label    string   `json:"label"`
+	Set      string   `json:"set"`
+	Position string   `json:"position,omitempty"`
+	Missing  []string `json:"missing,omitempty"`
+}
+
+// jsonEdge struct    JSON 输出中的边.
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// WriteJSON method    将依赖图以 JSON 格式写出，节点按 ID 排序.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	out := struct {
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+
+	for _, id := range parser.SortedKeys(g.Nodes) {
+		n := g.Nodes[id]
+		node := jsonNode{ID: id, Label: n.Label, Set: n.Set, Missing: g.Missing[id]}
+		if n.Element.Position.IsValid() {
+			node.Position = n.Element.Position.String()
+		}
+		out.Nodes = append(out.Nodes, node)
+	}
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, jsonEdge(e))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// WriteMetricsJSON function    以 JSON 数组写出组件指标，onlyOutliers 为 true 时只输出离群组件.
+func WriteMetricsJSON(w io.Writer, metrics []NodeMetrics, onlyOutliers bool) error {
+	out := make([]NodeMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		if !onlyOutliers || len(m.Outliers) > 0 {
+			out = append(out, m)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
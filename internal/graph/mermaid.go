@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// WriteMermaid method    将依赖图以 Mermaid flowchart 格式写出，同一 Set 的节点放在同一子图中
+// Mermaid 的节点 ID 不能包含 / 等字符，这里按节点排序后使用 n0、n1 等编号.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	ids := parser.SortedKeys(g.Nodes)
+	short := make(map[string]string, len(ids))
+	for i, id := range ids {
+		short[id] = fmt.Sprintf("n%d", i)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	bySet := make(map[string][]string)
+	for _, id := range ids {
+		bySet[g.Nodes[id].Set] = append(bySet[g.Nodes[id].Set], id)
+	}
+	for i, set := range parser.SortedKeys(bySet) {
+		fmt.Fprintf(&sb, "  subgraph s%d [\"%s\"]\n", i, strcase.UpperCamelCase(set)+"Set")
+		for _, id := range bySet[set] {
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", short[id], mermaidEscape(g.Nodes[id].Label))
+		}
+		sb.WriteString("  end\n")
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", short[e.From], short[e.To])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaidEscape function    转义 Mermaid 标签中的双引号.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
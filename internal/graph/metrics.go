@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// 离群指标名称.
+const (
+	MetricFanIn  = "fan-in"  // 被依赖数量
+	MetricFanOut = "fan-out" // 依赖数量
+	MetricDepth  = "depth"   // 距注入根节点的深度
+)
+
+// minOutlierValue 离群判定的最小值，避免小图中的正常波动被误判.
+const minOutlierValue = 3
+
+// NodeMetrics struct    单个组件的依赖指标.
+type NodeMetrics struct {
+	ID       string   `json:"id"`                 // 节点 ID
+	Label    string   `json:"label"`              // 显示名称
+	Set      string   `json:"set"`                // 所属 Set 名称
+	FanIn    int      `json:"fan_in"`             // 被多少个组件依赖
+	FanOut   int      `json:"fan_out"`            // 依赖多少个组件
+	Depth    int      `json:"depth"`              // 距最近注入根节点的深度，-1 表示不可达
+	Outliers []string `json:"outliers,omitempty"` // 超出阈值的指标名称
+}
+
+// Metrics method    计算每个组件的扇入、扇出以及距注入根节点的深度
+// 注入根节点为 @autowire.init 组件，没有时使用不被任何组件依赖的节点；
+// 指标超过 平均值 + sigma × 标准差 时标记为离群，结果按扇入与扇出之和降序排列.
+func (g *Graph) Metrics(sigma float64) []NodeMetrics {
+	fanIn := make(map[string]int)
+	fanOut := make(map[string]int)
+	for _, e := range g.Edges {
+		fanOut[e.From]++
+		fanIn[e.To]++
+	}
+	depth := g.depthFromRoots(fanIn)
+
+	result := make([]NodeMetrics, 0, len(g.Nodes))
+	for id, n := range g.Nodes {
+		d, ok := depth[id]
+		if !ok {
+			d = -1
+		}
+		result = append(result, NodeMetrics{
+			ID: id, Label: n.Label, Set: n.Set,
+			FanIn: fanIn[id], FanOut: fanOut[id], Depth: d,
+		})
+	}
+
+	markOutliers(result, MetricFanIn, sigma, func(m NodeMetrics) int { return m.FanIn })
+	markOutliers(result, MetricFanOut, sigma, func(m NodeMetrics) int { return m.FanOut })
+	markOutliers(result, MetricDepth, sigma, func(m NodeMetrics) int { return m.Depth })
+
+	slices.SortFunc(result, func(a, b NodeMetrics) int {
+		if c := cmp.Compare(b.FanIn+b.FanOut, a.FanIn+a.FanOut); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// depthFromRoots method    从注入根节点沿依赖方向做广度优先遍历，返回每个节点的最短深度.
+func (g *Graph) depthFromRoots(fanIn map[string]int) map[string]int {
+	var roots []string
+	for id, n := range g.Nodes {
+		if n.Element.InitWire {
+			roots = append(roots, id)
+		}
+	}
+	if len(roots) == 0 {
+		for id := range g.Nodes {
+			if fanIn[id] == 0 {
+				roots = append(roots, id)
+			}
+		}
+	}
+
+	depth := make(map[string]int, len(g.Nodes))
+	for _, id := range roots {
+		depth[id] = 0
+	}
+	adj := g.adjacency(false)
+	for frontier := roots; len(frontier) > 0; {
+		var next []string
+		for _, id := range frontier {
+			for _, nb := range adj[id] {
+				if _, ok := depth[nb]; !ok {
+					depth[nb] = depth[id] + 1
+					next = append(next, nb)
+				}
+			}
+		}
+		frontier = next
+	}
+	return depth
+}
+
+// markOutliers function    标记指标超过 平均值 + sigma × 标准差 的组件.
+func markOutliers(metrics []NodeMetrics, name string, sigma float64, value func(NodeMetrics) int) {
+	if len(metrics) == 0 {
+		return
+	}
+	var sum, sq float64
+	for _, m := range metrics {
+		v := float64(value(m))
+		sum += v
+		sq += v * v
+	}
+	n := float64(len(metrics))
+	mean := sum / n
+	threshold := mean + sigma*math.Sqrt(math.Max(sq/n-mean*mean, 0))
+
+	for i := range metrics {
+		if v := value(metrics[i]); v >= minOutlierValue && float64(v) > threshold {
+			metrics[i].Outliers = append(metrics[i].Outliers, name)
+		}
+	}
+}
+
+// WriteMetrics function    以表格形式写出组件指标，onlyOutliers 为 true 时只输出离群组件.
+func WriteMetrics(w io.Writer, metrics []NodeMetrics, onlyOutliers bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "COMPONENT\tSET\tFAN-IN\tFAN-OUT\tDEPTH\tOUTLIER")
+	for _, m := range metrics {
+		if onlyOutliers && len(m.Outliers) == 0 {
+			continue
+		}
+		depth := "-"
+		if m.Depth >= 0 {
+			depth = fmt.Sprint(m.Depth)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			m.Label, m.Set, m.FanIn, m.FanOut, depth, strings.Join(m.Outliers, ","))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+)
+
+func TestMetrics(t *testing.T) {
+	byName := make(map[string]NodeMetrics)
+	for _, m := range newTestGraph().Metrics(2) {
+		byName[m.Label] = m
+	}
+
+	tests := []struct {
+		label  string
+		fanIn  int
+		fanOut int
+		depth  int
+	}{
+		{"zoo.Zoo", 0, 2, 0},
+		{"zoo.Cat", 1, 1, 1},
+		{"food.Food", 2, 0, 1},
+		{"mocks.Mock", 0, 1, 0},
+	}
+	for _, tt := range tests {
+		m := byName[tt.label]
+		if m.FanIn != tt.fanIn || m.FanOut != tt.fanOut || m.Depth != tt.depth {
+			t.Errorf("%s = (in %d, out %d, depth %d), want (in %d, out %d, depth %d)",
+				tt.label, m.FanIn, m.FanOut, m.Depth, tt.fanIn, tt.fanOut, tt.depth)
+		}
+	}
+}
+
+func TestMetrics_Outliers(t *testing.T) {
+	// Hub 被 10 个组件依赖，其余组件之间没有依赖
+	elements := map[string]generator.Element{
+		"example.com/svc/Hub": {Name: "Hub", Pkg: "svc", Provides: []string{"example.com/svc.Hub"}},
+	}
+	for i := range 10 {
+		name := fmt.Sprintf("Svc%d", i)
+		elements["example.com/svc/"+name] = generator.Element{
+			Name: name, Pkg: "svc", Provides: []string{"example.com/svc." + name},
+			Deps: []string{"example.com/svc.Hub"},
+		}
+	}
+	metrics := Build(map[string]map[string]generator.Element{"svc": elements}).Metrics(2)
+
+	if metrics[0].Label != "svc.Hub" || !slices.Contains(metrics[0].Outliers, MetricFanIn) {
+		t.Errorf("svc.Hub 应该排在首位并被标记为扇入离群, got %+v", metrics[0])
+	}
+	for _, m := range metrics[1:] {
+		if len(m.Outliers) > 0 {
+			t.Errorf("%s 不应被标记为离群, got %v", m.Label, m.Outliers)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, metrics, true); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Errorf("只输出离群组件时应该只有表头和 svc.Hub 两行, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Validate method    校验依赖图，返回会导致 wire 失败的错误与仅供参考的警告
+// 错误包括组件之间的循环依赖，以及注入入口（@autowire.init）可达组件中没有提供者的依赖；
+// 其余组件缺少的依赖可能由手写的 Provider 提供，只作为警告.
+func (g *Graph) Validate() (errs []error, warnings []string) {
+	errs = g.CycleErrors()
+
+	var roots []string
+	for _, id := range parser.SortedKeys(g.Nodes) {
+		if g.Nodes[id].Element.InitWire {
+			roots = append(roots, id)
+		}
+	}
+	all := parser.NewSet(parser.SortedKeys(g.Nodes)...)
+	used := reachable(roots, all, 0, g.adjacency(false))
+
+	for _, id := range parser.SortedKeys(g.Missing) {
+		for _, t := range g.Missing[id] {
+			if !used.Contains(id) {
+				warnings = append(warnings, fmt.Sprintf("%s 依赖的 %s 没有 @autowire 提供者", g.describe(id), t))
+				continue
+			}
+			err := errors.NewMissingDepError(t)
+			err.Details = "  - 被 " + g.describe(id) + " 依赖"
+			errs = append(errs, err)
+		}
+	}
+	return errs, warnings
+}
+
+// CycleErrors method    为依赖图中的每个环返回一个错误，按 A → B → C → A 的顺序列出环上的组件及源码位置.
+func (g *Graph) CycleErrors() []error {
+	cycles := g.Cycles()
+	errs := make([]error, 0, len(cycles))
+	for _, cycle := range cycles {
+		errs = append(errs, errors.NewProviderCycleError(parser.Map(g.CyclePath(cycle), g.describe)))
+	}
+	return errs
+}
+
+// CyclePath method    返回强连通分量中从第一个节点出发再回到该节点的最短依赖链，首尾为同一节点
+// 如 [A, B, C] 返回 [A, B, C, A]，相邻节点之间均为依赖关系.
+func (g *Graph) CyclePath(cycle []string) []string {
+	if len(cycle) == 0 {
+		return nil
+	}
+	start := cycle[0]
+	members := parser.NewSet(cycle...)
+	adj := g.adjacency(false)
+
+	// 在分量内广度优先搜索回到起点的最短路径
+	prev := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, nb := range adj[id] {
+			if nb == start {
+				path := []string{start}
+				for at := id; at != start; at = prev[at] {
+					path = append(path, at)
+				}
+				slices.Reverse(path[1:])
+				return append(path, start)
+			}
+			if _, ok := prev[nb]; ok || !members.Contains(nb) {
+				continue
+			}
+			prev[nb] = id
+			queue = append(queue, nb)
+		}
+	}
+	return append(slices.Clone(cycle), start)
+}
+
+// Cycles method    返回依赖图中的所有环（强连通分量），每个环内的节点与环之间的顺序均稳定.
+func (g *Graph) Cycles() [][]string {
+	adj := g.adjacency(false)
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := parser.NewSet[string]()
+	var stack []string
+	var cycles [][]string
+
+	// Tarjan 强连通分量算法
+	var strongConnect func(id string)
+	strongConnect = func(id string) {
+		index[id] = len(index)
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack.Add(id)
+
+		for _, nb := range adj[id] {
+			if _, ok := index[nb]; !ok {
+				strongConnect(nb)
+				low[id] = min(low[id], low[nb])
+			} else if onStack.Contains(nb) {
+				low[id] = min(low[id], index[nb])
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			delete(onStack, top)
+			scc = append(scc, top)
+			if top == id {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			slices.Sort(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+
+	for _, id := range parser.SortedKeys(g.Nodes) {
+		if _, ok := index[id]; !ok {
+			strongConnect(id)
+		}
+	}
+	slices.SortFunc(cycles, func(a, b []string) int {
+		return slices.Compare(a, b)
+	})
+	return cycles
+}
+
+// describe method    返回节点的描述（包含源码位置）.
+func (g *Graph) describe(id string) string {
+	n := g.Nodes[id]
+	if !n.Element.Position.IsValid() {
+		return n.Label
+	}
+	return fmt.Sprintf("%s (%s)", n.Label, n.Element.Position)
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestValidate(t *testing.T) {
+	g := Build(map[string]map[string]generator.Element{
+		"app": {
+			"example.com/app/App": {Name: "App", Pkg: "app", InitWire: true, Provides: []string{"example.com/app.App"},
+				Deps: []string{"example.com/app.A", "example.com/app.Config"}},
+			"example.com/app/A": {Name: "A", Pkg: "app", Provides: []string{"example.com/app.A"},
+				Deps: []string{"example.com/app.B"}},
+			"example.com/app/B": {Name: "B", Pkg: "app", Provides: []string{"example.com/app.B"},
+				Deps: []string{"example.com/app.A"}},
+			"example.com/app/Tool": {Name: "Tool", Pkg: "app", Provides: []string{"example.com/app.Tool"},
+				Deps: []string{"string"}},
+		},
+	})
+
+	errs, warnings := g.Validate()
+	if len(errs) != 2 {
+		t.Fatalf("Validate() 应该返回循环依赖与缺少依赖两个错误, got %v", errs)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Tool 不被注入入口使用，缺少依赖只应作为警告, got %v", warnings)
+	}
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 || len(cycles[0]) != 2 || cycles[0][0] != "example.com/app/A" {
+		t.Errorf("Cycles() = %v, want [[A B]]", cycles)
+	}
+}
+
+func TestValidate_OK(t *testing.T) {
+	if errs, warnings := newTestGraph().Validate(); len(errs) != 0 || len(warnings) != 0 {
+		t.Errorf("Validate() = %v, %v, want no issues", errs, warnings)
+	}
+}
+
+func TestCyclePath(t *testing.T) {
+	elem := func(name string, deps ...string) generator.Element {
+		return generator.Element{Name: name, Pkg: "app", Provides: []string{"example.com/app." + name},
+			Deps: parser.Map(deps, func(d string) string { return "example.com/app." + d })}
+	}
+	g := Build(map[string]map[string]generator.Element{
+		"app": {
+			"example.com/app/A": elem("A", "B"),
+			"example.com/app/B": elem("B", "C"),
+			"example.com/app/C": elem("C", "A", "D"),
+			"example.com/app/D": elem("D"),
+		},
+	})
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 {
+		t.Fatalf("Cycles() = %v, want one cycle", cycles)
+	}
+	got := parser.Map(g.CyclePath(cycles[0]), func(id string) string { return g.Nodes[id].Element.Name })
+	if want := []string{"A", "B", "C", "A"}; !slices.Equal(got, want) {
+		t.Errorf("CyclePath() = %v, want %v", got, want)
+	}
+
+	errs := g.CycleErrors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "app.A\n  → app.B\n  → app.C\n  → app.A") {
+		t.Errorf("CycleErrors() = %v, want chain A → B → C → A", errs)
+	}
+}
@@ -0,0 +1,147 @@
+package iwanta
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"github.com/stoewer/go-strcase"
+)
+
+const (
+	// bundleInitTemplate 一次获取多个实例时的初始化文件模板
+	// 只生成返回聚合结构体的初始化函数，结构体的字段通过 wire.Struct 注入.
+	bundleInitTemplate = `// Code generated by go-autowire. DO NOT EDIT.
+
+//go:build wireinject
+// +build wireinject
+
+package {{ .Package }}
+{{ range .Injectors }}{{ if eq .Name "%[1]s" }}
+func InitializeIWantA%[2]s({{ join .Params ", " }}) (*%[1]s, func(), error) {
+	panic(wire.Build({{ .Build }}, wire.Struct(new(%[1]s), "*")))
+}
+{{ end }}{{ end }}`
+
+	// thisIsYourManyTemplate 一次获取多个实例时的辅助函数模板
+	// 调用初始化函数后将聚合结构体的字段依次赋值给各个参数.
+	thisIsYourManyTemplate = `
+func thisIsYour%s(%s,%s) (err error, cleanup func()) {
+	var res *%s
+	res, cleanup, err = %s
+	if err != nil {
+		return
+	}
+	%s
+	return
+}
+`
+)
+
+// wantMany method    一次获取多个实例：生成返回聚合结构体的初始化函数，字段依次为想要的类型，
+// 辅助函数 thisIsYour<Names> 将各字段赋值给调用参数，调用文件中的 IWantA 替换为一次调用.
+func (iw *iwantA) wantMany(targets []interface{}, searchDepDirs []string) {
+	if len(iw.wantInputIdents) != len(targets) {
+		panic(fmt.Sprintf("无法从调用代码中解析 IWantA 的参数：需要 %d 个 &变量 参数", len(targets)))
+	}
+
+	var (
+		genSuccess bool
+
+		genPath     = filepath.Dir(iw.callFile)
+		modeBase, _ = parser.GetModBase()
+		callPkgPath = parser.GetPkgPath(iw.callFile, modeBase)
+	)
+
+	// 各参数的类型写法与名称
+	typeVars := make([]string, len(targets))
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		typeVars[i] = typeVar(reflect.TypeOf(target).Elem(), callPkgPath)
+		names[i] = strcase.UpperCamelCase(typeVarName(typeVars[i]))
+	}
+	iw.thisIsYourFuncName = strings.Join(names, "")
+	bundle := "iWantA" + iw.thisIsYourFuncName
+	bundleFile := filepath.Join(genPath, strcase.SnakeCase(iw.thisIsYourFuncName)+"_iwanta_bundle.go")
+
+	// 清理临时文件
+	defer func() {
+		_ = os.Remove(bundleFile)
+		iw.cleanIWantATemp(iw.callFile)
+		if genSuccess {
+			// 生成成功后退出，让开发者重新运行
+			os.Exit(0)
+		}
+	}()
+
+	// 聚合结构体在运行 wire 时需要存在，生成完成后写入初始化文件
+	pkg, err := parser.GetPathGoPkgName(genPath)
+	if err != nil {
+		panic(err)
+	}
+	bundleDecl := bundleStruct(bundle, typeVars)
+	if err := parser.ImportAndWrite(bundleFile, []byte("package "+pkg+"\n"+bundleDecl)); err != nil {
+		panic(err)
+	}
+
+	tmpl, err := os.CreateTemp("", "iwanta-*.tmpl")
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = os.Remove(tmpl.Name()) }()
+	_, err = fmt.Fprintf(tmpl, bundleInitTemplate, bundle, iw.thisIsYourFuncName)
+	if cerr := tmpl.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	// 运行 autowire 生成代码
+	wireOpt := []config.Option{
+		config.WithSearchPaths(searchDepDirs...),
+		config.InitStruct(bundle),
+		config.WithInitTemplate(tmpl.Name()),
+		config.WithSumFile(false),
+	}
+	if err := runner.RunAutoWire(genPath, wireOpt...); err != nil {
+		panic(err)
+	}
+
+	// 生成初始化函数
+	params := make([]string, len(typeVars))
+	assigns := make([]string, len(typeVars))
+	for i, t := range typeVars {
+		params[i] = fmt.Sprintf("res%d *%s", i, t)
+		assigns[i] = fmt.Sprintf("*res%d = res.V%d", i, i)
+	}
+	args, err := iw.writeInitFile(typeVarName(iw.thisIsYourFuncName), func(initParams, call string) string {
+		return bundleDecl + fmt.Sprintf(thisIsYourManyTemplate, iw.thisIsYourFuncName, strings.Join(params, ", "),
+			initParams, bundle, call, strings.Join(assigns, "\n\t"))
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// 更新调用文件
+	if err = iw.updateCallFile(args); err != nil {
+		panic(err)
+	}
+	genSuccess = true
+}
+
+// bundleStruct function    返回聚合结构体的声明，字段依次为 V0、V1…….
+func bundleStruct(name string, typeVars []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\ntype %s struct {\n", name)
+	for i, t := range typeVars {
+		fmt.Fprintf(&b, "\tV%d %s\n", i, t)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
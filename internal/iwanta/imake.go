@@ -0,0 +1,180 @@
+package iwanta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/runner"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	// imakeRegisterTemplate 注册初始化函数的模板，追加到 wire 生成的初始化函数之后
+	// 测试编译时通过 init 注册，IMake 在同一个进程中调用初始化函数.
+	imakeRegisterTemplate = `
+func init() {
+	gutowire.Register(func() (res %s, cleanup func(), err error) {
+		res, cleanup, err = Initialize%s()
+		return
+	})
+}
+`
+
+	// imakePkgPath 生成文件中注册初始化函数的公共包.
+	imakePkgPath = "github.com/spelens-gud/gutowire/pkg/gutowire"
+)
+
+// ErrGenerated IMake 首次运行时生成了初始化文件，需要重新运行测试.
+var ErrGenerated = errors.New("已生成初始化文件，请重新运行测试")
+
+// makers 测试编译中注册的初始化函数，类型 -> func() (any, func(), error).
+var makers sync.Map
+
+// Register function    注册类型 T 的初始化函数，供 IMake 生成的 *_imake_test.go 在 init 中调用.
+func Register[T any](maker func() (T, func(), error)) {
+	makers.Store(reflect.TypeFor[T](), func() (any, func(), error) {
+		return maker()
+	})
+}
+
+// IMake function    不修改调用文件、不退出进程地获取类型 T 的实例，只能在测试文件中调用
+//
+// 工作原理：
+// 1. 查找测试编译中注册的 T 的初始化函数，找到时直接调用，返回同一个进程中创建的实例与其 cleanup
+// 2. 未注册时与 IWantA 相同，在调用文件所在目录运行 AutoWire 生成初始化函数，
+// 写入只参与测试编译的 <type>_imake_test.go（在 init 中注册初始化函数），返回 ErrGenerated
+// 3. 重新运行测试后进入第 1 步
+//
+// T 需要是包级声明的具名类型的指针或接口；初始化函数需要 @autowire.config 参数时返回错误，不会以零值代替.
+// 依赖变化后删除生成的 *_imake_test.go 即可重新生成.
+//
+// 使用示例：
+//
+//	zoo, cleanup, err := gutowire.IMake[*zoo.Zoo](ctx)
+//	if err != nil {
+//		t.Fatal(err) // 首次运行生成初始化文件，重新运行即可
+//	}
+//	defer cleanup()
+//
+// callFile: 调用 IMake 的文件
+// opts: 生成初始化函数时的扫描与生成选项，如搜索路径、排除目录等.
+func IMake[T any](ctx context.Context, callFile string, opts ...config.Option) (res T, cleanup func(), err error) {
+	if err := ctx.Err(); err != nil {
+		return res, nil, err
+	}
+	rType := reflect.TypeFor[T]()
+	if maker, ok := makers.Load(rType); ok {
+		v, cleanup, err := maker.(func() (any, func(), error))()
+		if err != nil {
+			return res, nil, err
+		}
+		res, _ = v.(T)
+		return res, cleanup, nil
+	}
+
+	named := rType
+	if named.Kind() == reflect.Pointer {
+		named = named.Elem()
+	} else if named.Kind() != reflect.Interface {
+		return res, nil, fmt.Errorf("IMake 返回初始化函数创建的实例，T 需要是具名类型的指针或接口，如 *zoo.Zoo: %s", rType)
+	}
+	if named.Name() == "" || named.PkgPath() == "" || strings.Contains(named.Name(), "[") {
+		return res, nil, fmt.Errorf("IMake 只支持包级声明的非泛型具名类型: %s", rType)
+	}
+	if !strings.HasSuffix(callFile, "_test.go") {
+		return res, nil, fmt.Errorf("IMake 生成的初始化函数只参与测试编译，需要在 _test.go 文件中调用: %s", callFile)
+	}
+	file, err := writeIMakeFile(ctx, rType, named, callFile, opts)
+	if err != nil {
+		return res, nil, err
+	}
+	return res, nil, fmt.Errorf("%w: %s", ErrGenerated, file)
+}
+
+// writeIMakeFile function    在调用文件所在目录生成 T 的初始化函数并写入 <type>_imake_test.go，返回文件路径
+// AutoWire 生成的 Set 文件与 wire_gen.go 是临时的，写入后删除.
+func writeIMakeFile(ctx context.Context, rType, named reflect.Type, callFile string,
+	opts []config.Option) (string, error) {
+	var (
+		genPath     = filepath.Dir(callFile)
+		modeBase, _ = parser.GetModBase()
+		callPkgPath = parser.GetPkgPath(callFile, modeBase)
+		iw          = &iwantA{callFile: callFile}
+	)
+	defer iw.cleanIWantATemp(callFile)
+
+	wireOpts := []config.Option{config.WithContext(ctx), config.WithSumFile(false)}
+	if modDir := parser.GetGoModDir(); modDir != "" {
+		wireOpts = append(wireOpts, config.WithSearchPaths(modDir))
+	}
+	wireOpts = append(wireOpts, opts...)
+
+	// 接口没有 wire.Struct 形式，改为初始化绑定到该接口的实现
+	typeVar := typeVar(named, callPkgPath)
+	if rType.Kind() == reflect.Pointer {
+		typeVar = "*" + typeVar
+	}
+	initType := typeVar
+	if rType.Kind() == reflect.Interface {
+		impl, err := interfaceImpl(rType, genPath, callPkgPath, wireOpts)
+		if err != nil {
+			return "", err
+		}
+		initType = impl
+	}
+	if err := runner.RunAutoWire(genPath, append(wireOpts, config.InitStruct(initType))...); err != nil {
+		return "", err
+	}
+
+	//nolint:gosec
+	initFileData, err := os.ReadFile(filepath.Join(genPath, "wire_gen.go"))
+	if err != nil {
+		return "", fmt.Errorf("读取 wire_gen.go 失败: %w", err)
+	}
+	ret := regexpInitMethod.FindStringSubmatch(string(initFileData))
+	if len(ret) < 3 {
+		return "", errors.New("invalid init file")
+	}
+	if params := strings.TrimSpace(ret[2]); params != "" {
+		return "", fmt.Errorf("Initialize%s 需要参数 %s（@autowire.config），IMake 不会以零值代替；"+
+			"请为配置提供构造函数或改用 IWantA", ret[1], params)
+	}
+
+	src := append(initFileData, fmt.Sprintf(imakeRegisterTemplate, typeVar, ret[1])...)
+	src, err = addImport(src, imakePkgPath)
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(genPath, typeVarName(strings.TrimPrefix(typeVar, "*"))+"_imake_test.go")
+	if err := parser.ImportAndWrite(file, src); err != nil {
+		return "", fmt.Errorf("写入初始化文件失败: %w", err)
+	}
+	return file, nil
+}
+
+// addImport function    为源码添加导入，goimports 无法从模块依赖中可靠地解析 gutowire 包.
+func addImport(src []byte, path string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("解析初始化文件失败: %w", err)
+	}
+	astutil.AddImport(fset, f, path)
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, fmt.Errorf("格式化初始化文件失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
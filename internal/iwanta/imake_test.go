@@ -0,0 +1,166 @@
+package iwanta
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type imakeService struct{ closed bool }
+
+type imakeValue struct{}
+
+func TestIMake_Registered(t *testing.T) {
+	svc := &imakeService{}
+	Register(func() (*imakeService, func(), error) {
+		return svc, func() { svc.closed = true }, nil
+	})
+
+	got, cleanup, err := IMake[*imakeService](t.Context(), "svc.go")
+	if err != nil {
+		t.Fatalf("IMake() error = %v", err)
+	}
+	if got != svc {
+		t.Fatalf("IMake() = %p, want registered instance %p", got, svc)
+	}
+	cleanup()
+	if !svc.closed {
+		t.Error("cleanup 应执行初始化函数返回的清理函数")
+	}
+}
+
+func TestIMake_Unsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		make    func() error
+		wantErr string
+	}{
+		{"非指针类型", func() error {
+			_, _, err := IMake[imakeValue](t.Context(), "svc_test.go")
+			return err
+		}, "具名类型的指针或接口"},
+		{"匿名类型", func() error {
+			_, _, err := IMake[*struct{}](t.Context(), "svc_test.go")
+			return err
+		}, "包级声明"},
+		{"非测试文件", func() error {
+			_, _, err := IMake[*imakeValue](t.Context(), "svc.go")
+			return err
+		}, "_test.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.make(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("IMake() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestIMake_Wire 在临时模块中两次运行调用 IMake 的测试：首次生成初始化文件，
+// 第二次在测试进程中获取实例，实例的未导出字段完整，cleanup 作用于同一个对象.
+func TestIMake_Wire(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short 模式跳过 wire 端到端测试")
+	}
+	if _, err := exec.LookPath("wire"); err != nil {
+		t.Skip("没有 wire 命令")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.25\n\nrequire (\n\tgithub.com/google/wire v0.7.0\n" +
+			"\tgithub.com/spelens-gud/gutowire v0.0.0\n)\n\nreplace github.com/spelens-gud/gutowire => " + root + "\n",
+		"go.sum": string(sum),
+		"zoo/zoo.go": `package zoo
+
+// @autowire(set=zoo)
+func NewKeeper() (*Keeper, func(), error) {
+	k := &Keeper{open: true}
+	return k, func() { k.open = false }, nil
+}
+
+type Keeper struct{ open bool }
+
+func (k *Keeper) Open() bool { return k.open }
+
+// @autowire(set=zoo)
+type Zoo struct {
+	Keeper *Keeper
+}
+`,
+		"app/app.go": "package app\n",
+		"app/app_test.go": `package app
+
+import (
+	"testing"
+
+	"example.com/app/zoo"
+	"github.com/spelens-gud/gutowire/pkg/gutowire"
+)
+
+func TestZoo(t *testing.T) {
+	z, cleanup, err := gutowire.IMake[*zoo.Zoo](t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !z.Keeper.Open() {
+		t.Fatal("keeper closed")
+	}
+	cleanup()
+	if z.Keeper.Open() {
+		t.Fatal("cleanup did not close the returned keeper")
+	}
+}
+`,
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goTest := func() ([]byte, error) {
+		cmd := exec.Command("go", "test", "-count=1", "./app")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		return cmd.CombinedOutput()
+	}
+	out, err := goTest()
+	if err == nil || !strings.Contains(string(out), ErrGenerated.Error()) {
+		t.Fatalf("首次运行应生成初始化文件: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app", "zoo_zoo_imake_test.go")); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "app"))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "autowire") || e.Name() == "wire_gen.go" {
+			t.Errorf("临时文件 %s 未删除", e.Name())
+		}
+	}
+	if out, err := goTest(); err != nil {
+		t.Fatalf("重新运行失败: %v\n%s", err, out)
+	}
+}
+
+func TestErrGenerated(t *testing.T) {
+	if err := errors.Unwrap(ErrGenerated); err != nil {
+		t.Errorf("ErrGenerated 不应包装其他错误: %v", err)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
 	"github.com/spelens-gud/gutowire/internal/parser"
 	"github.com/spelens-gud/gutowire/internal/runner"
 	"github.com/stoewer/go-strcase"
@@ -30,35 +32,30 @@ func thisIsYour%s(res *%s,%s) (err error, cleanup func()) {
 `
 )
 
-// regexpCall 用于匹配 IWantA 调用的正则表达式.
-var regexpCall = regexp.MustCompile(`gutowire\.IWantA\(&([a-zA-Z]+).*?\)`)
-
 // iwantA struct    功能的内部状态.
 type iwantA struct {
-	wantInputIdent     string   // 输入参数的标识符
+	wantInputIdents    []string // 输入参数，如 &zoo
 	thisIsYourFuncName string   // 生成的函数名称
-	callFileLines      []string // 调用文件的所有行
-	callLine           int      // 调用所在的行号
+	callFileData       []byte   // 调用文件的内容
+	callSite           callSite // IWantA 调用所在的语句
 	callFile           string   // 调用文件的路径
 }
 
-// initWantArgIdent method    初始化输入参数标识符
-// 从调用代码中提取变量名.
-func (iw *iwantA) initWantArgIdent() {
-	callLineStr := regexpCall.FindAllStringSubmatch(strings.TrimSpace(iw.callFileLines[iw.callLine-1]), -1)
-	for i := range callLineStr {
-		if len(callLineStr[i]) == 2 {
-			iw.wantInputIdent = callLineStr[i][1]
-			break
-		}
+// initWantArgIdent method    初始化输入参数
+// 解析调用文件，查找 IWantA 调用所在的语句，按顺序提取 &变量 参数.
+func (iw *iwantA) initWantArgIdent(callLine int) error {
+	site, err := findCallSite(iw.callFile, iw.callFileData, callLine)
+	if err != nil {
+		return err
 	}
+	iw.callSite = site
+	iw.wantInputIdents = site.args
 
 	// 重写调用代码，将 IWantA 替换为 thisIsYour
-	if iw.wantInputIdent == "" {
-		iw.wantInputIdent = "nil"
-	} else {
-		iw.wantInputIdent = "&" + iw.wantInputIdent
+	if len(iw.wantInputIdents) == 0 {
+		iw.wantInputIdents = []string{"nil"}
 	}
+	return nil
 }
 
 // IWantA function    魔法函数：快速获取任何类型的实例
@@ -77,9 +74,21 @@ func (iw *iwantA) initWantArgIdent() {
 //	gutowire.IWantA(&zoo)  // 第一次运行会生成代码并退出
 //	// 重新运行后，zoo 就会被正确初始化
 //
+//	gutowire.IWantA(&svc, &repo, &cfg)  // 一次获取多个实例，生成一个返回全部实例的初始化函数
+//
 // in: 指向想要类型的指针
-// searchDepDirs: 可选的依赖搜索目录.
-func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
+// more: 更多指向想要类型的指针，字符串参数为可选的依赖搜索目录.
+func IWantA(in interface{}, more ...interface{}) (_ struct{}) {
+	targets := []interface{}{in}
+	var searchDepDirs []string
+	for _, m := range more {
+		if dir, ok := m.(string); ok {
+			searchDepDirs = append(searchDepDirs, dir)
+		} else {
+			targets = append(targets, m)
+		}
+	}
+
 	// 如果未指定搜索目录，使用模块根目录
 	if len(searchDepDirs) == 0 {
 		modPath := parser.GetGoModDir()
@@ -101,13 +110,18 @@ func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
 	}
 
 	iw := &iwantA{
-		callFile:      callFile,
-		callLine:      callLine,
-		callFileLines: strings.Split(string(callFileData), "\n"),
+		callFile:     callFile,
+		callFileData: callFileData,
 	}
 
-	// 提取输入参数标识符
-	iw.initWantArgIdent()
+	// 提取输入参数
+	if err := iw.initWantArgIdent(callLine); err != nil {
+		panic(err)
+	}
+	if len(targets) > 1 {
+		iw.wantMany(targets, searchDepDirs)
+		return struct{}{}
+	}
 
 	// 生成 wire.go
 	var (
@@ -120,15 +134,8 @@ func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
 	)
 
 	// 确定类型的完整名称
-	if rType.PkgPath() == callPkgPath {
-		// 同一个包，只需要类型名
-		wantTypeVar = rType.Name()
-	} else {
-		// 不同包，需要完整路径
-		wantTypeVar = rType.String()
-	}
-
-	wantTypeName := strcase.SnakeCase(strings.ReplaceAll(strings.ReplaceAll(wantTypeVar, "_", ""), ".", "_"))
+	wantTypeVar = typeVar(rType, callPkgPath)
+	wantTypeName := typeVarName(wantTypeVar)
 	genPath := filepath.Dir(callFile)
 	wireOpt := make([]config.Option, 0)
 
@@ -143,13 +150,17 @@ func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
 		}
 	}()
 
-	// 配置搜索路径
-	wireOpt = append(wireOpt, parser.Map(searchDepDirs, func(s string) config.Option {
-		return config.WithSearchPath(s)
-	})...)
+	// 配置搜索路径；生成的文件是临时的，不记录生成锁
+	wireOpt = append(wireOpt, config.WithSearchPaths(searchDepDirs...), config.WithSumFile(false))
 
-	// 指定要初始化的类型
-	wireOpt = append(wireOpt, config.InitStruct(strings.TrimPrefix(wantTypeVar, "*")))
+	// 指定要初始化的类型；接口没有 wire.Struct 形式，改为初始化绑定到该接口的实现
+	initType := strings.TrimPrefix(wantTypeVar, "*")
+	if rType.Kind() == reflect.Interface {
+		if initType, err = interfaceImpl(rType, genPath, callPkgPath, wireOpt); err != nil {
+			panic(err)
+		}
+	}
+	wireOpt = append(wireOpt, config.InitStruct(initType))
 
 	// 运行 autowire 生成代码
 	if err := runner.RunAutoWire(genPath, wireOpt...); err != nil {
@@ -157,7 +168,9 @@ func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
 	}
 
 	// 生成初始化函数
-	args, err := iw.writeInitFile(wantTypeVar, wantTypeName)
+	args, err := iw.writeInitFile(wantTypeName, func(params, call string) string {
+		return fmt.Sprintf(thisIsYourTemplate, iw.thisIsYourFuncName, wantTypeVar, params, call)
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -171,31 +184,52 @@ func IWantA(in interface{}, searchDepDirs ...string) (_ struct{}) {
 	return struct{}{}
 }
 
-// updateCallFile method    更新调用文件，将 IWantA 替换为生成的函数.
-func (iw *iwantA) updateCallFile(configArgs []string) (err error) {
-	callLine := strings.TrimSpace(iw.callFileLines[iw.callLine-1])
-	callArgs := strings.Join(append([]string{iw.wantInputIdent}, configArgs...), ",")
-	assignStr := fmt.Sprintf("_, _ = thisIsYour%s(%s)", iw.thisIsYourFuncName, callArgs)
+// typeVar function    返回调用文件中引用类型的写法：同一个包只需要类型名，不同包需要带包名.
+func typeVar(rType reflect.Type, callPkgPath string) string {
+	if rType.PkgPath() == callPkgPath {
+		return rType.Name()
+	}
+	return rType.String()
+}
 
-	// 如果原来是 var 声明，保留 var 关键字
-	if strings.HasPrefix(callLine, "var ") {
-		assignStr = "var " + assignStr
+// typeVarName function    返回类型写法对应的蛇形名称，如 zoo.Zoo 返回 zoo_zoo.
+func typeVarName(typeVar string) string {
+	return strcase.SnakeCase(strings.ReplaceAll(strings.ReplaceAll(typeVar, "_", ""), ".", "_"))
+}
+
+// interfaceImpl function    查找绑定到接口的带注解实现，返回初始化函数使用的实现类型
+// 没有或存在多个实现时返回错误.
+func interfaceImpl(iface reflect.Type, genPath, callPkgPath string, opts []config.Option) (string, error) {
+	sc, err := runner.Scan(genPath, opts...)
+	if err != nil {
+		return "", err
+	}
+	id := iface.PkgPath() + "." + iface.Name()
+	impls := sc.Implementations(id)
+	switch len(impls) {
+	case 0:
+		return "", fmt.Errorf("未找到绑定到接口 %s 的 @autowire 实现", id)
+	case 1:
+		return generator.InjectorType(impls[0], impls[0].PkgPath == callPkgPath), nil
 	}
+	names := parser.Map(impls, func(e generator.Element) string { return e.PkgPath + "." + e.Name })
+	return "", fmt.Errorf("接口 %s 有多个 @autowire 实现，无法确定使用哪一个: %s", id, strings.Join(names, ", "))
+}
 
-	// 注释掉原来的 IWantA 调用
-	iw.callFileLines[iw.callLine-1] = "// " + callLine
-	// 插入新的调用代码
-	iw.callFileLines = append(iw.callFileLines[:iw.callLine],
-		append([]string{assignStr}, iw.callFileLines[iw.callLine:]...)...)
-	return parser.ImportAndWrite(iw.callFile, []byte(strings.Join(iw.callFileLines, "\n")))
+// updateCallFile method    更新调用文件：注释掉 IWantA 调用所在的语句，在其后插入生成函数的调用.
+func (iw *iwantA) updateCallFile(configArgs []string) (err error) {
+	callArgs := strings.Join(append(slices.Clone(iw.wantInputIdents), configArgs...), ", ")
+	assignStr := fmt.Sprintf("_, _ = thisIsYour%s(%s)", iw.thisIsYourFuncName, callArgs)
+	return parser.ImportAndWrite(iw.callFile, rewriteCallSite(iw.callFileData, iw.callSite, assignStr))
 }
 
 // regexpInitMethod 用于匹配 Initialize 函数的正则表达式.
 var regexpInitMethod = regexp.MustCompile(`Initialize(.+?)\((.*?)\)`)
 
 // writeInitFile method    生成初始化辅助文件
-// 读取 wire_gen.go，提取 Initialize 函数，生成 thisIsYour 包装函数.
-func (iw *iwantA) writeInitFile(wantVar, name string) (args []string, err error) {
+// 读取 wire_gen.go，提取 Initialize 函数，通过 wrapper 生成 thisIsYour 包装函数
+// wrapper 的参数为 Initialize 函数的参数声明与调用表达式.
+func (iw *iwantA) writeInitFile(name string, wrapper func(params, call string) string) (args []string, err error) {
 	genPath := filepath.Dir(iw.callFile)
 	//nolint:gosec
 	initFileData, err := os.ReadFile(filepath.Join(genPath, "wire_gen.go"))
@@ -235,7 +269,7 @@ func (iw *iwantA) writeInitFile(wantVar, name string) (args []string, err error)
 	filename += ".go"
 
 	// 生成 thisIsYour 函数
-	initFileData = append(initFileData, fmt.Sprintf(thisIsYourTemplate, iw.thisIsYourFuncName, wantVar, ret[2], call)...)
+	initFileData = append(initFileData, wrapper(ret[2], call)...)
 	initFileName := filepath.Join(genPath, filename)
 	if err = parser.ImportAndWrite(initFileName, initFileData); err != nil {
 		return nil, fmt.Errorf("写入初始化文件失败: %w", err)
@@ -0,0 +1,107 @@
+package iwanta
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// callSite struct    调用文件中 IWantA 调用所在的语句.
+type callSite struct {
+	start, end int      // 语句在文件中的字节偏移
+	decl       bool     // 语句是 var 声明（包级或函数内），替换后的语句同样使用 var
+	args       []string // 调用中的 &变量 参数，按顺序排列
+}
+
+// findCallSite function    解析调用文件，查找覆盖第 line 行的 IWantA 调用及其所在的语句
+// 调用可以跨越多行、位于闭包中，包可以使用任意别名导入.
+func findCallSite(filename string, src []byte, line int) (callSite, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, filename, src, goparser.ParseComments)
+	if err != nil {
+		return callSite{}, fmt.Errorf("解析调用文件失败: %w", err)
+	}
+
+	var call *ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && call == nil && isIWantA(c.Fun) &&
+			fset.Position(c.Pos()).Line <= line && line <= fset.Position(c.End()).Line {
+			call = c
+		}
+		return call == nil
+	})
+	if call == nil {
+		return callSite{}, fmt.Errorf("%s:%d 未找到 IWantA 调用", filename, line)
+	}
+
+	site := callSite{}
+	for _, arg := range call.Args {
+		if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			site.args = append(site.args, string(src[fset.Position(u.Pos()).Offset:fset.Position(u.End()).Offset]))
+		}
+	}
+
+	// 由内向外查找调用所在的语句，闭包中的调用使用闭包内的语句
+	path, _ := astutil.PathEnclosingInterval(f, call.Pos(), call.End())
+	var spec *ast.ValueSpec
+	for _, n := range path {
+		var stmt ast.Node
+		switch n := n.(type) {
+		case *ast.ExprStmt, *ast.AssignStmt:
+			stmt = n
+		case *ast.ValueSpec:
+			spec = n
+		case *ast.GenDecl:
+			// 带括号的 var 声明只替换调用所在的一项，其余声明保持不变
+			stmt, site.decl = n, true
+			if n.Lparen.IsValid() && spec != nil {
+				stmt, site.decl = spec, false
+			}
+		}
+		if stmt != nil {
+			site.start, site.end = fset.Position(stmt.Pos()).Offset, fset.Position(stmt.End()).Offset
+			return site, nil
+		}
+	}
+	return callSite{}, fmt.Errorf("%s:%d IWantA 调用需要作为单独的语句或 var 声明", filename, line)
+}
+
+// isIWantA function    判断调用的函数是否为 IWantA（pkg.IWantA 或点导入的 IWantA）.
+func isIWantA(fun ast.Expr) bool {
+	switch f := fun.(type) {
+	case *ast.SelectorExpr:
+		_, ok := f.X.(*ast.Ident)
+		return ok && f.Sel.Name == "IWantA"
+	case *ast.Ident:
+		return f.Name == "IWantA"
+	}
+	return false
+}
+
+// rewriteCallSite function    注释掉 IWantA 调用所在的语句，在其后插入 stmt
+// 语句的每一行单独注释，保留相对缩进；调用与其他代码在同一行时拆分为多行，之后由 goimports 重新格式化.
+func rewriteCallSite(src []byte, site callSite, stmt string) []byte {
+	if site.decl {
+		stmt = "var " + stmt
+	}
+	lineStart := bytes.LastIndexByte(src[:site.start], '\n') + 1
+	indent := src[lineStart:site.start]
+	indent = indent[:len(indent)-len(bytes.TrimLeft(indent, " \t"))]
+
+	var b bytes.Buffer
+	b.Write(src[:site.start])
+	for i, line := range strings.Split(string(src[site.start:site.end]), "\n") {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("// " + strings.TrimPrefix(line, string(indent)))
+	}
+	b.WriteString("\n" + stmt)
+	b.Write(src[site.end:])
+	return b.Bytes()
+}
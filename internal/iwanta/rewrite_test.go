@@ -0,0 +1,76 @@
+package iwanta
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestRewriteCallSite(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		args []string
+		want string
+	}{
+		{
+			name: "别名导入与多行调用",
+			src: "package app\n\nimport gw \"github.com/spelens-gud/gutowire\"\n\nfunc main() {\n" +
+				"\tvar zoo Zoo\n\tgw.IWantA(\n\t\t&zoo,\n\t\t\"./internal\",\n\t)\n\t_ = zoo\n}\n",
+			line: 7,
+			args: []string{"&zoo"},
+			want: "\t// gw.IWantA(\n\t// \t&zoo,\n\t// \t\"./internal\",\n\t// )\n" +
+				"\t_, _ = thisIsYourZoo(&zoo)\n\t_ = zoo\n",
+		},
+		{
+			name: "闭包中的调用",
+			src: "package app\n\nfunc main() {\n\tvar zoo Zoo\n" +
+				"\tfunc() { gutowire.IWantA(&zoo) }()\n}\n",
+			line: 5,
+			args: []string{"&zoo"},
+			want: "\tfunc() { // gutowire.IWantA(&zoo)\n\t\t_, _ = thisIsYourZoo(&zoo)\n\t}()\n",
+		},
+		{
+			name: "包级 var 声明",
+			src:  "package app\n\nvar zoo Zoo\n\nvar _ = gutowire.IWantA(&zoo)\n",
+			line: 5,
+			args: []string{"&zoo"},
+			want: "// var _ = gutowire.IWantA(&zoo)\nvar _, _ = thisIsYourZoo(&zoo)\n",
+		},
+		{
+			name: "带括号的 var 声明只替换一项",
+			src:  "package app\n\nvar zoo Zoo\n\nvar (\n\t_ = gutowire.IWantA(&zoo)\n\tx = 1\n)\n",
+			line: 6,
+			args: []string{"&zoo"},
+			want: "var (\n\t// _ = gutowire.IWantA(&zoo)\n\t_, _ = thisIsYourZoo(&zoo)\n\tx    = 1\n)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, err := findCallSite("app.go", []byte(tt.src), tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(site.args, tt.args) {
+				t.Errorf("args = %v, want %v", site.args, tt.args)
+			}
+			out, err := parser.ProcessImports(rewriteCallSite([]byte(tt.src), site, "_, _ = thisIsYourZoo(&zoo)"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("rewritten =\n%s\nwant contains:\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCallSite_NotFound(t *testing.T) {
+	src := "package app\n\nfunc main() {\n\tgutowire.IWantA(&zoo)\n}\n"
+	if _, err := findCallSite("app.go", []byte(src), 3); err == nil {
+		t.Error("findCallSite() 应在调用行不匹配时返回错误")
+	}
+}
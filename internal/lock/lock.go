@@ -0,0 +1,114 @@
+// Package lock 提供基于锁文件的进程间互斥，
+// 防止多个 gutowire 进程（如 IDE 保存钩子与手动执行）同时写入同一生成目录。
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	gerrors "github.com/spelens-gud/gutowire/internal/errors"
+)
+
+const (
+	// FileName 锁文件名称，位于生成目录下.
+	FileName = ".gutowire.lock"
+	// DefaultTimeout 默认等待锁的超时时间.
+	DefaultTimeout = time.Minute
+	// staleAfter 锁文件超过该时间未更新视为持有进程已异常退出.
+	staleAfter = 10 * time.Minute
+	// pollInterval 等待锁时的轮询间隔.
+	pollInterval = 100 * time.Millisecond
+)
+
+// Lock struct    已获取的目录锁.
+type Lock struct {
+	path string
+}
+
+// Acquire function    获取指定目录的锁，锁被占用时等待直到超时或 ctx 被取消
+//
+// ctx: 取消时停止等待并返回 ctx 的错误
+// dir: 需要加锁的目录（生成目录）
+// timeout: 最长等待时间，<= 0 时使用 DefaultTimeout
+func Acquire(ctx context.Context, dir string, timeout time.Duration) (*Lock, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+
+	lockPath := filepath.Join(dir, FileName)
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryCreate(lockPath)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &Lock{path: lockPath}, nil
+		}
+
+		// 清理异常退出进程遗留的过期锁
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleAfter {
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, newTimeoutError(lockPath, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Release method    释放锁.
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除锁文件 %s 失败: %w", l.path, err)
+	}
+	return nil
+}
+
+// tryCreate function    尝试以独占方式创建锁文件，已存在时返回 false.
+func tryCreate(lockPath string) (bool, error) {
+	//nolint:gosec
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("创建锁文件 %s 失败: %w", lockPath, err)
+	}
+	// 写入持有进程 PID 便于排查
+	_, _ = f.WriteString(strconv.Itoa(os.Getpid()))
+	return true, f.Close()
+}
+
+// newTimeoutError function    创建等待锁超时的友好错误.
+func newTimeoutError(lockPath string, timeout time.Duration) *gerrors.FriendlyError {
+	details := ""
+	//nolint:gosec
+	if pid, err := os.ReadFile(lockPath); err == nil {
+		details = "持有锁的进程 PID: " + string(pid)
+	}
+	return &gerrors.FriendlyError{
+		Type:    gerrors.ErrorTypeUnknown,
+		Message: fmt.Sprintf("等待生成目录锁超时 (%s)", timeout),
+		Details: details,
+		Suggestions: []string{
+			"确认没有其他 gutowire 进程正在生成同一目录",
+			"如果确认没有其他进程，手动删除锁文件: " + lockPath,
+			"通过 --lock-timeout 或配置 lock_timeout 延长等待时间",
+		},
+	}
+}
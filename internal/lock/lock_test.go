@@ -0,0 +1,63 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "wire")
+
+	l, err := Acquire(context.Background(), dir, time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("锁文件应该存在: %v", err)
+	}
+
+	// 锁被占用时应该超时
+	if _, err := Acquire(context.Background(), dir, 200*time.Millisecond); err == nil {
+		t.Fatal("Acquire() 应该在锁被占用时超时")
+	}
+
+	// ctx 被取消时停止等待
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Acquire(ctx, dir, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire() error = %v, want context.Canceled", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	// 释放后可以再次获取
+	l2, err := Acquire(context.Background(), dir, time.Second)
+	if err != nil {
+		t.Fatalf("释放后 Acquire() error = %v", err)
+	}
+	_ = l2.Release()
+}
+
+func TestAcquire_StaleLock(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, FileName)
+	if err := os.WriteFile(lockPath, []byte("1"), 0644); err != nil {
+		t.Fatalf("创建锁文件失败: %v", err)
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("修改锁文件时间失败: %v", err)
+	}
+
+	l, err := Acquire(context.Background(), dir, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("过期锁应该被清理, Acquire() error = %v", err)
+	}
+	_ = l.Release()
+}
@@ -0,0 +1,238 @@
+// Package logger 提供 gutowire 使用的 slog 日志处理器。
+// 输出格式与早期版本保持一致（带 [gutowire] 前缀、无时间戳），
+// 且不会修改标准库 log 包的全局状态，便于嵌入到其他程序中使用。
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prefix 日志前缀.
+const Prefix = "[gutowire] "
+
+// EventKey 事件类型的属性键
+// 关键日志携带该属性，便于 JSON 输出的消费方按事件类型处理；文本格式中不输出.
+const EventKey = "event"
+
+// 事件类型.
+const (
+	EventElement       = "element"        // 收集到组件
+	EventFileWritten   = "file_written"   // 写入生成文件
+	EventFileUnchanged = "file_unchanged" // 生成文件内容未变化，跳过写入
+	EventFileDiff      = "file_diff"      // 重新生成会修改的文件及其 unified diff（--diff）
+	EventWarning       = "warning"        // 校验警告
+	EventError         = "error"          // 错误
+	EventResult        = "result"         // 命令执行结果
+	EventSummary       = "summary"        // 生成汇总
+	EventDoctor        = "doctor"         // 环境检查项
+)
+
+// Default function    返回默认日志器：输出到标准输出，级别为 Info.
+func Default() *slog.Logger {
+	return New(os.Stdout, slog.LevelInfo)
+}
+
+// NewJSON function    创建输出 JSON 行的日志器，每条日志为一个 JSON 对象.
+func NewJSON(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Discard function    返回丢弃所有输出的日志器.
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// New function    创建使用 gutowire 文本格式的日志器.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(&handler{mu: &sync.Mutex{}, w: w, level: level})
+}
+
+// handler struct    gutowire 文本格式的 slog.Handler 实现.
+type handler struct {
+	mu     *sync.Mutex // 保护并发写入（由派生 handler 共享）
+	w      io.Writer   // 输出目标
+	level  slog.Leveler
+	attrs  []slog.Attr // 通过 WithAttrs 预置的属性
+	prefix string      // 通过 WithGroup 设置的属性键前缀
+}
+
+// Enabled method    判断日志级别是否启用.
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle method    格式化并输出一条日志.
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(Prefix)
+	switch {
+	case r.Level >= slog.LevelError:
+		sb.WriteString("[error] ")
+	case r.Level >= slog.LevelWarn:
+		sb.WriteString("[warn] ")
+	case r.Level < slog.LevelInfo:
+		sb.WriteString("[debug] ")
+	}
+	sb.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writeAttr(&sb, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&sb, h.prefix, a)
+		return true
+	})
+	sb.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+// WithAttrs method    返回附加了属性的 handler.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.attrs = append(make([]slog.Attr, 0, len(h.attrs)+len(attrs)), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		nh.attrs = append(nh.attrs, a)
+	}
+	return &nh
+}
+
+// WithGroup method    返回带属性分组的 handler.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.prefix = h.prefix + name + "."
+	return &nh
+}
+
+// writeAttr function    以 key=value 形式写入单个属性.
+func writeAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) || (prefix == "" && a.Key == EventKey) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeAttr(sb, prefix+a.Key+".", ga)
+		}
+		return
+	}
+	v := fmt.Sprint(a.Value.Any())
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		v = strconv.Quote(v)
+	}
+	sb.WriteString(" " + prefix + a.Key + "=" + v)
+}
+
+// Recorder struct    记录经过日志器输出的警告，严格模式下用于将警告视为错误.
+type Recorder struct {
+	mu       sync.Mutex
+	warnings []string // 按输出顺序排列的警告，格式与文本日志相同（不含前缀）
+}
+
+// Record function    返回包装 l 的日志器，经过它输出的 Warn 级别日志同时记录到返回的 Recorder
+// 即使 l 的级别高于 Warn（如 --log-level=error）也会记录.
+func Record(l *slog.Logger) (*slog.Logger, *Recorder) {
+	r := &Recorder{}
+	return slog.New(&recordHandler{next: l.Handler(), r: r}), r
+}
+
+// Len method    返回记录的警告数量，r 为 nil 时返回 0.
+func (r *Recorder) Len() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.warnings)
+}
+
+// Since method    返回第 n 条之后记录的警告，n 通常为之前调用 Len 的结果.
+func (r *Recorder) Since(n int) []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n >= len(r.warnings) {
+		return nil
+	}
+	return slices.Clone(r.warnings[n:])
+}
+
+// recordHandler struct    记录警告并转发给原 handler 的 slog.Handler 实现.
+type recordHandler struct {
+	next   slog.Handler
+	r      *Recorder
+	attrs  []slog.Attr // 通过 WithAttrs 预置的属性，用于格式化记录的警告
+	prefix string      // 通过 WithGroup 设置的属性键前缀
+}
+
+// Enabled method    Warn 级别总是启用以便记录，其余级别由原 handler 决定.
+func (h *recordHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return isWarn(l) || h.next.Enabled(ctx, l)
+}
+
+// Handle method    记录警告，并在原 handler 启用该级别时转发.
+func (h *recordHandler) Handle(ctx context.Context, r slog.Record) error {
+	if isWarn(r.Level) {
+		var sb strings.Builder
+		sb.WriteString(r.Message)
+		for _, a := range h.attrs {
+			writeAttr(&sb, "", a)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			writeAttr(&sb, h.prefix, a)
+			return true
+		})
+		h.r.mu.Lock()
+		h.r.warnings = append(h.r.warnings, sb.String())
+		h.r.mu.Unlock()
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs method    返回附加了属性的 handler.
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.next = h.next.WithAttrs(attrs)
+	nh.attrs = append(make([]slog.Attr, 0, len(h.attrs)+len(attrs)), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		nh.attrs = append(nh.attrs, a)
+	}
+	return &nh
+}
+
+// WithGroup method    返回带属性分组的 handler.
+func (h *recordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.next = h.next.WithGroup(name)
+	nh.prefix = h.prefix + name + "."
+	return &nh
+}
+
+// isWarn function    判断日志级别是否为警告（不含错误）.
+func isWarn(l slog.Level) bool {
+	return l >= slog.LevelWarn && l < slog.LevelError
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "普通信息",
+			log:  func(l *slog.Logger) { l.Info("扫描完成") },
+			want: "[gutowire] 扫描完成\n",
+		},
+		{
+			name: "警告带属性",
+			log:  func(l *slog.Logger) { l.Warn("删除文件失败", "file", "a.go", "error", "no such file") },
+			want: "[gutowire] [warn] 删除文件失败 file=a.go error=\"no such file\"\n",
+		},
+		{
+			name: "分组属性",
+			log:  func(l *slog.Logger) { l.WithGroup("wire").With("n", 1).Info("完成", "ok", true) },
+			want: "[gutowire] 完成 wire.n=1 wire.ok=true\n",
+		},
+		{
+			name: "事件属性不输出",
+			log:  func(l *slog.Logger) { l.Info("收集到 wire 对象", EventKey, EventElement, "set", "zooSet") },
+			want: "[gutowire] 收集到 wire 对象 set=zooSet\n",
+		},
+		{
+			name: "低于级别不输出",
+			log:  func(l *slog.Logger) { l.Debug("调试信息") },
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(New(&buf, slog.LevelInfo))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("输出 = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	var buf bytes.Buffer
+	l, r := Record(New(&buf, slog.LevelError))
+	l.Info("扫描完成")
+	l.With("pkg", "zoo").Warn("删除文件失败", "file", "a.go")
+	mark := r.Len()
+	l.WithGroup("wire").Warn("跳过", "n", 1)
+	l.Error("失败")
+
+	if got := r.Since(0); len(got) != 2 || got[0] != "删除文件失败 pkg=zoo file=a.go" || got[1] != "跳过 wire.n=1" {
+		t.Errorf("Since(0) = %q", got)
+	}
+	if got := r.Since(mark); len(got) != 1 {
+		t.Errorf("Since(%d) = %q", mark, got)
+	}
+	// 低于原日志器级别的警告只记录，不输出
+	if got := buf.String(); got != "[gutowire] [error] 失败\n" {
+		t.Errorf("输出 = %q", got)
+	}
+	var nilRecorder *Recorder
+	if nilRecorder.Len() != 0 || nilRecorder.Since(0) != nil {
+		t.Error("nil Recorder 应视为没有警告")
+	}
+}
@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// diffContext diff 中每处修改前后保留的上下文行数.
+const diffContext = 3
+
+// Files method    返回需要修改的源文件，按路径排序.
+func (p *Plan) Files() []string {
+	var files []string
+	for _, ins := range p.Insertions {
+		if len(files) == 0 || files[len(files)-1] != ins.File {
+			files = append(files, ins.File)
+		}
+	}
+	return files
+}
+
+// Apply method    将注解写入源文件，保留文件原有的权限.
+func (p *Plan) Apply() error {
+	for _, file := range p.Files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("读取文件 %s 失败: %w", file, err)
+		}
+		lines, err := readLines(file)
+		if err != nil {
+			return err
+		}
+		//nolint:gosec
+		if err := os.WriteFile(file, []byte(strings.Join(p.patch(file, lines), "")), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("写入文件 %s 失败: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Diff method    以 unified diff 格式输出将要插入的注解，不修改任何文件.
+func (p *Plan) Diff(w io.Writer) error {
+	for _, file := range p.Files() {
+		lines, err := readLines(file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, p.fileDiff(file, lines)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inserted method    返回文件中每一行之前插入的注释行，键为从 0 开始的行下标.
+func (p *Plan) inserted(file string) map[int][]string {
+	result := make(map[int][]string)
+	for _, ins := range p.Insertions {
+		if ins.File == file {
+			result[ins.Line-1] = append(result[ins.Line-1], ins.Indent+ins.Text+"\n")
+		}
+	}
+	return result
+}
+
+// patch method    返回插入注解后的各行（保留换行符）.
+func (p *Plan) patch(file string, lines []string) []string {
+	inserted := p.inserted(file)
+	result := make([]string, 0, len(lines)+len(inserted))
+	for i, line := range lines {
+		result = append(result, inserted[i]...)
+		result = append(result, line)
+	}
+	return result
+}
+
+// fileDiff method    生成单个文件的 unified diff，相邻的修改合并为同一个 hunk.
+func (p *Plan) fileDiff(file string, lines []string) string {
+	inserted := p.inserted(file)
+	var b strings.Builder
+	if name := displayPath(file); filepath.IsAbs(name) {
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", name, name)
+	} else {
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	}
+
+	added := 0 // 已输出的 hunk 中插入的行数
+	for start := 0; start < len(lines); {
+		// 查找下一处插入，确定 hunk 的范围
+		first := start
+		for first < len(lines) && len(inserted[first]) == 0 {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		from := max(first-diffContext, start)
+		to := first
+		for i := first; i < len(lines) && i <= to+2*diffContext; i++ {
+			if len(inserted[i]) > 0 {
+				to = i
+			}
+		}
+		end := min(to+diffContext, len(lines))
+
+		n := 0
+		for i := from; i < end; i++ {
+			n += len(inserted[i])
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", from+1, end-from, from+added+1, end-from+n)
+		for i := from; i < end; i++ {
+			for _, ins := range inserted[i] {
+				b.WriteString("+" + ins)
+			}
+			b.WriteString(" " + strings.TrimSuffix(lines[i], "\n") + "\n")
+		}
+		added += n
+		start = end
+	}
+	return b.String()
+}
+
+// displayPath function    返回 diff 中显示的文件路径：当前目录下的文件使用相对路径，统一为 / 分隔
+// 其他文件保留绝对路径，diff 中不添加 a/、b/ 前缀.
+func displayPath(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, ok := parser.RelPath(wd, file); ok {
+			return rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// readLines function    读取文件的各行，保留换行符.
+func readLines(file string) ([]string, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件 %s 失败: %w", file, err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
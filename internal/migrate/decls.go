@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"bytes"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// packageDecls method    解析目录下的全部非测试 Go 文件，收集可以添加注解的顶层声明
+// 生成的文件不收集，结果按目录缓存.
+func (m *migrator) packageDecls(dir string) *packageDecls {
+	if pd, ok := m.packages[dir]; ok {
+		return pd
+	}
+	pd := &packageDecls{decls: make(map[string]*decl)}
+	m.packages[dir] = pd
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return pd
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !parser.CheckFileType(entry.Name()) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		//nolint:gosec
+		src, err := os.ReadFile(file)
+		if err != nil || generatedHeader.Match(src) {
+			continue
+		}
+		f, err := goparser.ParseFile(m.fset, file, src, goparser.ParseComments)
+		if err != nil {
+			continue
+		}
+		pd.name = f.Name.Name
+		m.collectDecls(pd, file, f, bytes.Split(src, []byte("\n")))
+	}
+	return pd
+}
+
+// collectDecls method    收集文件中的函数（不含方法）、类型与单个名称的包级变量声明
+// 单个声明在 func、type、var 关键字所在行之前插入注解，分组声明在声明名称所在行之前插入.
+func (m *migrator) collectDecls(pd *packageDecls, file string, f *ast.File, lines [][]byte) {
+	add := func(kind token.Token, name string, pos token.Pos, comments ...*ast.CommentGroup) *decl {
+		line := m.fset.Position(pos).Line
+		d := &decl{
+			kind:      kind,
+			name:      name,
+			file:      file,
+			f:         f,
+			line:      line,
+			indent:    leadingSpace(lines[line-1]),
+			annotated: m.hasAnnotation(comments...),
+		}
+		pd.decls[name] = d
+		return d
+	}
+
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil {
+				continue
+			}
+			fd := add(token.FUNC, d.Name.Name, d.Pos(), d.Doc)
+			if d.Type.Results != nil && len(d.Type.Results.List) > 0 {
+				fd.result = d.Type.Results.List[0].Type
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				pos := spec.Pos()
+				if d.Lparen == token.NoPos {
+					pos = d.Pos()
+				}
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(token.TYPE, s.Name.Name, pos, d.Doc, s.Doc, s.Comment)
+				case *ast.ValueSpec:
+					if d.Tok != token.VAR || len(s.Names) != 1 {
+						continue
+					}
+					vd := add(token.VAR, s.Names[0].Name, pos, d.Doc, s.Doc, s.Comment)
+					vd.isSet = len(s.Values) == 1 && isNewSet(f, s.Values[0])
+				}
+			}
+		}
+	}
+}
+
+// hasAnnotation method    判断注释中是否已有注解标记.
+func (m *migrator) hasAnnotation(comments ...*ast.CommentGroup) bool {
+	for _, cg := range comments {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if strings.Contains(c.Text, m.tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNewSet function    判断表达式是否为 wire.NewSet(...) 调用.
+func isNewSet(f *ast.File, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "NewSet" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == wireImportPath {
+			return spec.Name == nil && x.Name == "wire" || spec.Name != nil && x.Name == spec.Name.Name
+		}
+	}
+	return false
+}
+
+// leadingSpace function    返回行首的空白字符.
+func leadingSpace(line []byte) string {
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
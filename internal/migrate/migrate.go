@@ -0,0 +1,579 @@
+// Package migrate 将手写的 wire.NewSet 提供者集合转换为 @autowire 注解。
+// 解析 wire.NewSet 中的每个提供者，找到其声明的类型、构造函数或变量，
+// 在声明前插入对应的注解，降低已有项目接入 gutowire 的成本。
+package migrate
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/stoewer/go-strcase"
+)
+
+// wireImportPath wire 包的导入路径.
+const wireImportPath = "github.com/google/wire"
+
+// Insertion struct    在源文件中插入的一行注解.
+type Insertion struct {
+	File   string // 源文件路径
+	Line   int    // 插入到该行之前，从 1 开始
+	Indent string // 与声明相同的缩进
+	Text   string // 注释内容，如 // @autowire(set=repo,Store)
+	Target string // 被注解的声明，如 repo.NewMySQL
+}
+
+// Skipped struct    无法自动转换的提供者.
+type Skipped struct {
+	Position token.Position // 提供者在 wire 文件中的位置
+	Expr     string         // 提供者表达式
+	Reason   string         // 原因
+}
+
+// Plan struct    迁移计划，Apply 写入源文件，Diff 只输出修改内容.
+type Plan struct {
+	Insertions []Insertion // 插入的注解，按文件与行号排序
+	Skipped    []Skipped   // 需要手动处理的提供者
+	Sets       []string    // 已转换的 Set 变量，如 repo.ProviderSet，确认生成结果后可以删除
+}
+
+// Analyze function    解析 wire 文件中的 wire.NewSet 声明并生成迁移计划
+// 提供者所在的包按工作区模块解析，第三方包中的提供者无法添加注解，记录在 Skipped 中.
+//
+// files: 包含 wire.NewSet 声明的 Go 文件
+// tag: 注解标记，为空时使用 @autowire
+func Analyze(files []string, tag string) (*Plan, error) {
+	m := newMigrator(tag, parser.PackageDir, func(dir string) string {
+		modBase, _ := parser.GetModBase()
+		return parser.GetPkgPath(filepath.Join(dir, "doc.go"), modBase)
+	})
+	return m.analyze(files)
+}
+
+// migrator struct    迁移过程的状态.
+type migrator struct {
+	tag    string                                 // 注解标记（不含注释前缀）
+	dirOf  func(importPath string) (string, bool) // 导入路径 -> 目录
+	pathOf func(dir string) string                // 目录 -> 导入路径
+
+	fset     *token.FileSet
+	packages map[string]*packageDecls // 目录 -> 包内的顶层声明
+	targets  []*target                // 按出现顺序记录的注解目标
+	plan     *Plan
+}
+
+// newMigrator function    创建迁移器，目录与导入路径的映射可以替换以便测试.
+func newMigrator(tag string, dirOf func(string) (string, bool), pathOf func(string) string) *migrator {
+	tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "//"))
+	if tag == "" {
+		tag = config.WireTag
+	}
+	return &migrator{
+		tag:      tag,
+		dirOf:    dirOf,
+		pathOf:   pathOf,
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*packageDecls),
+		plan:     &Plan{},
+	}
+}
+
+// packageDecls struct    包内的顶层声明.
+type packageDecls struct {
+	name  string           // 包名
+	decls map[string]*decl // 声明名称 -> 声明
+}
+
+// decl struct    可以添加注解的顶层声明.
+type decl struct {
+	kind      token.Token // token.FUNC、token.TYPE 或 token.VAR
+	name      string
+	file      string
+	f         *ast.File
+	line      int    // 插入注解的行号
+	indent    string // 声明行的缩进
+	annotated bool   // 是否已有注解
+	result    ast.Expr
+	isSet     bool // 变量的值为 wire.NewSet(...)
+}
+
+// target struct    待添加注解的声明及注解参数.
+type target struct {
+	decl    *decl
+	dir     string
+	pkgPath string
+	set     string
+	value   bool     // @autowire.value
+	ifaces  []string // 接口参数
+	impls   []string // 完整路径形式的接口（impl=）
+	fields  []string // wire.Struct 注入的字段，为空表示全部
+	byValue bool     // 按值绑定接口
+}
+
+// ref struct    wire 文件中引用的声明.
+type ref struct {
+	dir     string // 声明所在目录
+	pkgPath string // 声明所在包的导入路径
+	name    string
+}
+
+// wireFile struct    正在解析的 wire 文件.
+type wireFile struct {
+	f        *ast.File
+	dir      string
+	wireName string // wire 包在文件中的名称
+}
+
+// analyze method    解析全部 wire 文件，生成迁移计划.
+func (m *migrator) analyze(files []string) (*Plan, error) {
+	for _, file := range files {
+		if err := m.analyzeFile(file); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range m.targets {
+		m.plan.Insertions = append(m.plan.Insertions, Insertion{
+			File:   t.decl.file,
+			Line:   t.decl.line,
+			Indent: t.decl.indent,
+			Text:   m.annotation(t),
+			Target: parser.AppendPkg(m.packages[t.dir].name, t.decl.name),
+		})
+	}
+	slices.SortStableFunc(m.plan.Insertions, func(a, b Insertion) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	return m.plan, nil
+}
+
+// generatedHeader 匹配 Go 约定的生成文件标记，生成的文件（包括 gutowire 生成的 Set）不参与迁移.
+var generatedHeader = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// analyzeFile method    解析单个 wire 文件中的 wire.NewSet 变量.
+func (m *migrator) analyzeFile(file string) error {
+	//nolint:gosec
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("读取文件 %s 失败: %w", file, err)
+	}
+	if generatedHeader.Match(src) {
+		return nil
+	}
+	f, err := goparser.ParseFile(m.fset, file, src, goparser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("解析文件 %s 失败: %w", file, err)
+	}
+	wf := &wireFile{f: f, dir: filepath.Dir(file)}
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == wireImportPath {
+			wf.wireName = "wire"
+			if spec.Name != nil {
+				wf.wireName = spec.Name.Name
+			}
+		}
+	}
+	if wf.wireName == "" {
+		return nil
+	}
+
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i >= len(vs.Values) {
+					continue
+				}
+				if call, ok := wf.wireCall(vs.Values[i], "NewSet"); ok {
+					m.migrateSet(wf, name.Name, call)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateSet method    转换单个 wire.NewSet 中的提供者，接口绑定在全部提供者处理完后匹配.
+func (m *migrator) migrateSet(wf *wireFile, varName string, call *ast.CallExpr) {
+	set := setName(varName, wf.f.Name.Name)
+	m.plan.Sets = append(m.plan.Sets, parser.AppendPkg(wf.f.Name.Name, varName))
+
+	var provided []*target
+	var binds []*ast.CallExpr
+	for _, arg := range wf.setArgs(call) {
+		if bind, ok := wf.wireCall(arg, "Bind"); ok {
+			binds = append(binds, bind)
+		} else if t := m.provider(wf, set, arg); t != nil {
+			provided = append(provided, t)
+		}
+	}
+	for _, bind := range binds {
+		m.bind(wf, bind, provided)
+	}
+}
+
+// provider method    解析 wire.NewSet 中的单个提供者，无法转换时记录原因并返回 nil.
+func (m *migrator) provider(wf *wireFile, set string, arg ast.Expr) *target {
+	switch {
+	case isCall(wf, arg, "Struct"):
+		call := arg.(*ast.CallExpr)
+		r, _, ok := m.newExpr(wf, call.Args[0])
+		if !ok {
+			m.skip(arg, "无法解析结构体类型")
+			return nil
+		}
+		d := m.lookup(r, arg)
+		if d == nil {
+			return nil
+		}
+		if d.kind != token.TYPE {
+			m.skip(arg, "不是类型声明")
+			return nil
+		}
+		if ct := m.constructorOf(d); ct != "" {
+			m.skip(arg, fmt.Sprintf("同一包中存在构造函数 %s，注解会改用构造函数", ct))
+			return nil
+		}
+		t := m.addTarget(r, d, set, arg)
+		if t == nil {
+			return nil
+		}
+		for _, field := range call.Args[1:] {
+			if lit, ok := field.(*ast.BasicLit); ok {
+				if name, _ := strconv.Unquote(lit.Value); name != "*" {
+					t.fields = append(t.fields, name)
+				}
+			}
+		}
+		return t
+
+	case isCall(wf, arg, "Value"), isCall(wf, arg, "InterfaceValue"):
+		call := arg.(*ast.CallExpr)
+		value := call.Args[len(call.Args)-1]
+		r, ok := m.resolve(wf, value)
+		if !ok {
+			m.skip(arg, "只能转换包级变量，字面量等表达式需要先声明为变量")
+			return nil
+		}
+		d := m.lookup(r, arg)
+		if d == nil {
+			return nil
+		}
+		if d.kind != token.VAR || d.isSet {
+			m.skip(arg, "不是包级变量")
+			return nil
+		}
+		t := m.addTarget(r, d, set, arg)
+		if t == nil {
+			return nil
+		}
+		t.value = true
+		if len(call.Args) == 2 {
+			if itf, _, ok := m.newExpr(wf, call.Args[0]); ok {
+				m.addInterface(t, itf)
+			}
+		}
+		return t
+
+	case isCall(wf, arg, "FieldsOf"):
+		m.skip(arg, "wire.FieldsOf 没有对应的注解")
+		return nil
+	}
+
+	r, ok := m.resolve(wf, arg)
+	if !ok {
+		m.skip(arg, "不支持自动转换的提供者")
+		return nil
+	}
+	d := m.lookup(r, arg)
+	if d == nil {
+		return nil
+	}
+	switch {
+	case d.isSet:
+		// 引用的 Set 单独迁移，迁移后由汇总的 Sets 组合
+		return nil
+	case d.kind != token.FUNC:
+		m.skip(arg, "不是构造函数")
+		return nil
+	}
+	return m.addTarget(r, d, set, arg)
+}
+
+// bind method    将 wire.Bind 的接口添加到同一 Set 中提供实现类型的注解.
+func (m *migrator) bind(wf *wireFile, call *ast.CallExpr, provided []*target) {
+	if len(call.Args) != 2 {
+		return
+	}
+	itf, _, ok1 := m.newExpr(wf, call.Args[0])
+	impl, ptr, ok2 := m.newExpr(wf, call.Args[1])
+	if !ok1 || !ok2 {
+		m.skip(call, "无法解析绑定的类型")
+		return
+	}
+	for _, t := range provided {
+		if !m.provides(t, impl) {
+			continue
+		}
+		m.addInterface(t, itf)
+		if !ptr && t.decl.kind == token.TYPE {
+			t.byValue = true
+		}
+		return
+	}
+	m.skip(call, fmt.Sprintf("同一 Set 中没有提供 %s 的提供者", impl.name))
+}
+
+// provides method    判断注解目标是否提供指定类型：结构体为其自身，构造函数按第一个返回值判断.
+func (m *migrator) provides(t *target, typ ref) bool {
+	if t.decl.kind == token.TYPE {
+		return t.dir == typ.dir && t.decl.name == typ.name
+	}
+	if t.decl.kind != token.FUNC || t.decl.result == nil {
+		return false
+	}
+	expr := t.decl.result
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return t.dir == typ.dir && e.Name == typ.name
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok || e.Sel.Name != typ.name {
+			return false
+		}
+		p := importPathOf(t.decl.f, x.Name, m.packageName)
+		return p != "" && p == typ.pkgPath
+	}
+	return false
+}
+
+// addTarget method    记录注解目标，已有注解或已在其他 Set 中迁移的声明跳过.
+func (m *migrator) addTarget(r ref, d *decl, set string, expr ast.Expr) *target {
+	if d.annotated {
+		m.skip(expr, "声明已有注解")
+		return nil
+	}
+	for _, t := range m.targets {
+		if t.decl == d {
+			if t.set != set {
+				m.skip(expr, fmt.Sprintf("已迁移到 Set %s，同一组件只能属于一个 Set", t.set))
+			}
+			return nil
+		}
+	}
+	t := &target{decl: d, dir: r.dir, pkgPath: r.pkgPath, set: set}
+	m.targets = append(m.targets, t)
+	return t
+}
+
+// addInterface method    为注解目标添加接口参数
+// 与声明同包的接口直接写接口名，声明所在文件已导入接口所在包时写 包名.接口名，否则使用 impl= 完整路径.
+func (m *migrator) addInterface(t *target, itf ref) {
+	switch {
+	case itf.dir != "" && itf.dir == t.dir:
+		t.ifaces = append(t.ifaces, itf.name)
+		return
+	case itf.pkgPath == "":
+		return
+	}
+	for _, spec := range t.decl.f.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if p != itf.pkgPath {
+			continue
+		}
+		name := m.packageName(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			t.ifaces = append(t.ifaces, name+"."+itf.name)
+			return
+		}
+	}
+	t.impls = append(t.impls, itf.pkgPath+"."+itf.name)
+}
+
+// annotation method    返回注解目标的注释行.
+func (m *migrator) annotation(t *target) string {
+	tag := m.tag
+	if t.value {
+		tag += ".value"
+	}
+	args := append([]string{"set=" + t.set}, t.ifaces...)
+	if len(t.impls) > 0 {
+		args = append(args, "impl="+strings.Join(t.impls, "|"))
+	}
+	if len(t.fields) > 0 {
+		args = append(args, "fields="+strings.Join(t.fields, "|"))
+	}
+	if t.byValue {
+		args = append(args, "value")
+	}
+	prefix := "// "
+	if strings.HasPrefix(tag, "go:") {
+		// 指令形式的注释不带空格，如 //go:autowire(set=repo)
+		prefix = "//"
+	}
+	return prefix + tag + "(" + strings.Join(args, ",") + ")"
+}
+
+// skip method    记录无法自动转换的提供者.
+func (m *migrator) skip(expr ast.Expr, reason string) {
+	m.plan.Skipped = append(m.plan.Skipped, Skipped{
+		Position: m.fset.Position(expr.Pos()),
+		Expr:     types.ExprString(expr),
+		Reason:   reason,
+	})
+}
+
+// setName function    根据 Set 变量名确定 Set 名称，如 RepoSet 返回 repo
+// ProviderSet、Set 这类通用名称使用 wire 文件的包名.
+func setName(varName, pkgName string) string {
+	name := strings.TrimSuffix(varName, "Set")
+	if name == "" || strings.EqualFold(name, "provider") || strings.EqualFold(name, "providers") {
+		name = pkgName
+	}
+	return strcase.LowerCamelCase(name)
+}
+
+// wireCall method    判断表达式是否为 wire.<name>(...) 调用.
+func (wf *wireFile) wireCall(expr ast.Expr, name string) (*ast.CallExpr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return nil, false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return call, ok && x.Name == wf.wireName && len(call.Args) > 0
+}
+
+// setArgs method    返回 wire.NewSet 的全部参数，嵌套的 wire.NewSet 展开.
+func (wf *wireFile) setArgs(call *ast.CallExpr) []ast.Expr {
+	var args []ast.Expr
+	for _, arg := range call.Args {
+		if nested, ok := wf.wireCall(arg, "NewSet"); ok {
+			args = append(args, wf.setArgs(nested)...)
+			continue
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// isCall function    判断表达式是否为 wire.<name>(...) 调用.
+func isCall(wf *wireFile, expr ast.Expr, name string) bool {
+	_, ok := wf.wireCall(expr, name)
+	return ok
+}
+
+// newExpr method    解析 new(T) 或 new(*T) 表达式，返回类型引用与是否为指针.
+func (m *migrator) newExpr(wf *wireFile, expr ast.Expr) (ref, bool, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return ref{}, false, false
+	}
+	if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "new" {
+		return ref{}, false, false
+	}
+	typ, ptr := call.Args[0], false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = star.X, true
+	}
+	r, ok := m.resolve(wf, typ)
+	return r, ptr, ok
+}
+
+// resolve method    解析 wire 文件中引用的声明：标识符属于 wire 文件所在包，包名.名称 按导入解析.
+func (m *migrator) resolve(wf *wireFile, expr ast.Expr) (ref, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return ref{dir: wf.dir, pkgPath: m.pathOf(wf.dir), name: e.Name}, true
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return ref{}, false
+		}
+		p := importPathOf(wf.f, x.Name, m.packageName)
+		if p == "" {
+			return ref{}, false
+		}
+		dir, _ := m.dirOf(p)
+		return ref{dir: dir, pkgPath: p, name: e.Sel.Name}, true
+	}
+	return ref{}, false
+}
+
+// lookup method    查找引用的声明，不在工作区模块中或不存在时记录原因并返回 nil.
+func (m *migrator) lookup(r ref, expr ast.Expr) *decl {
+	if r.dir == "" {
+		m.skip(expr, "声明不在当前模块或工作区中，无法添加注解")
+		return nil
+	}
+	d := m.packageDecls(r.dir).decls[r.name]
+	if d == nil {
+		m.skip(expr, "未找到声明")
+	}
+	return d
+}
+
+// packageName method    返回导入路径对应的包名，包不在工作区模块中时使用路径最后一段.
+func (m *migrator) packageName(importPath string) string {
+	if dir, ok := m.dirOf(importPath); ok {
+		if name := m.packageDecls(dir).name; name != "" {
+			return name
+		}
+	}
+	return path.Base(importPath)
+}
+
+// importPathOf function    返回文件中包名对应的导入路径，未导入时返回空.
+func importPathOf(f *ast.File, name string, packageName func(string) string) string {
+	for _, spec := range f.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return p
+			}
+			continue
+		}
+		if packageName(p) == name {
+			return p
+		}
+	}
+	return ""
+}
+
+// constructorOf method    返回结构体声明所在包中的 New<Name> 或 Init<Name> 构造函数
+// 与生成时的规则一致：存在构造函数时注解使用构造函数而不是 wire.Struct.
+func (m *migrator) constructorOf(d *decl) string {
+	decls := m.packageDecls(filepath.Dir(d.file)).decls
+	for _, prefix := range []string{"Init", "New"} {
+		if ct, ok := decls[prefix+d.name]; ok && ct.kind == token.FUNC {
+			return prefix + d.name
+		}
+	}
+	return ""
+}
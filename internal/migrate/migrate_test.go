@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"repo/repo.go": "package repo\n\ntype Store interface{ Get() string }\n\n" +
+			"// MySQL 存储.\ntype MySQL struct{}\n\nfunc NewMySQL() *MySQL { return nil }\n\n" +
+			"type (\n\tCache struct{ Size int }\n\tMemory struct{}\n)\n\n" +
+			"// @autowire(set=repo)\ntype Annotated struct{}\n",
+		"svc/svc.go": "package svc\n\nimport \"io\"\n\ntype Service struct{}\n\n" +
+			"var Out io.Writer\n\nvar _ = io.EOF\n",
+		"app/wire.go": "//go:build wireinject\n\npackage app\n\nimport (\n\t\"io\"\n\n" +
+			"\t\"example.com/x/repo\"\n\tsvc \"example.com/x/svc\"\n\t\"github.com/google/wire\"\n)\n\n" +
+			"var RepoSet = wire.NewSet(\n\trepo.NewMySQL,\n\twire.Bind(new(repo.Store), new(*repo.MySQL)),\n" +
+			"\twire.Struct(new(repo.Cache), \"Size\"),\n\twire.Bind(new(repo.Store), new(repo.Memory)),\n" +
+			"\trepo.Annotated,\n)\n\n" +
+			"var ProviderSet = wire.NewSet(\n\tRepoSet,\n\twire.NewSet(wire.Struct(new(svc.Service), \"*\")),\n" +
+			"\twire.InterfaceValue(new(io.Writer), svc.Out),\n\twire.FieldsOf(new(*svc.Service)),\n)\n",
+	})
+	dirOf := func(p string) (string, bool) {
+		rel, ok := strings.CutPrefix(p, "example.com/x/")
+		return filepath.Join(root, rel), ok
+	}
+	pathOf := func(dir string) string {
+		return "example.com/x/" + filepath.Base(dir)
+	}
+
+	plan, err := newMigrator("", dirOf, pathOf).analyze([]string{filepath.Join(root, "app/wire.go")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, ins := range plan.Insertions {
+		got = append(got, ins.Target+" "+ins.Indent+ins.Text)
+	}
+	want := []string{
+		"repo.NewMySQL // @autowire(set=repo,Store)",
+		"repo.Cache \t// @autowire(set=repo,fields=Size)",
+		"svc.Service // @autowire(set=app)",
+		"svc.Out // @autowire.value(set=app,io.Writer)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("insertions =\n%v\nwant\n%v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	var reasons []string
+	for _, s := range plan.Skipped {
+		reasons = append(reasons, s.Expr)
+	}
+	// Memory 没有提供者，Annotated 已有注解，FieldsOf 没有对应的注解
+	wantSkipped := []string{"repo.Annotated", "wire.Bind(new(repo.Store), new(repo.Memory))",
+		"wire.FieldsOf(new(*svc.Service))"}
+	if !slices.Equal(reasons, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", reasons, wantSkipped)
+	}
+	if !slices.Equal(plan.Sets, []string{"app.RepoSet", "app.ProviderSet"}) {
+		t.Errorf("sets = %v", plan.Sets)
+	}
+
+	var diff strings.Builder
+	if err := plan.Diff(&diff); err != nil {
+		t.Fatal(err)
+	}
+	// 相邻的插入合并为同一个 hunk
+	if !strings.Contains(diff.String(), "@@ -5,9 +5,11 @@\n // MySQL 存储.\n type MySQL struct{}\n \n"+
+		"+// @autowire(set=repo,Store)\n func NewMySQL() *MySQL { return nil }\n \n type (\n"+
+		"+\t// @autowire(set=repo,fields=Size)\n") {
+		t.Errorf("diff =\n%s", diff.String())
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "svc/svc.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "// @autowire(set=app)\ntype Service struct{}\n\n"+
+		"// @autowire.value(set=app,io.Writer)\nvar Out io.Writer\n") {
+		t.Errorf("svc.go =\n%s", data)
+	}
+}
+
+func TestSetName(t *testing.T) {
+	tests := []struct{ varName, pkg, want string }{
+		{"RepoSet", "wiring", "repo"},
+		{"ProviderSet", "user", "user"},
+		{"Set", "order", "order"},
+		{"HTTPHandlers", "api", "httpHandlers"},
+	}
+	for _, tt := range tests {
+		if got := setName(tt.varName, tt.pkg); got != tt.want {
+			t.Errorf("setName(%q, %q) = %q, want %q", tt.varName, tt.pkg, got, tt.want)
+		}
+	}
+}
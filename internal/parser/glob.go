@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globCache 缓存已编译的 glob 正则表达式.
+var globCache sync.Map
+
+// MatchGlob function    判断路径是否匹配 glob 模式
+// 支持 *（不跨目录）、?、** （跨任意层目录）；路径统一使用 / 分隔。
+// 不含 / 的模式只与路径的最后一段匹配，例如 "*.pb.go" 匹配任意目录下的 pb 文件.
+func MatchGlob(pattern, name string) bool {
+	pattern = filepath.ToSlash(pattern)
+	name = filepath.ToSlash(name)
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+	return globRegexp(pattern).MatchString(name)
+}
+
+// MatchAnyGlob function    判断路径是否匹配任意一个 glob 模式.
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp function    将 glob 模式编译为正则表达式.
+func globRegexp(pattern string) *regexp.Regexp {
+	if re, ok := globCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re := regexp.MustCompile(sb.String())
+	globCache.Store(pattern, re)
+	return re
+}
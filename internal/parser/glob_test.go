@@ -0,0 +1,32 @@
+package parser
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.pb.go", "api/user/user.pb.go", true},
+		{"*.pb.go", "api/user/user.go", false},
+		{"api/*.go", "api/a.go", true},
+		{"api/*.go", "api/v1/a.go", false},
+		{"api/**", "api/v1/a.go", true},
+		{"api/**/*.go", "api/a.go", true},
+		{"api/**/*.go", "api/v1/v2/a.go", true},
+		{"internal/legacy/**", "internal/legacy", false},
+		{"internal/legacy/**", "internal/legacy/x/y.go", true},
+		{"**/mocks/*.go", "pkg/svc/mocks/store.go", true},
+		{"a?c.go", "abc.go", true},
+		{"a.c.go", "abc.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.name, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go/token"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -33,7 +34,7 @@ var (
 	importMu sync.Mutex
 )
 
-// GetPathGoPkgName    获取指定目录的 Go 包名
+// GetPathGoPkgName    获取指定目录的 Go 包名
 // 通过解析目录中的 .go 文件来确定包名.
 func GetPathGoPkgName(pathStr string) (pkg string, err error) {
 	entries, err := os.ReadDir(pathStr)
@@ -67,7 +68,7 @@ func GetPathGoPkgName(pathStr string) (pkg string, err error) {
 		}
 
 		// 解析文件
-		f, err := parser.ParseFile(token.NewFileSet(), "", bs, parser.ParseComments)
+		f, err := parser.ParseFile(token.NewFileSet(), name, bs, parser.ParseComments)
 		if err != nil {
 			return "", fmt.Errorf("解析文件 %s 失败: %w", name, err)
 		}
@@ -78,7 +79,7 @@ func GetPathGoPkgName(pathStr string) (pkg string, err error) {
 	return "", errors.New("目录中未找到有效的 Go 源文件")
 }
 
-// getGoPkgNameByDir    使用目录名作为包名
+// getGoPkgNameByDir    使用目录名作为包名
 // 这是一个后备方案，当无法从文件中读取包名时使用.
 func getGoPkgNameByDir(pathStr string) (pkg string) {
 	return filepath.Base(pathStr)
@@ -101,7 +102,7 @@ func GetGoModDir() (modPath string) {
 	return
 }
 
-// GetGoModFilePath    获取 go.mod 文件的完整路径
+// GetGoModFilePath    获取 go.mod 文件的完整路径
 // 使用 sync.Once 确保只执行一次 go env 命令.
 func GetGoModFilePath() (modPath string) {
 	o.Do(func() {
@@ -148,23 +149,34 @@ func GetModBase() (modBase string, err error) {
 // 例如: github.com/Just-maple/go-autowire/example/dependencies
 //
 // filePath: 文件的绝对或相对路径
-// modBase: 模块的基础路径.
+// modBase: 当前模块的基础路径，文件属于 go.work 中的其他模块时使用该模块的路径.
 func GetPkgPath(filePath, modBase string) (pkgPath string) {
 	abs, err := filepath.Abs(filePath)
 	if err != nil {
 		return
 	}
 
-	dir := GetGoModDir()
-	if len(abs) < len(dir) {
-		return
+	modDir := GetGoModDir()
+	if m, ok := ModuleOf(abs); ok {
+		if rel, _ := RelPath(modDir, m.Dir); rel != "." {
+			modDir, modBase = m.Dir, m.Path
+		}
 	}
 
-	// 计算相对于模块根目录的路径，并拼接模块基础路径
-	pkgPath = filepath.ToSlash(filepath.Dir(filepath.Join(modBase, abs[len(dir):])))
+	pkgPath, _ = filePkgPath(modDir, modBase, abs, CaseInsensitiveFS, windowsPaths)
 	return
 }
 
+// filePkgPath function    根据模块根目录与模块路径计算文件所在包的导入路径，文件不在模块内时返回 false
+// 相对路径统一为 / 分隔（兼容 Windows 盘符大小写差异、UNC 与长路径前缀），导入路径中不会出现 \.
+func filePkgPath(modDir, modBase, file string, fold, windows bool) (string, bool) {
+	rel, ok := relPath(modDir, file, fold, windows)
+	if !ok {
+		return "", false
+	}
+	return path.Dir(path.Join(modBase, rel)), true
+}
+
 // AppendPkg function    拼接包名和选择器
 // 如果包名为空，直接返回选择器
 // 例如: appendPkg("pkg", "Type") -> "pkg.Type".
@@ -177,7 +189,7 @@ func AppendPkg(pkg string, sel string) string {
 
 // ImportAndWrite function    自动添加缺失的 import，移除未使用的 import，并格式化代码.
 func ImportAndWrite(filename string, src []byte) error {
-	writeData, err := importProcess(src)
+	writeData, err := ProcessImports(src)
 	if err != nil {
 		return fmt.Errorf("处理 import 语句失败: %w", err)
 	}
@@ -189,9 +201,9 @@ func ImportAndWrite(filename string, src []byte) error {
 	return nil
 }
 
-// importProcess function    处理代码的 import 语句
+// ProcessImports function    处理代码的 import 语句
 // 使用 goimports 自动添加、删除和格式化 import.
-func importProcess(src []byte) ([]byte, error) {
+func ProcessImports(src []byte) ([]byte, error) {
 	importMu.Lock()
 	defer importMu.Unlock()
 
@@ -92,3 +92,62 @@ func TestGetPathGoPkgName_NonExistentDir(t *testing.T) {
 		t.Error("GetPathGoPkgName() 应该返回错误，但没有")
 	}
 }
+
+func TestRelPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		target string
+		fold   bool
+		want   string
+		wantOK bool
+	}{
+		{"子目录文件", "/work/mod", "/work/mod/pkg/a.go", false, "pkg/a.go", true},
+		{"相同目录", "/work/mod", "/work/mod/", false, ".", true},
+		{"不在目录下", "/work/mod", "/work/module/a.go", false, "", false},
+		{"大小写敏感不匹配", "/Work/Mod", "/work/mod/a.go", false, "", false},
+		{"大小写不敏感匹配", "C:/Work/Mod", "c:/work/mod/svc/a.go", true, "svc/a.go", true},
+		{"盘符路径深层目录", "C:/work/mod", "C:/work/mod/internal/svc/a.go", true, "internal/svc/a.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := relPath(tt.base, tt.target, tt.fold, false)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("relPath(%q, %q) = (%q, %v), want (%q, %v)", tt.base, tt.target, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFilePkgPath_Windows(t *testing.T) {
+	tests := []struct {
+		name   string
+		modDir string
+		file   string
+		want   string
+		wantOK bool
+	}{
+		{"盘符路径", `C:\work\mod`, `C:\work\mod\internal\svc\a.go`, "github.com/x/internal/svc", true},
+		{"盘符大小写不同", `c:\Work\Mod`, `C:\work\mod\internal\svc\a.go`, "github.com/x/internal/svc", true},
+		{"模块根目录文件", `C:\work\mod\`, `C:\work\mod\main.go`, "github.com/x", true},
+		{"混合分隔符", `C:/work/mod`, `C:\work\mod\internal/svc\a.go`, "github.com/x/internal/svc", true},
+		{"UNC 路径", `\\server\share\mod`, `\\server\share\mod\svc\a.go`, "github.com/x/svc", true},
+		{"UNC 共享不同", `\\server\share\mod`, `\\server\other\mod\svc\a.go`, "", false},
+		{"长路径前缀", `C:\work\mod`, `\\?\C:\work\mod\svc\a.go`, "github.com/x/svc", true},
+		{"长路径模块目录", `\\?\c:\work\mod`, `C:\work\mod\svc\a.go`, "github.com/x/svc", true},
+		{"UNC 长路径前缀", `\\server\share\mod`, `\\?\UNC\server\share\mod\svc\a.go`, "github.com/x/svc", true},
+		{"其他盘符", `C:\work\mod`, `D:\work\mod\svc\a.go`, "", false},
+		{"同名前缀目录", `C:\work\mod`, `C:\work\module\svc\a.go`, "", false},
+		{"上级目录", `C:\work\mod\svc`, `C:\work\mod\svc\..\a.go`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := filePkgPath(tt.modDir, "github.com/x", tt.file, true, true)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("filePkgPath(%q, %q) = (%q, %v), want (%q, %v)", tt.modDir, tt.file, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IgnoreFileNames 扫描与监听时读取的忽略文件，语法与 .gitignore 一致.
+var IgnoreFileNames = []string{".gitignore", ".gutowireignore"}
+
+// ignoreRule struct    忽略文件中的一条规则.
+type ignoreRule struct {
+	pattern  string // glob 模式
+	anchored bool   // 开头或中间带 /，相对忽略文件所在目录匹配，否则只与路径的最后一段匹配
+	negate   bool   // 以 ! 开头，重新包含之前被忽略的路径
+	dirOnly  bool   // 以 / 结尾，只匹配目录
+}
+
+// ignoreDir struct    一个目录中忽略文件的规则.
+type ignoreDir struct {
+	rules    []ignoreRule // 按出现顺序排列，后出现的规则优先
+	repoRoot bool         // 是否为仓库根目录（包含 .git），更上层目录的忽略文件不再生效
+}
+
+// Ignore struct    按目录读取 .gitignore 与 .gutowireignore，判断路径是否被忽略
+// 规则从仓库根目录（包含 .git 的目录，没有时为模块根目录）开始逐级生效，下层目录的规则优先；
+// 读取过的目录会被缓存，可以并发使用.
+type Ignore struct {
+	mu   sync.Mutex
+	dirs map[string]*ignoreDir // 绝对路径 -> 该目录中的规则
+}
+
+// NewIgnore function    创建忽略规则匹配器.
+func NewIgnore() *Ignore {
+	return &Ignore{dirs: make(map[string]*ignoreDir)}
+}
+
+// Match method    判断路径是否被忽略文件中的规则忽略，isDir 表示路径为目录.
+func (ig *Ignore) Match(path string, isDir bool) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	// 从路径所在目录向上收集各级目录，直到仓库根目录
+	var chain []string
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		chain = append(chain, dir)
+		if ig.load(dir).repoRoot {
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			// 不在仓库中时只使用模块根目录及以下的忽略文件
+			chain = moduleChain(chain, GetModuleDir(abs))
+			break
+		}
+	}
+
+	ignored := false
+	for i := len(chain) - 1; i >= 0; i-- {
+		rel, ok := RelPath(chain[i], abs)
+		if !ok {
+			continue
+		}
+		for _, rule := range ig.load(chain[i]).rules {
+			if (!rule.dirOnly || isDir) && rule.match(rel) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// match method    判断相对忽略文件所在目录的路径是否匹配规则.
+func (r ignoreRule) match(rel string) bool {
+	if r.anchored {
+		return globRegexp(r.pattern).MatchString(rel)
+	}
+	return MatchGlob(r.pattern, rel)
+}
+
+// moduleChain function    只保留模块根目录及其下层的目录.
+func moduleChain(chain []string, modDir string) []string {
+	for i, dir := range chain {
+		if dir == modDir {
+			return chain[:i+1]
+		}
+	}
+	return chain[:0]
+}
+
+// load method    读取目录中的忽略文件，结果会被缓存.
+func (ig *Ignore) load(dir string) *ignoreDir {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+	if d, ok := ig.dirs[dir]; ok {
+		return d
+	}
+
+	d := &ignoreDir{}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		d.repoRoot = true
+	}
+	for _, name := range IgnoreFileNames {
+		d.rules = append(d.rules, readIgnoreFile(filepath.Join(dir, name))...)
+	}
+	ig.dirs[dir] = d
+	return d
+}
+
+// readIgnoreFile function    读取忽略文件中的规则，文件不存在时返回 nil.
+func readIgnoreFile(fileName string) []ignoreRule {
+	//nolint:gosec
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil
+	}
+	//nolint:errcheck
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreRule function    解析一行忽略规则，空行与 # 开头的注释返回 false.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	// \# 与 \! 表示以该字符开头的文件名
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// 开头或中间带 / 的规则相对忽略文件所在目录匹配
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	rule.pattern = line
+	return rule, true
+}
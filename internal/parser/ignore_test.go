@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":                 "",
+		".gitignore":                "# 生成的代码\n/gen/\n*.pb.go\n!keep.pb.go\nbuild\n",
+		"api/.gutowireignore":       "legacy/\nv1/*.go\n",
+		"api/v1/user.go":            "",
+		"api/v1/nested/user.go":     "",
+		"api/legacy/user.go":        "",
+		"api/user.pb.go":            "",
+		"api/keep.pb.go":            "",
+		"svc/gen/a.go":              "",
+		"gen/a.go":                  "",
+		"svc/build/a.go":            "",
+		"svc/service.go":            "",
+		"svc/legacy/not_ignored.go": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"gen", true, true},
+		{"gen", false, false},     // 只匹配目录的规则
+		{"svc/gen", true, false},  // 带 / 的规则相对 .gitignore 所在目录
+		{"svc/build", true, true}, // 不带 / 的规则匹配任意层级
+		{"api/user.pb.go", false, true},
+		{"api/keep.pb.go", false, false}, // ! 重新包含
+		{"api/legacy", true, true},       // 下层目录的 .gutowireignore
+		{"svc/legacy", true, false},      // 只在所在目录及以下生效
+		{"api/v1/user.go", false, true},
+		{"api/v1/nested/user.go", false, false},
+		{"svc/service.go", false, false},
+	}
+	ig := NewIgnore()
+	for _, tt := range tests {
+		if got := ig.Match(filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("Match(%s, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
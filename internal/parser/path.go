@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CaseInsensitiveFS 当前平台的文件系统默认是否大小写不敏感（Windows、macOS）.
+var CaseInsensitiveFS = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// windowsPaths 当前平台是否使用 Windows 路径（\ 分隔、盘符、UNC 与 \\?\ 长路径前缀）.
+var windowsPaths = runtime.GOOS == "windows"
+
+// NameEqual function    比较两个文件或目录名称
+// 在大小写不敏感的文件系统上忽略大小写.
+func NameEqual(a, b string) bool {
+	if CaseInsensitiveFS {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// HasNamePrefix function    判断文件名是否带有指定前缀
+// 在大小写不敏感的文件系统上忽略大小写.
+func HasNamePrefix(name, prefix string) bool {
+	return len(name) >= len(prefix) && NameEqual(name[:len(prefix)], prefix)
+}
+
+// RelPath function    计算 target 相对于 base 的路径，结果统一使用 / 分隔
+// 两个路径都会先清理并统一分隔符；target 不在 base 之下时返回 false
+// Windows 上忽略盘符与路径的大小写，\\?\ 长路径前缀与不带前缀的同一路径视为相同.
+func RelPath(base, target string) (string, bool) {
+	return relPath(base, target, CaseInsensitiveFS, windowsPaths)
+}
+
+// relPath function    RelPath 的实现，fold 表示是否忽略大小写，windows 表示按 Windows 路径处理
+// 不依赖 filepath 的平台实现，任何平台上都可以测试 Windows 路径.
+func relPath(base, target string, fold, windows bool) (string, bool) {
+	base = normalizePath(base, windows)
+	target = normalizePath(target, windows)
+
+	equal := func(a, b string) bool {
+		if fold {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+
+	if equal(base, target) {
+		return ".", true
+	}
+	prefix := strings.TrimSuffix(base, "/") + "/"
+	if len(target) < len(prefix) || !equal(target[:len(prefix)], prefix) {
+		return "", false
+	}
+	return target[len(prefix):], true
+}
+
+// normalizePath function    清理路径并统一为 / 分隔
+// windows 为 true 时把 \ 视为分隔符，并去掉 \\?\ 与 \\?\UNC\ 长路径前缀：
+// \\?\C:\mod 等同于 C:/mod，\\?\UNC\server\share 等同于 //server/share.
+func normalizePath(p string, windows bool) string {
+	if !windows {
+		return filepath.ToSlash(filepath.Clean(p))
+	}
+	p = strings.ReplaceAll(p, `\`, "/")
+	for _, prefix := range []string{"//?/", "//./"} {
+		if rest, ok := strings.CutPrefix(p, prefix); ok {
+			if len(rest) >= 4 && strings.EqualFold(rest[:4], "UNC/") {
+				rest = "/" + rest[3:]
+			}
+			p = rest
+			break
+		}
+	}
+	// UNC 路径保留开头的 //，path.Clean 会把它合并为一个 /
+	if unc, ok := strings.CutPrefix(p, "//"); ok {
+		return "/" + path.Clean("/"+unc)
+	}
+	return path.Clean(p)
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WalkOptions struct    Walk 的选项.
+type WalkOptions struct {
+	MaxDepth int      // 大于 0 时只进入 root 下 MaxDepth 层以内的目录（root 为第 0 层），0 表示不限制
+	Roots    []string // 全部搜索路径，指向其中目录的符号链接不跟随（这些目录会按原路径遍历）
+}
+
+// Walk function    与 filepath.Walk 相同，按词法顺序遍历 root 下的目录与文件，另外：
+// 跟随指向搜索路径之外目录的符号链接，同一真实目录只遍历一次，符号链接形成循环时不会重复进入；
+// 超过 MaxDepth 的目录不会传给 fn.
+func Walk(root string, opts WalkOptions, fn filepath.WalkFunc) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	w := walker{opts: opts, fn: fn, visited: NewSet[string]()}
+	for _, r := range append([]string{root}, opts.Roots...) {
+		if real, err := realPath(r); err == nil {
+			w.roots = append(w.roots, real)
+		}
+	}
+	err = w.walk(root, info, 0, false)
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walker struct    Walk 的遍历状态.
+type walker struct {
+	opts    WalkOptions
+	fn      filepath.WalkFunc
+	roots   []string    // 搜索路径的真实路径
+	visited Set[string] // 已遍历目录的真实路径
+}
+
+// walk method    遍历目录或文件，link 表示 path 是指向目录的符号链接.
+func (w *walker) walk(path string, info fs.FileInfo, depth int, link bool) error {
+	if !info.IsDir() {
+		return w.fn(path, info, nil)
+	}
+	if real, err := realPath(path); err == nil {
+		if w.visited.Contains(real) || (link && w.withinRoots(real)) {
+			return nil
+		}
+		w.visited.Add(real)
+	}
+	if err := w.fn(path, info, nil); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return w.fn(path, info, err)
+	}
+	for _, entry := range entries {
+		name := filepath.Join(path, entry.Name())
+		info, err := entry.Info()
+		isLink := err == nil && info.Mode()&fs.ModeSymlink != 0
+		if isLink {
+			info, err = os.Stat(name)
+		}
+		if err != nil {
+			if err := w.fn(name, nil, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			continue
+		}
+		if info.IsDir() && w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+			continue
+		}
+		if err := w.walk(name, info, depth+1, isLink && info.IsDir()); err != nil {
+			// 文件返回 SkipDir 时跳过所在目录的其余内容
+			if !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// withinRoots method    判断真实路径是否在某个搜索路径内.
+func (w *walker) withinRoots(real string) bool {
+	for _, root := range w.roots {
+		if _, ok := RelPath(root, real); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath function    返回解析符号链接后的绝对路径.
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.go", "svc/b.go", "svc/deep/c.go", "svc/deep/deeper/d.go"} {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "shared.go"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"svc/loop":  root,                        // 指向上级目录，形成循环
+		"alias":     filepath.Join(root, "svc"),  // 指向搜索路径内的目录，按原路径遍历
+		"third":     outside,                     // 指向搜索路径外的目录，跟随
+		"broken.go": filepath.Join(root, "none"), // 失效的链接
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("不支持符号链接: %v", err)
+		}
+	}
+
+	walk := func(opts WalkOptions) []string {
+		t.Helper()
+		var files []string
+		err := Walk(root, opts, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				rel, _ := RelPath(root, path)
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+
+	want := []string{"a.go", "svc/b.go", "svc/deep/c.go", "svc/deep/deeper/d.go", "third/shared.go"}
+	if got := walk(WalkOptions{}); !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+	want = []string{"a.go", "svc/b.go", "svc/deep/c.go", "third/shared.go"}
+	if got := walk(WalkOptions{MaxDepth: 2}); !slices.Equal(got, want) {
+		t.Errorf("Walk(MaxDepth: 2) = %v, want %v", got, want)
+	}
+	// 链接目标在其他搜索路径内时不跟随
+	want = []string{"a.go", "svc/b.go", "svc/deep/c.go", "svc/deep/deeper/d.go"}
+	if got := walk(WalkOptions{Roots: []string{outside}}); !slices.Equal(got, want) {
+		t.Errorf("Walk(Roots) = %v, want %v", got, want)
+	}
+}
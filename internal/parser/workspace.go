@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Module struct    go.work 工作区中的单个 Go 模块.
+type Module struct {
+	Dir  string // 模块根目录（绝对路径）
+	Path string // go.mod 中 module 声明的路径
+}
+
+var (
+	// workModules 缓存工作区中的模块列表.
+	workModules []Module
+	// workOnce 确保工作区只解析一次.
+	workOnce sync.Once
+)
+
+// GetGoWorkFilePath function    获取 go.work 文件的完整路径，未使用工作区（或 GOWORK=off）时返回空.
+func GetGoWorkFilePath() string {
+	//nolint:gosec
+	cmd := exec.Command("go", "env", "GOWORK")
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	_ = cmd.Run()
+	work := strings.TrimSpace(stdout.String())
+	if work == "off" {
+		return ""
+	}
+	return work
+}
+
+// GetWorkspaceModules function    返回 go.work 中 use 的全部模块
+// 未使用工作区时只包含当前模块；无法读取的模块会被跳过.
+func GetWorkspaceModules() []Module {
+	workOnce.Do(func() {
+		workModules = loadWorkspaceModules(GetGoWorkFilePath())
+	})
+	return workModules
+}
+
+// loadWorkspaceModules function    解析 go.work 文件，读取每个 use 目录中 go.mod 的 module 路径.
+func loadWorkspaceModules(workFile string) []Module {
+	if workFile == "" {
+		if base, err := GetModBase(); err == nil {
+			if dir, err := filepath.Abs(GetGoModDir()); err == nil {
+				return []Module{{Dir: dir, Path: base}}
+			}
+		}
+		return nil
+	}
+
+	//nolint:gosec
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil
+	}
+	wf, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil
+	}
+
+	var modules []Module
+	for _, use := range wf.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(workFile), dir)
+		}
+		//nolint:gosec
+		mb, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		if f, err := modfile.ParseLax("go.mod", mb, nil); err == nil && f.Module != nil {
+			modules = append(modules, Module{Dir: filepath.Clean(dir), Path: f.Module.Mod.Path})
+		}
+	}
+	return modules
+}
+
+// ModuleOf function    返回包含指定文件的工作区模块，嵌套模块时取最内层的一个.
+func ModuleOf(filePath string) (Module, bool) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return Module{}, false
+	}
+	var found Module
+	for _, m := range GetWorkspaceModules() {
+		if _, ok := RelPath(m.Dir, abs); ok && len(m.Dir) > len(found.Dir) {
+			found = m
+		}
+	}
+	return found, found.Dir != ""
+}
+
+// GetModuleDir function    返回包含指定文件的模块根目录，不属于任何工作区模块时返回 go.mod 所在目录.
+func GetModuleDir(filePath string) string {
+	if m, ok := ModuleOf(filePath); ok {
+		return m.Dir
+	}
+	return GetGoModDir()
+}
+
+// PackageDir function    返回导入路径在工作区模块中对应的目录，嵌套模块时取最内层的一个
+// 不属于任何工作区模块（如第三方依赖）时返回 false.
+func PackageDir(importPath string) (string, bool) {
+	var found Module
+	for _, m := range GetWorkspaceModules() {
+		if importPath != m.Path && !strings.HasPrefix(importPath, m.Path+"/") {
+			continue
+		}
+		if len(m.Path) > len(found.Path) {
+			found = m
+		}
+	}
+	if found.Dir == "" {
+		return "", false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, found.Path), "/")
+	return filepath.Join(found.Dir, filepath.FromSlash(rel)), true
+}
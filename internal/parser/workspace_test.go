@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadWorkspaceModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.work":    "go 1.25\n\nuse (\n\t./app\n\t./lib\n\t./missing\n)\n",
+		"app/go.mod": "module example.com/app\n\ngo 1.25\n",
+		"lib/go.mod": "module example.com/lib\n\ngo 1.25\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 无法读取 go.mod 的 use 目录被跳过
+	modules := loadWorkspaceModules(filepath.Join(tmpDir, "go.work"))
+	want := []Module{
+		{Dir: filepath.Join(tmpDir, "app"), Path: "example.com/app"},
+		{Dir: filepath.Join(tmpDir, "lib"), Path: "example.com/lib"},
+	}
+	if !slices.Equal(modules, want) {
+		t.Errorf("loadWorkspaceModules() = %v, want %v", modules, want)
+	}
+}
+
+func TestPackageDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := GetModBase()
+	if err != nil {
+		t.Skip("不在 Go 模块中")
+	}
+	if dir, ok := PackageDir(base + "/internal/parser"); !ok || dir != wd {
+		t.Errorf("PackageDir() = %q, %v, want %q", dir, ok, wd)
+	}
+	if _, ok := PackageDir("github.com/google/wire"); ok {
+		t.Error("第三方依赖不应属于工作区模块")
+	}
+	if _, ok := PackageDir(base + "x/foo"); ok {
+		t.Error("模块路径只是前缀相同的包不应匹配")
+	}
+}
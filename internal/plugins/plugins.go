@@ -0,0 +1,204 @@
+// Package plugins 加载代码生成插件（Go 插件或可执行文件），在生成 Wire 配置文件之后
+// 依次发送扫描到的组件、写入的 Set 文件与生成完成事件，并返回插件需要写入的文件。
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	goplugin "plugin"
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/pkg/plugin"
+)
+
+// Loaded struct    已加载的插件.
+type Loaded struct {
+	Name   string        // 插件的配置（路径或命令），用于错误提示
+	Plugin plugin.Plugin // 插件实现
+	close  func() error  // 释放插件资源（可执行文件插件关闭标准输入并等待退出）
+}
+
+// Close method    释放插件资源.
+func (l *Loaded) Close() error {
+	if l.close == nil {
+		return nil
+	}
+	return l.close()
+}
+
+// Load function    加载插件：以 .so 结尾的路径作为 Go 插件打开，其余作为可执行文件命令（按空白分隔参数）.
+func Load(spec string) (*Loaded, error) {
+	if strings.HasSuffix(spec, ".so") {
+		return loadGoPlugin(spec)
+	}
+	return startExec(spec)
+}
+
+// loadGoPlugin function    打开 Go 插件并查找导出的 Plugin 变量.
+func loadGoPlugin(path string) (*Loaded, error) {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 Go 插件 %s 失败: %w", path, err)
+	}
+	sym, err := p.Lookup("Plugin")
+	if err != nil {
+		return nil, fmt.Errorf("Go 插件 %s 没有导出 Plugin 变量: %w", path, err)
+	}
+	// var Plugin plugin.Plugin = ... 查找到的是 *plugin.Plugin；var Plugin T 查找到的是 *T
+	switch v := sym.(type) {
+	case *plugin.Plugin:
+		return &Loaded{Name: path, Plugin: *v}, nil
+	case plugin.Plugin:
+		return &Loaded{Name: path, Plugin: v}, nil
+	default:
+		return nil, fmt.Errorf("Go 插件 %s 的 Plugin 变量（%T）没有实现 plugin.Plugin", path, sym)
+	}
+}
+
+// execPlugin struct    可执行文件插件：通过标准输入发送事件，从标准输出读取响应，每行一个 JSON.
+type execPlugin struct {
+	enc *json.Encoder
+	out *bufio.Scanner
+}
+
+// startExec function    启动可执行文件插件，插件的标准错误直接输出到 gutowire 的标准错误.
+func startExec(spec string) (*Loaded, error) {
+	args := strings.Fields(spec)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("插件命令为空")
+	}
+	//nolint:gosec
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建插件 %s 的标准输入失败: %w", spec, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建插件 %s 的标准输出失败: %w", spec, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动插件 %s 失败: %w", spec, err)
+	}
+
+	out := bufio.NewScanner(stdout)
+	out.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	return &Loaded{
+		Name:   spec,
+		Plugin: &execPlugin{enc: json.NewEncoder(stdin), out: out},
+		close: func() error {
+			_ = stdin.Close()
+			// 读取剩余输出，避免插件阻塞在写入上
+			_, _ = io.Copy(io.Discard, stdout)
+			if err := cmd.Wait(); err != nil {
+				return fmt.Errorf("插件 %s 异常退出: %w", spec, err)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// call method    发送一个事件并读取响应.
+func (p *execPlugin) call(ev plugin.Event) (*plugin.Response, error) {
+	if err := p.enc.Encode(ev); err != nil {
+		return nil, fmt.Errorf("向插件发送 %s 事件失败: %w", ev.Event, err)
+	}
+	if !p.out.Scan() {
+		if err := p.out.Err(); err != nil {
+			return nil, fmt.Errorf("读取插件响应失败: %w", err)
+		}
+		return nil, fmt.Errorf("插件在 %s 事件后没有响应就退出", ev.Event)
+	}
+	var resp plugin.Response
+	if err := json.Unmarshal(p.out.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("解析插件响应失败: %w（%s）", err, p.out.Text())
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// OnElement method    发送组件事件.
+func (p *execPlugin) OnElement(e plugin.Element) error {
+	_, err := p.call(plugin.Event{Event: plugin.EventElement, Element: &e})
+	return err
+}
+
+// OnSetWritten method    发送 Set 文件写入事件.
+func (p *execPlugin) OnSetWritten(s plugin.SetFile) error {
+	_, err := p.call(plugin.Event{Event: plugin.EventSetWritten, Set: &s})
+	return err
+}
+
+// OnComplete method    发送生成完成事件并返回插件需要写入的文件.
+func (p *execPlugin) OnComplete(r plugin.Result) ([]plugin.File, error) {
+	resp, err := p.call(plugin.Event{Event: plugin.EventComplete, Result: &r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Files, nil
+}
+
+// Run function    向插件依次发送组件、Set 文件与生成完成事件，返回插件需要写入的文件
+// 组件按 Set 名称、组件键排序，Set 文件按 Set 名称排序，多次运行的事件顺序一致.
+func Run(p plugin.Plugin, sc *generator.AutoWireSearcher) ([]plugin.File, error) {
+	for _, set := range parser.SortedKeys(sc.ElementMap) {
+		elements := sc.ElementMap[set]
+		for _, key := range parser.SortedKeys(elements) {
+			if err := p.OnElement(NewElement(set, elements[key])); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	setFiles := sc.SetFiles()
+	for _, set := range parser.SortedKeys(setFiles) {
+		s := plugin.SetFile{Name: set, File: setFiles[set], Elements: len(sc.ElementMap[set])}
+		if err := p.OnSetWritten(s); err != nil {
+			return nil, err
+		}
+	}
+	return p.OnComplete(plugin.Result{
+		GenPath: sc.GenPath(),
+		Package: sc.Package(),
+		Sets:    parser.SortedKeys(sc.ElementMap),
+	})
+}
+
+// NewElement function    将扫描到的组件转换为插件接口中的组件.
+func NewElement(set string, e generator.Element) plugin.Element {
+	kind := "type"
+	switch {
+	case e.FuncDecl:
+		kind = "func"
+	case e.ValueWire:
+		kind = "value"
+	}
+	bindings := slices.Clone(e.Implements)
+	slices.Sort(bindings)
+	return plugin.Element{
+		Set:         set,
+		Name:        e.Name,
+		Pkg:         e.Pkg,
+		PkgPath:     e.PkgPath,
+		Kind:        kind,
+		Constructor: e.Constructor,
+		Bindings:    bindings,
+		Deps:        slices.Clone(e.Deps),
+		Init:        e.InitWire,
+		Config:      e.ConfigWire,
+		Tag:         e.Tag,
+		Qualifier:   e.Qualifier,
+		File:        e.Position.Filename,
+		Line:        e.Position.Line,
+	}
+}
@@ -0,0 +1,78 @@
+package plugins
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/pkg/plugin"
+)
+
+// helperEnv 设置时测试二进制作为可执行文件插件运行.
+const helperEnv = "GUTOWIRE_TEST_PLUGIN"
+
+// recorder 记录收到的事件，完成时返回事件列表.
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) OnElement(e plugin.Element) error {
+	r.events = append(r.events, "element:"+e.Set+"."+e.Name)
+	return nil
+}
+
+func (r *recorder) OnSetWritten(s plugin.SetFile) error {
+	r.events = append(r.events, "set:"+s.Name)
+	return nil
+}
+
+func (r *recorder) OnComplete(res plugin.Result) ([]plugin.File, error) {
+	r.events = append(r.events, "complete:"+res.Package)
+	return []plugin.File{{Path: "events.txt", Content: strings.Join(r.events, "\n")}}, nil
+}
+
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		t.Skip("只作为可执行文件插件运行")
+	}
+	if err := plugin.Serve(&recorder{}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestExecPlugin(t *testing.T) {
+	t.Setenv(helperEnv, "1")
+	p, err := Load(os.Args[0] + " -test.run=^TestHelperPlugin$")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := p.Plugin.OnElement(plugin.Element{Set: "svc", Name: "Repo"}); err != nil {
+		t.Fatalf("OnElement() error = %v", err)
+	}
+	if err := p.Plugin.OnSetWritten(plugin.SetFile{Name: "svc"}); err != nil {
+		t.Fatalf("OnSetWritten() error = %v", err)
+	}
+	files, err := p.Plugin.OnComplete(plugin.Result{Package: "wire"})
+	if err != nil {
+		t.Fatalf("OnComplete() error = %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := "element:svc.Repo\nset:svc\ncomplete:wire"
+	if len(files) != 1 || files[0].Path != "events.txt" || files[0].Content != want {
+		t.Errorf("OnComplete() = %+v, want events.txt %q", files, want)
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	if _, err := Load("gutowire-plugin-does-not-exist"); err == nil {
+		t.Error("Load() 对不存在的命令应返回错误")
+	}
+	if _, err := Load("missing.so"); err == nil {
+		t.Error("Load() 对不存在的 Go 插件应返回错误")
+	}
+}
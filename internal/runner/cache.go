@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/lock"
+)
+
+// ClearCache function    删除生成目录中的缓存文件，下次生成时完整扫描并重写全部生成文件
+// 删除前获取生成目录锁，避免与正在进行的生成冲突；返回缓存文件路径以及删除前缓存文件是否存在.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，如锁等待时间
+func ClearCache(genPath string, opts ...config.Option) (string, bool, error) {
+	o := config.NewGenOpt(genPath, opts...)
+	file := filepath.Join(o.GenPath, generator.CacheFileName)
+
+	l, err := lock.Acquire(o.Context, o.GenPath, o.LockTimeout)
+	if err != nil {
+		return file, false, err
+	}
+	defer func() {
+		if rerr := l.Release(); rerr != nil {
+			o.Logger.Warn("释放生成目录锁失败", "error", rerr)
+		}
+	}()
+
+	_, err = os.Stat(file)
+	existed := err == nil
+	return file, existed, generator.NewCacheManager(o.GenPath, true).Clear()
+}
@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/graph"
+)
+
+// CheckResult struct    校验结果.
+type CheckResult struct {
+	Errors   []error  // 会导致生成或 wire 失败的问题
+	Warnings []string // 不影响生成的提示
+}
+
+// Check function    执行完整的扫描、注解解析与依赖图校验，但不写入任何文件
+// 适用于 pre-commit 钩子与 CI 检查；扫描本身失败时返回 error.
+//
+// genPath: 生成文件的目标目录（用于检测循环导入）
+// opts: 可选配置，如搜索路径、包名等
+func Check(genPath string, opts ...config.Option) (*CheckResult, error) {
+	o := config.NewGenOpt(genPath, opts...)
+	sc, err := scan(o)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{}
+	// 严格模式下扫描时输出的警告视为错误
+	if err := checkWarnings(o, 0); err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+	// 注解语法问题：严格模式下视为错误，否则作为提示
+	for _, d := range sc.Diagnostics() {
+		if o.Strict {
+			result.Errors = append(result.Errors, d.Err())
+		} else {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s: 注解 %s 格式错误，已忽略: %s", d.Position, d.Text, d.Reason))
+		}
+	}
+	if err := sc.Prepare(); err != nil {
+		result.Errors = append(result.Errors, err)
+	} else if err := sc.Validate(); err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+	for _, d := range sc.Deprecations() {
+		result.Warnings = append(result.Warnings, d.String())
+	}
+
+	graphErrs, warnings := graph.Build(sc.ElementMap).Validate()
+	result.Errors = append(result.Errors, graphErrs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, nil
+}
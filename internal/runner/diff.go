@@ -0,0 +1,20 @@
+package runner
+
+import (
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+)
+
+// Diff function    在内存中重新生成，返回与磁盘上的生成文件相比会被修改、新建或删除的文件及其前后内容
+// 不写入任何文件也不运行 wire，wire_gen.go 不在比较范围内；扫描或生成失败时返回 error.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，应与生成时一致
+func Diff(genPath string, opts ...config.Option) ([]generator.FileChange, error) {
+	o := config.NewGenOpt(genPath, append(opts, config.WithCheckOnly(true))...)
+	sc, err := generateInMemory(o)
+	if err != nil {
+		return nil, err
+	}
+	return sc.PendingFileChanges(), nil
+}
@@ -0,0 +1,345 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/toolchain"
+	"github.com/spelens-gud/gutowire/internal/version"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// DoctorStatus 环境检查项的结果.
+type DoctorStatus string
+
+const (
+	// DoctorOK 检查通过.
+	DoctorOK DoctorStatus = "ok"
+	// DoctorWarn 不影响生成，但可能导致问题.
+	DoctorWarn DoctorStatus = "warn"
+	// DoctorFail 会导致生成失败.
+	DoctorFail DoctorStatus = "fail"
+)
+
+// DoctorCheck struct    单个环境检查项.
+type DoctorCheck struct {
+	Name       string       `json:"name"`                 // 检查项名称
+	Status     DoctorStatus `json:"status"`               // 检查结果
+	Detail     string       `json:"detail"`               // 检查到的信息，如 wire 路径与版本
+	Suggestion string       `json:"suggestion,omitempty"` // 未通过时的解决建议
+}
+
+// Doctor function    检查运行 gutowire 所需的环境，不写入任何文件
+// 依次检查 wire 命令、go.mod、GOPATH/bin 是否在 PATH 中、生成目录的写权限以及注解数量.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，如搜索路径、wire 版本等
+func Doctor(genPath string, opts ...config.Option) []DoctorCheck {
+	o := config.NewGenOpt(genPath, opts...)
+	return []DoctorCheck{
+		checkWire(o),
+		checkGoMod(o),
+		checkGoBin(),
+		checkWritable(o.GenPath),
+		checkAnnotationCount(o),
+	}
+}
+
+// checkWire function    检查 wire 命令是否可用及其版本.
+func checkWire(o *config.Opt) DoctorCheck {
+	c := DoctorCheck{Name: "wire"}
+	if o.Backend == config.BackendFx {
+		c.Status, c.Detail = DoctorOK, "fx 后端不需要 wire 命令"
+		return c
+	}
+	if o.WireMode == config.WireModeGoRun {
+		return checkGoRunWire(o)
+	}
+
+	if o.WireVersion != "" {
+		v, err := toolchain.NormalizeVersion(o.WireVersion)
+		if err != nil {
+			c.Status, c.Detail = DoctorFail, "无效的 wire_version: "+o.WireVersion
+			c.Suggestion = "使用完整的语义化版本号，如 wire_version: v0.6.0"
+			return c
+		}
+		bin, err := toolchain.WireBinPath(v)
+		if err != nil {
+			c.Status, c.Detail = DoctorFail, err.Error()
+			return c
+		}
+		if _, err := os.Stat(bin); err != nil {
+			c.Status, c.Detail = DoctorWarn, fmt.Sprintf("wire %s 尚未安装到工具缓存", v)
+			c.Suggestion = "首次生成时会通过 go install 自动安装，需要能访问 GOPROXY"
+			return checkWireCompat(c, v)
+		}
+		c.Status, c.Detail = DoctorOK, fmt.Sprintf("%s (%s)", bin, v)
+		return checkWireCompat(c, v)
+	}
+
+	bin, err := exec.LookPath("wire")
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, "PATH 中未找到 wire 命令"
+		c.Suggestion = "运行 go install github.com/google/wire/cmd/wire@latest，或在配置文件中设置 wire_version"
+		return c
+	}
+	v := binaryVersion(o.Context, bin, toolchain.WirePackage)
+	c.Status, c.Detail = DoctorOK, fmt.Sprintf("%s (%s)", bin, v)
+	return checkWireCompat(c, v)
+}
+
+// checkGoRunWire function    检查 gorun 模式下能否通过 go run 编译 wire.
+func checkGoRunWire(o *config.Opt) DoctorCheck {
+	c := DoctorCheck{Name: "wire"}
+	if o.WireVersion != "" {
+		c.Status, c.Detail = DoctorOK, "gorun 模式，go run "+toolchain.WirePackage+"@"+o.WireVersion
+		v, _ := toolchain.NormalizeVersion(o.WireVersion)
+		return checkWireCompat(c, v)
+	}
+
+	version, err := moduleWireVersion(o.Context)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, "gorun 模式，当前模块无法编译 wire 命令"
+		c.Suggestion = "运行 go get -tool " + toolchain.WirePackage
+		return c
+	}
+	c.Status, c.Detail = DoctorOK, "gorun 模式，go.mod 中的 wire "+version
+	return checkWireCompat(c, version)
+}
+
+// wireUpgradeSuggestion wire 版本过低时的升级建议.
+var wireUpgradeSuggestion = fmt.Sprintf("运行 go install %s@latest 升级，或配置 wire_version: %s 及以上版本",
+	toolchain.WirePackage, version.MinWireVersion)
+
+// checkWireCompat function    wire 版本低于生成的代码需要的最低版本时，将检查结果改为警告.
+func checkWireCompat(c DoctorCheck, v string) DoctorCheck {
+	if toolchain.WireCompatible(v) {
+		return c
+	}
+	c.Status = DoctorWarn
+	c.Detail += "，低于生成的代码需要的 " + version.MinWireVersion
+	c.Suggestion = wireUpgradeSuggestion
+	return c
+}
+
+// WireInfo struct    生成时使用的 wire 及其与生成的代码的兼容性.
+type WireInfo struct {
+	Path       string `json:"path,omitempty"`    // wire 可执行文件路径，gorun 模式或未安装时为空
+	Version    string `json:"version,omitempty"` // wire 的模块版本，无法确定时为空
+	MinVersion string `json:"min_version"`       // 生成的代码需要的最低版本
+	Compatible bool   `json:"compatible"`        // 版本是否满足最低要求，无法确定版本时视为满足
+}
+
+// InstalledWire function    返回生成时会使用的 wire 及其版本，不安装、不运行 wire
+// 配置了 wire_version 时为工具缓存中的对应版本，gorun 模式为 go.mod 中的版本，否则为 PATH 中的 wire.
+//
+// opts: 可选配置，如 wire 版本、运行模式等
+func InstalledWire(opts ...config.Option) WireInfo {
+	o := config.NewGenOpt(".", opts...)
+	return installedWire(o.Context, o)
+}
+
+// installedWire function    返回配置对应的 wire 及其版本，ctx 被取消时不再读取版本.
+func installedWire(ctx context.Context, o *config.Opt) WireInfo {
+	info := WireInfo{MinVersion: version.MinWireVersion}
+	switch {
+	case o.WireVersion != "":
+		v, err := toolchain.NormalizeVersion(o.WireVersion)
+		if err != nil {
+			break
+		}
+		info.Version = v
+		if o.WireMode == config.WireModeGoRun {
+			break
+		}
+		if bin, err := toolchain.WireBinPath(v); err == nil {
+			if _, err := os.Stat(bin); err == nil {
+				info.Path = bin
+			}
+		}
+	case o.WireMode == config.WireModeGoRun:
+		if v, err := moduleWireVersion(ctx); err == nil && semver.IsValid(v) {
+			info.Version = v
+		}
+	default:
+		if bin, err := exec.LookPath("wire"); err == nil {
+			info.Path, info.Version = bin, toolchain.BinaryVersion(ctx, bin, toolchain.WirePackage)
+		}
+	}
+	info.Compatible = toolchain.WireCompatible(info.Version)
+	return info
+}
+
+// moduleWireVersion function    返回当前模块 go.mod 中 wire 的版本，模块无法编译 wire 命令时返回错误.
+func moduleWireVersion(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	//nolint:gosec
+	// 加载 wire 命令的全部依赖，缺少依赖时 go list 失败
+	cmd := exec.CommandContext(ctx, "go", "list", "-deps", "-f", "{{with .Module}}{{.Path}} {{.Version}}{{end}}",
+		toolchain.WirePackage)
+	cmd.Dir = parser.GetGoModDir()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	version := "未知版本"
+	for line := range strings.SplitSeq(string(output), "\n") {
+		if p, v, ok := strings.Cut(line, " "); ok && strings.HasPrefix(toolchain.WirePackage, p) {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// binaryVersion function    通过 go version -m 读取可执行文件中记录的模块版本，读取失败时返回 "未知版本".
+func binaryVersion(ctx context.Context, bin, pkg string) string {
+	if v := toolchain.BinaryVersion(ctx, bin, pkg); v != "" {
+		return v
+	}
+	return "未知版本"
+}
+
+// checkGoMod function    检查 go.mod 是否可读，以及是否依赖了所选后端的模块.
+func checkGoMod(o *config.Opt) DoctorCheck {
+	c := DoctorCheck{Name: "go.mod"}
+	modPath := parser.GetGoModFilePath()
+	if modPath == "" {
+		c.Status, c.Detail = DoctorFail, "未找到 go.mod"
+		c.Suggestion = "在项目根目录运行 go mod init <module>"
+		return c
+	}
+
+	//nolint:gosec
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, fmt.Sprintf("读取 %s 失败: %v", modPath, err)
+		return c
+	}
+	f, err := modfile.ParseLax(modPath, data, nil)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, fmt.Sprintf("解析 %s 失败: %v", modPath, err)
+		return c
+	}
+	if f.Module == nil {
+		c.Status, c.Detail = DoctorFail, modPath+" 缺少 module 声明"
+		return c
+	}
+
+	dep := "github.com/google/wire"
+	if o.Backend == config.BackendFx {
+		dep = "go.uber.org/fx"
+	}
+	if !slices.ContainsFunc(f.Require, func(r *modfile.Require) bool { return r.Mod.Path == dep }) {
+		c.Status, c.Detail = DoctorWarn, fmt.Sprintf("%s (module %s)，未依赖 %s", modPath, f.Module.Mod.Path, dep)
+		c.Suggestion = "运行 go get " + dep + "，否则生成的代码无法编译"
+		return c
+	}
+	c.Status, c.Detail = DoctorOK, fmt.Sprintf("%s (module %s)", modPath, f.Module.Mod.Path)
+	return c
+}
+
+// checkGoBin function    检查 go install 的安装目录（GOBIN 或 GOPATH/bin）是否在 PATH 中.
+func checkGoBin() DoctorCheck {
+	c := DoctorCheck{Name: "GOPATH/bin"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	//nolint:gosec
+	output, err := exec.CommandContext(ctx, "go", "env", "GOBIN", "GOPATH").Output()
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, "无法运行 go env: "+err.Error()
+		c.Suggestion = "确认已安装 Go 并且 go 命令在 PATH 中"
+		return c
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	binDir := strings.TrimSpace(lines[0])
+	if binDir == "" && len(lines) > 1 {
+		// 未设置 GOBIN 时安装到 GOPATH 第一项的 bin 目录
+		binDir = filepath.Join(filepath.SplitList(strings.TrimSpace(lines[1]))[0], "bin")
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && filepath.Clean(dir) == filepath.Clean(binDir) {
+			c.Status, c.Detail = DoctorOK, binDir
+			return c
+		}
+	}
+	c.Status, c.Detail = DoctorWarn, binDir+" 不在 PATH 中"
+	c.Suggestion = fmt.Sprintf("将 %s 加入 PATH，否则 go install 安装的 wire 无法直接使用", binDir)
+	return c
+}
+
+// checkWritable function    检查生成目录是否可写，目录不存在时检查最近的已存在上级目录.
+func checkWritable(genPath string) DoctorCheck {
+	c := DoctorCheck{Name: "生成目录"}
+	dir, err := filepath.Abs(genPath)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, err.Error()
+		return c
+	}
+	detail := dir
+	for {
+		if info, err := os.Stat(dir); err == nil {
+			if !info.IsDir() {
+				c.Status, c.Detail = DoctorFail, dir+" 不是目录"
+				return c
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		detail = fmt.Sprintf("%s（不存在，将在 %s 下创建）", genPath, dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".gutowire-doctor-*")
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, fmt.Sprintf("%s 不可写: %v", dir, err)
+		c.Suggestion = "检查目录权限，或使用 -w 指定其他生成目录"
+		return c
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	c.Status, c.Detail = DoctorOK, detail
+	return c
+}
+
+// checkAnnotationCount function    扫描搜索路径并统计注解数量，没有注解或注解格式错误时给出提示.
+func checkAnnotationCount(o *config.Opt) DoctorCheck {
+	c := DoctorCheck{Name: "注解"}
+	sc, err := scan(o)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, "扫描失败: "+err.Error()
+		return c
+	}
+
+	count := 0
+	for _, m := range sc.ElementMap {
+		count += len(m)
+	}
+	diags := sc.Diagnostics()
+	switch {
+	case count == 0:
+		c.Status, c.Detail = DoctorWarn, "未找到任何 "+o.Tag+" 注解"
+		c.Suggestion = "检查搜索路径（-s）与排除目录配置，或参考文档中的注解示例"
+	case len(diags) > 0:
+		c.Status, c.Detail = DoctorWarn, fmt.Sprintf("%d 个组件，%d 个注解格式错误", count, len(diags))
+		c.Suggestion = "运行 gutowire check 查看注解错误的位置"
+	default:
+		c.Status, c.Detail = DoctorOK, fmt.Sprintf("%d 个组件，%d 个 Set", count, len(sc.ElementMap))
+	}
+	return c
+}
@@ -4,26 +4,24 @@ package runner
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
-	"log"
-	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spelens-gud/gutowire/internal/config"
 	"github.com/spelens-gud/gutowire/internal/errors"
 	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/graph"
+	"github.com/spelens-gud/gutowire/internal/lock"
 	"github.com/spelens-gud/gutowire/internal/parser"
+	"github.com/spelens-gud/gutowire/internal/plugins"
+	"github.com/spelens-gud/gutowire/internal/toolchain"
+	"github.com/spelens-gud/gutowire/internal/version"
 )
 
-// init function    初始化日志配置.
-func init() {
-	log.SetPrefix("[gutowire] ") // 设置日志前缀
-	log.SetFlags(0)              // 不显示时间戳
-	log.SetOutput(os.Stdout)     // 输出到标准输出
-}
-
 // RunAutoWire function    执行完整的自动装配流程
 // 这是主入口函数，完成两个步骤：
 // 1. 扫描注解并生成 Wire 配置文件（autowire_*.go）
@@ -32,57 +30,319 @@ func init() {
 // genPath: 生成文件的目标目录
 // opts: 可选配置，如搜索路径、包名等
 func RunAutoWire(genPath string, opts ...config.Option) error {
+	_, err := RunAutoWireSummary(genPath, opts...)
+	return err
+}
+
+// Summary struct    一次自动装配的汇总信息，用于在运行结束时输出概览.
+type Summary struct {
+	generator.Summary
+
+	WireDuration time.Duration           // wire 命令的执行时间，没有运行 wire 时为 0
+	Duration     time.Duration           // 整个流程的执行时间
+	Deprecations []generator.Deprecation // 初始化函数用到的已弃用组件（deprecated= 参数）
+}
+
+// RunAutoWireSummary function    与 RunAutoWire 相同，成功时返回生成的汇总信息
+// （各 Set 的组件数量、写入的文件、wire 执行时间、源文件缓存命中率）.
+func RunAutoWireSummary(genPath string, opts ...config.Option) (*Summary, error) {
+	o := config.NewGenOpt(genPath, opts...)
+	return run(o.Context, o, func() (*generator.AutoWireSearcher, error) {
+		return scan(o)
+	}, true)
+}
+
+// Generate function    扫描注解并生成 Wire 配置文件（fx 后端生成 fx 模块），不调用 wire 命令
+// 供自行运行 wire 或只需要 Set 文件的构建工具使用；生成前同样检查注解语法与依赖图.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，如搜索路径、包名等
+func Generate(genPath string, opts ...config.Option) error {
+	o := config.NewGenOpt(genPath, opts...)
+	_, err := run(o.Context, o, func() (*generator.AutoWireSearcher, error) {
+		return scan(o)
+	}, false)
+	return err
+}
+
+// Incremental struct    增量自动装配，供 watch 模式使用
+// 首次运行时完整扫描，之后只重新解析变更的文件，其余文件复用内存中的扫描结果.
+type Incremental struct {
+	o     *config.Opt
+	sc    *generator.AutoWireSearcher // 上次的扫描结果，为 nil 时下次运行完整扫描
+	cache *generator.CacheManager     // 进程内共享的解析结果缓存，完整扫描时内容未变化的文件不重新解析
+}
+
+// NewIncremental function    创建增量自动装配.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，如搜索路径、包名等
+func NewIncremental(genPath string, opts ...config.Option) *Incremental {
+	o := config.NewGenOpt(genPath, opts...)
+	return &Incremental{o: o, cache: generator.NewSharedCache(o)}
+}
+
+// Run method    执行一次自动装配
+// changed 为变更（含新建、删除）的文件；首次运行或未指定文件时完整扫描.
+func (r *Incremental) Run(changed ...string) error {
+	return r.RunContext(r.o.Context, changed...)
+}
+
+// RunContext method    与 Run 相同，ctx 被取消时终止本次运行
+// 增量扫描被取消时扫描结果可能只更新了一部分，下次运行完整扫描.
+func (r *Incremental) RunContext(ctx context.Context, changed ...string) error {
+	summary, err := run(ctx, r.o, func() (*generator.AutoWireSearcher, error) {
+		if r.sc == nil || len(changed) == 0 {
+			sc, err := scanWithCache(ctx, r.o, r.cache)
+			r.sc = sc
+			return sc, err
+		}
+		r.o.Logger.Info("增量扫描变更的文件", "files", changed)
+		r.sc.SetContext(ctx)
+		if err := r.sc.Rescan(changed...); err != nil {
+			// 扫描结果可能只更新了一部分，下次重新完整扫描
+			r.sc = nil
+			return nil, err
+		}
+		return r.sc, nil
+	}, true)
+	if summary != nil {
+		// watch 模式不输出汇总，弃用警告通过日志输出
+		for _, d := range summary.Deprecations {
+			r.o.Logger.Warn("使用了已弃用的组件", "element", d.Element, "reason", d.Message,
+				"injectors", strings.Join(d.Injectors, ", "))
+		}
+	}
+	return err
+}
+
+// run function    在生成目录锁内完成自动装配
+// load 返回扫描结果（完整扫描或增量更新），之后生成 Wire 配置文件，withWire 为 true 时再调用 wire 命令；
+// fx 后端只生成 fx 模块，不调用 wire 命令；成功时返回生成的汇总信息。
+// ctx 被取消时在当前步骤结束前返回 ctx 的错误，load 需要使用同一个 ctx 扫描.
+func run(ctx context.Context, o *config.Opt, load func() (*generator.AutoWireSearcher, error),
+	withWire bool) (*Summary, error) {
+	start := time.Now()
+	if o.Backend != config.BackendWire && o.Backend != config.BackendFx {
+		return nil, fmt.Errorf("不支持的后端: %s（可选 %s、%s）", o.Backend, config.BackendWire, config.BackendFx)
+	}
+	if o.WireMode != config.WireModeExec && o.WireMode != config.WireModeGoRun {
+		return nil, fmt.Errorf("不支持的 wire 运行方式: %s（可选 %s、%s）",
+			o.WireMode, config.WireModeExec, config.WireModeGoRun)
+	}
+	if o.MissingProviders != config.MissingProvidersError && o.MissingProviders != config.MissingProvidersWarn {
+		return nil, fmt.Errorf("不支持的缺少提供者处理方式: %s（可选 %s、%s）",
+			o.MissingProviders, config.MissingProvidersError, config.MissingProvidersWarn)
+	}
+	if err := config.CheckDuplicateBinding(o.DuplicateBinding); err != nil {
+		return nil, err
+	}
+	if _, err := config.ParseConflictPolicy(o.ConflictPolicy); err != nil {
+		return nil, err
+	}
+	if err := o.CheckFileNaming(); err != nil {
+		return nil, err
+	}
+
+	// 获取生成目录锁，避免多个进程交错执行清理、写入和 wire
+	l, err := lock.Acquire(ctx, o.GenPath, o.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rerr := l.Release(); rerr != nil {
+			o.Logger.Warn("释放生成目录锁失败", "error", rerr)
+		}
+	}()
+
 	// 第一步：生成 Wire 配置文件
-	if err := runAutoWireGen(genPath, opts...); err != nil {
-		return fmt.Errorf("生成 Wire 配置文件失败: %w", err)
+	mark := o.Warnings.Len()
+	sc, err := load()
+	if err == nil {
+		if err = checkAnnotations(o, sc); err != nil {
+			return nil, err
+		}
+		// 严格模式下扫描时输出了警告，不写入任何文件
+		if err = checkWarnings(o, mark); err != nil {
+			return nil, err
+		}
+		err = runAutoWireGen(o, sc)
+	}
+	if err == nil && o.Stamp != nil {
+		err = sc.WriteStamp(o.Stamp)
+	}
+	if err == nil {
+		err = runPlugins(o, sc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成 Wire 配置文件失败: %w", err)
+	}
+	if err := checkWarnings(o, mark); err != nil {
+		return nil, err
+	}
+	summary := &Summary{Summary: sc.Summary(), Deprecations: sc.Deprecations()}
+	done := func() (*Summary, error) {
+		// 完整生成成功后记录生成校验文件，供 gutowire verify 校验
+		if o.SumFile && !o.CheckOnly && (withWire || o.Backend == config.BackendFx) {
+			if err := sc.WriteSum(sc.Sum(wireVersion(ctx, o), nil)); err != nil {
+				return nil, err
+			}
+		}
+		summary.Duration = time.Since(start)
+		return summary, nil
+	}
+
+	// 运行 wire 之前检查组件之间的循环依赖与没有提供者的依赖，给出带源码位置的提示（检查模式同样检查）
+	// missing_providers 为 warn 时没有提供者的依赖只输出警告，交由 wire 报告
+	if len(sc.ElementMap) > 0 {
+		errs := graph.Build(sc.ElementMap).CycleErrors()
+		missing := sc.MissingProviders()
+		if o.MissingProviders == config.MissingProvidersWarn {
+			for _, err := range missing {
+				msg := err.Error()
+				if fe := (*errors.FriendlyError)(nil); stderrors.As(err, &fe) {
+					msg = fe.Message
+				}
+				o.Logger.Warn(msg + "，交由 wire 报告")
+			}
+			if err := checkWarnings(o, mark); err != nil {
+				errs = append(errs, err)
+			}
+			missing = nil
+		}
+		if errs = append(errs, missing...); len(errs) > 0 {
+			return nil, stderrors.Join(errs...)
+		}
+	}
+
+	// 检查模式下只报告会变化的文件：配置文件都是最新时，在临时目录中运行 wire 比较 wire_gen.go
+	if o.CheckOnly {
+		files := sc.PendingChanges()
+		if len(files) == 0 && o.Backend == config.BackendWire && len(sc.ElementMap) > 0 && withWire {
+			if files, err = staleWireGen(ctx, o, sc); err != nil {
+				return nil, err
+			}
+		}
+		if len(files) > 0 {
+			return nil, errors.NewStaleGeneratedError(files)
+		}
+		o.Logger.Info("生成的文件已是最新")
+		return done()
+	}
+
+	if o.Backend == config.BackendFx {
+		o.Logger.Info("fx 模块写入成功")
+	} else {
+		o.Logger.Info("Wire 配置文件写入成功")
 	}
 
-	log.Printf("Wire 配置文件写入成功")
+	if o.Backend == config.BackendFx || len(sc.ElementMap) == 0 || !withWire {
+		return done()
+	}
 
 	// 第二步：调用 wire 命令生成最终代码
-	if err := runWire(genPath); err != nil {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	wireStart := time.Now()
+	err = runWire(ctx, o, sc.OutputDirs())
+	summary.WireDuration = time.Since(wireStart)
+	if err != nil {
 		// 使用友好的错误提示
 		if wireErr, ok := err.(*errors.FriendlyError); ok {
-			return wireErr
+			// wire 的输出指向生成的文件，映射回产生对应提供者的注解
+			if wireErr.Type == errors.ErrorTypeWireError {
+				wireErr.Sources = sc.WireSources(wireErr.Details)
+			}
+			return nil, wireErr
 		}
-		return fmt.Errorf("运行 wire 命令失败: %w", err)
+		return nil, fmt.Errorf("运行 wire 命令失败: %w", err)
 	}
-	return nil
+	return done()
 }
 
-// runAutoWireGen function    执行自动装配代码生成
-// 这是代码生成的核心函数，完成以下步骤：
-// 1. 初始化配置
-// 2. 扫描指定目录下的所有 Go 文件
-// 3. 解析 @autowire 注解
-// 4. 生成 Wire 配置文件
-//
-// genPath: 生成文件的目标目录
-// opts: 可选配置
-func runAutoWireGen(genPath string, opts ...config.Option) error {
-	// 初始化配置选项
-	o := config.NewGenOpt(genPath, opts...)
-	file := o.SearchPath
-	pkg := strings.ReplaceAll(o.Pkg, "-", "_") // 包名中的 - 替换为 _（Go 包名规范）
+// runPlugins function    依次调用配置的代码生成插件，写入插件返回的文件.
+func runPlugins(o *config.Opt, sc *generator.AutoWireSearcher) error {
+	for _, spec := range o.Plugins {
+		p, err := plugins.Load(spec)
+		if err != nil {
+			return err
+		}
+		files, err := plugins.Run(p.Plugin, sc)
+		if cerr := p.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("插件 %s 执行失败: %w", spec, err)
+		}
+		for _, f := range files {
+			if err := sc.WriteArtifact(f.Path, []byte(f.Content)); err != nil {
+				return fmt.Errorf("写入插件 %s 生成的文件失败: %w", spec, err)
+			}
+		}
+		o.Logger.Debug("插件执行完成", "plugin", spec, "files", len(files))
+	}
+	return nil
+}
 
-	// 获取模块基础路径
-	modBase, err := parser.GetModBase()
+// wirePackages function    返回 wire gen 的包参数：生成路径本身与相对生成路径的其他输出目录.
+func wirePackages(genPath string, outputDirs []string) []string {
+	pkgs := []string{"."}
+	base, err := filepath.Abs(genPath)
 	if err != nil {
-		return fmt.Errorf("获取模块基础路径失败: %w", err)
+		return append(pkgs, outputDirs...)
+	}
+	for _, dir := range outputDirs {
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			pkgs = append(pkgs, dir)
+			continue
+		}
+		if rel = filepath.ToSlash(rel); !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		pkgs = append(pkgs, rel)
 	}
+	return pkgs
+}
 
-	// 创建搜索器实例
-	sc := generator.NewAutoWireSearcher(genPath, modBase, o.InitWire, pkg, o.EnableCache, o.ExcludeDirs)
+// checkAnnotations function    处理扫描到的注解语法问题
+// 严格模式下返回带源码位置的错误，否则逐条输出警告（这些注解或参数已被忽略）.
+func checkAnnotations(o *config.Opt, sc *generator.AutoWireSearcher) error {
+	diags := sc.Diagnostics()
+	if o.Strict && len(diags) > 0 {
+		return stderrors.Join(parser.Map(diags, generator.Diagnostic.Err)...)
+	}
+	for _, d := range diags {
+		o.Logger.Warn("注解格式错误，已忽略", "pos", d.Position.String(), "annotation", d.Text, "reason", d.Reason)
+	}
+	return nil
+}
 
-	// 扫描所有文件，收集注解信息
-	if err := sc.SearchAllPath(file); err != nil {
-		return fmt.Errorf("扫描文件失败: %w", err)
+// checkWarnings function    严格模式下检查第 mark 条之后是否输出了警告，有则返回错误.
+func checkWarnings(o *config.Opt, mark int) error {
+	if warnings := o.Warnings.Since(mark); len(warnings) > 0 {
+		return errors.NewStrictWarningsError(warnings)
 	}
-	log.Printf("autowire 注解分析完成")
+	return nil
+}
 
-	// 如果没有找到任何注解，直接返回
+// runAutoWireGen function    根据扫描结果生成 Wire 配置文件
+// 扫描由调用方完成（完整扫描或增量更新），这里只负责写入文件.
+//
+// o: 已初始化的配置选项
+// sc: 扫描结果
+func runAutoWireGen(o *config.Opt, sc *generator.AutoWireSearcher) error {
+	// 没有找到任何注解时仍然执行生成，以删除上次生成的文件
 	if len(sc.ElementMap) == 0 {
-		log.Printf("未找到任何 @autowire 注解")
+		o.Logger.Info("未找到任何 @autowire 注解")
+	}
+
+	// fx 后端生成 fx 模块
+	if o.Backend == config.BackendFx {
+		if err := sc.WriteFx(); err != nil {
+			return fmt.Errorf("写入 fx 模块失败: %w", err)
+		}
 		return nil
 	}
 
@@ -93,45 +353,140 @@ func runAutoWireGen(genPath string, opts ...config.Option) error {
 	return nil
 }
 
-// runWire function    执行 Google Wire 命令行工具
-// 读取生成的 autowire_*.go 文件，生成最终的 wire_gen.go.
-func runWire(path string) error {
-	log.Printf("开始运行 wire 命令")
+// Scan function    扫描注解并返回收集结果，不生成任何文件
+// 供 check、graph 等只读场景以及第三方生成器复用扫描能力。
+//
+// genPath: 生成文件的目标目录（用于检测循环导入与定位缓存）
+// opts: 可选配置，如搜索路径、包名等
+func Scan(genPath string, opts ...config.Option) (*generator.AutoWireSearcher, error) {
+	return scan(config.NewGenOpt(genPath, opts...))
+}
 
-	// 查找 wire 命令的路径
-	wirePath, err := exec.LookPath("wire")
+// Stream function    流式扫描注解，每解析完一个文件即将其中的组件交给 fn，不生成任何文件
+// 组件不会汇总到内存中，适合只需要逐个读取组件的文档生成、规则检查等工具。
+//
+// genPath: 生成文件的目标目录（用于检测循环导入与定位缓存）
+// roots: 扫描的目录，为空时使用配置的搜索路径
+// opts: 可选配置，如排除目录、包名等
+func Stream(ctx context.Context, genPath string, roots []string, fn generator.ElementFunc,
+	opts ...config.Option) error {
+	o := config.NewGenOpt(genPath, opts...)
+	modBase, err := parser.GetModBase()
 	if err != nil {
-		return &errors.FriendlyError{
-			Type:    errors.ErrorTypeFileNotFound,
-			Message: "未找到 wire 命令",
-			Suggestions: []string{
-				"运行以下命令安装 wire: go install github.com/google/wire/cmd/wire@latest",
-				"确保 $GOPATH/bin 或 $GOBIN 在 PATH 环境变量中",
-				"检查 Go 环境是否正确配置",
-			},
-			HelpURL: "https://github.com/google/wire#installation",
-		}
+		return fmt.Errorf("获取模块基础路径失败: %w", err)
 	}
+	if len(roots) == 0 {
+		roots = o.SearchRoots()
+	}
+	return generator.NewAutoWireSearcher(o, modBase).Stream(ctx, fn, roots...)
+}
 
-	// 检查是否为可信的 bin 目录
-	if !strings.Contains(wirePath, "bin") {
-		return fmt.Errorf("wire 命令路径不安全: %s", wirePath)
+// scan function    使用已初始化的配置选项扫描注解.
+func scan(o *config.Opt) (*generator.AutoWireSearcher, error) {
+	return scanWithCache(o.Context, o, nil)
+}
+
+// scanWithCache function    扫描注解，cache 不为 nil 时使用进程内共享的缓存，ctx 被取消时终止扫描.
+func scanWithCache(ctx context.Context, o *config.Opt, cache *generator.CacheManager) (*generator.AutoWireSearcher,
+	error) {
+	// 获取模块基础路径
+	modBase, err := parser.GetModBase()
+	if err != nil {
+		return nil, fmt.Errorf("获取模块基础路径失败: %w", err)
+	}
+
+	// 创建搜索器实例
+	sc := generator.NewAutoWireSearcher(o, modBase)
+	sc.SetContext(ctx)
+	if cache != nil {
+		sc.ShareCache(cache)
 	}
 
-	// 创建带超时的上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// 扫描所有文件，收集注解信息
+	if err := sc.SearchAllPath(o.SearchRoots()...); err != nil {
+		return nil, fmt.Errorf("扫描文件失败: %w", err)
+	}
+	elements := 0
+	for _, set := range sc.ElementMap {
+		elements += len(set)
+	}
+	o.Logger.Info("autowire 注解分析完成", "sets", len(sc.ElementMap), "elements", elements)
+	return sc, nil
+}
+
+// runWire function    执行 Google Wire 命令行工具
+// 读取生成的 autowire_*.go 文件，生成最终的 wire_gen.go；
+// 指定 WireVersion 时使用固定版本，指定 WireTags 时以 wire gen -tags 运行；
+// gorun 模式通过 go run 编译运行 wire，不需要安装 wire 可执行文件；
+// outputDirs 为 Set 的其他输出目录，与生成路径一起传给 wire gen；ctx 被取消时终止 wire 命令.
+func runWire(ctx context.Context, o *config.Opt, outputDirs []string) error {
+	logger := o.Logger
+	logger.Info("开始运行 wire 命令", "mode", o.WireMode)
+
+	// wire 的参数
+	var wireArgs []string
+	if o.WireTags != "" || len(outputDirs) > 0 {
+		wireArgs = append(wireArgs, "gen")
+	}
+	if o.WireTags != "" {
+		wireArgs = append(wireArgs, "-tags", o.WireTags)
+	}
+	if len(outputDirs) > 0 {
+		wireArgs = append(wireArgs, wirePackages(o.GenPath, outputDirs)...)
+	}
+
+	// 创建带超时的上下文，gorun 模式需要先编译 wire，超时更长
+	timeout := 30 * time.Second
+	name, args := "", wireArgs
+	if o.WireMode == config.WireModeGoRun {
+		run, err := toolchain.GoRunWire(o.WireVersion)
+		if err != nil {
+			return err
+		}
+		name, args, timeout = "go", append(run, wireArgs...), 2*time.Minute
+	} else {
+		// 查找 wire 命令的路径（安装固定版本可能需要下载，不计入执行超时）
+		wirePath, err := toolchain.Wire(ctx, o.WireVersion, logger)
+		if err != nil {
+			return err
+		}
+		// 检查是否为可信的 bin 目录
+		if !strings.Contains(wirePath, "bin") {
+			return fmt.Errorf("wire 命令路径不安全: %s", wirePath)
+		}
+		name = wirePath
+	}
+	warnWireVersion(o, installedWire(ctx, o).Version)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// 在指定目录下执行 wire 命令
 	//nolint:gosec
-	cmd := exec.CommandContext(ctx, wirePath)
-	cmd.Dir = path
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = o.GenPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Printf("[生成失败] %s", output)
+		// 被调用方取消时输出不完整，不作为 wire 的错误解析
+		if ctx.Err() != nil {
+			return fmt.Errorf("wire 命令已取消: %w", ctx.Err())
+		}
+		logger.Error("wire 生成失败", "output", strings.TrimSpace(string(output)))
 		// 返回友好的错误提示
+		if o.WireMode == config.WireModeGoRun {
+			return toolchain.NewGoRunWireError(string(output))
+		}
 		return errors.NewWireError(string(output))
 	}
-	log.Printf("[生成成功] %s", output)
+	logger.Info("wire 生成成功", "output", strings.TrimSpace(string(output)))
 	return nil
 }
+
+// warnWireVersion function    wire 版本低于生成的代码需要的最低版本时输出警告
+// 旧版本的 wire 不支持 wire gen -tags 等用法，报错信息不会指向版本问题.
+func warnWireVersion(o *config.Opt, v string) {
+	if toolchain.WireCompatible(v) {
+		return
+	}
+	o.Logger.Warn("wire 版本低于生成的代码需要的最低版本", "version", v, "min", version.MinWireVersion,
+		"suggestion", wireUpgradeSuggestion)
+}
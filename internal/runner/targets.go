@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Target struct    批量生成的一个输出目标.
+type Target struct {
+	GenPath string          // 生成文件的目标目录
+	Options []config.Option // 目标自身的选项（如包名、Set、初始化类型），追加在公共选项之后
+}
+
+// TargetSummary struct    批量生成中一个目标的汇总信息.
+type TargetSummary struct {
+	*Summary
+
+	GenPath string // 生成文件的目标目录
+}
+
+// RunTargets function    批量生成：只扫描一次注解，依次生成到每个目标并调用 wire
+// 第一个目标完成扫描，其余目标复用扫描结果；某个目标失败时停止，返回已完成目标的汇总信息.
+//
+// targets: 输出目标，至少一个
+// opts: 各目标共用的配置，如搜索路径、缓存、wire 版本等
+func RunTargets(targets []Target, opts ...config.Option) ([]TargetSummary, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("没有配置生成目标")
+	}
+	genPaths := parser.Map(targets, func(t Target) string { return filepath.Clean(t.GenPath) })
+	for i, p := range genPaths {
+		if slices.Contains(genPaths[:i], p) {
+			return nil, fmt.Errorf("生成目标 %s 重复", p)
+		}
+	}
+
+	var scanned *generator.AutoWireSearcher
+	summaries := make([]TargetSummary, 0, len(targets))
+	for _, t := range targets {
+		targetOpts := slices.Concat(opts, t.Options, []config.Option{config.WithTargets(genPaths...)})
+		o := config.NewGenOpt(t.GenPath, targetOpts...)
+		summary, err := run(o.Context, o, func() (*generator.AutoWireSearcher, error) {
+			if scanned != nil {
+				return scanned.ForTarget(o), nil
+			}
+			sc, err := scan(o)
+			scanned = sc
+			return sc, err
+		}, true)
+		if err != nil {
+			return summaries, fmt.Errorf("生成目标 %s 失败: %w", t.GenPath, err)
+		}
+		summaries = append(summaries, TargetSummary{Summary: summary, GenPath: t.GenPath})
+	}
+	return summaries, nil
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/lock"
+	"github.com/spelens-gud/gutowire/internal/toolchain"
+)
+
+// Verify function    校验重新生成的结果是否与生成校验文件 gutowire.sum 一致，不写入任何文件
+// 依次比较 gutowire 与 wire 的版本、带注解的输入文件及其注解、组件所在包的其他文件、生成的文件；
+// 返回差异描述，没有差异时为空；生成校验文件不存在或扫描失败时返回 error。
+// 生成的配置文件都是最新时，在临时目录中重新运行 wire，以重新生成的 wire_gen.go 与校验文件比较.
+//
+// genPath: 生成文件的目标目录
+// opts: 可选配置，应与生成时一致
+func Verify(genPath string, opts ...config.Option) ([]string, error) {
+	o := config.NewGenOpt(genPath, append(opts, config.WithCheckOnly(true))...)
+	recorded, err := generator.ReadSum(o.GenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := generateInMemory(o)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := sc.PendingChanges()
+	var wireGens map[string][]byte
+	if len(pending) == 0 && o.Backend == config.BackendWire && len(sc.ElementMap) > 0 {
+		if wireGens, err = regenerateWireGen(o.Context, o, sc); err != nil {
+			return nil, err
+		}
+	}
+	diffs := recorded.Diff(sc.Sum(wireVersion(o.Context, o), wireGens))
+	for _, file := range pending {
+		diffs = append(diffs, "重新生成会改变文件: "+file)
+	}
+	return diffs, nil
+}
+
+// generateInMemory function    持有生成目录锁，以检查模式扫描并生成（包括插件），不写入任何文件
+// o 需要开启 CheckOnly；严格模式下扫描或生成时输出了警告返回错误，与 check --strict 一致.
+func generateInMemory(o *config.Opt) (*generator.AutoWireSearcher, error) {
+	l, err := lock.Acquire(o.Context, o.GenPath, o.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rerr := l.Release(); rerr != nil {
+			o.Logger.Warn("释放生成目录锁失败", "error", rerr)
+		}
+	}()
+
+	mark := o.Warnings.Len()
+	sc, err := scan(o)
+	if err == nil {
+		if err = checkAnnotations(o, sc); err != nil {
+			return nil, err
+		}
+		if err = checkWarnings(o, mark); err != nil {
+			return nil, err
+		}
+		err = runAutoWireGen(o, sc)
+	}
+	if err == nil {
+		err = runPlugins(o, sc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成 Wire 配置文件失败: %w", err)
+	}
+	if err := checkWarnings(o, mark); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// wireVersion function    返回生成使用的 wire 版本，记录到生成校验文件中
+// fx 后端不运行 wire，返回空字符串；无法确定版本时返回 "未知版本".
+func wireVersion(ctx context.Context, o *config.Opt) string {
+	switch {
+	case o.Backend == config.BackendFx:
+		return ""
+	case o.WireVersion != "":
+		if v, err := toolchain.NormalizeVersion(o.WireVersion); err == nil {
+			return v
+		}
+		return o.WireVersion
+	case o.WireMode == config.WireModeGoRun:
+		if v, err := moduleWireVersion(ctx); err == nil {
+			return v
+		}
+	default:
+		if bin, err := exec.LookPath("wire"); err == nil {
+			return binaryVersion(ctx, bin, toolchain.WirePackage)
+		}
+	}
+	return "未知版本"
+}
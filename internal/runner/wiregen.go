@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/generator"
+)
+
+// wireGenFile wire 生成的文件名.
+const wireGenFile = "wire_gen.go"
+
+// regenerateWireGen function    在临时目录中重新运行 wire，返回各输出目录重新生成的 wire_gen.go（路径 -> 内容），不修改输出目录
+// 调用方需确认磁盘上的 autowire_*.go 已是最新（检查模式下没有待更新的文件）；
+// 每个输出目录的 Go 文件复制到同级以 _ 开头的临时目录（go 命令的 ./... 不匹配），wire 只写入临时目录；
+// 返回的路径为绝对路径.
+func regenerateWireGen(ctx context.Context, o *config.Opt, sc *generator.AutoWireSearcher) (map[string][]byte, error) {
+	dirs, err := outputDirs(o, sc)
+	if err != nil {
+		return nil, err
+	}
+	tmps := make([]string, 0, len(dirs))
+	defer func() {
+		for _, tmp := range tmps {
+			if err := os.RemoveAll(tmp); err != nil {
+				o.Logger.Warn("删除临时目录失败", "dir", tmp, "error", err)
+			}
+		}
+	}()
+	for _, dir := range dirs {
+		tmp, err := copyPackage(dir)
+		if tmp != "" {
+			tmps = append(tmps, tmp)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	wo := *o
+	wo.GenPath = tmps[0]
+	if err := runWire(ctx, &wo, tmps[1:]); err != nil {
+		return nil, err
+	}
+
+	gens := make(map[string][]byte, len(dirs))
+	for i, dir := range dirs {
+		//nolint:gosec
+		if data, err := os.ReadFile(filepath.Join(tmps[i], wireGenFile)); err == nil {
+			gens[filepath.Join(dir, wireGenFile)] = data
+		}
+	}
+	return gens, nil
+}
+
+// staleWireGen function    返回重新运行 wire 时会变化（修改、新建或删除）的 wire_gen.go.
+func staleWireGen(ctx context.Context, o *config.Opt, sc *generator.AutoWireSearcher) ([]string, error) {
+	gens, err := regenerateWireGen(ctx, o, sc)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := outputDirs(o, sc)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, dir := range dirs {
+		file := filepath.Join(dir, wireGenFile)
+		//nolint:gosec
+		existing, rerr := os.ReadFile(file)
+		data, ok := gens[file]
+		if (rerr == nil) != ok || !bytes.Equal(existing, data) {
+			stale = append(stale, file)
+		}
+	}
+	return stale, nil
+}
+
+// outputDirs function    返回生成路径与 Set 的其他输出目录的绝对路径.
+func outputDirs(o *config.Opt, sc *generator.AutoWireSearcher) ([]string, error) {
+	dirs := append([]string{o.GenPath}, sc.OutputDirs()...)
+	for i, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		dirs[i] = abs
+	}
+	return dirs, nil
+}
+
+// copyPackage function    将目录中除 wire_gen.go 与测试文件外的 Go 文件复制到同级的临时目录，返回临时目录.
+func copyPackage(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("读取目录 %s 失败: %w", dir, err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "_gutowire_check_*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, ".go") || name == wireGenFile ||
+			strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		//nolint:gosec
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return tmp, err
+		}
+		//nolint:gosec
+		if err := os.WriteFile(filepath.Join(tmp, name), data, 0o644); err != nil {
+			return tmp, fmt.Errorf("复制 %s 失败: %w", name, err)
+		}
+	}
+	return tmp, nil
+}
@@ -0,0 +1,245 @@
+// Package toolchain 管理 gutowire 依赖的外部工具。
+// 支持将指定版本的 wire 安装到工具缓存目录，保证所有开发者与 CI 使用相同的 wire 版本。
+package toolchain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spelens-gud/gutowire/internal/errors"
+	"github.com/spelens-gud/gutowire/internal/version"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// WirePackage wire 命令行工具的包路径.
+	WirePackage = "github.com/google/wire/cmd/wire"
+	// CacheDirEnv 覆盖工具缓存目录的环境变量.
+	CacheDirEnv = "GUTOWIRE_TOOL_CACHE"
+)
+
+// NormalizeVersion function    校验并规范化 wire 版本号，如 0.6.0 返回 v0.6.0
+// 固定版本必须为完整的语义化版本，不接受 latest 等浮动版本.
+func NormalizeVersion(version string) (string, error) {
+	v := strings.TrimSpace(version)
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) || semver.Canonical(v) != v {
+		return "", &errors.FriendlyError{
+			Type:    errors.ErrorTypeInvalidConfig,
+			Message: "无效的 wire 版本: " + version,
+			Suggestions: []string{
+				"使用完整的语义化版本号，如 wire_version: v0.6.0",
+				"不支持 latest 等浮动版本，否则无法保证各环境生成结果一致",
+			},
+		}
+	}
+	return v, nil
+}
+
+// WireCompatible function    判断 wire 版本是否满足生成的代码需要的最低版本 version.MinWireVersion
+// 无法识别的版本（如空字符串、(devel)）视为兼容，不阻止生成.
+func WireCompatible(v string) bool {
+	if !semver.IsValid(v) {
+		return true
+	}
+	return semver.Compare(v, version.MinWireVersion) >= 0
+}
+
+// binaryVersionKey struct    BinaryVersion 结果缓存的键，可执行文件被替换（如重新安装）后修改时间随之变化.
+type binaryVersionKey struct {
+	bin, pkg string
+	modTime  time.Time
+}
+
+// binaryVersions BinaryVersion 的结果缓存，同一进程中多次生成（如 watch 模式）不重复运行 go version -m.
+var binaryVersions sync.Map
+
+// BinaryVersion function    通过 go version -m 读取可执行文件中记录的 pkg 所在模块的版本，读取失败时返回空字符串
+// 结果按可执行文件路径与修改时间缓存；ctx 被取消时返回空字符串且不缓存.
+func BinaryVersion(ctx context.Context, bin, pkg string) string {
+	info, err := os.Stat(bin)
+	if err != nil {
+		return ""
+	}
+	key := binaryVersionKey{bin: bin, pkg: pkg, modTime: info.ModTime()}
+	if v, ok := binaryVersions.Load(key); ok {
+		return v.(string)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	//nolint:gosec
+	output, err := exec.CommandContext(ctx, "go", "version", "-m", bin).Output()
+	if err != nil {
+		return ""
+	}
+	v := ""
+	for line := range strings.SplitSeq(string(output), "\n") {
+		// path 行记录主包路径，mod 行记录所在模块及版本
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" && strings.HasPrefix(pkg, fields[1]) {
+			v = fields[2]
+			break
+		}
+	}
+	binaryVersions.Store(key, v)
+	return v
+}
+
+// CacheDir function    返回工具缓存根目录
+// 优先使用 GUTOWIRE_TOOL_CACHE 环境变量，否则为用户缓存目录下的 gutowire/tools.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户缓存目录失败: %w", err)
+	}
+	return filepath.Join(dir, "gutowire", "tools"), nil
+}
+
+// WireBinPath function    返回指定版本 wire 在工具缓存中的可执行文件路径.
+func WireBinPath(version string) (string, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := "wire"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(root, "wire@"+version, "bin", name), nil
+}
+
+// Wire function    返回 wire 可执行文件路径
+// version 为空时从 PATH 中查找；否则使用工具缓存中的对应版本，不存在时通过 go install 安装.
+func Wire(ctx context.Context, version string, logger *slog.Logger) (string, error) {
+	if version == "" {
+		wirePath, err := exec.LookPath("wire")
+		if err != nil {
+			return "", &errors.FriendlyError{
+				Type:    errors.ErrorTypeFileNotFound,
+				Message: "未找到 wire 命令",
+				Suggestions: []string{
+					"运行以下命令安装 wire: go install github.com/google/wire/cmd/wire@latest",
+					"确保 $GOPATH/bin 或 $GOBIN 在 PATH 环境变量中",
+					"在 .gutowire.yaml 中配置 wire_version，由 gutowire 自动安装固定版本",
+				},
+				HelpURL: "https://github.com/google/wire#installation",
+			}
+		}
+		return wirePath, nil
+	}
+
+	v, err := NormalizeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	bin, err := WireBinPath(v)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(bin); err == nil && !info.IsDir() {
+		return bin, nil
+	}
+
+	logger.Info("安装 wire 到工具缓存", "version", v, "path", bin)
+	if err := install(ctx, WirePackage, v, filepath.Dir(bin)); err != nil {
+		return "", err
+	}
+	return bin, nil
+}
+
+// GoRunWire function    返回以 go run 方式运行 wire 的 go 命令参数，不需要安装 wire 可执行文件
+// version 为空时使用当前模块 go.mod 中依赖的 wire 版本（需要 go.sum 中有 cmd/wire 的依赖，
+// 如通过 go get -tool 添加）；否则运行指定版本。wire 的生成器位于其 internal 包中，无法作为库调用，
+// go run 仍需要编译 wire 并可能下载模块，不是封闭的运行方式.
+func GoRunWire(version string) ([]string, error) {
+	pkg := WirePackage
+	if version != "" {
+		v, err := NormalizeVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		pkg += "@" + v
+	}
+	return []string{"run", pkg}, nil
+}
+
+// NewGoRunWireError function    根据 go run 的输出返回友好错误
+// 模块中缺少 wire 依赖时给出添加依赖的建议，其余情况视为 wire 自身的错误.
+func NewGoRunWireError(output string) error {
+	if !strings.Contains(output, "no required module provides package") &&
+		!strings.Contains(output, "missing go.sum entry") &&
+		!strings.Contains(output, "module lookup disabled") {
+		return errors.NewWireError(output)
+	}
+	return &errors.FriendlyError{
+		Type:    errors.ErrorTypeFileNotFound,
+		Message: "当前模块无法编译 wire 命令",
+		Details: strings.TrimSpace(output),
+		Suggestions: []string{
+			"运行 go get -tool " + WirePackage + " 将 wire 添加为模块的工具依赖",
+			"Go 1.24 之前的版本可以在 tools.go 中导入 " + WirePackage + " 后运行 go mod tidy",
+			"或在 .gutowire.yaml 中配置 wire_version，由 go run 运行指定版本",
+		},
+		HelpURL: "https://go.dev/doc/modules/managing-dependencies#tools",
+	}
+}
+
+// install function    使用 go install 将工具安装到临时目录，成功后再移动到目标目录
+// 避免安装中断或并发安装时留下不完整的可执行文件.
+func install(ctx context.Context, pkg, version, binDir string) error {
+	if err := os.MkdirAll(filepath.Dir(binDir), 0o755); err != nil {
+		return fmt.Errorf("创建工具缓存目录失败: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(binDir), ".install-*")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	//nolint:errcheck
+	defer os.RemoveAll(tmp)
+
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, "go", "install", pkg+"@"+version)
+	// 在临时目录中执行，避免受当前模块 go.mod 的影响
+	cmd.Dir = tmp
+	cmd.Env = append(os.Environ(), "GOBIN="+tmp)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return &errors.FriendlyError{
+			Type:    errors.ErrorTypeFileNotFound,
+			Message: fmt.Sprintf("安装 wire %s 失败", version),
+			Details: strings.TrimSpace(string(output)),
+			Suggestions: []string{
+				"检查网络连接与 GOPROXY 配置",
+				"确认 wire_version 对应的版本已发布",
+				"也可以手动安装后删除 wire_version 配置，使用 PATH 中的 wire",
+			},
+			HelpURL: "https://github.com/google/wire/releases",
+		}
+	}
+
+	name := filepath.Base(pkg)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	// 其他进程可能已完成安装，此时直接使用已有的版本
+	if err := os.Rename(tmp, binDir); err != nil {
+		if _, serr := os.Stat(filepath.Join(binDir, name)); serr == nil {
+			return nil
+		}
+		return fmt.Errorf("移动 wire 到工具缓存失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package toolchain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/logger"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"v0.6.0", "v0.6.0", false},
+		{"0.6.0", "v0.6.0", false},
+		{" v0.7.0 ", "v0.7.0", false},
+		{"v1.0.0-rc.1", "v1.0.0-rc.1", false},
+		{"latest", "", true},
+		{"v0.6", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := NormalizeVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeVersion(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWireCompatible(t *testing.T) {
+	tests := map[string]bool{
+		"v0.4.0":                             false,
+		"v0.5.0":                             true,
+		"v0.7.1":                             true,
+		"v0.5.1-0.20240101000000-abcdef0123": true,
+		"v0.5.0-rc.1":                        false,
+		"(devel)":                            true,
+		"":                                   true,
+	}
+	for v, want := range tests {
+		if got := WireCompatible(v); got != want {
+			t.Errorf("WireCompatible(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestWire_Cached(t *testing.T) {
+	t.Setenv(CacheDirEnv, t.TempDir())
+
+	bin, err := WireBinPath("v0.6.0")
+	if err != nil {
+		t.Fatalf("WireBinPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(bin), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 缓存中已存在对应版本时不应触发安装
+	got, err := Wire(context.Background(), "0.6.0", logger.Discard())
+	if err != nil {
+		t.Fatalf("Wire() error = %v", err)
+	}
+	if got != bin {
+		t.Errorf("Wire() = %q, want %q", got, bin)
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	bin, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 测试二进制记录了当前模块，结果按路径与修改时间缓存
+	const pkg = "github.com/spelens-gud/gutowire/internal/toolchain"
+	if v := BinaryVersion(context.Background(), bin, pkg); v == "" {
+		t.Fatal("BinaryVersion() 未读取到版本")
+	}
+	if _, ok := binaryVersions.Load(binaryVersionKey{bin: bin, pkg: pkg, modTime: info.ModTime()}); !ok {
+		t.Error("BinaryVersion() 的结果未缓存")
+	}
+
+	// ctx 被取消时不读取也不缓存
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v := BinaryVersion(ctx, bin, WirePackage); v != "" {
+		t.Errorf("BinaryVersion() = %q, want empty", v)
+	}
+	if _, ok := binaryVersions.Load(binaryVersionKey{bin: bin, pkg: WirePackage, modTime: info.ModTime()}); ok {
+		t.Error("ctx 被取消时不应缓存结果")
+	}
+	if v := BinaryVersion(context.Background(), filepath.Join(t.TempDir(), "wire"), WirePackage); v != "" {
+		t.Errorf("不存在的可执行文件 BinaryVersion() = %q", v)
+	}
+}
+
+func TestGoRunWire(t *testing.T) {
+	args, err := GoRunWire("")
+	if err != nil || !slices.Equal(args, []string{"run", WirePackage}) {
+		t.Errorf("GoRunWire(\"\") = %v, %v", args, err)
+	}
+	args, err = GoRunWire("0.6.0")
+	if err != nil || !slices.Equal(args, []string{"run", WirePackage + "@v0.6.0"}) {
+		t.Errorf("GoRunWire(\"0.6.0\") = %v, %v", args, err)
+	}
+	if _, err := GoRunWire("latest"); err == nil {
+		t.Error("GoRunWire(\"latest\") 应返回错误")
+	}
+}
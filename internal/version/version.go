@@ -1,8 +1,15 @@
 // Package version 管理 gutowire 的版本信息。
-// 版本号可以在编译时通过 -ldflags 设置，或从 Go 模块信息中读取。
+// 版本号、提交与构建时间可以在编译时通过 -ldflags 设置，或从 Go 模块信息中读取。
 package version
 
-import "runtime/debug"
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// MinWireVersion 生成的代码需要的最低 google/wire 版本
+// 生成的 Set 使用的 wire.Struct、wire.FieldsOf 自 v0.3.0 起提供，按构建标签运行（wire gen -tags）需要 v0.5.0.
+const MinWireVersion = "v0.5.0"
 
 // Build-time parameters set via -ldflags
 
@@ -10,6 +17,34 @@ import "runtime/debug"
 // It is set at build time via -ldflags, or defaults to "devel".
 var Version = "devel"
 
+// Commit 构建时的 git 提交，未通过 -ldflags 设置时读取 VCS 构建信息，工作区有修改时带 -dirty 后缀.
+var Commit = ""
+
+// Date 构建时间（RFC 3339），未通过 -ldflags 设置时使用 VCS 构建信息中的提交时间.
+var Date = ""
+
+// Info struct    gutowire 的版本与构建信息.
+type Info struct {
+	Version        string `json:"version"`          // gutowire 版本
+	Commit         string `json:"commit,omitempty"` // 构建时的 git 提交
+	Date           string `json:"date,omitempty"`   // 构建时间
+	GoVersion      string `json:"go_version"`       // 编译使用的 Go 版本
+	Platform       string `json:"platform"`         // 操作系统与架构，如 linux/amd64
+	MinWireVersion string `json:"min_wire_version"` // 生成的代码需要的最低 wire 版本
+}
+
+// Get function    返回当前的版本与构建信息.
+func Get() Info {
+	return Info{
+		Version:        Version,
+		Commit:         Commit,
+		Date:           Date,
+		GoVersion:      runtime.Version(),
+		Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		MinWireVersion: MinWireVersion,
+	}
+}
+
 // init function    初始化版本信息
 // A user may install crush using `go install github.com/charmbracelet/crush@latest`.
 // without -ldflags, in which case the version above is unset. As a workaround
@@ -24,4 +59,26 @@ func init() {
 	if mainVersion != "" && mainVersion != "(devel)" {
 		Version = mainVersion
 	}
+
+	// go build 在 VCS 工作区中构建时记录提交信息，go install 远程模块时没有
+	var revision, modified, vcsTime string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		}
+	}
+	if Commit == "" && revision != "" {
+		Commit = revision
+		if modified == "true" {
+			Commit += "-dirty"
+		}
+	}
+	if Date == "" {
+		Date = vcsTime
+	}
 }
@@ -2,8 +2,10 @@
 package watcher
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,17 +13,23 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/parser"
 	"github.com/spelens-gud/gutowire/internal/runner"
 )
 
-// Watcher struct    文件监听器.
+// Watcher struct    文件监听器
+// 变更的文件在防抖时间内合并，之后只重新解析这些文件并增量生成.
 type Watcher struct {
 	watcher        *fsnotify.Watcher
-	genPath        string
-	opts           []config.Option
+	runner         *runner.Incremental
+	searchPaths    []string
 	ignorePatterns []string
 	debounceTime   time.Duration
-	lastRun        time.Time
+	pending        parser.Set[string] // 等待重新生成的变更文件或目录
+	dirs           parser.Set[string] // 已加入监听列表的目录
+	ignore         *parser.Ignore     // .gitignore 与 .gutowireignore 的规则，为 nil 时不遵循
+	filePrefix     string             // 生成文件名的前缀，如 autowire
+	logger         *slog.Logger
 }
 
 // New function    创建新的文件监听器.
@@ -31,77 +39,208 @@ func New(genPath string, ignorePatterns []string, opts ...config.Option) (*Watch
 		return nil, fmt.Errorf("创建文件监听器失败: %w", err)
 	}
 
+	o := config.NewGenOpt(genPath, opts...)
+	var ignore *parser.Ignore
+	if o.IgnoreFiles {
+		ignore = parser.NewIgnore()
+	}
 	return &Watcher{
 		watcher:        w,
-		genPath:        genPath,
-		opts:           opts,
+		runner:         runner.NewIncremental(genPath, opts...),
+		searchPaths:    o.SearchRoots(),
 		ignorePatterns: ignorePatterns,
 		debounceTime:   500 * time.Millisecond, // 防抖时间
-		lastRun:        time.Now(),
+		pending:        parser.NewSet[string](),
+		dirs:           parser.NewSet[string](),
+		ignore:         ignore,
+		filePrefix:     o.FilePrefix,
+		logger:         o.Logger,
 	}, nil
 }
 
-// Watch method    开始监听.
-func (w *Watcher) Watch(searchPath string) error {
-	log.Printf("> 开始监听目录: %s <", searchPath)
-	log.Printf("! 提示: 修改 .go 文件后将自动重新生成代码")
-	log.Printf("⏸  按 Ctrl+Z 停止监听\n")
+// generation struct    后台进行中的一次增量生成.
+type generation struct {
+	files  []string           // 本次重新解析的文件或目录
+	done   chan error         // 生成结束时接收结果
+	cancel context.CancelFunc // 取消本次生成
+}
+
+// Run method    执行一次完整生成，之后的文件变更只增量生成；ctx 被取消时终止生成.
+func (w *Watcher) Run(ctx context.Context) error {
+	return w.runner.RunContext(ctx)
+}
+
+// Watch method    开始监听，可以同时监听多个目录，未指定时监听配置的搜索路径
+// 监听的目录应与扫描的搜索路径一致，以便变更的文件与扫描结果中的路径对应。
+// 生成在后台进行，期间再次检测到变更时取消进行中的生成，防抖后与新的变更一起重新生成；
+// ctx 被取消（如 Ctrl+C）时终止进行中的生成并停止监听.
+func (w *Watcher) Watch(ctx context.Context, searchPaths ...string) error {
+	if len(searchPaths) == 0 {
+		searchPaths = w.searchPaths
+	}
+	for _, searchPath := range searchPaths {
+		w.logger.Info("> 开始监听目录", "path", searchPath)
+	}
+	w.logger.Info("! 提示: 修改 .go 文件后将自动重新生成代码")
+	w.logger.Info("⏸  按 Ctrl+C 停止监听")
 
 	// 递归添加目录到监听列表
-	if err := w.addRecursive(searchPath); err != nil {
-		return fmt.Errorf("添加监听目录失败: %w", err)
+	for _, searchPath := range searchPaths {
+		if err := w.addRecursive(searchPath); err != nil {
+			return fmt.Errorf("添加监听目录失败: %w", err)
+		}
 	}
 
-	// 处理事件
+	// 处理事件：最后一次变更之后等待防抖时间再重新生成，同一时间只进行一次生成
+	var (
+		debounce <-chan time.Time
+		current  *generation  // 进行中的生成，为 nil 时空闲
+		done     <-chan error // current 的结果，空闲时为 nil
+	)
+	defer func() {
+		if current != nil {
+			current.cancel()
+			<-current.done
+		}
+	}()
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
+
 		case event, ok := <-w.watcher.Events:
 			if !ok {
 				return nil
 			}
-			w.handleEvent(event)
+			if w.handleEvent(event) {
+				debounce = time.After(w.debounceTime)
+				// 进行中的生成已经过期，取消后与新的变更一起重新生成
+				if current != nil {
+					current.cancel()
+				}
+			}
+
+		case <-debounce:
+			debounce = nil
+			// 取消的生成结束后再开始
+			if current == nil {
+				current = w.regenerate(ctx)
+				done = current.done
+			}
+
+		case err := <-done:
+			w.finish(ctx, current, err)
+			current, done = nil, nil
+			if debounce == nil && len(w.pending) > 0 {
+				current = w.regenerate(ctx)
+				done = current.done
+			}
 
 		case err, ok := <-w.watcher.Errors:
 			if !ok {
 				return nil
 			}
-			log.Printf("x 监听错误: %v", err)
+			w.logger.Error("x 监听错误", "error", err)
 		}
 	}
 }
 
-// handleEvent method    处理文件变更事件.
-func (w *Watcher) handleEvent(event fsnotify.Event) {
+// handleEvent method    处理文件变更事件，返回是否记录了需要重新生成的文件或目录.
+func (w *Watcher) handleEvent(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+
+	// 新建或移入的目录：加入监听列表，其中已有的文件需要扫描
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			return w.handleDirCreate(name)
+		}
+	}
+
+	// 删除或移走的目录：已无法 stat，根据监听列表判断
+	if (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)) && w.dirs.Contains(name) {
+		return w.handleDirRemove(name)
+	}
+
 	// 忽略非 Go 文件
-	if !strings.HasSuffix(event.Name, ".go") {
-		return
+	if !strings.HasSuffix(name, ".go") {
+		return false
 	}
 
-	// 忽略生成的文件
-	if w.shouldIgnore(event.Name) {
-		return
+	// 忽略生成的文件与忽略文件中匹配的文件
+	if w.shouldIgnore(name) || w.ignored(name, false) {
+		return false
 	}
 
-	// 只处理写入和创建事件
-	if event.Op&fsnotify.Write != fsnotify.Write && event.Op&fsnotify.Create != fsnotify.Create {
-		return
+	// 处理写入、创建、删除与重命名事件（删除与移走的文件会移除其组件）
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
+		!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	w.logger.Info("> 检测到文件变更", "file", name, "op", event.Op.String())
+	w.pending.Add(name)
+	return true
+}
+
+// handleDirCreate method    将新建的目录加入监听列表并记录为待扫描.
+func (w *Watcher) handleDirCreate(dir string) bool {
+	if skipDir(dir) || w.ignored(dir, true) {
+		return false
+	}
+	if err := w.addRecursive(dir); err != nil {
+		w.logger.Warn("添加监听目录失败", "dir", dir, "error", err)
 	}
+	w.logger.Info("> 检测到新目录", "dir", dir)
+	w.pending.Add(dir)
+	return true
+}
 
-	// 防抖：避免短时间内多次触发
-	now := time.Now()
-	if now.Sub(w.lastRun) < w.debounceTime {
-		return
+// handleDirRemove method    将删除或移走的目录（及其子目录）移出监听列表并记录为待扫描
+// 目录已不存在时，重新生成会移除其下所有文件的组件.
+func (w *Watcher) handleDirRemove(dir string) bool {
+	prefix := dir + string(filepath.Separator)
+	for d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// 目录删除时监听已被自动移除，这里忽略错误
+			_ = w.watcher.Remove(d)
+			delete(w.dirs, d)
+		}
 	}
-	w.lastRun = now
+	w.logger.Info("> 检测到目录移除", "dir", dir)
+	w.pending.Add(dir)
+	return true
+}
 
-	log.Printf("\n> 检测到文件变更: %s", event.Name)
-	log.Printf(">>>>>>> 正在重新生成代码 >>>>>>\n")
+// regenerate method    在后台重新解析防抖期间变更的文件并增量生成.
+func (w *Watcher) regenerate(ctx context.Context) *generation {
+	files := parser.SortedKeys(w.pending)
+	w.pending = parser.NewSet[string]()
 
-	// 执行代码生成
-	if err := runner.RunAutoWire(w.genPath, w.opts...); err != nil {
-		log.Printf("x 生成失败: %v\n", err)
-	} else {
-		log.Printf("✓ 生成成功\n")
+	w.logger.Info(">>>>>>> 正在重新生成代码 >>>>>>", "files", len(files))
+
+	ctx, cancel := context.WithCancel(ctx)
+	g := &generation{files: files, done: make(chan error, 1), cancel: cancel}
+	go func() {
+		defer cancel()
+		g.done <- w.runner.RunContext(ctx, files...)
+	}()
+	return g
+}
+
+// finish method    处理一次生成的结果：因新的变更被取消时将其文件放回待生成列表，与新的变更一起重新生成.
+func (w *Watcher) finish(ctx context.Context, g *generation, err error) {
+	switch {
+	case err == nil:
+		w.logger.Info("✓ 生成成功")
+	case ctx.Err() != nil:
+		// 停止监听，不再重新生成
+	case errors.Is(err, context.Canceled):
+		w.logger.Info("检测到新的变更，已取消进行中的生成", "files", len(g.files))
+		for _, f := range g.files {
+			w.pending.Add(f)
+		}
+	default:
+		w.logger.Error("x 生成失败", "error", err)
 	}
 }
 
@@ -110,7 +249,7 @@ func (w *Watcher) shouldIgnore(path string) bool {
 	base := filepath.Base(path)
 
 	// 忽略生成的文件
-	if strings.HasPrefix(base, "autowire_") || base == "wire_gen.go" {
+	if parser.HasNamePrefix(base, w.filePrefix+"_") || parser.NameEqual(base, "wire_gen.go") {
 		return true
 	}
 
@@ -121,6 +260,9 @@ func (w *Watcher) shouldIgnore(path string) bool {
 
 	// 检查自定义忽略模式
 	for _, pattern := range w.ignorePatterns {
+		if parser.CaseInsensitiveFS {
+			pattern, base = strings.ToLower(pattern), strings.ToLower(base)
+		}
 		matched, _ := filepath.Match(pattern, base)
 		if matched {
 			return true
@@ -130,6 +272,11 @@ func (w *Watcher) shouldIgnore(path string) bool {
 	return false
 }
 
+// ignored method    判断路径是否被 .gitignore 或 .gutowireignore 忽略.
+func (w *Watcher) ignored(path string, isDir bool) bool {
+	return w.ignore != nil && w.ignore.Match(path, isDir)
+}
+
 // addRecursive method    递归添加目录到监听列表.
 func (w *Watcher) addRecursive(root string) error {
 	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -142,9 +289,8 @@ func (w *Watcher) addRecursive(root string) error {
 			return nil
 		}
 
-		// 跳过隐藏目录和特殊目录
-		base := filepath.Base(path)
-		if strings.HasPrefix(base, ".") || base == "vendor" || base == "testdata" {
+		// 跳过隐藏目录、特殊目录以及忽略文件中匹配的目录
+		if skipDir(path) || (path != root && w.ignored(path, true)) {
 			return filepath.SkipDir
 		}
 
@@ -152,11 +298,19 @@ func (w *Watcher) addRecursive(root string) error {
 		if err := w.watcher.Add(path); err != nil {
 			return fmt.Errorf("添加监听目录 %s 失败: %w", path, err)
 		}
+		w.dirs.Add(filepath.Clean(path))
 
 		return nil
 	})
 }
 
+// skipDir function    判断是否跳过目录：隐藏目录、vendor、testdata 以及 node_modules、bazel-out 等构建产物.
+func skipDir(path string) bool {
+	base := filepath.Base(path)
+	return (strings.HasPrefix(base, ".") && base != "." && base != "..") || base == "vendor" || base == "testdata" ||
+		base == "node_modules" || strings.HasPrefix(base, "bazel-")
+}
+
 // Close method    关闭监听器.
 func (w *Watcher) Close() error {
 	return w.watcher.Close()
@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/logger"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+func TestHandleEvent(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := filepath.Join(dir, "pkg")
+	if err := os.MkdirAll(pkg, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(filepath.Join(dir, "wire"), []string{"*.gen.go"}, config.WithCache(false),
+		config.WithLogger(logger.Discard()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	if err := w.addRecursive(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// 处理收到的事件，直到所有路径都记录为待重新生成
+	await := func(paths ...string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			missing := false
+			for _, p := range paths {
+				missing = missing || !w.pending.Contains(p)
+			}
+			if !missing {
+				w.pending = parser.NewSet[string]()
+				return
+			}
+			select {
+			case event := <-w.watcher.Events:
+				w.handleEvent(event)
+			case err := <-w.watcher.Errors:
+				t.Fatal(err)
+			case <-timeout:
+				t.Fatalf("pending = %v, want %v", parser.SortedKeys(w.pending), paths)
+			}
+		}
+	}
+	write := func(name string) string {
+		t.Helper()
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.WriteFile(file, []byte("package pkg\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	// 新建文件；生成的文件、测试文件、匹配忽略模式的文件与非 Go 文件被忽略
+	for _, name := range []string{"autowire_a.go", "wire_gen.go", "a_test.go", "a.gen.go", "README.md"} {
+		write("pkg/" + name)
+	}
+	a := write("pkg/a.go")
+	await(a)
+	for _, name := range []string{"autowire_a.go", "wire_gen.go", "a_test.go", "a.gen.go", "README.md"} {
+		if file := filepath.Join(pkg, name); w.pending.Contains(file) {
+			t.Errorf("%s 应该被忽略", name)
+		}
+	}
+
+	// 重命名文件：旧文件移除组件，新文件重新解析
+	b := filepath.Join(pkg, "b.go")
+	if err := os.Rename(a, b); err != nil {
+		t.Fatal(err)
+	}
+	await(a, b)
+
+	// 删除文件
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	await(b)
+
+	// 新建目录：加入监听列表，其中的文件随之扫描
+	sub := filepath.Join(dir, "sub")
+	for _, d := range []string{sub, filepath.Join(sub, "inner")} {
+		if err := os.Mkdir(d, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		await(d)
+	}
+	if !w.dirs.Contains(sub) || !w.dirs.Contains(filepath.Join(sub, "inner")) {
+		t.Errorf("dirs = %v, want %s", parser.SortedKeys(w.dirs), sub)
+	}
+	c := write("sub/inner/c.go")
+	await(c)
+
+	// 移走目录：旧目录（及其子目录）移出监听列表，新目录加入监听列表
+	moved := filepath.Join(dir, "moved")
+	if err := os.Rename(sub, moved); err != nil {
+		t.Fatal(err)
+	}
+	await(sub, moved)
+	if w.dirs.Contains(sub) || w.dirs.Contains(filepath.Join(sub, "inner")) || !w.dirs.Contains(moved) {
+		t.Errorf("dirs = %v", parser.SortedKeys(w.dirs))
+	}
+
+	// 删除目录
+	if err := os.RemoveAll(moved); err != nil {
+		t.Fatal(err)
+	}
+	await(moved)
+	if w.dirs.Contains(moved) || w.dirs.Contains(filepath.Join(moved, "inner")) {
+		t.Errorf("dirs = %v", parser.SortedKeys(w.dirs))
+	}
+}
@@ -0,0 +1,358 @@
+// Package annotations 解析 @autowire 注解，与 gutowire 生成代码时使用同一个解析器，
+// 供 linter、IDE 插件等工具识别注解、检查语法以及补全参数。
+//
+// 注解的语法为 标记[.后缀](参数, ...)，如 @autowire(set=db,Store:ptr,priority=10)、@autowire.init(name=ServerApp,ctx)；
+// 参数以逗号分隔，双引号中的逗号不拆分；key=value 为带值参数，不带值的参数为标记（如 ctx）或绑定的接口（如 Store:ptr）.
+//
+// 本包导出的类型与函数保持向后兼容.
+package annotations
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/scanner"
+	"go/types"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultTag 默认的注解标记.
+const DefaultTag = "@autowire"
+
+// 注解后缀.
+const (
+	SuffixInit   = "init"   // @autowire.init 初始化入口
+	SuffixConfig = "config" // @autowire.config 配置注入
+	SuffixValue  = "value"  // @autowire.value 包级变量
+	SuffixMock   = "mock"   // @autowire.mock 测试替身
+	SuffixSet    = "set"    // @autowire.set 组合 Set
+	SuffixRaw    = "raw"    // @autowire.raw 自定义提供者表达式
+	SuffixEnv    = "env"    // @autowire.env 读取环境变量
+)
+
+// 接口参数的绑定方式标记，如 Store:ptr.
+const (
+	BindPtr   = "ptr"   // wire.Bind(new(I), new(*T))
+	BindValue = "value" // wire.Bind(new(I), new(T))
+)
+
+// scope= 参数的取值.
+const (
+	ScopeSingleton = "singleton" // 单例，默认行为
+	ScopeFactory   = "factory"   // 提供 <Type>Factory 工厂函数类型，每次调用构造新的实例
+)
+
+// Suffixes 注解支持的后缀.
+var Suffixes = []string{SuffixInit, SuffixConfig, SuffixValue, SuffixMock, SuffixSet, SuffixRaw, SuffixEnv}
+
+// EnvTypes @autowire.env 支持解析的类型（type= 参数的取值）.
+var EnvTypes = []string{"string", "bool", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16",
+	"uint32", "uint64", "float32", "float64", "time.Duration"}
+
+// ValueOptions 必须带值的参数.
+var ValueOptions = []string{"set", "new", "priority", "name", "tag", "qualifier", "of", "out", "fields", "exclude",
+	"impl", "for", "include", "scope", "group", "deprecated", "args", "expr", "type", "default"}
+
+// FlagOptions 不带值的参数：与 .init、.config 后缀等价的 init、config，按值绑定接口的 value，
+// 在整个包中查找生命周期方法的 lifecycle，以及初始化函数接收 context.Context 的 ctx.
+var FlagOptions = []string{"init", "config", "value", "lifecycle", "ctx"}
+
+// ErrNotAnnotation 文本不是注解（不以注解标记开头，或标记之后紧跟其他单词，如 @autowired）.
+var ErrNotAnnotation = errors.New("不是注解")
+
+// interfacePattern 接口参数的格式：接口名或 包名.接口名.
+var interfacePattern = regexp.MustCompile(`^[A-Za-z_]\w*(\.[A-Za-z_]\w*)?$`)
+
+// implPattern impl= 参数的格式：完整路径形式的接口，如 github.com/foo/bar.Store.
+var implPattern = regexp.MustCompile(`^[\w.~-]+(/[\w.~-]+)*\.[A-Za-z_]\w*$`)
+
+// buildTagPattern tag= 参数的格式：单个构建标签.
+var buildTagPattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// reservedArgName 生成的初始化函数使用的参数名：ctx 参数与配置参数 c0、c1...
+var reservedArgName = regexp.MustCompile(`^(ctx|c\d+)$`)
+
+// Annotation struct    解析后的单行注解.
+type Annotation struct {
+	Tag     string   // 注解标记，如 @autowire
+	Suffix  string   // 后缀，如 init、config，没有后缀时为空
+	Options []Option // 参数，按书写顺序排列
+}
+
+// Option struct    注解参数.
+type Option struct {
+	Key      string // 参数名，接口参数为接口名（可以带 :ptr、:value 标记）
+	Value    string // 参数值，带双引号的值保留引号，不带值的参数为空
+	HasValue bool   // 是否为 key=value 形式
+}
+
+// IsInterface method    判断是否为接口参数：不带值且不是标记参数.
+func (o Option) IsInterface() bool {
+	return !o.HasValue && !slices.Contains(FlagOptions, o.Key)
+}
+
+// Interface method    返回接口参数的接口名与绑定方式（ptr、value，没有标记时为空）.
+func (o Option) Interface() (name, kind string) {
+	name, kind, _ = strings.Cut(o.Key, ":")
+	return name, kind
+}
+
+// Get method    返回参数的值，参数重复时返回最后一个；set 参数返回合并后的值，如 set=db,set=app 返回 db|app.
+func (a Annotation) Get(key string) (string, bool) {
+	value, ok := a.Map()[key]
+	return value, ok
+}
+
+// Sets method    返回注解注册到的 Set 名称（set=db|app 与重复的 set 参数），按书写顺序排列.
+func (a Annotation) Sets() []string {
+	return SplitList(a.Map()["set"])
+}
+
+// Interfaces method    返回绑定的接口参数，按书写顺序排列，如 @autowire(set=db,Store,Cache:value) 返回 Store、Cache:value.
+func (a Annotation) Interfaces() []Option {
+	var itfs []Option
+	for _, o := range a.Options {
+		if o.IsInterface() {
+			itfs = append(itfs, o)
+		}
+	}
+	return itfs
+}
+
+// Map method    返回参数名到值的映射，与 gutowire 生成时使用的参数相同：
+// 重复的 set 参数合并为 db|app，其他参数重复时取最后一个，不带值的参数值为空.
+func (a Annotation) Map() map[string]string {
+	options := make(map[string]string, len(a.Options))
+	for _, o := range a.Options {
+		v := o.Value
+		// 重复的 set 参数与 set=db|app 等价
+		if prev := options[o.Key]; o.Key == "set" && prev != "" {
+			v = prev + "|" + v
+		}
+		options[o.Key] = v
+	}
+	return options
+}
+
+// Parse function    使用默认标记解析单行注解，并检查后缀与参数
+// doc 为一行注释，可以带 // 前缀；不是注解时返回 ErrNotAnnotation，语法错误时同时返回已解析的内容.
+func Parse(doc string) (Annotation, error) {
+	return ParseTag(DefaultTag, doc)
+}
+
+// ParseTag function    与 Parse 相同，使用自定义注解标记（如 @inject、//go:autowire）.
+func ParseTag(tag, doc string) (Annotation, error) {
+	a, err := ParseLoose(tag, doc)
+	if err != nil {
+		return a, err
+	}
+	return a, a.Validate()
+}
+
+// ParseLoose function    只解析注解的结构（标记、后缀、括号与参数），不检查后缀与参数是否有效
+// gutowire 生成代码时使用该函数，无效的参数被忽略，由 Validate 给出提示.
+func ParseLoose(tag, doc string) (Annotation, error) {
+	tag = NormalizeTag(tag)
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(doc), "//"))
+	rest, ok := strings.CutPrefix(text, tag)
+	if !ok || (rest != "" && (rest[0] == '_' || unicode.IsLetter(rune(rest[0])) || unicode.IsDigit(rune(rest[0])))) {
+		return Annotation{}, ErrNotAnnotation
+	}
+
+	a := Annotation{Tag: tag}
+	if strings.HasPrefix(rest, ".") {
+		suffix, _, _ := strings.Cut(rest[1:], "(")
+		a.Suffix = suffix
+		rest = strings.TrimPrefix(rest, "."+suffix)
+	}
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return a, fmt.Errorf("注解参数需要写在括号中，如 %s(set=xxx)", tag)
+	}
+	for _, s := range SplitOptions(rest[1 : len(rest)-1]) {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(s, "=")
+		a.Options = append(a.Options, Option{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value),
+			HasValue: hasValue})
+	}
+	return a, nil
+}
+
+// Validate method    检查注解的后缀与参数，返回第一个问题.
+func (a Annotation) Validate() error {
+	if a.Suffix != "" && !slices.Contains(Suffixes, a.Suffix) {
+		return fmt.Errorf("未知的注解后缀 .%s（可选 .init、.config、.value、.mock、.set、.raw、.env）", a.Suffix)
+	}
+	if a.Suffix == SuffixSet {
+		if options := a.Map(); options["name"] == "" || options["include"] == "" {
+			return fmt.Errorf("组合 Set 需要指定 name 与 include 参数，如 %s.set(name=app,include=db|http)", a.Tag)
+		}
+	}
+	if a.Suffix == SuffixRaw {
+		if options := a.Map(); options["expr"] == "" {
+			return fmt.Errorf("自定义提供者需要指定 expr 参数，如 %s.raw(set=x,expr=\"pkg.ProvideThing(DefaultConfig)\")", a.Tag)
+		}
+	}
+	if a.Suffix == SuffixEnv {
+		if options := a.Map(); options["name"] == "" {
+			return fmt.Errorf("环境变量注解需要指定 name 参数，如 %s.env(set=x,name=PORT,default=8080)", a.Tag)
+		}
+	}
+	for _, o := range a.Options {
+		if err := o.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate method    检查单个参数.
+func (o Option) validate() error {
+	key, value := o.Key, o.Value
+	switch {
+	case key == "":
+		return fmt.Errorf("参数 %q 缺少名称", "="+value)
+	case strings.Contains(value, "=") && !strings.HasPrefix(value, `"`):
+		return fmt.Errorf("参数 %q 格式错误，应为 key=value", key+"="+value)
+	case slices.Contains(ValueOptions, key) && value == "":
+		return fmt.Errorf("参数 %s 缺少值", key)
+	case key == "scope" && value != ScopeSingleton && value != ScopeFactory:
+		return fmt.Errorf("无效的作用域 %s（可选 %s、%s）", value, ScopeSingleton, ScopeFactory)
+	case key == "priority" && !isInteger(value):
+		return fmt.Errorf("参数 priority 需要为整数: %s", value)
+	case key == "tag" && !ValidBuildTag(value):
+		return fmt.Errorf("无效的构建标签: %s", value)
+	case key == "impl" && !validImpl(value):
+		return fmt.Errorf("impl 参数需要为完整路径形式的接口，如 github.com/foo/bar.Store: %s", value)
+	case key == "args":
+		if _, err := ParseArgs(value); err != nil {
+			return fmt.Errorf("无效的 args 参数，%w", err)
+		}
+	case key == "expr":
+		if _, err := ParseExpr(value); err != nil {
+			return fmt.Errorf("无效的 expr 参数，%w", err)
+		}
+	case key == "type" && !slices.Contains(EnvTypes, value):
+		return fmt.Errorf("不支持的环境变量类型 %s（可选 %s）", value, strings.Join(EnvTypes, "、"))
+	case o.HasValue && !slices.Contains(ValueOptions, key) && !slices.Contains(FlagOptions, key):
+		return fmt.Errorf("未知的参数 %s", key)
+	case !o.HasValue && !validBindKind(key):
+		return fmt.Errorf("无效的绑定方式 %s（可选 %s、%s）", key, BindPtr, BindValue)
+	case o.IsInterface() && !interfacePattern.MatchString(bindName(key)):
+		return fmt.Errorf("无效的接口名 %s", key)
+	}
+	return nil
+}
+
+// NormalizeTag function    规范化注解标记，去掉注释前缀，如 //go:autowire 返回 go:autowire.
+func NormalizeTag(tag string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "//"))
+}
+
+// SplitOptions function    按逗号拆分注解参数，双引号中的逗号不拆分，如 deprecated="use NewV2, see #12".
+func SplitOptions(content string) []string {
+	var items []string
+	start, quoted := 0, false
+	for i := range len(content) {
+		switch {
+		case content[i] == '"':
+			quoted = !quoted
+		case content[i] == ',' && !quoted:
+			items = append(items, content[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, content[start:])
+}
+
+// SplitList function    按 | 拆分参数值（如 set=db|app、impl="a.Store|b.Cache"），去掉空白与空项.
+func SplitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ValidBuildTag function    判断 tag= 参数是否为有效的构建标签.
+func ValidBuildTag(tag string) bool {
+	return buildTagPattern.MatchString(tag)
+}
+
+// ParseArgs function    解析 args= 参数中的参数声明，如 opts ...Option 或 name string, port int
+// 每个参数都需要名称，只有最后一个参数可以是可变参数；value 可以带双引号.
+func ParseArgs(value string) ([]*ast.Field, error) {
+	expr, err := goparser.ParseExpr("func(" + strings.Trim(value, `"`) + ")")
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return nil, fmt.Errorf("参数声明格式错误（%s）: %s", list[0].Msg, value)
+	}
+	ft, ok := expr.(*ast.FuncType)
+	if err != nil || !ok || len(ft.Params.List) == 0 {
+		return nil, fmt.Errorf("参数声明格式错误: %s", value)
+	}
+	for _, field := range ft.Params.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("参数需要名称，如 opts ...Option: %s", types.ExprString(field.Type))
+		}
+		for _, name := range field.Names {
+			if reservedArgName.MatchString(name.Name) {
+				return nil, fmt.Errorf("参数名 %s 与生成的 ctx 参数或配置参数冲突", name.Name)
+			}
+		}
+	}
+	return ft.Params.List, nil
+}
+
+// ParseExpr function    解析 expr= 参数中的 Go 表达式，如 mypkg.ProvideThing(DefaultConfig)；value 可以带双引号.
+func ParseExpr(value string) (ast.Expr, error) {
+	expr, err := goparser.ParseExpr(Unquote(value))
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return nil, fmt.Errorf("表达式格式错误（%s）: %s", list[0].Msg, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("表达式格式错误: %s", value)
+	}
+	return expr, nil
+}
+
+// Unquote function    去掉参数值两端的双引号，值中的引号保持不变，如 "pkg.New(`x`)" 返回 pkg.New(`x`).
+func Unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// validImpl function    判断 impl= 参数中以 | 分隔的每个接口是否为完整路径形式（可以带引号）.
+func validImpl(value string) bool {
+	items := SplitList(strings.Trim(value, `"`))
+	return len(items) > 0 && !slices.ContainsFunc(items, func(itf string) bool {
+		return !validBindKind(itf) || !implPattern.MatchString(bindName(itf))
+	})
+}
+
+// validBindKind function    判断接口参数的绑定方式标记是否有效，没有标记时有效.
+func validBindKind(s string) bool {
+	_, kind, ok := strings.Cut(s, ":")
+	return !ok || kind == BindPtr || kind == BindValue
+}
+
+// bindName function    返回去掉绑定方式标记的接口参数.
+func bindName(s string) string {
+	itf, _, _ := strings.Cut(s, ":")
+	return itf
+}
+
+// isInteger function    判断字符串是否为整数.
+func isInteger(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
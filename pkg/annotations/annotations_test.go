@@ -0,0 +1,98 @@
+package annotations
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	a, err := Parse(`// @autowire(set=db,set=app, Store, Cache:value,deprecated="use NewV2, see #12",priority=10)`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if a.Tag != DefaultTag || a.Suffix != "" {
+		t.Errorf("Parse() tag = %q, suffix = %q", a.Tag, a.Suffix)
+	}
+	if got := a.Sets(); !reflect.DeepEqual(got, []string{"db", "app"}) {
+		t.Errorf("Sets() = %v", got)
+	}
+	if got, _ := a.Get("deprecated"); got != `"use NewV2, see #12"` {
+		t.Errorf("Get(deprecated) = %q", got)
+	}
+	var itfs []string
+	for _, o := range a.Interfaces() {
+		name, kind := o.Interface()
+		itfs = append(itfs, name+"/"+kind)
+	}
+	if want := []string{"Store/", "Cache/value"}; !reflect.DeepEqual(itfs, want) {
+		t.Errorf("Interfaces() = %v, want %v", itfs, want)
+	}
+
+	a, err = ParseTag("//go:autowire", "go:autowire.init(name=ServerApp,ctx,args=\"opts ...Option\")")
+	if err != nil || a.Suffix != SuffixInit || len(a.Options) != 3 || a.Options[1] != (Option{Key: "ctx"}) {
+		t.Errorf("ParseTag() = %+v, %v", a, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string // 错误信息包含的内容，空字符串表示不是注解
+	}{
+		{doc: "普通注释"},
+		{doc: "@autowired(set=db)"},
+		{doc: "@autowire set=db", want: "括号"},
+		{doc: "@autowire.bean(set=db)", want: "未知的注解后缀 .bean"},
+		{doc: "@autowire.set(name=app)", want: "name 与 include"},
+		{doc: "@autowire(set=)", want: "参数 set 缺少值"},
+		{doc: "@autowire(scope=request)", want: "无效的作用域"},
+		{doc: "@autowire(priority=high)", want: "整数"},
+		{doc: "@autowire(tag=a-b)", want: "构建标签"},
+		{doc: "@autowire(impl=Store)", want: "完整路径"},
+		{doc: "@autowire.init(args=\"ctx int\")", want: "冲突"},
+		{doc: "@autowire(color=red)", want: "未知的参数 color"},
+		{doc: "@autowire(Store:ref)", want: "绑定方式"},
+		{doc: "@autowire(a.b.C)", want: "接口名"},
+		{doc: "@autowire.raw(set=db)", want: "expr"},
+		{doc: "@autowire.raw(set=db,expr=\"db.Open(\")", want: "无效的 expr 参数"},
+		{doc: "@autowire.env(set=db,default=8080)", want: "name"},
+		{doc: "@autowire.env(name=PORT,type=uint128)", want: "不支持的环境变量类型"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.doc)
+		if tt.want == "" {
+			if !errors.Is(err, ErrNotAnnotation) {
+				t.Errorf("Parse(%q) error = %v, want ErrNotAnnotation", tt.doc, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
+
+func TestParseLoose(t *testing.T) {
+	// 只解析结构，未知的参数保留给调用方处理
+	a, err := ParseLoose(DefaultTag, "@autowire(set=db,color=red)")
+	if err != nil || a.Map()["color"] != "red" {
+		t.Errorf("ParseLoose() = %+v, %v", a, err)
+	}
+	if err := a.Validate(); err == nil {
+		t.Error("Validate() error = nil, want unknown option")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	fields, err := ParseArgs(`"name string, opts ...Option"`)
+	if err != nil || len(fields) != 2 {
+		t.Fatalf("ParseArgs() = %v, %v", fields, err)
+	}
+	for _, value := range []string{"string", "opts ...Option, name string", "c0 int", "func("} {
+		if _, err := ParseArgs(value); err == nil {
+			t.Errorf("ParseArgs(%q) error = nil", value)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package gutowire
+
+import (
+	"context"
+	"slices"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/runner"
+)
+
+// Scanner struct    注解扫描器，只读取源码，不写入任何文件.
+type Scanner struct {
+	genPath string
+	opts    []Option
+}
+
+// NewScanner function    创建注解扫描器
+//
+// genPath: 生成文件的目标目录，扫描时会跳过导入该目录的包以避免循环依赖
+// opts: 扫描选项，零值表示使用默认配置.
+func NewScanner(genPath string, opts ScanOptions) *Scanner {
+	return &Scanner{genPath: genPath, opts: opts.options()}
+}
+
+// Scan method    扫描 @autowire 注解并返回扫描模型.
+func (s *Scanner) Scan() (*Model, error) {
+	return Scan(s.genPath, s.opts...)
+}
+
+// ScanContext method    与 Scan 相同，ctx 被取消时终止扫描并返回 ctx 的错误.
+func (s *Scanner) ScanContext(ctx context.Context) (*Model, error) {
+	return Scan(s.genPath, withContext(ctx, s.opts)...)
+}
+
+// ScanFunc method    扫描 @autowire 注解，每解析完一个文件即将其中的组件交给 fn，不构建完整的扫描模型
+// 适合文档生成、规则检查等只需要逐个读取组件的工具，内存占用不随项目规模增长。
+// 与 Scan 的区别：组件不会按 Set 汇总与排序，带注解的接口不会自动绑定到实现（Bindings 只包含注解中声明的接口）。
+//
+// ctx: 取消时停止扫描并返回 ctx 的错误
+// root: 扫描的目录，为空时使用配置的搜索路径
+// fn: 接收组件的回调，不会被并发调用；返回错误时停止扫描并原样返回该错误.
+func (s *Scanner) ScanFunc(ctx context.Context, root string, fn func(Element) error) error {
+	var roots []string
+	if root != "" {
+		roots = []string{root}
+	}
+	return runner.Stream(ctx, s.genPath, roots, func(e generator.Element) error {
+		return fn(newElement(e))
+	}, s.opts...)
+}
+
+// CheckResult struct    校验结果.
+type CheckResult struct {
+	Errors   []error  // 会导致生成或 wire 失败的问题
+	Warnings []string // 不影响生成的提示
+}
+
+// OK method    判断校验是否通过（没有错误）.
+func (r *CheckResult) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Generator struct    Wire 配置文件生成器，生成 autowire_*.go（fx 后端生成 fx 模块），不调用 wire 命令.
+type Generator struct {
+	genPath string
+	opts    []Option
+}
+
+// NewGenerator function    创建生成器
+//
+// genPath: 生成文件的目标目录
+// opts: 生成选项，零值表示使用默认配置.
+func NewGenerator(genPath string, opts GenerateOptions) *Generator {
+	return &Generator{genPath: genPath, opts: opts.options()}
+}
+
+// Generate method    扫描注解并生成 Wire 配置文件，生成前检查注解语法与依赖图.
+func (g *Generator) Generate() error {
+	return runner.Generate(g.genPath, g.opts...)
+}
+
+// GenerateContext method    与 Generate 相同，ctx 被取消时终止扫描与写入并返回 ctx 的错误
+// 已经写入的文件保留，下次生成时覆盖.
+func (g *Generator) GenerateContext(ctx context.Context) error {
+	return runner.Generate(g.genPath, withContext(ctx, g.opts)...)
+}
+
+// Check method    执行扫描、注解解析与依赖图校验，不写入任何文件；扫描本身失败时返回 error.
+func (g *Generator) Check() (*CheckResult, error) {
+	return g.CheckContext(context.Background())
+}
+
+// CheckContext method    与 Check 相同，ctx 被取消时终止扫描并返回 ctx 的错误.
+func (g *Generator) CheckContext(ctx context.Context) (*CheckResult, error) {
+	result, err := runner.Check(g.genPath, withContext(ctx, g.opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckResult{Errors: result.Errors, Warnings: result.Warnings}, nil
+}
+
+// Runner struct    完整的自动装配：生成 Wire 配置文件并调用 wire 生成 wire_gen.go
+// 同一个 Runner 多次运行时复用上次的扫描结果，只重新解析变更的文件.
+type Runner struct {
+	inc *runner.Incremental
+}
+
+// NewRunner function    创建自动装配运行器
+//
+// genPath: 生成文件的目标目录
+// opts: 运行选项，零值表示使用默认配置.
+func NewRunner(genPath string, opts RunOptions) *Runner {
+	return &Runner{inc: runner.NewIncremental(genPath, opts.options()...)}
+}
+
+// Run method    执行一次自动装配
+// changed 为变更（含新建、删除）的文件；首次运行或未指定文件时完整扫描.
+func (r *Runner) Run(changed ...string) error {
+	return r.inc.Run(changed...)
+}
+
+// RunContext method    与 Run 相同，ctx 被取消时终止本次运行（包括 wire 命令）并返回 ctx 的错误
+// 适合在新的变更到来时取消已经过期的运行.
+func (r *Runner) RunContext(ctx context.Context, changed ...string) error {
+	return r.inc.RunContext(ctx, changed...)
+}
+
+// withContext function    在选项之后追加 ctx，不修改原有的选项列表.
+func withContext(ctx context.Context, opts []Option) []Option {
+	return append(slices.Clip(opts), WithContext(ctx))
+}
@@ -0,0 +1,75 @@
+// Package gutowire 是 gutowire 对外暴露的公共 API。
+// 第三方代码生成器（如 HTTP 路由注册）可以通过本包复用 @autowire 注解扫描能力，
+// 获取扫描模型而无需执行 Wire 配置生成；构建工具（mage、任务运行器、其他生成器）
+// 可以通过 Scanner、Generator 与 Runner 直接嵌入 gutowire，而无需调用命令行。
+//
+// 本包导出的类型与函数保持向后兼容，internal 下的包不提供兼容性保证。
+package gutowire
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+	"github.com/spelens-gud/gutowire/internal/runner"
+)
+
+// Option 配置函数类型，用于调整扫描与生成行为.
+type Option = config.Option
+
+// WithPkg function    设置生成文件的包名.
+func WithPkg(pkg string) Option {
+	return config.WithPkg(pkg)
+}
+
+// WithSearchPath function    设置依赖搜索路径，不设置时使用 go.mod 所在目录.
+func WithSearchPath(path string) Option {
+	return config.WithSearchPath(path)
+}
+
+// WithCache function    设置是否启用缓存.
+func WithCache(enable bool) Option {
+	return config.WithCache(enable)
+}
+
+// WithSearchPaths function    添加多个依赖搜索路径，各目录中的组件合并生成.
+func WithSearchPaths(paths ...string) Option {
+	return config.WithSearchPaths(paths...)
+}
+
+// WithExcludeDirs function    设置扫描时排除的目录列表，支持相对模块根目录的 glob.
+func WithExcludeDirs(dirs []string) Option {
+	return config.WithExcludeDirs(dirs)
+}
+
+// WithIncludeOnly function    只扫描指定的目录，支持相对模块根目录的 glob.
+func WithIncludeOnly(dirs ...string) Option {
+	return config.WithIncludeOnly(dirs...)
+}
+
+// WithTag function    设置注解标记，替代默认的 @autowire，如 @inject.
+func WithTag(tag string) Option {
+	return config.WithTag(tag)
+}
+
+// WithLogger function    设置日志器，gutowire 不会修改标准库 log 的全局状态.
+func WithLogger(l *slog.Logger) Option {
+	return config.WithLogger(l)
+}
+
+// WithContext function    设置 ctx，取消时终止进行中的扫描、写入与 wire 命令并返回 ctx 的错误.
+func WithContext(ctx context.Context) Option {
+	return config.WithContext(ctx)
+}
+
+// Scan function    扫描 @autowire 注解并返回扫描模型，不会写入任何文件
+//
+// genPath: 生成文件的目标目录，扫描时会跳过导入该目录的包以避免循环依赖
+// opts: 可选配置，如搜索路径、排除目录等
+func Scan(genPath string, opts ...Option) (*Model, error) {
+	sc, err := runner.Scan(genPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newModel(sc.ElementMap), nil
+}
@@ -0,0 +1,27 @@
+package gutowire
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/spelens-gud/gutowire/internal/iwanta"
+)
+
+// ErrGenerated IMake 首次运行时生成了初始化文件，重新运行测试即可获取实例.
+var ErrGenerated = iwanta.ErrGenerated
+
+// IMake function    获取类型 T（具名类型的指针或接口）的实例，不修改调用文件也不退出进程，只能在测试文件中调用
+// 首次运行时在调用文件所在目录生成只参与测试编译的 <type>_imake_test.go 并返回 ErrGenerated，
+// 重新运行测试后在同一个进程中调用初始化函数，返回的实例与 cleanup 与 wire 生成的初始化函数完全相同.
+// 初始化函数需要 @autowire.config 参数时返回错误.
+//
+// opts: 生成初始化函数时的扫描与生成选项，如搜索路径、排除目录等.
+func IMake[T any](ctx context.Context, opts ...Option) (T, func(), error) {
+	_, callFile, _, _ := runtime.Caller(1)
+	return iwanta.IMake[T](ctx, callFile, opts...)
+}
+
+// Register function    注册类型 T 的初始化函数，由 IMake 生成的 *_imake_test.go 调用，一般不需要直接使用.
+func Register[T any](maker func() (T, func(), error)) {
+	iwanta.Register(maker)
+}
@@ -0,0 +1,110 @@
+package gutowire
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+	"github.com/spelens-gud/gutowire/internal/parser"
+)
+
+// Model struct    扫描模型，包含按 Set 分组的全部注解组件.
+type Model struct {
+	Sets []Set // 按名称排序的 Set 列表
+}
+
+// Set struct    表示一个 Wire Set 及其包含的组件.
+type Set struct {
+	Name     string    // Set 名称，如 animals
+	Elements []Element // 按包路径和名称排序的组件列表
+}
+
+// Element struct    表示一个带 @autowire 注解的组件.
+type Element struct {
+	Name        string   // 类型或函数名称，如 Zoo、NewCat
+	Pkg         string   // 所在包名
+	PkgPath     string   // 完整的包导入路径
+	Set         string   // 所属 Set 名称
+	Kind        Kind     // 声明类型：类型、函数或变量声明
+	Constructor string   // 构造函数名称，为空表示使用 wire.Struct 注入
+	Bindings    []string // 绑定的接口列表，对应生成的 wire.Bind
+	Fields      []string // 导出字段列表（仅 config 组件）
+	Init        bool     // 是否标记为 @autowire.init
+	Injector    string   // 命名注入入口（@autowire.init(name=...)），为空表示未命名
+	Config      bool     // 是否标记为 @autowire.config
+	Position    Position // 声明在源文件中的位置
+}
+
+// Kind 组件声明类型.
+type Kind string
+
+const (
+	// KindType 类型声明，如 type Zoo struct{}.
+	KindType Kind = "type"
+	// KindFunc 函数声明，如 func NewZoo() *Zoo.
+	KindFunc Kind = "func"
+	// KindValue 变量声明，如 var DefaultOptions = &Options{}（@autowire.value）.
+	KindValue Kind = "value"
+)
+
+// Position struct    源码位置.
+type Position struct {
+	Filename string // 文件路径
+	Line     int    // 行号，从 1 开始
+	Column   int    // 列号，从 1 开始
+}
+
+// Set method    按名称查找 Set，不存在时返回 nil.
+func (m *Model) Set(name string) *Set {
+	for i := range m.Sets {
+		if m.Sets[i].Name == name {
+			return &m.Sets[i]
+		}
+	}
+	return nil
+}
+
+// newModel function    将内部扫描结果转换为公共模型.
+func newModel(elementMap map[string]map[string]generator.Element) *Model {
+	m := &Model{Sets: make([]Set, 0, len(elementMap))}
+	for _, setName := range parser.SortedKeys(elementMap) {
+		elements := elementMap[setName]
+		set := Set{Name: setName, Elements: make([]Element, 0, len(elements))}
+		for _, key := range parser.SortedKeys(elements) {
+			set.Elements = append(set.Elements, newElement(elements[key]))
+		}
+		m.Sets = append(m.Sets, set)
+	}
+	return m
+}
+
+// newElement function    转换单个组件.
+func newElement(e generator.Element) Element {
+	kind := KindType
+	switch {
+	case e.FuncDecl:
+		kind = KindFunc
+	case e.ValueWire:
+		kind = KindValue
+	}
+	bindings := slices.Clone(e.Implements)
+	slices.SortFunc(bindings, strings.Compare)
+	return Element{
+		Name:        e.Name,
+		Pkg:         e.Pkg,
+		PkgPath:     e.PkgPath,
+		Set:         e.Set,
+		Kind:        kind,
+		Constructor: e.Constructor,
+		Bindings:    bindings,
+		Fields:      slices.Clone(e.Fields),
+		Init:        e.InitWire,
+		Injector:    e.Injector,
+		Config:      e.ConfigWire,
+		Position: Position{
+			Filename: e.Position.Filename,
+			Line:     e.Position.Line,
+			Column:   e.Position.Column,
+		},
+	}
+}
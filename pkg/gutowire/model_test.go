@@ -0,0 +1,56 @@
+package gutowire
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/generator"
+)
+
+func TestNewModel(t *testing.T) {
+	elementMap := map[string]map[string]generator.Element{
+		"zoo": {
+			"example.com/zoo/Zoo": {Name: "Zoo", Set: "zoo", InitWire: true},
+		},
+		"animals": {
+			"example.com/animals/Dog": {
+				Name:       "Dog",
+				Set:        "animals",
+				Implements: []string{"Animal", "Barker"},
+				Position:   token.Position{Filename: "dog.go", Line: 3, Column: 6},
+			},
+			"example.com/animals/NewCat": {Name: "NewCat", Set: "animals", Constructor: "NewCat", FuncDecl: true},
+		},
+	}
+
+	m := newModel(elementMap)
+
+	if len(m.Sets) != 2 || m.Sets[0].Name != "animals" || m.Sets[1].Name != "zoo" {
+		t.Fatalf("Sets 顺序错误: %+v", m.Sets)
+	}
+
+	animals := m.Set("animals")
+	if animals == nil || len(animals.Elements) != 2 {
+		t.Fatalf("Set(animals) = %+v, want 2 elements", animals)
+	}
+
+	dog := animals.Elements[0]
+	if dog.Name != "Dog" || dog.Set != "animals" || dog.Kind != KindType || len(dog.Bindings) != 2 {
+		t.Errorf("Dog = %+v", dog)
+	}
+	if dog.Position.Filename != "dog.go" || dog.Position.Line != 3 {
+		t.Errorf("Dog.Position = %+v", dog.Position)
+	}
+
+	if cat := animals.Elements[1]; cat.Kind != KindFunc || cat.Constructor != "NewCat" {
+		t.Errorf("NewCat = %+v", cat)
+	}
+
+	if !m.Set("zoo").Elements[0].Init {
+		t.Error("Zoo 应该标记为 init")
+	}
+
+	if m.Set("missing") != nil {
+		t.Error("Set(missing) 应该返回 nil")
+	}
+}
@@ -0,0 +1,165 @@
+package gutowire
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+// ScanOptions struct    扫描选项，零值表示使用默认配置.
+type ScanOptions struct {
+	SearchPaths []string              // 搜索路径，为空时使用 go.mod 所在目录以及 go.work 中的其他模块
+	ExcludeDirs []string              // 排除的目录，支持相对模块根目录的 glob，为 nil 时使用 config.DefaultExcludeDirs
+	IncludeOnly []string              // 只扫描的目录，支持相对模块根目录的 glob，为空表示全部
+	MaxDepth    int                   // 扫描进入的最大目录深度，搜索路径本身为第 0 层，0 表示不限制
+	Tag         string                // 注解标记，为空时使用 @autowire
+	NoCache     bool                  // 不读写缓存文件
+	NoIgnore    bool                  // 不遵循 .gitignore 与 .gutowireignore
+	GOOS        string                // 评估源文件构建约束的目标操作系统，为空时使用当前平台
+	GOARCH      string                // 评估源文件构建约束的目标架构，为空时使用当前平台
+	BuildTags   []string              // 评估源文件构建约束时启用的构建标签
+	Logger      *slog.Logger          // 日志器，为空时输出到标准输出
+	Progress    func(done, total int) // 扫描进度回调，每处理完一个文件调用一次，可能并发调用
+	Options     []Option              // 额外的配置函数，在以上字段之后应用
+}
+
+// GenerateOptions struct    生成选项，包含扫描选项.
+type GenerateOptions struct {
+	ScanOptions
+
+	Pkg              string            // 生成文件的包名，为空时从生成目录推断
+	InitTypes        []string          // 生成初始化函数的类型，如 Zoo；"*" 表示全部 @autowire.init 类型，为空时不生成
+	Backend          string            // 依赖注入后端：wire（默认）或 fx
+	DuplicateBinding string            // 重复接口绑定的处理策略：error（默认）、priority 或 split
+	ConflictPolicy   string            // 不同 Set 绑定同一接口的处理策略：error（默认）、prefer_set:<name> 或 last_wins
+	MissingProviders string            // 没有提供者的依赖的处理方式：error（默认）或 warn（只输出警告，交由 wire 报告）
+	Strict           bool              // 注解语法错误或输出警告时终止生成，默认只输出警告
+	Parallel         int               // 扫描与写入文件的最大并发数，0 表示使用 GOMAXPROCS
+	SetOutputs       map[string]string // Set 名称 -> 输出目录（相对模块根目录）
+	SetTags          map[string]string // Set 名称 -> 构建标签
+	SetBuildTags     map[string]string // Set 名称 -> Set 文件的构建约束
+	LockTimeout      time.Duration     // 等待生成目录锁的超时时间，0 表示使用默认值
+	InitTemplate     string            // 初始化文件（wire.gen.go）的 text/template 模板路径，为空时使用内置模板
+	FilePrefix       string            // 生成文件名的前缀，为空时使用 autowire
+	FileSuffix       string            // 生成文件名的后缀（.go 之前），如 _gen
+	HeaderTemplate   string            // 生成文件头部注释（如许可证）的 text/template 模板路径
+	Plugins          []string          // 代码生成插件：Go 插件（.so）路径或可执行文件命令，见 pkg/plugin
+}
+
+// RunOptions struct    完整运行（生成并调用 wire）的选项，包含生成选项.
+type RunOptions struct {
+	GenerateOptions
+
+	WireVersion string // 固定的 wire 版本，为空时使用 PATH 中的 wire
+	WireTags    string // 运行 wire 时使用的构建标签
+	WireMode    string // 运行 wire 的方式：exec（默认）或 gorun
+}
+
+// options method    将扫描选项转换为配置函数.
+func (s ScanOptions) options() []Option {
+	var opts []Option
+	if len(s.SearchPaths) > 0 {
+		opts = append(opts, config.WithSearchPaths(s.SearchPaths...))
+	}
+	if s.ExcludeDirs != nil {
+		opts = append(opts, config.WithExcludeDirs(s.ExcludeDirs))
+	}
+	if len(s.IncludeOnly) > 0 {
+		opts = append(opts, config.WithIncludeOnly(s.IncludeOnly...))
+	}
+	if s.MaxDepth > 0 {
+		opts = append(opts, config.WithMaxDepth(s.MaxDepth))
+	}
+	if s.Tag != "" {
+		opts = append(opts, config.WithTag(s.Tag))
+	}
+	if s.NoCache {
+		opts = append(opts, config.WithCache(false))
+	}
+	if s.NoIgnore {
+		opts = append(opts, config.WithIgnoreFiles(false))
+	}
+	if s.GOOS != "" || s.GOARCH != "" {
+		opts = append(opts, config.WithTarget(s.GOOS, s.GOARCH))
+	}
+	if len(s.BuildTags) > 0 {
+		opts = append(opts, config.WithBuildTags(s.BuildTags...))
+	}
+	if s.Logger != nil {
+		opts = append(opts, config.WithLogger(s.Logger))
+	}
+	if s.Progress != nil {
+		opts = append(opts, config.WithProgress(s.Progress))
+	}
+	return append(opts, s.Options...)
+}
+
+// options method    将生成选项转换为配置函数，ScanOptions.Options 最后应用.
+func (g GenerateOptions) options() []Option {
+	var opts []Option
+	if g.Pkg != "" {
+		opts = append(opts, config.WithPkg(g.Pkg))
+	}
+	if len(g.InitTypes) > 0 {
+		opts = append(opts, config.InitStruct(g.InitTypes...))
+	}
+	if g.Backend != "" {
+		opts = append(opts, config.WithBackend(g.Backend))
+	}
+	if g.DuplicateBinding != "" {
+		opts = append(opts, config.WithDuplicateBinding(g.DuplicateBinding))
+	}
+	if g.ConflictPolicy != "" {
+		opts = append(opts, config.WithConflictPolicy(g.ConflictPolicy))
+	}
+	if g.MissingProviders != "" {
+		opts = append(opts, config.WithMissingProviders(g.MissingProviders))
+	}
+	if g.Strict {
+		opts = append(opts, config.WithStrict(true))
+	}
+	if g.Parallel > 0 {
+		opts = append(opts, config.WithParallel(g.Parallel))
+	}
+	if len(g.SetOutputs) > 0 {
+		opts = append(opts, config.WithSetOutputs(g.SetOutputs))
+	}
+	if len(g.SetTags) > 0 {
+		opts = append(opts, config.WithSetTags(g.SetTags))
+	}
+	if len(g.SetBuildTags) > 0 {
+		opts = append(opts, config.WithSetBuildTags(g.SetBuildTags))
+	}
+	if g.LockTimeout > 0 {
+		opts = append(opts, config.WithLockTimeout(g.LockTimeout))
+	}
+	if g.InitTemplate != "" {
+		opts = append(opts, config.WithInitTemplate(g.InitTemplate))
+	}
+	if g.FilePrefix != "" || g.FileSuffix != "" {
+		opts = append(opts, config.WithFileNaming(g.FilePrefix, g.FileSuffix))
+	}
+	if g.HeaderTemplate != "" {
+		opts = append(opts, config.WithHeaderTemplate(g.HeaderTemplate))
+	}
+	if len(g.Plugins) > 0 {
+		opts = append(opts, config.WithPlugins(g.Plugins...))
+	}
+	return append(opts, g.ScanOptions.options()...)
+}
+
+// options method    将运行选项转换为配置函数，ScanOptions.Options 最后应用.
+func (r RunOptions) options() []Option {
+	var opts []Option
+	if r.WireVersion != "" {
+		opts = append(opts, config.WithWireVersion(r.WireVersion))
+	}
+	if r.WireTags != "" {
+		opts = append(opts, config.WithWireTags(r.WireTags))
+	}
+	if r.WireMode != "" {
+		opts = append(opts, config.WithWireMode(r.WireMode))
+	}
+	return append(opts, r.GenerateOptions.options()...)
+}
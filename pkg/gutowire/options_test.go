@@ -0,0 +1,44 @@
+package gutowire
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spelens-gud/gutowire/internal/config"
+)
+
+func TestRunOptions(t *testing.T) {
+	opts := RunOptions{
+		GenerateOptions: GenerateOptions{
+			ScanOptions: ScanOptions{
+				SearchPaths: []string{"./internal"},
+				NoCache:     true,
+				BuildTags:   []string{"integration"},
+				Options:     []Option{config.WithPkg("override")},
+			},
+			Pkg:       "wiring",
+			InitTypes: []string{"*"},
+			Backend:   config.BackendFx,
+		},
+		WireMode: config.WireModeEmbedded, // 旧名称，初始化时转换为 gorun
+	}
+	o := config.NewGenOpt(t.TempDir(), opts.options()...)
+
+	// ScanOptions.Options 最后应用，可以覆盖结构体字段
+	if o.Pkg != "override" {
+		t.Errorf("Pkg = %q, want override", o.Pkg)
+	}
+	if !slices.Equal(o.SearchPaths, []string{"./internal"}) || o.EnableCache {
+		t.Errorf("SearchPaths = %v, EnableCache = %v", o.SearchPaths, o.EnableCache)
+	}
+	if !slices.Equal(o.BuildTags, []string{"integration"}) || !slices.Equal(o.InitWire, []string{"*"}) {
+		t.Errorf("BuildTags = %v, InitWire = %v", o.BuildTags, o.InitWire)
+	}
+	if o.Backend != config.BackendFx || o.WireMode != config.WireModeGoRun {
+		t.Errorf("Backend = %q, WireMode = %q", o.Backend, o.WireMode)
+	}
+	// 未设置的字段使用默认值
+	if !slices.Equal(o.ExcludeDirs, config.DefaultExcludeDirs) {
+		t.Errorf("ExcludeDirs = %v", o.ExcludeDirs)
+	}
+}
@@ -0,0 +1,132 @@
+// Package plugin 定义 gutowire 的代码生成插件接口。
+// 插件在 gutowire 生成 Wire 配置文件之后依次收到扫描到的组件、写入的 Set 文件以及生成完成的通知，
+// 可以基于同一次扫描生成额外的文件（指标注册表、组件目录、服务定位器等），而无需再实现一遍注解扫描。
+//
+// 插件有两种加载方式：
+//   - Go 插件：go build -buildmode=plugin 编译的 .so 文件，导出名为 Plugin 的变量（类型实现 Plugin 接口）
+//   - 可执行文件：gutowire 启动该命令，通过标准输入发送事件、从标准输出读取响应，每行一个 JSON，
+//     使用 Serve 可以直接实现该协议
+//
+// 本包导出的类型与函数保持向后兼容.
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Plugin interface    代码生成插件，任何方法返回错误都会终止本次生成.
+type Plugin interface {
+	// OnElement 对每个组件调用一次，按 Set 名称、包路径与名称排序
+	OnElement(e Element) error
+	// OnSetWritten 每个 Set 文件生成后调用一次，按 Set 名称排序
+	OnSetWritten(s SetFile) error
+	// OnComplete 全部组件与 Set 处理完成后调用，返回需要写入的文件
+	OnComplete(r Result) ([]File, error)
+}
+
+// Element struct    带 @autowire 注解的组件.
+type Element struct {
+	Set         string   `json:"set"`                   // 所属 Set 名称
+	Name        string   `json:"name"`                  // 类型、函数或变量名称
+	Pkg         string   `json:"pkg"`                   // 所在包名
+	PkgPath     string   `json:"pkg_path"`              // 完整的包导入路径
+	Kind        string   `json:"kind"`                  // 声明类型：type、func 或 value
+	Constructor string   `json:"constructor,omitempty"` // 构造函数名称，为空表示使用 wire.Struct 注入
+	Bindings    []string `json:"bindings,omitempty"`    // 绑定的接口列表
+	Deps        []string `json:"deps,omitempty"`        // 依赖的类型（包路径.类型名）
+	Init        bool     `json:"init,omitempty"`        // 是否标记为 @autowire.init
+	Config      bool     `json:"config,omitempty"`      // 是否标记为 @autowire.config
+	Tag         string   `json:"tag,omitempty"`         // 构建标签（tag= 参数）
+	Qualifier   string   `json:"qualifier,omitempty"`   // 限定名（qualifier= 参数）
+	File        string   `json:"file"`                  // 声明所在的源文件
+	Line        int      `json:"line"`                  // 声明所在的行号
+}
+
+// SetFile struct    生成的 Set 文件.
+type SetFile struct {
+	Name     string `json:"name"`     // Set 名称
+	File     string `json:"file"`     // 生成的文件路径
+	Elements int    `json:"elements"` // Set 中的组件数量
+}
+
+// Result struct    本次生成的结果.
+type Result struct {
+	GenPath string   `json:"gen_path"` // 生成目录
+	Package string   `json:"package"`  // 生成文件的包名
+	Sets    []string `json:"sets"`     // 生成的 Set 名称
+}
+
+// File struct    插件需要写入的文件.
+type File struct {
+	Path    string `json:"path"`    // 文件路径，相对路径相对生成目录，不能位于生成目录之外
+	Content string `json:"content"` // 文件内容
+}
+
+// 事件名称.
+const (
+	EventElement    = "element"     // 组件事件，Event.Element 有效
+	EventSetWritten = "set_written" // Set 文件写入事件，Event.Set 有效
+	EventComplete   = "complete"    // 生成完成事件，Event.Result 有效
+)
+
+// Event struct    可执行文件插件从标准输入读取的事件.
+type Event struct {
+	Event   string   `json:"event"`             // 事件名称
+	Element *Element `json:"element,omitempty"` // 组件
+	Set     *SetFile `json:"set,omitempty"`     // 生成的 Set 文件
+	Result  *Result  `json:"result,omitempty"`  // 生成结果
+}
+
+// Response struct    可执行文件插件对每个事件输出的响应.
+type Response struct {
+	Error string `json:"error,omitempty"` // 不为空时终止生成
+	Files []File `json:"files,omitempty"` // 需要写入的文件（仅 complete 事件）
+}
+
+// Serve function    以可执行文件插件的协议运行 p：从标准输入逐行读取事件，对每个事件向标准输出写入一行响应
+// 标准输入关闭时返回；插件自身的日志应输出到标准错误.
+func Serve(p Plugin) error {
+	return serve(p, os.Stdin, os.Stdout)
+}
+
+// serve function    Serve 的实现，便于测试.
+func serve(p Plugin, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return fmt.Errorf("解析事件失败: %w", err)
+		}
+		var resp Response
+		if err := dispatch(p, ev, &resp); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("写入响应失败: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// dispatch function    调用事件对应的插件方法.
+func dispatch(p Plugin, ev Event, resp *Response) error {
+	switch {
+	case ev.Event == EventElement && ev.Element != nil:
+		return p.OnElement(*ev.Element)
+	case ev.Event == EventSetWritten && ev.Set != nil:
+		return p.OnSetWritten(*ev.Set)
+	case ev.Event == EventComplete && ev.Result != nil:
+		files, err := p.OnComplete(*ev.Result)
+		resp.Files = files
+		return err
+	default:
+		// 未知事件忽略，便于之后增加新的事件
+		return nil
+	}
+}
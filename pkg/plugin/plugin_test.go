@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// catalog 记录组件名称，完成时生成组件目录.
+type catalog struct {
+	names []string
+}
+
+func (c *catalog) OnElement(e Element) error {
+	if e.Name == "Bad" {
+		return errors.New("不支持的组件 Bad")
+	}
+	c.names = append(c.names, e.Name)
+	return nil
+}
+
+func (c *catalog) OnSetWritten(SetFile) error { return nil }
+
+func (c *catalog) OnComplete(r Result) ([]File, error) {
+	return []File{{Path: "catalog.txt", Content: r.Package + ":" + strings.Join(c.names, ",")}}, nil
+}
+
+func TestServe(t *testing.T) {
+	events := []Event{
+		{Event: EventElement, Element: &Element{Name: "Repo"}},
+		{Event: EventElement, Element: &Element{Name: "Bad"}},
+		{Event: "unknown"},
+		{Event: EventSetWritten, Set: &SetFile{Name: "svc"}},
+		{Event: EventComplete, Result: &Result{Package: "wire"}},
+	}
+	var in bytes.Buffer
+	enc := json.NewEncoder(&in)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := serve(&catalog{}, &in, &out); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(events) {
+		t.Fatalf("响应行数 = %d, want %d:\n%s", len(lines), len(events), out.String())
+	}
+	var bad, done Response
+	if err := json.Unmarshal([]byte(lines[1]), &bad); err != nil || bad.Error != "不支持的组件 Bad" {
+		t.Errorf("Bad 的响应 = %s", lines[1])
+	}
+	if err := json.Unmarshal([]byte(lines[4]), &done); err != nil ||
+		len(done.Files) != 1 || done.Files[0].Content != "wire:Repo" {
+		t.Errorf("complete 的响应 = %s", lines[4])
+	}
+}